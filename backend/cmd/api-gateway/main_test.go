@@ -0,0 +1,201 @@
+package main
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/yerenwgventures/GreatNigeriaLibrary-Foundation/backend/pkg/common/config"
+	grouphandler "github.com/yerenwgventures/GreatNigeriaLibrary-Foundation/backend/services/groups/handler"
+	notificationhandlers "github.com/yerenwgventures/GreatNigeriaLibrary-Foundation/backend/services/notification/handlers"
+	notificationservice "github.com/yerenwgventures/GreatNigeriaLibrary-Foundation/backend/services/notification/service"
+)
+
+// Routes registered by the auth, content and discussion service mains,
+// which cannot be imported here. Groups and notification routes come from
+// their handlers.
+var (
+	authServiceRoutes = []string{
+		"POST /auth/login",
+		"GET /auth/oauth/:provider/callback",
+		"POST /auth/email/verify/send",
+		"GET /users/:id/profile",
+		"GET /account/sessions",
+		"POST /account/2fa/webauthn/register/begin",
+		"POST /admin/sessions/maintenance",
+		"GET /admin/users",
+		"POST /admin/content/rules",
+		"GET /engaged/features",
+		"GET /active/features",
+		"GET /premium/features",
+		"GET /moderator/tools",
+	}
+	contentServiceRoutes = []string{
+		"GET /public/books",
+		"GET /public/books/:id/chapters",
+		"GET /content/books/:id",
+		"GET /api/sections/:id/quiz",
+		"POST /api/sections/:id/audio",
+		"GET /api/media/jobs/:id",
+		"POST /api/export/:contentType/:id",
+		"GET /api/v1/search",
+		"POST /api/v1/content-admin/import/books",
+		"POST /user/books/:id/progress",
+		"GET /user/points/balance",
+		"POST /create/books",
+		"PUT /manage/books/:id",
+		"POST /admin/content/import",
+		"POST /admin/points/adjust",
+	}
+	discussionServiceRoutes = []string{
+		"GET /public/discussions/:id",
+		"GET /discussions/",
+		"GET /discussions/:id/comments",
+		"POST /participate/discussions/:id/comments",
+		"POST /rich-text/attachments",
+		"POST /reports",
+		"GET /reports/moderation/pending",
+		"DELETE /moderate/comments/:id",
+		"GET /moderation/queue",
+		"GET /moderation/trust-settings",
+		"GET /trust/me",
+		"POST /moderation/flags",
+		"POST /appeals",
+		"GET /moderation/appeals",
+		"GET /moderation/dashboard",
+		"GET /admin/discussions/stats",
+	}
+)
+
+// newService starts a stand-in for a service that answers every request
+// matching one of its routes with the service name and the matched route
+func newService(t *testing.T, name string, register func(router *gin.Engine)) string {
+	t.Helper()
+
+	router := gin.New()
+	router.Use(func(c *gin.Context) {
+		if c.FullPath() == "" {
+			return
+		}
+		c.String(http.StatusOK, name+" "+c.Request.Method+" "+c.FullPath())
+		c.Abort()
+	})
+	register(router)
+
+	server := httptest.NewServer(router)
+	t.Cleanup(server.Close)
+	return server.URL
+}
+
+func registerRoutes(routes []string) func(router *gin.Engine) {
+	return func(router *gin.Engine) {
+		for _, route := range routes {
+			method, path, _ := strings.Cut(route, " ")
+			router.Handle(method, path, func(c *gin.Context) {})
+		}
+	}
+}
+
+func newTestGateway(t *testing.T) *gin.Engine {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+
+	discussion := func(router *gin.Engine) {
+		registerRoutes(discussionServiceRoutes)(router)
+		notifications := notificationhandlers.NewNotificationHandler(nil, notificationservice.NewStreamTokens("secret", 0))
+		notifications.RegisterRoutes(router.Group("/"))
+		notifications.RegisterStreamRoutes(router.Group("/"))
+	}
+	groups := func(router *gin.Engine) {
+		handler := grouphandler.NewGroupHandler(nil)
+		handler.RegisterPublicRoutes(router.Group("/"))
+		handler.RegisterRoutes(router.Group("/"))
+	}
+
+	cfg := &config.Config{}
+	cfg.Auth.JWTSecret = "secret"
+	cfg.Services.AuthService = config.ServiceConfig{URL: newService(t, "auth", registerRoutes(authServiceRoutes)), Timeout: time.Second}
+	cfg.Services.ContentService = config.ServiceConfig{URL: newService(t, "content", registerRoutes(contentServiceRoutes)), Timeout: time.Second}
+	cfg.Services.DiscussionService = config.ServiceConfig{URL: newService(t, "discussion", discussion), Timeout: time.Second}
+	cfg.Services.GroupsService = config.ServiceConfig{URL: newService(t, "groups", groups), Timeout: time.Second}
+
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+
+	router := gin.New()
+	require.NoError(t, registerGatewayRoutes(router, cfg, logger))
+	return router
+}
+
+func TestGatewayRoutesReachServiceRoutes(t *testing.T) {
+	router := newTestGateway(t)
+
+	cases := map[string]string{
+		"POST /api/v1/auth/login":                          "auth POST /auth/login",
+		"GET /api/v1/auth/oauth/google/callback":           "auth GET /auth/oauth/:provider/callback",
+		"POST /api/v1/auth/email/verify/send":              "auth POST /auth/email/verify/send",
+		"GET /api/v1/users/7/profile":                      "auth GET /users/:id/profile",
+		"GET /api/v1/account/sessions":                     "auth GET /account/sessions",
+		"POST /api/v1/account/2fa/webauthn/register/begin": "auth POST /account/2fa/webauthn/register/begin",
+		"POST /api/v1/auth/admin/sessions/maintenance":     "auth POST /admin/sessions/maintenance",
+		"GET /api/v1/auth/admin/users":                     "auth GET /admin/users",
+		"POST /api/v1/auth/admin/content/rules":            "auth POST /admin/content/rules",
+		"GET /api/v1/engaged/features":                     "auth GET /engaged/features",
+		"GET /api/v1/active/features":                      "auth GET /active/features",
+		"GET /api/v1/premium/features":                     "auth GET /premium/features",
+		"GET /api/v1/moderator/tools":                      "auth GET /moderator/tools",
+		"GET /api/v1/content/public/books":                 "content GET /public/books",
+		"GET /api/v1/content/public/books/3/chapters":      "content GET /public/books/:id/chapters",
+		"GET /api/v1/content/books/3":                      "content GET /content/books/:id",
+		"GET /api/v1/sections/5/quiz":                      "content GET /api/sections/:id/quiz",
+		"POST /api/v1/sections/5/audio":                    "content POST /api/sections/:id/audio",
+		"GET /api/v1/media/jobs/9":                         "content GET /api/media/jobs/:id",
+		"POST /api/v1/export/book/3":                       "content POST /api/export/:contentType/:id",
+		"GET /api/v1/search":                               "content GET /api/v1/search",
+		"POST /api/v1/content-admin/import/books":          "content POST /api/v1/content-admin/import/books",
+		"POST /api/v1/user/books/3/progress":               "content POST /user/books/:id/progress",
+		"GET /api/v1/user/points/balance":                  "content GET /user/points/balance",
+		"POST /api/v1/create/books":                        "content POST /create/books",
+		"PUT /api/v1/manage/books/3":                       "content PUT /manage/books/:id",
+		"POST /api/v1/content/admin/content/import":        "content POST /admin/content/import",
+		"POST /api/v1/content/admin/points/adjust":         "content POST /admin/points/adjust",
+		"GET /api/v1/discussion/public/discussions/4":      "discussion GET /public/discussions/:id",
+		"GET /api/v1/discussions/":                         "discussion GET /discussions/",
+		"GET /api/v1/discussions/4/comments":               "discussion GET /discussions/:id/comments",
+		"POST /api/v1/participate/discussions/4/comments":  "discussion POST /participate/discussions/:id/comments",
+		"POST /api/v1/rich-text/attachments":               "discussion POST /rich-text/attachments",
+		"POST /api/v1/reports":                             "discussion POST /reports",
+		"GET /api/v1/reports/moderation/pending":           "discussion GET /reports/moderation/pending",
+		"DELETE /api/v1/moderate/comments/4":               "discussion DELETE /moderate/comments/:id",
+		"GET /api/v1/moderation/queue":                     "discussion GET /moderation/queue",
+		"GET /api/v1/moderation/trust-settings":            "discussion GET /moderation/trust-settings",
+		"GET /api/v1/trust/me":                             "discussion GET /trust/me",
+		"POST /api/v1/moderation/flags":                    "discussion POST /moderation/flags",
+		"POST /api/v1/appeals":                             "discussion POST /appeals",
+		"GET /api/v1/moderation/appeals":                   "discussion GET /moderation/appeals",
+		"GET /api/v1/moderation/dashboard":                 "discussion GET /moderation/dashboard",
+		"GET /api/v1/discussion/admin/discussions/stats":   "discussion GET /admin/discussions/stats",
+		"GET /api/v1/notifications":                        "discussion GET /notifications",
+		"POST /api/v1/notifications/stream-token":          "discussion POST /notifications/stream-token",
+		"GET /api/v1/notifications/stream":                 "discussion GET /notifications/stream",
+		"GET /api/v1/groups":                               "groups GET /groups",
+		"GET /api/v1/groups/12":                            "groups GET /groups/:id",
+		"POST /api/v1/groups/12/members":                   "groups POST /groups/:id/members",
+		"GET /api/v1/groups/12/calendar.ics":               "groups GET /groups/:id/calendar.ics",
+	}
+	for route, want := range cases {
+		method, path, _ := strings.Cut(route, " ")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(method, path, nil))
+
+		assert.Equal(t, http.StatusOK, w.Code, route)
+		assert.Equal(t, want, w.Body.String(), route)
+	}
+}
@@ -1,18 +1,23 @@
 package main
 
 import (
+        "context"
         "log"
         "os"
 
         "github.com/gin-gonic/gin"
+        "github.com/yerenwgventures/GreatNigeriaLibrary-Foundation/backend/services/auth/account"
         "github.com/yerenwgventures/GreatNigeriaLibrary-Foundation/backend/services/auth/handlers"
         "github.com/yerenwgventures/GreatNigeriaLibrary-Foundation/backend/services/auth/repository"
         "github.com/yerenwgventures/GreatNigeriaLibrary-Foundation/backend/services/auth/service"
+        "github.com/yerenwgventures/GreatNigeriaLibrary-Foundation/backend/pkg/common/audit"
         "github.com/yerenwgventures/GreatNigeriaLibrary-Foundation/backend/pkg/common/auth"
         "github.com/yerenwgventures/GreatNigeriaLibrary-Foundation/backend/pkg/common/config"
         "github.com/yerenwgventures/GreatNigeriaLibrary-Foundation/backend/pkg/common/database"
         "github.com/yerenwgventures/GreatNigeriaLibrary-Foundation/backend/pkg/common/logger"
+        "github.com/yerenwgventures/GreatNigeriaLibrary-Foundation/backend/pkg/common/mailer"
         "github.com/yerenwgventures/GreatNigeriaLibrary-Foundation/backend/pkg/common/middleware"
+        "github.com/yerenwgventures/GreatNigeriaLibrary-Foundation/backend/pkg/common/redis"
         "github.com/yerenwgventures/GreatNigeriaLibrary-Foundation/backend/pkg/models"
         "github.com/joho/godotenv"
 )
@@ -44,32 +49,58 @@ func main() {
                 logger.Fatal("Failed to connect to database: " + err.Error())
         }
 
-        // Run GORM auto-migration for auth service
+        // Apply versioned schema migrations
         migrationService := database.NewMigrationService(db, logger)
-        if err := migrationService.MigrateAuthService(); err != nil {
-                logger.Fatal("Failed to run auth service migrations: " + err.Error())
+        if err := migrationService.Migrate(); err != nil {
+                logger.Fatal("Failed to run database migrations: " + err.Error())
+        }
+        if cfg.Database.SeedDemoData {
+                if err := migrationService.SeedDemoData(); err != nil {
+                        logger.Fatal("Failed to load demo data: " + err.Error())
+                }
         }
 
         // Initialize repositories
         userRepo := repository.NewUserRepository(db, logger.Logger)
         twoFARepo := repository.NewTwoFARepository(db, logger.Logger)
         sessionRepo := repository.NewSessionRepository(db, logger.Logger)
+        identityRepo := repository.NewIdentityRepository(db, logger.Logger)
+        webAuthnRepo := repository.NewWebAuthnRepository(db, logger.Logger)
         contentAccessRepo := repository.NewGormContentAccessRepository(db)
 
+        // Outgoing email is persisted in a queue and delivered in the background
+        // with retries, through SMTP or the development outbox (MAIL_DRIVER)
+        mailQueue := mailer.NewQueue(db, mailer.New(cfg.Email))
+        go mailQueue.Run(context.Background(), mailer.DefaultPollInterval)
+
+        // Security and admin actions of all services go to one hash-chained
+        // audit log, which this service exposes to admins
+        auditStore := audit.NewGormStore(db)
+        auditRecorder := audit.NewRecorder(auditStore, "auth")
+
         // Initialize services
         userService := service.NewUserService(userRepo, logger)
+        userService.SetMailer(mailQueue)
+        userService.SetIdentityRepository(identityRepo)
+        userService.SetAuditRecorder(auditRecorder)
+        accountService := account.NewService(userRepo, logger)
+        accountService.SetAuditRecorder(auditRecorder)
         twoFAService := service.NewTwoFAService(twoFARepo, userService, logger)
+        webAuthnService := service.NewWebAuthnService(webAuthnRepo, userService, logger)
         sessionService := service.NewSessionService(sessionRepo, userRepo, logger)
         contentAccessService := service.NewContentAccessService(contentAccessRepo, userRepo, logger.Logger)
 
         // Initialize handlers
         userHandler := handlers.NewUserHandler(userService, logger)
-        accountHandler := handlers.NewAccountHandler(userService, logger)
+        accountHandler := account.NewAccountHandler(accountService, logger)
         twoFAHandler := handlers.NewTwoFAHandler(twoFAService, logger)
+        webAuthnHandler := account.NewWebAuthnHandler(webAuthnService, logger)
+        identityHandler := account.NewIdentityHandler(userService, logger)
         sessionHandler := handlers.NewSessionHandler(sessionService, logger)
         contentAccessHandler := handlers.NewContentAccessHandler(contentAccessService, logger)
         verificationHandler := handlers.NewVerificationHandler(userService)
         profileCompletionHandler := handlers.NewProfileCompletionHandler(userService)
+        auditHandler := audit.NewHandler(auditStore)
 
         // Set up Gin router with centralized error handling
         router := gin.New()
@@ -80,9 +111,34 @@ func main() {
         router.Use(middleware.RequestLogger())
         router.Use(middleware.SecurityHeaders())
 
+        // Rate limit requests, sharing buckets through Redis when it is available.
+        // The limiter is applied per route group after authentication so signed-in
+        // users are limited by user ID rather than IP. Login, password reset and
+        // 2FA verification get stricter per-route limits.
+        var rateLimitRedis *redis.Client
+        if cfg.Redis.Enabled {
+                rateLimitRedis, err = redis.NewClient(&redis.Config{
+                        Host:         cfg.Redis.Host,
+                        Port:         cfg.Redis.Port,
+                        Password:     cfg.Redis.Password,
+                        Database:     cfg.Redis.Database,
+                        PoolSize:     cfg.Redis.PoolSize,
+                        MinIdleConns: cfg.Redis.MinIdleConns,
+                        MaxRetries:   cfg.Redis.MaxRetries,
+                        DialTimeout:  cfg.Redis.DialTimeout,
+                        ReadTimeout:  cfg.Redis.ReadTimeout,
+                        WriteTimeout: cfg.Redis.WriteTimeout,
+                })
+                if err != nil {
+                        logger.WithError(err).Warn("Failed to connect to Redis, rate limits will be tracked per instance")
+                        rateLimitRedis = nil
+                }
+        }
+        rateLimiter := middleware.NewRateLimiter(cfg.RateLimit, rateLimitRedis, logger)
+
         // Initialize enhanced JWT manager and authorization manager
         jwtManager := userService.GetJWTManager() // We'll need to add this method
-        authManager := auth.NewAuthorizationManager()
+        authManager := authorizer{auth.NewAuthorizationManager()}
 
         // Add health check endpoint
         router.GET("/health", func(c *gin.Context) {
@@ -94,6 +150,7 @@ func main() {
 
         // Define API routes
         authRoutes := router.Group("/auth")
+        authRoutes.Use(rateLimiter.Middleware())
         {
                 authRoutes.POST("/register", userHandler.Register)
                 authRoutes.POST("/login", userHandler.Login)
@@ -101,18 +158,22 @@ func main() {
                 authRoutes.POST("/password/reset", userHandler.ResetPassword)
                 authRoutes.POST("/password/reset/confirm", userHandler.ConfirmPasswordReset)
                 authRoutes.POST("/logout", userHandler.Logout)
+                authRoutes.GET("/oauth/providers", identityHandler.GetProviders)
                 authRoutes.GET("/oauth/:provider", userHandler.OAuthLogin)
                 authRoutes.GET("/oauth/:provider/callback", userHandler.OAuthCallback)
+                webAuthnHandler.RegisterLoginRoutes(authRoutes)
         }
         
         // Content access routes (public endpoint)
         contentRoutes := router.Group("/content")
+        contentRoutes.Use(rateLimiter.Middleware())
         {
                 contentRoutes.POST("/check-access", contentAccessHandler.CheckContentAccess)
         }
                 
         // Email verification routes
         emailRoutes := router.Group("/auth/email")
+        emailRoutes.Use(rateLimiter.Middleware())
         {
                 emailRoutes.POST("/verify/send", userHandler.SendEmailVerification)
                 emailRoutes.POST("/verify/confirm", userHandler.VerifyEmail)
@@ -122,10 +183,11 @@ func main() {
         // Basic user routes - require authentication
         userRoutes := router.Group("/users")
         userRoutes.Use(middleware.AuthRequired(jwtManager, logger))
+        userRoutes.Use(rateLimiter.Middleware())
         {
                 userRoutes.GET("/:id", userHandler.GetUser)
                 userRoutes.PATCH("/:id",
-                        middleware.ResourceOwnerOrPermission(authManager, auth.PermissionUpdateProfile, "user_id", logger),
+                        middleware.ResourceOwnerOrPermission(authManager, middleware.Permission(auth.PermissionUpdateProfile), "user_id", logger),
                         userHandler.UpdateUser)
                 userRoutes.GET("/:id/profile", userHandler.GetUserProfile)
         }
@@ -133,9 +195,10 @@ func main() {
         // Account management routes - require authentication
         accountRoutes := router.Group("/account")
         accountRoutes.Use(middleware.AuthRequired(jwtManager, logger))
+        accountRoutes.Use(rateLimiter.Middleware())
         {
                 accountRoutes.DELETE("/delete",
-                        middleware.PermissionRequired(authManager, auth.PermissionDeleteProfile, logger),
+                        middleware.PermissionRequired(authManager, middleware.Permission(auth.PermissionDeleteProfile), logger),
                         accountHandler.DeleteAccount)
                 
                 // Two-factor authentication routes
@@ -147,6 +210,13 @@ func main() {
                 accountRoutes.POST("/2fa/backup-codes", twoFAHandler.GenerateBackupCodes)
                 accountRoutes.POST("/2fa/validate-backup", twoFAHandler.ValidateBackupCode)
                 
+                // WebAuthn passkeys and security keys, usable for passwordless
+                // login and as a second factor in place of TOTP
+                webAuthnHandler.RegisterRoutes(accountRoutes)
+                
+                // Linked OAuth identities
+                identityHandler.RegisterRoutes(accountRoutes)
+                
                 // Session management routes
                 accountRoutes.GET("/sessions", sessionHandler.GetSessions)
                 accountRoutes.POST("/sessions/revoke", sessionHandler.RevokeSession)
@@ -174,6 +244,7 @@ func main() {
         // Admin session routes - require admin access
         adminSessionRoutes := router.Group("/admin/sessions")
         adminSessionRoutes.Use(middleware.AdminAuth())
+        adminSessionRoutes.Use(rateLimiter.Middleware())
         {
                 adminSessionRoutes.POST("/maintenance", sessionHandler.PerformMaintenance)
         }
@@ -184,6 +255,7 @@ func main() {
         // Engaged user routes - require engaged user role or higher
         engagedUserRoutes := router.Group("/engaged")
         engagedUserRoutes.Use(middleware.RoleAuth(models.RoleEngagedUser))
+        engagedUserRoutes.Use(rateLimiter.Middleware())
         {
                 engagedUserRoutes.GET("/features", roleHandlers.GetEngagedUserFeatures)
         }
@@ -191,6 +263,7 @@ func main() {
         // Active user routes - require active user role or higher
         activeUserRoutes := router.Group("/active")
         activeUserRoutes.Use(middleware.RoleAuth(models.RoleActiveUser))
+        activeUserRoutes.Use(rateLimiter.Middleware())
         {
                 activeUserRoutes.GET("/features", roleHandlers.GetActiveUserFeatures)
         }
@@ -198,6 +271,7 @@ func main() {
         // Premium user routes - require premium user role or higher
         premiumUserRoutes := router.Group("/premium")
         premiumUserRoutes.Use(middleware.RoleAuth(models.RolePremiumUser))
+        premiumUserRoutes.Use(rateLimiter.Middleware())
         {
                 premiumUserRoutes.GET("/features", roleHandlers.GetPremiumUserFeatures)
         }
@@ -206,21 +280,22 @@ func main() {
         moderatorRoutes := router.Group("/moderator")
         moderatorRoutes.Use(middleware.AuthRequired(jwtManager, logger))
         moderatorRoutes.Use(middleware.RoleRequired(int(auth.RoleModerator), logger))
+        moderatorRoutes.Use(rateLimiter.Middleware())
         {
                 moderatorRoutes.GET("/tools", roleHandlers.GetModeratorTools)
 
                 // Content access management (read-only and permissions)
                 moderatorRoutes.GET("/content/access",
-                        middleware.PermissionRequired(authManager, auth.PermissionManageContent, logger),
+                        middleware.PermissionRequired(authManager, middleware.Permission(auth.PermissionManageContent), logger),
                         contentAccessHandler.GetContentAccess)
                 moderatorRoutes.GET("/content/rules",
-                        middleware.PermissionRequired(authManager, auth.PermissionManageContent, logger),
+                        middleware.PermissionRequired(authManager, middleware.Permission(auth.PermissionManageContent), logger),
                         contentAccessHandler.GetContentRules)
                 moderatorRoutes.POST("/content/permissions",
-                        middleware.PermissionRequired(authManager, auth.PermissionManageContent, logger),
+                        middleware.PermissionRequired(authManager, middleware.Permission(auth.PermissionManageContent), logger),
                         contentAccessHandler.GrantUserPermission)
                 moderatorRoutes.DELETE("/content/permissions/:id",
-                        middleware.PermissionRequired(authManager, auth.PermissionManageContent, logger),
+                        middleware.PermissionRequired(authManager, middleware.Permission(auth.PermissionManageContent), logger),
                         contentAccessHandler.RevokeUserPermission)
                 
                 // Verification request review
@@ -234,31 +309,43 @@ func main() {
         adminRoutes := router.Group("/admin")
         adminRoutes.Use(middleware.AuthRequired(jwtManager, logger))
         adminRoutes.Use(middleware.RoleRequired(int(auth.RoleAdmin), logger))
+        adminRoutes.Use(rateLimiter.Middleware())
         {
                 // User management
                 adminRoutes.GET("/users",
-                        middleware.PermissionRequired(authManager, auth.PermissionManageUsers, logger),
+                        middleware.PermissionRequired(authManager, middleware.Permission(auth.PermissionManageUsers), logger),
                         userHandler.ListUsers)
                 adminRoutes.PATCH("/users/:id/role",
-                        middleware.PermissionRequired(authManager, auth.PermissionManageUsers, logger),
+                        middleware.PermissionRequired(authManager, middleware.Permission(auth.PermissionManageUsers), logger),
                         userHandler.UpdateUserRole)
                 adminRoutes.GET("/users/role/:role",
-                        middleware.PermissionRequired(authManager, auth.PermissionManageUsers, logger),
+                        middleware.PermissionRequired(authManager, middleware.Permission(auth.PermissionManageUsers), logger),
                         userHandler.GetUsersByRole)
 
                 // Content access management
                 adminRoutes.POST("/content/access",
-                        middleware.PermissionRequired(authManager, auth.PermissionManageContent, logger),
+                        middleware.PermissionRequired(authManager, middleware.Permission(auth.PermissionManageContent), logger),
                         contentAccessHandler.SetContentAccess)
                 adminRoutes.POST("/content/rules",
-                        middleware.PermissionRequired(authManager, auth.PermissionManageContent, logger),
+                        middleware.PermissionRequired(authManager, middleware.Permission(auth.PermissionManageContent), logger),
                         contentAccessHandler.CreateContentRule)
                 adminRoutes.PUT("/content/rules",
-                        middleware.PermissionRequired(authManager, auth.PermissionManageContent, logger),
+                        middleware.PermissionRequired(authManager, middleware.Permission(auth.PermissionManageContent), logger),
                         contentAccessHandler.UpdateContentRule)
                 adminRoutes.DELETE("/content/rules/:id",
-                        middleware.PermissionRequired(authManager, auth.PermissionManageContent, logger),
+                        middleware.PermissionRequired(authManager, middleware.Permission(auth.PermissionManageContent), logger),
                         contentAccessHandler.DeleteContentRule)
+
+                // Audit log
+                adminRoutes.GET("/audit",
+                        middleware.PermissionRequired(authManager, middleware.Permission(auth.PermissionViewAuditLog), logger),
+                        auditHandler.Query)
+                adminRoutes.GET("/audit/export",
+                        middleware.PermissionRequired(authManager, middleware.Permission(auth.PermissionViewAuditLog), logger),
+                        auditHandler.Export)
+                adminRoutes.GET("/audit/verify",
+                        middleware.PermissionRequired(authManager, middleware.Permission(auth.PermissionViewAuditLog), logger),
+                        auditHandler.Verify)
         }
 
         // Start server
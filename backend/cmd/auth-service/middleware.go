@@ -0,0 +1,32 @@
+package main
+
+import (
+	"github.com/yerenwgventures/GreatNigeriaLibrary-Foundation/backend/pkg/common/auth"
+	"github.com/yerenwgventures/GreatNigeriaLibrary-Foundation/backend/pkg/common/middleware"
+)
+
+// authorizer lets the authorization manager satisfy
+// middleware.AuthorizationManager, whose methods take the middleware's Role
+// and Permission types
+type authorizer struct {
+	*auth.AuthorizationManager
+}
+
+// HasPermission checks if a role has a specific permission
+func (a authorizer) HasPermission(role middleware.Role, permission middleware.Permission) bool {
+	return a.AuthorizationManager.HasPermission(auth.Role(role), auth.Permission(permission))
+}
+
+// HasAnyPermission checks if a role has any of the specified permissions
+func (a authorizer) HasAnyPermission(role middleware.Role, permissions []middleware.Permission) bool {
+	converted := make([]auth.Permission, len(permissions))
+	for i, permission := range permissions {
+		converted[i] = auth.Permission(permission)
+	}
+	return a.AuthorizationManager.HasAnyPermission(auth.Role(role), converted)
+}
+
+// CanAccessResource checks if a user can access a specific resource
+func (a authorizer) CanAccessResource(userRole middleware.Role, userID uint, resource string, action string, resourceOwnerID uint) bool {
+	return a.AuthorizationManager.CanAccessResource(auth.Role(userRole), userID, resource, action, resourceOwnerID)
+}
@@ -1,13 +1,21 @@
 package main
 
 import (
+	"context"
 	"log"
 	"os"
 
 	"github.com/gin-gonic/gin"
 	"github.com/yerenwgventures/GreatNigeriaLibrary-Foundation/backend/services/content/handlers"
+	"github.com/yerenwgventures/GreatNigeriaLibrary-Foundation/backend/services/content/models"
+	"github.com/yerenwgventures/GreatNigeriaLibrary-Foundation/backend/services/content/publishing"
 	"github.com/yerenwgventures/GreatNigeriaLibrary-Foundation/backend/services/content/repository"
+	"github.com/yerenwgventures/GreatNigeriaLibrary-Foundation/backend/services/content/search"
 	"github.com/yerenwgventures/GreatNigeriaLibrary-Foundation/backend/services/content/service"
+	pointshandlers "github.com/yerenwgventures/GreatNigeriaLibrary-Foundation/backend/services/points/handlers"
+	pointsrepository "github.com/yerenwgventures/GreatNigeriaLibrary-Foundation/backend/services/points/repository"
+	pointsservice "github.com/yerenwgventures/GreatNigeriaLibrary-Foundation/backend/services/points/service"
+	"github.com/yerenwgventures/GreatNigeriaLibrary-Foundation/backend/pkg/common/audit"
 	"github.com/yerenwgventures/GreatNigeriaLibrary-Foundation/backend/pkg/common/auth"
 	"github.com/yerenwgventures/GreatNigeriaLibrary-Foundation/backend/pkg/common/config"
 	"github.com/yerenwgventures/GreatNigeriaLibrary-Foundation/backend/pkg/common/redis"
@@ -44,10 +52,10 @@ func main() {
 		logger.Fatal("Failed to connect to database: " + err.Error())
 	}
 
-	// Run GORM auto-migration for content service
+	// Apply versioned schema migrations
 	migrationService := database.NewMigrationService(db, logger)
-	if err := migrationService.MigrateContentService(); err != nil {
-		logger.Fatal("Failed to run content service migrations: " + err.Error())
+	if err := migrationService.Migrate(); err != nil {
+		logger.Fatal("Failed to run database migrations: " + err.Error())
 	}
 
 	// Initialize repositories
@@ -63,9 +71,56 @@ func main() {
 	bookmarkService := service.NewBookmarkService(bookmarkRepo, logger)
 	feedbackService := service.NewFeedbackService(feedbackRepo, bookRepo, logger)
 	noteService := service.NewNoteService(noteRepo, bookRepo, logger)
-	bookImportService := service.NewBookImportService(bookRepo, logger)
 	contentRenderer := service.NewContentRenderer(bookRepo)
-	mediaGenerator := service.NewMediaGenerator(bookRepo, contentRenderer, "http://localhost:5000", "./static/media")
+
+	// Initialize full-text search and rebuild the index in the background
+	searchIndex := search.NewPostgresIndex(db)
+	citationRepo := repository.NewCitationRepository(db)
+	citationService := service.NewCitationService(citationRepo, bookRepo)
+
+	// Section audio is generated by background workers
+	mediaJobRepo := repository.NewGormMediaJobRepository(db)
+	ttsEngine, err := newTTSEngine(cfg.TTS)
+	if err != nil {
+		logger.WithError(err).Warn("Audio generation is disabled")
+	}
+	mediaGenerator := service.NewMediaGenerator(
+		bookRepo,
+		citationService,
+		contentRenderer,
+		mediaJobRepo,
+		ttsEngine,
+		"http://localhost:5000",
+		"./static/media",
+	)
+	go service.NewMediaJobWorker(mediaGenerator, mediaJobRepo, models.MediaJobAudio, service.DefaultMediaJobInterval).Run(context.Background())
+	searchIndexer := service.NewSearchIndexer(
+		searchIndex,
+		bookRepo,
+		citationRepo,
+		repository.NewGormDiscussionRepository(db),
+	)
+	go func() {
+		if err := searchIndexer.ReindexAll(); err != nil {
+			logger.WithError(err).Warn("Failed to rebuild search index")
+		}
+	}()
+	searchService := service.NewSearchService(bookRepo, searchIndex)
+
+	// Manuscript imports revise existing content through the admin service
+	contentAdminService := service.NewContentAdminService(
+		bookRepo,
+		repository.NewGormChapterRepository(db),
+		repository.NewGormSectionRepository(db),
+		searchIndexer,
+	)
+	contentAdminService.SetAuditRecorder(audit.NewRecorder(audit.NewGormStore(db), "content"))
+	bookImportService := service.NewBookImportService(bookRepo, citationRepo, contentAdminService)
+
+	// Initialize the points ledger
+	pointsRepo := pointsrepository.NewGormPointsRepository(db)
+	pointsService := pointsservice.NewPointsService(pointsRepo)
+	progressService.SetPointsAwarder(pointsService)
 
 	// Initialize handlers
 	bookHandlers := handlers.NewBookHandlers(bookService, bookImportService)
@@ -75,10 +130,14 @@ func main() {
 	noteHandler := handlers.NewNoteHandler(noteService, logger)
 	quizHandler := handlers.NewQuizHandler()
 	mediaHandler := handlers.NewMediaHandler(mediaGenerator)
+	searchHandler := handlers.NewSearchHandler(searchService)
+	contentAdminHandler := handlers.NewContentAdminHandler(contentAdminService)
+	pointsHandler := pointshandlers.NewPointsHandler(pointsService)
 
 	// Initialize enhanced JWT manager and authorization manager
 	var jwtManager *auth.JWTManager
 	var authManager *auth.AuthorizationManager
+	var rateLimitRedis *redis.Client
 
 	// Initialize Redis client if enabled
 	if cfg.Redis.Enabled {
@@ -113,6 +172,7 @@ func main() {
 				cfg.Auth.JWTIssuer,
 			)
 			logger.Info("Redis connected successfully for JWT token management")
+			rateLimitRedis = redisClient
 		}
 	} else {
 		jwtManager = auth.NewJWTManagerWithoutRedis(
@@ -125,6 +185,20 @@ func main() {
 
 	authManager = auth.NewAuthorizationManager()
 
+	// Publish and unpublish scheduled content. The lease lets only one
+	// replica apply the schedule.
+	publishScheduler := publishing.NewScheduler(
+		contentAdminService,
+		publishing.NewGormStore(db),
+		database.NewLease(db, publishing.LeaseName, 3*publishing.DefaultInterval),
+		publishing.DefaultInterval,
+	)
+	publishScheduler.AddListener(mediaGenerator)
+	if rateLimitRedis != nil {
+		publishScheduler.AddListener(publishing.NewRedisEmitter(rateLimitRedis, publishing.DefaultEventChannel))
+	}
+	go publishScheduler.Run(context.Background())
+
 	// Set up Gin router with centralized error handling
 	router := gin.New()
 
@@ -134,8 +208,13 @@ func main() {
 	router.Use(middleware.RequestLogger())
 	router.Use(middleware.SecurityHeaders())
 
+	// Rate limit each route group after authentication so signed-in users are
+	// limited by user ID rather than IP
+	rateLimiter := middleware.NewRateLimiter(cfg.RateLimit, rateLimitRedis, logger)
+
 	// Public API routes - no authentication required
 	public := router.Group("/public")
+	public.Use(rateLimiter.Middleware())
 	{
 		public.GET("/books", bookHandlers.GetAllBooks)
 		public.GET("/books/:id", bookHandlers.GetBookByID)
@@ -148,6 +227,7 @@ func main() {
 	// Content reading routes - require basic authentication
 	content := router.Group("/content")
 	content.Use(middleware.AuthRequired(jwtManager, logger))
+	content.Use(rateLimiter.Middleware())
 	{
 		content.GET("/books",
 			middleware.PermissionRequired(authManager, auth.PermissionReadContent, logger),
@@ -168,12 +248,30 @@ func main() {
 
 	// Register interactive elements routes
 	apiGroup := router.Group("/api")
+	apiGroup.Use(rateLimiter.Middleware())
 	quizHandler.RegisterRoutes(apiGroup)
 	mediaHandler.RegisterRoutes(apiGroup)
 
+	// Edition export and audio generation routes - require authentication
+	mediaHandler.RegisterAuthenticatedRoutes(router.Group("/api", middleware.AuthRequired(jwtManager, logger), rateLimiter.Middleware()))
+
+	// Full-text search routes
+	apiV1 := router.Group("/api/v1")
+	searchHandler.RegisterRoutes(apiV1.Group("", rateLimiter.Middleware()))
+
+	// Content administration routes - require admin permissions. Imports,
+	// revisions and publishing go through the admin service, which keeps the
+	// search index in sync.
+	contentAdmin := apiV1.Group("")
+	contentAdmin.Use(middleware.AuthRequired(jwtManager, logger))
+	contentAdmin.Use(middleware.RoleRequired(int(auth.RoleAdmin), logger))
+	contentAdmin.Use(rateLimiter.Middleware())
+	contentAdminHandler.RegisterRoutes(contentAdmin)
+
 	// User content interaction routes - require authentication and content permissions
 	userContent := router.Group("/user")
 	userContent.Use(middleware.AuthRequired(jwtManager, logger))
+	userContent.Use(rateLimiter.Middleware())
 	{
 		// Book progress routes
 		userContent.POST("/books/:id/progress",
@@ -218,10 +316,14 @@ func main() {
 			noteHandler.ExportNotes)
 	}
 
+	// Points balance and history routes
+	pointsHandler.RegisterRoutes(userContent)
+
 	// Content creation routes - require content creation permissions
 	create := router.Group("/create")
 	create.Use(middleware.AuthRequired(jwtManager, logger))
 	create.Use(middleware.PermissionRequired(authManager, auth.PermissionCreateContent, logger))
+	create.Use(rateLimiter.Middleware())
 	{
 		// Future content creation endpoints will go here
 		create.POST("/books", func(c *gin.Context) {
@@ -237,6 +339,7 @@ func main() {
 		auth.PermissionDeleteContent,
 		auth.PermissionPublishContent,
 	}, logger))
+	manage.Use(rateLimiter.Middleware())
 	{
 		// Future content management endpoints will go here
 		manage.PUT("/books/:id", func(c *gin.Context) {
@@ -251,6 +354,7 @@ func main() {
 	admin := router.Group("/admin")
 	admin.Use(middleware.AuthRequired(jwtManager, logger))
 	admin.Use(middleware.RoleRequired(int(auth.RoleAdmin), logger))
+	admin.Use(rateLimiter.Middleware())
 	{
 		admin.GET("/content/stats", func(c *gin.Context) {
 			c.JSON(501, gin.H{"message": "Admin content stats not yet implemented"})
@@ -260,7 +364,9 @@ func main() {
 			func(c *gin.Context) {
 				c.JSON(501, gin.H{"message": "Content publishing not yet implemented"})
 			})
+		admin.POST("/content/import", bookHandlers.ImportManuscript)
 	}
+	pointsHandler.RegisterAdminRoutes(admin)
 
 		// Feedback routes
 		protected.POST("/feedback/mood", feedbackHandler.SubmitMoodFeedback)
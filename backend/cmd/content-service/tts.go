@@ -0,0 +1,35 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/yerenwgventures/GreatNigeriaLibrary-Foundation/backend/pkg/common/config"
+	"github.com/yerenwgventures/GreatNigeriaLibrary-Foundation/backend/services/content/tts"
+)
+
+// newTTSEngine creates the text-to-speech engine for section audio. It
+// returns nil without an error when audio is switched off.
+func newTTSEngine(cfg config.TTSConfig) (tts.Engine, error) {
+	var engine *tts.CommandEngine
+	switch cfg.Engine {
+	case "none", "":
+		return nil, nil
+	case "tone":
+		return tts.NewToneEngine(), nil
+	case "espeak":
+		engine = tts.NewEspeakEngine(cfg.Binary, cfg.Voice, cfg.WordsPerMinute)
+	case "piper":
+		if cfg.Model == "" {
+			return nil, errors.New("piper needs a voice model")
+		}
+		engine = tts.NewPiperEngine(cfg.Binary, cfg.Model)
+	default:
+		return nil, fmt.Errorf("unknown TTS engine: %s", cfg.Engine)
+	}
+
+	if err := engine.Check(); err != nil {
+		return nil, err
+	}
+	return engine, nil
+}
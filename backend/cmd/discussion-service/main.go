@@ -1,20 +1,30 @@
 package main
 
 import (
+	"context"
 	"log"
 	"os"
 
 	"github.com/gin-gonic/gin"
+	"github.com/yerenwgventures/GreatNigeriaLibrary-Foundation/backend/services/discussion/appeals"
 	"github.com/yerenwgventures/GreatNigeriaLibrary-Foundation/backend/services/discussion/handlers"
 	"github.com/yerenwgventures/GreatNigeriaLibrary-Foundation/backend/services/discussion/repository"
 	"github.com/yerenwgventures/GreatNigeriaLibrary-Foundation/backend/services/discussion/service"
+	notificationhandlers "github.com/yerenwgventures/GreatNigeriaLibrary-Foundation/backend/services/notification/handlers"
+	notificationrepository "github.com/yerenwgventures/GreatNigeriaLibrary-Foundation/backend/services/notification/repository"
+	notification "github.com/yerenwgventures/GreatNigeriaLibrary-Foundation/backend/services/notification/service"
+	pointsrepository "github.com/yerenwgventures/GreatNigeriaLibrary-Foundation/backend/services/points/repository"
+	pointsservice "github.com/yerenwgventures/GreatNigeriaLibrary-Foundation/backend/services/points/service"
+	"github.com/yerenwgventures/GreatNigeriaLibrary-Foundation/backend/pkg/common/audit"
 	"github.com/yerenwgventures/GreatNigeriaLibrary-Foundation/backend/pkg/common/auth"
 	"github.com/yerenwgventures/GreatNigeriaLibrary-Foundation/backend/pkg/common/config"
 	"github.com/yerenwgventures/GreatNigeriaLibrary-Foundation/backend/pkg/common/database"
 	"github.com/yerenwgventures/GreatNigeriaLibrary-Foundation/backend/pkg/common/logger"
+	"github.com/yerenwgventures/GreatNigeriaLibrary-Foundation/backend/pkg/common/mailer"
 	"github.com/yerenwgventures/GreatNigeriaLibrary-Foundation/backend/pkg/common/middleware"
 	"github.com/yerenwgventures/GreatNigeriaLibrary-Foundation/backend/pkg/common/redis"
 	"github.com/joho/godotenv"
+	"github.com/sirupsen/logrus"
 )
 
 func main() {
@@ -44,10 +54,10 @@ func main() {
 		logger.Fatal("Failed to connect to database: " + err.Error())
 	}
 
-	// Run GORM auto-migration for discussion service
+	// Apply versioned schema migrations
 	migrationService := database.NewMigrationService(db, logger)
-	if err := migrationService.MigrateDiscussionService(); err != nil {
-		logger.Fatal("Failed to run discussion service migrations: " + err.Error())
+	if err := migrationService.Migrate(); err != nil {
+		logger.Fatal("Failed to run database migrations: " + err.Error())
 	}
 
 	// Initialize repositories
@@ -60,15 +70,103 @@ func main() {
 	commentService := service.NewCommentService(commentRepo, logger)
 	likeService := service.NewLikeService(likeRepo, logger)
 
+	// Subscription digests are generated on a schedule and emailed through the shared email queue
+	mailQueue := mailer.NewQueue(db, mailer.New(cfg.Email))
+	emailTemplates, err := mailer.NewTemplates(cfg.Email.FromName)
+	if err != nil {
+		logger.Fatal("Failed to load email templates: " + err.Error())
+	}
+	gormDiscussionRepo := repository.NewGormDiscussionRepository(db)
+	subscriptionService := service.NewSubscriptionService(
+		repository.NewGormSubscriptionRepository(db),
+		gormDiscussionRepo,
+		repository.NewGormTopicRepository(db),
+		gormDiscussionRepo,
+		mailQueue,
+		emailTemplates,
+		cfg.App.FrontendURL,
+	)
+	go mailQueue.Run(context.Background(), mailer.DefaultPollInterval)
+	go service.NewDigestScheduler(subscriptionService, service.DefaultDigestInterval).Run(context.Background())
+
+	// Enforce category posting rules on new topics and comments, and publish
+	// held posts once they are approved in the moderation queue
+	moderationRepo := repository.NewGormModerationRepository(db)
+	moderationService := service.NewModerationService(moderationRepo, gormDiscussionRepo)
+	postingPolicy := service.NewPostingPolicy(gormDiscussionRepo, moderationService, nil)
+	discussionService.SetPostingPolicy(postingPolicy)
+	moderationService.SetContentApprover(discussionService)
+
+	// Score new posts for spam and abuse. Moderators' decisions label the
+	// scores, and one replica retrains the classifier from them.
+	spamService := service.NewSpamService(repository.NewGormClassificationRepository(db))
+	postingPolicy.SetSpamService(spamService)
+	moderationService.SetFeedback(spamService)
+	go service.NewClassifierTrainer(
+		spamService,
+		database.NewLease(db, service.ClassifierLeaseName, 3*service.DefaultRetrainInterval),
+		service.DefaultRetrainInterval,
+	).Run(context.Background())
+
+	// Recalculate trust levels from users' activity every day on one replica,
+	// syncing them to the auth service. Low levels may not post links.
+	trustService := service.NewTrustService(repository.NewGormTrustRepository(db), moderationRepo)
+	moderationService.SetTrustService(trustService)
+	postingPolicy.SetTrustService(trustService)
+	go service.NewTrustScheduler(
+		trustService,
+		database.NewLease(db, service.TrustLeaseName, 3*service.DefaultTrustInterval),
+		service.DefaultTrustInterval,
+	).Run(context.Background())
+
+	// Weigh flags by the flagger's trust level and accuracy. Enough weight
+	// hides a post and queues it for a moderator to confirm or reverse.
+	flagService := service.NewFlagService(repository.NewGormFlagRepository(db), gormDiscussionRepo, gormDiscussionRepo)
+	flagService.SetTrustService(trustService)
+	flagService.SetModerationQueue(moderationService)
+	flagService.SetFeedback(spamService)
+	moderationService.SetFlagResolver(flagService)
+
+	// Penalties and moderator grants are recorded in the audit log
+	auditRecorder := audit.NewRecorder(audit.NewGormStore(db), "discussion")
+	moderationService.SetAuditRecorder(auditRecorder)
+	flagService.SetAuditRecorder(auditRecorder)
+
+	// Users appeal penalties, bans and hidden posts to a moderator other than
+	// the one who decided, within the appeal window and review SLA
+	appealService := service.NewAppealService(repository.NewGormAppealRepository(db), flagService, moderationService, appeals.DefaultWindow, appeals.DefaultSLA)
+
+	// Rich text with mentions and attachments, and user reports of content
+	richTextService := service.NewRichTextService(repository.NewGormRichTextRepository(db), gormDiscussionRepo)
+	richTextService.SetTrustService(trustService)
+	reportService := service.NewReportService(repository.NewGormReportRepository(db), gormDiscussionRepo, gormDiscussionRepo, gormDiscussionRepo)
+
 	// Initialize handlers
 	discussionHandler := handlers.NewDiscussionHandler(discussionService, logger)
+
+	// Award forum points through the points ledger
+	pointsRepo := pointsrepository.NewGormPointsRepository(db)
+	pointsIntegration := handlers.NewForumPointsIntegration(discussionService, logrus.StandardLogger(), pointsservice.NewPointsService(pointsRepo), true)
+	discussionHandler.WithPointsIntegration(pointsIntegration)
 	commentHandler := handlers.NewCommentHandler(commentService, logger)
 	likeHandler := handlers.NewLikeHandler(likeService, logger)
+	richTextHandler := handlers.NewRichTextHandler(richTextService)
+	reportHandler := handlers.NewReportHandler(reportService)
+	trustHandler := handlers.NewTrustHandler(trustService)
+	flagHandler := handlers.NewFlagHandler(flagService)
+	appealHandler := handlers.NewAppealHandler(appealService)
 
 	// Initialize enhanced JWT manager and authorization manager
 	var jwtManager *auth.JWTManager
 	var authManager *auth.AuthorizationManager
 
+	// Notifications are delivered to this instance's connections unless Redis
+	// is available to fan them out across instances
+	var notificationBroker notification.Broker = notification.NewLocalBroker()
+
+	// Rate limit buckets are shared through Redis when it is available
+	var rateLimitRedis *redis.Client
+
 	// Initialize Redis client if enabled
 	if cfg.Redis.Enabled {
 		redisConfig := &redis.Config{
@@ -102,6 +200,11 @@ func main() {
 				cfg.Auth.JWTIssuer,
 			)
 			logger.Info("Redis connected successfully for JWT token management")
+			rateLimitRedis = redisClient
+
+			redisBroker := notification.NewRedisBroker(redisClient, notification.DefaultRedisChannel)
+			go redisBroker.Run(context.Background())
+			notificationBroker = redisBroker
 		}
 	} else {
 		jwtManager = auth.NewJWTManagerWithoutRedis(
@@ -114,6 +217,19 @@ func main() {
 
 	authManager = auth.NewAuthorizationManager()
 
+	// Initialize the notification center
+	notificationRepo := notificationrepository.NewGormNotificationRepository(db)
+	notificationService := notification.NewNotificationService(notificationRepo, notificationBroker)
+	notificationHandler := notificationhandlers.NewNotificationHandler(
+		notificationService,
+		notification.NewStreamTokens(cfg.Auth.JWTSecret, notification.DefaultStreamTokenTTL),
+	)
+	discussionService.SetNotifier(notificationService)
+	flagService.SetNotifier(notificationService)
+	appealService.SetNotifier(notificationService)
+	richTextService.SetNotifier(notificationService)
+	reportService.SetNotifier(notificationService)
+
 	// Set up Gin router with centralized error handling
 	router := gin.New()
 
@@ -123,8 +239,13 @@ func main() {
 	router.Use(middleware.RequestLogger())
 	router.Use(middleware.SecurityHeaders())
 
+	// Rate limit each route group after authentication so signed-in users are
+	// limited by user ID rather than IP
+	rateLimiter := middleware.NewRateLimiter(cfg.RateLimit, rateLimitRedis, logger)
+
 	// Public routes - can be accessed without authentication
 	public := router.Group("/public")
+	public.Use(rateLimiter.Middleware())
 	{
 		public.GET("/discussions",
 			middleware.PermissionRequired(authManager, auth.PermissionReadDiscussion, logger),
@@ -141,6 +262,7 @@ func main() {
 	discussions := router.Group("/discussions")
 	discussions.Use(middleware.AuthRequired(jwtManager, logger))
 	discussions.Use(middleware.PermissionRequired(authManager, auth.PermissionReadDiscussion, logger))
+	discussions.Use(rateLimiter.Middleware())
 	{
 		discussions.GET("/", discussionHandler.ListDiscussions)
 		discussions.GET("/:id", discussionHandler.GetDiscussion)
@@ -150,6 +272,7 @@ func main() {
 	// Discussion participation routes - require authentication and create permission
 	participate := router.Group("/participate")
 	participate.Use(middleware.AuthRequired(jwtManager, logger))
+	participate.Use(rateLimiter.Middleware())
 	{
 		participate.POST("/discussions",
 			middleware.PermissionRequired(authManager, auth.PermissionCreateDiscussion, logger),
@@ -180,10 +303,26 @@ func main() {
 			likeHandler.LikeComment)
 	}
 
+	// Notification center routes - require authentication, except the stream,
+	// which browsers open with a short-lived stream token since EventSource
+	// cannot send an Authorization header
+	notificationHandler.RegisterRoutes(router.Group("/", middleware.AuthRequired(jwtManager, logger), rateLimiter.Middleware()))
+	notificationHandler.RegisterStreamRoutes(router.Group("/", rateLimiter.Middleware()))
+
+	// Rich text, content report, trust level, flagging and appeal routes -
+	// require authentication
+	authenticated := router.Group("/", middleware.AuthRequired(jwtManager, logger), rateLimiter.Middleware())
+	richTextHandler.RegisterRoutes(authenticated)
+	reportHandler.RegisterRoutes(authenticated)
+	trustHandler.RegisterRoutes(authenticated)
+	flagHandler.RegisterRoutes(authenticated)
+	appealHandler.RegisterRoutes(authenticated)
+
 	// Moderation routes - require moderator role and moderation permissions
 	moderate := router.Group("/moderate")
 	moderate.Use(middleware.AuthRequired(jwtManager, logger))
 	moderate.Use(middleware.RoleRequired(int(auth.RoleModerator), logger))
+	moderate.Use(rateLimiter.Middleware())
 	{
 		moderate.DELETE("/discussions/:id",
 			middleware.PermissionRequired(authManager, auth.PermissionModerateDiscussion, logger),
@@ -203,10 +342,32 @@ func main() {
 			})
 	}
 
+	// Report review routes - require moderator role
+	reportHandler.RegisterModerationRoutes(router.Group("/",
+		middleware.AuthRequired(jwtManager, logger),
+		middleware.RoleRequired(int(auth.RoleModerator), logger),
+		rateLimiter.Middleware(),
+	))
+
+	// Moderation tools - require moderator role
+	moderation := router.Group("/",
+		middleware.AuthRequired(jwtManager, logger),
+		middleware.RoleRequired(int(auth.RoleModerator), logger),
+		rateLimiter.Middleware(),
+	)
+	handlers.NewModerationHandler(moderationService).
+		WithSpamService(spamService).
+		WithAppealService(appealService).
+		RegisterRoutes(moderation)
+	trustHandler.RegisterModerationRoutes(moderation)
+	flagHandler.RegisterModerationRoutes(moderation)
+	appealHandler.RegisterModerationRoutes(moderation)
+
 	// Admin discussion routes - require admin permissions
 	admin := router.Group("/admin")
 	admin.Use(middleware.AuthRequired(jwtManager, logger))
 	admin.Use(middleware.RoleRequired(int(auth.RoleAdmin), logger))
+	admin.Use(rateLimiter.Middleware())
 	{
 		admin.GET("/discussions/stats", func(c *gin.Context) {
 			c.JSON(501, gin.H{"message": "Discussion stats not yet implemented"})
@@ -0,0 +1,160 @@
+package main
+
+import (
+	"context"
+	"log"
+	"os"
+
+	"github.com/gin-gonic/gin"
+	"github.com/joho/godotenv"
+	"github.com/yerenwgventures/GreatNigeriaLibrary-Foundation/backend/pkg/common/auth"
+	"github.com/yerenwgventures/GreatNigeriaLibrary-Foundation/backend/pkg/common/config"
+	"github.com/yerenwgventures/GreatNigeriaLibrary-Foundation/backend/pkg/common/database"
+	"github.com/yerenwgventures/GreatNigeriaLibrary-Foundation/backend/pkg/common/logger"
+	"github.com/yerenwgventures/GreatNigeriaLibrary-Foundation/backend/pkg/common/mailer"
+	"github.com/yerenwgventures/GreatNigeriaLibrary-Foundation/backend/pkg/common/middleware"
+	"github.com/yerenwgventures/GreatNigeriaLibrary-Foundation/backend/pkg/common/redis"
+	"github.com/yerenwgventures/GreatNigeriaLibrary-Foundation/backend/services/groups/handler"
+	"github.com/yerenwgventures/GreatNigeriaLibrary-Foundation/backend/services/groups/repository"
+	"github.com/yerenwgventures/GreatNigeriaLibrary-Foundation/backend/services/groups/service"
+	notificationrepository "github.com/yerenwgventures/GreatNigeriaLibrary-Foundation/backend/services/notification/repository"
+	notification "github.com/yerenwgventures/GreatNigeriaLibrary-Foundation/backend/services/notification/service"
+)
+
+func main() {
+	// Load environment variables
+	if err := godotenv.Load(); err != nil {
+		log.Println("Warning: .env file not found")
+	}
+
+	// Load configuration (YAML with environment overrides)
+	cfg, err := config.LoadFromYAML("config.yaml")
+	if err != nil {
+		// Fallback to environment-only configuration
+		log.Println("YAML config not found, using environment variables only")
+		cfg, err = config.LoadConfig()
+		if err != nil {
+			log.Fatal("Failed to load configuration: " + err.Error())
+		}
+	}
+
+	// Initialize logger
+	appLogger := logger.New(logger.ParseLogLevel(cfg.Logging.Level))
+	appLogger.Info("Starting Great Nigeria Groups Service")
+
+	// Connect to database
+	db, err := database.NewDatabase(cfg)
+	if err != nil {
+		appLogger.Fatal("Failed to connect to database: " + err.Error())
+	}
+
+	// Apply versioned schema migrations
+	migrationService := database.NewMigrationService(db, appLogger)
+	if err := migrationService.Migrate(); err != nil {
+		appLogger.Fatal("Failed to run database migrations: " + err.Error())
+	}
+
+	// Invitations and calendar invites are emailed through the shared email queue
+	mailQueue := mailer.NewQueue(db, mailer.New(cfg.Email))
+	emailTemplates, err := mailer.NewTemplates(cfg.Email.FromName)
+	if err != nil {
+		appLogger.Fatal("Failed to load email templates: " + err.Error())
+	}
+	go mailQueue.Run(context.Background(), mailer.DefaultPollInterval)
+
+	// Initialize repository, service and handler
+	groupRepo := repository.NewGroupRepository(db)
+	groupService := service.NewGroupServiceWithMailer(groupRepo, mailQueue, emailTemplates, cfg.App.FrontendURL)
+	groupHandler := handler.NewGroupHandler(groupService)
+
+	// Notifications are stored here and pushed to connected clients by the
+	// service that holds their stream, through Redis when it is available
+	var notificationBroker notification.Broker = notification.NewLocalBroker()
+
+	// Rate limit buckets are shared through Redis when it is available
+	var rateLimitRedis *redis.Client
+
+	// Initialize the JWT manager, with token revocation if Redis is enabled
+	var jwtManager *auth.JWTManager
+	if cfg.Redis.Enabled {
+		redisConfig := &redis.Config{
+			Host:         cfg.Redis.Host,
+			Port:         cfg.Redis.Port,
+			Password:     cfg.Redis.Password,
+			Database:     cfg.Redis.Database,
+			PoolSize:     cfg.Redis.PoolSize,
+			MinIdleConns: cfg.Redis.MinIdleConns,
+			MaxRetries:   cfg.Redis.MaxRetries,
+			DialTimeout:  cfg.Redis.DialTimeout,
+			ReadTimeout:  cfg.Redis.ReadTimeout,
+			WriteTimeout: cfg.Redis.WriteTimeout,
+		}
+
+		redisClient, err := redis.NewClient(redisConfig)
+		if err != nil {
+			appLogger.WithError(err).Warn("Failed to connect to Redis, JWT features will be limited")
+			jwtManager = auth.NewJWTManagerWithoutRedis(
+				cfg.Auth.JWTSecret,
+				cfg.Auth.AccessTokenExpiration,
+				cfg.Auth.RefreshTokenExpiration,
+				cfg.Auth.JWTIssuer,
+			)
+		} else {
+			jwtManager = auth.NewJWTManager(
+				cfg.Auth.JWTSecret,
+				cfg.Auth.AccessTokenExpiration,
+				cfg.Auth.RefreshTokenExpiration,
+				redisClient.Client,
+				cfg.Auth.JWTIssuer,
+			)
+			appLogger.Info("Redis connected successfully for JWT token management")
+			rateLimitRedis = redisClient
+			notificationBroker = notification.NewRedisBroker(redisClient, notification.DefaultRedisChannel)
+		}
+	} else {
+		jwtManager = auth.NewJWTManagerWithoutRedis(
+			cfg.Auth.JWTSecret,
+			cfg.Auth.AccessTokenExpiration,
+			cfg.Auth.RefreshTokenExpiration,
+			cfg.Auth.JWTIssuer,
+		)
+	}
+
+	// Notify invited users in the notification center
+	notificationRepo := notificationrepository.NewGormNotificationRepository(db)
+	groupService.SetNotifier(notification.NewNotificationService(notificationRepo, notificationBroker))
+
+	// Set up Gin router with centralized error handling
+	mwLogger := middlewareLogger{appLogger}
+	router := gin.New()
+	router.Use(middleware.PanicRecovery(mwLogger))
+	router.Use(middleware.ErrorHandler(mwLogger))
+	router.Use(middleware.RequestLogger(mwLogger))
+	router.Use(middleware.SecurityHeaders())
+
+	// Rate limit each route group after authentication so signed-in users are
+	// limited by user ID rather than IP
+	rateLimiter := middleware.NewRateLimiter(cfg.RateLimit, rateLimitRedis, mwLogger)
+
+	router.GET("/health", func(c *gin.Context) {
+		c.JSON(200, gin.H{"status": "ok", "service": "groups-service"})
+	})
+
+	// Calendar feeds are fetched by calendar apps, authenticated by the
+	// secret in their URL instead of a session
+	groupHandler.RegisterPublicRoutes(router.Group("/", rateLimiter.Middleware()))
+
+	// Group routes - require authentication
+	groupHandler.RegisterRoutes(router.Group("/", middleware.AuthRequired(tokenValidator{jwtManager}, mwLogger), rateLimiter.Middleware()))
+
+	// Start server
+	port := os.Getenv("GROUPS_SERVICE_PORT")
+	if port == "" {
+		port = "8004" // Default port for groups service
+	}
+
+	appLogger.Info("Groups Service starting on port " + port)
+	if err := router.Run("0.0.0.0:" + port); err != nil {
+		appLogger.Fatal("Failed to start Groups Service: " + err.Error())
+	}
+}
@@ -0,0 +1,49 @@
+package main
+
+import (
+	"github.com/yerenwgventures/GreatNigeriaLibrary-Foundation/backend/pkg/common/auth"
+	"github.com/yerenwgventures/GreatNigeriaLibrary-Foundation/backend/pkg/common/logger"
+	"github.com/yerenwgventures/GreatNigeriaLibrary-Foundation/backend/pkg/common/middleware"
+)
+
+// middlewareLogger lets the shared logger satisfy middleware.Logger, whose
+// WithField methods return the interface rather than *logger.Logger
+type middlewareLogger struct {
+	*logger.Logger
+}
+
+// WithField adds a field to the logger context
+func (l middlewareLogger) WithField(key string, value interface{}) middleware.Logger {
+	return middlewareLogger{l.Logger.WithField(key, value)}
+}
+
+// WithFields adds multiple fields to the logger context
+func (l middlewareLogger) WithFields(fields map[string]interface{}) middleware.Logger {
+	return middlewareLogger{l.Logger.WithFields(fields)}
+}
+
+// tokenValidator lets the JWT manager satisfy middleware.JWTManager
+type tokenValidator struct {
+	*auth.JWTManager
+}
+
+// ValidateToken validates a token and returns its claims
+func (v tokenValidator) ValidateToken(tokenString string) (*middleware.Claims, error) {
+	claims, err := v.JWTManager.ValidateToken(tokenString)
+	if err != nil {
+		return nil, err
+	}
+
+	return &middleware.Claims{
+		UserID:       claims.UserID,
+		Username:     claims.Username,
+		Email:        claims.Email,
+		Role:         claims.Role,
+		Permissions:  claims.Permissions,
+		SessionID:    claims.SessionID,
+		TokenType:    claims.TokenType,
+		DeviceID:     claims.DeviceID,
+		IPAddress:    claims.IPAddress,
+		TokenVersion: claims.TokenVersion,
+	}, nil
+}
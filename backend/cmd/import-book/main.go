@@ -0,0 +1,156 @@
+// Command import-book imports a book manuscript into the content database.
+//
+// Usage:
+//
+//	import-book [flags] PATH
+//
+// PATH is a directory of Markdown files with a book.yaml, a zip of such a
+// directory, an EPUB or a DOCX. Run with -dry-run first to see what would
+// change.
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/joho/godotenv"
+	"github.com/yerenwgventures/GreatNigeriaLibrary-Foundation/backend/pkg/common/config"
+	"github.com/yerenwgventures/GreatNigeriaLibrary-Foundation/backend/pkg/common/database"
+	"github.com/yerenwgventures/GreatNigeriaLibrary-Foundation/backend/services/content/importer"
+	"github.com/yerenwgventures/GreatNigeriaLibrary-Foundation/backend/services/content/repository"
+	"github.com/yerenwgventures/GreatNigeriaLibrary-Foundation/backend/services/content/search"
+	"github.com/yerenwgventures/GreatNigeriaLibrary-Foundation/backend/services/content/service"
+)
+
+func main() {
+	configPath := flag.String("config", "config.yaml", "path to the YAML configuration")
+	bookID := flag.Uint("book-id", 0, "book to update instead of matching by title")
+	dryRun := flag.Bool("dry-run", false, "show the changes without writing them")
+	publish := flag.Bool("publish", false, "publish the book, chapters and sections that are created")
+	notes := flag.String("notes", "", "notes recorded on the revisions")
+	showDiff := flag.Bool("diff", false, "print the diff of every change")
+	asJSON := flag.Bool("json", false, "print the report as JSON")
+	flag.Usage = usage
+	flag.Parse()
+
+	if flag.NArg() != 1 {
+		usage()
+		os.Exit(2)
+	}
+
+	manuscript, err := readManuscript(flag.Arg(0))
+	if err != nil {
+		fail(err)
+	}
+
+	_ = godotenv.Load()
+
+	cfg, err := config.LoadFromYAML(*configPath)
+	if err != nil {
+		cfg, err = config.LoadConfig()
+		if err != nil {
+			fail(fmt.Errorf("failed to load configuration: %w", err))
+		}
+	}
+
+	db, err := database.NewDatabase(cfg)
+	if err != nil {
+		fail(err)
+	}
+
+	bookRepo := repository.NewBookRepository(db)
+	citationRepo := repository.NewCitationRepository(db)
+	searchIndexer := service.NewSearchIndexer(
+		search.NewPostgresIndex(db),
+		bookRepo,
+		citationRepo,
+		repository.NewGormDiscussionRepository(db),
+	)
+	adminService := service.NewContentAdminService(
+		bookRepo,
+		repository.NewGormChapterRepository(db),
+		repository.NewGormSectionRepository(db),
+		searchIndexer,
+	)
+	importService := service.NewBookImportService(bookRepo, citationRepo, adminService)
+
+	report, err := importService.ImportManuscript(manuscript, service.ImportOptions{
+		BookID:  *bookID,
+		DryRun:  *dryRun,
+		Publish: *publish,
+		Notes:   *notes,
+	})
+	if err != nil {
+		fail(err)
+	}
+
+	if *asJSON {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(report); err != nil {
+			fail(err)
+		}
+		return
+	}
+	if err := printReport(report, *showDiff); err != nil {
+		fail(err)
+	}
+}
+
+// readManuscript parses a Markdown directory or a manuscript file
+func readManuscript(path string) (*importer.Manuscript, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	if info.IsDir() {
+		return importer.ParseMarkdown(os.DirFS(path))
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return importer.Parse(path, data)
+}
+
+// printReport writes one line per change followed by the totals
+func printReport(report *service.ImportReport, showDiff bool) error {
+	if report.DryRun {
+		fmt.Println("Dry run: nothing was written")
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ACTION\tKIND\tREF\tTITLE")
+	for _, change := range report.Changes {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", change.Action, change.Kind, change.Ref, change.Title)
+		if showDiff && change.Diff != "" {
+			w.Flush()
+			fmt.Print(change.Diff)
+		}
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+
+	fmt.Printf("Book %d: %d created, %d updated, %d unchanged, %d missing from the manuscript\n",
+		report.BookID,
+		report.Summary[service.ImportActionCreate],
+		report.Summary[service.ImportActionUpdate],
+		report.Summary[service.ImportActionUnchanged],
+		report.Summary[service.ImportActionMissing])
+	return nil
+}
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "Usage: import-book [flags] PATH")
+	flag.PrintDefaults()
+}
+
+func fail(err error) {
+	fmt.Fprintln(os.Stderr, "import-book:", err)
+	os.Exit(1)
+}
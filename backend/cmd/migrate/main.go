@@ -0,0 +1,151 @@
+// Command migrate manages the database schema.
+//
+// Usage:
+//
+//	migrate [flags] status         show applied and pending migrations
+//	migrate [flags] up [N]         apply all pending migrations, or the next N
+//	migrate [flags] down [N]       roll back the last migration, or the last N
+//	migrate [flags] redo           roll back and reapply the last migration
+//	migrate [flags] seed           load the demo data
+//	migrate [flags] create NAME    add an empty migration to -dir
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"text/tabwriter"
+
+	"github.com/joho/godotenv"
+	"github.com/yerenwgventures/GreatNigeriaLibrary-Foundation/backend/pkg/common/config"
+	"github.com/yerenwgventures/GreatNigeriaLibrary-Foundation/backend/pkg/common/database"
+	"github.com/yerenwgventures/GreatNigeriaLibrary-Foundation/backend/pkg/common/logger"
+)
+
+func main() {
+	configPath := flag.String("config", "config.yaml", "path to the YAML configuration")
+	dir := flag.String("dir", "pkg/common/database/migrations", "migrations directory used by create")
+	flag.Usage = usage
+	flag.Parse()
+
+	if flag.NArg() == 0 {
+		usage()
+		os.Exit(2)
+	}
+	command, args := flag.Arg(0), flag.Args()[1:]
+
+	// create only writes files and needs no database
+	if command == "create" {
+		if len(args) != 1 {
+			fail(fmt.Errorf("usage: migrate create NAME"))
+		}
+		upPath, downPath, err := database.CreateMigration(*dir, args[0])
+		if err != nil {
+			fail(err)
+		}
+		fmt.Println("Created", upPath)
+		fmt.Println("Created", downPath)
+		return
+	}
+
+	_ = godotenv.Load()
+
+	cfg, err := config.LoadFromYAML(*configPath)
+	if err != nil {
+		cfg, err = config.LoadConfig()
+		if err != nil {
+			fail(fmt.Errorf("failed to load configuration: %w", err))
+		}
+	}
+	log := logger.New(logger.ParseLogLevel(cfg.Logging.Level))
+
+	db, err := database.NewDatabase(cfg)
+	if err != nil {
+		fail(err)
+	}
+
+	migrator, err := database.NewMigrator(db, log)
+	if err != nil {
+		fail(err)
+	}
+
+	switch command {
+	case "status":
+		err = printStatus(migrator)
+	case "up":
+		var count int
+		if count, err = migrator.Up(countArg(args, 0)); err == nil {
+			fmt.Printf("Applied %d migration(s)\n", count)
+		}
+	case "down":
+		var count int
+		if count, err = migrator.Down(countArg(args, 1)); err == nil {
+			fmt.Printf("Rolled back %d migration(s)\n", count)
+		}
+	case "redo":
+		if err = migrator.Redo(); err == nil {
+			fmt.Println("Reapplied the last migration")
+		}
+	case "seed":
+		var count int
+		if count, err = migrator.Seed(); err == nil {
+			fmt.Printf("Loaded %d seed file(s)\n", count)
+		}
+	default:
+		usage()
+		os.Exit(2)
+	}
+
+	if err != nil {
+		fail(err)
+	}
+}
+
+// printStatus writes one line per migration
+func printStatus(migrator *database.Migrator) error {
+	statuses, err := migrator.Status()
+	if err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "VERSION\tNAME\tSTATUS\tAPPLIED AT")
+	for _, s := range statuses {
+		state, appliedAt := "pending", ""
+		if s.Applied {
+			state = "applied"
+			appliedAt = s.AppliedAt.Local().Format("2006-01-02 15:04:05")
+		}
+		if s.Modified {
+			state += " (modified)"
+		}
+		if s.Missing {
+			state += " (file missing)"
+		}
+		fmt.Fprintf(w, "%04d\t%s\t%s\t%s\n", s.Version, s.Name, state, appliedAt)
+	}
+	return w.Flush()
+}
+
+// countArg parses the optional N argument of up and down
+func countArg(args []string, defaultValue int) int {
+	if len(args) == 0 {
+		return defaultValue
+	}
+	n, err := strconv.Atoi(args[0])
+	if err != nil || n < 1 {
+		fail(fmt.Errorf("invalid count %q", args[0]))
+	}
+	return n
+}
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "Usage: migrate [flags] status|up [N]|down [N]|redo|seed|create NAME")
+	flag.PrintDefaults()
+}
+
+func fail(err error) {
+	fmt.Fprintln(os.Stderr, "migrate:", err)
+	os.Exit(1)
+}
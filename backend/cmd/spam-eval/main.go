@@ -0,0 +1,132 @@
+// Command spam-eval measures how well the discussion spam classifier would
+// do on the posts moderators have labelled. It cross-validates: each fold of
+// the labelled history is scored by a model trained on the other folds, so
+// no post is scored by a model that was trained on it.
+//
+// Usage:
+//
+//	spam-eval [flags]
+//
+// It prints precision and recall at a range of spam score thresholds, to
+// help choose AutoModerationSettings.SpamScoreThreshold.
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/joho/godotenv"
+	"github.com/yerenwgventures/GreatNigeriaLibrary-Foundation/backend/pkg/common/config"
+	"github.com/yerenwgventures/GreatNigeriaLibrary-Foundation/backend/pkg/common/database"
+	"github.com/yerenwgventures/GreatNigeriaLibrary-Foundation/backend/services/discussion/classifier"
+	"github.com/yerenwgventures/GreatNigeriaLibrary-Foundation/backend/services/discussion/models"
+)
+
+func main() {
+	configPath := flag.String("config", "config.yaml", "path to the YAML configuration")
+	folds := flag.Int("folds", 5, "number of cross-validation folds")
+	limit := flag.Int("limit", 50000, "most recently labelled posts to evaluate on")
+	thresholds := flag.String("thresholds", "0.5,0.6,0.7,0.8,0.9", "comma-separated spam score thresholds")
+	flag.Usage = usage
+	flag.Parse()
+
+	if flag.NArg() != 0 {
+		usage()
+		os.Exit(2)
+	}
+	levels, err := parseThresholds(*thresholds)
+	if err != nil {
+		fail(err)
+	}
+
+	_ = godotenv.Load()
+
+	cfg, err := config.LoadFromYAML(*configPath)
+	if err != nil {
+		cfg, err = config.LoadConfig()
+		if err != nil {
+			fail(fmt.Errorf("failed to load configuration: %w", err))
+		}
+	}
+
+	db, err := database.NewDatabase(cfg)
+	if err != nil {
+		fail(err)
+	}
+
+	var labelled []models.PostClassification
+	err = db.Where("label <> ''").Order("labeled_at DESC").Limit(*limit).Find(&labelled).Error
+	if err != nil {
+		fail(fmt.Errorf("failed to load labelled posts: %w", err))
+	}
+
+	examples, spam := examplesOf(labelled)
+	fmt.Printf("%d labelled posts: %d spam, %d legitimate\n", len(examples), spam, len(examples)-spam)
+
+	metrics, err := classifier.CrossValidate(examples, *folds, levels...)
+	if err != nil {
+		fail(err)
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(w, "THRESHOLD\tPRECISION\tRECALL\tF1\tACCURACY\tTP\tFP\tFN\tTN\t")
+	for i, m := range metrics {
+		fmt.Fprintf(w, "%.2f\t%.3f\t%.3f\t%.3f\t%.3f\t%d\t%d\t%d\t%d\t\n",
+			levels[i], m.Precision(), m.Recall(), m.F1(), m.Accuracy(),
+			m.TruePositives, m.FalsePositives, m.FalseNegatives, m.TrueNegatives)
+	}
+	if err := w.Flush(); err != nil {
+		fail(err)
+	}
+}
+
+// examplesOf turns labelled classifications into examples and counts the spam
+func examplesOf(labelled []models.PostClassification) ([]classifier.Example, int) {
+	var examples []classifier.Example
+	spam := 0
+	for _, c := range labelled {
+		var features []string
+		if err := json.Unmarshal([]byte(c.Features), &features); err != nil {
+			fmt.Fprintf(os.Stderr, "spam-eval: skipping classification %d: %v\n", c.ID, err)
+			continue
+		}
+		example := classifier.Example{
+			Features:  features,
+			Heuristic: c.HeuristicScore,
+			Spam:      c.Label == models.LabelSpam,
+		}
+		if example.Spam {
+			spam++
+		}
+		examples = append(examples, example)
+	}
+	return examples, spam
+}
+
+// parseThresholds parses the -thresholds flag
+func parseThresholds(list string) ([]float64, error) {
+	var thresholds []float64
+	for _, field := range strings.Split(list, ",") {
+		t, err := strconv.ParseFloat(strings.TrimSpace(field), 64)
+		if err != nil || t < 0 || t > 1 {
+			return nil, fmt.Errorf("invalid threshold %q", field)
+		}
+		thresholds = append(thresholds, t)
+	}
+	return thresholds, nil
+}
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "Usage: spam-eval [flags]")
+	flag.PrintDefaults()
+}
+
+func fail(err error) {
+	fmt.Fprintln(os.Stderr, "spam-eval:", err)
+	os.Exit(1)
+}
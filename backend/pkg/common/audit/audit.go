@@ -0,0 +1,184 @@
+// Package audit records security and admin actions in an append-only,
+// hash-chained log shared by all services.
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"time"
+)
+
+// Entry is one record of the audit log. Every entry holds the hash of the
+// entry before it, so changing or removing an entry breaks the chain.
+type Entry struct {
+	ID         uint64    `gorm:"primaryKey" json:"id"`
+	Service    string    `gorm:"size:50;not null" json:"service"`
+	ActorID    uint      `gorm:"index" json:"actor_id"`                 // Zero for system actions
+	ActorName  string    `gorm:"size:100" json:"actor_name,omitempty"`  // Background job that acted, if any
+	Action     string    `gorm:"size:100;not null;index" json:"action"` // e.g. "user.role_changed"
+	TargetType string    `gorm:"size:50;not null" json:"target_type"`   // e.g. "user", "book"
+	TargetID   string    `gorm:"size:100;not null" json:"target_id"`
+	Before     string    `gorm:"type:text" json:"-"` // JSON
+	After      string    `gorm:"type:text" json:"-"` // JSON
+	Changes    string    `gorm:"type:text" json:"-"` // JSON object of changed fields
+	IPAddress  string    `gorm:"size:45" json:"ip_address,omitempty"`
+	UserAgent  string    `gorm:"size:1000" json:"user_agent,omitempty"`
+	RequestID  string    `gorm:"size:128" json:"request_id,omitempty"`
+	CreatedAt  time.Time `gorm:"not null;index" json:"created_at"`
+	PrevHash   string    `gorm:"size:64;not null" json:"prev_hash"`
+	Hash       string    `gorm:"size:64;not null;uniqueIndex" json:"hash"`
+}
+
+// TableName specifies the table name for Entry
+func (Entry) TableName() string {
+	return "audit_log"
+}
+
+// MarshalJSON embeds the before and after states and the changes as JSON
+// rather than as strings
+func (e Entry) MarshalJSON() ([]byte, error) {
+	type entry Entry
+	return json.Marshal(struct {
+		entry
+		Before  json.RawMessage `json:"before,omitempty"`
+		After   json.RawMessage `json:"after,omitempty"`
+		Changes json.RawMessage `json:"changes,omitempty"`
+	}{
+		entry:   entry(e),
+		Before:  rawJSON(e.Before),
+		After:   rawJSON(e.After),
+		Changes: rawJSON(e.Changes),
+	})
+}
+
+func rawJSON(s string) json.RawMessage {
+	if s == "" {
+		return nil
+	}
+	return json.RawMessage(s)
+}
+
+// Event describes an action to record
+type Event struct {
+	Action     string
+	TargetType string
+	TargetID   interface{} // Formatted with fmt.Sprint
+	// ActorID names the acting user when the request context does not, e.g.
+	// for actions taken on behalf of a moderator
+	ActorID uint
+	// Before and After are the target's state around the action. They are
+	// stored as JSON, along with the top-level fields that differ.
+	Before interface{}
+	After  interface{}
+}
+
+// Recorder records events of one service. A nil Recorder records nothing, so
+// services can leave auditing unconfigured in tests.
+type Recorder struct {
+	store   Store
+	service string
+	now     func() time.Time
+}
+
+// NewRecorder creates a recorder that appends to store in the name of service
+func NewRecorder(store Store, service string) *Recorder {
+	return &Recorder{
+		store:   store,
+		service: service,
+		now:     time.Now,
+	}
+}
+
+// Record appends an event to the audit log. The actor, IP address, user agent
+// and request ID are taken from ctx, see WithRequest.
+func (r *Recorder) Record(ctx context.Context, event Event) error {
+	if r == nil {
+		return nil
+	}
+
+	request := RequestFromContext(ctx)
+	entry := &Entry{
+		Service:    r.service,
+		ActorID:    request.ActorID,
+		ActorName:  request.ActorName,
+		Action:     event.Action,
+		TargetType: event.TargetType,
+		TargetID:   fmt.Sprint(event.TargetID),
+		IPAddress:  request.IPAddress,
+		UserAgent:  request.UserAgent,
+		RequestID:  request.RequestID,
+		// The database keeps microseconds, and the hash must survive a round trip
+		CreatedAt: r.now().UTC().Truncate(time.Microsecond),
+	}
+	if event.ActorID != 0 {
+		entry.ActorID = event.ActorID
+	}
+
+	before, beforeFields, err := encodeState(event.Before)
+	if err != nil {
+		return fmt.Errorf("failed to encode state before %s: %w", event.Action, err)
+	}
+	after, afterFields, err := encodeState(event.After)
+	if err != nil {
+		return fmt.Errorf("failed to encode state after %s: %w", event.Action, err)
+	}
+	entry.Before, entry.After = before, after
+	if beforeFields != nil && afterFields != nil {
+		changes, err := json.Marshal(Diff(beforeFields, afterFields))
+		if err != nil {
+			return fmt.Errorf("failed to encode changes of %s: %w", event.Action, err)
+		}
+		entry.Changes = string(changes)
+	}
+
+	if err := r.store.Append(ctx, entry); err != nil {
+		return fmt.Errorf("failed to append %s to audit log: %w", event.Action, err)
+	}
+	return nil
+}
+
+// encodeState returns a state as JSON and, when it is an object, its fields
+func encodeState(state interface{}) (string, map[string]interface{}, error) {
+	if state == nil {
+		return "", nil, nil
+	}
+	data, err := json.Marshal(state)
+	if err != nil {
+		return "", nil, err
+	}
+
+	var fields map[string]interface{}
+	if err := json.Unmarshal(data, &fields); err != nil {
+		// Not an object, e.g. a role number, so it is compared as a whole
+		var value interface{}
+		if err := json.Unmarshal(data, &value); err != nil {
+			return "", nil, err
+		}
+		fields = map[string]interface{}{"value": value}
+	}
+	return string(data), fields, nil
+}
+
+// Change is the before and after value of a field
+type Change struct {
+	Before interface{} `json:"before"`
+	After  interface{} `json:"after"`
+}
+
+// Diff returns the top-level fields whose values differ between two states
+func Diff(before, after map[string]interface{}) map[string]Change {
+	changes := make(map[string]Change)
+	for key, value := range before {
+		if other, ok := after[key]; !ok || !reflect.DeepEqual(value, other) {
+			changes[key] = Change{Before: value, After: after[key]}
+		}
+	}
+	for key, value := range after {
+		if _, ok := before[key]; !ok {
+			changes[key] = Change{After: value}
+		}
+	}
+	return changes
+}
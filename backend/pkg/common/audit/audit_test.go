@@ -0,0 +1,256 @@
+package audit
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type role struct {
+	Role   int    `json:"role"`
+	Status string `json:"status"`
+}
+
+func newTestRecorder(store Store, start time.Time) *Recorder {
+	recorder := NewRecorder(store, "auth")
+	now := start
+	recorder.now = func() time.Time {
+		now = now.Add(time.Minute)
+		return now
+	}
+	return recorder
+}
+
+func recordRoleChanges(t *testing.T, recorder *Recorder, n int) {
+	ctx := WithRequest(context.Background(), RequestInfo{
+		ActorID:   1,
+		IPAddress: "203.0.113.7",
+		UserAgent: "test",
+		RequestID: "req-1",
+	})
+	for i := 0; i < n; i++ {
+		err := recorder.Record(ctx, Event{
+			Action:     "user.role_changed",
+			TargetType: "user",
+			TargetID:   uint(100 + i),
+			Before:     role{Role: 1, Status: "active"},
+			After:      role{Role: 2, Status: "active"},
+		})
+		require.NoError(t, err)
+	}
+}
+
+func TestRecordStoresRequestAndChanges(t *testing.T) {
+	store := NewMemoryStore()
+	recordRoleChanges(t, newTestRecorder(store, time.Now()), 1)
+
+	entries, err := store.Query(context.Background(), Filter{})
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+
+	entry := entries[0]
+	assert.Equal(t, "auth", entry.Service)
+	assert.Equal(t, uint(1), entry.ActorID)
+	assert.Equal(t, "100", entry.TargetID)
+	assert.Equal(t, "203.0.113.7", entry.IPAddress)
+	assert.Equal(t, "req-1", entry.RequestID)
+	assert.Equal(t, GenesisHash, entry.PrevHash)
+	assert.JSONEq(t, `{"role":{"before":1,"after":2}}`, entry.Changes)
+
+	data, err := json.Marshal(entry)
+	require.NoError(t, err)
+	var decoded map[string]interface{}
+	require.NoError(t, json.Unmarshal(data, &decoded))
+	assert.Equal(t, map[string]interface{}{"role": float64(1), "status": "active"}, decoded["before"])
+}
+
+func TestRecordEventActorAndScalarState(t *testing.T) {
+	store := NewMemoryStore()
+	recorder := NewRecorder(store, "discussion")
+
+	err := recorder.Record(SystemContext(context.Background(), "publisher"), Event{
+		Action:     "user.penalty_applied",
+		TargetType: "user",
+		TargetID:   7,
+		ActorID:    3,
+		Before:     "none",
+		After:      "suspension",
+	})
+	require.NoError(t, err)
+
+	entries, _ := store.Query(context.Background(), Filter{})
+	require.Len(t, entries, 1)
+	assert.Equal(t, uint(3), entries[0].ActorID)
+	assert.Equal(t, "publisher", entries[0].ActorName)
+	assert.JSONEq(t, `{"value":{"before":"none","after":"suspension"}}`, entries[0].Changes)
+}
+
+func TestNilRecorder(t *testing.T) {
+	var recorder *Recorder
+	assert.NoError(t, recorder.Record(context.Background(), Event{Action: "user.deleted"}))
+}
+
+func TestDiff(t *testing.T) {
+	changes := Diff(
+		map[string]interface{}{"a": 1, "b": "x", "c": true},
+		map[string]interface{}{"a": 1, "b": "y", "d": false},
+	)
+	assert.Equal(t, map[string]Change{
+		"b": {Before: "x", After: "y"},
+		"c": {Before: true},
+		"d": {After: false},
+	}, changes)
+}
+
+func TestVerify(t *testing.T) {
+	store := NewMemoryStore()
+	recordRoleChanges(t, newTestRecorder(store, time.Now()), 5)
+
+	result, err := Verify(context.Background(), store)
+	require.NoError(t, err)
+	assert.Equal(t, 5, result.Entries)
+	assert.Equal(t, uint64(5), result.HeadID)
+	assert.Equal(t, store.entries[4].Hash, result.HeadHash)
+}
+
+func TestVerifyDetectsTampering(t *testing.T) {
+	tests := []struct {
+		name   string
+		tamper func(store *MemoryStore)
+	}{
+		{"modified", func(store *MemoryStore) { store.entries[2].After = `{"role":5}` }},
+		{"removed", func(store *MemoryStore) {
+			store.entries = append(store.entries[:2], store.entries[3:]...)
+		}},
+		{"resealed", func(store *MemoryStore) {
+			store.entries[2].ActorID = 9
+			store.entries[2].Seal(store.entries[2].PrevHash)
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := NewMemoryStore()
+			recordRoleChanges(t, newTestRecorder(store, time.Now()), 5)
+			tt.tamper(store)
+
+			_, err := Verify(context.Background(), store)
+			assert.ErrorIs(t, err, ErrChainBroken)
+		})
+	}
+}
+
+func TestQueryFilters(t *testing.T) {
+	store := NewMemoryStore()
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	recordRoleChanges(t, newTestRecorder(store, start), 5)
+	ctx := context.Background()
+
+	entries, err := store.Query(ctx, Filter{TargetID: "102"})
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	assert.Equal(t, uint64(3), entries[0].ID)
+
+	// Entries are recorded a minute apart from 00:01
+	entries, err = store.Query(ctx, Filter{From: start.Add(2 * time.Minute), To: start.Add(4 * time.Minute)})
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+	assert.Equal(t, uint64(2), entries[0].ID)
+
+	other := uint(2)
+	entries, err = store.Query(ctx, Filter{ActorID: &other})
+	require.NoError(t, err)
+	assert.Empty(t, entries)
+
+	entries, err = store.Query(ctx, Filter{AfterID: 2, Limit: 2})
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+	assert.Equal(t, uint64(3), entries[0].ID)
+	assert.Equal(t, uint64(4), entries[1].ID)
+}
+
+func TestExport(t *testing.T) {
+	store := NewMemoryStore()
+	recordRoleChanges(t, newTestRecorder(store, time.Now()), MaxQueryLimit+2)
+
+	var buf bytes.Buffer
+	count, err := Export(context.Background(), store, Filter{}, &buf)
+	require.NoError(t, err)
+	assert.Equal(t, MaxQueryLimit+2, count)
+
+	scanner := bufio.NewScanner(&buf)
+	lines := 0
+	for scanner.Scan() {
+		var entry map[string]interface{}
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &entry))
+		lines++
+		assert.Equal(t, float64(lines), entry["id"])
+	}
+	assert.Equal(t, MaxQueryLimit+2, lines)
+}
+
+func TestRequestContext(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	c.Request = httptest.NewRequest(http.MethodPost, "/", nil)
+	c.Request.Header.Set("User-Agent", "browser")
+	c.Request.Header.Set(headerRequestID, "abc")
+	c.Request.RemoteAddr = "198.51.100.4:1234"
+	c.Set("user_id", uint(42))
+
+	info := RequestFromContext(RequestContext(c))
+	assert.Equal(t, RequestInfo{
+		ActorID:   42,
+		IPAddress: "198.51.100.4",
+		UserAgent: "browser",
+		RequestID: "abc",
+	}, info)
+}
+
+func TestHandler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	store := NewMemoryStore()
+	recordRoleChanges(t, newTestRecorder(store, time.Now()), 3)
+
+	handler := NewHandler(store)
+	router := gin.New()
+	router.GET("/admin/audit", handler.Query)
+	router.GET("/admin/audit/export", handler.Export)
+	router.GET("/admin/audit/verify", handler.Verify)
+
+	get := func(path string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+		return w
+	}
+
+	w := get("/admin/audit?actor_id=1&limit=2")
+	require.Equal(t, http.StatusOK, w.Code)
+	var page struct {
+		Entries     []map[string]interface{} `json:"entries"`
+		NextAfterID uint64                   `json:"next_after_id"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &page))
+	assert.Len(t, page.Entries, 2)
+	assert.Equal(t, uint64(2), page.NextAfterID)
+
+	assert.Equal(t, http.StatusBadRequest, get("/admin/audit?from=yesterday").Code)
+
+	w = get("/admin/audit/export?target_type=user")
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "application/x-ndjson", w.Header().Get("Content-Type"))
+	assert.Equal(t, 3, bytes.Count(w.Body.Bytes(), []byte("\n")))
+
+	assert.Equal(t, http.StatusOK, get("/admin/audit/verify").Code)
+	store.entries[1].TargetID = "1"
+	assert.Equal(t, http.StatusConflict, get("/admin/audit/verify").Code)
+}
@@ -0,0 +1,77 @@
+package audit
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// GenesisHash is the previous hash of the first entry
+const GenesisHash = "0000000000000000000000000000000000000000000000000000000000000000"
+
+// ErrChainBroken is returned when an entry was changed, removed or inserted
+var ErrChainBroken = errors.New("audit log hash chain is broken")
+
+// Seal links an entry to the hash of the entry before it and computes its
+// own hash
+func (e *Entry) Seal(prevHash string) {
+	e.PrevHash = prevHash
+	e.Hash = e.computeHash()
+}
+
+// computeHash hashes every recorded field of an entry and its previous hash.
+// The ID is left out because the database assigns it after sealing.
+func (e *Entry) computeHash() string {
+	payload, _ := json.Marshal([]interface{}{
+		e.PrevHash,
+		e.Service,
+		e.ActorID,
+		e.ActorName,
+		e.Action,
+		e.TargetType,
+		e.TargetID,
+		e.Before,
+		e.After,
+		e.Changes,
+		e.IPAddress,
+		e.UserAgent,
+		e.RequestID,
+		e.CreatedAt.UTC().Format(time.RFC3339Nano),
+	})
+	sum := sha256.Sum256(payload)
+	return hex.EncodeToString(sum[:])
+}
+
+// VerifyResult summarizes a verified chain
+type VerifyResult struct {
+	Entries  int    `json:"entries"`
+	HeadID   uint64 `json:"head_id"`
+	HeadHash string `json:"head_hash"` // Keep it elsewhere to detect removal of the latest entries
+}
+
+// Verify walks the whole log in order and checks every entry's hash and its
+// link to the entry before it. It returns ErrChainBroken naming the first
+// entry that does not verify.
+func Verify(ctx context.Context, store Store) (*VerifyResult, error) {
+	result := &VerifyResult{HeadHash: GenesisHash}
+	err := Each(ctx, store, Filter{}, func(entry *Entry) error {
+		if entry.PrevHash != result.HeadHash {
+			return fmt.Errorf("%w: entry %d does not link to entry %d", ErrChainBroken, entry.ID, result.HeadID)
+		}
+		if entry.Hash != entry.computeHash() {
+			return fmt.Errorf("%w: entry %d was modified", ErrChainBroken, entry.ID)
+		}
+		result.Entries++
+		result.HeadID = entry.ID
+		result.HeadHash = entry.Hash
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
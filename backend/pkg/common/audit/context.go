@@ -0,0 +1,68 @@
+package audit
+
+import (
+	"context"
+
+	"github.com/gin-gonic/gin"
+)
+
+// headerRequestID is the request ID header the API gateway forwards, see
+// gateway.HeaderRequestID
+const headerRequestID = "X-Request-ID"
+
+// RequestInfo describes who acted and from where
+type RequestInfo struct {
+	ActorID   uint
+	ActorName string
+	IPAddress string
+	UserAgent string
+	RequestID string
+}
+
+type requestInfoKey struct{}
+
+// WithRequest returns a context that records events as done by info
+func WithRequest(ctx context.Context, info RequestInfo) context.Context {
+	return context.WithValue(ctx, requestInfoKey{}, info)
+}
+
+// RequestFromContext returns the request info stored with WithRequest
+func RequestFromContext(ctx context.Context) RequestInfo {
+	if ctx == nil {
+		return RequestInfo{}
+	}
+	info, _ := ctx.Value(requestInfoKey{}).(RequestInfo)
+	return info
+}
+
+// RequestContext returns the context of a request carrying the signed-in
+// user, client IP, user agent and request ID. Handlers pass it to the service
+// methods that record events.
+func RequestContext(c *gin.Context) context.Context {
+	info := RequestInfo{
+		IPAddress: c.ClientIP(),
+		UserAgent: c.Request.UserAgent(),
+		RequestID: c.GetString("request_id"),
+	}
+	if info.RequestID == "" {
+		info.RequestID = c.GetHeader(headerRequestID)
+	}
+
+	// The auth middleware sets user_id; some handlers still use userID
+	for _, key := range []string{"user_id", "userID"} {
+		if userID, ok := c.Get(key); ok {
+			if id, ok := userID.(uint); ok {
+				info.ActorID = id
+				break
+			}
+		}
+	}
+
+	return WithRequest(c.Request.Context(), info)
+}
+
+// SystemContext returns a context that records events as done by a
+// background job rather than a user
+func SystemContext(ctx context.Context, job string) context.Context {
+	return WithRequest(ctx, RequestInfo{ActorName: job})
+}
@@ -0,0 +1,197 @@
+package audit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Query limits
+const (
+	DefaultQueryLimit = 100
+	MaxQueryLimit     = 1000
+)
+
+// chainLockID is the Postgres advisory lock that serializes appends, so
+// services and replicas extend one chain in ID order
+const chainLockID = 727361
+
+// Filter selects audit log entries. Zero fields match everything.
+type Filter struct {
+	ActorID    *uint
+	Action     string
+	Service    string
+	TargetType string
+	TargetID   string
+	From       time.Time // Inclusive
+	To         time.Time // Exclusive
+	AfterID    uint64    // Cursor: only entries after this ID
+	Limit      int
+}
+
+func (f Filter) limit() int {
+	if f.Limit <= 0 {
+		return DefaultQueryLimit
+	}
+	if f.Limit > MaxQueryLimit {
+		return MaxQueryLimit
+	}
+	return f.Limit
+}
+
+func (f Filter) matches(e *Entry) bool {
+	return (f.ActorID == nil || e.ActorID == *f.ActorID) &&
+		(f.Action == "" || e.Action == f.Action) &&
+		(f.Service == "" || e.Service == f.Service) &&
+		(f.TargetType == "" || e.TargetType == f.TargetType) &&
+		(f.TargetID == "" || e.TargetID == f.TargetID) &&
+		(f.From.IsZero() || !e.CreatedAt.Before(f.From)) &&
+		(f.To.IsZero() || e.CreatedAt.Before(f.To)) &&
+		e.ID > f.AfterID
+}
+
+// Store persists the audit log. It only appends; there is no way to change
+// or remove entries.
+type Store interface {
+	// Append seals an entry onto the end of the chain and stores it
+	Append(ctx context.Context, entry *Entry) error
+	// Query returns matching entries in ID order
+	Query(ctx context.Context, filter Filter) ([]Entry, error)
+}
+
+// Each calls fn for every matching entry in ID order, a page at a time
+func Each(ctx context.Context, store Store, filter Filter, fn func(*Entry) error) error {
+	filter.Limit = MaxQueryLimit
+	for {
+		entries, err := store.Query(ctx, filter)
+		if err != nil {
+			return err
+		}
+		for i := range entries {
+			if err := fn(&entries[i]); err != nil {
+				return err
+			}
+		}
+		if len(entries) < filter.Limit {
+			return nil
+		}
+		filter.AfterID = entries[len(entries)-1].ID
+	}
+}
+
+// GormStore keeps the audit log in the audit_log table. A trigger created by
+// the migration rejects updates and deletes.
+type GormStore struct {
+	db *gorm.DB
+}
+
+// NewGormStore creates a new database-backed audit store
+func NewGormStore(db *gorm.DB) *GormStore {
+	return &GormStore{db: db}
+}
+
+// Append seals an entry onto the end of the chain and stores it
+func (s *GormStore) Append(ctx context.Context, entry *Entry) error {
+	return s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if tx.Dialector.Name() == "postgres" {
+			if err := tx.Exec("SELECT pg_advisory_xact_lock(?)", chainLockID).Error; err != nil {
+				return fmt.Errorf("failed to lock audit log: %w", err)
+			}
+		}
+
+		prevHash := GenesisHash
+		var last Entry
+		err := tx.Select("hash").Order("id DESC").Take(&last).Error
+		switch {
+		case errors.Is(err, gorm.ErrRecordNotFound):
+		case err != nil:
+			return fmt.Errorf("failed to load last audit entry: %w", err)
+		default:
+			prevHash = last.Hash
+		}
+
+		entry.Seal(prevHash)
+		return tx.Create(entry).Error
+	})
+}
+
+// Query returns matching entries in ID order
+func (s *GormStore) Query(ctx context.Context, filter Filter) ([]Entry, error) {
+	query := s.db.WithContext(ctx).Model(&Entry{})
+	if filter.ActorID != nil {
+		query = query.Where("actor_id = ?", *filter.ActorID)
+	}
+	if filter.Action != "" {
+		query = query.Where("action = ?", filter.Action)
+	}
+	if filter.Service != "" {
+		query = query.Where("service = ?", filter.Service)
+	}
+	if filter.TargetType != "" {
+		query = query.Where("target_type = ?", filter.TargetType)
+	}
+	if filter.TargetID != "" {
+		query = query.Where("target_id = ?", filter.TargetID)
+	}
+	if !filter.From.IsZero() {
+		query = query.Where("created_at >= ?", filter.From)
+	}
+	if !filter.To.IsZero() {
+		query = query.Where("created_at < ?", filter.To)
+	}
+	if filter.AfterID > 0 {
+		query = query.Where("id > ?", filter.AfterID)
+	}
+
+	var entries []Entry
+	err := query.Order("id").Limit(filter.limit()).Find(&entries).Error
+	return entries, err
+}
+
+// MemoryStore keeps the audit log in memory. It is only suitable for
+// development and tests.
+type MemoryStore struct {
+	mu      sync.Mutex
+	entries []Entry
+}
+
+// NewMemoryStore creates a new in-memory audit store
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{}
+}
+
+// Append seals an entry onto the end of the chain and stores it
+func (s *MemoryStore) Append(ctx context.Context, entry *Entry) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	prevHash := GenesisHash
+	if n := len(s.entries); n > 0 {
+		prevHash = s.entries[n-1].Hash
+	}
+	entry.ID = uint64(len(s.entries) + 1)
+	entry.Seal(prevHash)
+	s.entries = append(s.entries, *entry)
+	return nil
+}
+
+// Query returns matching entries in ID order
+func (s *MemoryStore) Query(ctx context.Context, filter Filter) ([]Entry, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var entries []Entry
+	for i := range s.entries {
+		if filter.matches(&s.entries[i]) {
+			entries = append(entries, s.entries[i])
+			if len(entries) == filter.limit() {
+				break
+			}
+		}
+	}
+	return entries, nil
+}
@@ -71,6 +71,7 @@ const (
 	PermissionManageSystem   Permission = "admin:manage_system"
 	PermissionViewAnalytics  Permission = "admin:view_analytics"
 	PermissionManageSettings Permission = "admin:manage_settings"
+	PermissionViewAuditLog   Permission = "admin:view_audit_log"
 )
 
 // AuthorizationManager handles role-based access control
@@ -162,6 +163,7 @@ func (am *AuthorizationManager) initializeRolePermissions() {
 		PermissionManageContent,
 		PermissionViewAnalytics,
 		PermissionManageSettings,
+		PermissionViewAuditLog,
 	}
 
 	// SuperAdmin permissions (full system access)
@@ -190,6 +192,7 @@ func (am *AuthorizationManager) initializeRolePermissions() {
 		PermissionManageSystem,
 		PermissionViewAnalytics,
 		PermissionManageSettings,
+		PermissionViewAuditLog,
 	}
 }
 
@@ -0,0 +1,123 @@
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// ErrChallengeNotFound is returned when a challenge is unknown, expired or
+// already used
+var ErrChallengeNotFound = errors.New("challenge not found or expired")
+
+// ChallengeStore keeps short-lived, single-use values, such as OAuth login
+// states and WebAuthn ceremonies, until the client answers them
+type ChallengeStore interface {
+	Save(ctx context.Context, key string, value []byte, ttl time.Duration) error
+	// Consume returns and removes a value, so every value is used once. It
+	// returns ErrChallengeNotFound when the key is unknown or expired.
+	Consume(ctx context.Context, key string) ([]byte, error)
+}
+
+// SaveChallenge stores v as JSON until it expires
+func SaveChallenge(ctx context.Context, store ChallengeStore, key string, v interface{}, ttl time.Duration) error {
+	payload, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Errorf("failed to encode challenge: %w", err)
+	}
+	return store.Save(ctx, key, payload, ttl)
+}
+
+// ConsumeChallenge removes a value stored with SaveChallenge and decodes it
+// into v
+func ConsumeChallenge(ctx context.Context, store ChallengeStore, key string, v interface{}) error {
+	payload, err := store.Consume(ctx, key)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(payload, v); err != nil {
+		return fmt.Errorf("failed to decode challenge: %w", err)
+	}
+	return nil
+}
+
+// RedisChallengeStore keeps challenges in Redis, so the answer to a challenge
+// can reach any replica
+type RedisChallengeStore struct {
+	client *redis.Client
+}
+
+// NewRedisChallengeStore creates a new Redis challenge store
+func NewRedisChallengeStore(client *redis.Client) *RedisChallengeStore {
+	return &RedisChallengeStore{client: client}
+}
+
+// Save stores a value until it expires
+func (s *RedisChallengeStore) Save(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	return s.client.Set(ctx, key, value, ttl).Err()
+}
+
+// Consume returns and removes a value
+func (s *RedisChallengeStore) Consume(ctx context.Context, key string) ([]byte, error) {
+	value, err := s.client.GetDel(ctx, key).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, ErrChallengeNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load challenge: %w", err)
+	}
+	return value, nil
+}
+
+// MemoryChallengeStore keeps challenges in memory. It is only suitable for a
+// single replica, e.g. in development and tests.
+type MemoryChallengeStore struct {
+	mu         sync.Mutex
+	challenges map[string]memoryChallenge
+}
+
+type memoryChallenge struct {
+	value     []byte
+	expiresAt time.Time
+}
+
+// NewMemoryChallengeStore creates a new in-memory challenge store
+func NewMemoryChallengeStore() *MemoryChallengeStore {
+	return &MemoryChallengeStore{challenges: make(map[string]memoryChallenge)}
+}
+
+// Save stores a value until it expires, removing expired values
+func (s *MemoryChallengeStore) Save(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	for k, stored := range s.challenges {
+		if now.After(stored.expiresAt) {
+			delete(s.challenges, k)
+		}
+	}
+	s.challenges[key] = memoryChallenge{value: value, expiresAt: now.Add(ttl)}
+	return nil
+}
+
+// Consume returns and removes a value
+func (s *MemoryChallengeStore) Consume(ctx context.Context, key string) ([]byte, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	stored, ok := s.challenges[key]
+	if !ok {
+		return nil, ErrChallengeNotFound
+	}
+	delete(s.challenges, key)
+	if time.Now().After(stored.expiresAt) {
+		return nil, ErrChallengeNotFound
+	}
+	return stored.value, nil
+}
@@ -2,199 +2,200 @@ package auth
 
 import (
 	"context"
-	"encoding/json"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
 	"errors"
 	"fmt"
-	"io"
-	"net/http"
+	"sort"
+	"time"
 
+	"github.com/yerenwgventures/GreatNigeriaLibrary-Foundation/backend/pkg/common/config"
 	"golang.org/x/oauth2"
-	"golang.org/x/oauth2/google"
 )
 
-// OAuthManager manages OAuth authentication
-type OAuthManager struct {
-	logger Logger
-	config Config
-}
-
-// Logger interface for OAuth manager
-type Logger interface {
-	Info(msg string)
-	Error(msg string)
-	WithField(key string, value interface{}) Logger
-	WithFields(fields map[string]interface{}) Logger
-	WithError(err error) Logger
-}
+// DefaultOAuthStateTTL is how long a login started at a provider can take
+const DefaultOAuthStateTTL = 10 * time.Minute
 
-// Config interface for OAuth manager
-type Config interface {
-	GetOAuthConfig(provider string) *oauth2.Config
-}
+var (
+	// ErrUnsupportedOAuthProvider is returned for providers that are not configured
+	ErrUnsupportedOAuthProvider = errors.New("unsupported OAuth provider")
+	// ErrInvalidOAuthState is returned when the state of a callback is unknown,
+	// expired, already used or issued for another provider
+	ErrInvalidOAuthState = errors.New("invalid or expired OAuth state")
+)
 
 // OAuthUserInfo represents OAuth user information
 type OAuthUserInfo struct {
-	ID       string `json:"id"`
-	Email    string `json:"email"`
-	Name     string `json:"name"`
-	Picture  string `json:"picture"`
-	Provider string `json:"provider"`
+	ID            string `json:"id"`
+	Email         string `json:"email"`
+	EmailVerified bool   `json:"email_verified"`
+	Name          string `json:"name"`
+	Picture       string `json:"picture"`
+	Provider      string `json:"provider"`
 }
 
-// GoogleUserInfo represents Google OAuth user info
-type GoogleUserInfo struct {
-	ID      string `json:"id"`
-	Email   string `json:"email"`
-	Name    string `json:"name"`
-	Picture string `json:"picture"`
+// OAuthProvider is an OAuth 2.0 or OpenID Connect identity provider
+type OAuthProvider interface {
+	Name() string
+	DisplayName() string
+	// AuthCodeURL returns the URL the user is sent to for consent. The nonce
+	// is only sent to OpenID Connect providers.
+	AuthCodeURL(ctx context.Context, state, nonce string, opts ...oauth2.AuthCodeOption) (string, error)
+	// Exchange redeems an authorization code and returns the user it was
+	// issued for
+	Exchange(ctx context.Context, code, nonce string, opts ...oauth2.AuthCodeOption) (*OAuthUserInfo, error)
 }
 
-// NewOAuthManager creates a new OAuth manager
-func NewOAuthManager(logger Logger, config Config) *OAuthManager {
-	return &OAuthManager{
-		logger: logger,
-		config: config,
-	}
+// OAuthState is what the server remembers about a login in progress
+type OAuthState struct {
+	Provider   string    `json:"provider"`
+	Verifier   string    `json:"verifier"`
+	Nonce      string    `json:"nonce"`
+	LinkUserID uint      `json:"link_user_id,omitempty"`
+	CreatedAt  time.Time `json:"created_at"`
 }
 
-// GetAuthURL returns the OAuth authorization URL
-func (o *OAuthManager) GetAuthURL(provider, state string) (string, error) {
-	config := o.getOAuthConfig(provider)
-	if config == nil {
-		return "", errors.New("unsupported OAuth provider")
-	}
+// oauthStateKeyPrefix prefixes the challenge store keys of login states
+const oauthStateKeyPrefix = "oauth:state:"
 
-	url := config.AuthCodeURL(state, oauth2.AccessTypeOffline)
-	o.logger.WithFields(map[string]interface{}{
-		"provider": provider,
-		"state":    state,
-	}).Info("Generated OAuth authorization URL")
+// OAuthProviderInfo describes a configured provider to clients
+type OAuthProviderInfo struct {
+	Name        string `json:"name"`
+	DisplayName string `json:"display_name"`
+}
 
-	return url, nil
+// OAuthManager manages OAuth authentication with the configured providers.
+// Every login gets a one-time state holding a PKCE verifier and a nonce,
+// kept server-side in the challenge store.
+type OAuthManager struct {
+	providers map[string]OAuthProvider
+	states    ChallengeStore
+	stateTTL  time.Duration
 }
 
-// ExchangeCodeForToken exchanges authorization code for access token
-func (o *OAuthManager) ExchangeCodeForToken(provider, code string) (*oauth2.Token, error) {
-	config := o.getOAuthConfig(provider)
-	if config == nil {
-		return nil, errors.New("unsupported OAuth provider")
+// NewOAuthManager creates an OAuth manager with the providers in the config
+func NewOAuthManager(cfg config.OAuthConfig, states ChallengeStore) (*OAuthManager, error) {
+	stateTTL := cfg.StateTTL
+	if stateTTL <= 0 {
+		stateTTL = DefaultOAuthStateTTL
 	}
 
-	token, err := config.Exchange(context.Background(), code)
-	if err != nil {
-		o.logger.WithError(err).WithField("provider", provider).Error("Failed to exchange code for token")
-		return nil, err
+	manager := &OAuthManager{
+		providers: make(map[string]OAuthProvider),
+		states:    states,
+		stateTTL:  stateTTL,
+	}
+	for name, providerConfig := range cfg.Providers {
+		provider, err := NewOAuthProvider(name, providerConfig)
+		if err != nil {
+			return nil, err
+		}
+		manager.Register(provider)
 	}
+	return manager, nil
+}
 
-	o.logger.WithField("provider", provider).Info("Successfully exchanged code for token")
-	return token, nil
+// Register adds a provider, replacing any provider of the same name
+func (o *OAuthManager) Register(provider OAuthProvider) {
+	o.providers[provider.Name()] = provider
 }
 
-// GetUserInfo retrieves user information using OAuth token
-func (o *OAuthManager) GetUserInfo(provider string, token *oauth2.Token) (*OAuthUserInfo, error) {
-	switch provider {
-	case "google":
-		return o.getGoogleUserInfo(token)
-	default:
-		return nil, errors.New("unsupported OAuth provider")
+// Providers lists the configured providers by name
+func (o *OAuthManager) Providers() []OAuthProviderInfo {
+	providers := make([]OAuthProviderInfo, 0, len(o.providers))
+	for _, provider := range o.providers {
+		providers = append(providers, OAuthProviderInfo{
+			Name:        provider.Name(),
+			DisplayName: provider.DisplayName(),
+		})
 	}
+	sort.Slice(providers, func(i, j int) bool {
+		return providers[i].Name < providers[j].Name
+	})
+	return providers
 }
 
-// getGoogleUserInfo retrieves Google user information
-func (o *OAuthManager) getGoogleUserInfo(token *oauth2.Token) (*OAuthUserInfo, error) {
-	client := &http.Client{}
-	req, err := http.NewRequest("GET", "https://www.googleapis.com/oauth2/v2/userinfo", nil)
-	if err != nil {
-		return nil, err
+// GetAuthURL starts a login at the provider and returns its authorization
+// URL. A non-zero linkUserID links the identity to that user instead of
+// logging in.
+func (o *OAuthManager) GetAuthURL(ctx context.Context, providerName string, linkUserID uint) (string, error) {
+	provider, ok := o.providers[providerName]
+	if !ok {
+		return "", ErrUnsupportedOAuthProvider
 	}
 
-	req.Header.Set("Authorization", "Bearer "+token.AccessToken)
-
-	resp, err := client.Do(req)
+	state, err := randomURLToken()
 	if err != nil {
-		o.logger.WithError(err).Error("Failed to get Google user info")
-		return nil, err
+		return "", err
 	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		o.logger.WithField("status_code", resp.StatusCode).Error("Google API returned error")
-		return nil, fmt.Errorf("Google API returned status %d", resp.StatusCode)
+	nonce, err := randomURLToken()
+	if err != nil {
+		return "", err
 	}
-
-	body, err := io.ReadAll(resp.Body)
+	verifier, err := randomURLToken()
 	if err != nil {
-		return nil, err
+		return "", err
 	}
 
-	var googleUser GoogleUserInfo
-	if err := json.Unmarshal(body, &googleUser); err != nil {
-		o.logger.WithError(err).Error("Failed to unmarshal Google user info")
-		return nil, err
+	data := &OAuthState{
+		Provider:   providerName,
+		Verifier:   verifier,
+		Nonce:      nonce,
+		LinkUserID: linkUserID,
+		CreatedAt:  time.Now(),
 	}
-
-	userInfo := &OAuthUserInfo{
-		ID:       googleUser.ID,
-		Email:    googleUser.Email,
-		Name:     googleUser.Name,
-		Picture:  googleUser.Picture,
-		Provider: "google",
+	if err := SaveChallenge(ctx, o.states, oauthStateKeyPrefix+state, data, o.stateTTL); err != nil {
+		return "", fmt.Errorf("failed to store OAuth state: %w", err)
 	}
 
-	o.logger.WithField("email", userInfo.Email).Info("Retrieved Google user info")
-	return userInfo, nil
+	return provider.AuthCodeURL(ctx, state, nonce,
+		oauth2.SetAuthURLParam("code_challenge", pkceChallenge(verifier)),
+		oauth2.SetAuthURLParam("code_challenge_method", "S256"),
+	)
 }
 
-// getOAuthConfig returns OAuth configuration for provider
-func (o *OAuthManager) getOAuthConfig(provider string) *oauth2.Config {
-	switch provider {
-	case "google":
-		return &oauth2.Config{
-			ClientID:     "your-google-client-id",     // Should come from config
-			ClientSecret: "your-google-client-secret", // Should come from config
-			RedirectURL:  "http://localhost:8080/auth/google/callback",
-			Scopes: []string{
-				"https://www.googleapis.com/auth/userinfo.email",
-				"https://www.googleapis.com/auth/userinfo.profile",
-			},
-			Endpoint: google.Endpoint,
-		}
-	default:
-		return nil
+// HandleCallback consumes the state of a callback and exchanges the code for
+// the user's identity. The returned state tells a login from a link.
+func (o *OAuthManager) HandleCallback(ctx context.Context, providerName, state, code string) (*OAuthUserInfo, *OAuthState, error) {
+	provider, ok := o.providers[providerName]
+	if !ok {
+		return nil, nil, ErrUnsupportedOAuthProvider
 	}
-}
-
-// ValidateState validates OAuth state parameter
-func (o *OAuthManager) ValidateState(receivedState, expectedState string) bool {
-	return receivedState == expectedState
-}
 
-// RevokeToken revokes an OAuth token
-func (o *OAuthManager) RevokeToken(provider string, token *oauth2.Token) error {
-	switch provider {
-	case "google":
-		return o.revokeGoogleToken(token)
-	default:
-		return errors.New("unsupported OAuth provider")
+	var data OAuthState
+	err := ConsumeChallenge(ctx, o.states, oauthStateKeyPrefix+state, &data)
+	if errors.Is(err, ErrChallengeNotFound) {
+		return nil, nil, ErrInvalidOAuthState
 	}
-}
-
-// revokeGoogleToken revokes a Google OAuth token
-func (o *OAuthManager) revokeGoogleToken(token *oauth2.Token) error {
-	revokeURL := fmt.Sprintf("https://oauth2.googleapis.com/revoke?token=%s", token.AccessToken)
-	
-	resp, err := http.Post(revokeURL, "application/x-www-form-urlencoded", nil)
 	if err != nil {
-		o.logger.WithError(err).Error("Failed to revoke Google token")
-		return err
+		return nil, nil, err
+	}
+	if data.Provider != providerName {
+		return nil, nil, ErrInvalidOAuthState
 	}
-	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK {
-		o.logger.WithField("status_code", resp.StatusCode).Error("Google token revocation failed")
-		return fmt.Errorf("token revocation failed with status %d", resp.StatusCode)
+	userInfo, err := provider.Exchange(ctx, code, data.Nonce,
+		oauth2.SetAuthURLParam("code_verifier", data.Verifier),
+	)
+	if err != nil {
+		return nil, nil, err
 	}
+	return userInfo, &data, nil
+}
+
+// pkceChallenge returns the S256 code challenge for a PKCE verifier
+func pkceChallenge(verifier string) string {
+	sum := sha256.Sum256([]byte(verifier))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
 
-	o.logger.Info("Successfully revoked Google token")
-	return nil
+// randomURLToken returns 32 random bytes encoded for use in URLs. The 43
+// characters are also a valid PKCE verifier.
+func randomURLToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate random token: %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
 }
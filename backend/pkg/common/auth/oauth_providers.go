@@ -0,0 +1,348 @@
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/yerenwgventures/GreatNigeriaLibrary-Foundation/backend/pkg/common/config"
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/facebook"
+	"golang.org/x/oauth2/github"
+)
+
+// OAuth provider types
+const (
+	OAuthProviderGoogle   = "google"
+	OAuthProviderGitHub   = "github"
+	OAuthProviderFacebook = "facebook"
+	OAuthProviderOIDC     = "oidc"
+)
+
+const (
+	googleIssuer    = "https://accounts.google.com"
+	githubUserURL   = "https://api.github.com/user"
+	facebookUserURL = "https://graph.facebook.com/me?fields=id,name,email,picture.type(large)"
+)
+
+// oauthHTTPTimeout bounds every request to a provider
+const oauthHTTPTimeout = 10 * time.Second
+
+// oauthProvider is an OAuth 2.0 provider. Providers with an issuer are
+// OpenID Connect providers: their endpoints are discovered and users are
+// read from the verified ID token. Other providers read users from their
+// own APIs.
+type oauthProvider struct {
+	name        string
+	displayName string
+	config      oauth2.Config
+	userInfoURL string
+	issuer      *oidcIssuer
+	fetchUser   func(ctx context.Context, token *oauth2.Token) (*OAuthUserInfo, error)
+	client      *http.Client
+}
+
+// NewOAuthProvider creates a provider from its configuration. The type
+// defaults to the provider name, so a provider named github needs no type.
+func NewOAuthProvider(name string, cfg config.OAuthProviderConfig) (OAuthProvider, error) {
+	providerType := cfg.Type
+	if providerType == "" {
+		providerType = name
+	}
+	if cfg.ClientID == "" {
+		return nil, fmt.Errorf("OAuth provider %s has no client ID", name)
+	}
+
+	p := &oauthProvider{
+		name:        name,
+		displayName: cfg.DisplayName,
+		config: oauth2.Config{
+			ClientID:     cfg.ClientID,
+			ClientSecret: cfg.ClientSecret,
+			RedirectURL:  cfg.RedirectURL,
+			Scopes:       cfg.Scopes,
+		},
+		userInfoURL: cfg.UserInfoURL,
+		client:      &http.Client{Timeout: oauthHTTPTimeout},
+	}
+
+	var defaultName, defaultUserInfoURL string
+	var defaultScopes []string
+	switch providerType {
+	case OAuthProviderGoogle:
+		issuer := cfg.Issuer
+		if issuer == "" {
+			issuer = googleIssuer
+		}
+		p.issuer = newOIDCIssuer(issuer, p.client)
+		defaultName = "Google"
+		defaultScopes = []string{"openid", "email", "profile"}
+	case OAuthProviderGitHub:
+		p.config.Endpoint = github.Endpoint
+		p.fetchUser = p.githubUser
+		defaultName = "GitHub"
+		defaultUserInfoURL = githubUserURL
+		defaultScopes = []string{"read:user", "user:email"}
+	case OAuthProviderFacebook:
+		p.config.Endpoint = facebook.Endpoint
+		p.fetchUser = p.facebookUser
+		defaultName = "Facebook"
+		defaultUserInfoURL = facebookUserURL
+		defaultScopes = []string{"email", "public_profile"}
+	case OAuthProviderOIDC:
+		if cfg.Issuer == "" {
+			return nil, fmt.Errorf("OIDC provider %s has no issuer", name)
+		}
+		p.issuer = newOIDCIssuer(cfg.Issuer, p.client)
+		defaultName = name
+		defaultScopes = []string{"openid", "email", "profile"}
+	default:
+		return nil, fmt.Errorf("OAuth provider %s has unknown type %q", name, providerType)
+	}
+
+	if p.displayName == "" {
+		p.displayName = defaultName
+	}
+	if p.userInfoURL == "" {
+		p.userInfoURL = defaultUserInfoURL
+	}
+	if len(p.config.Scopes) == 0 {
+		p.config.Scopes = defaultScopes
+	}
+	if cfg.AuthURL != "" {
+		p.config.Endpoint.AuthURL = cfg.AuthURL
+	}
+	if cfg.TokenURL != "" {
+		p.config.Endpoint.TokenURL = cfg.TokenURL
+	}
+	return p, nil
+}
+
+// Name returns the provider name used in URLs
+func (p *oauthProvider) Name() string {
+	return p.name
+}
+
+// DisplayName returns the provider name shown to users
+func (p *oauthProvider) DisplayName() string {
+	return p.displayName
+}
+
+// AuthCodeURL returns the URL the user is sent to for consent
+func (p *oauthProvider) AuthCodeURL(ctx context.Context, state, nonce string, opts ...oauth2.AuthCodeOption) (string, error) {
+	cfg, err := p.oauthConfig(ctx)
+	if err != nil {
+		return "", err
+	}
+	if p.issuer != nil {
+		opts = append(opts, oauth2.SetAuthURLParam("nonce", nonce))
+	}
+	return cfg.AuthCodeURL(state, opts...), nil
+}
+
+// Exchange redeems an authorization code and returns the user it was issued for
+func (p *oauthProvider) Exchange(ctx context.Context, code, nonce string, opts ...oauth2.AuthCodeOption) (*OAuthUserInfo, error) {
+	cfg, err := p.oauthConfig(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	token, err := cfg.Exchange(context.WithValue(ctx, oauth2.HTTPClient, p.client), code, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to exchange OAuth code: %w", err)
+	}
+
+	var userInfo *OAuthUserInfo
+	if p.issuer != nil {
+		userInfo, err = p.oidcUser(ctx, token, nonce)
+	} else {
+		userInfo, err = p.fetchUser(ctx, token)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if userInfo.ID == "" {
+		return nil, fmt.Errorf("OAuth provider %s returned no user ID", p.name)
+	}
+	userInfo.Provider = p.name
+	return userInfo, nil
+}
+
+// oauthConfig returns the OAuth 2.0 config, with the endpoints of OpenID
+// Connect providers taken from discovery unless configured
+func (p *oauthProvider) oauthConfig(ctx context.Context) (*oauth2.Config, error) {
+	cfg := p.config
+	if p.issuer == nil {
+		return &cfg, nil
+	}
+
+	metadata, err := p.issuer.discover(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if cfg.Endpoint.AuthURL == "" {
+		cfg.Endpoint.AuthURL = metadata.AuthorizationEndpoint
+	}
+	if cfg.Endpoint.TokenURL == "" {
+		cfg.Endpoint.TokenURL = metadata.TokenEndpoint
+	}
+	return &cfg, nil
+}
+
+// oidcUser reads the user from the ID token, and from the userinfo endpoint
+// when the token has no email or name
+func (p *oauthProvider) oidcUser(ctx context.Context, token *oauth2.Token, nonce string) (*OAuthUserInfo, error) {
+	rawIDToken, _ := token.Extra("id_token").(string)
+	if rawIDToken == "" {
+		return nil, fmt.Errorf("OAuth provider %s returned no ID token", p.name)
+	}
+
+	claims, err := p.issuer.verify(ctx, rawIDToken, p.config.ClientID, nonce)
+	if err != nil {
+		return nil, err
+	}
+	userInfo := &OAuthUserInfo{
+		ID:            claims.Subject,
+		Email:         claims.Email,
+		EmailVerified: bool(claims.EmailVerified),
+		Name:          claims.Name,
+		Picture:       claims.Picture,
+	}
+	if userInfo.Email != "" && userInfo.Name != "" {
+		return userInfo, nil
+	}
+
+	userInfoURL := p.userInfoURL
+	if userInfoURL == "" {
+		metadata, err := p.issuer.discover(ctx)
+		if err != nil {
+			return nil, err
+		}
+		userInfoURL = metadata.UserInfoEndpoint
+	}
+	if userInfoURL == "" {
+		return userInfo, nil
+	}
+
+	var profile oidcUserInfo
+	if err := getJSON(ctx, p.client, userInfoURL, token, &profile); err != nil {
+		return nil, err
+	}
+	// The userinfo response must be about the user in the ID token
+	if profile.Subject != claims.Subject {
+		return nil, errors.New("userinfo subject does not match the ID token")
+	}
+	if userInfo.Email == "" {
+		userInfo.Email = profile.Email
+		userInfo.EmailVerified = bool(profile.EmailVerified)
+	}
+	if userInfo.Name == "" {
+		userInfo.Name = profile.Name
+	}
+	if userInfo.Picture == "" {
+		userInfo.Picture = profile.Picture
+	}
+	return userInfo, nil
+}
+
+// githubUser reads the user and their primary email from the GitHub API
+func (p *oauthProvider) githubUser(ctx context.Context, token *oauth2.Token) (*OAuthUserInfo, error) {
+	var user struct {
+		ID        int64  `json:"id"`
+		Login     string `json:"login"`
+		Name      string `json:"name"`
+		Email     string `json:"email"`
+		AvatarURL string `json:"avatar_url"`
+	}
+	if err := getJSON(ctx, p.client, p.userInfoURL, token, &user); err != nil {
+		return nil, err
+	}
+
+	// The profile email is public and may be unverified; the emails API
+	// tells which address is primary and verified
+	var emails []struct {
+		Email    string `json:"email"`
+		Primary  bool   `json:"primary"`
+		Verified bool   `json:"verified"`
+	}
+	if err := getJSON(ctx, p.client, p.userInfoURL+"/emails", token, &emails); err != nil {
+		return nil, err
+	}
+
+	userInfo := &OAuthUserInfo{
+		Name:    user.Name,
+		Email:   user.Email,
+		Picture: user.AvatarURL,
+	}
+	if user.ID != 0 {
+		userInfo.ID = strconv.FormatInt(user.ID, 10)
+	}
+	if userInfo.Name == "" {
+		userInfo.Name = user.Login
+	}
+	for _, email := range emails {
+		if email.Primary {
+			userInfo.Email = email.Email
+			userInfo.EmailVerified = email.Verified
+			break
+		}
+	}
+	return userInfo, nil
+}
+
+// facebookUser reads the user from the Facebook Graph API
+func (p *oauthProvider) facebookUser(ctx context.Context, token *oauth2.Token) (*OAuthUserInfo, error) {
+	var user struct {
+		ID      string `json:"id"`
+		Name    string `json:"name"`
+		Email   string `json:"email"`
+		Picture struct {
+			Data struct {
+				URL string `json:"url"`
+			} `json:"data"`
+		} `json:"picture"`
+	}
+	if err := getJSON(ctx, p.client, p.userInfoURL, token, &user); err != nil {
+		return nil, err
+	}
+
+	// Facebook only returns confirmed email addresses
+	return &OAuthUserInfo{
+		ID:            user.ID,
+		Email:         user.Email,
+		EmailVerified: user.Email != "",
+		Name:          user.Name,
+		Picture:       user.Picture.Data.URL,
+	}, nil
+}
+
+// getJSON decodes the JSON response of a GET request, authorized with the
+// token when one is given
+func getJSON(ctx context.Context, client *http.Client, url string, token *oauth2.Token, v interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	if token != nil {
+		token.SetAuthHeader(req)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to request %s: %w", url, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s returned status %d", url, resp.StatusCode)
+	}
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		return fmt.Errorf("failed to decode response of %s: %w", url, err)
+	}
+	return nil
+}
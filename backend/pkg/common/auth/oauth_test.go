@@ -0,0 +1,224 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/yerenwgventures/GreatNigeriaLibrary-Foundation/backend/pkg/common/config"
+)
+
+// testIssuer is an OpenID Connect issuer that checks the PKCE verifier and
+// signs ID tokens for the nonce of the last authorization URL
+type testIssuer struct {
+	*httptest.Server
+	key       *rsa.PrivateKey
+	challenge string
+	nonce     string
+}
+
+func newTestIssuer(t *testing.T) *testIssuer {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	issuer := &testIssuer{key: key}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]string{
+			"issuer":                 issuer.URL,
+			"authorization_endpoint": issuer.URL + "/authorize",
+			"token_endpoint":         issuer.URL + "/token",
+			"jwks_uri":               issuer.URL + "/jwks",
+		})
+	})
+	mux.HandleFunc("/jwks", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"keys": []map[string]string{{
+				"kty": "RSA",
+				"kid": "k1",
+				"use": "sig",
+				"n":   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+				"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+			}},
+		})
+	})
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		if r.FormValue("code") != "good-code" || pkceChallenge(r.FormValue("code_verifier")) != issuer.challenge {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(map[string]string{"error": "invalid_grant"})
+			return
+		}
+		token := jwt.NewWithClaims(jwt.SigningMethodRS256, jwt.MapClaims{
+			"iss":            issuer.URL,
+			"aud":            "client-1",
+			"sub":            "user-1",
+			"exp":            time.Now().Add(time.Hour).Unix(),
+			"nonce":          issuer.nonce,
+			"email":          "ada@example.com",
+			"email_verified": "true",
+			"name":           "Ada Obi",
+		})
+		token.Header["kid"] = "k1"
+		idToken, err := token.SignedString(key)
+		require.NoError(t, err)
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"access_token": "access-1",
+			"token_type":   "Bearer",
+			"expires_in":   3600,
+			"id_token":     idToken,
+		})
+	})
+	issuer.Server = httptest.NewServer(mux)
+	t.Cleanup(issuer.Close)
+	return issuer
+}
+
+func TestOAuthManagerOIDCLogin(t *testing.T) {
+	issuer := newTestIssuer(t)
+	manager, err := NewOAuthManager(config.OAuthConfig{
+		Providers: map[string]config.OAuthProviderConfig{
+			"acme": {
+				Type:        OAuthProviderOIDC,
+				DisplayName: "Acme SSO",
+				ClientID:    "client-1",
+				RedirectURL: "http://localhost:8080/auth/oauth/acme/callback",
+				Issuer:      issuer.URL,
+			},
+		},
+	}, NewMemoryChallengeStore())
+	require.NoError(t, err)
+	assert.Equal(t, []OAuthProviderInfo{{Name: "acme", DisplayName: "Acme SSO"}}, manager.Providers())
+
+	ctx := context.Background()
+	authURL, err := manager.GetAuthURL(ctx, "acme", 42)
+	require.NoError(t, err)
+
+	parsed, err := url.Parse(authURL)
+	require.NoError(t, err)
+	query := parsed.Query()
+	assert.Equal(t, issuer.URL+"/authorize", parsed.Scheme+"://"+parsed.Host+parsed.Path)
+	assert.Equal(t, "S256", query.Get("code_challenge_method"))
+	assert.Equal(t, "openid email profile", query.Get("scope"))
+	require.NotEmpty(t, query.Get("nonce"))
+	issuer.challenge = query.Get("code_challenge")
+	issuer.nonce = query.Get("nonce")
+
+	state := query.Get("state")
+	_, _, err = manager.HandleCallback(ctx, "acme", state, "bad-code")
+	assert.Error(t, err)
+	_, _, err = manager.HandleCallback(ctx, "acme", state, "good-code")
+	assert.ErrorIs(t, err, ErrInvalidOAuthState, "a state is used once")
+
+	authURL, err = manager.GetAuthURL(ctx, "acme", 42)
+	require.NoError(t, err)
+	parsed, _ = url.Parse(authURL)
+	issuer.challenge = parsed.Query().Get("code_challenge")
+	issuer.nonce = parsed.Query().Get("nonce")
+
+	userInfo, data, err := manager.HandleCallback(ctx, "acme", parsed.Query().Get("state"), "good-code")
+	require.NoError(t, err)
+	assert.Equal(t, &OAuthUserInfo{
+		ID:            "user-1",
+		Email:         "ada@example.com",
+		EmailVerified: true,
+		Name:          "Ada Obi",
+		Provider:      "acme",
+	}, userInfo)
+	assert.Equal(t, uint(42), data.LinkUserID)
+
+	// An ID token issued for another login is rejected
+	authURL, err = manager.GetAuthURL(ctx, "acme", 0)
+	require.NoError(t, err)
+	parsed, _ = url.Parse(authURL)
+	issuer.challenge = parsed.Query().Get("code_challenge")
+	_, _, err = manager.HandleCallback(ctx, "acme", parsed.Query().Get("state"), "good-code")
+	assert.ErrorContains(t, err, "nonce")
+
+	_, err = manager.GetAuthURL(ctx, "myspace", 0)
+	assert.ErrorIs(t, err, ErrUnsupportedOAuthProvider)
+}
+
+func TestOAuthManagerGitHubLogin(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]string{"access_token": "access-1", "token_type": "bearer"})
+	})
+	mux.HandleFunc("/user", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "Bearer access-1", r.Header.Get("Authorization"))
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"id":         583231,
+			"login":      "adaobi",
+			"email":      "public@example.com",
+			"avatar_url": "https://avatars.example.com/u/583231",
+		})
+	})
+	mux.HandleFunc("/user/emails", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode([]map[string]interface{}{
+			{"email": "public@example.com", "primary": false, "verified": false},
+			{"email": "ada@example.com", "primary": true, "verified": true},
+		})
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	manager, err := NewOAuthManager(config.OAuthConfig{
+		Providers: map[string]config.OAuthProviderConfig{
+			"github": {
+				ClientID:    "client-1",
+				AuthURL:     server.URL + "/authorize",
+				TokenURL:    server.URL + "/token",
+				UserInfoURL: server.URL + "/user",
+			},
+		},
+	}, NewMemoryChallengeStore())
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	authURL, err := manager.GetAuthURL(ctx, "github", 0)
+	require.NoError(t, err)
+	parsed, _ := url.Parse(authURL)
+	assert.Empty(t, parsed.Query().Get("nonce"))
+
+	userInfo, data, err := manager.HandleCallback(ctx, "github", parsed.Query().Get("state"), "code")
+	require.NoError(t, err)
+	assert.Equal(t, &OAuthUserInfo{
+		ID:            "583231",
+		Email:         "ada@example.com",
+		EmailVerified: true,
+		Name:          "adaobi",
+		Picture:       "https://avatars.example.com/u/583231",
+		Provider:      "github",
+	}, userInfo)
+	assert.Zero(t, data.LinkUserID)
+}
+
+func TestNewOAuthManagerRejectsInvalidProviders(t *testing.T) {
+	_, err := NewOAuthManager(config.OAuthConfig{
+		Providers: map[string]config.OAuthProviderConfig{"github": {}},
+	}, NewMemoryChallengeStore())
+	assert.Error(t, err, "a provider without a client ID")
+
+	_, err = NewOAuthManager(config.OAuthConfig{
+		Providers: map[string]config.OAuthProviderConfig{"acme": {Type: OAuthProviderOIDC, ClientID: "client-1"}},
+	}, NewMemoryChallengeStore())
+	assert.Error(t, err, "an OIDC provider without an issuer")
+
+	_, err = NewOAuthManager(config.OAuthConfig{
+		Providers: map[string]config.OAuthProviderConfig{"acme": {ClientID: "client-1"}},
+	}, NewMemoryChallengeStore())
+	assert.Error(t, err, "a provider of unknown type")
+}
@@ -0,0 +1,256 @@
+package auth
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// jwksRefreshInterval is the least time between two fetches of an issuer's
+// signing keys, which are fetched again when a token names an unknown key
+const jwksRefreshInterval = time.Minute
+
+// oidcSigningMethods are the ID token signing algorithms accepted
+var oidcSigningMethods = []string{"RS256", "RS384", "RS512", "PS256", "PS384", "PS512", "ES256", "ES384", "ES512"}
+
+// oidcMetadata is the part of an OpenID Connect discovery document in use
+type oidcMetadata struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	UserInfoEndpoint      string `json:"userinfo_endpoint"`
+	JWKSURI               string `json:"jwks_uri"`
+}
+
+// oidcBool is a boolean claim that some issuers send as a string
+type oidcBool bool
+
+// UnmarshalJSON accepts true, false, "true" and "false"
+func (b *oidcBool) UnmarshalJSON(data []byte) error {
+	switch strings.Trim(string(data), `"`) {
+	case "true":
+		*b = true
+	case "false", "null", "":
+		*b = false
+	default:
+		return fmt.Errorf("invalid boolean claim %s", data)
+	}
+	return nil
+}
+
+// idTokenClaims are the claims read from an ID token
+type idTokenClaims struct {
+	Nonce         string   `json:"nonce"`
+	Email         string   `json:"email"`
+	EmailVerified oidcBool `json:"email_verified"`
+	Name          string   `json:"name"`
+	Picture       string   `json:"picture"`
+	jwt.RegisteredClaims
+}
+
+// oidcUserInfo is a userinfo endpoint response
+type oidcUserInfo struct {
+	Subject       string   `json:"sub"`
+	Email         string   `json:"email"`
+	EmailVerified oidcBool `json:"email_verified"`
+	Name          string   `json:"name"`
+	Picture       string   `json:"picture"`
+}
+
+// oidcIssuer discovers an OpenID Connect issuer on first use and verifies
+// the ID tokens it signs
+type oidcIssuer struct {
+	url    string
+	client *http.Client
+
+	mu            sync.Mutex
+	metadata      *oidcMetadata
+	keys          map[string]interface{}
+	keysFetchedAt time.Time
+}
+
+func newOIDCIssuer(url string, client *http.Client) *oidcIssuer {
+	return &oidcIssuer{
+		url:    strings.TrimSuffix(url, "/"),
+		client: client,
+	}
+}
+
+// discover returns the issuer's discovery document, fetching it once
+func (i *oidcIssuer) discover(ctx context.Context) (*oidcMetadata, error) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	if i.metadata != nil {
+		return i.metadata, nil
+	}
+
+	var metadata oidcMetadata
+	if err := getJSON(ctx, i.client, i.url+"/.well-known/openid-configuration", nil, &metadata); err != nil {
+		return nil, fmt.Errorf("failed to discover OIDC issuer %s: %w", i.url, err)
+	}
+	if strings.TrimSuffix(metadata.Issuer, "/") != i.url {
+		return nil, fmt.Errorf("OIDC discovery document of %s names issuer %s", i.url, metadata.Issuer)
+	}
+	if metadata.AuthorizationEndpoint == "" || metadata.TokenEndpoint == "" || metadata.JWKSURI == "" {
+		return nil, fmt.Errorf("OIDC discovery document of %s is incomplete", i.url)
+	}
+
+	i.metadata = &metadata
+	return i.metadata, nil
+}
+
+// verify checks an ID token's signature, issuer, audience, expiry and nonce
+func (i *oidcIssuer) verify(ctx context.Context, rawIDToken, clientID, nonce string) (*idTokenClaims, error) {
+	metadata, err := i.discover(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	claims := &idTokenClaims{}
+	_, err = jwt.ParseWithClaims(rawIDToken, claims, func(token *jwt.Token) (interface{}, error) {
+		kid, _ := token.Header["kid"].(string)
+		return i.key(ctx, metadata.JWKSURI, kid)
+	},
+		jwt.WithValidMethods(oidcSigningMethods),
+		jwt.WithIssuer(metadata.Issuer),
+		jwt.WithAudience(clientID),
+		jwt.WithLeeway(time.Minute),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("invalid ID token: %w", err)
+	}
+	if claims.ExpiresAt == nil {
+		return nil, errors.New("invalid ID token: token has no expiry")
+	}
+	if claims.Subject == "" {
+		return nil, errors.New("invalid ID token: token has no subject")
+	}
+	if subtle.ConstantTimeCompare([]byte(claims.Nonce), []byte(nonce)) != 1 {
+		return nil, errors.New("invalid ID token: nonce does not match")
+	}
+	return claims, nil
+}
+
+// key returns the signing key with the given ID. Tokens without a key ID
+// are accepted when the issuer has a single key.
+func (i *oidcIssuer) key(ctx context.Context, jwksURI, kid string) (interface{}, error) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	if key := i.lookupKey(kid); key != nil {
+		return key, nil
+	}
+	if i.keys != nil && time.Since(i.keysFetchedAt) < jwksRefreshInterval {
+		return nil, fmt.Errorf("unknown signing key %q", kid)
+	}
+
+	var jwks struct {
+		Keys []jsonWebKey `json:"keys"`
+	}
+	if err := getJSON(ctx, i.client, jwksURI, nil, &jwks); err != nil {
+		return nil, fmt.Errorf("failed to fetch signing keys: %w", err)
+	}
+
+	keys := make(map[string]interface{})
+	for _, jwk := range jwks.Keys {
+		if jwk.Use != "" && jwk.Use != "sig" {
+			continue
+		}
+		key, err := jwk.publicKey()
+		if err != nil {
+			// Keys of other types may be listed alongside the ones in use
+			continue
+		}
+		keys[jwk.Kid] = key
+	}
+	i.keys = keys
+	i.keysFetchedAt = time.Now()
+
+	if key := i.lookupKey(kid); key != nil {
+		return key, nil
+	}
+	return nil, fmt.Errorf("unknown signing key %q", kid)
+}
+
+func (i *oidcIssuer) lookupKey(kid string) interface{} {
+	if key, ok := i.keys[kid]; ok {
+		return key
+	}
+	if kid == "" && len(i.keys) == 1 {
+		for _, key := range i.keys {
+			return key
+		}
+	}
+	return nil
+}
+
+// jsonWebKey is an RSA or elliptic curve public key in a JSON Web Key Set
+type jsonWebKey struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+func (k jsonWebKey) publicKey() (interface{}, error) {
+	switch k.Kty {
+	case "RSA":
+		n, err := base64.RawURLEncoding.DecodeString(k.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := base64.RawURLEncoding.DecodeString(k.E)
+		if err != nil {
+			return nil, err
+		}
+		exponent := new(big.Int).SetBytes(e)
+		if !exponent.IsInt64() || exponent.Int64() < 3 {
+			return nil, errors.New("invalid RSA exponent")
+		}
+		return &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(exponent.Int64())}, nil
+	case "EC":
+		var curve elliptic.Curve
+		switch k.Crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, fmt.Errorf("unsupported curve %s", k.Crv)
+		}
+		x, err := base64.RawURLEncoding.DecodeString(k.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := base64.RawURLEncoding.DecodeString(k.Y)
+		if err != nil {
+			return nil, err
+		}
+		key := &ecdsa.PublicKey{Curve: curve, X: new(big.Int).SetBytes(x), Y: new(big.Int).SetBytes(y)}
+		if !curve.IsOnCurve(key.X, key.Y) {
+			return nil, errors.New("invalid elliptic curve key")
+		}
+		return key, nil
+	default:
+		return nil, fmt.Errorf("unsupported key type %s", k.Kty)
+	}
+}
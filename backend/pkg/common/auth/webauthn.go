@@ -0,0 +1,316 @@
+package auth
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/go-webauthn/webauthn/protocol"
+	"github.com/go-webauthn/webauthn/webauthn"
+	"github.com/yerenwgventures/GreatNigeriaLibrary-Foundation/backend/pkg/common/config"
+)
+
+// DefaultWebAuthnTimeout is how long a user has to answer their authenticator
+const DefaultWebAuthnTimeout = 5 * time.Minute
+
+var (
+	// ErrInvalidWebAuthnCeremony is returned when a ceremony is unknown,
+	// expired, already used, or was started for another user or purpose
+	ErrInvalidWebAuthnCeremony = errors.New("invalid or expired WebAuthn ceremony")
+	// ErrWebAuthnVerification is returned when an authenticator's response
+	// does not verify
+	ErrWebAuthnVerification = errors.New("WebAuthn verification failed")
+	// ErrWebAuthnSignCount is returned when an authenticator's signature
+	// counter did not increase since the credential was last used, which
+	// means the assertion was replayed or the authenticator was cloned
+	ErrWebAuthnSignCount = errors.New("WebAuthn signature counter did not increase")
+)
+
+// Purposes of WebAuthn ceremonies
+const (
+	webAuthnRegistration = "registration"
+	webAuthnLogin        = "login"
+	webAuthnPasskeyLogin = "passkey_login"
+)
+
+// webAuthnCeremonyKeyPrefix prefixes the challenge store keys of ceremonies
+const webAuthnCeremonyKeyPrefix = "webauthn:ceremony:"
+
+// WebAuthnUser is a user and the credentials they registered
+type WebAuthnUser struct {
+	ID          uint
+	Name        string
+	DisplayName string
+	Credentials []webauthn.Credential
+}
+
+// WebAuthnID returns the user handle stored on the authenticator
+func (u *WebAuthnUser) WebAuthnID() []byte {
+	return WebAuthnUserHandle(u.ID)
+}
+
+// WebAuthnName returns the name the authenticator shows for the account
+func (u *WebAuthnUser) WebAuthnName() string {
+	return u.Name
+}
+
+// WebAuthnDisplayName returns the user's display name
+func (u *WebAuthnUser) WebAuthnDisplayName() string {
+	if u.DisplayName == "" {
+		return u.Name
+	}
+	return u.DisplayName
+}
+
+// WebAuthnCredentials returns the user's credentials
+func (u *WebAuthnUser) WebAuthnCredentials() []webauthn.Credential {
+	return u.Credentials
+}
+
+// WebAuthnIcon is deprecated by the specification and always empty
+func (u *WebAuthnUser) WebAuthnIcon() string {
+	return ""
+}
+
+func (u *WebAuthnUser) descriptors() []protocol.CredentialDescriptor {
+	descriptors := make([]protocol.CredentialDescriptor, 0, len(u.Credentials))
+	for _, credential := range u.Credentials {
+		descriptors = append(descriptors, credential.Descriptor())
+	}
+	return descriptors
+}
+
+// WebAuthnUserHandle returns the user handle of a user ID. The handle is the
+// ID as 8 big-endian bytes, so it holds no personal information.
+func WebAuthnUserHandle(userID uint) []byte {
+	handle := make([]byte, 8)
+	binary.BigEndian.PutUint64(handle, uint64(userID))
+	return handle
+}
+
+// ParseWebAuthnUserHandle returns the user ID of a user handle
+func ParseWebAuthnUserHandle(handle []byte) (uint, error) {
+	if len(handle) != 8 {
+		return 0, fmt.Errorf("invalid user handle length %d", len(handle))
+	}
+	return uint(binary.BigEndian.Uint64(handle)), nil
+}
+
+// webAuthnCeremony is what the server remembers about a ceremony in progress
+type webAuthnCeremony struct {
+	Purpose string               `json:"purpose"`
+	UserID  uint                 `json:"user_id,omitempty"`
+	Session webauthn.SessionData `json:"session"`
+}
+
+// WebAuthnManager runs WebAuthn registration and assertion ceremonies for
+// passkeys and security keys. Every ceremony gets a one-time ID; the
+// challenge it answers is kept server-side in the challenge store.
+type WebAuthnManager struct {
+	webAuthn   *webauthn.WebAuthn
+	ceremonies ChallengeStore
+	timeout    time.Duration
+}
+
+// NewWebAuthnManager creates a WebAuthn manager for the configured relying party
+func NewWebAuthnManager(cfg config.WebAuthnConfig, ceremonies ChallengeStore) (*WebAuthnManager, error) {
+	timeout := cfg.Timeout
+	if timeout <= 0 {
+		timeout = DefaultWebAuthnTimeout
+	}
+	if len(cfg.RPOrigins) == 0 {
+		return nil, errors.New("webauthn: no relying party origins configured")
+	}
+
+	timeouts := webauthn.TimeoutConfig{Enforce: true, Timeout: timeout, TimeoutUVD: timeout}
+	w, err := webauthn.New(&webauthn.Config{
+		RPID:                  cfg.RPID,
+		RPDisplayName:         cfg.RPDisplayName,
+		RPOrigins:             cfg.RPOrigins,
+		AttestationPreference: protocol.PreferNoAttestation,
+		Timeouts: webauthn.TimeoutsConfig{
+			Login:        timeouts,
+			Registration: timeouts,
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("webauthn: %w", err)
+	}
+
+	return &WebAuthnManager{
+		webAuthn:   w,
+		ceremonies: ceremonies,
+		timeout:    timeout,
+	}, nil
+}
+
+// BeginRegistration starts registering a new credential for a user. The
+// credential is created as a passkey where the authenticator supports it, so
+// it can also be used to log in without a password.
+func (m *WebAuthnManager) BeginRegistration(ctx context.Context, user *WebAuthnUser) (string, *protocol.CredentialCreation, error) {
+	creation, session, err := m.webAuthn.BeginRegistration(user,
+		webauthn.WithExclusions(user.descriptors()),
+		webauthn.WithResidentKeyRequirement(protocol.ResidentKeyRequirementPreferred),
+	)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to begin WebAuthn registration: %w", err)
+	}
+
+	id, err := m.saveCeremony(ctx, webAuthnRegistration, user.ID, session)
+	if err != nil {
+		return "", nil, err
+	}
+	return id, creation, nil
+}
+
+// FinishRegistration verifies the authenticator's response to a registration
+// and returns the new credential
+func (m *WebAuthnManager) FinishRegistration(ctx context.Context, ceremonyID string, user *WebAuthnUser, response io.Reader) (*webauthn.Credential, error) {
+	session, err := m.consumeCeremony(ctx, ceremonyID, webAuthnRegistration, user.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	parsed, err := protocol.ParseCredentialCreationResponseBody(response)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrWebAuthnVerification, err)
+	}
+	credential, err := m.webAuthn.CreateCredential(user, *session, parsed)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrWebAuthnVerification, err)
+	}
+	return credential, nil
+}
+
+// BeginLogin starts an assertion with one of a user's credentials, e.g. as
+// the second factor of a password login
+func (m *WebAuthnManager) BeginLogin(ctx context.Context, user *WebAuthnUser) (string, *protocol.CredentialAssertion, error) {
+	assertion, session, err := m.webAuthn.BeginLogin(user)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to begin WebAuthn login: %w", err)
+	}
+
+	id, err := m.saveCeremony(ctx, webAuthnLogin, user.ID, session)
+	if err != nil {
+		return "", nil, err
+	}
+	return id, assertion, nil
+}
+
+// FinishLogin verifies an assertion started with BeginLogin and returns the
+// credential that signed it, with its new signature counter
+func (m *WebAuthnManager) FinishLogin(ctx context.Context, ceremonyID string, user *WebAuthnUser, response io.Reader) (*webauthn.Credential, error) {
+	session, err := m.consumeCeremony(ctx, ceremonyID, webAuthnLogin, user.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	parsed, err := protocol.ParseCredentialRequestResponseBody(response)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrWebAuthnVerification, err)
+	}
+	credential, err := m.webAuthn.ValidateLogin(user, *session, parsed)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrWebAuthnVerification, err)
+	}
+	return checkSignCount(credential)
+}
+
+// BeginPasskeyLogin starts a passwordless login. The browser offers the
+// passkeys it has for this site, and the user verifies themselves on the
+// authenticator, e.g. with a fingerprint or PIN.
+func (m *WebAuthnManager) BeginPasskeyLogin(ctx context.Context) (string, *protocol.CredentialAssertion, error) {
+	assertion, session, err := m.webAuthn.BeginDiscoverableLogin(
+		webauthn.WithUserVerification(protocol.VerificationRequired),
+	)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to begin WebAuthn login: %w", err)
+	}
+
+	id, err := m.saveCeremony(ctx, webAuthnPasskeyLogin, 0, session)
+	if err != nil {
+		return "", nil, err
+	}
+	return id, assertion, nil
+}
+
+// FinishPasskeyLogin verifies an assertion started with BeginPasskeyLogin.
+// loadUser loads the user named by the passkey's user handle. It returns the
+// user and the credential that signed the assertion.
+func (m *WebAuthnManager) FinishPasskeyLogin(ctx context.Context, ceremonyID string, response io.Reader, loadUser func(userID uint) (*WebAuthnUser, error)) (*WebAuthnUser, *webauthn.Credential, error) {
+	session, err := m.consumeCeremony(ctx, ceremonyID, webAuthnPasskeyLogin, 0)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	parsed, err := protocol.ParseCredentialRequestResponseBody(response)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w: %v", ErrWebAuthnVerification, err)
+	}
+
+	var user *WebAuthnUser
+	credential, err := m.webAuthn.ValidateDiscoverableLogin(func(rawID, userHandle []byte) (webauthn.User, error) {
+		userID, err := ParseWebAuthnUserHandle(userHandle)
+		if err != nil {
+			return nil, err
+		}
+		user, err = loadUser(userID)
+		if err != nil {
+			return nil, err
+		}
+		return user, nil
+	}, *session, parsed)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w: %v", ErrWebAuthnVerification, err)
+	}
+
+	credential, err = checkSignCount(credential)
+	if err != nil {
+		return nil, nil, err
+	}
+	return user, credential, nil
+}
+
+func (m *WebAuthnManager) saveCeremony(ctx context.Context, purpose string, userID uint, session *webauthn.SessionData) (string, error) {
+	id, err := randomURLToken()
+	if err != nil {
+		return "", err
+	}
+	ceremony := &webAuthnCeremony{
+		Purpose: purpose,
+		UserID:  userID,
+		Session: *session,
+	}
+	if err := SaveChallenge(ctx, m.ceremonies, webAuthnCeremonyKeyPrefix+id, ceremony, m.timeout); err != nil {
+		return "", fmt.Errorf("failed to store WebAuthn ceremony: %w", err)
+	}
+	return id, nil
+}
+
+func (m *WebAuthnManager) consumeCeremony(ctx context.Context, id, purpose string, userID uint) (*webauthn.SessionData, error) {
+	var ceremony webAuthnCeremony
+	err := ConsumeChallenge(ctx, m.ceremonies, webAuthnCeremonyKeyPrefix+id, &ceremony)
+	if errors.Is(err, ErrChallengeNotFound) {
+		return nil, ErrInvalidWebAuthnCeremony
+	}
+	if err != nil {
+		return nil, err
+	}
+	if ceremony.Purpose != purpose || ceremony.UserID != userID {
+		return nil, ErrInvalidWebAuthnCeremony
+	}
+	return &ceremony.Session, nil
+}
+
+// checkSignCount rejects assertions whose signature counter did not increase.
+// Authenticators that do not count, such as most synced passkeys, always
+// report zero and are accepted.
+func checkSignCount(credential *webauthn.Credential) (*webauthn.Credential, error) {
+	if credential.Authenticator.CloneWarning {
+		return nil, ErrWebAuthnSignCount
+	}
+	return credential, nil
+}
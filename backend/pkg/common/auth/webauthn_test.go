@@ -0,0 +1,215 @@
+package auth
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"testing"
+
+	"github.com/go-webauthn/webauthn/protocol"
+	"github.com/go-webauthn/webauthn/protocol/webauthncbor"
+	"github.com/go-webauthn/webauthn/webauthn"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/yerenwgventures/GreatNigeriaLibrary-Foundation/backend/pkg/common/config"
+)
+
+const (
+	testRPID     = "library.example.com"
+	testRPOrigin = "https://library.example.com"
+)
+
+// softAuthenticator is a software passkey holding one ES256 credential. It
+// answers ceremonies the way a browser and platform authenticator would.
+type softAuthenticator struct {
+	key          *ecdsa.PrivateKey
+	credentialID []byte
+	userHandle   []byte
+	origin       string
+	signCount    uint32
+}
+
+func newSoftAuthenticator(t *testing.T) *softAuthenticator {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	credentialID := make([]byte, 16)
+	_, err = rand.Read(credentialID)
+	require.NoError(t, err)
+	return &softAuthenticator{key: key, credentialID: credentialID, origin: testRPOrigin}
+}
+
+func (a *softAuthenticator) clientData(t *testing.T, ceremony string, challenge protocol.URLEncodedBase64) []byte {
+	clientData, err := json.Marshal(map[string]string{
+		"type":      ceremony,
+		"challenge": challenge.String(),
+		"origin":    a.origin,
+	})
+	require.NoError(t, err)
+	return clientData
+}
+
+// authData returns authenticator data with user presence and verification
+func (a *softAuthenticator) authData(flags byte) []byte {
+	rpIDHash := sha256.Sum256([]byte(testRPID))
+	data := append([]byte{}, rpIDHash[:]...)
+	data = append(data, flags|0x01|0x04)
+	return binary.BigEndian.AppendUint32(data, a.signCount)
+}
+
+// register answers a registration with "none" attestation
+func (a *softAuthenticator) register(t *testing.T, creation *protocol.CredentialCreation) []byte {
+	a.userHandle = []byte(creation.Response.User.ID.(protocol.URLEncodedBase64))
+
+	publicKey, err := webauthncbor.Marshal(map[int]interface{}{
+		1:  2,  // kty: EC2
+		3:  -7, // alg: ES256
+		-1: 1,  // crv: P-256
+		-2: a.key.X.FillBytes(make([]byte, 32)),
+		-3: a.key.Y.FillBytes(make([]byte, 32)),
+	})
+	require.NoError(t, err)
+
+	authData := a.authData(0x40)                     // attested credential data included
+	authData = append(authData, make([]byte, 16)...) // AAGUID
+	authData = binary.BigEndian.AppendUint16(authData, uint16(len(a.credentialID)))
+	authData = append(authData, a.credentialID...)
+	authData = append(authData, publicKey...)
+
+	attestationObject, err := webauthncbor.Marshal(map[string]interface{}{
+		"fmt":      "none",
+		"attStmt":  map[string]interface{}{},
+		"authData": authData,
+	})
+	require.NoError(t, err)
+
+	return a.response(t, map[string]string{
+		"clientDataJSON":    base64.RawURLEncoding.EncodeToString(a.clientData(t, "webauthn.create", creation.Response.Challenge)),
+		"attestationObject": base64.RawURLEncoding.EncodeToString(attestationObject),
+	})
+}
+
+// assert signs an assertion, increasing the signature counter first
+func (a *softAuthenticator) assert(t *testing.T, assertion *protocol.CredentialAssertion) []byte {
+	a.signCount++
+	authData := a.authData(0)
+	clientData := a.clientData(t, "webauthn.get", assertion.Response.Challenge)
+
+	clientDataHash := sha256.Sum256(clientData)
+	digest := sha256.Sum256(append(append([]byte{}, authData...), clientDataHash[:]...))
+	signature, err := ecdsa.SignASN1(rand.Reader, a.key, digest[:])
+	require.NoError(t, err)
+
+	return a.response(t, map[string]string{
+		"clientDataJSON":    base64.RawURLEncoding.EncodeToString(clientData),
+		"authenticatorData": base64.RawURLEncoding.EncodeToString(authData),
+		"signature":         base64.RawURLEncoding.EncodeToString(signature),
+		"userHandle":        base64.RawURLEncoding.EncodeToString(a.userHandle),
+	})
+}
+
+func (a *softAuthenticator) response(t *testing.T, response map[string]string) []byte {
+	id := base64.RawURLEncoding.EncodeToString(a.credentialID)
+	body, err := json.Marshal(map[string]interface{}{
+		"id":       id,
+		"rawId":    id,
+		"type":     "public-key",
+		"response": response,
+	})
+	require.NoError(t, err)
+	return body
+}
+
+func newTestWebAuthnManager(t *testing.T) *WebAuthnManager {
+	manager, err := NewWebAuthnManager(config.WebAuthnConfig{
+		RPID:          testRPID,
+		RPDisplayName: "Great Nigeria Library",
+		RPOrigins:     []string{testRPOrigin},
+	}, NewMemoryChallengeStore())
+	require.NoError(t, err)
+	return manager
+}
+
+// registerTestCredential registers a software authenticator for a new user
+func registerTestCredential(t *testing.T, manager *WebAuthnManager) (*WebAuthnUser, *softAuthenticator) {
+	ctx := context.Background()
+	user := &WebAuthnUser{ID: 42, Name: "ada@example.com", DisplayName: "Ada"}
+	authenticator := newSoftAuthenticator(t)
+
+	ceremonyID, creation, err := manager.BeginRegistration(ctx, user)
+	require.NoError(t, err)
+	assert.Equal(t, testRPID, creation.Response.RelyingParty.ID)
+
+	credential, err := manager.FinishRegistration(ctx, ceremonyID, user, bytes.NewReader(authenticator.register(t, creation)))
+	require.NoError(t, err)
+	assert.Equal(t, authenticator.credentialID, credential.ID)
+
+	user.Credentials = []webauthn.Credential{*credential}
+	return user, authenticator
+}
+
+func TestWebAuthnPasskeyLogin(t *testing.T) {
+	ctx := context.Background()
+	manager := newTestWebAuthnManager(t)
+	user, authenticator := registerTestCredential(t, manager)
+	loadUser := func(userID uint) (*WebAuthnUser, error) {
+		assert.Equal(t, user.ID, userID)
+		return user, nil
+	}
+
+	ceremonyID, assertion, err := manager.BeginPasskeyLogin(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, assertion.Response.AllowedCredentials)
+	response := authenticator.assert(t, assertion)
+
+	loggedIn, credential, err := manager.FinishPasskeyLogin(ctx, ceremonyID, bytes.NewReader(response), loadUser)
+	require.NoError(t, err)
+	assert.Equal(t, user.ID, loggedIn.ID)
+	assert.Equal(t, uint32(1), credential.Authenticator.SignCount)
+	user.Credentials[0] = *credential
+
+	// Ceremonies are single use
+	_, _, err = manager.FinishPasskeyLogin(ctx, ceremonyID, bytes.NewReader(response), loadUser)
+	assert.ErrorIs(t, err, ErrInvalidWebAuthnCeremony)
+
+	// A cloned authenticator reuses a signature counter the server has seen
+	ceremonyID, assertion, err = manager.BeginPasskeyLogin(ctx)
+	require.NoError(t, err)
+	authenticator.signCount = 0
+	_, _, err = manager.FinishPasskeyLogin(ctx, ceremonyID, bytes.NewReader(authenticator.assert(t, assertion)), loadUser)
+	assert.ErrorIs(t, err, ErrWebAuthnSignCount)
+}
+
+func TestWebAuthnSecondFactorLogin(t *testing.T) {
+	ctx := context.Background()
+	manager := newTestWebAuthnManager(t)
+	user, authenticator := registerTestCredential(t, manager)
+
+	ceremonyID, assertion, err := manager.BeginLogin(ctx, user)
+	require.NoError(t, err)
+	require.Len(t, assertion.Response.AllowedCredentials, 1)
+
+	credential, err := manager.FinishLogin(ctx, ceremonyID, user, bytes.NewReader(authenticator.assert(t, assertion)))
+	require.NoError(t, err)
+	assert.Equal(t, uint32(1), credential.Authenticator.SignCount)
+
+	// A second factor ceremony cannot be finished as a passwordless login
+	ceremonyID, assertion, err = manager.BeginLogin(ctx, user)
+	require.NoError(t, err)
+	_, _, err = manager.FinishPasskeyLogin(ctx, ceremonyID, bytes.NewReader(authenticator.assert(t, assertion)), func(uint) (*WebAuthnUser, error) {
+		return user, nil
+	})
+	assert.ErrorIs(t, err, ErrInvalidWebAuthnCeremony)
+
+	// Assertions are bound to the relying party's origin
+	ceremonyID, assertion, err = manager.BeginLogin(ctx, user)
+	require.NoError(t, err)
+	authenticator.origin = "https://phishing.example.net"
+	_, err = manager.FinishLogin(ctx, ceremonyID, user, bytes.NewReader(authenticator.assert(t, assertion)))
+	assert.ErrorIs(t, err, ErrWebAuthnVerification)
+}
@@ -4,21 +4,24 @@ import (
 	"fmt"
 	"os"
 	"strconv"
+	"strings"
 	"time"
 
-	"golang.org/x/oauth2"
 	"gopkg.in/yaml.v3"
 )
 
 // Config represents application configuration
 type Config struct {
+	App         AppConfig         `json:"app" yaml:"app"`
 	Server      ServerConfig      `json:"server" yaml:"server"`
 	Database    DatabaseConfig    `json:"database" yaml:"database"`
 	Redis       RedisConfig       `json:"redis" yaml:"redis"`
 	Auth        AuthConfig        `json:"auth" yaml:"auth"`
 	OAuth       OAuthConfig       `json:"oauth" yaml:"oauth"`
+	WebAuthn    WebAuthnConfig    `json:"webauthn" yaml:"webauthn"`
 	Email       EmailConfig       `json:"email" yaml:"email"`
 	Storage     StorageConfig     `json:"storage" yaml:"storage"`
+	TTS         TTSConfig         `json:"tts" yaml:"tts"`
 	Logging     LoggingConfig     `json:"logging" yaml:"logging"`
 	Services    ServicesConfig    `json:"services" yaml:"services"`
 	Features    FeaturesConfig    `json:"features" yaml:"features"`
@@ -26,6 +29,11 @@ type Config struct {
 	CORS        CORSConfig        `json:"cors" yaml:"cors"`
 }
 
+// AppConfig represents application-wide settings
+type AppConfig struct {
+	FrontendURL string `json:"frontend_url" yaml:"frontend_url"`
+}
+
 // ServerConfig represents server configuration
 type ServerConfig struct {
 	Host         string        `json:"host" yaml:"host"`
@@ -47,6 +55,7 @@ type DatabaseConfig struct {
 	MaxOpenConns    int    `json:"max_open_conns"`
 	MaxIdleConns    int    `json:"max_idle_conns"`
 	ConnMaxLifetime time.Duration `json:"conn_max_lifetime"`
+	SeedDemoData    bool          `json:"seed_demo_data"` // Load demo data after migrating on startup
 }
 
 // RedisConfig represents enhanced Redis configuration
@@ -82,16 +91,35 @@ type AuthConfig struct {
 	TokenSecurityChecks         bool          `json:"token_security_checks" yaml:"token_security_checks"`
 }
 
-// OAuthConfig represents OAuth configuration
+// OAuthConfig represents OAuth configuration. Providers are keyed by the name
+// used in the login and callback URLs, e.g. /auth/oauth/github.
 type OAuthConfig struct {
-	Google GoogleOAuthConfig `json:"google"`
+	StateTTL  time.Duration                  `json:"state_ttl" yaml:"state_ttl"`
+	Providers map[string]OAuthProviderConfig `json:"providers" yaml:"providers"`
+}
+
+// OAuthProviderConfig represents the configuration of one OAuth provider
+type OAuthProviderConfig struct {
+	Type         string   `json:"type" yaml:"type"` // google, github, facebook, oidc; defaults to the provider name
+	DisplayName  string   `json:"display_name" yaml:"display_name"`
+	ClientID     string   `json:"client_id" yaml:"client_id"`
+	ClientSecret string   `json:"client_secret" yaml:"client_secret"`
+	RedirectURL  string   `json:"redirect_url" yaml:"redirect_url"`
+	Issuer       string   `json:"issuer" yaml:"issuer"` // OIDC issuer, discovered through /.well-known/openid-configuration
+	Scopes       []string `json:"scopes" yaml:"scopes"`
+	AuthURL      string   `json:"auth_url" yaml:"auth_url"`         // overrides the provider default, e.g. for GitHub Enterprise
+	TokenURL     string   `json:"token_url" yaml:"token_url"`       // overrides the provider default
+	UserInfoURL  string   `json:"userinfo_url" yaml:"userinfo_url"` // overrides the provider default
 }
 
-// GoogleOAuthConfig represents Google OAuth configuration
-type GoogleOAuthConfig struct {
-	ClientID     string `json:"client_id"`
-	ClientSecret string `json:"client_secret"`
-	RedirectURL  string `json:"redirect_url"`
+// WebAuthnConfig represents the relying party of passkey and security key
+// logins. Credentials are bound to the RP ID, so changing it invalidates all
+// registered credentials.
+type WebAuthnConfig struct {
+	RPID          string        `json:"rp_id" yaml:"rp_id"` // domain of the site, e.g. greatnigeria.net
+	RPDisplayName string        `json:"rp_display_name" yaml:"rp_display_name"`
+	RPOrigins     []string      `json:"rp_origins" yaml:"rp_origins"` // origins allowed to run ceremonies
+	Timeout       time.Duration `json:"timeout" yaml:"timeout"`
 }
 
 // EmailConfig represents email configuration
@@ -102,6 +130,8 @@ type EmailConfig struct {
 	SMTPPassword string `json:"smtp_password"`
 	FromEmail    string `json:"from_email"`
 	FromName     string `json:"from_name"`
+	Driver       string `json:"driver"`     // smtp, outbox
+	OutboxDir    string `json:"outbox_dir"` // optional directory for .eml files written by the outbox driver
 }
 
 // StorageConfig represents storage configuration
@@ -119,6 +149,15 @@ type S3Config struct {
 	SecretKey string `json:"secret_key"`
 }
 
+// TTSConfig represents text-to-speech configuration for section audio
+type TTSConfig struct {
+	Engine         string `json:"engine" yaml:"engine"`                     // espeak, piper, tone, none
+	Binary         string `json:"binary" yaml:"binary"`                     // path of the espeak-ng or piper executable
+	Voice          string `json:"voice" yaml:"voice"`                       // espeak-ng voice, e.g. en or en-us
+	Model          string `json:"model" yaml:"model"`                       // piper voice model
+	WordsPerMinute int    `json:"words_per_minute" yaml:"words_per_minute"` // espeak-ng speaking rate
+}
+
 // LoggingConfig represents logging configuration
 type LoggingConfig struct {
 	Level  string `json:"level" yaml:"level"`
@@ -132,12 +171,17 @@ type ServicesConfig struct {
 	AuthService       ServiceConfig `json:"auth_service" yaml:"auth_service"`
 	ContentService    ServiceConfig `json:"content_service" yaml:"content_service"`
 	DiscussionService ServiceConfig `json:"discussion_service" yaml:"discussion_service"`
+	GroupsService     ServiceConfig `json:"groups_service" yaml:"groups_service"`
 	APIGateway        ServiceConfig `json:"api_gateway" yaml:"api_gateway"`
 }
 
 // ServiceConfig represents individual service configuration
 type ServiceConfig struct {
 	Port int `json:"port" yaml:"port"`
+	// URL is where the API gateway reaches the service
+	URL string `json:"url" yaml:"url"`
+	// Timeout bounds each request the gateway proxies to the service
+	Timeout time.Duration `json:"timeout" yaml:"timeout"`
 }
 
 // FeaturesConfig represents feature flags
@@ -169,6 +213,9 @@ type CORSConfig struct {
 // LoadConfig loads configuration from environment variables
 func LoadConfig() (*Config, error) {
 	config := &Config{
+		App: AppConfig{
+			FrontendURL: getEnv("FRONTEND_URL", "http://localhost:3000"),
+		},
 		Server: ServerConfig{
 			Host:         getEnv("SERVER_HOST", "0.0.0.0"),
 			Port:         getEnvAsInt("SERVER_PORT", 8080),
@@ -187,6 +234,7 @@ func LoadConfig() (*Config, error) {
 			MaxOpenConns:    getEnvAsInt("DB_MAX_OPEN_CONNS", 25),
 			MaxIdleConns:    getEnvAsInt("DB_MAX_IDLE_CONNS", 25),
 			ConnMaxLifetime: getEnvAsDuration("DB_CONN_MAX_LIFETIME", 5*time.Minute),
+			SeedDemoData:    getEnvAsBool("DB_SEED_DEMO_DATA", false),
 		},
 		Redis: RedisConfig{
 			Host:         getEnv("REDIS_HOST", "localhost"),
@@ -218,11 +266,14 @@ func LoadConfig() (*Config, error) {
 			TokenSecurityChecks:         getEnvAsBool("TOKEN_SECURITY_CHECKS", true),
 		},
 		OAuth: OAuthConfig{
-			Google: GoogleOAuthConfig{
-				ClientID:     getEnv("GOOGLE_CLIENT_ID", ""),
-				ClientSecret: getEnv("GOOGLE_CLIENT_SECRET", ""),
-				RedirectURL:  getEnv("GOOGLE_REDIRECT_URL", "http://localhost:8080/auth/google/callback"),
-			},
+			StateTTL:  getEnvAsDuration("OAUTH_STATE_TTL", 10*time.Minute),
+			Providers: getOAuthProvidersFromEnv(),
+		},
+		WebAuthn: WebAuthnConfig{
+			RPID:          getEnv("WEBAUTHN_RP_ID", "localhost"),
+			RPDisplayName: getEnv("WEBAUTHN_RP_DISPLAY_NAME", "Great Nigeria Library"),
+			RPOrigins:     getEnvAsSlice("WEBAUTHN_RP_ORIGINS", []string{getEnv("FRONTEND_URL", "http://localhost:3000")}),
+			Timeout:       getEnvAsDuration("WEBAUTHN_TIMEOUT", 5*time.Minute),
 		},
 		Email: EmailConfig{
 			SMTPHost:     getEnv("SMTP_HOST", "localhost"),
@@ -231,6 +282,8 @@ func LoadConfig() (*Config, error) {
 			SMTPPassword: getEnv("SMTP_PASSWORD", ""),
 			FromEmail:    getEnv("FROM_EMAIL", "noreply@greatnigeria.com"),
 			FromName:     getEnv("FROM_NAME", "Great Nigeria Library"),
+			Driver:       getEnv("MAIL_DRIVER", "smtp"),
+			OutboxDir:    getEnv("MAIL_OUTBOX_DIR", ""),
 		},
 		Storage: StorageConfig{
 			Type:      getEnv("STORAGE_TYPE", "local"),
@@ -242,6 +295,43 @@ func LoadConfig() (*Config, error) {
 				SecretKey: getEnv("S3_SECRET_KEY", ""),
 			},
 		},
+		TTS: TTSConfig{
+			Engine:         getEnv("TTS_ENGINE", "espeak"),
+			Binary:         getEnv("TTS_BINARY", ""),
+			Voice:          getEnv("TTS_VOICE", "en"),
+			Model:          getEnv("TTS_MODEL", ""),
+			WordsPerMinute: getEnvAsInt("TTS_WORDS_PER_MINUTE", 160),
+		},
+		RateLimit: RateLimitConfig{
+			Enabled:           getEnvAsBool("RATE_LIMIT_ENABLED", true),
+			RequestsPerMinute: getEnvAsInt("RATE_LIMIT_REQUESTS_PER_MINUTE", 60),
+			BurstSize:         getEnvAsInt("RATE_LIMIT_BURST_SIZE", 10),
+		},
+		Services: ServicesConfig{
+			AuthService: ServiceConfig{
+				Port:    getEnvAsInt("AUTH_SERVICE_PORT", 8081),
+				URL:     getEnv("AUTH_SERVICE_URL", "http://localhost:8081"),
+				Timeout: getEnvAsDuration("AUTH_SERVICE_TIMEOUT", 10*time.Second),
+			},
+			ContentService: ServiceConfig{
+				Port:    getEnvAsInt("CONTENT_SERVICE_PORT", 8082),
+				URL:     getEnv("CONTENT_SERVICE_URL", "http://localhost:8082"),
+				Timeout: getEnvAsDuration("CONTENT_SERVICE_TIMEOUT", 15*time.Second),
+			},
+			DiscussionService: ServiceConfig{
+				Port:    getEnvAsInt("DISCUSSION_SERVICE_PORT", 8083),
+				URL:     getEnv("DISCUSSION_SERVICE_URL", "http://localhost:8083"),
+				Timeout: getEnvAsDuration("DISCUSSION_SERVICE_TIMEOUT", 10*time.Second),
+			},
+			GroupsService: ServiceConfig{
+				Port:    getEnvAsInt("GROUPS_SERVICE_PORT", 8084),
+				URL:     getEnv("GROUPS_SERVICE_URL", "http://localhost:8084"),
+				Timeout: getEnvAsDuration("GROUPS_SERVICE_TIMEOUT", 10*time.Second),
+			},
+			APIGateway: ServiceConfig{
+				Port: getEnvAsInt("API_GATEWAY_PORT", 8080),
+			},
+		},
 		Logging: LoggingConfig{
 			Level:  getEnv("LOG_LEVEL", "info"),
 			Format: getEnv("LOG_FORMAT", "json"),
@@ -253,22 +343,35 @@ func LoadConfig() (*Config, error) {
 	return config, nil
 }
 
-// GetOAuthConfig returns OAuth configuration for a provider
-func (c *Config) GetOAuthConfig(provider string) *oauth2.Config {
-	switch provider {
-	case "google":
-		return &oauth2.Config{
-			ClientID:     c.OAuth.Google.ClientID,
-			ClientSecret: c.OAuth.Google.ClientSecret,
-			RedirectURL:  c.OAuth.Google.RedirectURL,
-			Scopes: []string{
-				"https://www.googleapis.com/auth/userinfo.email",
-				"https://www.googleapis.com/auth/userinfo.profile",
-			},
+// getOAuthProvidersFromEnv returns the OAuth providers configured through
+// <PREFIX>_CLIENT_ID, <PREFIX>_CLIENT_SECRET and <PREFIX>_REDIRECT_URL for
+// Google, GitHub, Facebook and one OIDC issuer. Providers without a client ID
+// are left out.
+func getOAuthProvidersFromEnv() map[string]OAuthProviderConfig {
+	providers := make(map[string]OAuthProviderConfig)
+	for _, provider := range []struct{ name, prefix string }{
+		{"google", "GOOGLE"},
+		{"github", "GITHUB"},
+		{"facebook", "FACEBOOK"},
+		{getEnv("OIDC_PROVIDER_NAME", "oidc"), "OIDC"},
+	} {
+		clientID := getEnv(provider.prefix+"_CLIENT_ID", "")
+		if clientID == "" {
+			continue
+		}
+		providerType, issuer := provider.name, ""
+		if provider.prefix == "OIDC" {
+			providerType, issuer = "oidc", getEnv("OIDC_ISSUER", "")
+		}
+		providers[provider.name] = OAuthProviderConfig{
+			Type:         providerType,
+			ClientID:     clientID,
+			ClientSecret: getEnv(provider.prefix+"_CLIENT_SECRET", ""),
+			RedirectURL:  getEnv(provider.prefix+"_REDIRECT_URL", "http://localhost:8080/auth/oauth/"+provider.name+"/callback"),
+			Issuer:       issuer,
 		}
-	default:
-		return nil
 	}
+	return providers
 }
 
 // GetDatabaseDSN returns database connection string
@@ -331,6 +434,21 @@ func getEnvAsBool(key string, defaultValue bool) bool {
 	return defaultValue
 }
 
+// getEnvAsSlice reads a comma-separated list
+func getEnvAsSlice(key string, defaultValue []string) []string {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue
+	}
+	var values []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			values = append(values, item)
+		}
+	}
+	return values
+}
+
 // LoadFromYAML loads configuration from YAML file with environment variable overrides
 func LoadFromYAML(filename string) (*Config, error) {
 	// First try to load from YAML file
@@ -361,6 +479,11 @@ func LoadFromYAML(filename string) (*Config, error) {
 
 // mergeConfigs merges environment config into YAML config
 func mergeConfigs(yamlConfig, envConfig *Config) {
+	// App config
+	if os.Getenv("FRONTEND_URL") != "" || yamlConfig.App.FrontendURL == "" {
+		yamlConfig.App.FrontendURL = envConfig.App.FrontendURL
+	}
+
 	// Server config
 	if envConfig.Server.Host != "0.0.0.0" || yamlConfig.Server.Host == "" {
 		yamlConfig.Server.Host = envConfig.Server.Host
@@ -388,12 +511,41 @@ func mergeConfigs(yamlConfig, envConfig *Config) {
 	if os.Getenv("DB_DATABASE") != "" {
 		yamlConfig.Database.Database = envConfig.Database.Database
 	}
+	if os.Getenv("DB_SEED_DEMO_DATA") != "" {
+		yamlConfig.Database.SeedDemoData = envConfig.Database.SeedDemoData
+	}
 
 	// Auth config - environment variables always override YAML
 	if os.Getenv("JWT_SECRET") != "" {
 		yamlConfig.Auth.JWTSecret = envConfig.Auth.JWTSecret
 	}
 
+	// OAuth config - providers configured in the environment replace YAML
+	// providers of the same name
+	if os.Getenv("OAUTH_STATE_TTL") != "" || yamlConfig.OAuth.StateTTL == 0 {
+		yamlConfig.OAuth.StateTTL = envConfig.OAuth.StateTTL
+	}
+	if yamlConfig.OAuth.Providers == nil {
+		yamlConfig.OAuth.Providers = make(map[string]OAuthProviderConfig)
+	}
+	for name, provider := range envConfig.OAuth.Providers {
+		yamlConfig.OAuth.Providers[name] = provider
+	}
+
+	// WebAuthn config - environment variables always override YAML
+	if os.Getenv("WEBAUTHN_RP_ID") != "" || yamlConfig.WebAuthn.RPID == "" {
+		yamlConfig.WebAuthn.RPID = envConfig.WebAuthn.RPID
+	}
+	if os.Getenv("WEBAUTHN_RP_DISPLAY_NAME") != "" || yamlConfig.WebAuthn.RPDisplayName == "" {
+		yamlConfig.WebAuthn.RPDisplayName = envConfig.WebAuthn.RPDisplayName
+	}
+	if os.Getenv("WEBAUTHN_RP_ORIGINS") != "" || len(yamlConfig.WebAuthn.RPOrigins) == 0 {
+		yamlConfig.WebAuthn.RPOrigins = envConfig.WebAuthn.RPOrigins
+	}
+	if os.Getenv("WEBAUTHN_TIMEOUT") != "" || yamlConfig.WebAuthn.Timeout == 0 {
+		yamlConfig.WebAuthn.Timeout = envConfig.WebAuthn.Timeout
+	}
+
 	// Redis config - environment variables always override YAML
 	if os.Getenv("REDIS_HOST") != "" {
 		yamlConfig.Redis.Host = envConfig.Redis.Host
@@ -404,4 +556,79 @@ func mergeConfigs(yamlConfig, envConfig *Config) {
 	if os.Getenv("REDIS_PASSWORD") != "" {
 		yamlConfig.Redis.Password = envConfig.Redis.Password
 	}
+
+	// Rate limit config - environment variables always override YAML
+	if os.Getenv("RATE_LIMIT_ENABLED") != "" {
+		yamlConfig.RateLimit.Enabled = envConfig.RateLimit.Enabled
+	}
+	if os.Getenv("RATE_LIMIT_REQUESTS_PER_MINUTE") != "" || yamlConfig.RateLimit.RequestsPerMinute == 0 {
+		yamlConfig.RateLimit.RequestsPerMinute = envConfig.RateLimit.RequestsPerMinute
+	}
+	if os.Getenv("RATE_LIMIT_BURST_SIZE") != "" || yamlConfig.RateLimit.BurstSize == 0 {
+		yamlConfig.RateLimit.BurstSize = envConfig.RateLimit.BurstSize
+	}
+
+	// Service config - environment variables always override YAML
+	mergeServiceConfig(&yamlConfig.Services.AuthService, envConfig.Services.AuthService, "AUTH_SERVICE")
+	mergeServiceConfig(&yamlConfig.Services.ContentService, envConfig.Services.ContentService, "CONTENT_SERVICE")
+	mergeServiceConfig(&yamlConfig.Services.DiscussionService, envConfig.Services.DiscussionService, "DISCUSSION_SERVICE")
+	mergeServiceConfig(&yamlConfig.Services.GroupsService, envConfig.Services.GroupsService, "GROUPS_SERVICE")
+	mergeServiceConfig(&yamlConfig.Services.APIGateway, envConfig.Services.APIGateway, "API_GATEWAY")
+
+	// Email config - environment variables always override YAML
+	if os.Getenv("SMTP_HOST") != "" || yamlConfig.Email.SMTPHost == "" {
+		yamlConfig.Email.SMTPHost = envConfig.Email.SMTPHost
+	}
+	if os.Getenv("SMTP_PORT") != "" || yamlConfig.Email.SMTPPort == 0 {
+		yamlConfig.Email.SMTPPort = envConfig.Email.SMTPPort
+	}
+	if os.Getenv("SMTP_USERNAME") != "" {
+		yamlConfig.Email.SMTPUsername = envConfig.Email.SMTPUsername
+	}
+	if os.Getenv("SMTP_PASSWORD") != "" {
+		yamlConfig.Email.SMTPPassword = envConfig.Email.SMTPPassword
+	}
+	if os.Getenv("FROM_EMAIL") != "" || yamlConfig.Email.FromEmail == "" {
+		yamlConfig.Email.FromEmail = envConfig.Email.FromEmail
+	}
+	if os.Getenv("FROM_NAME") != "" || yamlConfig.Email.FromName == "" {
+		yamlConfig.Email.FromName = envConfig.Email.FromName
+	}
+	if os.Getenv("MAIL_DRIVER") != "" || yamlConfig.Email.Driver == "" {
+		yamlConfig.Email.Driver = envConfig.Email.Driver
+	}
+	if os.Getenv("MAIL_OUTBOX_DIR") != "" {
+		yamlConfig.Email.OutboxDir = envConfig.Email.OutboxDir
+	}
+
+	// TTS config - environment variables always override YAML
+	if os.Getenv("TTS_ENGINE") != "" || yamlConfig.TTS.Engine == "" {
+		yamlConfig.TTS.Engine = envConfig.TTS.Engine
+	}
+	if os.Getenv("TTS_BINARY") != "" {
+		yamlConfig.TTS.Binary = envConfig.TTS.Binary
+	}
+	if os.Getenv("TTS_VOICE") != "" || yamlConfig.TTS.Voice == "" {
+		yamlConfig.TTS.Voice = envConfig.TTS.Voice
+	}
+	if os.Getenv("TTS_MODEL") != "" {
+		yamlConfig.TTS.Model = envConfig.TTS.Model
+	}
+	if os.Getenv("TTS_WORDS_PER_MINUTE") != "" || yamlConfig.TTS.WordsPerMinute == 0 {
+		yamlConfig.TTS.WordsPerMinute = envConfig.TTS.WordsPerMinute
+	}
+}
+
+// mergeServiceConfig merges one service's environment config, read from the
+// <prefix>_PORT, <prefix>_URL and <prefix>_TIMEOUT variables, into its YAML config
+func mergeServiceConfig(yamlService *ServiceConfig, envService ServiceConfig, prefix string) {
+	if os.Getenv(prefix+"_PORT") != "" || yamlService.Port == 0 {
+		yamlService.Port = envService.Port
+	}
+	if os.Getenv(prefix+"_URL") != "" || yamlService.URL == "" {
+		yamlService.URL = envService.URL
+	}
+	if os.Getenv(prefix+"_TIMEOUT") != "" || yamlService.Timeout == 0 {
+		yamlService.Timeout = envService.Timeout
+	}
 }
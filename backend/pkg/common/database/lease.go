@@ -0,0 +1,98 @@
+package database
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// JobLease is a row in job_leases, naming the instance that currently runs
+// a background job
+type JobLease struct {
+	Name      string    `gorm:"primaryKey;size:100"`
+	Holder    string    `gorm:"size:255;not null"`
+	ExpiresAt time.Time `gorm:"not null"`
+}
+
+// TableName specifies the table name for JobLease
+func (JobLease) TableName() string {
+	return "job_leases"
+}
+
+// Lease lets one of several service replicas run a background job. The
+// holder renews the lease on every run; if it stops, another replica takes
+// over once the lease expires.
+type Lease struct {
+	db     *gorm.DB
+	name   string
+	holder string
+	ttl    time.Duration
+}
+
+// NewLease creates a lease on the named job. ttl must be longer than the
+// interval at which the job renews it.
+func NewLease(db *gorm.DB, name string, ttl time.Duration) *Lease {
+	return &Lease{
+		db:     db,
+		name:   name,
+		holder: leaseHolderID(),
+		ttl:    ttl,
+	}
+}
+
+// leaseHolderID identifies this process among the replicas
+func leaseHolderID() string {
+	hostname, err := os.Hostname()
+	if err != nil {
+		hostname = "unknown"
+	}
+	suffix := make([]byte, 4)
+	_, _ = rand.Read(suffix)
+	return fmt.Sprintf("%s-%d-%s", hostname, os.Getpid(), hex.EncodeToString(suffix))
+}
+
+// Holder returns the identifier this instance records as the lease holder
+func (l *Lease) Holder() string {
+	return l.holder
+}
+
+// Acquire takes the lease if it is free or expired, or renews it if this
+// instance already holds it. It reports whether this instance holds the
+// lease afterwards.
+func (l *Lease) Acquire(ctx context.Context) (bool, error) {
+	now := time.Now()
+	expiresAt := now.Add(l.ttl)
+
+	result := l.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "name"}},
+		DoUpdates: clause.Assignments(map[string]interface{}{
+			"holder":     l.holder,
+			"expires_at": expiresAt,
+		}),
+		Where: clause.Where{Exprs: []clause.Expression{
+			gorm.Expr("job_leases.holder = ? OR job_leases.expires_at < ?", l.holder, now),
+		}},
+	}).Create(&JobLease{Name: l.name, Holder: l.holder, ExpiresAt: expiresAt})
+	if result.Error != nil {
+		return false, fmt.Errorf("failed to acquire lease %s: %w", l.name, result.Error)
+	}
+	return result.RowsAffected == 1, nil
+}
+
+// Release gives up the lease so another replica can take it without waiting
+// for it to expire
+func (l *Lease) Release(ctx context.Context) error {
+	err := l.db.WithContext(ctx).
+		Where("name = ? AND holder = ?", l.name, l.holder).
+		Delete(&JobLease{}).Error
+	if err != nil {
+		return fmt.Errorf("failed to release lease %s: %w", l.name, err)
+	}
+	return nil
+}
@@ -0,0 +1,57 @@
+package database_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/yerenwgventures/GreatNigeriaLibrary-Foundation/backend/pkg/common/database"
+	"github.com/yerenwgventures/GreatNigeriaLibrary-Foundation/backend/pkg/common/database/dbtest"
+)
+
+func TestLeaseExpiryAndTakeover(t *testing.T) {
+	db := dbtest.Open(t)
+	ctx := context.Background()
+	a := database.NewLease(db, "job", time.Minute)
+	b := database.NewLease(db, "job", time.Minute)
+	other := database.NewLease(db, "other-job", time.Minute)
+
+	held, err := a.Acquire(ctx)
+	require.NoError(t, err)
+	assert.True(t, held)
+
+	held, err = b.Acquire(ctx)
+	require.NoError(t, err)
+	assert.False(t, held, "held by a")
+
+	held, err = a.Acquire(ctx)
+	require.NoError(t, err)
+	assert.True(t, held, "the holder renews")
+
+	held, err = other.Acquire(ctx)
+	require.NoError(t, err)
+	assert.True(t, held, "leases are per job")
+
+	// a stops renewing and the lease expires
+	require.NoError(t, db.Exec("UPDATE job_leases SET expires_at = NOW() - INTERVAL '1 second' WHERE name = 'job'").Error)
+
+	held, err = b.Acquire(ctx)
+	require.NoError(t, err)
+	assert.True(t, held, "b takes over the expired lease")
+
+	held, err = a.Acquire(ctx)
+	require.NoError(t, err)
+	assert.False(t, held, "a lost the lease")
+
+	require.NoError(t, a.Release(ctx))
+	held, err = a.Acquire(ctx)
+	require.NoError(t, err)
+	assert.False(t, held, "only the holder can release")
+
+	require.NoError(t, b.Release(ctx))
+	held, err = a.Acquire(ctx)
+	require.NoError(t, err)
+	assert.True(t, held, "a released lease is free straight away")
+}
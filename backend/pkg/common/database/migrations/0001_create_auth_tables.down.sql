@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS user_trust_levels;
+DROP TABLE IF EXISTS email_verification_tokens;
+DROP TABLE IF EXISTS password_reset_tokens;
+DROP TABLE IF EXISTS sessions;
+DROP TABLE IF EXISTS users;
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS demo_content;
+DROP TABLE IF EXISTS content_categories;
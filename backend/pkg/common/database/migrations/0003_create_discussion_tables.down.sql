@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS forum_replies;
+DROP TABLE IF EXISTS forum_topics;
+DROP TABLE IF EXISTS forum_categories;
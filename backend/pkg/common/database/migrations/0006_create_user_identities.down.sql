@@ -0,0 +1,14 @@
+ALTER TABLE users ADD COLUMN IF NOT EXISTS oauth_provider VARCHAR(50);
+ALTER TABLE users ADD COLUMN IF NOT EXISTS oauth_id VARCHAR(255);
+
+-- Users with several identities keep the oldest one
+UPDATE users
+SET oauth_provider = identity.provider, oauth_id = identity.subject
+FROM (
+    SELECT DISTINCT ON (user_id) user_id, provider, subject
+    FROM user_identities
+    ORDER BY user_id, created_at, id
+) AS identity
+WHERE users.id = identity.user_id;
+
+DROP TABLE IF EXISTS user_identities;
@@ -0,0 +1,26 @@
+-- External OAuth identities, so one user can sign in with several providers.
+-- Replaces the single oauth_provider and oauth_id columns of users.
+
+CREATE TABLE IF NOT EXISTS user_identities (
+    id BIGSERIAL PRIMARY KEY,
+    user_id BIGINT NOT NULL REFERENCES users(id),
+    provider VARCHAR(50) NOT NULL,
+    subject VARCHAR(255) NOT NULL,
+    email VARCHAR(255),
+    name VARCHAR(255),
+    last_login_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE,
+    updated_at TIMESTAMP WITH TIME ZONE
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_user_identities_provider_subject ON user_identities(provider, subject);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_user_identities_user_provider ON user_identities(user_id, provider);
+
+INSERT INTO user_identities (user_id, provider, subject, email, name, last_login_at, created_at, updated_at)
+SELECT id, oauth_provider, oauth_id, email, full_name, last_login, created_at, NOW()
+FROM users
+WHERE oauth_provider <> '' AND oauth_id <> '' AND deleted_at IS NULL
+ON CONFLICT DO NOTHING;
+
+ALTER TABLE users DROP COLUMN IF EXISTS oauth_provider;
+ALTER TABLE users DROP COLUMN IF EXISTS oauth_id;
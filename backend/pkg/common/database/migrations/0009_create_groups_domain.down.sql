@@ -0,0 +1,37 @@
+DROP TABLE IF EXISTS group_join_requests;
+DROP TABLE IF EXISTS group_invitations;
+DROP TABLE IF EXISTS group_comments;
+DROP TABLE IF EXISTS group_discussions;
+DROP TABLE IF EXISTS shared_resources;
+DROP TABLE IF EXISTS group_actions;
+DROP TABLE IF EXISTS event_attendees;
+DROP TABLE IF EXISTS local_events;
+DROP TABLE IF EXISTS group_members;
+DROP TABLE IF EXISTS groups;
+
+-- Restore the tables of 0004
+CREATE TABLE IF NOT EXISTS user_groups (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    name VARCHAR(255) NOT NULL,
+    description TEXT,
+    creator_id BIGINT REFERENCES users(id),
+    is_public BOOLEAN DEFAULT TRUE,
+    member_count INTEGER DEFAULT 0,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE TABLE IF NOT EXISTS group_members (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    group_id UUID REFERENCES user_groups(id) ON DELETE CASCADE,
+    user_id BIGINT REFERENCES users(id) ON DELETE CASCADE,
+    role VARCHAR(50) DEFAULT 'member',
+    joined_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    UNIQUE(group_id, user_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_group_members_group ON group_members(group_id);
+CREATE INDEX IF NOT EXISTS idx_group_members_user ON group_members(user_id);
+CREATE INDEX IF NOT EXISTS idx_user_groups_creator ON user_groups(creator_id);
@@ -0,0 +1,208 @@
+-- Groups domain tables used by the groups service. Replaces the user_groups
+-- and group_members tables of 0004, which used UUID keys no code read.
+
+DROP TABLE IF EXISTS group_members;
+DROP TABLE IF EXISTS user_groups;
+
+CREATE TABLE IF NOT EXISTS groups (
+    id BIGSERIAL PRIMARY KEY,
+    name VARCHAR(255) NOT NULL,
+    description TEXT,
+    type VARCHAR(50) NOT NULL,
+    visibility VARCHAR(50) NOT NULL DEFAULT 'public',
+    location VARCHAR(255),
+    latitude DOUBLE PRECISION DEFAULT 0,
+    longitude DOUBLE PRECISION DEFAULT 0,
+    is_virtual BOOLEAN DEFAULT FALSE,
+    join_approval_required BOOLEAN DEFAULT TRUE,
+    membership_code VARCHAR(50),
+    max_members INTEGER DEFAULT 0,
+    created_by_id BIGINT NOT NULL REFERENCES users(id),
+    banner_image VARCHAR(255),
+    avatar_image VARCHAR(255),
+    tags VARCHAR(255),
+    created_at TIMESTAMP WITH TIME ZONE,
+    updated_at TIMESTAMP WITH TIME ZONE,
+    deleted_at TIMESTAMP WITH TIME ZONE
+);
+
+CREATE INDEX IF NOT EXISTS idx_groups_deleted_at ON groups(deleted_at);
+CREATE INDEX IF NOT EXISTS idx_groups_created_by_id ON groups(created_by_id);
+
+CREATE TABLE IF NOT EXISTS group_members (
+    id BIGSERIAL PRIMARY KEY,
+    group_id BIGINT NOT NULL REFERENCES groups(id) ON DELETE CASCADE,
+    user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    role VARCHAR(50) NOT NULL DEFAULT 'member',
+    status VARCHAR(50) NOT NULL DEFAULT 'active',
+    joined_at TIMESTAMP WITH TIME ZONE,
+    invited_by BIGINT,
+    approved_by BIGINT,
+    last_active TIMESTAMP WITH TIME ZONE,
+    notes TEXT,
+    created_at TIMESTAMP WITH TIME ZONE,
+    updated_at TIMESTAMP WITH TIME ZONE,
+    deleted_at TIMESTAMP WITH TIME ZONE
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_group_members_group_user ON group_members(group_id, user_id) WHERE deleted_at IS NULL;
+CREATE INDEX IF NOT EXISTS idx_group_members_user_id ON group_members(user_id);
+CREATE INDEX IF NOT EXISTS idx_group_members_deleted_at ON group_members(deleted_at);
+
+CREATE TABLE IF NOT EXISTS local_events (
+    id BIGSERIAL PRIMARY KEY,
+    group_id BIGINT NOT NULL REFERENCES groups(id) ON DELETE CASCADE,
+    title VARCHAR(255) NOT NULL,
+    description TEXT,
+    location VARCHAR(255),
+    latitude DOUBLE PRECISION DEFAULT 0,
+    longitude DOUBLE PRECISION DEFAULT 0,
+    is_virtual BOOLEAN DEFAULT FALSE,
+    virtual_link VARCHAR(255),
+    start_time TIMESTAMP WITH TIME ZONE NOT NULL,
+    end_time TIMESTAMP WITH TIME ZONE NOT NULL,
+    status VARCHAR(50) NOT NULL DEFAULT 'planned',
+    created_by_id BIGINT NOT NULL REFERENCES users(id),
+    max_attendees INTEGER DEFAULT 0,
+    created_at TIMESTAMP WITH TIME ZONE,
+    updated_at TIMESTAMP WITH TIME ZONE,
+    deleted_at TIMESTAMP WITH TIME ZONE
+);
+
+CREATE INDEX IF NOT EXISTS idx_local_events_group_id ON local_events(group_id);
+CREATE INDEX IF NOT EXISTS idx_local_events_start_time ON local_events(start_time);
+CREATE INDEX IF NOT EXISTS idx_local_events_deleted_at ON local_events(deleted_at);
+
+CREATE TABLE IF NOT EXISTS event_attendees (
+    id BIGSERIAL PRIMARY KEY,
+    event_id BIGINT NOT NULL REFERENCES local_events(id) ON DELETE CASCADE,
+    user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    status VARCHAR(50) NOT NULL DEFAULT 'going',
+    rsvp_time TIMESTAMP WITH TIME ZONE NOT NULL,
+    checked_in BOOLEAN DEFAULT FALSE,
+    check_in_time TIMESTAMP WITH TIME ZONE,
+    notes TEXT,
+    created_at TIMESTAMP WITH TIME ZONE,
+    updated_at TIMESTAMP WITH TIME ZONE,
+    deleted_at TIMESTAMP WITH TIME ZONE
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_event_attendees_event_user ON event_attendees(event_id, user_id) WHERE deleted_at IS NULL;
+CREATE INDEX IF NOT EXISTS idx_event_attendees_user_id ON event_attendees(user_id);
+CREATE INDEX IF NOT EXISTS idx_event_attendees_deleted_at ON event_attendees(deleted_at);
+
+CREATE TABLE IF NOT EXISTS group_actions (
+    id BIGSERIAL PRIMARY KEY,
+    group_id BIGINT NOT NULL REFERENCES groups(id) ON DELETE CASCADE,
+    title VARCHAR(255) NOT NULL,
+    description TEXT,
+    status VARCHAR(50) NOT NULL DEFAULT 'pending',
+    priority VARCHAR(50) NOT NULL DEFAULT 'medium',
+    due_date TIMESTAMP WITH TIME ZONE,
+    completed_at TIMESTAMP WITH TIME ZONE,
+    created_by_id BIGINT NOT NULL REFERENCES users(id),
+    assigned_to_id BIGINT REFERENCES users(id),
+    created_at TIMESTAMP WITH TIME ZONE,
+    updated_at TIMESTAMP WITH TIME ZONE,
+    deleted_at TIMESTAMP WITH TIME ZONE
+);
+
+CREATE INDEX IF NOT EXISTS idx_group_actions_group_id ON group_actions(group_id);
+CREATE INDEX IF NOT EXISTS idx_group_actions_assigned_to_id ON group_actions(assigned_to_id);
+CREATE INDEX IF NOT EXISTS idx_group_actions_deleted_at ON group_actions(deleted_at);
+
+CREATE TABLE IF NOT EXISTS shared_resources (
+    id BIGSERIAL PRIMARY KEY,
+    group_id BIGINT,
+    event_id BIGINT REFERENCES local_events(id) ON DELETE CASCADE,
+    action_id BIGINT REFERENCES group_actions(id) ON DELETE CASCADE,
+    title VARCHAR(255) NOT NULL,
+    description TEXT,
+    type VARCHAR(50) NOT NULL,
+    url VARCHAR(255),
+    file_path VARCHAR(255),
+    file_size BIGINT DEFAULT 0,
+    mime_type VARCHAR(100),
+    created_by_id BIGINT NOT NULL REFERENCES users(id),
+    is_public BOOLEAN DEFAULT FALSE,
+    tags VARCHAR(255),
+    created_at TIMESTAMP WITH TIME ZONE,
+    updated_at TIMESTAMP WITH TIME ZONE,
+    deleted_at TIMESTAMP WITH TIME ZONE
+);
+
+CREATE INDEX IF NOT EXISTS idx_shared_resources_group_id ON shared_resources(group_id);
+CREATE INDEX IF NOT EXISTS idx_shared_resources_event_id ON shared_resources(event_id);
+CREATE INDEX IF NOT EXISTS idx_shared_resources_action_id ON shared_resources(action_id);
+CREATE INDEX IF NOT EXISTS idx_shared_resources_deleted_at ON shared_resources(deleted_at);
+
+CREATE TABLE IF NOT EXISTS group_discussions (
+    id BIGSERIAL PRIMARY KEY,
+    group_id BIGINT NOT NULL REFERENCES groups(id) ON DELETE CASCADE,
+    title VARCHAR(255) NOT NULL,
+    content TEXT,
+    created_by_id BIGINT NOT NULL REFERENCES users(id),
+    is_pinned BOOLEAN DEFAULT FALSE,
+    is_locked BOOLEAN DEFAULT FALSE,
+    created_at TIMESTAMP WITH TIME ZONE,
+    updated_at TIMESTAMP WITH TIME ZONE,
+    deleted_at TIMESTAMP WITH TIME ZONE
+);
+
+CREATE INDEX IF NOT EXISTS idx_group_discussions_group_id ON group_discussions(group_id);
+CREATE INDEX IF NOT EXISTS idx_group_discussions_deleted_at ON group_discussions(deleted_at);
+
+CREATE TABLE IF NOT EXISTS group_comments (
+    id BIGSERIAL PRIMARY KEY,
+    discussion_id BIGINT NOT NULL REFERENCES group_discussions(id) ON DELETE CASCADE,
+    content TEXT,
+    created_by_id BIGINT NOT NULL REFERENCES users(id),
+    parent_id BIGINT REFERENCES group_comments(id) ON DELETE CASCADE,
+    created_at TIMESTAMP WITH TIME ZONE,
+    updated_at TIMESTAMP WITH TIME ZONE,
+    deleted_at TIMESTAMP WITH TIME ZONE
+);
+
+CREATE INDEX IF NOT EXISTS idx_group_comments_discussion_id ON group_comments(discussion_id);
+CREATE INDEX IF NOT EXISTS idx_group_comments_parent_id ON group_comments(parent_id);
+CREATE INDEX IF NOT EXISTS idx_group_comments_deleted_at ON group_comments(deleted_at);
+
+CREATE TABLE IF NOT EXISTS group_invitations (
+    id BIGSERIAL PRIMARY KEY,
+    group_id BIGINT NOT NULL REFERENCES groups(id) ON DELETE CASCADE,
+    email VARCHAR(255),
+    user_id BIGINT REFERENCES users(id) ON DELETE CASCADE,
+    invited_by_id BIGINT NOT NULL REFERENCES users(id),
+    status VARCHAR(50) NOT NULL DEFAULT 'pending',
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    code VARCHAR(100) NOT NULL,
+    message TEXT,
+    created_at TIMESTAMP WITH TIME ZONE,
+    updated_at TIMESTAMP WITH TIME ZONE,
+    deleted_at TIMESTAMP WITH TIME ZONE
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_group_invitations_code ON group_invitations(code);
+CREATE INDEX IF NOT EXISTS idx_group_invitations_group_id ON group_invitations(group_id);
+CREATE INDEX IF NOT EXISTS idx_group_invitations_user_id ON group_invitations(user_id);
+CREATE INDEX IF NOT EXISTS idx_group_invitations_deleted_at ON group_invitations(deleted_at);
+
+CREATE TABLE IF NOT EXISTS group_join_requests (
+    id BIGSERIAL PRIMARY KEY,
+    group_id BIGINT NOT NULL REFERENCES groups(id) ON DELETE CASCADE,
+    user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    status VARCHAR(50) NOT NULL DEFAULT 'pending',
+    message TEXT,
+    approved_by BIGINT,
+    approved_at TIMESTAMP WITH TIME ZONE,
+    rejected_by BIGINT,
+    rejected_at TIMESTAMP WITH TIME ZONE,
+    code VARCHAR(100),
+    created_at TIMESTAMP WITH TIME ZONE,
+    updated_at TIMESTAMP WITH TIME ZONE,
+    deleted_at TIMESTAMP WITH TIME ZONE
+);
+
+CREATE INDEX IF NOT EXISTS idx_group_join_requests_group_id ON group_join_requests(group_id);
+CREATE INDEX IF NOT EXISTS idx_group_join_requests_user_id ON group_join_requests(user_id);
+CREATE INDEX IF NOT EXISTS idx_group_join_requests_deleted_at ON group_join_requests(deleted_at);
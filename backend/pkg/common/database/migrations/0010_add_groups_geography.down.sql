@@ -0,0 +1,9 @@
+-- The PostGIS extension is left installed, other schemas may use it
+
+DROP INDEX IF EXISTS idx_local_events_geog_geometry;
+DROP INDEX IF EXISTS idx_local_events_geog;
+DROP INDEX IF EXISTS idx_groups_geog_geometry;
+DROP INDEX IF EXISTS idx_groups_geog;
+
+ALTER TABLE local_events DROP COLUMN IF EXISTS geog;
+ALTER TABLE groups DROP COLUMN IF EXISTS geog;
@@ -0,0 +1,44 @@
+-- Geography columns for the map and distance queries of the groups service.
+-- They are generated from latitude and longitude, so the application never
+-- writes them. Without PostGIS this migration changes nothing and the groups
+-- service computes distances and clusters in Go.
+
+DO $migration$
+BEGIN
+    IF NOT EXISTS (SELECT 1 FROM pg_available_extensions WHERE name = 'postgis') THEN
+        RAISE NOTICE 'PostGIS is not available, skipping geography columns';
+        RETURN;
+    END IF;
+
+    BEGIN
+        CREATE EXTENSION IF NOT EXISTS postgis;
+    EXCEPTION WHEN insufficient_privilege THEN
+        RAISE NOTICE 'Not allowed to create the PostGIS extension, skipping geography columns';
+        RETURN;
+    END;
+
+    -- Virtual groups and events and those without coordinates have no
+    -- position, as in models.Group.Position
+    EXECUTE $sql$
+        ALTER TABLE groups ADD COLUMN IF NOT EXISTS geog geography(Point, 4326)
+        GENERATED ALWAYS AS (
+            CASE WHEN NOT COALESCE(is_virtual, FALSE) AND (latitude <> 0 OR longitude <> 0)
+            THEN ST_SetSRID(ST_MakePoint(longitude, latitude), 4326)::geography END
+        ) STORED
+    $sql$;
+    EXECUTE $sql$
+        ALTER TABLE local_events ADD COLUMN IF NOT EXISTS geog geography(Point, 4326)
+        GENERATED ALWAYS AS (
+            CASE WHEN NOT COALESCE(is_virtual, FALSE) AND (latitude <> 0 OR longitude <> 0)
+            THEN ST_SetSRID(ST_MakePoint(longitude, latitude), 4326)::geography END
+        ) STORED
+    $sql$;
+
+    -- Radius and nearest queries use the geography index, map viewports the
+    -- geometry one
+    EXECUTE 'CREATE INDEX IF NOT EXISTS idx_groups_geog ON groups USING GIST (geog)';
+    EXECUTE 'CREATE INDEX IF NOT EXISTS idx_groups_geog_geometry ON groups USING GIST ((geog::geometry))';
+    EXECUTE 'CREATE INDEX IF NOT EXISTS idx_local_events_geog ON local_events USING GIST (geog)';
+    EXECUTE 'CREATE INDEX IF NOT EXISTS idx_local_events_geog_geometry ON local_events USING GIST ((geog::geometry))';
+END
+$migration$;
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS calendar_tokens;
+
+ALTER TABLE local_events DROP COLUMN IF EXISTS sequence;
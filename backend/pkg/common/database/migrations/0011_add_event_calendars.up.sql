@@ -0,0 +1,18 @@
+-- Calendar support for group events. The sequence of an event grows with
+-- each change to its time, place or status, and calendar tokens are the
+-- secrets in the URLs of members' calendar feeds.
+
+ALTER TABLE local_events ADD COLUMN IF NOT EXISTS sequence INTEGER NOT NULL DEFAULT 0;
+
+CREATE TABLE IF NOT EXISTS calendar_tokens (
+    id BIGSERIAL PRIMARY KEY,
+    user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    token_hash VARCHAR(64) NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE,
+    updated_at TIMESTAMP WITH TIME ZONE,
+    deleted_at TIMESTAMP WITH TIME ZONE
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_calendar_tokens_user_id ON calendar_tokens(user_id) WHERE deleted_at IS NULL;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_calendar_tokens_token_hash ON calendar_tokens(token_hash);
+CREATE INDEX IF NOT EXISTS idx_calendar_tokens_deleted_at ON calendar_tokens(deleted_at);
@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS event_check_in_keys;
+
+DROP INDEX IF EXISTS idx_event_attendees_waitlist;
+
+ALTER TABLE event_attendees DROP COLUMN IF EXISTS checked_in_by_id;
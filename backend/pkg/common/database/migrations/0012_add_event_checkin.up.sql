@@ -0,0 +1,20 @@
+-- QR code check-in for group events. Each event gets an Ed25519 key pair
+-- that signs attendees' check-in tokens, and check-ins record who scanned
+-- them. Waitlisted RSVPs are promoted in RSVP order.
+
+ALTER TABLE event_attendees ADD COLUMN IF NOT EXISTS checked_in_by_id BIGINT REFERENCES users(id);
+
+CREATE INDEX IF NOT EXISTS idx_event_attendees_waitlist ON event_attendees(event_id, rsvp_time) WHERE status = 'waitlisted' AND deleted_at IS NULL;
+
+CREATE TABLE IF NOT EXISTS event_check_in_keys (
+    id BIGSERIAL PRIMARY KEY,
+    event_id BIGINT NOT NULL REFERENCES local_events(id) ON DELETE CASCADE,
+    public_key BYTEA NOT NULL,
+    private_key BYTEA NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE,
+    updated_at TIMESTAMP WITH TIME ZONE,
+    deleted_at TIMESTAMP WITH TIME ZONE
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_event_check_in_keys_event_id ON event_check_in_keys(event_id);
+CREATE INDEX IF NOT EXISTS idx_event_check_in_keys_deleted_at ON event_check_in_keys(deleted_at);
@@ -0,0 +1 @@
+ALTER TABLE IF EXISTS topics DROP COLUMN IF EXISTS is_approved;
@@ -0,0 +1,6 @@
+-- Topics held for moderator approval by their category's posting rules are
+-- hidden from listings until approved. This adds the column to an existing
+-- topics table; migration 0025 creates the topics and comments tables, both
+-- with is_approved, on new databases.
+
+ALTER TABLE IF EXISTS topics ADD COLUMN IF NOT EXISTS is_approved BOOLEAN NOT NULL DEFAULT TRUE;
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS post_classifications;
+
+DROP TABLE IF EXISTS classifier_models;
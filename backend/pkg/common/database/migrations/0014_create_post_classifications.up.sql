@@ -0,0 +1,42 @@
+-- Spam and abuse scores of discussion posts, and the classifier models that
+-- produced them. Moderators' decisions label the scores, and the classifier
+-- is retrained on the labelled ones.
+
+CREATE TABLE IF NOT EXISTS classifier_models (
+    id BIGSERIAL PRIMARY KEY,
+    examples INTEGER NOT NULL DEFAULT 0,
+    spam_examples INTEGER NOT NULL DEFAULT 0,
+    precision DOUBLE PRECISION NOT NULL DEFAULT 0,
+    recall DOUBLE PRECISION NOT NULL DEFAULT 0,
+    data TEXT NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE,
+    updated_at TIMESTAMP WITH TIME ZONE,
+    deleted_at TIMESTAMP WITH TIME ZONE
+);
+
+CREATE INDEX IF NOT EXISTS idx_classifier_models_deleted_at ON classifier_models(deleted_at);
+
+CREATE TABLE IF NOT EXISTS post_classifications (
+    id BIGSERIAL PRIMARY KEY,
+    content_type VARCHAR(20) NOT NULL,
+    content_id BIGINT NOT NULL,
+    user_id BIGINT NOT NULL,
+    spam_score DOUBLE PRECISION NOT NULL DEFAULT 0,
+    bayes_score DOUBLE PRECISION NOT NULL DEFAULT 0,
+    heuristic_score DOUBLE PRECISION NOT NULL DEFAULT 0,
+    negativity_score DOUBLE PRECISION NOT NULL DEFAULT 0,
+    signals TEXT,
+    features TEXT,
+    model_id BIGINT REFERENCES classifier_models(id) ON DELETE SET NULL,
+    label VARCHAR(10) NOT NULL DEFAULT '',
+    labeled_by BIGINT,
+    labeled_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE,
+    updated_at TIMESTAMP WITH TIME ZONE,
+    deleted_at TIMESTAMP WITH TIME ZONE
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_post_classification_content ON post_classifications(content_type, content_id);
+CREATE INDEX IF NOT EXISTS idx_post_classifications_user_id ON post_classifications(user_id);
+CREATE INDEX IF NOT EXISTS idx_post_classifications_label ON post_classifications(label);
+CREATE INDEX IF NOT EXISTS idx_post_classifications_deleted_at ON post_classifications(deleted_at);
@@ -0,0 +1,24 @@
+-- Thresholds the trust engine promotes and demotes discussion users by, and
+-- what each trust level may do. Without a row the engine uses its defaults.
+
+CREATE TABLE IF NOT EXISTS trust_settings (
+    id BIGSERIAL PRIMARY KEY,
+    basic_score DOUBLE PRECISION NOT NULL DEFAULT 20,
+    member_score DOUBLE PRECISION NOT NULL DEFAULT 50,
+    regular_score DOUBLE PRECISION NOT NULL DEFAULT 75,
+    leader_score DOUBLE PRECISION NOT NULL DEFAULT 90,
+    hysteresis DOUBLE PRECISION NOT NULL DEFAULT 5,
+    penalty_window_days INTEGER NOT NULL DEFAULT 180,
+    link_level TEXT NOT NULL DEFAULT 'basic',
+    attachment_level TEXT NOT NULL DEFAULT 'member',
+    new_user_flag_weight DOUBLE PRECISION NOT NULL DEFAULT 0.5,
+    basic_flag_weight DOUBLE PRECISION NOT NULL DEFAULT 1,
+    member_flag_weight DOUBLE PRECISION NOT NULL DEFAULT 1,
+    regular_flag_weight DOUBLE PRECISION NOT NULL DEFAULT 1.5,
+    leader_flag_weight DOUBLE PRECISION NOT NULL DEFAULT 2,
+    created_at TIMESTAMP WITH TIME ZONE,
+    updated_at TIMESTAMP WITH TIME ZONE,
+    deleted_at TIMESTAMP WITH TIME ZONE
+);
+
+CREATE INDEX IF NOT EXISTS idx_trust_settings_deleted_at ON trust_settings(deleted_at);
@@ -0,0 +1,2 @@
+ALTER TABLE trust_settings DROP COLUMN IF EXISTS hide_flag_weight;
+DROP TABLE IF EXISTS flag_aggregates;
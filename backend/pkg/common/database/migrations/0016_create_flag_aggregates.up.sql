@@ -0,0 +1,28 @@
+-- Combined weight of the flags on each topic and comment, and whether the
+-- flags hid it until a moderator confirms or reverses them.
+
+CREATE TABLE IF NOT EXISTS flag_aggregates (
+    id BIGSERIAL PRIMARY KEY,
+    content_type TEXT NOT NULL,
+    content_id BIGINT NOT NULL,
+    author_id BIGINT NOT NULL,
+    weight DOUBLE PRECISION NOT NULL DEFAULT 0,
+    flaggers INTEGER NOT NULL DEFAULT 0,
+    status TEXT NOT NULL DEFAULT 'open',
+    hidden_at TIMESTAMP WITH TIME ZONE,
+    queue_item_id BIGINT,
+    appeal_reason TEXT NOT NULL DEFAULT '',
+    appealed_at TIMESTAMP WITH TIME ZONE,
+    resolved_by BIGINT,
+    resolved_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE,
+    updated_at TIMESTAMP WITH TIME ZONE,
+    deleted_at TIMESTAMP WITH TIME ZONE
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_flag_aggregate_content ON flag_aggregates(content_type, content_id);
+CREATE INDEX IF NOT EXISTS idx_flag_aggregates_author_id ON flag_aggregates(author_id);
+CREATE INDEX IF NOT EXISTS idx_flag_aggregates_deleted_at ON flag_aggregates(deleted_at);
+
+-- Combined flag weight that hides a post
+ALTER TABLE trust_settings ADD COLUMN IF NOT EXISTS hide_flag_weight DOUBLE PRECISION NOT NULL DEFAULT 3;
@@ -0,0 +1,27 @@
+-- Users' appeals of penalties, moderation actions and hidden or rejected
+-- posts. Each decision may be appealed once.
+
+CREATE TABLE IF NOT EXISTS appeals (
+    id BIGSERIAL PRIMARY KEY,
+    decision_type TEXT NOT NULL,
+    decision_id BIGINT NOT NULL,
+    user_id BIGINT NOT NULL,
+    acting_moderator_id BIGINT NOT NULL DEFAULT 0,
+    reviewer_id BIGINT,
+    reason TEXT NOT NULL DEFAULT '',
+    status TEXT NOT NULL DEFAULT 'pending',
+    reduced_days INTEGER,
+    response TEXT NOT NULL DEFAULT '',
+    due_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    assigned_at TIMESTAMP WITH TIME ZONE,
+    resolved_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE,
+    updated_at TIMESTAMP WITH TIME ZONE,
+    deleted_at TIMESTAMP WITH TIME ZONE
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_appeal_decision ON appeals(decision_type, decision_id);
+CREATE INDEX IF NOT EXISTS idx_appeals_user_id ON appeals(user_id);
+CREATE INDEX IF NOT EXISTS idx_appeals_reviewer_id ON appeals(reviewer_id);
+CREATE INDEX IF NOT EXISTS idx_appeals_pending_due ON appeals(due_at) WHERE status = 'pending';
+CREATE INDEX IF NOT EXISTS idx_appeals_deleted_at ON appeals(deleted_at);
@@ -0,0 +1,35 @@
+-- Full-text search documents for books, chapters, sections and their front
+-- and back matter. Tags are stored as ",a,b," so single tags can be matched
+-- with LIKE, and doc_key identifies the indexed item so reindexing replaces
+-- its document instead of adding another.
+
+CREATE TABLE IF NOT EXISTS search_documents (
+    id BIGSERIAL PRIMARY KEY,
+    doc_key VARCHAR(64),
+    content_type VARCHAR(32),
+    content_id BIGINT,
+    book_id BIGINT,
+    book_title TEXT,
+    chapter_id BIGINT,
+    chapter_title TEXT,
+    chapter_number BIGINT,
+    section_id BIGINT,
+    section_title TEXT,
+    section_number BIGINT,
+    title TEXT,
+    body TEXT,
+    tags TEXT,
+    updated_at TIMESTAMP WITH TIME ZONE
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_search_documents_doc_key ON search_documents(doc_key);
+CREATE INDEX IF NOT EXISTS idx_search_documents_content_type ON search_documents(content_type);
+CREATE INDEX IF NOT EXISTS idx_search_documents_book_id ON search_documents(book_id);
+
+ALTER TABLE search_documents ADD COLUMN IF NOT EXISTS search_vector tsvector
+    GENERATED ALWAYS AS (
+        setweight(to_tsvector('english', coalesce(title, '')), 'A') ||
+        setweight(to_tsvector('english', coalesce(body, '')), 'B')
+    ) STORED;
+
+CREATE INDEX IF NOT EXISTS idx_search_documents_vector ON search_documents USING GIN (search_vector);
@@ -0,0 +1,24 @@
+-- Emails waiting to be delivered by the shared mail queue. Workers claim due
+-- rows for a lease before sending them, and failed sends are retried once
+-- next_attempt_at has passed.
+
+CREATE TABLE IF NOT EXISTS outbound_emails (
+    id BIGSERIAL PRIMARY KEY,
+    recipients TEXT NOT NULL,
+    subject TEXT,
+    text_body TEXT,
+    html_body TEXT,
+    headers TEXT,
+    attachments TEXT,
+    tag VARCHAR(64),
+    status VARCHAR(16) NOT NULL DEFAULT 'pending',
+    attempts BIGINT NOT NULL DEFAULT 0,
+    next_attempt_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    last_error TEXT,
+    sent_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE,
+    updated_at TIMESTAMP WITH TIME ZONE
+);
+
+CREATE INDEX IF NOT EXISTS idx_outbound_emails_tag ON outbound_emails(tag);
+CREATE INDEX IF NOT EXISTS idx_outbound_emails_due ON outbound_emails(status, next_attempt_at);
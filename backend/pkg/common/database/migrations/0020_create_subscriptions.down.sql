@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS subscription_digests;
+DROP TABLE IF EXISTS subscription_preferences;
+DROP TABLE IF EXISTS advanced_subscriptions;
@@ -0,0 +1,65 @@
+-- Users' subscriptions to topics, categories and tags, their digest
+-- preferences, and the digests generated for them. A digest row is written
+-- before it is sent, so an interrupted run can pick it up again.
+
+CREATE TABLE IF NOT EXISTS advanced_subscriptions (
+    id BIGSERIAL PRIMARY KEY,
+    user_id BIGINT,
+    type TEXT,
+    reference_id BIGINT,
+    frequency TEXT,
+    email_notification BOOLEAN DEFAULT TRUE,
+    push_notification BOOLEAN DEFAULT TRUE,
+    in_app_notification BOOLEAN DEFAULT TRUE,
+    muted BOOLEAN DEFAULT FALSE,
+    last_notified_at TIMESTAMP WITH TIME ZONE,
+    expires_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE,
+    updated_at TIMESTAMP WITH TIME ZONE,
+    deleted_at TIMESTAMP WITH TIME ZONE
+);
+
+CREATE INDEX IF NOT EXISTS idx_subscription_user ON advanced_subscriptions(user_id);
+CREATE INDEX IF NOT EXISTS idx_subscription_type ON advanced_subscriptions(type);
+CREATE INDEX IF NOT EXISTS idx_subscription_reference ON advanced_subscriptions(reference_id);
+CREATE INDEX IF NOT EXISTS idx_advanced_subscriptions_deleted_at ON advanced_subscriptions(deleted_at);
+
+CREATE TABLE IF NOT EXISTS subscription_preferences (
+    id BIGSERIAL PRIMARY KEY,
+    user_id BIGINT,
+    default_frequency TEXT,
+    default_email_enabled BOOLEAN DEFAULT TRUE,
+    default_push_enabled BOOLEAN DEFAULT TRUE,
+    default_in_app_enabled BOOLEAN DEFAULT TRUE,
+    digest_day BIGINT,
+    digest_hour BIGINT,
+    time_zone VARCHAR(64) DEFAULT 'UTC',
+    auto_subscribe_to_replies BOOLEAN DEFAULT TRUE,
+    auto_subscribe_to_created BOOLEAN DEFAULT TRUE,
+    created_at TIMESTAMP WITH TIME ZONE,
+    updated_at TIMESTAMP WITH TIME ZONE,
+    deleted_at TIMESTAMP WITH TIME ZONE
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_subscription_preferences_user_id ON subscription_preferences(user_id);
+CREATE INDEX IF NOT EXISTS idx_subscription_preferences_deleted_at ON subscription_preferences(deleted_at);
+
+-- One digest per user, frequency and period
+CREATE TABLE IF NOT EXISTS subscription_digests (
+    id BIGSERIAL PRIMARY KEY,
+    user_id BIGINT,
+    frequency_type TEXT,
+    period_key VARCHAR(32),
+    digest_content TEXT,
+    delivery_status TEXT,
+    scheduled_for TIMESTAMP WITH TIME ZONE,
+    sent_at TIMESTAMP WITH TIME ZONE,
+    error_message TEXT,
+    created_at TIMESTAMP WITH TIME ZONE,
+    updated_at TIMESTAMP WITH TIME ZONE,
+    deleted_at TIMESTAMP WITH TIME ZONE
+);
+
+CREATE INDEX IF NOT EXISTS idx_digest_user ON subscription_digests(user_id);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_digest_period ON subscription_digests(user_id, frequency_type, period_key);
+CREATE INDEX IF NOT EXISTS idx_subscription_digests_deleted_at ON subscription_digests(deleted_at);
@@ -0,0 +1,21 @@
+-- In-app notifications. A user never gets two notifications with the same
+-- dedup key, so an event delivered twice is only stored once.
+
+CREATE TABLE IF NOT EXISTS notifications (
+    id BIGSERIAL PRIMARY KEY,
+    user_id BIGINT NOT NULL,
+    type VARCHAR(32) NOT NULL,
+    title VARCHAR(255),
+    body TEXT,
+    link VARCHAR(512),
+    actor_id BIGINT,
+    reference_type VARCHAR(32),
+    reference_id BIGINT,
+    read_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE,
+    dedup_key VARCHAR(128)
+);
+
+CREATE INDEX IF NOT EXISTS idx_notifications_user_unread ON notifications(user_id, read_at);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_notifications_dedup ON notifications(user_id, dedup_key);
+CREATE INDEX IF NOT EXISTS idx_notifications_created_at ON notifications(created_at);
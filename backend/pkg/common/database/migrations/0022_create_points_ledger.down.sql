@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS points_earning_rules;
+DROP TABLE IF EXISTS points_transactions;
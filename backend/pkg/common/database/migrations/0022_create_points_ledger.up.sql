@@ -0,0 +1,62 @@
+-- Points ledger. users.points_balance is kept equal to the sum of a user's
+-- transactions, and earning rules configure what each action is worth.
+
+CREATE TABLE IF NOT EXISTS points_transactions (
+    id BIGSERIAL PRIMARY KEY,
+    user_id BIGINT NOT NULL,
+    points BIGINT NOT NULL,
+    transaction_type VARCHAR(50) NOT NULL,
+    description VARCHAR(255),
+    reference_id BIGINT,
+    reference_type VARCHAR(100),
+    created_at TIMESTAMP WITH TIME ZONE
+);
+
+ALTER TABLE points_transactions ADD COLUMN IF NOT EXISTS action VARCHAR(64);
+ALTER TABLE points_transactions ADD COLUMN IF NOT EXISTS balance_after BIGINT;
+ALTER TABLE points_transactions ADD COLUMN IF NOT EXISTS actor_id BIGINT;
+ALTER TABLE points_transactions ADD COLUMN IF NOT EXISTS idempotency_key VARCHAR(191);
+
+CREATE INDEX IF NOT EXISTS idx_points_transactions_user_id ON points_transactions(user_id);
+CREATE INDEX IF NOT EXISTS idx_points_transactions_transaction_type ON points_transactions(transaction_type);
+CREATE INDEX IF NOT EXISTS idx_points_transactions_reference_id ON points_transactions(reference_id);
+CREATE INDEX IF NOT EXISTS idx_points_transactions_daily ON points_transactions(user_id, action, created_at);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_points_transactions_idempotency ON points_transactions(user_id, idempotency_key);
+
+CREATE TABLE IF NOT EXISTS points_earning_rules (
+    id BIGSERIAL PRIMARY KEY,
+    action VARCHAR(64) NOT NULL,
+    points BIGINT NOT NULL,
+    daily_cap BIGINT NOT NULL DEFAULT 0,
+    enabled BOOLEAN NOT NULL DEFAULT TRUE,
+    description VARCHAR(255),
+    created_at TIMESTAMP WITH TIME ZONE,
+    updated_at TIMESTAMP WITH TIME ZONE
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_points_earning_rules_action ON points_earning_rules(action);
+
+-- Default earning rules. Admins can change them afterwards, so existing
+-- rules are left alone.
+INSERT INTO points_earning_rules (action, points, daily_cap, enabled, description, created_at, updated_at) VALUES
+    ('topic_created', 10, 50, TRUE, 'Started a discussion topic', NOW(), NOW()),
+    ('comment_created', 2, 20, TRUE, 'Commented on a discussion', NOW(), NOW()),
+    ('upvote_received', 1, 25, TRUE, 'Received an upvote', NOW(), NOW()),
+    ('topic_featured', 25, 0, TRUE, 'Topic was featured', NOW(), NOW()),
+    ('section_completed', 5, 100, TRUE, 'Completed a book section', NOW(), NOW()),
+    ('quiz_passed', 10, 100, TRUE, 'Passed a quiz', NOW(), NOW()),
+    ('element_completed', 5, 100, TRUE, 'Completed an interactive element', NOW(), NOW()),
+    ('book_completed', 50, 0, TRUE, 'Completed a book', NOW(), NOW())
+ON CONFLICT (action) DO NOTHING;
+
+-- Balances that predate the ledger become opening-balance transactions, so
+-- every balance equals the sum of its ledger. Users who already have
+-- transactions were recorded by the ledger and are left alone.
+INSERT INTO points_transactions
+    (user_id, points, transaction_type, description, action, balance_after, idempotency_key, created_at)
+SELECT u.id, u.points_balance, 'adjusted', 'Opening balance', 'opening_balance', u.points_balance, 'opening_balance', NOW()
+FROM users u
+WHERE u.deleted_at IS NULL
+  AND u.points_balance <> 0
+  AND NOT EXISTS (SELECT 1 FROM points_transactions t WHERE t.user_id = u.id)
+ON CONFLICT DO NOTHING;
@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS content_revision_logs;
+
+ALTER TABLE IF EXISTS book_sections DROP COLUMN IF EXISTS scheduled_unpublish_at;
+ALTER TABLE IF EXISTS book_chapters DROP COLUMN IF EXISTS scheduled_unpublish_at;
+ALTER TABLE IF EXISTS books DROP COLUMN IF EXISTS scheduled_unpublish_at;
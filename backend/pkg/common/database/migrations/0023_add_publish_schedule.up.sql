@@ -0,0 +1,24 @@
+-- Scheduled unpublishing and the content revision log written by the
+-- publisher. The content tables may not exist yet on a fresh database, so
+-- their columns are only added when they do.
+
+ALTER TABLE IF EXISTS books ADD COLUMN IF NOT EXISTS scheduled_unpublish_at TIMESTAMP WITH TIME ZONE;
+ALTER TABLE IF EXISTS book_chapters ADD COLUMN IF NOT EXISTS scheduled_unpublish_at TIMESTAMP WITH TIME ZONE;
+ALTER TABLE IF EXISTS book_sections ADD COLUMN IF NOT EXISTS scheduled_unpublish_at TIMESTAMP WITH TIME ZONE;
+
+CREATE TABLE IF NOT EXISTS content_revision_logs (
+    id BIGSERIAL PRIMARY KEY,
+    created_at TIMESTAMP WITH TIME ZONE,
+    updated_at TIMESTAMP WITH TIME ZONE,
+    deleted_at TIMESTAMP WITH TIME ZONE,
+    content_type TEXT,
+    content_id BIGINT,
+    revision_id BIGINT,
+    user_id BIGINT,
+    action TEXT,
+    change_summary TEXT,
+    timestamp TIMESTAMP WITH TIME ZONE
+);
+
+CREATE INDEX IF NOT EXISTS idx_content_revision_logs_deleted_at ON content_revision_logs(deleted_at);
+CREATE INDEX IF NOT EXISTS idx_content_revision_logs_content ON content_revision_logs(content_type, content_id);
@@ -0,0 +1,23 @@
+-- Background media generation jobs, such as section audio, polled by clients
+-- until they complete. Jobs are looked up by section and content hash so an
+-- unchanged section reuses its finished media.
+
+CREATE TABLE IF NOT EXISTS media_jobs (
+    id BIGSERIAL PRIMARY KEY,
+    kind VARCHAR(20) NOT NULL,
+    section_id BIGINT NOT NULL,
+    content_hash VARCHAR(64),
+    status VARCHAR(20) NOT NULL,
+    attempts BIGINT DEFAULT 0,
+    error TEXT,
+    url TEXT,
+    timing_url TEXT,
+    duration DECIMAL,
+    created_at TIMESTAMP WITH TIME ZONE,
+    updated_at TIMESTAMP WITH TIME ZONE,
+    started_at TIMESTAMP WITH TIME ZONE,
+    completed_at TIMESTAMP WITH TIME ZONE
+);
+
+CREATE INDEX IF NOT EXISTS idx_media_jobs_target ON media_jobs(kind, section_id, content_hash);
+CREATE INDEX IF NOT EXISTS idx_media_jobs_status ON media_jobs(status);
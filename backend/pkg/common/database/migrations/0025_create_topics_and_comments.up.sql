@@ -0,0 +1,52 @@
+-- Discussion topics and their comments. Posts held for moderator approval by
+-- their category's posting rules have is_approved false and are hidden from
+-- listings until approved. Databases whose tables predate this migration gain
+-- any approval column they lack.
+
+CREATE TABLE IF NOT EXISTS topics (
+    id BIGSERIAL PRIMARY KEY,
+    title TEXT,
+    content TEXT,
+    user_id BIGINT,
+    category_id BIGINT,
+    is_pinned BOOLEAN DEFAULT FALSE,
+    is_locked BOOLEAN DEFAULT FALSE,
+    is_approved BOOLEAN NOT NULL DEFAULT TRUE,
+    view_count INTEGER DEFAULT 0,
+    last_post_at TIMESTAMP WITH TIME ZONE,
+    book_id BIGINT,
+    chapter_id BIGINT,
+    section_id BIGINT,
+    created_at TIMESTAMP WITH TIME ZONE,
+    updated_at TIMESTAMP WITH TIME ZONE,
+    deleted_at TIMESTAMP WITH TIME ZONE
+);
+
+ALTER TABLE topics ADD COLUMN IF NOT EXISTS is_approved BOOLEAN NOT NULL DEFAULT TRUE;
+
+CREATE INDEX IF NOT EXISTS idx_topics_user_id ON topics(user_id);
+CREATE INDEX IF NOT EXISTS idx_topics_category_id ON topics(category_id);
+CREATE INDEX IF NOT EXISTS idx_topics_deleted_at ON topics(deleted_at);
+
+CREATE TABLE IF NOT EXISTS comments (
+    id BIGSERIAL PRIMARY KEY,
+    content TEXT,
+    user_id BIGINT,
+    topic_id BIGINT REFERENCES topics(id) ON DELETE CASCADE,
+    parent_id BIGINT REFERENCES comments(id) ON DELETE CASCADE,
+    is_approved BOOLEAN NOT NULL DEFAULT TRUE,
+    is_flagged BOOLEAN DEFAULT FALSE,
+    flag_reason TEXT,
+    edited_at TIMESTAMP WITH TIME ZONE,
+    is_edited BOOLEAN DEFAULT FALSE,
+    created_at TIMESTAMP WITH TIME ZONE,
+    updated_at TIMESTAMP WITH TIME ZONE,
+    deleted_at TIMESTAMP WITH TIME ZONE
+);
+
+ALTER TABLE comments ADD COLUMN IF NOT EXISTS is_approved BOOLEAN NOT NULL DEFAULT TRUE;
+
+CREATE INDEX IF NOT EXISTS idx_comments_user_id ON comments(user_id);
+CREATE INDEX IF NOT EXISTS idx_comments_topic_id ON comments(topic_id);
+CREATE INDEX IF NOT EXISTS idx_comments_parent_id ON comments(parent_id);
+CREATE INDEX IF NOT EXISTS idx_comments_deleted_at ON comments(deleted_at);
@@ -0,0 +1,2 @@
+ALTER TABLE flag_aggregates ADD COLUMN IF NOT EXISTS appealed_at TIMESTAMP WITH TIME ZONE;
+ALTER TABLE flag_aggregates ADD COLUMN IF NOT EXISTS appeal_reason TEXT NOT NULL DEFAULT '';
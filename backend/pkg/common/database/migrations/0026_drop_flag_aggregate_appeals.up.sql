@@ -0,0 +1,5 @@
+-- Authors appeal posts hidden by flags through the appeals service once a
+-- moderator confirms the flags, so flag aggregates no longer record appeals.
+
+ALTER TABLE flag_aggregates DROP COLUMN IF EXISTS appeal_reason;
+ALTER TABLE flag_aggregates DROP COLUMN IF EXISTS appealed_at;
@@ -0,0 +1,2 @@
+ALTER TABLE subscription_digests DROP COLUMN IF EXISTS attempts;
+ALTER TABLE subscription_digests DROP COLUMN IF EXISTS claimed_at;
@@ -0,0 +1,6 @@
+-- When sending each digest last started and how often it was tried, so that
+-- digests left sending by a stopped instance, and failed ones, are sent again
+-- a limited number of times.
+
+ALTER TABLE subscription_digests ADD COLUMN IF NOT EXISTS claimed_at TIMESTAMP WITH TIME ZONE;
+ALTER TABLE subscription_digests ADD COLUMN IF NOT EXISTS attempts INTEGER NOT NULL DEFAULT 0;
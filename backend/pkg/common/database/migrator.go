@@ -0,0 +1,516 @@
+package database
+
+import (
+	"crypto/sha256"
+	"embed"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// migrationLockID is the Postgres advisory lock key held while migrating, so
+// services starting at the same time apply migrations one after another
+const migrationLockID int64 = 7_245_913_482_117
+
+// noTransactionDirective at the top of a migration file runs it outside a
+// transaction, e.g. for CREATE INDEX CONCURRENTLY
+const noTransactionDirective = "-- migrate:no-transaction"
+
+//go:embed migrations/*.sql
+var migrationFS embed.FS
+
+//go:embed seeds/*.sql
+var seedFS embed.FS
+
+// migrationFileRegex matches migration files, e.g. 0003_create_groups.up.sql
+var migrationFileRegex = regexp.MustCompile(`^([0-9]+)_([a-z0-9_]+)\.(up|down)\.sql$`)
+
+// seedFileRegex matches seed files, e.g. 0001_demo_categories.sql
+var seedFileRegex = regexp.MustCompile(`^([0-9]+)_([a-z0-9_]+)\.sql$`)
+
+var (
+	// ErrChecksumMismatch is returned when an applied migration file was edited
+	ErrChecksumMismatch = errors.New("applied migration has been modified")
+	// ErrIrreversible is returned when rolling back a migration without a down file
+	ErrIrreversible = errors.New("migration has no down migration")
+	// ErrUnknownMigration is returned when rolling back a migration whose files are missing
+	ErrUnknownMigration = errors.New("applied migration not found in migration files")
+)
+
+// Migration is one versioned schema change
+type Migration struct {
+	Version  int64
+	Name     string
+	Up       string
+	Down     string
+	Checksum string // SHA-256 of Up
+}
+
+// ID returns the migration's file prefix, e.g. 0003_create_groups
+func (m *Migration) ID() string {
+	return fmt.Sprintf("%04d_%s", m.Version, m.Name)
+}
+
+// transactional reports whether the migration may run inside a transaction
+func transactional(sql string) bool {
+	return !strings.HasPrefix(strings.TrimSpace(sql), noTransactionDirective)
+}
+
+// SchemaMigration is a row in schema_migrations
+type SchemaMigration struct {
+	Version    int64     `gorm:"primaryKey;autoIncrement:false"`
+	Name       string    `gorm:"size:255;not null"`
+	Checksum   string    `gorm:"size:64;not null"`
+	AppliedAt  time.Time `gorm:"not null"`
+	DurationMs int64     `gorm:"not null"`
+}
+
+// TableName specifies the table name for SchemaMigration
+func (SchemaMigration) TableName() string {
+	return "schema_migrations"
+}
+
+// MigrationStatus describes one migration for the status command
+type MigrationStatus struct {
+	Version   int64
+	Name      string
+	Applied   bool
+	AppliedAt *time.Time
+	Modified  bool // the file changed after it was applied
+	Missing   bool // applied but no longer in the migration files
+}
+
+// Seed is a file of idempotent demo data
+type Seed struct {
+	Name string
+	SQL  string
+}
+
+// LoadMigrations reads the migrations in dir, sorted by version. Every
+// version needs an up file; the down file is optional.
+func LoadMigrations(fsys fs.FS, dir string) ([]Migration, error) {
+	entries, err := fs.ReadDir(fsys, dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read migrations: %w", err)
+	}
+
+	byVersion := make(map[int64]*Migration)
+	for _, entry := range entries {
+		match := migrationFileRegex.FindStringSubmatch(entry.Name())
+		if entry.IsDir() || match == nil {
+			continue
+		}
+		version, err := strconv.ParseInt(match[1], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid migration version in %s: %w", entry.Name(), err)
+		}
+
+		migration := byVersion[version]
+		if migration == nil {
+			migration = &Migration{Version: version, Name: match[2]}
+			byVersion[version] = migration
+		} else if migration.Name != match[2] {
+			return nil, fmt.Errorf("duplicate migration version %d: %s and %s", version, migration.Name, match[2])
+		}
+
+		content, err := fs.ReadFile(fsys, path.Join(dir, entry.Name()))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", entry.Name(), err)
+		}
+		if match[3] == "up" {
+			migration.Up = string(content)
+		} else {
+			migration.Down = string(content)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, migration := range byVersion {
+		if strings.TrimSpace(migration.Up) == "" {
+			return nil, fmt.Errorf("migration %s has no up migration", migration.ID())
+		}
+		sum := sha256.Sum256([]byte(migration.Up))
+		migration.Checksum = hex.EncodeToString(sum[:])
+		migrations = append(migrations, *migration)
+	}
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].Version < migrations[j].Version
+	})
+
+	return migrations, nil
+}
+
+// LoadSeeds reads the seed files in dir, sorted by name
+func LoadSeeds(fsys fs.FS, dir string) ([]Seed, error) {
+	entries, err := fs.ReadDir(fsys, dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read seeds: %w", err)
+	}
+
+	var seeds []Seed
+	for _, entry := range entries {
+		if entry.IsDir() || !seedFileRegex.MatchString(entry.Name()) {
+			continue
+		}
+		content, err := fs.ReadFile(fsys, path.Join(dir, entry.Name()))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", entry.Name(), err)
+		}
+		seeds = append(seeds, Seed{Name: strings.TrimSuffix(entry.Name(), ".sql"), SQL: string(content)})
+	}
+
+	// fs.ReadDir returns entries sorted by file name
+	return seeds, nil
+}
+
+// CreateMigration writes an empty up/down pair to dir, numbered after the
+// newest existing migration, and returns the paths
+func CreateMigration(dir, name string) (string, string, error) {
+	name = strings.ToLower(strings.Join(strings.FieldsFunc(name, func(r rune) bool {
+		return !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9')
+	}), "_"))
+	if name == "" {
+		return "", "", errors.New("migration name is required")
+	}
+
+	migrations, err := LoadMigrations(os.DirFS(dir), ".")
+	if err != nil {
+		return "", "", err
+	}
+	var version int64 = 1
+	if len(migrations) > 0 {
+		version = migrations[len(migrations)-1].Version + 1
+	}
+
+	migration := Migration{Version: version, Name: name}
+	upPath := filepath.Join(dir, migration.ID()+".up.sql")
+	downPath := filepath.Join(dir, migration.ID()+".down.sql")
+
+	header := fmt.Sprintf("-- %s\n", migration.ID())
+	if err := os.WriteFile(upPath, []byte(header+"\n"), 0o644); err != nil {
+		return "", "", fmt.Errorf("failed to create %s: %w", upPath, err)
+	}
+	if err := os.WriteFile(downPath, []byte(header+"\n"), 0o644); err != nil {
+		return "", "", fmt.Errorf("failed to create %s: %w", downPath, err)
+	}
+
+	return upPath, downPath, nil
+}
+
+// Migrator applies versioned SQL migrations and records them in
+// schema_migrations. Applied migrations are checksummed; editing one after
+// it ran is an error, so schema changes always go in a new migration.
+type Migrator struct {
+	db         *gorm.DB
+	migrations []Migration
+	seeds      []Seed
+	logger     Logger
+}
+
+// NewMigrator creates a migrator for the migrations and seeds embedded in
+// this package
+func NewMigrator(db *gorm.DB, logger Logger) (*Migrator, error) {
+	migrations, err := LoadMigrations(migrationFS, "migrations")
+	if err != nil {
+		return nil, err
+	}
+	seeds, err := LoadSeeds(seedFS, "seeds")
+	if err != nil {
+		return nil, err
+	}
+	return NewMigratorWith(db, migrations, seeds, logger), nil
+}
+
+// NewMigratorWith creates a migrator for the given migrations and seeds
+func NewMigratorWith(db *gorm.DB, migrations []Migration, seeds []Seed, logger Logger) *Migrator {
+	return &Migrator{
+		db:         db,
+		migrations: migrations,
+		seeds:      seeds,
+		logger:     logger,
+	}
+}
+
+// Status lists every known migration and whether it has been applied
+func (m *Migrator) Status() ([]MigrationStatus, error) {
+	applied, err := m.applied(m.db)
+	if err != nil {
+		return nil, err
+	}
+	return status(m.migrations, applied), nil
+}
+
+// Up applies pending migrations in version order and returns how many ran.
+// A limit of 0 applies all of them.
+func (m *Migrator) Up(limit int) (int, error) {
+	count := 0
+	err := m.withLock(func(conn *gorm.DB) error {
+		applied, err := m.applied(conn)
+		if err != nil {
+			return err
+		}
+		pending, err := pendingMigrations(m.migrations, applied)
+		if err != nil {
+			return err
+		}
+		if limit > 0 && len(pending) > limit {
+			pending = pending[:limit]
+		}
+
+		for i := range pending {
+			if err := m.apply(conn, &pending[i]); err != nil {
+				return err
+			}
+			count++
+		}
+		return nil
+	})
+	return count, err
+}
+
+// Down rolls back the most recently applied migrations and returns how many
+// were rolled back
+func (m *Migrator) Down(steps int) (int, error) {
+	if steps < 1 {
+		steps = 1
+	}
+
+	count := 0
+	err := m.withLock(func(conn *gorm.DB) error {
+		rollbacks, err := m.rollbacks(conn, steps)
+		if err != nil {
+			return err
+		}
+		for i := range rollbacks {
+			if err := m.rollback(conn, &rollbacks[i]); err != nil {
+				return err
+			}
+			count++
+		}
+		return nil
+	})
+	return count, err
+}
+
+// Redo rolls back the most recently applied migration and applies it again
+func (m *Migrator) Redo() error {
+	return m.withLock(func(conn *gorm.DB) error {
+		rollbacks, err := m.rollbacks(conn, 1)
+		if err != nil {
+			return err
+		}
+		if len(rollbacks) == 0 {
+			return errors.New("no migrations have been applied")
+		}
+		if err := m.rollback(conn, &rollbacks[0]); err != nil {
+			return err
+		}
+		return m.apply(conn, &rollbacks[0])
+	})
+}
+
+// Seed loads the demo data. Seed files must be safe to run repeatedly.
+func (m *Migrator) Seed() (int, error) {
+	count := 0
+	err := m.withLock(func(conn *gorm.DB) error {
+		for _, seed := range m.seeds {
+			if err := conn.Exec(seed.SQL).Error; err != nil {
+				return fmt.Errorf("seed %s failed: %w", seed.Name, err)
+			}
+			m.logger.Info("Loaded seed " + seed.Name)
+			count++
+		}
+		return nil
+	})
+	return count, err
+}
+
+// withLock runs fn on a single connection holding the migration advisory lock
+func (m *Migrator) withLock(fn func(conn *gorm.DB) error) error {
+	return m.db.Connection(func(conn *gorm.DB) error {
+		if err := conn.Exec("SELECT pg_advisory_lock(?)", migrationLockID).Error; err != nil {
+			return fmt.Errorf("failed to acquire migration lock: %w", err)
+		}
+		defer func() {
+			if err := conn.Exec("SELECT pg_advisory_unlock(?)", migrationLockID).Error; err != nil {
+				m.logger.Error("Failed to release migration lock: " + err.Error())
+			}
+		}()
+
+		err := conn.Exec(`
+			CREATE TABLE IF NOT EXISTS schema_migrations (
+				version BIGINT PRIMARY KEY,
+				name VARCHAR(255) NOT NULL,
+				checksum VARCHAR(64) NOT NULL,
+				applied_at TIMESTAMP WITH TIME ZONE NOT NULL,
+				duration_ms BIGINT NOT NULL
+			)
+		`).Error
+		if err != nil {
+			return fmt.Errorf("failed to create schema_migrations: %w", err)
+		}
+
+		return fn(conn)
+	})
+}
+
+// applied returns the recorded migrations keyed by version
+func (m *Migrator) applied(conn *gorm.DB) (map[int64]SchemaMigration, error) {
+	applied := make(map[int64]SchemaMigration)
+	if !conn.Migrator().HasTable(&SchemaMigration{}) {
+		return applied, nil
+	}
+
+	var rows []SchemaMigration
+	if err := conn.Order("version").Find(&rows).Error; err != nil {
+		return nil, fmt.Errorf("failed to read schema_migrations: %w", err)
+	}
+	for _, row := range rows {
+		applied[row.Version] = row
+	}
+	return applied, nil
+}
+
+// rollbacks returns the migrations to roll back, newest first
+func (m *Migrator) rollbacks(conn *gorm.DB, steps int) ([]Migration, error) {
+	var rows []SchemaMigration
+	if err := conn.Order("version DESC").Limit(steps).Find(&rows).Error; err != nil {
+		return nil, fmt.Errorf("failed to read schema_migrations: %w", err)
+	}
+
+	known := make(map[int64]Migration, len(m.migrations))
+	for _, migration := range m.migrations {
+		known[migration.Version] = migration
+	}
+
+	migrations := make([]Migration, 0, len(rows))
+	for _, row := range rows {
+		migration, ok := known[row.Version]
+		if !ok {
+			return nil, fmt.Errorf("%w: %04d_%s", ErrUnknownMigration, row.Version, row.Name)
+		}
+		if strings.TrimSpace(migration.Down) == "" {
+			return nil, fmt.Errorf("%w: %s", ErrIrreversible, migration.ID())
+		}
+		migrations = append(migrations, migration)
+	}
+	return migrations, nil
+}
+
+// apply runs an up migration and records it
+func (m *Migrator) apply(conn *gorm.DB, migration *Migration) error {
+	start := time.Now()
+	run := func(tx *gorm.DB) error {
+		if err := tx.Exec(migration.Up).Error; err != nil {
+			return err
+		}
+		return tx.Create(&SchemaMigration{
+			Version:    migration.Version,
+			Name:       migration.Name,
+			Checksum:   migration.Checksum,
+			AppliedAt:  time.Now(),
+			DurationMs: time.Since(start).Milliseconds(),
+		}).Error
+	}
+
+	var err error
+	if transactional(migration.Up) {
+		err = conn.Transaction(run)
+	} else {
+		err = run(conn)
+	}
+	if err != nil {
+		return fmt.Errorf("migration %s failed: %w", migration.ID(), err)
+	}
+
+	m.logger.Info(fmt.Sprintf("Applied migration %s (%s)", migration.ID(), time.Since(start).Round(time.Millisecond)))
+	return nil
+}
+
+// rollback runs a down migration and removes its record
+func (m *Migrator) rollback(conn *gorm.DB, migration *Migration) error {
+	run := func(tx *gorm.DB) error {
+		if err := tx.Exec(migration.Down).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&SchemaMigration{}, migration.Version).Error
+	}
+
+	var err error
+	if transactional(migration.Down) {
+		err = conn.Transaction(run)
+	} else {
+		err = run(conn)
+	}
+	if err != nil {
+		return fmt.Errorf("rollback of %s failed: %w", migration.ID(), err)
+	}
+
+	m.logger.Info("Rolled back migration " + migration.ID())
+	return nil
+}
+
+// pendingMigrations returns the migrations that have not been applied. It
+// fails if an applied migration's file has changed since it ran.
+func pendingMigrations(migrations []Migration, applied map[int64]SchemaMigration) ([]Migration, error) {
+	var pending []Migration
+	for _, migration := range migrations {
+		row, ok := applied[migration.Version]
+		if !ok {
+			pending = append(pending, migration)
+			continue
+		}
+		if row.Checksum != migration.Checksum {
+			return nil, fmt.Errorf("%w: %s", ErrChecksumMismatch, migration.ID())
+		}
+	}
+	return pending, nil
+}
+
+// status merges the migration files with the applied rows
+func status(migrations []Migration, applied map[int64]SchemaMigration) []MigrationStatus {
+	statuses := make([]MigrationStatus, 0, len(migrations))
+	known := make(map[int64]bool, len(migrations))
+
+	for _, migration := range migrations {
+		known[migration.Version] = true
+		s := MigrationStatus{Version: migration.Version, Name: migration.Name}
+		if row, ok := applied[migration.Version]; ok {
+			appliedAt := row.AppliedAt
+			s.Applied = true
+			s.AppliedAt = &appliedAt
+			s.Modified = row.Checksum != migration.Checksum
+		}
+		statuses = append(statuses, s)
+	}
+
+	for version, row := range applied {
+		if known[version] {
+			continue
+		}
+		appliedAt := row.AppliedAt
+		statuses = append(statuses, MigrationStatus{
+			Version:   version,
+			Name:      row.Name,
+			Applied:   true,
+			AppliedAt: &appliedAt,
+			Missing:   true,
+		})
+	}
+
+	sort.Slice(statuses, func(i, j int) bool {
+		return statuses[i].Version < statuses[j].Version
+	})
+	return statuses
+}
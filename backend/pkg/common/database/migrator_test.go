@@ -0,0 +1,102 @@
+package database
+
+import (
+	"errors"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadMigrations(t *testing.T) {
+	fsys := fstest.MapFS{
+		"m/0002_add_index.up.sql":  {Data: []byte("CREATE INDEX a ON t(a);")},
+		"m/0001_create_t.up.sql":   {Data: []byte("CREATE TABLE t (a INT);")},
+		"m/0001_create_t.down.sql": {Data: []byte("DROP TABLE t;")},
+		"m/README.md":              {Data: []byte("not a migration")},
+		"m/0003_notes.txt":         {Data: []byte("ignored")},
+	}
+
+	migrations, err := LoadMigrations(fsys, "m")
+	require.NoError(t, err)
+	require.Len(t, migrations, 2)
+
+	assert.Equal(t, int64(1), migrations[0].Version)
+	assert.Equal(t, "0001_create_t", migrations[0].ID())
+	assert.Equal(t, "DROP TABLE t;", migrations[0].Down)
+	assert.Len(t, migrations[0].Checksum, 64)
+	assert.Equal(t, "add_index", migrations[1].Name)
+	assert.Empty(t, migrations[1].Down)
+}
+
+func TestLoadMigrationsRejectsInvalidSets(t *testing.T) {
+	_, err := LoadMigrations(fstest.MapFS{
+		"m/0001_create_t.down.sql": {Data: []byte("DROP TABLE t;")},
+	}, "m")
+	assert.Error(t, err, "a down file without an up file")
+
+	_, err = LoadMigrations(fstest.MapFS{
+		"m/0001_create_t.up.sql": {Data: []byte("CREATE TABLE t (a INT);")},
+		"m/0001_create_u.up.sql": {Data: []byte("CREATE TABLE u (a INT);")},
+	}, "m")
+	assert.Error(t, err, "two migrations with the same version")
+}
+
+func TestPendingMigrationsDetectsModifiedFiles(t *testing.T) {
+	migrations := []Migration{
+		{Version: 1, Name: "one", Checksum: "aaa"},
+		{Version: 2, Name: "two", Checksum: "bbb"},
+		{Version: 3, Name: "three", Checksum: "ccc"},
+	}
+
+	pending, err := pendingMigrations(migrations, map[int64]SchemaMigration{
+		1: {Version: 1, Checksum: "aaa"},
+	})
+	require.NoError(t, err)
+	require.Len(t, pending, 2)
+	assert.Equal(t, int64(2), pending[0].Version)
+
+	_, err = pendingMigrations(migrations, map[int64]SchemaMigration{
+		1: {Version: 1, Checksum: "edited"},
+	})
+	assert.True(t, errors.Is(err, ErrChecksumMismatch))
+}
+
+func TestStatus(t *testing.T) {
+	appliedAt := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	statuses := status(
+		[]Migration{
+			{Version: 1, Name: "one", Checksum: "aaa"},
+			{Version: 3, Name: "three", Checksum: "ccc"},
+		},
+		map[int64]SchemaMigration{
+			1: {Version: 1, Name: "one", Checksum: "changed", AppliedAt: appliedAt},
+			2: {Version: 2, Name: "two", Checksum: "bbb", AppliedAt: appliedAt},
+		},
+	)
+
+	require.Len(t, statuses, 3)
+	assert.True(t, statuses[0].Applied)
+	assert.True(t, statuses[0].Modified)
+	assert.True(t, statuses[1].Missing)
+	assert.Equal(t, "two", statuses[1].Name)
+	assert.False(t, statuses[2].Applied)
+	assert.Nil(t, statuses[2].AppliedAt)
+}
+
+func TestEmbeddedMigrationsAreReversible(t *testing.T) {
+	migrations, err := LoadMigrations(migrationFS, "migrations")
+	require.NoError(t, err)
+	require.NotEmpty(t, migrations)
+
+	for i, migration := range migrations {
+		assert.Equal(t, int64(i+1), migration.Version, "versions are consecutive")
+		assert.NotEmpty(t, migration.Down, "%s has a down migration", migration.ID())
+	}
+
+	seeds, err := LoadSeeds(seedFS, "seeds")
+	require.NoError(t, err)
+	assert.NotEmpty(t, seeds)
+}
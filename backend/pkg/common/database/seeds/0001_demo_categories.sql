@@ -0,0 +1,16 @@
+-- Demo content and forum categories for development. Safe to run repeatedly.
+
+INSERT INTO content_categories (name, slug, description) VALUES
+    ('Platform Guide', 'platform-guide', 'How to use the Great Nigeria Library platform'),
+    ('Educational Content', 'educational', 'Educational materials and resources'),
+    ('Community Guidelines', 'community', 'Community rules and guidelines')
+ON CONFLICT (slug) DO NOTHING;
+
+INSERT INTO forum_categories (name, description, sort_order)
+SELECT v.name, v.description, v.sort_order
+FROM (VALUES
+    ('General Discussion', 'General topics and discussions', 1),
+    ('Platform Help', 'Help and support for using the platform', 2),
+    ('Feature Requests', 'Suggest new features for the platform', 3)
+) AS v(name, description, sort_order)
+WHERE NOT EXISTS (SELECT 1 FROM forum_categories f WHERE f.name = v.name);
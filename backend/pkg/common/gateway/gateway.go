@@ -0,0 +1,100 @@
+// Package gateway implements the API gateway in front of the backend
+// services. Route prefixes are rewritten and proxied to upstream services
+// with per-route timeouts, retries for idempotent requests and a circuit
+// breaker per upstream. JWTs are verified once at the gateway and the caller's identity is
+// forwarded to upstreams in X-User-* headers.
+package gateway
+
+import (
+	"context"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Route maps a path prefix to an upstream. The prefix is replaced with
+// Rewrite before the request is forwarded, so a route with prefix
+// /api/v1/discussions and rewrite /discussions sends
+// /api/v1/discussions/7 to the discussion service as /discussions/7.
+// Prefixes may nest; a request goes to the route with the longest
+// matching prefix.
+type Route struct {
+	Prefix   string
+	Rewrite  string
+	Upstream *Upstream
+	// Timeout bounds the whole proxied request, including retries. Zero
+	// means no timeout. Event streams are never timed out.
+	Timeout time.Duration
+}
+
+// Register adds the routes to router. Routes nested under another route's
+// prefix share its handler, since gin cannot register both.
+func Register(router gin.IRouter, routes []Route) {
+	sorted := make([]Route, len(routes))
+	for i, route := range routes {
+		route.Prefix = strings.TrimRight(route.Prefix, "/")
+		sorted[i] = route
+	}
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return len(sorted[i].Prefix) > len(sorted[j].Prefix)
+	})
+
+	for i, route := range sorted {
+		nested := false
+		for _, outer := range sorted[i+1:] {
+			if hasPathPrefix(route.Prefix, outer.Prefix) {
+				nested = true
+				break
+			}
+		}
+		if nested {
+			continue
+		}
+
+		handler := proxyHandler(sorted)
+		router.Any(route.Prefix, handler)
+		router.Any(route.Prefix+"/*path", handler)
+	}
+}
+
+// proxyHandler proxies requests to the route with the longest prefix
+// matching the path. routes must be sorted longest prefix first.
+func proxyHandler(routes []Route) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var route *Route
+		for i := range routes {
+			if hasPathPrefix(c.Request.URL.Path, routes[i].Prefix) {
+				route = &routes[i]
+				break
+			}
+		}
+		if route == nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Not found"})
+			return
+		}
+
+		req := rewritePath(c.Request, route.Prefix, route.Rewrite)
+
+		if route.Timeout > 0 && !isEventStream(req) {
+			ctx, cancel := context.WithTimeout(req.Context(), route.Timeout)
+			defer cancel()
+			req = req.WithContext(ctx)
+		}
+
+		route.Upstream.ServeHTTP(c.Writer, req)
+	}
+}
+
+// hasPathPrefix reports whether path is prefix or lies below it
+func hasPathPrefix(path, prefix string) bool {
+	return path == prefix || strings.HasPrefix(path, prefix+"/")
+}
+
+// isEventStream reports whether the client asked for a Server-Sent Events
+// stream, which stays open for as long as the client is connected
+func isEventStream(r *http.Request) bool {
+	return strings.Contains(r.Header.Get("Accept"), "text/event-stream")
+}
@@ -0,0 +1,197 @@
+package gateway
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/yerenwgventures/GreatNigeriaLibrary-Foundation/backend/pkg/common/auth"
+)
+
+type stubValidator struct{}
+
+func (stubValidator) ValidateToken(token string) (*auth.Claims, error) {
+	if token != "good" {
+		return nil, errors.New("invalid token")
+	}
+	return &auth.Claims{UserID: 42, Username: "ada", Role: 2, TokenType: "access"}, nil
+}
+
+func newTestGateway(t *testing.T, backend http.HandlerFunc) (*gin.Engine, *Upstream) {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+
+	server := httptest.NewServer(backend)
+	t.Cleanup(server.Close)
+
+	upstream, err := NewUpstream("content", server.URL, UpstreamOptions{
+		RetryBackoff:     time.Millisecond,
+		FailureThreshold: 2,
+		OpenTimeout:      time.Hour,
+	})
+	require.NoError(t, err)
+
+	router := gin.New()
+	router.Use(RequestID(), Authenticate(stubValidator{}))
+	Register(router, []Route{{Prefix: "/api/v1/content", Rewrite: "/content", Upstream: upstream, Timeout: time.Second}})
+	return router, upstream
+}
+
+func TestProxyRewritesPrefixAndForwardsIdentity(t *testing.T) {
+	router, _ := newTestGateway(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/content/books/1", r.URL.Path)
+		assert.Equal(t, "/api/v1", r.Header.Get(HeaderForwardedPrefix))
+		assert.Equal(t, "42", r.Header.Get(HeaderUserID))
+		assert.Equal(t, "2", r.Header.Get(HeaderUserRole))
+		assert.NotEmpty(t, r.Header.Get(HeaderRequestID))
+		io.WriteString(w, "ok")
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/content/books/1", nil)
+	req.Header.Set("Authorization", "Bearer good")
+	req.Header.Set(HeaderUserID, "1")                   // spoofed, must be replaced
+	req.Header.Set(HeaderForwardedPrefix, "/elsewhere") // spoofed, must be replaced
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "ok", w.Body.String())
+	assert.NotEmpty(t, w.Header().Get(HeaderRequestID))
+}
+
+func TestProxyRoutesNestedPrefixesToTheLongestMatch(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	newUpstream := func(name string) *Upstream {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			io.WriteString(w, name+" "+r.URL.Path)
+		}))
+		t.Cleanup(server.Close)
+		upstream, err := NewUpstream(name, server.URL, UpstreamOptions{})
+		require.NoError(t, err)
+		return upstream
+	}
+	auth, content := newUpstream("auth"), newUpstream("content")
+
+	router := gin.New()
+	Register(router, []Route{
+		{Prefix: "/api/v1/content/admin", Rewrite: "/admin", Upstream: content, Timeout: time.Second},
+		{Prefix: "/api/v1/auth", Rewrite: "/auth", Upstream: auth, Timeout: time.Second},
+		{Prefix: "/api/v1/content", Rewrite: "/content", Upstream: content, Timeout: time.Second},
+		{Prefix: "/api/v1/content/public", Rewrite: "/public", Upstream: content, Timeout: time.Second},
+		{Prefix: "/api/v1/search", Rewrite: "/api/v1/search", Upstream: content, Timeout: time.Second},
+		{Prefix: "/api/v1/root", Rewrite: "", Upstream: auth, Timeout: time.Second},
+	})
+
+	cases := map[string]string{
+		"/api/v1/auth/login":              "auth /auth/login",
+		"/api/v1/auth":                    "auth /auth",
+		"/api/v1/content/books/1":         "content /content/books/1",
+		"/api/v1/content/public/books":    "content /public/books",
+		"/api/v1/content/admin/content/7": "content /admin/content/7",
+		"/api/v1/content/publications":    "content /content/publications",
+		"/api/v1/search":                  "content /api/v1/search",
+		"/api/v1/search/suggest":          "content /api/v1/search/suggest",
+		"/api/v1/root":                    "auth /",
+		"/api/v1/root/health":             "auth /health",
+	}
+	for path, want := range cases {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+		assert.Equal(t, http.StatusOK, w.Code, path)
+		assert.Equal(t, want, w.Body.String(), path)
+	}
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/authors", nil))
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestProxyRejectsInvalidTokenAndStripsSpoofedIdentity(t *testing.T) {
+	router, _ := newTestGateway(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Empty(t, r.Header.Get(HeaderUserID))
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/content/books", nil)
+	req.Header.Set("Authorization", "Bearer bad")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+
+	req = httptest.NewRequest(http.MethodGet, "/api/v1/content/books", nil)
+	req.Header.Set(HeaderUserID, "1")
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+}
+
+func TestProxyRetriesOnlyIdempotentRequests(t *testing.T) {
+	var calls int32
+	router, _ := newTestGateway(t, func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		assert.Equal(t, "payload", string(body))
+		if atomic.AddInt32(&calls, 1) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	req := httptest.NewRequest(http.MethodPut, "/api/v1/content/books/1", strings.NewReader("payload"))
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusNoContent, w.Code)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&calls))
+
+	atomic.StoreInt32(&calls, 0)
+	req = httptest.NewRequest(http.MethodPost, "/api/v1/content/books", strings.NewReader("payload"))
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+}
+
+func TestCircuitOpensAfterRepeatedFailures(t *testing.T) {
+	var calls int32
+	router, upstream := newTestGateway(t, func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.WriteHeader(http.StatusBadGateway)
+	})
+
+	for i := 0; i < 2; i++ {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/api/v1/content/books", nil))
+		assert.Equal(t, http.StatusBadGateway, w.Code)
+	}
+	assert.Equal(t, StateOpen, upstream.Circuit())
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/api/v1/content/books", nil))
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&calls))
+}
+
+func TestBreakerHalfOpenProbe(t *testing.T) {
+	now := time.Now()
+	breaker := NewBreaker(1, time.Minute)
+	breaker.now = func() time.Time { return now }
+
+	breaker.Failure()
+	assert.False(t, breaker.Allow())
+
+	now = now.Add(time.Minute)
+	assert.True(t, breaker.Allow())
+	assert.False(t, breaker.Allow(), "only one probe at a time")
+
+	breaker.Success()
+	assert.Equal(t, StateClosed, breaker.State())
+	assert.True(t, breaker.Allow())
+}
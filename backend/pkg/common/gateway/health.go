@@ -0,0 +1,93 @@
+package gateway
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// DefaultHealthTimeout bounds each upstream health check
+const DefaultHealthTimeout = 3 * time.Second
+
+// UpstreamHealth is the health of one upstream
+type UpstreamHealth struct {
+	Status    string `json:"status"` // up or down
+	Circuit   string `json:"circuit"`
+	LatencyMs int64  `json:"latencyMs"`
+	Error     string `json:"error,omitempty"`
+}
+
+// CheckHealth calls the upstream's health endpoint. It bypasses the circuit
+// breaker so an open circuit does not hide a recovered service.
+func (u *Upstream) CheckHealth(ctx context.Context) UpstreamHealth {
+	health := UpstreamHealth{Status: "down", Circuit: u.Circuit().String()}
+	start := time.Now()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.target.JoinPath(u.healthPath).String(), nil)
+	if err != nil {
+		health.Error = err.Error()
+		return health
+	}
+
+	resp, err := u.client.Do(req)
+	health.LatencyMs = time.Since(start).Milliseconds()
+	if err != nil {
+		health.Error = err.Error()
+		return health
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		health.Error = fmt.Sprintf("health check returned %d", resp.StatusCode)
+		return health
+	}
+
+	health.Status = "up"
+	return health
+}
+
+// HealthHandler reports the gateway as healthy only when every upstream is.
+// Upstreams are checked concurrently.
+func HealthHandler(upstreams []*Upstream, timeout time.Duration) gin.HandlerFunc {
+	if timeout <= 0 {
+		timeout = DefaultHealthTimeout
+	}
+
+	return func(c *gin.Context) {
+		ctx, cancel := context.WithTimeout(c.Request.Context(), timeout)
+		defer cancel()
+
+		results := make(map[string]UpstreamHealth, len(upstreams))
+		var mu sync.Mutex
+		var wg sync.WaitGroup
+		for _, upstream := range upstreams {
+			wg.Add(1)
+			go func(u *Upstream) {
+				defer wg.Done()
+				health := u.CheckHealth(ctx)
+				mu.Lock()
+				results[u.Name] = health
+				mu.Unlock()
+			}(upstream)
+		}
+		wg.Wait()
+
+		status, code := "healthy", http.StatusOK
+		for _, health := range results {
+			if health.Status != "up" {
+				status, code = "degraded", http.StatusServiceUnavailable
+				break
+			}
+		}
+
+		c.JSON(code, gin.H{
+			"status":    status,
+			"timestamp": time.Now().Format(time.RFC3339),
+			"services":  results,
+		})
+	}
+}
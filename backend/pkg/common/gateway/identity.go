@@ -0,0 +1,78 @@
+package gateway
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/yerenwgventures/GreatNigeriaLibrary-Foundation/backend/pkg/common/auth"
+)
+
+// Identity headers forwarded to upstreams. They are only ever set by the
+// gateway; values sent by clients are removed.
+const (
+	HeaderUserID          = "X-User-ID"
+	HeaderUserName        = "X-User-Name"
+	HeaderUserEmail       = "X-User-Email"
+	HeaderUserRole        = "X-User-Role"
+	HeaderUserPermissions = "X-User-Permissions"
+	HeaderSessionID       = "X-Session-ID"
+)
+
+var identityHeaders = []string{
+	HeaderUserID,
+	HeaderUserName,
+	HeaderUserEmail,
+	HeaderUserRole,
+	HeaderUserPermissions,
+	HeaderSessionID,
+}
+
+// TokenValidator validates access tokens. It is satisfied by *auth.JWTManager.
+type TokenValidator interface {
+	ValidateToken(tokenString string) (*auth.Claims, error)
+}
+
+// Authenticate verifies the bearer token, if any, and forwards the caller's
+// identity to upstreams. Requests without a token pass through anonymously so
+// public endpoints keep working; requests with an invalid token are rejected.
+func Authenticate(validator TokenValidator) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		for _, header := range identityHeaders {
+			c.Request.Header.Del(header)
+		}
+
+		authHeader := c.GetHeader("Authorization")
+		if authHeader == "" {
+			c.Next()
+			return
+		}
+
+		token := strings.TrimPrefix(authHeader, "Bearer ")
+		if token == authHeader || token == "" {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Invalid authorization header format"})
+			return
+		}
+
+		claims, err := validator.ValidateToken(token)
+		if err != nil || claims.TokenType == "refresh" {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Invalid or expired token"})
+			return
+		}
+
+		c.Request.Header.Set(HeaderUserID, strconv.FormatUint(uint64(claims.UserID), 10))
+		c.Request.Header.Set(HeaderUserName, claims.Username)
+		c.Request.Header.Set(HeaderUserEmail, claims.Email)
+		c.Request.Header.Set(HeaderUserRole, strconv.Itoa(claims.Role))
+		if len(claims.Permissions) > 0 {
+			c.Request.Header.Set(HeaderUserPermissions, strings.Join(claims.Permissions, ","))
+		}
+		if claims.SessionID != "" {
+			c.Request.Header.Set(HeaderSessionID, claims.SessionID)
+		}
+		c.Set("user_id", claims.UserID)
+
+		c.Next()
+	}
+}
@@ -0,0 +1,39 @@
+package gateway
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"regexp"
+
+	"github.com/gin-gonic/gin"
+)
+
+// HeaderRequestID carries the request ID to upstreams and back to the client
+const HeaderRequestID = "X-Request-ID"
+
+// requestIDRegex limits client-supplied request IDs to safe log values
+var requestIDRegex = regexp.MustCompile(`^[A-Za-z0-9._-]{8,128}$`)
+
+// RequestID makes sure every request has an ID. A well-formed ID from the
+// client is kept so traces can span the frontend; otherwise one is generated.
+func RequestID() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := c.GetHeader(HeaderRequestID)
+		if !requestIDRegex.MatchString(id) {
+			id = newRequestID()
+		}
+
+		c.Request.Header.Set(HeaderRequestID, id)
+		c.Header(HeaderRequestID, id)
+		c.Set("request_id", id)
+
+		c.Next()
+	}
+}
+
+// newRequestID returns a random 128-bit hex ID
+func newRequestID() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,133 @@
+package gateway
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+// ErrCircuitOpen is returned when an upstream's circuit breaker is open
+var ErrCircuitOpen = errors.New("circuit breaker is open")
+
+// maxReplayBody is the largest request body buffered so an idempotent
+// request can be retried. Larger bodies are sent once.
+const maxReplayBody = 1 << 20
+
+// idempotentMethods are retried after connection errors and gateway errors
+var idempotentMethods = map[string]bool{
+	http.MethodGet:     true,
+	http.MethodHead:    true,
+	http.MethodOptions: true,
+	http.MethodPut:     true,
+	http.MethodDelete:  true,
+}
+
+// retryableStatus reports whether a response means the upstream could not
+// handle the request and another attempt may succeed
+func retryableStatus(code int) bool {
+	return code == http.StatusBadGateway || code == http.StatusServiceUnavailable || code == http.StatusGatewayTimeout
+}
+
+// resilientTransport retries idempotent requests and feeds every outcome into
+// a circuit breaker
+type resilientTransport struct {
+	next       http.RoundTripper
+	breaker    *Breaker
+	maxRetries int
+	backoff    time.Duration
+}
+
+// RoundTrip sends the request, retrying idempotent requests with linear backoff
+func (t *resilientTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if !t.breaker.Allow() {
+		return nil, ErrCircuitOpen
+	}
+
+	attempts := 1
+	if idempotentMethods[req.Method] {
+		replayable, err := makeReplayable(req)
+		if err != nil {
+			t.breaker.Release()
+			return nil, err
+		}
+		if replayable {
+			attempts += t.maxRetries
+		}
+	}
+
+	for attempt := 1; ; attempt++ {
+		resp, err := t.next.RoundTrip(req)
+		failed := err != nil || retryableStatus(resp.StatusCode)
+		if !failed {
+			t.breaker.Success()
+			return resp, nil
+		}
+
+		if attempt >= attempts || !t.wait(req.Context(), attempt) {
+			t.record(req.Context(), err)
+			return resp, err
+		}
+
+		if resp != nil {
+			io.Copy(io.Discard, resp.Body)
+			resp.Body.Close()
+		}
+		if req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				t.breaker.Release()
+				return nil, err
+			}
+			req.Body = body
+		}
+	}
+}
+
+// wait sleeps before the next attempt and reports whether to continue
+func (t *resilientTransport) wait(ctx context.Context, attempt int) bool {
+	timer := time.NewTimer(time.Duration(attempt) * t.backoff)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return false
+	case <-timer.C:
+		return true
+	}
+}
+
+// record counts a failed request against the breaker unless the client gave up
+func (t *resilientTransport) record(ctx context.Context, err error) {
+	if errors.Is(ctx.Err(), context.Canceled) || errors.Is(err, context.Canceled) {
+		t.breaker.Release()
+		return
+	}
+	t.breaker.Failure()
+}
+
+// makeReplayable buffers a small request body so it can be sent again and
+// reports whether the request can be retried
+func makeReplayable(req *http.Request) (bool, error) {
+	if req.Body == nil || req.Body == http.NoBody || req.GetBody != nil {
+		return true, nil
+	}
+	if req.ContentLength <= 0 || req.ContentLength > maxReplayBody {
+		return false, nil
+	}
+
+	body, err := io.ReadAll(req.Body)
+	req.Body.Close()
+	if err != nil {
+		return false, fmt.Errorf("error reading request body: %w", err)
+	}
+
+	req.Body = io.NopCloser(bytes.NewReader(body))
+	req.GetBody = func() (io.ReadCloser, error) {
+		return io.NopCloser(bytes.NewReader(body)), nil
+	}
+	return true, nil
+}
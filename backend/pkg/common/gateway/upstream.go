@@ -0,0 +1,149 @@
+package gateway
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// Upstream defaults
+const (
+	DefaultMaxRetries       = 2
+	DefaultRetryBackoff     = 100 * time.Millisecond
+	DefaultFailureThreshold = 5
+	DefaultOpenTimeout      = 30 * time.Second
+	DefaultHealthPath       = "/health"
+)
+
+// UpstreamOptions configures an upstream. Zero values use the defaults.
+type UpstreamOptions struct {
+	MaxRetries       int
+	RetryBackoff     time.Duration
+	FailureThreshold int
+	OpenTimeout      time.Duration
+	HealthPath       string
+	Transport        http.RoundTripper
+}
+
+// Upstream is a backend service the gateway proxies to
+type Upstream struct {
+	Name       string
+	target     *url.URL
+	healthPath string
+	breaker    *Breaker
+	proxy      *httputil.ReverseProxy
+	client     *http.Client
+}
+
+// NewUpstream creates a new upstream for the service at rawURL
+func NewUpstream(name, rawURL string, opts UpstreamOptions) (*Upstream, error) {
+	target, err := url.Parse(rawURL)
+	if err != nil || target.Scheme == "" || target.Host == "" {
+		return nil, fmt.Errorf("invalid URL %q for %s service", rawURL, name)
+	}
+
+	if opts.MaxRetries == 0 {
+		opts.MaxRetries = DefaultMaxRetries
+	}
+	if opts.RetryBackoff == 0 {
+		opts.RetryBackoff = DefaultRetryBackoff
+	}
+	if opts.FailureThreshold == 0 {
+		opts.FailureThreshold = DefaultFailureThreshold
+	}
+	if opts.OpenTimeout == 0 {
+		opts.OpenTimeout = DefaultOpenTimeout
+	}
+	if opts.HealthPath == "" {
+		opts.HealthPath = DefaultHealthPath
+	}
+	if opts.Transport == nil {
+		opts.Transport = http.DefaultTransport
+	}
+
+	u := &Upstream{
+		Name:       name,
+		target:     target,
+		healthPath: opts.HealthPath,
+		breaker:    NewBreaker(opts.FailureThreshold, opts.OpenTimeout),
+		client:     &http.Client{Transport: opts.Transport},
+	}
+	u.proxy = &httputil.ReverseProxy{
+		Rewrite: func(r *httputil.ProxyRequest) {
+			r.SetURL(target)
+			r.SetXForwarded()
+		},
+		Transport: &resilientTransport{
+			next:       opts.Transport,
+			breaker:    u.breaker,
+			maxRetries: opts.MaxRetries,
+			backoff:    opts.RetryBackoff,
+		},
+		ErrorHandler: u.handleError,
+	}
+
+	return u, nil
+}
+
+// Circuit returns the state of the upstream's circuit breaker
+func (u *Upstream) Circuit() BreakerState {
+	return u.breaker.State()
+}
+
+// ServeHTTP proxies a request whose path is already relative to the upstream
+func (u *Upstream) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	u.proxy.ServeHTTP(w, r)
+}
+
+// handleError writes the response for a request the upstream did not answer
+func (u *Upstream) handleError(w http.ResponseWriter, r *http.Request, err error) {
+	status := http.StatusBadGateway
+	message := fmt.Sprintf("%s service is unavailable", u.Name)
+
+	switch {
+	case errors.Is(err, ErrCircuitOpen):
+		status = http.StatusServiceUnavailable
+	case errors.Is(err, context.DeadlineExceeded):
+		status = http.StatusGatewayTimeout
+		message = fmt.Sprintf("%s service timed out", u.Name)
+	case errors.Is(err, context.Canceled):
+		// The client went away; nobody reads the response
+		return
+	}
+
+	log.Printf("Error proxying %s %s to %s service (request %s): %v",
+		r.Method, r.URL.Path, u.Name, r.Header.Get(HeaderRequestID), err)
+
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(status)
+	fmt.Fprintf(w, `{"error":%q}`, message)
+}
+
+// HeaderForwardedPrefix holds the part of the client's path that a route
+// stripped, so services can build paths clients use, for example for cookies
+const HeaderForwardedPrefix = "X-Forwarded-Prefix"
+
+// rewritePath returns a shallow copy of r with prefix in its path replaced
+// by rewrite. When rewrite only strips the start of prefix, as /api/v1/auth
+// to /auth does, the stripped part is forwarded in X-Forwarded-Prefix.
+func rewritePath(r *http.Request, prefix, rewrite string) *http.Request {
+	out := r.Clone(r.Context())
+	rewrite = strings.TrimRight(rewrite, "/")
+	out.URL.Path = rewrite + strings.TrimPrefix(r.URL.Path, prefix)
+	if out.URL.Path == "" {
+		out.URL.Path = "/"
+	}
+	out.URL.RawPath = ""
+
+	out.Header.Del(HeaderForwardedPrefix)
+	if rewrite != "" && rewrite != prefix && strings.HasSuffix(prefix, rewrite) {
+		out.Header.Set(HeaderForwardedPrefix, strings.TrimSuffix(prefix, rewrite))
+	}
+	return out
+}
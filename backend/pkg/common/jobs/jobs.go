@@ -0,0 +1,93 @@
+// Package jobs runs periodic background jobs. When several replicas of a
+// service run the same job, a lease lets only one of them do the work.
+package jobs
+
+import (
+	"context"
+	"log"
+	"time"
+)
+
+// releaseTimeout bounds releasing the lease on shutdown
+const releaseTimeout = 5 * time.Second
+
+// Lease lets one of several replicas run a job, see database.Lease
+type Lease interface {
+	Acquire(ctx context.Context) (bool, error)
+	Release(ctx context.Context) error
+}
+
+// Runner runs a periodic job. With a lease, only the replica holding it
+// should do the work; a nil lease runs the job on every replica, which is
+// only safe with a single replica.
+type Runner struct {
+	name     string
+	lease    Lease
+	interval time.Duration
+}
+
+// NewRunner creates a runner for the job called name, which passes every
+// interval. The name is used in log messages.
+func NewRunner(name string, lease Lease, interval time.Duration) *Runner {
+	return &Runner{
+		name:     name,
+		lease:    lease,
+		interval: interval,
+	}
+}
+
+// Interval returns the time between passes
+func (r *Runner) Interval() time.Duration {
+	return r.interval
+}
+
+// Acquire acquires or renews the lease and reports whether this replica
+// holds it. Errors are logged and count as not holding the lease.
+func (r *Runner) Acquire(ctx context.Context) bool {
+	if r.lease == nil {
+		return true
+	}
+	held, err := r.lease.Acquire(ctx)
+	if err != nil {
+		log.Printf("Error acquiring %s lease: %v", r.name, err)
+		return false
+	}
+	return held
+}
+
+// Run calls pass until ctx is cancelled, sleeping for the duration it
+// returns in between. The lease is released on return, so another replica
+// takes over without waiting for it to expire.
+func (r *Runner) Run(ctx context.Context, pass func(ctx context.Context) time.Duration) {
+	defer r.release()
+
+	for {
+		timer := time.NewTimer(pass(ctx))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+	}
+}
+
+// Every calls pass every interval until ctx is cancelled, see Run
+func (r *Runner) Every(ctx context.Context, pass func(ctx context.Context)) {
+	r.Run(ctx, func(ctx context.Context) time.Duration {
+		pass(ctx)
+		return r.interval
+	})
+}
+
+// release hands the lease to another replica
+func (r *Runner) release() {
+	if r.lease == nil {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), releaseTimeout)
+	defer cancel()
+	if err := r.lease.Release(ctx); err != nil {
+		log.Printf("Error releasing %s lease: %v", r.name, err)
+	}
+}
@@ -0,0 +1,73 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeLease is held unless taken by another replica
+type fakeLease struct {
+	taken    bool
+	err      error
+	acquired int
+	released int
+}
+
+func (l *fakeLease) Acquire(ctx context.Context) (bool, error) {
+	l.acquired++
+	return !l.taken && l.err == nil, l.err
+}
+
+func (l *fakeLease) Release(ctx context.Context) error {
+	l.released++
+	return nil
+}
+
+func TestAcquire(t *testing.T) {
+	ctx := context.Background()
+	assert.True(t, NewRunner("job", nil, time.Minute).Acquire(ctx), "without a lease every replica runs the job")
+
+	lease := &fakeLease{}
+	runner := NewRunner("job", lease, time.Minute)
+	assert.True(t, runner.Acquire(ctx))
+
+	lease.taken = true
+	assert.False(t, runner.Acquire(ctx))
+
+	lease.taken, lease.err = false, errors.New("database unavailable")
+	assert.False(t, runner.Acquire(ctx), "errors count as not holding the lease")
+	assert.Equal(t, 3, lease.acquired)
+}
+
+func TestRunWaitsAndReleasesTheLease(t *testing.T) {
+	lease := &fakeLease{}
+	runner := NewRunner("job", lease, time.Hour)
+	ctx, cancel := context.WithCancel(context.Background())
+
+	passes := 0
+	runner.Run(ctx, func(ctx context.Context) time.Duration {
+		passes++
+		if passes == 3 {
+			cancel()
+			return time.Hour
+		}
+		return 0
+	})
+
+	assert.Equal(t, 3, passes, "a zero wait passes again straight away")
+	assert.Equal(t, 1, lease.released)
+}
+
+func TestEvery(t *testing.T) {
+	runner := NewRunner("job", nil, time.Hour)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	passes := 0
+	runner.Every(ctx, func(ctx context.Context) { passes++ })
+	assert.Equal(t, 1, passes, "the first pass runs at once")
+}
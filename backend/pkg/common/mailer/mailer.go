@@ -0,0 +1,99 @@
+// Package mailer delivers transactional email. Messages are rendered from
+// versioned templates and handed to a Mailer, which is either an SMTP
+// client or an outbox used in development and tests. Queue wraps any Mailer
+// with a persisted retry queue.
+package mailer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/yerenwgventures/GreatNigeriaLibrary-Foundation/backend/pkg/common/config"
+)
+
+// Supported mail drivers
+const (
+	DriverSMTP   = "smtp"
+	DriverOutbox = "outbox"
+)
+
+// ErrNoRecipients is returned when a message has no recipients
+var ErrNoRecipients = errors.New("message has no recipients")
+
+// Message is a single email
+type Message struct {
+	To       []string          `json:"to"`
+	Subject  string            `json:"subject"`
+	TextBody string            `json:"text_body"`
+	HTMLBody string            `json:"html_body,omitempty"`
+	Headers  map[string]string `json:"headers,omitempty"`
+	// Tag identifies the kind of email, e.g. the template name, for logging
+	Tag         string       `json:"tag,omitempty"`
+	Attachments []Attachment `json:"attachments,omitempty"`
+}
+
+// Attachment is a file sent with a message. ContentType may carry
+// parameters, e.g. "text/calendar; method=REQUEST" for meeting invites.
+type Attachment struct {
+	Filename    string `json:"filename"`
+	ContentType string `json:"content_type"`
+	Content     []byte `json:"content"`
+}
+
+// Validate checks that the message can be sent
+func (m *Message) Validate() error {
+	if m == nil || len(m.To) == 0 {
+		return ErrNoRecipients
+	}
+	for _, to := range m.To {
+		if strings.TrimSpace(to) == "" || strings.ContainsAny(to, "\r\n") {
+			return fmt.Errorf("invalid recipient address %q", to)
+		}
+	}
+	if strings.ContainsAny(m.Subject, "\r\n") {
+		return errors.New("subject must not contain line breaks")
+	}
+	for key, value := range m.Headers {
+		if strings.ContainsAny(key+value, "\r\n") {
+			return fmt.Errorf("invalid header %q", key)
+		}
+	}
+	if m.TextBody == "" && m.HTMLBody == "" {
+		return errors.New("message has no body")
+	}
+	for _, attachment := range m.Attachments {
+		if attachment.Filename == "" || strings.ContainsAny(attachment.Filename+attachment.ContentType, "\r\n\"") {
+			return fmt.Errorf("invalid attachment %q", attachment.Filename)
+		}
+	}
+	return nil
+}
+
+// Mailer sends email messages
+type Mailer interface {
+	Send(ctx context.Context, msg *Message) error
+}
+
+// New creates the Mailer selected by cfg.Driver. Unknown drivers fall back
+// to SMTP.
+func New(cfg config.EmailConfig) Mailer {
+	switch strings.ToLower(cfg.Driver) {
+	case DriverOutbox:
+		return NewOutboxMailer(cfg.OutboxDir)
+	default:
+		return NewSMTPMailer(cfg)
+	}
+}
+
+// SendTemplate renders the latest version of a template and sends it to a
+// single recipient
+func SendTemplate(ctx context.Context, m Mailer, templates *Templates, to, name string, data interface{}) error {
+	msg, err := templates.Render(name, data)
+	if err != nil {
+		return err
+	}
+	msg.To = []string{to}
+	return m.Send(ctx, msg)
+}
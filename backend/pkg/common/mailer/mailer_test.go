@@ -0,0 +1,195 @@
+package mailer
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/yerenwgventures/GreatNigeriaLibrary-Foundation/backend/pkg/common/config"
+)
+
+func newTestTemplates(t *testing.T) *Templates {
+	templates, err := NewTemplates("Great Nigeria Library")
+	require.NoError(t, err)
+	return templates
+}
+
+func TestTemplatesRenderPasswordReset(t *testing.T) {
+	templates := newTestTemplates(t)
+
+	msg, err := templates.Render(TemplatePasswordReset, PasswordResetData{
+		Name:      "Ada <Admin>",
+		ResetURL:  "https://example.com/reset-password?token=abc",
+		ExpiresIn: time.Hour,
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, "Reset your Great Nigeria Library password", msg.Subject)
+	assert.Equal(t, "password_reset.v1", msg.Tag)
+	assert.Contains(t, msg.TextBody, "Hello Ada <Admin>,")
+	assert.Contains(t, msg.TextBody, "https://example.com/reset-password?token=abc")
+	assert.Contains(t, msg.TextBody, "expires in 1 hour")
+	assert.Contains(t, msg.HTMLBody, "Hello Ada &lt;Admin&gt;,")
+	assert.Contains(t, msg.HTMLBody, `href="https://example.com/reset-password?token=abc"`)
+}
+
+func TestTemplatesRenderDigest(t *testing.T) {
+	templates := newTestTemplates(t)
+
+	msg, err := templates.Render(TemplateDigest, DigestData{
+		Name:         "Ada",
+		Period:       "weekly",
+		TotalUpdates: 3,
+		Sections: []DigestSection{{
+			Title: "Topics you follow",
+			Items: []DigestItem{{Title: "Power sector reform", URL: "https://example.com/t/1", Summary: "3 new posts"}},
+		}},
+		ManageURL: "https://example.com/subscriptions",
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, "Your weekly digest: 3 updates", msg.Subject)
+	assert.Contains(t, msg.TextBody, "  - Power sector reform (3 new posts)")
+	assert.Contains(t, msg.HTMLBody, `<a href="https://example.com/t/1">Power sector reform</a>`)
+}
+
+func TestTemplatesRenderEventInvitation(t *testing.T) {
+	templates := newTestTemplates(t)
+	wat := time.FixedZone("WAT", 3600)
+	data := EventInvitationData{
+		Name:        "Ada",
+		EventTitle:  "Book club",
+		GroupName:   "Lagos Readers",
+		StartTime:   time.Date(2025, 3, 8, 16, 0, 0, 0, wat),
+		EndTime:     time.Date(2025, 3, 8, 18, 0, 0, 0, wat),
+		Location:    "Yaba",
+		VirtualLink: "https://meet.example.com/book-club",
+		EventURL:    "https://example.com/groups/events/7",
+	}
+
+	msg, err := templates.Render(TemplateEventInvitation, data)
+	require.NoError(t, err)
+	assert.Equal(t, "Book club, Sat 8 Mar 4:00 PM", msg.Subject)
+	assert.Contains(t, msg.TextBody, "When: Saturday 8 March 2025, 4:00 PM to 6:00 PM WAT")
+	assert.Contains(t, msg.TextBody, "Join online: https://meet.example.com/book-club")
+
+	data.Cancelled = true
+	msg, err = templates.Render(TemplateEventInvitation, data)
+	require.NoError(t, err)
+	assert.Equal(t, "Cancelled: Book club, Sat 8 Mar 4:00 PM", msg.Subject)
+	assert.Contains(t, msg.TextBody, "has been cancelled")
+	assert.NotContains(t, msg.TextBody, "Join online")
+}
+
+func TestTemplatesUnknown(t *testing.T) {
+	templates := newTestTemplates(t)
+
+	_, err := templates.Render("missing", nil)
+	assert.Error(t, err)
+	_, err = templates.RenderVersion(TemplateDigest, 99, DigestData{})
+	assert.Error(t, err)
+	assert.Equal(t, 1, templates.Latest(TemplateGroupInvitation))
+}
+
+func TestOutboxMailer(t *testing.T) {
+	dir := t.TempDir()
+	outbox := NewOutboxMailer(dir)
+	templates := newTestTemplates(t)
+
+	err := SendTemplate(context.Background(), outbox, templates, "ada@example.com", TemplateEmailVerification, EmailVerificationData{
+		Name:            "Ada",
+		VerificationURL: "https://example.com/verify-email?token=xyz",
+		ExpiresIn:       24 * time.Hour,
+	})
+	require.NoError(t, err)
+
+	last := outbox.Last()
+	require.NotNil(t, last)
+	assert.Equal(t, []string{"ada@example.com"}, last.To)
+	assert.Contains(t, last.TextBody, "expires in 24 hours")
+
+	files, err := filepath.Glob(filepath.Join(dir, "*.eml"))
+	require.NoError(t, err)
+	require.Len(t, files, 1)
+	data, err := os.ReadFile(files[0])
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "To: ada@example.com\r\n")
+
+	assert.ErrorIs(t, outbox.Send(context.Background(), &Message{Subject: "x", TextBody: "y"}), ErrNoRecipients)
+	assert.Len(t, outbox.Messages(), 1)
+	outbox.Reset()
+	assert.Nil(t, outbox.Last())
+}
+
+func TestBuildMIMEMultipart(t *testing.T) {
+	msg := &Message{
+		To:       []string{"a@example.com", "b@example.com"},
+		Subject:  "Ẹ káàbọ̀",
+		TextBody: "plain",
+		HTMLBody: "<p>html</p>",
+		Headers:  map[string]string{"List-Unsubscribe": "<https://example.com/unsubscribe>"},
+	}
+	data, err := buildMIME(`"Library" <noreply@example.com>`, msg, time.Unix(1700000000, 0).UTC())
+	require.NoError(t, err)
+	body := string(data)
+
+	assert.Contains(t, body, "To: a@example.com, b@example.com\r\n")
+	assert.Contains(t, body, "Subject: =?utf-8?q?")
+	assert.Contains(t, body, "List-Unsubscribe: <https://example.com/unsubscribe>\r\n")
+	assert.Contains(t, body, "Content-Type: multipart/alternative;")
+	assert.Contains(t, body, "Message-ID: <")
+	assert.True(t, strings.Index(body, "text/plain") < strings.Index(body, "text/html"))
+}
+
+func TestBuildMIMEAttachments(t *testing.T) {
+	msg := &Message{
+		To:       []string{"a@example.com"},
+		Subject:  "Invitation",
+		TextBody: "plain",
+		HTMLBody: "<p>html</p>",
+		Attachments: []Attachment{{
+			Filename:    "invite.ics",
+			ContentType: "text/calendar; charset=utf-8; method=REQUEST",
+			Content:     []byte(strings.Repeat("BEGIN:VCALENDAR\r\n", 10)),
+		}},
+	}
+	data, err := buildMIME("noreply@example.com", msg, time.Unix(1700000000, 0).UTC())
+	require.NoError(t, err)
+	body := string(data)
+
+	assert.Contains(t, body, "Content-Type: multipart/mixed;")
+	assert.Contains(t, body, "Content-Type: text/calendar; charset=utf-8; method=REQUEST; name=\"invite.ics\"\r\n")
+	assert.Contains(t, body, "Content-Disposition: attachment; filename=\"invite.ics\"\r\n")
+	assert.True(t, strings.Index(body, "multipart/alternative") < strings.Index(body, "text/calendar"), "the body comes first")
+	for _, line := range strings.Split(body, "\r\n") {
+		assert.LessOrEqual(t, len(line), 78)
+	}
+
+	msg.Attachments[0].Filename = "invite\r\n.ics"
+	assert.Error(t, msg.Validate())
+}
+
+func TestMessageValidateRejectsHeaderInjection(t *testing.T) {
+	msg := &Message{To: []string{"a@example.com"}, Subject: "hi\r\nBcc: x@example.com", TextBody: "body"}
+	assert.Error(t, msg.Validate())
+
+	msg = &Message{To: []string{"a@example.com"}, Subject: "hi", TextBody: "body", Headers: map[string]string{"X-Test": "a\nb"}}
+	assert.Error(t, msg.Validate())
+}
+
+func TestBackoff(t *testing.T) {
+	assert.Equal(t, 30*time.Second, backoff(1, 30*time.Second, time.Hour))
+	assert.Equal(t, time.Minute, backoff(2, 30*time.Second, time.Hour))
+	assert.Equal(t, 4*time.Minute, backoff(4, 30*time.Second, time.Hour))
+	assert.Equal(t, time.Hour, backoff(20, 30*time.Second, time.Hour))
+}
+
+func TestNewSelectsDriver(t *testing.T) {
+	assert.IsType(t, &OutboxMailer{}, New(config.EmailConfig{Driver: "outbox"}))
+	assert.IsType(t, &SMTPMailer{}, New(config.EmailConfig{Driver: "smtp"}))
+}
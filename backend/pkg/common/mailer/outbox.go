@@ -0,0 +1,94 @@
+package mailer
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// OutboxMailer keeps sent messages in memory instead of delivering them.
+// When dir is set each message is also written there as an .eml file so it
+// can be opened in a mail client during development.
+type OutboxMailer struct {
+	dir  string
+	from string
+	now  func() time.Time
+
+	mu       sync.Mutex
+	messages []Message
+}
+
+// NewOutboxMailer creates a new outbox mailer. dir may be empty.
+func NewOutboxMailer(dir string) *OutboxMailer {
+	return &OutboxMailer{
+		dir:  dir,
+		from: "outbox@localhost",
+		now:  time.Now,
+	}
+}
+
+// Send records msg in the outbox
+func (o *OutboxMailer) Send(ctx context.Context, msg *Message) error {
+	if err := msg.Validate(); err != nil {
+		return err
+	}
+
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	o.messages = append(o.messages, *msg)
+
+	if o.dir == "" {
+		return nil
+	}
+
+	body, err := buildMIME(o.from, msg, o.now())
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(o.dir, 0o755); err != nil {
+		return fmt.Errorf("error creating outbox directory: %w", err)
+	}
+	name := fmt.Sprintf("%s-%04d", o.now().UTC().Format("20060102T150405"), len(o.messages))
+	if msg.Tag != "" {
+		name += "-" + msg.Tag
+	}
+	if err := os.WriteFile(filepath.Join(o.dir, name+".eml"), body, 0o644); err != nil {
+		return fmt.Errorf("error writing outbox message: %w", err)
+	}
+
+	return nil
+}
+
+// Messages returns a copy of every message sent so far
+func (o *OutboxMailer) Messages() []Message {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	messages := make([]Message, len(o.messages))
+	copy(messages, o.messages)
+	return messages
+}
+
+// Last returns the most recently sent message, or nil if there is none
+func (o *OutboxMailer) Last() *Message {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	if len(o.messages) == 0 {
+		return nil
+	}
+	msg := o.messages[len(o.messages)-1]
+	return &msg
+}
+
+// Reset empties the outbox
+func (o *OutboxMailer) Reset() {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	o.messages = nil
+}
@@ -0,0 +1,291 @@
+package mailer
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Outbound email statuses
+const (
+	StatusPending = "pending"
+	StatusSending = "sending"
+	StatusSent    = "sent"
+	StatusFailed  = "failed"
+)
+
+// Queue defaults
+const (
+	DefaultMaxAttempts  = 8
+	DefaultBaseDelay    = 30 * time.Second
+	DefaultMaxDelay     = time.Hour
+	DefaultBatchSize    = 50
+	DefaultPollInterval = 15 * time.Second
+
+	// sendLease is how long a claimed email stays locked to one worker. If the
+	// worker dies mid-send the email becomes claimable again after the lease.
+	sendLease = 5 * time.Minute
+)
+
+// OutboundEmail is a queued email. The table is created by the
+// 0019_create_outbound_emails migration.
+type OutboundEmail struct {
+	ID            uint       `gorm:"primarykey" json:"id"`
+	Recipients    string     `gorm:"type:text;not null" json:"recipients"` // comma separated
+	Subject       string     `gorm:"type:text" json:"subject"`
+	TextBody      string     `gorm:"type:text" json:"textBody"`
+	HTMLBody      string     `gorm:"type:text" json:"htmlBody"`
+	Headers       string     `gorm:"type:text" json:"headers"`     // JSON object
+	Attachments   string     `gorm:"type:text" json:"attachments"` // JSON array
+	Tag           string     `gorm:"size:64;index" json:"tag"`
+	Status        string     `gorm:"size:16;not null;default:pending;index:idx_outbound_emails_due,priority:1" json:"status"`
+	Attempts      int        `gorm:"not null;default:0" json:"attempts"`
+	NextAttemptAt time.Time  `gorm:"not null;index:idx_outbound_emails_due,priority:2" json:"nextAttemptAt"`
+	LastError     string     `gorm:"type:text" json:"lastError"`
+	SentAt        *time.Time `json:"sentAt"`
+	CreatedAt     time.Time  `json:"createdAt"`
+	UpdatedAt     time.Time  `json:"updatedAt"`
+}
+
+// TableName specifies the table name for OutboundEmail
+func (OutboundEmail) TableName() string {
+	return "outbound_emails"
+}
+
+// message converts the row back into a Message
+func (e *OutboundEmail) message() (*Message, error) {
+	msg := &Message{
+		To:       strings.Split(e.Recipients, ","),
+		Subject:  e.Subject,
+		TextBody: e.TextBody,
+		HTMLBody: e.HTMLBody,
+		Tag:      e.Tag,
+	}
+	if e.Headers != "" {
+		if err := json.Unmarshal([]byte(e.Headers), &msg.Headers); err != nil {
+			return nil, fmt.Errorf("error decoding headers: %w", err)
+		}
+	}
+	if e.Attachments != "" {
+		if err := json.Unmarshal([]byte(e.Attachments), &msg.Attachments); err != nil {
+			return nil, fmt.Errorf("error decoding attachments: %w", err)
+		}
+	}
+	return msg, nil
+}
+
+// Queue is a Mailer that persists messages and delivers them in the
+// background through another Mailer, retrying failures with exponential
+// backoff. Send only enqueues; ProcessPending or Run performs delivery.
+type Queue struct {
+	db          *gorm.DB
+	mailer      Mailer
+	maxAttempts int
+	baseDelay   time.Duration
+	maxDelay    time.Duration
+	batchSize   int
+	now         func() time.Time
+}
+
+// NewQueue creates a new queue that delivers through mailer
+func NewQueue(db *gorm.DB, mailer Mailer) *Queue {
+	return &Queue{
+		db:          db,
+		mailer:      mailer,
+		maxAttempts: DefaultMaxAttempts,
+		baseDelay:   DefaultBaseDelay,
+		maxDelay:    DefaultMaxDelay,
+		batchSize:   DefaultBatchSize,
+		now:         time.Now,
+	}
+}
+
+// WithRetryPolicy overrides the attempt limit and backoff delays
+func (q *Queue) WithRetryPolicy(maxAttempts int, baseDelay, maxDelay time.Duration) *Queue {
+	q.maxAttempts = maxAttempts
+	q.baseDelay = baseDelay
+	q.maxDelay = maxDelay
+	return q
+}
+
+// Send enqueues msg for delivery
+func (q *Queue) Send(ctx context.Context, msg *Message) error {
+	if err := msg.Validate(); err != nil {
+		return err
+	}
+
+	email := &OutboundEmail{
+		Recipients:    strings.Join(msg.To, ","),
+		Subject:       msg.Subject,
+		TextBody:      msg.TextBody,
+		HTMLBody:      msg.HTMLBody,
+		Tag:           msg.Tag,
+		Status:        StatusPending,
+		NextAttemptAt: q.now(),
+	}
+	if len(msg.Headers) > 0 {
+		headers, err := json.Marshal(msg.Headers)
+		if err != nil {
+			return fmt.Errorf("error encoding headers: %w", err)
+		}
+		email.Headers = string(headers)
+	}
+	if len(msg.Attachments) > 0 {
+		attachments, err := json.Marshal(msg.Attachments)
+		if err != nil {
+			return fmt.Errorf("error encoding attachments: %w", err)
+		}
+		email.Attachments = string(attachments)
+	}
+
+	if err := q.db.WithContext(ctx).Create(email).Error; err != nil {
+		return fmt.Errorf("error queueing email: %w", err)
+	}
+	return nil
+}
+
+// ProcessPending delivers every email that is due and returns the number
+// sent. Rows are claimed with FOR UPDATE SKIP LOCKED so several workers can
+// share the queue.
+func (q *Queue) ProcessPending(ctx context.Context) (int, error) {
+	sent := 0
+	for {
+		batch, err := q.claim(ctx)
+		if err != nil {
+			return sent, err
+		}
+		if len(batch) == 0 {
+			return sent, nil
+		}
+
+		for i := range batch {
+			if ctx.Err() != nil {
+				return sent, ctx.Err()
+			}
+			ok, err := q.deliver(ctx, &batch[i])
+			if err != nil {
+				return sent, err
+			}
+			if ok {
+				sent++
+			}
+		}
+
+		if len(batch) < q.batchSize {
+			return sent, nil
+		}
+	}
+}
+
+// Run processes the queue every interval until ctx is cancelled
+func (q *Queue) Run(ctx context.Context, interval time.Duration) {
+	if interval <= 0 {
+		interval = DefaultPollInterval
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := q.ProcessPending(ctx); err != nil && ctx.Err() == nil {
+			log.Printf("Error processing email queue: %v", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// claim locks a batch of due emails to this worker
+func (q *Queue) claim(ctx context.Context) ([]OutboundEmail, error) {
+	now := q.now()
+	var batch []OutboundEmail
+
+	err := q.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("status IN ? AND next_attempt_at <= ?", []string{StatusPending, StatusSending}, now).
+			Order("next_attempt_at, id").
+			Limit(q.batchSize).
+			Find(&batch).Error
+		if err != nil || len(batch) == 0 {
+			return err
+		}
+
+		ids := make([]uint, len(batch))
+		for i := range batch {
+			ids[i] = batch[i].ID
+		}
+		return tx.Model(&OutboundEmail{}).
+			Where("id IN ?", ids).
+			Updates(map[string]interface{}{
+				"status":          StatusSending,
+				"next_attempt_at": now.Add(sendLease),
+				"updated_at":      now,
+			}).Error
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error claiming queued emails: %w", err)
+	}
+
+	return batch, nil
+}
+
+// deliver sends one claimed email and records the outcome
+func (q *Queue) deliver(ctx context.Context, email *OutboundEmail) (bool, error) {
+	msg, err := email.message()
+	if err == nil {
+		err = q.mailer.Send(ctx, msg)
+	}
+
+	now := q.now()
+	attempts := email.Attempts + 1
+	updates := map[string]interface{}{
+		"attempts":   attempts,
+		"updated_at": now,
+	}
+
+	if err == nil {
+		updates["status"] = StatusSent
+		updates["sent_at"] = now
+		updates["last_error"] = ""
+	} else {
+		updates["last_error"] = err.Error()
+		if attempts >= q.maxAttempts {
+			updates["status"] = StatusFailed
+			log.Printf("Giving up on email %d (%s) after %d attempts: %v", email.ID, email.Tag, attempts, err)
+		} else {
+			updates["status"] = StatusPending
+			updates["next_attempt_at"] = now.Add(backoff(attempts, q.baseDelay, q.maxDelay))
+		}
+	}
+
+	if dbErr := q.db.WithContext(ctx).Model(&OutboundEmail{}).Where("id = ?", email.ID).Updates(updates).Error; dbErr != nil {
+		return false, fmt.Errorf("error updating queued email %d: %w", email.ID, dbErr)
+	}
+
+	return err == nil, nil
+}
+
+// backoff returns the delay before the next attempt after the given number
+// of failed attempts: base, 2*base, 4*base, ... capped at max
+func backoff(attempts int, base, max time.Duration) time.Duration {
+	delay := base
+	for i := 1; i < attempts; i++ {
+		delay *= 2
+		if delay >= max {
+			return max
+		}
+	}
+	if delay > max {
+		return max
+	}
+	return delay
+}
@@ -0,0 +1,242 @@
+package mailer
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/tls"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"mime"
+	"mime/quotedprintable"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/yerenwgventures/GreatNigeriaLibrary-Foundation/backend/pkg/common/config"
+)
+
+// smtpTimeout bounds dialing and the whole SMTP conversation
+const smtpTimeout = 30 * time.Second
+
+// SMTPMailer sends messages through an SMTP server. Port 465 uses implicit
+// TLS; other ports upgrade with STARTTLS when the server offers it.
+type SMTPMailer struct {
+	cfg config.EmailConfig
+	now func() time.Time
+}
+
+// NewSMTPMailer creates a new SMTP mailer
+func NewSMTPMailer(cfg config.EmailConfig) *SMTPMailer {
+	return &SMTPMailer{cfg: cfg, now: time.Now}
+}
+
+// Send delivers msg to every recipient
+func (m *SMTPMailer) Send(ctx context.Context, msg *Message) error {
+	if err := msg.Validate(); err != nil {
+		return err
+	}
+
+	body, err := buildMIME(m.from(), msg, m.now())
+	if err != nil {
+		return err
+	}
+
+	deadline := time.Now().Add(smtpTimeout)
+	if d, ok := ctx.Deadline(); ok && d.Before(deadline) {
+		deadline = d
+	}
+
+	addr := net.JoinHostPort(m.cfg.SMTPHost, strconv.Itoa(m.cfg.SMTPPort))
+	dialer := &net.Dialer{Deadline: deadline}
+	conn, err := dialer.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return fmt.Errorf("error connecting to SMTP server: %w", err)
+	}
+	conn.SetDeadline(deadline)
+
+	tlsConfig := &tls.Config{ServerName: m.cfg.SMTPHost}
+	if m.cfg.SMTPPort == 465 {
+		conn = tls.Client(conn, tlsConfig)
+	}
+
+	client, err := smtp.NewClient(conn, m.cfg.SMTPHost)
+	if err != nil {
+		conn.Close()
+		return fmt.Errorf("error starting SMTP session: %w", err)
+	}
+	defer client.Close()
+
+	if m.cfg.SMTPPort != 465 {
+		if ok, _ := client.Extension("STARTTLS"); ok {
+			if err := client.StartTLS(tlsConfig); err != nil {
+				return fmt.Errorf("error starting TLS: %w", err)
+			}
+		}
+	}
+
+	if m.cfg.SMTPUsername != "" {
+		auth := smtp.PlainAuth("", m.cfg.SMTPUsername, m.cfg.SMTPPassword, m.cfg.SMTPHost)
+		if err := client.Auth(auth); err != nil {
+			return fmt.Errorf("error authenticating with SMTP server: %w", err)
+		}
+	}
+
+	if err := client.Mail(m.cfg.FromEmail); err != nil {
+		return fmt.Errorf("error setting sender: %w", err)
+	}
+	for _, to := range msg.To {
+		if err := client.Rcpt(to); err != nil {
+			return fmt.Errorf("error adding recipient %s: %w", to, err)
+		}
+	}
+
+	writer, err := client.Data()
+	if err != nil {
+		return fmt.Errorf("error starting message data: %w", err)
+	}
+	if _, err := writer.Write(body); err != nil {
+		writer.Close()
+		return fmt.Errorf("error writing message: %w", err)
+	}
+	if err := writer.Close(); err != nil {
+		return fmt.Errorf("error sending message: %w", err)
+	}
+
+	return client.Quit()
+}
+
+// from returns the formatted From address
+func (m *SMTPMailer) from() string {
+	return (&mail.Address{Name: m.cfg.FromName, Address: m.cfg.FromEmail}).String()
+}
+
+// buildMIME renders msg as an RFC 5322 message. Messages with attachments
+// are sent as multipart/mixed with the body first.
+func buildMIME(from string, msg *Message, now time.Time) ([]byte, error) {
+	var buf bytes.Buffer
+
+	headers := map[string]string{
+		"From":         from,
+		"Date":         now.Format(time.RFC1123Z),
+		"Subject":      mime.QEncoding.Encode("utf-8", msg.Subject),
+		"MIME-Version": "1.0",
+		"Message-ID":   messageID(from),
+	}
+	for key, value := range msg.Headers {
+		headers[key] = value
+	}
+	headers["To"] = strings.Join(msg.To, ", ")
+
+	keys := make([]string, 0, len(headers))
+	for key := range headers {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		fmt.Fprintf(&buf, "%s: %s\r\n", key, headers[key])
+	}
+
+	if len(msg.Attachments) == 0 {
+		if err := writeBody(&buf, msg); err != nil {
+			return nil, err
+		}
+		return buf.Bytes(), nil
+	}
+
+	boundary := randomToken()
+	fmt.Fprintf(&buf, "Content-Type: multipart/mixed; boundary=%q\r\n\r\n", boundary)
+	fmt.Fprintf(&buf, "--%s\r\n", boundary)
+	if err := writeBody(&buf, msg); err != nil {
+		return nil, err
+	}
+	for _, attachment := range msg.Attachments {
+		fmt.Fprintf(&buf, "--%s\r\n", boundary)
+		writeAttachment(&buf, attachment)
+	}
+	fmt.Fprintf(&buf, "--%s--\r\n", boundary)
+
+	return buf.Bytes(), nil
+}
+
+// writeBody writes the text and HTML bodies of msg. Messages with both are
+// sent as multipart/alternative.
+func writeBody(buf *bytes.Buffer, msg *Message) error {
+	switch {
+	case msg.TextBody != "" && msg.HTMLBody != "":
+		boundary := randomToken()
+		fmt.Fprintf(buf, "Content-Type: multipart/alternative; boundary=%q\r\n\r\n", boundary)
+		for _, part := range []struct{ contentType, body string }{
+			{"text/plain", msg.TextBody},
+			{"text/html", msg.HTMLBody},
+		} {
+			fmt.Fprintf(buf, "--%s\r\n", boundary)
+			if err := writePart(buf, part.contentType, part.body); err != nil {
+				return err
+			}
+		}
+		fmt.Fprintf(buf, "--%s--\r\n", boundary)
+		return nil
+	case msg.HTMLBody != "":
+		return writePart(buf, "text/html", msg.HTMLBody)
+	default:
+		return writePart(buf, "text/plain", msg.TextBody)
+	}
+}
+
+// writeAttachment writes a base64 encoded attachment with its headers
+func writeAttachment(buf *bytes.Buffer, attachment Attachment) {
+	contentType := attachment.ContentType
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+	fmt.Fprintf(buf, "Content-Type: %s; name=%q\r\n", contentType, attachment.Filename)
+	fmt.Fprintf(buf, "Content-Disposition: attachment; filename=%q\r\n", attachment.Filename)
+	buf.WriteString("Content-Transfer-Encoding: base64\r\n\r\n")
+
+	encoded := base64.StdEncoding.EncodeToString(attachment.Content)
+	// Lines of base64 are limited to 76 characters
+	for len(encoded) > 76 {
+		buf.WriteString(encoded[:76] + "\r\n")
+		encoded = encoded[76:]
+	}
+	buf.WriteString(encoded + "\r\n")
+}
+
+// writePart writes a quoted-printable body with its headers
+func writePart(buf *bytes.Buffer, contentType, body string) error {
+	fmt.Fprintf(buf, "Content-Type: %s; charset=utf-8\r\n", contentType)
+	buf.WriteString("Content-Transfer-Encoding: quoted-printable\r\n\r\n")
+	writer := quotedprintable.NewWriter(buf)
+	if _, err := writer.Write([]byte(body)); err != nil {
+		return fmt.Errorf("error encoding message body: %w", err)
+	}
+	if err := writer.Close(); err != nil {
+		return fmt.Errorf("error encoding message body: %w", err)
+	}
+	buf.WriteString("\r\n")
+	return nil
+}
+
+// messageID generates a Message-ID in the sender's domain
+func messageID(from string) string {
+	domain := "localhost"
+	if addr, err := mail.ParseAddress(from); err == nil {
+		if at := strings.LastIndex(addr.Address, "@"); at >= 0 {
+			domain = addr.Address[at+1:]
+		}
+	}
+	return fmt.Sprintf("<%s@%s>", randomToken(), domain)
+}
+
+// randomToken returns a random hex string
+func randomToken() string {
+	b := make([]byte, 12)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
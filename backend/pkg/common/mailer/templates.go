@@ -0,0 +1,253 @@
+package mailer
+
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	htmltemplate "html/template"
+	"io/fs"
+	"regexp"
+	"strconv"
+	"strings"
+	texttemplate "text/template"
+	"time"
+)
+
+// Template names
+const (
+	TemplatePasswordReset     = "password_reset"
+	TemplateEmailVerification = "email_verification"
+	TemplateGroupInvitation   = "group_invitation"
+	TemplateEventInvitation   = "event_invitation"
+	TemplateDigest            = "digest"
+)
+
+//go:embed templates/*.html templates/*.txt
+var templateFS embed.FS
+
+// templateFileRegex matches versioned template files, e.g. digest.v2.html
+var templateFileRegex = regexp.MustCompile(`^([a-z0-9_]+)\.v([0-9]+)\.(html|txt)$`)
+
+// PasswordResetData is the data for the password_reset template
+type PasswordResetData struct {
+	Name      string
+	ResetURL  string
+	ExpiresIn time.Duration
+}
+
+// EmailVerificationData is the data for the email_verification template
+type EmailVerificationData struct {
+	Name            string
+	VerificationURL string
+	ExpiresIn       time.Duration
+}
+
+// GroupInvitationData is the data for the group_invitation template
+type GroupInvitationData struct {
+	GroupName   string
+	InviterName string
+	Message     string
+	AcceptURL   string
+	ExpiresAt   time.Time
+}
+
+// EventInvitationData is the data for the event_invitation template, which
+// carries a calendar invite for an event the recipient has RSVP'd to.
+// Withdrawn is set when the recipient is no longer attending.
+type EventInvitationData struct {
+	Name        string
+	EventTitle  string
+	GroupName   string
+	StartTime   time.Time
+	EndTime     time.Time
+	Location    string
+	VirtualLink string
+	EventURL    string
+	Updated     bool
+	Cancelled   bool
+	Withdrawn   bool
+}
+
+// DigestData is the data for the digest template
+type DigestData struct {
+	Name         string
+	Period       string // daily, weekly, monthly
+	TotalUpdates int
+	Sections     []DigestSection
+	ManageURL    string
+}
+
+// DigestSection groups digest items under a heading
+type DigestSection struct {
+	Title string
+	Items []DigestItem
+}
+
+// DigestItem is a single line in a digest
+type DigestItem struct {
+	Title   string
+	URL     string
+	Summary string
+}
+
+// templatePair is one version of a template in both formats
+type templatePair struct {
+	html *htmltemplate.Template
+	text *texttemplate.Template
+}
+
+// Templates renders versioned email templates. Every template has a
+// plain-text file, which defines "subject" and "content", and may have an
+// HTML file defining "content". Both are wrapped in the matching layout.
+type Templates struct {
+	appName  string
+	versions map[string]map[int]*templatePair
+	latest   map[string]int
+}
+
+// NewTemplates parses the embedded templates
+func NewTemplates(appName string) (*Templates, error) {
+	funcs := map[string]interface{}{
+		"appName":  func() string { return appName },
+		"duration": humanizeDuration,
+	}
+
+	htmlLayout, err := htmltemplate.New("layout.html").Funcs(funcs).ParseFS(templateFS, "templates/layout.html")
+	if err != nil {
+		return nil, fmt.Errorf("error parsing HTML layout: %w", err)
+	}
+	textLayout, err := texttemplate.New("layout.txt").Funcs(funcs).ParseFS(templateFS, "templates/layout.txt")
+	if err != nil {
+		return nil, fmt.Errorf("error parsing text layout: %w", err)
+	}
+
+	t := &Templates{
+		appName:  appName,
+		versions: make(map[string]map[int]*templatePair),
+		latest:   make(map[string]int),
+	}
+
+	entries, err := fs.ReadDir(templateFS, "templates")
+	if err != nil {
+		return nil, fmt.Errorf("error reading templates: %w", err)
+	}
+	for _, entry := range entries {
+		match := templateFileRegex.FindStringSubmatch(entry.Name())
+		if match == nil {
+			continue
+		}
+		name, format := match[1], match[3]
+		version, _ := strconv.Atoi(match[2])
+		path := "templates/" + entry.Name()
+
+		if t.versions[name] == nil {
+			t.versions[name] = make(map[int]*templatePair)
+		}
+		pair := t.versions[name][version]
+		if pair == nil {
+			pair = &templatePair{}
+			t.versions[name][version] = pair
+		}
+
+		switch format {
+		case "html":
+			layout, err := htmlLayout.Clone()
+			if err != nil {
+				return nil, err
+			}
+			if pair.html, err = layout.ParseFS(templateFS, path); err != nil {
+				return nil, fmt.Errorf("error parsing %s: %w", entry.Name(), err)
+			}
+		case "txt":
+			layout, err := textLayout.Clone()
+			if err != nil {
+				return nil, err
+			}
+			if pair.text, err = layout.ParseFS(templateFS, path); err != nil {
+				return nil, fmt.Errorf("error parsing %s: %w", entry.Name(), err)
+			}
+		}
+
+		if version > t.latest[name] {
+			t.latest[name] = version
+		}
+	}
+
+	for name, versions := range t.versions {
+		for version, pair := range versions {
+			if pair.text == nil || pair.text.Lookup("subject") == nil {
+				return nil, fmt.Errorf("template %s.v%d has no plain-text subject", name, version)
+			}
+		}
+	}
+
+	return t, nil
+}
+
+// Latest returns the newest version of a template, or 0 if it does not exist
+func (t *Templates) Latest(name string) int {
+	return t.latest[name]
+}
+
+// Render renders the latest version of a template into a message without
+// recipients
+func (t *Templates) Render(name string, data interface{}) (*Message, error) {
+	version := t.latest[name]
+	if version == 0 {
+		return nil, fmt.Errorf("unknown email template %q", name)
+	}
+	return t.RenderVersion(name, version, data)
+}
+
+// RenderVersion renders a specific version of a template
+func (t *Templates) RenderVersion(name string, version int, data interface{}) (*Message, error) {
+	pair := t.versions[name][version]
+	if pair == nil {
+		return nil, fmt.Errorf("unknown email template %s.v%d", name, version)
+	}
+
+	var subject, text bytes.Buffer
+	if err := pair.text.ExecuteTemplate(&subject, "subject", data); err != nil {
+		return nil, fmt.Errorf("error rendering %s subject: %w", name, err)
+	}
+	if err := pair.text.ExecuteTemplate(&text, "layout", data); err != nil {
+		return nil, fmt.Errorf("error rendering %s text body: %w", name, err)
+	}
+
+	msg := &Message{
+		Subject:  strings.Join(strings.Fields(subject.String()), " "),
+		TextBody: strings.TrimSpace(text.String()) + "\n",
+		Tag:      fmt.Sprintf("%s.v%d", name, version),
+	}
+
+	if pair.html != nil {
+		var html bytes.Buffer
+		if err := pair.html.ExecuteTemplate(&html, "layout", data); err != nil {
+			return nil, fmt.Errorf("error rendering %s HTML body: %w", name, err)
+		}
+		msg.HTMLBody = html.String()
+	}
+
+	return msg, nil
+}
+
+// humanizeDuration formats a duration as e.g. "1 hour" or "30 minutes"
+func humanizeDuration(d time.Duration) string {
+	plural := func(n int64, unit string) string {
+		if n == 1 {
+			return "1 " + unit
+		}
+		return fmt.Sprintf("%d %ss", n, unit)
+	}
+
+	switch {
+	case d >= 48*time.Hour && d%(24*time.Hour) == 0:
+		return plural(int64(d/(24*time.Hour)), "day")
+	case d >= time.Hour:
+		return plural(int64(d/time.Hour), "hour")
+	case d >= time.Minute:
+		return plural(int64(d/time.Minute), "minute")
+	default:
+		return plural(int64(d/time.Second), "second")
+	}
+}
@@ -0,0 +1,184 @@
+package markdown
+
+import (
+	"bytes"
+	"fmt"
+	stdhtml "html"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/microcosm-cc/bluemonday"
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/extension"
+	"github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/renderer/html"
+)
+
+// placeholderRegex matches the content embed placeholders that are expanded
+// after Markdown rendering ({{interactive:N}}, {{image:...}}, {{video:...}},
+// {{audio:...}} and {{topic:N}}). Matches bypass the sanitizer, so IDs must be
+// numeric and media references may only use URL characters; anything else is
+// rendered and sanitized as ordinary text.
+var placeholderRegex = regexp.MustCompile(`\{\{(?:(?:interactive|topic):\d+|(?:image|video|audio):[A-Za-z0-9\-._~:/?#\[\]@!$&'()*+,;=%]+)\}\}`)
+
+// Placeholders are swapped for private-use code points while Markdown is
+// parsed so that neither the parser nor the sanitizer can alter them
+const (
+	placeholderStart = "\ue000"
+	placeholderEnd   = "\ue001"
+)
+
+var placeholderTokenRegex = regexp.MustCompile(placeholderStart + `(\d+)` + placeholderEnd)
+var standalonePlaceholderRegex = regexp.MustCompile(`<p>(` + placeholderStart + `\d+` + placeholderEnd + `)</p>`)
+
+// Renderer converts CommonMark/GFM Markdown into sanitized HTML
+type Renderer struct {
+	markdown goldmark.Markdown
+	policy   *bluemonday.Policy
+}
+
+// NewRenderer creates a renderer with GFM tables, strikethrough, autolinks,
+// task lists, footnotes, fenced code and heading anchors enabled
+func NewRenderer() *Renderer {
+	return &Renderer{
+		markdown: goldmark.New(
+			goldmark.WithExtensions(
+				extension.GFM,
+				extension.Footnote,
+			),
+			goldmark.WithParserOptions(
+				parser.WithAutoHeadingID(),
+			),
+			goldmark.WithRendererOptions(
+				// Raw HTML is passed through here and cleaned by the sanitizer
+				html.WithUnsafe(),
+			),
+		),
+		policy: NewPolicy(),
+	}
+}
+
+// NewPolicy returns the allow-list HTML policy applied to rendered content
+func NewPolicy() *bluemonday.Policy {
+	policy := bluemonday.UGCPolicy()
+
+	// Book content links are authored by editors, so only external links get nofollow
+	policy.RequireNoFollowOnLinks(false)
+	policy.RequireNoFollowOnFullyQualifiedLinks(true)
+
+	// Fenced code language hints
+	policy.AllowAttrs("class").Matching(regexp.MustCompile(`^language-[\w+#.-]+$`)).OnElements("code")
+
+	// GFM task list checkboxes
+	policy.AllowAttrs("type").Matching(regexp.MustCompile(`^checkbox$`)).OnElements("input")
+	policy.AllowAttrs("checked", "disabled").OnElements("input")
+
+	// Footnote markup
+	policy.AllowAttrs("class").Matching(regexp.MustCompile(`^(footnote-ref|footnote-backref|footnotes)$`)).OnElements("a", "div")
+	policy.AllowAttrs("role").Matching(regexp.MustCompile(`^doc-(noteref|endnotes|backlink)$`)).OnElements("a", "div")
+
+	// Table cell alignment
+	policy.AllowAttrs("style").Matching(regexp.MustCompile(`^text-align:(left|right|center)$`)).OnElements("th", "td")
+
+	return policy
+}
+
+// Render converts Markdown into sanitized HTML. Content embed placeholders are
+// preserved verbatim, and a placeholder that forms a paragraph on its own is
+// emitted without the surrounding <p> so block-level embeds nest correctly.
+func (r *Renderer) Render(source string) (string, error) {
+	var placeholders []string
+	source = placeholderRegex.ReplaceAllStringFunc(source, func(match string) string {
+		placeholders = append(placeholders, match)
+		return placeholderStart + strconv.Itoa(len(placeholders)-1) + placeholderEnd
+	})
+
+	var buf bytes.Buffer
+	if err := r.markdown.Convert([]byte(source), &buf); err != nil {
+		return "", fmt.Errorf("failed to render markdown: %w", err)
+	}
+
+	output := r.policy.Sanitize(buf.String())
+
+	output = standalonePlaceholderRegex.ReplaceAllString(output, "$1")
+	output = placeholderTokenRegex.ReplaceAllStringFunc(output, func(token string) string {
+		index, err := strconv.Atoi(strings.TrimSuffix(strings.TrimPrefix(token, placeholderStart), placeholderEnd))
+		if err != nil || index >= len(placeholders) {
+			return ""
+		}
+		return placeholders[index]
+	})
+
+	return output, nil
+}
+
+// Sanitize applies the content HTML policy to an HTML fragment
+func (r *Renderer) Sanitize(fragment string) string {
+	return r.policy.Sanitize(fragment)
+}
+
+// PlainText renders Markdown and strips all markup, returning whitespace
+// separated text suitable for search indexing. Placeholders are dropped.
+func (r *Renderer) PlainText(source string) (string, error) {
+	blocks, err := r.Paragraphs(source)
+	if err != nil {
+		return "", err
+	}
+	return strings.Join(blocks, " "), nil
+}
+
+// Paragraphs renders Markdown and returns the plain text of each block, such
+// as a paragraph, heading or list item, for text-to-speech. Placeholders are
+// dropped.
+func (r *Renderer) Paragraphs(source string) ([]string, error) {
+	source = placeholderRegex.ReplaceAllString(source, " ")
+
+	var buf bytes.Buffer
+	if err := r.markdown.Convert([]byte(source), &buf); err != nil {
+		return nil, fmt.Errorf("failed to render markdown: %w", err)
+	}
+
+	text := textPolicy.Sanitize(blockEndRegex.ReplaceAllString(buf.String(), "$0"+blockSeparator))
+	var blocks []string
+	for _, block := range strings.Split(stdhtml.UnescapeString(text), blockSeparator) {
+		if words := strings.Fields(block); len(words) > 0 {
+			blocks = append(blocks, strings.Join(words, " "))
+		}
+	}
+	return blocks, nil
+}
+
+// blockSeparator marks block ends while tags are stripped
+const blockSeparator = "\u2029"
+
+// textPolicy strips every element, keeping only text content
+var textPolicy = bluemonday.StrictPolicy()
+
+// blockEndRegex matches closing block tags and line breaks so words in
+// adjacent blocks stay separated once tags are stripped
+var blockEndRegex = regexp.MustCompile(`</(?:p|h[1-6]|li|td|th|blockquote|pre|div)>|<br\s*/?>`)
+
+// defaultRenderer is shared by the package-level helpers
+var defaultRenderer = NewRenderer()
+
+// Render converts Markdown into sanitized HTML using the default renderer
+func Render(source string) (string, error) {
+	return defaultRenderer.Render(source)
+}
+
+// PlainText converts Markdown into plain text using the default renderer
+func PlainText(source string) (string, error) {
+	return defaultRenderer.PlainText(source)
+}
+
+// Paragraphs converts Markdown into plain text blocks using the default
+// renderer
+func Paragraphs(source string) ([]string, error) {
+	return defaultRenderer.Paragraphs(source)
+}
+
+// Sanitize applies the content HTML policy using the default renderer
+func Sanitize(fragment string) string {
+	return defaultRenderer.Sanitize(fragment)
+}
@@ -0,0 +1,96 @@
+package markdown
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var update = flag.Bool("update", false, "update golden files in testdata")
+
+// TestRenderGolden renders every testdata/*.md file and compares the output
+// with the matching .html golden file. Run with -update to regenerate them.
+func TestRenderGolden(t *testing.T) {
+	inputs, err := filepath.Glob(filepath.Join("testdata", "*.md"))
+	require.NoError(t, err)
+	require.NotEmpty(t, inputs)
+
+	renderer := NewRenderer()
+
+	for _, input := range inputs {
+		name := strings.TrimSuffix(filepath.Base(input), ".md")
+		t.Run(name, func(t *testing.T) {
+			source, err := os.ReadFile(input)
+			require.NoError(t, err)
+
+			output, err := renderer.Render(string(source))
+			require.NoError(t, err)
+
+			golden := strings.TrimSuffix(input, ".md") + ".html"
+			if *update {
+				require.NoError(t, os.WriteFile(golden, []byte(output), 0644))
+			}
+
+			expected, err := os.ReadFile(golden)
+			require.NoError(t, err)
+			assert.Equal(t, string(expected), output)
+		})
+	}
+}
+
+func TestRenderStripsDangerousMarkup(t *testing.T) {
+	output, err := Render("<script>alert(1)</script>\n\n[x](javascript:alert(1)) <b onclick=\"x()\">b</b>")
+	require.NoError(t, err)
+
+	assert.NotContains(t, output, "<script")
+	assert.NotContains(t, output, "javascript:")
+	assert.NotContains(t, output, "onclick")
+}
+
+func TestRenderPreservesPlaceholders(t *testing.T) {
+	output, err := Render("{{interactive:7}}\n\nSee {{image:/a_b_c.png}} here")
+	require.NoError(t, err)
+
+	assert.True(t, strings.HasPrefix(output, "{{interactive:7}}"), "standalone placeholder should not be wrapped in <p>")
+	assert.Contains(t, output, "<p>See {{image:/a_b_c.png}} here</p>")
+}
+
+func TestRenderSanitizesMalformedPlaceholders(t *testing.T) {
+	payloads := []string{
+		"{{topic:<script>alert(1)</script>}}",
+		"{{interactive:<img src=x onerror=alert(1)>}}",
+		"{{image:x\" onerror=\"alert(1)}}",
+		"{{video:<iframe src=javascript:alert(1)>}}",
+		"{{audio:/a.mp3><script>alert(1)</script>}}",
+	}
+	for _, payload := range payloads {
+		t.Run(payload, func(t *testing.T) {
+			output, err := Render(payload)
+			require.NoError(t, err)
+
+			assert.NotContains(t, output, "<script")
+			assert.NotContains(t, output, "<iframe")
+			assert.NotContains(t, output, "onerror=alert")
+			assert.NotContains(t, output, "\" onerror")
+		})
+	}
+}
+
+func TestPlainText(t *testing.T) {
+	text, err := PlainText("# Title\n\nSome **bold** &amp; <em>inline</em> text.\n\n{{interactive:3}}\n\n- one\n- two")
+	require.NoError(t, err)
+
+	assert.Equal(t, "Title Some bold & inline text. one two", text)
+}
+
+func TestParagraphs(t *testing.T) {
+	blocks, err := Paragraphs("# Title\n\nFirst line\nsame paragraph.\n\n{{interactive:3}}\n\n- one\n- two")
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"Title", "First line same paragraph.", "one", "two"}, blocks)
+}
@@ -239,16 +239,6 @@ func PanicRecovery(logger Logger) gin.HandlerFunc {
 	})
 }
 
-// RateLimit middleware (basic implementation)
-func RateLimit() gin.HandlerFunc {
-	// This is a basic implementation
-	// In production, you'd want to use Redis or similar
-	return gin.HandlerFunc(func(c *gin.Context) {
-		// Basic rate limiting logic would go here
-		c.Next()
-	})
-}
-
 // AuthRequired middleware validates JWT tokens with enhanced security
 func AuthRequired(jwtManager JWTManager, logger Logger) gin.HandlerFunc {
 	return gin.HandlerFunc(func(c *gin.Context) {
@@ -0,0 +1,416 @@
+package middleware
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	goredis "github.com/redis/go-redis/v9"
+	"github.com/yerenwgventures/GreatNigeriaLibrary-Foundation/backend/pkg/common/config"
+	"github.com/yerenwgventures/GreatNigeriaLibrary-Foundation/backend/pkg/common/errors"
+	"github.com/yerenwgventures/GreatNigeriaLibrary-Foundation/backend/pkg/common/redis"
+)
+
+// Default rate limits used when RateLimitConfig leaves them unset
+const (
+	DefaultRequestsPerMinute = 60
+	DefaultBurstSize         = 10
+)
+
+// rateLimitKeyPrefix namespaces rate limit buckets in Redis
+const rateLimitKeyPrefix = "ratelimit:"
+
+// RateLimitRule describes a token bucket: it holds up to BurstSize tokens and
+// refills at RequestsPerMinute tokens per minute
+type RateLimitRule struct {
+	RequestsPerMinute int
+	BurstSize         int
+}
+
+// normalized fills in defaults for unset values
+func (r RateLimitRule) normalized() RateLimitRule {
+	if r.RequestsPerMinute <= 0 {
+		r.RequestsPerMinute = DefaultRequestsPerMinute
+	}
+	if r.BurstSize <= 0 {
+		r.BurstSize = DefaultBurstSize
+	}
+	return r
+}
+
+// ratePerSecond returns the refill rate in tokens per second
+func (r RateLimitRule) ratePerSecond() float64 {
+	return float64(r.RequestsPerMinute) / 60
+}
+
+// RateLimitResult is the outcome of taking a token from a bucket
+type RateLimitResult struct {
+	Allowed    bool
+	Limit      int
+	Remaining  int
+	ResetAfter time.Duration // Time until the bucket is full again
+	RetryAfter time.Duration // Time until the next token is available, zero when allowed
+}
+
+// newRateLimitResult builds a result from the tokens left in a bucket
+func newRateLimitResult(rule RateLimitRule, allowed bool, tokens float64) RateLimitResult {
+	rate := rule.ratePerSecond()
+	result := RateLimitResult{
+		Allowed:    allowed,
+		Limit:      rule.BurstSize,
+		Remaining:  int(math.Floor(tokens)),
+		ResetAfter: time.Duration((float64(rule.BurstSize) - tokens) / rate * float64(time.Second)),
+	}
+	if !allowed {
+		result.RetryAfter = time.Duration((1 - tokens) / rate * float64(time.Second))
+	}
+	return result
+}
+
+// RateLimitStore takes tokens from named buckets
+type RateLimitStore interface {
+	Take(ctx context.Context, key string, rule RateLimitRule) (RateLimitResult, error)
+}
+
+// tokenBucketScript atomically refills and takes from a bucket stored as a
+// hash of {tokens, ts}. Redis server time is used so every instance shares a clock.
+var tokenBucketScript = goredis.NewScript(`
+local rate = tonumber(ARGV[1])
+local capacity = tonumber(ARGV[2])
+local time = redis.call('TIME')
+local now = tonumber(time[1]) * 1000 + math.floor(tonumber(time[2]) / 1000)
+
+local state = redis.call('HMGET', KEYS[1], 'tokens', 'ts')
+local tokens = tonumber(state[1])
+local ts = tonumber(state[2])
+if tokens == nil or ts == nil then
+	tokens = capacity
+	ts = now
+end
+
+tokens = math.min(capacity, tokens + math.max(0, now - ts) * rate)
+local allowed = 0
+if tokens >= 1 then
+	tokens = tokens - 1
+	allowed = 1
+end
+
+redis.call('HSET', KEYS[1], 'tokens', tostring(tokens), 'ts', now)
+redis.call('PEXPIRE', KEYS[1], math.ceil((capacity - tokens) / rate) + 1000)
+return {allowed, tostring(tokens)}
+`)
+
+// RedisRateLimitStore keeps token buckets in Redis so limits are shared across instances
+type RedisRateLimitStore struct {
+	client *redis.Client
+}
+
+// NewRedisRateLimitStore creates a Redis-backed rate limit store
+func NewRedisRateLimitStore(client *redis.Client) *RedisRateLimitStore {
+	return &RedisRateLimitStore{client: client}
+}
+
+// Take removes a token from the bucket if one is available
+func (s *RedisRateLimitStore) Take(ctx context.Context, key string, rule RateLimitRule) (RateLimitResult, error) {
+	rule = rule.normalized()
+	ratePerMillisecond := rule.ratePerSecond() / 1000
+
+	values, err := tokenBucketScript.Run(ctx, s.client.Client, []string{rateLimitKeyPrefix + key},
+		strconv.FormatFloat(ratePerMillisecond, 'f', -1, 64), rule.BurstSize).Slice()
+	if err != nil {
+		return RateLimitResult{}, fmt.Errorf("failed to run rate limit script: %w", err)
+	}
+	if len(values) != 2 {
+		return RateLimitResult{}, fmt.Errorf("unexpected rate limit script result: %v", values)
+	}
+
+	allowed, _ := values[0].(int64)
+	tokensText, _ := values[1].(string)
+	tokens, err := strconv.ParseFloat(tokensText, 64)
+	if err != nil {
+		return RateLimitResult{}, fmt.Errorf("invalid token count %q: %w", tokensText, err)
+	}
+
+	return newRateLimitResult(rule, allowed == 1, tokens), nil
+}
+
+// memoryBucket is an in-process token bucket
+type memoryBucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// MemoryRateLimitStore keeps token buckets in process memory. It is used when
+// Redis is not configured and as a fallback when Redis is unreachable.
+type MemoryRateLimitStore struct {
+	mu        sync.Mutex
+	buckets   map[string]*memoryBucket
+	now       func() time.Time
+	lastSweep time.Time
+}
+
+// NewMemoryRateLimitStore creates an in-process rate limit store
+func NewMemoryRateLimitStore() *MemoryRateLimitStore {
+	return &MemoryRateLimitStore{
+		buckets: make(map[string]*memoryBucket),
+		now:     time.Now,
+	}
+}
+
+// Take removes a token from the bucket if one is available
+func (s *MemoryRateLimitStore) Take(ctx context.Context, key string, rule RateLimitRule) (RateLimitResult, error) {
+	rule = rule.normalized()
+	capacity := float64(rule.BurstSize)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	s.sweep(now)
+
+	bucket, exists := s.buckets[key]
+	if !exists {
+		bucket = &memoryBucket{tokens: capacity, last: now}
+		s.buckets[key] = bucket
+	}
+
+	elapsed := now.Sub(bucket.last).Seconds()
+	if elapsed > 0 {
+		bucket.tokens = math.Min(capacity, bucket.tokens+elapsed*rule.ratePerSecond())
+		bucket.last = now
+	}
+
+	allowed := bucket.tokens >= 1
+	if allowed {
+		bucket.tokens--
+	}
+
+	return newRateLimitResult(rule, allowed, bucket.tokens), nil
+}
+
+// sweep drops buckets that have been idle long enough to be full again.
+// It runs at most once a minute so Take stays cheap.
+func (s *MemoryRateLimitStore) sweep(now time.Time) {
+	if now.Sub(s.lastSweep) < time.Minute {
+		return
+	}
+	s.lastSweep = now
+
+	for key, bucket := range s.buckets {
+		if now.Sub(bucket.last) > 10*time.Minute {
+			delete(s.buckets, key)
+		}
+	}
+}
+
+// RateLimitKeyFunc derives the bucket key for a request
+type RateLimitKeyFunc func(c *gin.Context) string
+
+// KeyByIP keys requests by client IP
+func KeyByIP(c *gin.Context) string {
+	return "ip:" + c.ClientIP()
+}
+
+// KeyByUserOrIP keys requests by authenticated user ID, falling back to the
+// client IP for anonymous requests
+func KeyByUserOrIP(c *gin.Context) string {
+	if userID, ok := GetUserID(c); ok && userID != 0 {
+		return "user:" + strconv.FormatUint(uint64(userID), 10)
+	}
+	return KeyByIP(c)
+}
+
+// RateLimitPolicy is a named rule with its key strategy. The name scopes the
+// buckets, so policies applied to different route groups never share tokens.
+type RateLimitPolicy struct {
+	Name    string
+	Rule    RateLimitRule
+	KeyFunc RateLimitKeyFunc
+}
+
+// rateLimitOverride applies a stricter policy to paths ending in a suffix
+type rateLimitOverride struct {
+	pathSuffix string
+	policy     RateLimitPolicy
+}
+
+// DefaultAuthRateLimitRule is applied to login, password reset and 2FA routes
+var DefaultAuthRateLimitRule = RateLimitRule{RequestsPerMinute: 5, BurstSize: 5}
+
+// RateLimiter applies token-bucket rate limiting to requests
+type RateLimiter struct {
+	enabled       bool
+	store         RateLimitStore
+	fallback      *MemoryRateLimitStore
+	defaultPolicy RateLimitPolicy
+	overrides     []rateLimitOverride
+	logger        Logger
+}
+
+// NewRateLimiter creates a rate limiter from configuration. Buckets are kept
+// in Redis when a client is given and in process memory otherwise. Requests
+// are keyed by user ID when authenticated and by client IP otherwise, and
+// auth-sensitive routes get stricter per-IP overrides.
+func NewRateLimiter(cfg config.RateLimitConfig, client *redis.Client, logger Logger) *RateLimiter {
+	limiter := &RateLimiter{
+		enabled:  cfg.Enabled,
+		fallback: NewMemoryRateLimitStore(),
+		defaultPolicy: RateLimitPolicy{
+			Name: "default",
+			Rule: RateLimitRule{
+				RequestsPerMinute: cfg.RequestsPerMinute,
+				BurstSize:         cfg.BurstSize,
+			}.normalized(),
+			KeyFunc: KeyByUserOrIP,
+		},
+		logger: logger,
+	}
+
+	if client != nil {
+		limiter.store = NewRedisRateLimitStore(client)
+	} else {
+		limiter.store = limiter.fallback
+	}
+
+	limiter.WithOverride("/auth/login", RateLimitPolicy{Name: "auth-login", Rule: DefaultAuthRateLimitRule, KeyFunc: KeyByIP})
+	limiter.WithOverride("/password/reset", RateLimitPolicy{Name: "password-reset", Rule: DefaultAuthRateLimitRule, KeyFunc: KeyByIP})
+	limiter.WithOverride("/password/reset/confirm", RateLimitPolicy{Name: "password-reset", Rule: DefaultAuthRateLimitRule, KeyFunc: KeyByIP})
+	limiter.WithOverride("/2fa/verify", RateLimitPolicy{Name: "2fa-verify", Rule: DefaultAuthRateLimitRule, KeyFunc: KeyByUserOrIP})
+	limiter.WithOverride("/webauthn/login/finish", RateLimitPolicy{Name: "auth-login", Rule: DefaultAuthRateLimitRule, KeyFunc: KeyByIP})
+	limiter.WithOverride("/webauthn/verify/finish", RateLimitPolicy{Name: "2fa-verify", Rule: DefaultAuthRateLimitRule, KeyFunc: KeyByUserOrIP})
+
+	return limiter
+}
+
+// RateLimit middleware applies token-bucket rate limiting from configuration.
+// Pass a nil client to keep buckets in process memory.
+func RateLimit(cfg config.RateLimitConfig, client *redis.Client, logger Logger) gin.HandlerFunc {
+	return NewRateLimiter(cfg, client, logger).Middleware()
+}
+
+// WithStore replaces the bucket store
+func (l *RateLimiter) WithStore(store RateLimitStore) *RateLimiter {
+	l.store = store
+	return l
+}
+
+// WithOverride applies a policy to every request whose path ends with
+// pathSuffix, replacing any existing override for the same suffix
+func (l *RateLimiter) WithOverride(pathSuffix string, policy RateLimitPolicy) *RateLimiter {
+	if policy.KeyFunc == nil {
+		policy.KeyFunc = KeyByUserOrIP
+	}
+	policy.Rule = policy.Rule.normalized()
+
+	for i := range l.overrides {
+		if l.overrides[i].pathSuffix == pathSuffix {
+			l.overrides[i].policy = policy
+			return l
+		}
+	}
+	l.overrides = append(l.overrides, rateLimitOverride{pathSuffix: pathSuffix, policy: policy})
+	return l
+}
+
+// Middleware returns a handler applying the default policy and path overrides
+func (l *RateLimiter) Middleware() gin.HandlerFunc {
+	return gin.HandlerFunc(func(c *gin.Context) {
+		l.apply(c, l.policyFor(c.Request.URL.Path))
+	})
+}
+
+// Limit returns a handler applying a specific policy, typically attached to a
+// route group with group.Use(limiter.Limit(policy))
+func (l *RateLimiter) Limit(policy RateLimitPolicy) gin.HandlerFunc {
+	if policy.KeyFunc == nil {
+		policy.KeyFunc = KeyByUserOrIP
+	}
+	policy.Rule = policy.Rule.normalized()
+
+	return gin.HandlerFunc(func(c *gin.Context) {
+		l.apply(c, policy)
+	})
+}
+
+// policyFor picks the override matching a path, or the default policy
+func (l *RateLimiter) policyFor(path string) RateLimitPolicy {
+	path = strings.TrimSuffix(path, "/")
+
+	// The longest matching suffix wins so more specific overrides take precedence
+	var matched *rateLimitOverride
+	for i := range l.overrides {
+		override := &l.overrides[i]
+		if strings.HasSuffix(path, override.pathSuffix) {
+			if matched == nil || len(override.pathSuffix) > len(matched.pathSuffix) {
+				matched = override
+			}
+		}
+	}
+	if matched != nil {
+		return matched.policy
+	}
+	return l.defaultPolicy
+}
+
+// apply takes a token for the request and rejects it when the bucket is empty
+func (l *RateLimiter) apply(c *gin.Context, policy RateLimitPolicy) {
+	if !l.enabled {
+		c.Next()
+		return
+	}
+
+	key := policy.Name + ":" + policy.KeyFunc(c)
+	result, err := l.store.Take(c.Request.Context(), key, policy.Rule)
+	if err != nil && l.store != RateLimitStore(l.fallback) {
+		// Keep limiting locally rather than failing open when Redis is unavailable
+		if l.logger != nil {
+			l.logger.WithField("error", err.Error()).Error("Rate limit store unavailable, using in-process limiter")
+		}
+		result, err = l.fallback.Take(c.Request.Context(), key, policy.Rule)
+	}
+	if err != nil {
+		c.Next()
+		return
+	}
+
+	setRateLimitHeaders(c, policy.Rule, result)
+
+	if !result.Allowed {
+		if l.logger != nil {
+			l.logger.WithFields(map[string]interface{}{
+				"policy": policy.Name,
+				"key":    key,
+				"path":   c.Request.URL.Path,
+				"ip":     c.ClientIP(),
+			}).Info("Rate limit exceeded")
+		}
+		c.Header("Retry-After", strconv.Itoa(ceilSeconds(result.RetryAfter)))
+		c.Error(errors.ErrRateLimitExceeded)
+		c.Abort()
+		return
+	}
+
+	c.Next()
+}
+
+// setRateLimitHeaders writes the IETF RateLimit header fields
+func setRateLimitHeaders(c *gin.Context, rule RateLimitRule, result RateLimitResult) {
+	window := int(math.Ceil(float64(rule.BurstSize) / rule.ratePerSecond()))
+	c.Header("RateLimit-Limit", strconv.Itoa(result.Limit))
+	c.Header("RateLimit-Remaining", strconv.Itoa(result.Remaining))
+	c.Header("RateLimit-Reset", strconv.Itoa(ceilSeconds(result.ResetAfter)))
+	c.Header("RateLimit-Policy", fmt.Sprintf("%d;w=%d", result.Limit, window))
+}
+
+// ceilSeconds rounds a duration up to whole seconds
+func ceilSeconds(d time.Duration) int {
+	if d <= 0 {
+		return 0
+	}
+	return int(math.Ceil(d.Seconds()))
+}
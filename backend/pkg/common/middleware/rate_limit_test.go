@@ -0,0 +1,141 @@
+package middleware
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/yerenwgventures/GreatNigeriaLibrary-Foundation/backend/pkg/common/config"
+)
+
+// nopLogger discards all log output
+type nopLogger struct{}
+
+func (nopLogger) Info(msg string)                                  {}
+func (nopLogger) Error(msg string)                                 {}
+func (l nopLogger) WithField(key string, value interface{}) Logger { return l }
+func (l nopLogger) WithFields(fields map[string]interface{}) Logger {
+	return l
+}
+
+func newTestRouter(limiter *RateLimiter) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(ErrorHandler(nopLogger{}))
+	router.Use(func(c *gin.Context) {
+		if userID := c.GetHeader("X-Test-User"); userID == "42" {
+			c.Set("user_id", uint(42))
+		}
+		c.Next()
+	})
+	router.Use(limiter.Middleware())
+	ok := func(c *gin.Context) { c.Status(http.StatusOK) }
+	router.GET("/books", ok)
+	router.POST("/auth/login", ok)
+	return router
+}
+
+func perform(router *gin.Engine, method, path, ip, userID string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, nil)
+	req.RemoteAddr = ip + ":1234"
+	if userID != "" {
+		req.Header.Set("X-Test-User", userID)
+	}
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestMemoryRateLimitStoreRefills(t *testing.T) {
+	store := NewMemoryRateLimitStore()
+	now := time.Unix(1700000000, 0)
+	store.now = func() time.Time { return now }
+	rule := RateLimitRule{RequestsPerMinute: 60, BurstSize: 2}
+
+	for i := 0; i < 2; i++ {
+		result, err := store.Take(context.Background(), "k", rule)
+		require.NoError(t, err)
+		assert.True(t, result.Allowed)
+	}
+
+	result, err := store.Take(context.Background(), "k", rule)
+	require.NoError(t, err)
+	assert.False(t, result.Allowed)
+	assert.Equal(t, 0, result.Remaining)
+	assert.Equal(t, time.Second, result.RetryAfter)
+
+	now = now.Add(1500 * time.Millisecond)
+	result, err = store.Take(context.Background(), "k", rule)
+	require.NoError(t, err)
+	assert.True(t, result.Allowed)
+	assert.Equal(t, 0, result.Remaining)
+}
+
+func TestRateLimitRejectsWithHeadersAndAppError(t *testing.T) {
+	limiter := NewRateLimiter(config.RateLimitConfig{Enabled: true, RequestsPerMinute: 60, BurstSize: 2}, nil, nopLogger{})
+	router := newTestRouter(limiter)
+
+	w := perform(router, http.MethodGet, "/books", "10.0.0.1", "")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "2", w.Header().Get("RateLimit-Limit"))
+	assert.Equal(t, "1", w.Header().Get("RateLimit-Remaining"))
+	assert.Equal(t, "2;w=2", w.Header().Get("RateLimit-Policy"))
+
+	perform(router, http.MethodGet, "/books", "10.0.0.1", "")
+	w = perform(router, http.MethodGet, "/books", "10.0.0.1", "")
+	require.Equal(t, http.StatusTooManyRequests, w.Code)
+	assert.Equal(t, "0", w.Header().Get("RateLimit-Remaining"))
+	assert.Equal(t, "1", w.Header().Get("Retry-After"))
+
+	var body map[string]interface{}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+	assert.Equal(t, false, body["success"])
+	assert.Equal(t, "rate_limit", body["code"])
+
+	// A different client has its own bucket
+	w = perform(router, http.MethodGet, "/books", "10.0.0.2", "")
+	assert.Equal(t, http.StatusOK, w.Code)
+}
+
+func TestRateLimitKeysByUserWhenAuthenticated(t *testing.T) {
+	limiter := NewRateLimiter(config.RateLimitConfig{Enabled: true, RequestsPerMinute: 60, BurstSize: 1}, nil, nopLogger{})
+	router := newTestRouter(limiter)
+
+	assert.Equal(t, http.StatusOK, perform(router, http.MethodGet, "/books", "10.0.0.1", "42").Code)
+	// Same user from another IP shares the bucket
+	assert.Equal(t, http.StatusTooManyRequests, perform(router, http.MethodGet, "/books", "10.0.0.2", "42").Code)
+	// Anonymous request from the first IP is keyed separately
+	assert.Equal(t, http.StatusOK, perform(router, http.MethodGet, "/books", "10.0.0.1", "").Code)
+}
+
+func TestRateLimitAuthOverride(t *testing.T) {
+	limiter := NewRateLimiter(config.RateLimitConfig{Enabled: true, RequestsPerMinute: 600, BurstSize: 100}, nil, nopLogger{})
+	router := newTestRouter(limiter)
+
+	for i := 0; i < DefaultAuthRateLimitRule.BurstSize; i++ {
+		assert.Equal(t, http.StatusOK, perform(router, http.MethodPost, "/auth/login", "10.0.0.1", "").Code)
+	}
+	w := perform(router, http.MethodPost, "/auth/login", "10.0.0.1", "")
+	assert.Equal(t, http.StatusTooManyRequests, w.Code)
+	assert.Equal(t, "5", w.Header().Get("RateLimit-Limit"))
+
+	// The default policy keeps its own, larger bucket
+	assert.Equal(t, http.StatusOK, perform(router, http.MethodGet, "/books", "10.0.0.1", "").Code)
+}
+
+func TestRateLimitDisabled(t *testing.T) {
+	limiter := NewRateLimiter(config.RateLimitConfig{Enabled: false, BurstSize: 1}, nil, nopLogger{})
+	router := newTestRouter(limiter)
+
+	for i := 0; i < 3; i++ {
+		w := perform(router, http.MethodGet, "/books", "10.0.0.1", "")
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Empty(t, w.Header().Get("RateLimit-Limit"))
+	}
+}
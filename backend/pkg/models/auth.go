@@ -1,6 +1,7 @@
 package models
 
 import (
+	"encoding/json"
 	"time"
 )
 
@@ -98,6 +99,58 @@ type UserTrustLevel struct {
 	User User `json:"user" gorm:"foreignKey:UserID"`
 }
 
+// UserIdentity links a user to their account at an external OAuth provider.
+// A user has at most one identity per provider.
+type UserIdentity struct {
+	ID          uint      `json:"id" gorm:"primaryKey"`
+	UserID      uint      `json:"user_id" gorm:"not null;uniqueIndex:idx_user_identities_user_provider"`
+	Provider    string    `json:"provider" gorm:"size:50;not null;uniqueIndex:idx_user_identities_user_provider;uniqueIndex:idx_user_identities_provider_subject"`
+	Subject     string    `json:"-" gorm:"size:255;not null;uniqueIndex:idx_user_identities_provider_subject"` // The user's ID at the provider
+	Email       string    `json:"email" gorm:"size:255"`
+	Name        string    `json:"name" gorm:"size:255"`
+	LastLoginAt time.Time `json:"last_login_at"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// WebAuthnCredential is a passkey or security key registered by a user. It
+// signs the user in without a password, or serves as a second factor.
+type WebAuthnCredential struct {
+	ID              uint       `json:"id" gorm:"primaryKey"`
+	UserID          uint       `json:"user_id" gorm:"not null;index"`
+	CredentialID    []byte     `json:"-" gorm:"not null;uniqueIndex"`
+	PublicKey       []byte     `json:"-" gorm:"not null"` // COSE encoded
+	AttestationType string     `json:"-" gorm:"size:50"`
+	Transports      string     `json:"transports" gorm:"size:255"` // Comma-separated, e.g. "internal,hybrid"
+	AAGUID          []byte     `json:"-" gorm:"column:aaguid"`
+	SignCount       uint32     `json:"-" gorm:"not null;default:0"`
+	UserVerified    bool       `json:"user_verified"`
+	BackupEligible  bool       `json:"backup_eligible"` // Synced passkeys are backup eligible
+	BackupState     bool       `json:"backed_up"`
+	Name            string     `json:"name" gorm:"size:100"`
+	LastUsedAt      *time.Time `json:"last_used_at"`
+	CreatedAt       time.Time  `json:"created_at"`
+	UpdatedAt       time.Time  `json:"updated_at"`
+}
+
+// TableName keeps GORM from naming the table "web_authn_credentials"
+func (WebAuthnCredential) TableName() string {
+	return "webauthn_credentials"
+}
+
+// WebAuthnRegisterRequest finishes registering a credential
+type WebAuthnRegisterRequest struct {
+	CeremonyID string          `json:"ceremony_id" binding:"required"`
+	Name       string          `json:"name" binding:"max=100"`
+	Credential json.RawMessage `json:"credential" binding:"required"` // The PublicKeyCredential from navigator.credentials.create
+}
+
+// WebAuthnAssertionRequest finishes a login or second factor check
+type WebAuthnAssertionRequest struct {
+	CeremonyID string          `json:"ceremony_id" binding:"required"`
+	Credential json.RawMessage `json:"credential" binding:"required"` // The PublicKeyCredential from navigator.credentials.get
+}
+
 // OAuthUserInfo represents OAuth user information
 type OAuthUserInfo struct {
 	ID       string `json:"id"`
@@ -123,23 +123,6 @@ type ActivityLog struct {
 	User User `json:"user" gorm:"foreignKey:UserID"`
 }
 
-// AuditLog represents an audit log entry
-type AuditLog struct {
-	ID         uint      `json:"id" gorm:"primaryKey"`
-	UserID     uint      `json:"user_id" gorm:"index"`
-	Action     string    `json:"action" gorm:"size:100;not null"`
-	Table      string    `json:"table" gorm:"size:100;not null"`
-	RecordID   string    `json:"record_id" gorm:"size:100;not null"`
-	OldValues  string    `json:"old_values" gorm:"type:json"`
-	NewValues  string    `json:"new_values" gorm:"type:json"`
-	IPAddress  string    `json:"ip_address" gorm:"size:45"`
-	UserAgent  string    `json:"user_agent" gorm:"size:1000"`
-	CreatedAt  time.Time `json:"created_at"`
-	
-	// Relationships
-	User User `json:"user" gorm:"foreignKey:UserID"`
-}
-
 // SystemConfig represents system configuration
 type SystemConfig struct {
 	ID          uint      `json:"id" gorm:"primaryKey"`
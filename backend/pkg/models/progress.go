@@ -0,0 +1,22 @@
+package models
+
+import (
+	"time"
+)
+
+// TopicCompletion records that a user finished a book section. A section
+// counts as completed once per user.
+type TopicCompletion struct {
+	ID          uint      `json:"id" gorm:"primaryKey"`
+	UserID      uint      `json:"user_id" gorm:"not null;uniqueIndex:idx_topic_completions_user_section,priority:1"`
+	BookID      uint      `json:"book_id" gorm:"not null;index"`
+	ChapterID   uint      `json:"chapter_id" gorm:"not null"`
+	SectionID   uint      `json:"section_id" gorm:"not null;uniqueIndex:idx_topic_completions_user_section,priority:2"`
+	Points      int       `json:"points"` // Points awarded for the completion, 0 if capped or disabled
+	CompletedAt time.Time `json:"completed_at" gorm:"not null"`
+}
+
+// TableName specifies the table name for TopicCompletion
+func (TopicCompletion) TableName() string {
+	return "topic_completions"
+}
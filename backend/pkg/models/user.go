@@ -45,9 +45,7 @@ type User struct {
 	// Account Status
 	IsActive        bool      `json:"is_active" gorm:"default:true"`
 	IsVerified      bool      `json:"is_verified" gorm:"default:false"`
-	IsOAuth         bool      `json:"is_oauth" gorm:"default:false"`
-	OAuthProvider   string    `json:"oauth_provider" gorm:"size:50"`
-	OAuthID         string    `json:"oauth_id" gorm:"size:255"`
+	IsOAuth         bool      `json:"is_oauth" gorm:"default:false"` // Created through an OAuth provider, see UserIdentity
 	
 	// Membership & Points
 	MembershipLevel MembershipLevel `json:"membership_level" gorm:"default:'basic'"`
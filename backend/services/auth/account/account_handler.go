@@ -1,40 +1,49 @@
-package handlers
+package account
 
 import (
+	"context"
 	"net/http"
-	"strconv"
 
 	"github.com/gin-gonic/gin"
+	"github.com/yerenwgventures/GreatNigeriaLibrary-Foundation/backend/pkg/common/audit"
 	"github.com/yerenwgventures/GreatNigeriaLibrary-Foundation/backend/pkg/common/errors"
 	"github.com/yerenwgventures/GreatNigeriaLibrary-Foundation/backend/pkg/common/logger"
-	"github.com/yerenwgventures/GreatNigeriaLibrary-Foundation/backend/pkg/models"
 )
 
+// AccountService deletes the signed-in user's account
+type AccountService interface {
+	DeleteUser(ctx context.Context, userID uint, password string) error
+}
+
+// DeleteAccountRequest confirms an account deletion with the user's password
+type DeleteAccountRequest struct {
+	Password string `json:"password" binding:"required"`
+}
+
 // AccountHandler handles account management requests
 type AccountHandler struct {
-	userService UserService
-	logger      *logger.Logger
+	accountService AccountService
+	logger         *logger.Logger
 }
 
 // NewAccountHandler creates a new account handler
-func NewAccountHandler(userService UserService, logger *logger.Logger) *AccountHandler {
+func NewAccountHandler(accountService AccountService, logger *logger.Logger) *AccountHandler {
 	return &AccountHandler{
-		userService: userService,
-		logger:      logger,
+		accountService: accountService,
+		logger:         logger,
 	}
 }
 
 // DeleteAccount handles account deletion requests
 func (h *AccountHandler) DeleteAccount(c *gin.Context) {
 	// Get user ID from context (set by auth middleware)
-	userIDStr, exists := c.Get("userID")
+	userIDValue, exists := c.Get("user_id")
 	if !exists {
-		h.logger.Error("User ID not found in context")
-		c.JSON(http.StatusUnauthorized, errors.ErrUnauthorized("Authentication required"))
+		c.JSON(http.StatusUnauthorized, errors.ErrUnauthorizedAccess("Authentication required"))
 		return
 	}
 
-	userID, ok := userIDStr.(uint)
+	userID, ok := userIDValue.(uint)
 	if !ok {
 		h.logger.Error("Failed to parse user ID from context")
 		c.JSON(http.StatusInternalServerError, errors.ErrInternalServer("Invalid user information"))
@@ -42,17 +51,16 @@ func (h *AccountHandler) DeleteAccount(c *gin.Context) {
 	}
 
 	// Parse request body to get password confirmation
-	var req models.AccountDeleteRequest
+	var req DeleteAccountRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		h.logger.WithError(err).Error("Invalid account deletion request")
-		c.JSON(http.StatusBadRequest, errors.ErrInvalidRequest("Invalid request format"))
+		c.JSON(http.StatusBadRequest, errors.ErrBadRequest("Invalid request format"))
 		return
 	}
 
 	// Validate password and delete account
-	if err := h.userService.DeleteUser(userID, req.Password); err != nil {
-		if e, ok := err.(*errors.APIError); ok {
-			c.JSON(e.Status, e)
+	if err := h.accountService.DeleteUser(audit.RequestContext(c), userID, req.Password); err != nil {
+		if e, ok := err.(*errors.AppError); ok {
+			c.JSON(e.HTTPStatus(), e)
 			return
 		}
 		h.logger.WithError(err).WithField("user_id", userID).Error("Failed to delete account")
@@ -64,4 +72,4 @@ func (h *AccountHandler) DeleteAccount(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{
 		"message": "Account successfully deleted",
 	})
-}
\ No newline at end of file
+}
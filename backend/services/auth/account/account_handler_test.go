@@ -0,0 +1,91 @@
+package account
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	apperrors "github.com/yerenwgventures/GreatNigeriaLibrary-Foundation/backend/pkg/common/errors"
+	"github.com/yerenwgventures/GreatNigeriaLibrary-Foundation/backend/pkg/common/logger"
+)
+
+// MockAccountService is a mock implementation of the AccountService interface
+type MockAccountService struct {
+	mock.Mock
+}
+
+func (m *MockAccountService) DeleteUser(ctx context.Context, userID uint, password string) error {
+	args := m.Called(userID, password)
+	return args.Error(0)
+}
+
+func TestDeleteAccount(t *testing.T) {
+	testCases := []struct {
+		name           string
+		body           string
+		authenticated  bool
+		setupMock      func(m *MockAccountService)
+		expectedStatus int
+	}{
+		{
+			name:          "Successful deletion",
+			body:          `{"password":"password123"}`,
+			authenticated: true,
+			setupMock: func(m *MockAccountService) {
+				m.On("DeleteUser", testUserID, "password123").Return(nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:          "Invalid password",
+			body:          `{"password":"wrongpassword"}`,
+			authenticated: true,
+			setupMock: func(m *MockAccountService) {
+				m.On("DeleteUser", testUserID, "wrongpassword").Return(apperrors.ErrUnauthorizedAccess("Invalid password"))
+			},
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			name:          "Service failure",
+			body:          `{"password":"password123"}`,
+			authenticated: true,
+			setupMock: func(m *MockAccountService) {
+				m.On("DeleteUser", testUserID, "password123").Return(errors.New("database error"))
+			},
+			expectedStatus: http.StatusInternalServerError,
+		},
+		{
+			name:           "Missing password",
+			body:           `{}`,
+			authenticated:  true,
+			setupMock:      func(m *MockAccountService) {},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "Not signed in",
+			body:           `{"password":"password123"}`,
+			setupMock:      func(m *MockAccountService) {},
+			expectedStatus: http.StatusUnauthorized,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mockService := new(MockAccountService)
+			tc.setupMock(mockService)
+			handler := NewAccountHandler(mockService, logger.New(logger.FATAL))
+			router := newAccountRouter(func(router *gin.RouterGroup) {
+				router.DELETE("/delete", handler.DeleteAccount)
+			})
+
+			w := serve(router, http.MethodDelete, "/account/delete", tc.body, tc.authenticated)
+
+			assert.Equal(t, tc.expectedStatus, w.Code, w.Body.String())
+			mockService.AssertExpectations(t)
+		})
+	}
+}
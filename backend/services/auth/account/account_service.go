@@ -0,0 +1,73 @@
+package account
+
+import (
+	"context"
+
+	"github.com/yerenwgventures/GreatNigeriaLibrary-Foundation/backend/pkg/common/audit"
+	"github.com/yerenwgventures/GreatNigeriaLibrary-Foundation/backend/pkg/common/errors"
+	"github.com/yerenwgventures/GreatNigeriaLibrary-Foundation/backend/pkg/common/logger"
+	"github.com/yerenwgventures/GreatNigeriaLibrary-Foundation/backend/pkg/models"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// UserRepository loads and deletes user accounts
+type UserRepository interface {
+	GetByID(id uint) (*models.User, error)
+	DeleteUser(id uint) error
+}
+
+// Service implements account deletion
+type Service struct {
+	userRepo UserRepository
+	audit    *audit.Recorder
+	logger   *logger.Logger
+}
+
+// NewService creates a new account service
+func NewService(userRepo UserRepository, logger *logger.Logger) *Service {
+	return &Service{
+		userRepo: userRepo,
+		logger:   logger,
+	}
+}
+
+// SetAuditRecorder sets the recorder that account deletions are audited with
+func (s *Service) SetAuditRecorder(recorder *audit.Recorder) {
+	s.audit = recorder
+}
+
+// DeleteUser soft-deletes a user account once the password is confirmed
+func (s *Service) DeleteUser(ctx context.Context, userID uint, password string) error {
+	s.logger.WithField("user_id", userID).Info("Deleting user account")
+
+	// Verify password first as a security measure
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		s.logger.WithError(err).WithField("user_id", userID).Error("Failed to get user for password verification")
+		return errors.ErrInternalServer("Failed to verify password")
+	}
+	if user == nil {
+		return errors.ErrNotFound("User")
+	}
+	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(password)); err != nil {
+		s.logger.WithField("user_id", userID).Warn("Account deletion failed due to invalid password")
+		return errors.ErrUnauthorizedAccess("Invalid password")
+	}
+
+	if err := s.userRepo.DeleteUser(userID); err != nil {
+		s.logger.WithError(err).WithField("user_id", userID).Error("Failed to delete user account")
+		return errors.ErrInternalServer("Failed to delete account")
+	}
+
+	// The account is already gone, so a failure to record it is only logged
+	if err := s.audit.Record(ctx, audit.Event{
+		Action:     "user.deleted",
+		TargetType: "user",
+		TargetID:   userID,
+	}); err != nil {
+		s.logger.WithError(err).WithField("user_id", userID).Error("Failed to record audit event")
+	}
+
+	s.logger.WithField("user_id", userID).Info("User account deleted successfully")
+	return nil
+}
@@ -0,0 +1,135 @@
+package account
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"github.com/yerenwgventures/GreatNigeriaLibrary-Foundation/backend/pkg/common/audit"
+	apperrors "github.com/yerenwgventures/GreatNigeriaLibrary-Foundation/backend/pkg/common/errors"
+	"github.com/yerenwgventures/GreatNigeriaLibrary-Foundation/backend/pkg/common/logger"
+	"github.com/yerenwgventures/GreatNigeriaLibrary-Foundation/backend/pkg/models"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// MockUserRepository mocks the UserRepository interface
+type MockUserRepository struct {
+	mock.Mock
+}
+
+// GetByID mocks the GetByID method
+func (m *MockUserRepository) GetByID(id uint) (*models.User, error) {
+	args := m.Called(id)
+	user, _ := args.Get(0).(*models.User)
+	return user, args.Error(1)
+}
+
+// DeleteUser mocks the DeleteUser method
+func (m *MockUserRepository) DeleteUser(id uint) error {
+	args := m.Called(id)
+	return args.Error(0)
+}
+
+// TestDeleteUser tests the DeleteUser method
+func TestDeleteUser(t *testing.T) {
+	hash, err := bcrypt.GenerateFromPassword([]byte("validPassword123"), bcrypt.MinCost)
+	require.NoError(t, err)
+	user := func(id uint) *models.User {
+		u := &models.User{Password: string(hash)}
+		u.ID = id
+		return u
+	}
+
+	testCases := []struct {
+		name           string
+		userID         uint
+		password       string
+		setupMock      func(*MockUserRepository)
+		expectedStatus int
+		expectedErrMsg string
+	}{
+		{
+			name:     "Successful deletion",
+			userID:   1,
+			password: "validPassword123",
+			setupMock: func(m *MockUserRepository) {
+				m.On("GetByID", uint(1)).Return(user(1), nil)
+				m.On("DeleteUser", uint(1)).Return(nil)
+			},
+		},
+		{
+			name:     "Invalid password",
+			userID:   2,
+			password: "invalidPassword",
+			setupMock: func(m *MockUserRepository) {
+				m.On("GetByID", uint(2)).Return(user(2), nil)
+			},
+			expectedStatus: http.StatusUnauthorized,
+			expectedErrMsg: "Invalid password",
+		},
+		{
+			name:     "User not found",
+			userID:   3,
+			password: "password123",
+			setupMock: func(m *MockUserRepository) {
+				m.On("GetByID", uint(3)).Return(nil, nil)
+			},
+			expectedStatus: http.StatusNotFound,
+			expectedErrMsg: "User not found",
+		},
+		{
+			name:     "Database error during lookup",
+			userID:   4,
+			password: "validPassword123",
+			setupMock: func(m *MockUserRepository) {
+				m.On("GetByID", uint(4)).Return(nil, errors.New("database error"))
+			},
+			expectedStatus: http.StatusInternalServerError,
+			expectedErrMsg: "Failed to verify password",
+		},
+		{
+			name:     "Database error during deletion",
+			userID:   5,
+			password: "validPassword123",
+			setupMock: func(m *MockUserRepository) {
+				m.On("GetByID", uint(5)).Return(user(5), nil)
+				m.On("DeleteUser", uint(5)).Return(errors.New("database error"))
+			},
+			expectedStatus: http.StatusInternalServerError,
+			expectedErrMsg: "Failed to delete account",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mockRepo := new(MockUserRepository)
+			tc.setupMock(mockRepo)
+			store := audit.NewMemoryStore()
+			service := NewService(mockRepo, logger.New(logger.FATAL))
+			service.SetAuditRecorder(audit.NewRecorder(store, "auth"))
+
+			err := service.DeleteUser(context.Background(), tc.userID, tc.password)
+
+			entries, queryErr := store.Query(context.Background(), audit.Filter{})
+			require.NoError(t, queryErr)
+			if tc.expectedErrMsg == "" {
+				assert.NoError(t, err)
+				require.Len(t, entries, 1)
+				assert.Equal(t, "user.deleted", entries[0].Action)
+				assert.Equal(t, "1", entries[0].TargetID)
+			} else {
+				var appErr *apperrors.AppError
+				require.ErrorAs(t, err, &appErr)
+				assert.Equal(t, tc.expectedStatus, appErr.HTTPStatus())
+				assert.Equal(t, tc.expectedErrMsg, appErr.Message)
+				assert.Empty(t, entries, "nothing is deleted")
+			}
+
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
@@ -0,0 +1,108 @@
+// Package account serves the signed-in user's account endpoints under
+// /account. Its handlers read the user set by middleware.AuthRequired.
+package account
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/yerenwgventures/GreatNigeriaLibrary-Foundation/backend/pkg/common/auth"
+	"github.com/yerenwgventures/GreatNigeriaLibrary-Foundation/backend/pkg/common/errors"
+	"github.com/yerenwgventures/GreatNigeriaLibrary-Foundation/backend/pkg/common/logger"
+	"github.com/yerenwgventures/GreatNigeriaLibrary-Foundation/backend/pkg/models"
+)
+
+// IdentityService defines the operations on users' external OAuth identities
+type IdentityService interface {
+	OAuthProviders() []auth.OAuthProviderInfo
+	OAuthLinkURL(userID uint, provider string) (string, error)
+	ListIdentities(userID uint) ([]models.UserIdentity, error)
+	UnlinkIdentity(userID uint, provider string) error
+}
+
+// IdentityHandler handles linking and unlinking OAuth identities
+type IdentityHandler struct {
+	identityService IdentityService
+	logger          *logger.Logger
+}
+
+// NewIdentityHandler creates a new IdentityHandler
+func NewIdentityHandler(identityService IdentityService, logger *logger.Logger) *IdentityHandler {
+	return &IdentityHandler{
+		identityService: identityService,
+		logger:          logger,
+	}
+}
+
+// RegisterRoutes registers the linked identity routes on the account group,
+// which must require authentication
+func (h *IdentityHandler) RegisterRoutes(router *gin.RouterGroup) {
+	router.GET("/identities", h.GetIdentities)
+	router.POST("/identities/:provider", h.LinkIdentity)
+	router.DELETE("/identities/:provider", h.UnlinkIdentity)
+}
+
+// GetProviders lists the OAuth providers users can sign in with
+func (h *IdentityHandler) GetProviders(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"providers": h.identityService.OAuthProviders()})
+}
+
+// GetIdentities lists the identities linked to the signed-in user
+func (h *IdentityHandler) GetIdentities(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	identities, err := h.identityService.ListIdentities(userID.(uint))
+	if err != nil {
+		h.respondError(c, err, "Failed to list linked accounts")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"identities": identities})
+}
+
+// LinkIdentity returns the URL that links an identity at a provider to the
+// signed-in user. The provider redirects back to the OAuth callback.
+func (h *IdentityHandler) LinkIdentity(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	url, err := h.identityService.OAuthLinkURL(userID.(uint), c.Param("provider"))
+	if err != nil {
+		h.respondError(c, err, "Failed to generate OAuth URL")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"url": url})
+}
+
+// UnlinkIdentity unlinks the signed-in user's identity at a provider
+func (h *IdentityHandler) UnlinkIdentity(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	if err := h.identityService.UnlinkIdentity(userID.(uint), c.Param("provider")); err != nil {
+		h.respondError(c, err, "Failed to unlink account")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Account unlinked"})
+}
+
+func (h *IdentityHandler) respondError(c *gin.Context, err error, message string) {
+	if e, ok := err.(*errors.AppError); ok {
+		c.JSON(e.HTTPStatus(), e)
+		return
+	}
+	h.logger.WithError(err).Error(message)
+	c.JSON(http.StatusInternalServerError, errors.ErrInternalServer(message))
+}
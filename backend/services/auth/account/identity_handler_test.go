@@ -0,0 +1,136 @@
+package account
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/yerenwgventures/GreatNigeriaLibrary-Foundation/backend/pkg/common/auth"
+	"github.com/yerenwgventures/GreatNigeriaLibrary-Foundation/backend/pkg/common/logger"
+	"github.com/yerenwgventures/GreatNigeriaLibrary-Foundation/backend/pkg/common/middleware"
+	"github.com/yerenwgventures/GreatNigeriaLibrary-Foundation/backend/pkg/models"
+)
+
+// testToken authenticates as testUserID
+const (
+	testToken  = "access-token"
+	testUserID = uint(42)
+)
+
+// fakeTokens accepts testToken only
+type fakeTokens struct{}
+
+func (fakeTokens) ValidateToken(token string) (*middleware.Claims, error) {
+	if token != testToken {
+		return nil, errors.New("invalid token")
+	}
+	return &middleware.Claims{UserID: testUserID, Username: "ada", TokenType: "access"}, nil
+}
+
+func (fakeTokens) ExtractUserID(token string) (uint, error) { return testUserID, nil }
+
+func (fakeTokens) IsTokenRevoked(token string) bool { return false }
+
+// nopLogger discards the middleware's log output
+type nopLogger struct{}
+
+func (nopLogger) Info(msg string)  {}
+func (nopLogger) Error(msg string) {}
+
+func (l nopLogger) WithField(key string, value interface{}) middleware.Logger { return l }
+
+func (l nopLogger) WithFields(fields map[string]interface{}) middleware.Logger { return l }
+
+// newAccountRouter mounts routes on an /account group behind the real
+// AuthRequired middleware, the way the auth service main does
+func newAccountRouter(register func(router *gin.RouterGroup)) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(middleware.ErrorHandler(nopLogger{}))
+	register(router.Group("/account", middleware.AuthRequired(fakeTokens{}, nopLogger{})))
+	return router
+}
+
+// serve sends a request with an optional JSON body, authenticated with
+// testToken when authenticated is set
+func serve(router *gin.Engine, method, target, body string, authenticated bool) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, target, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	if authenticated {
+		req.Header.Set("Authorization", "Bearer "+testToken)
+	}
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+// fakeIdentityService records the user each call was made for
+type fakeIdentityService struct {
+	userIDs  []uint
+	provider string
+}
+
+func (s *fakeIdentityService) OAuthProviders() []auth.OAuthProviderInfo { return nil }
+
+func (s *fakeIdentityService) OAuthLinkURL(userID uint, provider string) (string, error) {
+	s.userIDs = append(s.userIDs, userID)
+	s.provider = provider
+	return "https://accounts.example.com/authorize?state=link", nil
+}
+
+func (s *fakeIdentityService) ListIdentities(userID uint) ([]models.UserIdentity, error) {
+	s.userIDs = append(s.userIDs, userID)
+	return []models.UserIdentity{{UserID: userID, Provider: "google"}}, nil
+}
+
+func (s *fakeIdentityService) UnlinkIdentity(userID uint, provider string) error {
+	s.userIDs = append(s.userIDs, userID)
+	s.provider = provider
+	return nil
+}
+
+func TestIdentityRoutesUseTheAuthenticatedUser(t *testing.T) {
+	identities := &fakeIdentityService{}
+	router := newAccountRouter(NewIdentityHandler(identities, logger.New(logger.FATAL)).RegisterRoutes)
+
+	w := serve(router, http.MethodGet, "/account/identities", "", true)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var listed struct {
+		Identities []models.UserIdentity `json:"identities"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &listed))
+	require.Len(t, listed.Identities, 1)
+	assert.Equal(t, testUserID, listed.Identities[0].UserID)
+
+	w = serve(router, http.MethodPost, "/account/identities/github", "", true)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.Contains(t, w.Body.String(), "accounts.example.com")
+	assert.Equal(t, "github", identities.provider)
+
+	w = serve(router, http.MethodDelete, "/account/identities/google", "", true)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.Equal(t, "google", identities.provider)
+
+	assert.Equal(t, []uint{testUserID, testUserID, testUserID}, identities.userIDs)
+}
+
+func TestIdentityRoutesRequireAuthentication(t *testing.T) {
+	identities := &fakeIdentityService{}
+	router := newAccountRouter(NewIdentityHandler(identities, logger.New(logger.FATAL)).RegisterRoutes)
+
+	for _, route := range []struct{ method, target string }{
+		{http.MethodGet, "/account/identities"},
+		{http.MethodPost, "/account/identities/github"},
+		{http.MethodDelete, "/account/identities/google"},
+	} {
+		w := serve(router, route.method, route.target, "", false)
+		assert.Equal(t, http.StatusUnauthorized, w.Code, route.target)
+	}
+	assert.Empty(t, identities.userIDs)
+}
@@ -0,0 +1,218 @@
+package account
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-webauthn/webauthn/protocol"
+	"github.com/yerenwgventures/GreatNigeriaLibrary-Foundation/backend/pkg/common/errors"
+	"github.com/yerenwgventures/GreatNigeriaLibrary-Foundation/backend/pkg/common/logger"
+	"github.com/yerenwgventures/GreatNigeriaLibrary-Foundation/backend/pkg/models"
+)
+
+// WebAuthnService defines the passkey operations the handler needs, see
+// service.WebAuthnService
+type WebAuthnService interface {
+	BeginRegistration(userID uint) (string, *protocol.CredentialCreation, error)
+	FinishRegistration(userID uint, req *models.WebAuthnRegisterRequest) (*models.WebAuthnCredential, error)
+	BeginVerification(userID uint) (string, *protocol.CredentialAssertion, error)
+	FinishVerification(userID uint, req *models.WebAuthnAssertionRequest) error
+	BeginLogin() (string, *protocol.CredentialAssertion, error)
+	FinishLogin(req *models.WebAuthnAssertionRequest) (*models.UserResponse, *models.TokenPair, error)
+	ListCredentials(userID uint) ([]models.WebAuthnCredential, error)
+	RevokeCredential(userID, id uint) error
+}
+
+// WebAuthnHandler handles passkey and security key requests. Begin endpoints
+// return a ceremony ID and the options for navigator.credentials; finish
+// endpoints take the ceremony ID and the credential the browser returned.
+type WebAuthnHandler struct {
+	webAuthnService WebAuthnService
+	logger          *logger.Logger
+}
+
+// NewWebAuthnHandler creates a new WebAuthnHandler instance
+func NewWebAuthnHandler(webAuthnService WebAuthnService, logger *logger.Logger) *WebAuthnHandler {
+	return &WebAuthnHandler{
+		webAuthnService: webAuthnService,
+		logger:          logger,
+	}
+}
+
+// RegisterRoutes registers the passkey management and second factor routes
+// on the account group, which must require authentication
+func (h *WebAuthnHandler) RegisterRoutes(router *gin.RouterGroup) {
+	router.GET("/2fa/webauthn/credentials", h.GetCredentials)
+	router.DELETE("/2fa/webauthn/credentials/:id", h.RevokeCredential)
+	router.POST("/2fa/webauthn/register/begin", h.BeginRegistration)
+	router.POST("/2fa/webauthn/register/finish", h.FinishRegistration)
+	router.POST("/2fa/webauthn/verify/begin", h.BeginVerification)
+	router.POST("/2fa/webauthn/verify/finish", h.FinishVerification)
+}
+
+// RegisterLoginRoutes registers the passwordless login routes on the public
+// auth group
+func (h *WebAuthnHandler) RegisterLoginRoutes(router *gin.RouterGroup) {
+	router.POST("/webauthn/login/begin", h.BeginLogin)
+	router.POST("/webauthn/login/finish", h.FinishLogin)
+}
+
+// BeginRegistration starts registering a passkey for the signed-in user
+func (h *WebAuthnHandler) BeginRegistration(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	ceremonyID, options, err := h.webAuthnService.BeginRegistration(userID.(uint))
+	if err != nil {
+		h.respondError(c, err, "Failed to begin passkey registration")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"ceremony_id": ceremonyID, "options": options})
+}
+
+// FinishRegistration stores the passkey the authenticator created
+func (h *WebAuthnHandler) FinishRegistration(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	var req models.WebAuthnRegisterRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, errors.ErrBadRequest("Invalid request"))
+		return
+	}
+
+	credential, err := h.webAuthnService.FinishRegistration(userID.(uint), &req)
+	if err != nil {
+		h.respondError(c, err, "Failed to register passkey")
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"credential": credential})
+}
+
+// BeginVerification starts a second factor check with the signed-in user's
+// passkeys, the WebAuthn counterpart of /2fa/verify
+func (h *WebAuthnHandler) BeginVerification(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	ceremonyID, options, err := h.webAuthnService.BeginVerification(userID.(uint))
+	if err != nil {
+		h.respondError(c, err, "Failed to begin passkey verification")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"ceremony_id": ceremonyID, "options": options})
+}
+
+// FinishVerification verifies a second factor assertion
+func (h *WebAuthnHandler) FinishVerification(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	var req models.WebAuthnAssertionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, errors.ErrBadRequest("Invalid request"))
+		return
+	}
+
+	if err := h.webAuthnService.FinishVerification(userID.(uint), &req); err != nil {
+		h.respondError(c, err, "Failed to verify passkey")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Passkey verified successfully", "valid": true})
+}
+
+// BeginLogin starts a passwordless login with a passkey
+func (h *WebAuthnHandler) BeginLogin(c *gin.Context) {
+	ceremonyID, options, err := h.webAuthnService.BeginLogin()
+	if err != nil {
+		h.respondError(c, err, "Failed to begin passkey login")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"ceremony_id": ceremonyID, "options": options})
+}
+
+// FinishLogin signs in the user whose passkey signed the assertion
+func (h *WebAuthnHandler) FinishLogin(c *gin.Context) {
+	var req models.WebAuthnAssertionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, errors.ErrBadRequest("Invalid request"))
+		return
+	}
+
+	user, tokens, err := h.webAuthnService.FinishLogin(&req)
+	if err != nil {
+		h.respondError(c, err, "Failed to sign in with passkey")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"user":   user,
+		"tokens": tokens,
+	})
+}
+
+// GetCredentials lists the signed-in user's passkeys
+func (h *WebAuthnHandler) GetCredentials(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	credentials, err := h.webAuthnService.ListCredentials(userID.(uint))
+	if err != nil {
+		h.respondError(c, err, "Failed to list passkeys")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"credentials": credentials})
+}
+
+// RevokeCredential revokes one of the signed-in user's passkeys
+func (h *WebAuthnHandler) RevokeCredential(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, errors.ErrBadRequest("Invalid passkey ID"))
+		return
+	}
+
+	if err := h.webAuthnService.RevokeCredential(userID.(uint), uint(id)); err != nil {
+		h.respondError(c, err, "Failed to revoke passkey")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Passkey revoked"})
+}
+
+func (h *WebAuthnHandler) respondError(c *gin.Context, err error, message string) {
+	if e, ok := err.(*errors.AppError); ok {
+		c.JSON(e.HTTPStatus(), e)
+		return
+	}
+	h.logger.WithError(err).Error(message)
+	c.JSON(http.StatusInternalServerError, errors.ErrInternalServer(message))
+}
@@ -0,0 +1,130 @@
+package account
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-webauthn/webauthn/protocol"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/yerenwgventures/GreatNigeriaLibrary-Foundation/backend/pkg/common/logger"
+	"github.com/yerenwgventures/GreatNigeriaLibrary-Foundation/backend/pkg/models"
+)
+
+// fakeWebAuthnService records the user each call was made for
+type fakeWebAuthnService struct {
+	calls   []string
+	userIDs []uint
+}
+
+func (s *fakeWebAuthnService) record(call string, userID uint) {
+	s.calls = append(s.calls, call)
+	s.userIDs = append(s.userIDs, userID)
+}
+
+func (s *fakeWebAuthnService) BeginRegistration(userID uint) (string, *protocol.CredentialCreation, error) {
+	s.record("BeginRegistration", userID)
+	return "ceremony", &protocol.CredentialCreation{}, nil
+}
+
+func (s *fakeWebAuthnService) FinishRegistration(userID uint, req *models.WebAuthnRegisterRequest) (*models.WebAuthnCredential, error) {
+	s.record("FinishRegistration", userID)
+	return &models.WebAuthnCredential{ID: 1, UserID: userID, Name: req.Name}, nil
+}
+
+func (s *fakeWebAuthnService) BeginVerification(userID uint) (string, *protocol.CredentialAssertion, error) {
+	s.record("BeginVerification", userID)
+	return "ceremony", &protocol.CredentialAssertion{}, nil
+}
+
+func (s *fakeWebAuthnService) FinishVerification(userID uint, req *models.WebAuthnAssertionRequest) error {
+	s.record("FinishVerification", userID)
+	return nil
+}
+
+func (s *fakeWebAuthnService) BeginLogin() (string, *protocol.CredentialAssertion, error) {
+	s.record("BeginLogin", 0)
+	return "ceremony", &protocol.CredentialAssertion{}, nil
+}
+
+func (s *fakeWebAuthnService) FinishLogin(req *models.WebAuthnAssertionRequest) (*models.UserResponse, *models.TokenPair, error) {
+	s.record("FinishLogin", 0)
+	return &models.UserResponse{ID: testUserID}, &models.TokenPair{}, nil
+}
+
+func (s *fakeWebAuthnService) ListCredentials(userID uint) ([]models.WebAuthnCredential, error) {
+	s.record("ListCredentials", userID)
+	return nil, nil
+}
+
+func (s *fakeWebAuthnService) RevokeCredential(userID, id uint) error {
+	s.record("RevokeCredential", userID)
+	return nil
+}
+
+// webAuthnRoutes are the account routes with the status each returns
+var webAuthnRoutes = []struct {
+	method, target, body string
+	status               int
+}{
+	{http.MethodGet, "/account/2fa/webauthn/credentials", "", http.StatusOK},
+	{http.MethodDelete, "/account/2fa/webauthn/credentials/3", "", http.StatusOK},
+	{http.MethodPost, "/account/2fa/webauthn/register/begin", "", http.StatusOK},
+	{http.MethodPost, "/account/2fa/webauthn/register/finish", `{"ceremony_id":"ceremony","name":"Laptop","credential":{}}`, http.StatusCreated},
+	{http.MethodPost, "/account/2fa/webauthn/verify/begin", "", http.StatusOK},
+	{http.MethodPost, "/account/2fa/webauthn/verify/finish", `{"ceremony_id":"ceremony","credential":{}}`, http.StatusOK},
+}
+
+func newWebAuthnRouter(passkeys *fakeWebAuthnService) *gin.Engine {
+	handler := NewWebAuthnHandler(passkeys, logger.New(logger.FATAL))
+	router := newAccountRouter(handler.RegisterRoutes)
+	handler.RegisterLoginRoutes(router.Group("/auth"))
+	return router
+}
+
+func TestWebAuthnRoutesUseTheAuthenticatedUser(t *testing.T) {
+	passkeys := &fakeWebAuthnService{}
+	router := newWebAuthnRouter(passkeys)
+
+	for _, route := range webAuthnRoutes {
+		w := serve(router, route.method, route.target, route.body, true)
+		assert.Equal(t, route.status, w.Code, route.target+": "+w.Body.String())
+	}
+
+	assert.Equal(t, []string{
+		"ListCredentials",
+		"RevokeCredential",
+		"BeginRegistration",
+		"FinishRegistration",
+		"BeginVerification",
+		"FinishVerification",
+	}, passkeys.calls)
+	for _, userID := range passkeys.userIDs {
+		assert.Equal(t, testUserID, userID)
+	}
+}
+
+func TestWebAuthnRoutesRequireAuthentication(t *testing.T) {
+	passkeys := &fakeWebAuthnService{}
+	router := newWebAuthnRouter(passkeys)
+
+	for _, route := range webAuthnRoutes {
+		w := serve(router, route.method, route.target, route.body, false)
+		assert.Equal(t, http.StatusUnauthorized, w.Code, route.target)
+	}
+	assert.Empty(t, passkeys.calls)
+}
+
+func TestWebAuthnLoginIsPublic(t *testing.T) {
+	passkeys := &fakeWebAuthnService{}
+	router := newWebAuthnRouter(passkeys)
+
+	w := serve(router, http.MethodPost, "/auth/webauthn/login/begin", "", false)
+	assert.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	w = serve(router, http.MethodPost, "/auth/webauthn/login/finish", `{"ceremony_id":"ceremony","credential":{}}`, false)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.Contains(t, w.Body.String(), `"tokens"`)
+
+	assert.Equal(t, []string{"BeginLogin", "FinishLogin"}, passkeys.calls)
+}
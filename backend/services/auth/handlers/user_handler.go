@@ -1,11 +1,13 @@
 package handlers
 
 import (
+        "context"
         "net/http"
         "strconv"
         "time"
 
         "github.com/gin-gonic/gin"
+        "github.com/yerenwgventures/GreatNigeriaLibrary-Foundation/backend/pkg/common/audit"
         "github.com/yerenwgventures/GreatNigeriaLibrary-Foundation/backend/pkg/common/errors"
         "github.com/yerenwgventures/GreatNigeriaLibrary-Foundation/backend/pkg/common/logger"
         "github.com/yerenwgventures/GreatNigeriaLibrary-Foundation/backend/pkg/models"
@@ -41,7 +43,7 @@ type UserService interface {
         
         // OAuth operations
         OAuthLoginURL(provider string) (string, error)
-        OAuthCallback(provider, code string) (*models.UserResponse, *models.TokenPair, error)
+        OAuthCallback(provider, state, code string) (*models.UserResponse, *models.TokenPair, error)
         
         // Email verification methods
         SendEmailVerification(email string) error
@@ -49,11 +51,8 @@ type UserService interface {
         ResendVerificationEmail(email string) error
         
         // User role operations
-        UpdateUserRole(userID uint, role int) error
+        UpdateUserRole(ctx context.Context, userID uint, role int) error
         GetUsersByRole(role int, page, pageSize int) ([]models.UserResponse, int64, error)
-        
-        // Account management
-        DeleteUser(userID uint, password string) error
 }
 
 // NewUserHandler creates a new user handler
@@ -349,8 +348,8 @@ func (h *UserHandler) OAuthLogin(c *gin.Context) {
         
         url, err := h.userService.OAuthLoginURL(provider)
         if err != nil {
-                if e, ok := err.(*errors.APIError); ok {
-                        c.JSON(e.Status, e)
+                if e, ok := err.(*errors.AppError); ok {
+                        c.JSON(e.HTTPStatus(), e)
                         return
                 }
                 h.logger.WithError(err).Error("Failed to generate OAuth URL")
@@ -363,20 +362,26 @@ func (h *UserHandler) OAuthLogin(c *gin.Context) {
         })
 }
 
-// OAuthCallback handles OAuth callback
+// OAuthCallback handles OAuth callback. It signs the user in, or links the
+// identity when the flow was started from the account settings.
 func (h *UserHandler) OAuthCallback(c *gin.Context) {
         provider := c.Param("provider")
         code := c.Query("code")
-        
-        if code == "" {
-                c.JSON(http.StatusBadRequest, errors.ErrInvalidRequest("Authorization code is missing"))
+        state := c.Query("state")
+
+        if providerError := c.Query("error"); providerError != "" {
+                c.JSON(http.StatusBadRequest, errors.ErrBadRequest("Sign-in was cancelled or refused: "+providerError))
+                return
+        }
+        if code == "" || state == "" {
+                c.JSON(http.StatusBadRequest, errors.ErrBadRequest("Authorization code or state is missing"))
                 return
         }
 
-        user, tokens, err := h.userService.OAuthCallback(provider, code)
+        user, tokens, err := h.userService.OAuthCallback(provider, state, code)
         if err != nil {
-                if e, ok := err.(*errors.APIError); ok {
-                        c.JSON(e.Status, e)
+                if e, ok := err.(*errors.AppError); ok {
+                        c.JSON(e.HTTPStatus(), e)
                         return
                 }
                 h.logger.WithError(err).Error("Failed to process OAuth callback")
@@ -384,6 +389,14 @@ func (h *UserHandler) OAuthCallback(c *gin.Context) {
                 return
         }
 
+        if tokens == nil {
+                c.JSON(http.StatusOK, gin.H{
+                        "user":    user,
+                        "message": "Account linked",
+                })
+                return
+        }
+
         c.JSON(http.StatusOK, gin.H{
                 "user":   user,
                 "tokens": tokens,
@@ -531,7 +544,7 @@ func (h *UserHandler) UpdateUserRole(c *gin.Context) {
         }
         
         // Update user role
-        err = h.userService.UpdateUserRole(uint(id), req.Role)
+        err = h.userService.UpdateUserRole(audit.RequestContext(c), uint(id), req.Role)
         if err != nil {
                 if e, ok := err.(*errors.APIError); ok {
                         c.JSON(e.Status, e)
@@ -0,0 +1,86 @@
+package repository
+
+import (
+	"errors"
+	"time"
+
+	"github.com/yerenwgventures/GreatNigeriaLibrary-Foundation/backend/pkg/common/logger"
+	"github.com/yerenwgventures/GreatNigeriaLibrary-Foundation/backend/pkg/models"
+	"gorm.io/gorm"
+)
+
+// ErrIdentityNotFound is returned when a user has no identity at a provider
+var ErrIdentityNotFound = errors.New("identity not found")
+
+// IdentityRepository defines methods for the external identities of users
+type IdentityRepository interface {
+	GetIdentity(provider, subject string) (*models.UserIdentity, error)
+	ListIdentities(userID uint) ([]models.UserIdentity, error)
+	CreateIdentity(identity *models.UserIdentity) error
+	UpdateIdentityLogin(id uint, email, name string) error
+	DeleteIdentity(userID uint, provider string) error
+}
+
+// IdentityRepositoryImpl implements IdentityRepository interface
+type IdentityRepositoryImpl struct {
+	db     *gorm.DB
+	logger *logger.Logger
+}
+
+// NewIdentityRepository creates a new IdentityRepository instance
+func NewIdentityRepository(db *gorm.DB, logger *logger.Logger) IdentityRepository {
+	return &IdentityRepositoryImpl{
+		db:     db,
+		logger: logger,
+	}
+}
+
+// GetIdentity gets the identity with a provider's user ID, or nil when the
+// provider account is not linked to any user
+func (r *IdentityRepositoryImpl) GetIdentity(provider, subject string) (*models.UserIdentity, error) {
+	var identity models.UserIdentity
+	err := r.db.Where("provider = ? AND subject = ?", provider, subject).First(&identity).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &identity, nil
+}
+
+// ListIdentities lists a user's identities, oldest first
+func (r *IdentityRepositoryImpl) ListIdentities(userID uint) ([]models.UserIdentity, error) {
+	var identities []models.UserIdentity
+	err := r.db.Where("user_id = ?", userID).Order("created_at, id").Find(&identities).Error
+	return identities, err
+}
+
+// CreateIdentity links an identity to a user
+func (r *IdentityRepositoryImpl) CreateIdentity(identity *models.UserIdentity) error {
+	return r.db.Create(identity).Error
+}
+
+// UpdateIdentityLogin records a login with an identity and the email and
+// name the provider returned
+func (r *IdentityRepositoryImpl) UpdateIdentityLogin(id uint, email, name string) error {
+	return r.db.Model(&models.UserIdentity{}).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{
+			"email":         email,
+			"name":          name,
+			"last_login_at": time.Now(),
+		}).Error
+}
+
+// DeleteIdentity unlinks a user's identity at a provider
+func (r *IdentityRepositoryImpl) DeleteIdentity(userID uint, provider string) error {
+	result := r.db.Where("user_id = ? AND provider = ?", userID, provider).Delete(&models.UserIdentity{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrIdentityNotFound
+	}
+	return nil
+}
@@ -352,8 +352,11 @@ func (r *UserRepository) DeleteUser(id uint) error {
                         return err
                 }
                 
-                // Could add additional cleanup operations here if needed in the future
-                // For example, cascade soft-delete user's comments, posts, etc.
+                // Unlink external identities, so the provider accounts can sign up again
+                if err := tx.Where("user_id = ?", id).Delete(&models.UserIdentity{}).Error; err != nil {
+                        r.logger.WithError(err).WithField("user_id", id).Error("Error unlinking user identities")
+                        return err
+                }
                 
                 return nil
         })
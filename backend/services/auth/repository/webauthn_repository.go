@@ -0,0 +1,86 @@
+package repository
+
+import (
+	"errors"
+	"time"
+
+	"github.com/yerenwgventures/GreatNigeriaLibrary-Foundation/backend/pkg/common/logger"
+	"github.com/yerenwgventures/GreatNigeriaLibrary-Foundation/backend/pkg/models"
+	"gorm.io/gorm"
+)
+
+var (
+	// ErrWebAuthnCredentialNotFound is returned when a user has no credential
+	// with an ID
+	ErrWebAuthnCredentialNotFound = errors.New("WebAuthn credential not found")
+	// ErrStaleSignCount is returned when another login with a credential
+	// already stored the same or a higher signature counter
+	ErrStaleSignCount = errors.New("WebAuthn signature counter is stale")
+)
+
+// WebAuthnRepository defines methods for users' WebAuthn credentials
+type WebAuthnRepository interface {
+	ListCredentials(userID uint) ([]models.WebAuthnCredential, error)
+	CreateCredential(credential *models.WebAuthnCredential) error
+	UpdateCredentialUse(id uint, signCount uint32, backupState bool) error
+	DeleteCredential(userID, id uint) error
+}
+
+// WebAuthnRepositoryImpl implements WebAuthnRepository interface
+type WebAuthnRepositoryImpl struct {
+	db     *gorm.DB
+	logger *logger.Logger
+}
+
+// NewWebAuthnRepository creates a new WebAuthnRepository instance
+func NewWebAuthnRepository(db *gorm.DB, logger *logger.Logger) WebAuthnRepository {
+	return &WebAuthnRepositoryImpl{
+		db:     db,
+		logger: logger,
+	}
+}
+
+// ListCredentials lists a user's credentials, oldest first
+func (r *WebAuthnRepositoryImpl) ListCredentials(userID uint) ([]models.WebAuthnCredential, error) {
+	var credentials []models.WebAuthnCredential
+	err := r.db.Where("user_id = ?", userID).Order("created_at, id").Find(&credentials).Error
+	return credentials, err
+}
+
+// CreateCredential stores a newly registered credential
+func (r *WebAuthnRepositoryImpl) CreateCredential(credential *models.WebAuthnCredential) error {
+	return r.db.Create(credential).Error
+}
+
+// UpdateCredentialUse records a login with a credential and its new
+// signature counter. The counter only moves forward, so of two logins that
+// replay the same assertion concurrently only one succeeds. Authenticators
+// that do not count always report zero.
+func (r *WebAuthnRepositoryImpl) UpdateCredentialUse(id uint, signCount uint32, backupState bool) error {
+	result := r.db.Model(&models.WebAuthnCredential{}).
+		Where("id = ? AND (sign_count < ? OR ? = 0)", id, signCount, signCount).
+		Updates(map[string]interface{}{
+			"sign_count":   signCount,
+			"backup_state": backupState,
+			"last_used_at": time.Now(),
+		})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrStaleSignCount
+	}
+	return nil
+}
+
+// DeleteCredential revokes one of a user's credentials
+func (r *WebAuthnRepositoryImpl) DeleteCredential(userID, id uint) error {
+	result := r.db.Where("user_id = ? AND id = ?", userID, id).Delete(&models.WebAuthnCredential{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrWebAuthnCredentialNotFound
+	}
+	return nil
+}
@@ -1,18 +1,21 @@
 package service
 
 import (
+	"context"
 	"fmt"
-	"net/http"
 	"strings"
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/yerenwgventures/GreatNigeriaLibrary-Foundation/backend/pkg/common/audit"
 	"github.com/yerenwgventures/GreatNigeriaLibrary-Foundation/backend/pkg/common/auth"
 	"github.com/yerenwgventures/GreatNigeriaLibrary-Foundation/backend/pkg/common/config"
 	"github.com/yerenwgventures/GreatNigeriaLibrary-Foundation/backend/pkg/common/errors"
 	"github.com/yerenwgventures/GreatNigeriaLibrary-Foundation/backend/pkg/common/logger"
+	"github.com/yerenwgventures/GreatNigeriaLibrary-Foundation/backend/pkg/common/mailer"
 	"github.com/yerenwgventures/GreatNigeriaLibrary-Foundation/backend/pkg/common/redis"
 	"github.com/yerenwgventures/GreatNigeriaLibrary-Foundation/backend/pkg/models"
+	"github.com/yerenwgventures/GreatNigeriaLibrary-Foundation/backend/services/auth/repository"
 	"golang.org/x/crypto/bcrypt"
 )
 
@@ -23,6 +26,11 @@ type UserService struct {
 	sessionService *SessionService   // Added session service
 	jwtManager     *auth.JWTManager
 	oauthManager   *auth.OAuthManager
+	webAuthn       *auth.WebAuthnManager
+	identityRepo   repository.IdentityRepository
+	mailer         mailer.Mailer
+	emailTemplates *mailer.Templates
+	audit          *audit.Recorder
 	logger         *logger.Logger
 	config         *config.Config
 }
@@ -115,15 +123,38 @@ func NewUserService(userRepo UserRepository, logger *logger.Logger) *UserService
 		)
 	}
 
-	// Create OAuth manager
-	oauthManager := auth.NewOAuthManager(logger, cfg)
+	// Create OAuth and WebAuthn managers. OAuth login states and WebAuthn
+	// challenges are kept in Redis, so the answer to a challenge can reach any
+	// replica.
+	var challenges auth.ChallengeStore
+	if redisClient != nil {
+		challenges = auth.NewRedisChallengeStore(redisClient.Client)
+	} else {
+		logger.Warn("Redis is not available, OAuth and passkey logins will only work with a single instance")
+		challenges = auth.NewMemoryChallengeStore()
+	}
+	oauthManager, err := auth.NewOAuthManager(cfg.OAuth, challenges)
+	if err != nil {
+		logger.Fatal("Failed to configure OAuth providers: " + err.Error())
+	}
+	webAuthnManager, err := auth.NewWebAuthnManager(cfg.WebAuthn, challenges)
+	if err != nil {
+		logger.Fatal("Failed to configure WebAuthn: " + err.Error())
+	}
+
+	emailTemplates, err := mailer.NewTemplates(cfg.Email.FromName)
+	if err != nil {
+		logger.Fatal("Failed to load email templates: " + err.Error())
+	}
 
 	return &UserService{
-		userRepo:     userRepo,
-		jwtManager:   jwtManager,
-		oauthManager: oauthManager,
-		logger:       logger,
-		config:       cfg,
+		userRepo:       userRepo,
+		jwtManager:     jwtManager,
+		oauthManager:   oauthManager,
+		webAuthn:       webAuthnManager,
+		emailTemplates: emailTemplates,
+		logger:         logger,
+		config:         cfg,
 	}
 }
 
@@ -138,6 +169,38 @@ func (s *UserService) SetSessionRepository(sessionRepo SessionRepository) {
 	s.sessionRepo = sessionRepo
 }
 
+// SetIdentityRepository sets the repository of users' external identities
+func (s *UserService) SetIdentityRepository(identityRepo repository.IdentityRepository) {
+	s.identityRepo = identityRepo
+}
+
+// SetMailer sets the mailer used for password reset and verification emails.
+// Without a mailer the links are only logged, which is useful in development.
+func (s *UserService) SetMailer(m mailer.Mailer) {
+	s.mailer = m
+}
+
+// SetAuditRecorder sets the recorder of role changes and account deletions
+func (s *UserService) SetAuditRecorder(recorder *audit.Recorder) {
+	s.audit = recorder
+}
+
+// recordAudit records an event in the audit log. The action already happened,
+// so a failure to record it is only logged.
+func (s *UserService) recordAudit(ctx context.Context, event audit.Event) {
+	if err := s.audit.Record(ctx, event); err != nil {
+		s.logger.WithError(err).WithField("action", event.Action).Error("Failed to record audit event")
+	}
+}
+
+// sendEmail renders an email template and sends it to user
+func (s *UserService) sendEmail(user *models.User, template string, data interface{}) error {
+	if s.mailer == nil {
+		return fmt.Errorf("no mailer configured")
+	}
+	return mailer.SendTemplate(context.Background(), s.mailer, s.emailTemplates, user.Email, template, data)
+}
+
 // Register registers a new user
 func (s *UserService) Register(req *models.UserRegisterRequest) (*models.UserResponse, *models.TokenPair, error) {
 	s.logger.WithField("email", req.Email).Info("Registering new user")
@@ -731,7 +794,8 @@ func (s *UserService) ResetPassword(email string) error {
 	resetToken := uuid.New().String()
 
 	// Set expiration time (e.g., 24 hours from now)
-	expiresAt := time.Now().Add(24 * time.Hour)
+	expiresIn := 24 * time.Hour
+	expiresAt := time.Now().Add(expiresIn)
 
 	// Store the token in the database
 	err = s.userRepo.CreatePasswordResetToken(user.ID, resetToken, expiresAt)
@@ -743,16 +807,27 @@ func (s *UserService) ResetPassword(email string) error {
 	// Generate reset link
 	resetLink := fmt.Sprintf("%s/reset-password?token=%s", s.config.App.FrontendURL, resetToken)
 
-	// In a production environment, send an email with the reset link
-	// For now, just log it
-	s.logger.WithFields(map[string]interface{}{
-		"email":       email,
-		"user_id":     user.ID,
-		"reset_token": resetToken,
-		"reset_link":  resetLink,
-	}).Info("Password reset token generated and ready to be sent")
+	if s.mailer == nil {
+		// No mailer in development, log the link so the flow can be completed by hand
+		s.logger.WithFields(map[string]interface{}{
+			"email":      email,
+			"user_id":    user.ID,
+			"reset_link": resetLink,
+		}).Info("No mailer configured, password reset email not sent")
+		return nil
+	}
+
+	err = s.sendEmail(user, mailer.TemplatePasswordReset, mailer.PasswordResetData{
+		Name:      user.FullName,
+		ResetURL:  resetLink,
+		ExpiresIn: expiresIn,
+	})
+	if err != nil {
+		s.logger.WithError(err).WithField("user_id", user.ID).Error("Failed to send password reset email")
+		return errors.ErrInternalServer("Failed to send password reset email")
+	}
 
-	// TODO: Implement email sending functionality
+	s.logger.WithField("user_id", user.ID).Info("Password reset email sent")
 
 	return nil
 }
@@ -829,7 +904,8 @@ func (s *UserService) SendEmailVerification(email string) error {
 	verificationToken := uuid.New().String()
 
 	// Set expiration time (e.g., 48 hours from now)
-	expiresAt := time.Now().Add(48 * time.Hour)
+	expiresIn := 48 * time.Hour
+	expiresAt := time.Now().Add(expiresIn)
 
 	// Store the token in the database
 	err = s.userRepo.CreateEmailVerificationToken(user.ID, verificationToken, expiresAt)
@@ -841,16 +917,27 @@ func (s *UserService) SendEmailVerification(email string) error {
 	// Generate verification link
 	verificationLink := fmt.Sprintf("%s/verify-email?token=%s", s.config.App.FrontendURL, verificationToken)
 
-	// In a production environment, send an email with the verification link
-	// For now, just log it
-	s.logger.WithFields(map[string]interface{}{
-		"email":              email,
-		"user_id":            user.ID,
-		"verification_token": verificationToken,
-		"verification_link":  verificationLink,
-	}).Info("Email verification token generated and ready to be sent")
+	if s.mailer == nil {
+		// No mailer in development, log the link so the flow can be completed by hand
+		s.logger.WithFields(map[string]interface{}{
+			"email":             email,
+			"user_id":           user.ID,
+			"verification_link": verificationLink,
+		}).Info("No mailer configured, verification email not sent")
+		return nil
+	}
 
-	// TODO: Implement email sending functionality
+	err = s.sendEmail(user, mailer.TemplateEmailVerification, mailer.EmailVerificationData{
+		Name:            user.FullName,
+		VerificationURL: verificationLink,
+		ExpiresIn:       expiresIn,
+	})
+	if err != nil {
+		s.logger.WithError(err).WithField("user_id", user.ID).Error("Failed to send verification email")
+		return errors.ErrInternalServer("Failed to send verification email")
+	}
+
+	s.logger.WithField("user_id", user.ID).Info("Verification email sent")
 
 	return nil
 }
@@ -900,7 +987,7 @@ func (s *UserService) ResendVerificationEmail(email string) error {
 }
 
 // UpdateUserRole updates a user's role
-func (s *UserService) UpdateUserRole(userID uint, role int) error {
+func (s *UserService) UpdateUserRole(ctx context.Context, userID uint, role int) error {
 	s.logger.WithFields(map[string]interface{}{
 		"user_id": userID,
 		"role":    role,
@@ -933,6 +1020,14 @@ func (s *UserService) UpdateUserRole(userID uint, role int) error {
 		return errors.ErrInternalServer("Failed to update user role")
 	}
 
+	s.recordAudit(ctx, audit.Event{
+		Action:     "user.role_changed",
+		TargetType: "user",
+		TargetID:   userID,
+		Before:     map[string]interface{}{"role": user.Role},
+		After:      map[string]interface{}{"role": role},
+	})
+
 	s.logger.WithFields(map[string]interface{}{
 		"user_id":   userID,
 		"role":      role,
@@ -990,103 +1085,265 @@ func (s *UserService) Logout(userID uint) error {
 	return nil
 }
 
+// OAuthProviders lists the OAuth providers users can sign in with
+func (s *UserService) OAuthProviders() []auth.OAuthProviderInfo {
+	return s.oauthManager.Providers()
+}
+
 // OAuthLoginURL generates a URL for OAuth login
 func (s *UserService) OAuthLoginURL(provider string) (string, error) {
-	s.logger.WithField("provider", provider).Info("OAuth login URL requested")
+	return s.oauthURL(provider, 0)
+}
+
+// OAuthLinkURL generates a URL that links an OAuth identity to a signed-in user
+func (s *UserService) OAuthLinkURL(userID uint, provider string) (string, error) {
+	return s.oauthURL(provider, userID)
+}
 
-	// Generate the OAuth URL using the OAuth manager
-	authURL, err := s.oauthManager.GetAuthURL(provider)
+// oauthURL starts a login, or a link when linkUserID is set, at the provider
+func (s *UserService) oauthURL(provider string, linkUserID uint) (string, error) {
+	authURL, err := s.oauthManager.GetAuthURL(context.Background(), provider, linkUserID)
+	if err == auth.ErrUnsupportedOAuthProvider {
+		return "", errors.ErrNotFound("OAuth provider")
+	}
 	if err != nil {
 		s.logger.WithError(err).WithField("provider", provider).Error("Failed to generate OAuth URL")
-		return "", errors.NewAPIError("OAuth Error", "Failed to generate OAuth URL: "+err.Error(), http.StatusInternalServerError)
+		return "", errors.ErrInternalServer("Failed to generate OAuth URL")
 	}
 
 	s.logger.WithFields(map[string]interface{}{
 		"provider": provider,
-		"auth_url": authURL,
+		"link":     linkUserID != 0,
 	}).Info("OAuth login URL generated")
 
 	return authURL, nil
 }
 
-// OAuthCallback handles OAuth callback
-func (s *UserService) OAuthCallback(provider, code string) (*models.UserResponse, *models.TokenPair, error) {
-	s.logger.WithFields(map[string]interface{}{
-		"provider":    provider,
-		"code_length": len(code),
-	}).Info("OAuth callback received")
-
-	// Exchange the authorization code for user information
-	userInfo, err := s.oauthManager.ExchangeCodeAndGetUserInfo(provider, code)
-	if err != nil {
+// OAuthCallback completes a login or link started with OAuthLoginURL or
+// OAuthLinkURL. A login returns tokens; a link returns the user without tokens.
+func (s *UserService) OAuthCallback(provider, state, code string) (*models.UserResponse, *models.TokenPair, error) {
+	userInfo, oauthState, err := s.oauthManager.HandleCallback(context.Background(), provider, state, code)
+	switch {
+	case err == auth.ErrUnsupportedOAuthProvider:
+		return nil, nil, errors.ErrNotFound("OAuth provider")
+	case err == auth.ErrInvalidOAuthState:
+		return nil, nil, errors.ErrBadRequest("The sign-in link has expired, please try again")
+	case err != nil:
 		s.logger.WithError(err).WithField("provider", provider).Error("Failed to exchange code for user info")
-		return nil, nil, errors.ErrInternalServer("Authentication failed")
+		return nil, nil, errors.ErrUnauthorizedAccess("Authentication failed")
 	}
 
-	// Check if a user with this email already exists
-	existingUser, err := s.userRepo.GetByEmail(userInfo.Email)
+	identity, err := s.identityRepo.GetIdentity(provider, userInfo.ID)
 	if err != nil {
-		s.logger.WithError(err).WithField("email", userInfo.Email).Error("Failed to check for existing user")
+		s.logger.WithError(err).WithField("provider", provider).Error("Failed to look up OAuth identity")
 		return nil, nil, errors.ErrInternalServer("Authentication failed")
 	}
 
+	if oauthState.LinkUserID != 0 {
+		user, err := s.linkIdentity(oauthState.LinkUserID, identity, userInfo)
+		if err != nil {
+			return nil, nil, err
+		}
+		response := user.ToResponse()
+		return &response, nil, nil
+	}
+
 	var user *models.User
+	if identity != nil {
+		user, err = s.userRepo.GetByID(identity.UserID)
+		if err != nil || user == nil {
+			s.logger.WithError(err).WithField("user_id", identity.UserID).Error("Failed to load user of OAuth identity")
+			return nil, nil, errors.ErrInternalServer("Authentication failed")
+		}
+		if err := s.identityRepo.UpdateIdentityLogin(identity.ID, userInfo.Email, userInfo.Name); err != nil {
+			s.logger.WithError(err).WithField("user_id", user.ID).Error("Failed to record OAuth login")
+			// Non-critical error, continue
+		}
+	} else {
+		user, err = s.oauthSignUp(userInfo)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
 
-	if existingUser != nil {
-		// User exists, check if they're already linked to this OAuth provider
-		if existingUser.IsOAuth && existingUser.OAuthProvider == provider && existingUser.OAuthID == userInfo.ID {
-			// User already linked, just update last login
-			user = existingUser
-			if err := s.userRepo.UpdateLastLogin(user.ID); err != nil {
-				s.logger.WithError(err).WithField("user_id", user.ID).Error("Failed to update last login")
-				// Non-critical error, continue
-			}
-		} else if existingUser.IsOAuth {
-			// User exists but is linked to a different provider
-			s.logger.WithField("email", userInfo.Email).Info("User already exists with a different OAuth provider")
-			return nil, nil, errors.ErrResourceExists("Account already exists with a different provider")
-		} else {
-			// User exists with email/password auth, we could link the accounts here if desired
-			// For now, just return an error
-			s.logger.WithField("email", userInfo.Email).Info("User already exists with password auth")
-			return nil, nil, errors.ErrResourceExists("Account already exists with this email")
+	return s.CompletePasswordlessLogin(user, provider)
+}
+
+// CompletePasswordlessLogin signs in a user who proved their identity without
+// a password, through an OAuth provider or with a passkey. method names the
+// provider or "passkey" in the logs.
+func (s *UserService) CompletePasswordlessLogin(user *models.User, method string) (*models.UserResponse, *models.TokenPair, error) {
+	if !user.IsActive {
+		return nil, nil, errors.ErrForbiddenAccess("Account is disabled")
+	}
+	if err := s.userRepo.UpdateLastLogin(user.ID); err != nil {
+		s.logger.WithError(err).WithField("user_id", user.ID).Error("Failed to update last login")
+		// Non-critical error, continue
+	}
+
+	// Generate tokens
+	tokens, err := s.jwtManager.GenerateTokenPair(user)
+	if err != nil {
+		s.logger.WithError(err).WithField("user_id", user.ID).Error("Failed to generate tokens")
+		return nil, nil, errors.ErrInternalServer("Failed to generate authentication tokens")
+	}
+
+	s.logger.WithFields(map[string]interface{}{
+		"user_id": user.ID,
+		"method":  method,
+	}).Info("Passwordless login successful")
+	response := user.ToResponse()
+	return &response, tokens, nil
+}
+
+// oauthSignUp links a new identity to the account with the same email, or
+// creates an account for it. An existing account is only linked when both
+// the provider and this service have verified the email, so nobody can take
+// over an account by registering its email elsewhere.
+func (s *UserService) oauthSignUp(userInfo *auth.OAuthUserInfo) (*models.User, error) {
+	if userInfo.Email == "" {
+		return nil, errors.ErrBadRequest("The provider did not share an email address")
+	}
+
+	user, err := s.userRepo.GetByEmail(userInfo.Email)
+	if err != nil {
+		s.logger.WithError(err).WithField("email", userInfo.Email).Error("Failed to check for existing user")
+		return nil, errors.ErrInternalServer("Authentication failed")
+	}
+
+	if user != nil {
+		if !userInfo.EmailVerified || !user.IsVerified {
+			s.logger.WithField("email", userInfo.Email).Info("OAuth login matches an account that cannot be linked automatically")
+			return nil, errors.ErrConflict("An account with this email already exists. Sign in and link " + userInfo.Provider + " from your account settings")
 		}
 	} else {
-		// Create a new user with OAuth info
-		username := "user" + models.GenerateRandomString(8) // Generate a unique username
+		now := time.Now()
 		user = &models.User{
-			Username:        username,
+			Username:        "user" + models.GenerateRandomString(8),
 			Email:           userInfo.Email,
 			FullName:        userInfo.Name,
 			ProfileImage:    userInfo.Picture,
 			IsOAuth:         true,
-			OAuthProvider:   provider,
-			OAuthID:         userInfo.ID,
+			IsVerified:      userInfo.EmailVerified,
 			IsActive:        true,
-			LastLogin:       time.Now(),
-			CreatedAt:       time.Now(),
-			UpdatedAt:       time.Now(),
+			LastLogin:       now,
+			CreatedAt:       now,
+			UpdatedAt:       now,
 			MembershipLevel: models.MembershipBasic,
 			PointsBalance:   0,
 		}
-
 		if err := s.userRepo.Create(user); err != nil {
 			s.logger.WithError(err).WithField("email", userInfo.Email).Error("Failed to create user via OAuth")
-			return nil, nil, errors.ErrInternalServer("Failed to create account")
+			return nil, errors.ErrInternalServer("Failed to create account")
 		}
-
 		s.logger.WithField("user_id", user.ID).Info("Created new user via OAuth")
 	}
 
-	// Generate tokens
-	tokens, err := s.jwtManager.GenerateTokenPair(user)
+	if err := s.createIdentity(user.ID, userInfo); err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+// linkIdentity links an identity to a signed-in user
+func (s *UserService) linkIdentity(userID uint, identity *models.UserIdentity, userInfo *auth.OAuthUserInfo) (*models.User, error) {
+	user, err := s.userRepo.GetByID(userID)
 	if err != nil {
-		s.logger.WithError(err).WithField("user_id", user.ID).Error("Failed to generate tokens")
-		return nil, nil, errors.ErrInternalServer("Failed to generate authentication tokens")
+		return nil, errors.ErrInternalServer("Failed to link account")
+	}
+	if user == nil {
+		return nil, errors.ErrNotFound("User")
 	}
 
-	s.logger.WithField("user_id", user.ID).Info("OAuth login successful")
-	return &user.ToResponse(), tokens, nil
+	if identity != nil {
+		if identity.UserID != userID {
+			return nil, errors.ErrConflict("This " + userInfo.Provider + " account is linked to another user")
+		}
+		// Already linked to this user
+		return user, nil
+	}
+
+	identities, err := s.identityRepo.ListIdentities(userID)
+	if err != nil {
+		s.logger.WithError(err).WithField("user_id", userID).Error("Failed to list identities")
+		return nil, errors.ErrInternalServer("Failed to link account")
+	}
+	for _, linked := range identities {
+		if linked.Provider == userInfo.Provider {
+			return nil, errors.ErrConflict("Another " + userInfo.Provider + " account is already linked, unlink it first")
+		}
+	}
+
+	if err := s.createIdentity(userID, userInfo); err != nil {
+		return nil, err
+	}
+	s.logger.WithFields(map[string]interface{}{
+		"user_id":  userID,
+		"provider": userInfo.Provider,
+	}).Info("Linked OAuth identity")
+	return user, nil
+}
+
+func (s *UserService) createIdentity(userID uint, userInfo *auth.OAuthUserInfo) error {
+	identity := &models.UserIdentity{
+		UserID:      userID,
+		Provider:    userInfo.Provider,
+		Subject:     userInfo.ID,
+		Email:       userInfo.Email,
+		Name:        userInfo.Name,
+		LastLoginAt: time.Now(),
+	}
+	if err := s.identityRepo.CreateIdentity(identity); err != nil {
+		s.logger.WithError(err).WithField("user_id", userID).Error("Failed to create OAuth identity")
+		return errors.ErrInternalServer("Failed to link account")
+	}
+	return nil
+}
+
+// ListIdentities lists the external identities linked to a user
+func (s *UserService) ListIdentities(userID uint) ([]models.UserIdentity, error) {
+	identities, err := s.identityRepo.ListIdentities(userID)
+	if err != nil {
+		s.logger.WithError(err).WithField("user_id", userID).Error("Failed to list identities")
+		return nil, errors.ErrInternalServer("Failed to list linked accounts")
+	}
+	return identities, nil
+}
+
+// UnlinkIdentity unlinks a user's identity at a provider. A user without a
+// password must keep at least one identity to sign in with.
+func (s *UserService) UnlinkIdentity(userID uint, provider string) error {
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		return errors.ErrInternalServer("Failed to unlink account")
+	}
+	if user == nil {
+		return errors.ErrNotFound("User")
+	}
+
+	identities, err := s.identityRepo.ListIdentities(userID)
+	if err != nil {
+		s.logger.WithError(err).WithField("user_id", userID).Error("Failed to list identities")
+		return errors.ErrInternalServer("Failed to unlink account")
+	}
+	if user.Password == "" && len(identities) == 1 && identities[0].Provider == provider {
+		return errors.ErrConflict("Set a password before unlinking your only sign-in method")
+	}
+
+	err = s.identityRepo.DeleteIdentity(userID, provider)
+	if err == repository.ErrIdentityNotFound {
+		return errors.ErrNotFound("Linked account")
+	}
+	if err != nil {
+		s.logger.WithError(err).WithField("user_id", userID).Error("Failed to unlink identity")
+		return errors.ErrInternalServer("Failed to unlink account")
+	}
+
+	s.logger.WithFields(map[string]interface{}{
+		"user_id":  userID,
+		"provider": provider,
+	}).Info("Unlinked OAuth identity")
+	return nil
 }
 
 // ListUsers gets a paginated list of users
@@ -1118,32 +1375,6 @@ func (s *UserService) ListUsers(page, pageSize int) ([]models.UserResponse, int6
 	return userResponses, total, nil
 }
 
-// DeleteUser soft-deletes a user account
-func (s *UserService) DeleteUser(userID uint, password string) error {
-	s.logger.WithField("user_id", userID).Info("Deleting user account")
-
-	// Verify password first as a security measure
-	isValid, err := s.VerifyPassword(userID, password)
-	if err != nil {
-		s.logger.WithError(err).WithField("user_id", userID).Error("Failed to verify password during account deletion")
-		return err
-	}
-
-	if !isValid {
-		s.logger.WithField("user_id", userID).Warn("Account deletion failed due to invalid password")
-		return errors.NewAPIError("Unauthorized", "Invalid password", http.StatusUnauthorized)
-	}
-
-	// Proceed with account deletion
-	if err := s.userRepo.DeleteUser(userID); err != nil {
-		s.logger.WithError(err).WithField("user_id", userID).Error("Failed to delete user account")
-		return errors.ErrInternalServer("Failed to delete account")
-	}
-
-	s.logger.WithField("user_id", userID).Info("User account deleted successfully")
-	return nil
-}
-
 // VerifyPassword checks if a password is correct for a user
 func (s *UserService) VerifyPassword(userID uint, password string) (bool, error) {
 	s.logger.WithField("user_id", userID).Info("Verifying user password")
@@ -1340,6 +1571,12 @@ func (s *UserService) generateDeviceID(userAgent, ipAddress string) string {
 	return fmt.Sprintf("device_%x", len(deviceInfo)*31+int(deviceInfo[0]))
 }
 
+// GetWebAuthnManager returns the WebAuthn manager, which shares its challenge
+// store with the OAuth logins
+func (s *UserService) GetWebAuthnManager() *auth.WebAuthnManager {
+	return s.webAuthn
+}
+
 // GetJWTManager returns the JWT manager for use in middleware
 func (s *UserService) GetJWTManager() *auth.JWTManager {
 	return s.jwtManager
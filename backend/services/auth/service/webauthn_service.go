@@ -0,0 +1,314 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	stderrors "errors"
+	"strings"
+
+	"github.com/go-webauthn/webauthn/protocol"
+	"github.com/go-webauthn/webauthn/webauthn"
+	"github.com/yerenwgventures/GreatNigeriaLibrary-Foundation/backend/pkg/common/auth"
+	"github.com/yerenwgventures/GreatNigeriaLibrary-Foundation/backend/pkg/common/errors"
+	"github.com/yerenwgventures/GreatNigeriaLibrary-Foundation/backend/pkg/common/logger"
+	"github.com/yerenwgventures/GreatNigeriaLibrary-Foundation/backend/pkg/models"
+	"github.com/yerenwgventures/GreatNigeriaLibrary-Foundation/backend/services/auth/repository"
+)
+
+// MaxWebAuthnCredentials is how many passkeys and security keys a user can
+// register
+const MaxWebAuthnCredentials = 10
+
+// WebAuthnService defines methods for passkey and security key operations.
+// Every ceremony has a Begin step, whose options the client passes to
+// navigator.credentials, and a Finish step that verifies the result.
+type WebAuthnService interface {
+	// Registration of a new credential by a signed-in user
+	BeginRegistration(userID uint) (string, *protocol.CredentialCreation, error)
+	FinishRegistration(userID uint, req *models.WebAuthnRegisterRequest) (*models.WebAuthnCredential, error)
+
+	// Second factor for a signed-in user, in place of a TOTP code
+	BeginVerification(userID uint) (string, *protocol.CredentialAssertion, error)
+	FinishVerification(userID uint, req *models.WebAuthnAssertionRequest) error
+
+	// Passwordless login with a passkey
+	BeginLogin() (string, *protocol.CredentialAssertion, error)
+	FinishLogin(req *models.WebAuthnAssertionRequest) (*models.UserResponse, *models.TokenPair, error)
+
+	ListCredentials(userID uint) ([]models.WebAuthnCredential, error)
+	RevokeCredential(userID, id uint) error
+}
+
+// WebAuthnServiceImpl implements WebAuthnService interface
+type WebAuthnServiceImpl struct {
+	webAuthnRepo repository.WebAuthnRepository
+	userService  *UserService
+	manager      *auth.WebAuthnManager
+	logger       *logger.Logger
+}
+
+// NewWebAuthnService creates a new WebAuthnService instance
+func NewWebAuthnService(webAuthnRepo repository.WebAuthnRepository, userService *UserService, logger *logger.Logger) WebAuthnService {
+	return &WebAuthnServiceImpl{
+		webAuthnRepo: webAuthnRepo,
+		userService:  userService,
+		manager:      userService.GetWebAuthnManager(),
+		logger:       logger,
+	}
+}
+
+// BeginRegistration starts registering a passkey or security key
+func (s *WebAuthnServiceImpl) BeginRegistration(userID uint) (string, *protocol.CredentialCreation, error) {
+	user, credentials, err := s.webAuthnUser(userID)
+	if err != nil {
+		return "", nil, err
+	}
+	if len(credentials) >= MaxWebAuthnCredentials {
+		return "", nil, errors.ErrConflict("You have registered the maximum number of passkeys, revoke one first")
+	}
+
+	ceremonyID, creation, err := s.manager.BeginRegistration(context.Background(), user)
+	if err != nil {
+		s.logger.WithError(err).WithField("user_id", userID).Error("Failed to begin WebAuthn registration")
+		return "", nil, errors.ErrInternalServer("Failed to begin passkey registration")
+	}
+	return ceremonyID, creation, nil
+}
+
+// FinishRegistration verifies the authenticator's response and stores the
+// new credential
+func (s *WebAuthnServiceImpl) FinishRegistration(userID uint, req *models.WebAuthnRegisterRequest) (*models.WebAuthnCredential, error) {
+	user, _, err := s.webAuthnUser(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	credential, err := s.manager.FinishRegistration(context.Background(), req.CeremonyID, user, bytes.NewReader(req.Credential))
+	if err != nil {
+		return nil, s.ceremonyError(err, userID, "Passkey registration failed")
+	}
+
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		name = "Passkey"
+	}
+	stored := toWebAuthnCredentialModel(userID, name, credential)
+	if err := s.webAuthnRepo.CreateCredential(stored); err != nil {
+		s.logger.WithError(err).WithField("user_id", userID).Error("Failed to store WebAuthn credential")
+		return nil, errors.ErrInternalServer("Failed to register passkey")
+	}
+
+	s.logger.WithFields(map[string]interface{}{
+		"user_id":       userID,
+		"credential_id": stored.ID,
+	}).Info("WebAuthn credential registered")
+	return stored, nil
+}
+
+// BeginVerification starts a second factor check with one of the user's
+// credentials
+func (s *WebAuthnServiceImpl) BeginVerification(userID uint) (string, *protocol.CredentialAssertion, error) {
+	user, credentials, err := s.webAuthnUser(userID)
+	if err != nil {
+		return "", nil, err
+	}
+	if len(credentials) == 0 {
+		return "", nil, errors.ErrBadRequest("No passkeys are registered")
+	}
+
+	ceremonyID, assertion, err := s.manager.BeginLogin(context.Background(), user)
+	if err != nil {
+		s.logger.WithError(err).WithField("user_id", userID).Error("Failed to begin WebAuthn verification")
+		return "", nil, errors.ErrInternalServer("Failed to begin passkey verification")
+	}
+	return ceremonyID, assertion, nil
+}
+
+// FinishVerification verifies a second factor assertion
+func (s *WebAuthnServiceImpl) FinishVerification(userID uint, req *models.WebAuthnAssertionRequest) error {
+	user, credentials, err := s.webAuthnUser(userID)
+	if err != nil {
+		return err
+	}
+
+	credential, err := s.manager.FinishLogin(context.Background(), req.CeremonyID, user, bytes.NewReader(req.Credential))
+	if err != nil {
+		return s.ceremonyError(err, userID, "Passkey verification failed")
+	}
+	return s.recordUse(userID, credentials, credential)
+}
+
+// BeginLogin starts a passwordless login
+func (s *WebAuthnServiceImpl) BeginLogin() (string, *protocol.CredentialAssertion, error) {
+	ceremonyID, assertion, err := s.manager.BeginPasskeyLogin(context.Background())
+	if err != nil {
+		s.logger.WithError(err).Error("Failed to begin WebAuthn login")
+		return "", nil, errors.ErrInternalServer("Failed to begin passkey login")
+	}
+	return ceremonyID, assertion, nil
+}
+
+// FinishLogin verifies a passkey assertion and signs its user in
+func (s *WebAuthnServiceImpl) FinishLogin(req *models.WebAuthnAssertionRequest) (*models.UserResponse, *models.TokenPair, error) {
+	var credentials []models.WebAuthnCredential
+	loadUser := func(userID uint) (*auth.WebAuthnUser, error) {
+		user, userCredentials, err := s.webAuthnUser(userID)
+		credentials = userCredentials
+		return user, err
+	}
+
+	user, credential, err := s.manager.FinishPasskeyLogin(context.Background(), req.CeremonyID, bytes.NewReader(req.Credential), loadUser)
+	if err != nil {
+		return nil, nil, s.ceremonyError(err, 0, "Passkey login failed")
+	}
+	if err := s.recordUse(user.ID, credentials, credential); err != nil {
+		return nil, nil, err
+	}
+
+	account, err := s.userService.GetUserByID(user.ID)
+	if err != nil {
+		return nil, nil, err
+	}
+	return s.userService.CompletePasswordlessLogin(account, "passkey")
+}
+
+// ListCredentials lists the user's passkeys and security keys
+func (s *WebAuthnServiceImpl) ListCredentials(userID uint) ([]models.WebAuthnCredential, error) {
+	credentials, err := s.webAuthnRepo.ListCredentials(userID)
+	if err != nil {
+		s.logger.WithError(err).WithField("user_id", userID).Error("Failed to list WebAuthn credentials")
+		return nil, errors.ErrInternalServer("Failed to list passkeys")
+	}
+	return credentials, nil
+}
+
+// RevokeCredential deletes one of the user's credentials, so it can no longer
+// sign in
+func (s *WebAuthnServiceImpl) RevokeCredential(userID, id uint) error {
+	err := s.webAuthnRepo.DeleteCredential(userID, id)
+	if stderrors.Is(err, repository.ErrWebAuthnCredentialNotFound) {
+		return errors.ErrNotFound("Passkey")
+	}
+	if err != nil {
+		s.logger.WithError(err).WithField("user_id", userID).Error("Failed to revoke WebAuthn credential")
+		return errors.ErrInternalServer("Failed to revoke passkey")
+	}
+
+	s.logger.WithFields(map[string]interface{}{
+		"user_id":       userID,
+		"credential_id": id,
+	}).Info("WebAuthn credential revoked")
+	return nil
+}
+
+// webAuthnUser loads a user and their credentials
+func (s *WebAuthnServiceImpl) webAuthnUser(userID uint) (*auth.WebAuthnUser, []models.WebAuthnCredential, error) {
+	user, err := s.userService.GetUserByID(userID)
+	if err != nil {
+		return nil, nil, err
+	}
+	credentials, err := s.ListCredentials(userID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	displayName := user.DisplayName
+	if displayName == "" {
+		displayName = user.FullName
+	}
+	webAuthnUser := &auth.WebAuthnUser{
+		ID:          user.ID,
+		Name:        user.Email,
+		DisplayName: displayName,
+		Credentials: make([]webauthn.Credential, 0, len(credentials)),
+	}
+	for i := range credentials {
+		webAuthnUser.Credentials = append(webAuthnUser.Credentials, toWebAuthnCredential(&credentials[i]))
+	}
+	return webAuthnUser, credentials, nil
+}
+
+// recordUse stores the new signature counter of the credential that signed an
+// assertion. It fails when a concurrent login already used the counter.
+func (s *WebAuthnServiceImpl) recordUse(userID uint, credentials []models.WebAuthnCredential, used *webauthn.Credential) error {
+	for _, credential := range credentials {
+		if !bytes.Equal(credential.CredentialID, used.ID) {
+			continue
+		}
+		err := s.webAuthnRepo.UpdateCredentialUse(credential.ID, used.Authenticator.SignCount, used.Flags.BackupState)
+		if stderrors.Is(err, repository.ErrStaleSignCount) {
+			s.logger.WithField("user_id", userID).Warn("Rejected WebAuthn assertion with a stale signature counter")
+			return errors.ErrUnauthorizedAccess("Passkey verification failed")
+		}
+		if err != nil {
+			s.logger.WithError(err).WithField("user_id", userID).Error("Failed to update WebAuthn credential")
+			return errors.ErrInternalServer("Passkey verification failed")
+		}
+		return nil
+	}
+	return errors.ErrUnauthorizedAccess("Passkey verification failed")
+}
+
+// ceremonyError maps an error of the WebAuthn manager to an API error
+func (s *WebAuthnServiceImpl) ceremonyError(err error, userID uint, message string) error {
+	switch {
+	case stderrors.Is(err, auth.ErrInvalidWebAuthnCeremony):
+		return errors.ErrBadRequest("The passkey request has expired, please try again")
+	case stderrors.Is(err, auth.ErrWebAuthnSignCount):
+		s.logger.WithField("user_id", userID).Warn("Rejected WebAuthn assertion from a possibly cloned authenticator")
+		return errors.ErrUnauthorizedAccess(message)
+	case stderrors.Is(err, auth.ErrWebAuthnVerification):
+		s.logger.WithError(err).WithField("user_id", userID).Info("WebAuthn verification failed")
+		return errors.ErrUnauthorizedAccess(message)
+	default:
+		s.logger.WithError(err).WithField("user_id", userID).Error("WebAuthn ceremony failed")
+		return errors.ErrInternalServer(message)
+	}
+}
+
+// toWebAuthnCredential converts a stored credential for the WebAuthn library
+func toWebAuthnCredential(credential *models.WebAuthnCredential) webauthn.Credential {
+	var transports []protocol.AuthenticatorTransport
+	if credential.Transports != "" {
+		for _, transport := range strings.Split(credential.Transports, ",") {
+			transports = append(transports, protocol.AuthenticatorTransport(transport))
+		}
+	}
+	return webauthn.Credential{
+		ID:              credential.CredentialID,
+		PublicKey:       credential.PublicKey,
+		AttestationType: credential.AttestationType,
+		Transport:       transports,
+		Flags: webauthn.CredentialFlags{
+			UserPresent:    true,
+			UserVerified:   credential.UserVerified,
+			BackupEligible: credential.BackupEligible,
+			BackupState:    credential.BackupState,
+		},
+		Authenticator: webauthn.Authenticator{
+			AAGUID:    credential.AAGUID,
+			SignCount: credential.SignCount,
+		},
+	}
+}
+
+// toWebAuthnCredentialModel converts a newly registered credential for storage
+func toWebAuthnCredentialModel(userID uint, name string, credential *webauthn.Credential) *models.WebAuthnCredential {
+	transports := make([]string, 0, len(credential.Transport))
+	for _, transport := range credential.Transport {
+		transports = append(transports, string(transport))
+	}
+	return &models.WebAuthnCredential{
+		UserID:          userID,
+		CredentialID:    credential.ID,
+		PublicKey:       credential.PublicKey,
+		AttestationType: credential.AttestationType,
+		Transports:      strings.Join(transports, ","),
+		AAGUID:          credential.Authenticator.AAGUID,
+		SignCount:       credential.Authenticator.SignCount,
+		UserVerified:    credential.Flags.UserVerified,
+		BackupEligible:  credential.Flags.BackupEligible,
+		BackupState:     credential.Flags.BackupState,
+		Name:            name,
+	}
+}
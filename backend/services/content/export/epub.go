@@ -0,0 +1,350 @@
+package export
+
+import (
+	"archive/zip"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+// epubStyles is the stylesheet shared by every EPUB document
+const epubStyles = `body { font-family: serif; line-height: 1.5; margin: 0 5%; }
+h1, h2, h3, h4, h5, h6 { font-family: sans-serif; line-height: 1.2; }
+h1 { margin-top: 2em; }
+.title-page { text-align: center; margin-top: 20%; }
+.subtitle { font-size: 1.2em; font-style: italic; }
+.author { margin-top: 2em; }
+.cover { text-align: center; margin: 0; padding: 0; }
+.cover img { max-width: 100%; max-height: 100%; }
+blockquote { margin-left: 1.5em; font-style: italic; }
+pre { white-space: pre-wrap; font-size: 0.85em; }
+sup a { text-decoration: none; }
+aside.footnotes { border-top: 1px solid #999; margin-top: 2em; font-size: 0.85em; }
+nav ol { list-style: none; padding-left: 1em; }
+`
+
+// imageExtensions maps cover media types to file extensions
+var imageExtensions = map[string]string{
+	"image/jpeg":    ".jpg",
+	"image/png":     ".png",
+	"image/gif":     ".gif",
+	"image/svg+xml": ".svg",
+}
+
+// epubDocument is a content document in the spine
+type epubDocument struct {
+	id    string
+	href  string
+	title string
+	body  string
+}
+
+// WriteEPUB writes the publication as an EPUB 3 package with a navigation
+// document, a cover page when there is a cover image, and footnotes at the
+// end of each chapter
+func WriteEPUB(w io.Writer, p *Publication) error {
+	if err := p.validate(); err != nil {
+		return err
+	}
+	zw := zip.NewWriter(w)
+
+	// The mimetype must come first and be stored uncompressed
+	mimetype, err := zw.CreateHeader(&zip.FileHeader{Name: "mimetype", Method: zip.Store})
+	if err != nil {
+		return err
+	}
+	if _, err := io.WriteString(mimetype, "application/epub+zip"); err != nil {
+		return err
+	}
+
+	files := map[string]string{
+		"META-INF/container.xml": `<?xml version="1.0" encoding="UTF-8"?>
+<container version="1.0" xmlns="urn:oasis:names:tc:opendocument:xmlns:container">
+  <rootfiles>
+    <rootfile full-path="OEBPS/content.opf" media-type="application/oebps-package+xml"/>
+  </rootfiles>
+</container>
+`,
+		"OEBPS/styles.css": epubStyles,
+	}
+
+	var documents []epubDocument
+	coverHref := ""
+	if p.Cover != nil && imageExtensions[p.Cover.MediaType] != "" {
+		coverHref = "images/cover" + imageExtensions[p.Cover.MediaType]
+		documents = append(documents, epubDocument{
+			id:    "cover",
+			href:  "cover.xhtml",
+			title: "Cover",
+			body:  `<div class="cover"><img src="` + coverHref + `" alt="` + xmlEscape(p.Title) + `"/></div>`,
+		})
+	}
+	documents = append(documents, epubDocument{
+		id:    "title-page",
+		href:  "title.xhtml",
+		title: p.Title,
+		body:  epubTitlePage(p),
+	})
+	for i, part := range p.Parts {
+		documents = append(documents, epubDocument{
+			id:    fmt.Sprintf("part-%03d", i+1),
+			href:  fmt.Sprintf("part-%03d.xhtml", i+1),
+			title: part.Title,
+			body:  epubPart(part, p.partNotes(part)),
+		})
+	}
+
+	for _, doc := range documents {
+		files["OEBPS/"+doc.href] = xhtmlDocument(doc.title, p.language(), doc.body)
+	}
+	files["OEBPS/nav.xhtml"] = xhtmlDocument("Contents", p.language(), epubNav(p, documents))
+	files["OEBPS/content.opf"] = epubPackage(p, documents, coverHref)
+
+	// Write files in a fixed order so identical publications produce
+	// identical archives
+	names := make([]string, 0, len(files))
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		f, err := zw.Create(name)
+		if err != nil {
+			return err
+		}
+		if _, err := io.WriteString(f, files[name]); err != nil {
+			return err
+		}
+	}
+
+	if coverHref != "" {
+		f, err := zw.Create("OEBPS/" + coverHref)
+		if err != nil {
+			return err
+		}
+		if _, err := f.Write(p.Cover.Data); err != nil {
+			return err
+		}
+	}
+
+	return zw.Close()
+}
+
+// epubTitlePage returns the body of the title page
+func epubTitlePage(p *Publication) string {
+	var sb strings.Builder
+	sb.WriteString(`<section class="title-page" epub:type="titlepage">`)
+	sb.WriteString("<h1>" + xmlEscape(p.Title) + "</h1>")
+	if p.Subtitle != "" {
+		sb.WriteString(`<p class="subtitle">` + xmlEscape(p.Subtitle) + "</p>")
+	}
+	if p.Author != "" {
+		sb.WriteString(`<p class="author">` + xmlEscape(p.Author) + "</p>")
+	}
+	if p.Description != "" {
+		sb.WriteString("<p>" + xmlEscape(p.Description) + "</p>")
+	}
+	sb.WriteString("</section>")
+	return sb.String()
+}
+
+// epubPart returns the body of a part document. Citation markers become
+// note references to footnotes at the end of the document.
+func epubPart(part Part, notes map[int]string) string {
+	x := &xhtmlWriter{notes: notes, cited: make(map[int]bool)}
+
+	x.sb.WriteString(`<section epub:type="chapter">`)
+	x.sb.WriteString("<h1>" + xmlEscape(part.Title) + "</h1>")
+	x.content(part.HTML)
+	for i, section := range part.Sections {
+		fmt.Fprintf(&x.sb, `<section id="section-%d">`, i+1)
+		x.sb.WriteString("<h2>" + xmlEscape(section.Title) + "</h2>")
+		x.content(section.HTML)
+		x.sb.WriteString("</section>")
+	}
+
+	if len(x.cited) > 0 {
+		x.sb.WriteString(`<aside class="footnotes">`)
+		for _, number := range sortedNotes(x.cited) {
+			fmt.Fprintf(&x.sb, `<aside epub:type="footnote" id="note-%d"><p>%d. %s</p></aside>`,
+				number, number, xmlEscape(notes[number]))
+		}
+		x.sb.WriteString("</aside>")
+	}
+	x.sb.WriteString("</section>")
+	return x.sb.String()
+}
+
+// epubNav returns the body of the navigation document
+func epubNav(p *Publication, documents []epubDocument) string {
+	var sb strings.Builder
+	sb.WriteString(`<nav epub:type="toc" id="toc"><h1>Contents</h1><ol>`)
+	parts := documents[len(documents)-len(p.Parts):]
+	for i, part := range p.Parts {
+		href := parts[i].href
+		sb.WriteString(`<li><a href="` + href + `">` + xmlEscape(part.Title) + "</a>")
+		if len(part.Sections) > 0 {
+			sb.WriteString("<ol>")
+			for j, section := range part.Sections {
+				fmt.Fprintf(&sb, `<li><a href="%s#section-%d">%s</a></li>`, href, j+1, xmlEscape(section.Title))
+			}
+			sb.WriteString("</ol>")
+		}
+		sb.WriteString("</li>")
+	}
+	sb.WriteString("</ol></nav>")
+
+	sb.WriteString(`<nav epub:type="landmarks" hidden="hidden"><ol>`)
+	for _, doc := range documents {
+		switch doc.id {
+		case "cover":
+			sb.WriteString(`<li><a epub:type="cover" href="cover.xhtml">Cover</a></li>`)
+		case "title-page":
+			sb.WriteString(`<li><a epub:type="titlepage" href="title.xhtml">Title Page</a></li>`)
+		}
+	}
+	if len(parts) > 0 {
+		sb.WriteString(`<li><a epub:type="bodymatter" href="` + parts[0].href + `">Start of Content</a></li>`)
+	}
+	sb.WriteString("</ol></nav>")
+	return sb.String()
+}
+
+// epubPackage returns the OPF package document
+func epubPackage(p *Publication, documents []epubDocument, coverHref string) string {
+	var sb strings.Builder
+	sb.WriteString(`<?xml version="1.0" encoding="UTF-8"?>
+<package xmlns="http://www.idpf.org/2007/opf" version="3.0" unique-identifier="pub-id" xml:lang="` + xmlEscape(p.language()) + `">
+  <metadata xmlns:dc="http://purl.org/dc/elements/1.1/">
+`)
+	sb.WriteString("    <dc:identifier id=\"pub-id\">" + xmlEscape(p.Identifier) + "</dc:identifier>\n")
+	sb.WriteString("    <dc:title>" + xmlEscape(p.Title) + "</dc:title>\n")
+	sb.WriteString("    <dc:language>" + xmlEscape(p.language()) + "</dc:language>\n")
+	if p.Author != "" {
+		sb.WriteString("    <dc:creator>" + xmlEscape(p.Author) + "</dc:creator>\n")
+	}
+	if p.Description != "" {
+		sb.WriteString("    <dc:description>" + xmlEscape(p.Description) + "</dc:description>\n")
+	}
+	sb.WriteString("    <meta property=\"dcterms:modified\">" + p.modified().Format("2006-01-02T15:04:05Z") + "</meta>\n")
+	sb.WriteString("  </metadata>\n  <manifest>\n")
+	sb.WriteString("    <item id=\"nav\" href=\"nav.xhtml\" media-type=\"application/xhtml+xml\" properties=\"nav\"/>\n")
+	sb.WriteString("    <item id=\"styles\" href=\"styles.css\" media-type=\"text/css\"/>\n")
+	if coverHref != "" {
+		fmt.Fprintf(&sb, "    <item id=\"cover-image\" href=\"%s\" media-type=\"%s\" properties=\"cover-image\"/>\n",
+			coverHref, p.Cover.MediaType)
+	}
+	for _, doc := range documents {
+		fmt.Fprintf(&sb, "    <item id=\"%s\" href=\"%s\" media-type=\"application/xhtml+xml\"/>\n", doc.id, doc.href)
+	}
+	sb.WriteString("  </manifest>\n  <spine>\n")
+	for _, doc := range documents {
+		fmt.Fprintf(&sb, "    <itemref idref=\"%s\"/>\n", doc.id)
+	}
+	sb.WriteString("  </spine>\n</package>\n")
+	return sb.String()
+}
+
+// xhtmlDocument wraps a body in an XHTML content document
+func xhtmlDocument(title, language, body string) string {
+	return `<?xml version="1.0" encoding="UTF-8"?>
+<!DOCTYPE html>
+<html xmlns="http://www.w3.org/1999/xhtml" xmlns:epub="http://www.idpf.org/2007/ops" xml:lang="` + xmlEscape(language) + `" lang="` + xmlEscape(language) + `">
+<head>
+<meta charset="UTF-8"/>
+<title>` + xmlEscape(title) + `</title>
+<link rel="stylesheet" type="text/css" href="styles.css"/>
+</head>
+<body>
+` + body + `
+</body>
+</html>
+`
+}
+
+// xhtmlWriter serializes parsed content HTML as well-formed XHTML
+type xhtmlWriter struct {
+	sb    strings.Builder
+	notes map[int]string
+	cited map[int]bool
+}
+
+// content writes an HTML fragment
+func (x *xhtmlWriter) content(src string) {
+	if strings.TrimSpace(src) == "" {
+		return
+	}
+	for _, child := range parseHTML(src).children {
+		x.node(child, false)
+	}
+}
+
+// node writes a node and its children. Inside pre, text is kept as is and
+// citation markers are not converted.
+func (x *xhtmlWriter) node(n *node, pre bool) {
+	if n.tag == "" {
+		if pre {
+			x.sb.WriteString(xmlEscape(n.text))
+			return
+		}
+		for _, seg := range splitNotes(n.text, x.notes) {
+			if seg.note == 0 {
+				x.sb.WriteString(xmlEscape(seg.text))
+				continue
+			}
+			x.cited[seg.note] = true
+			fmt.Fprintf(&x.sb, `<sup><a epub:type="noteref" href="#note-%d">%d</a></sup>`, seg.note, seg.note)
+		}
+		return
+	}
+
+	if !keptTags[n.tag] {
+		for _, child := range n.children {
+			x.node(child, pre)
+		}
+		return
+	}
+
+	tag := n.tag
+	if headingLevel(tag) > 0 {
+		tag = demoteHeading(tag)
+	}
+
+	attrs := ""
+	switch tag {
+	case "a":
+		href := externalLink(n.attrs["href"])
+		if href == "" {
+			for _, child := range n.children {
+				x.node(child, pre)
+			}
+			return
+		}
+		attrs = ` href="` + xmlEscape(href) + `"`
+	case "td", "th":
+		for _, name := range []string{"colspan", "rowspan"} {
+			if value := n.attrs[name]; value != "" {
+				attrs += " " + name + `="` + xmlEscape(value) + `"`
+			}
+		}
+	}
+
+	if tag == "br" || tag == "hr" {
+		x.sb.WriteString("<" + tag + "/>")
+		return
+	}
+	x.sb.WriteString("<" + tag + attrs + ">")
+	for _, child := range n.children {
+		x.node(child, pre || tag == "pre")
+	}
+	x.sb.WriteString("</" + tag + ">")
+}
+
+// xmlEscaper escapes text for XML content and attribute values
+var xmlEscaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;", `"`, "&quot;", "'", "&apos;")
+
+// xmlEscape escapes text for XML content and attribute values
+func xmlEscape(s string) string {
+	return xmlEscaper.Replace(s)
+}
@@ -0,0 +1,112 @@
+package export
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testPublication() *Publication {
+	paragraphs := strings.Repeat("<p>Nigeria's institutions were shaped by their colonial origins and the choices made since independence.</p>", 40)
+	return &Publication{
+		Identifier: "urn:greatnigeria:book:1",
+		Title:      "Awakening the Giant",
+		Author:     "Great Nigeria Network",
+		Modified:   time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC),
+		Parts: []Part{
+			{Title: "Preface", HTML: "<p>A nation at the crossroads &amp; its people.</p>"},
+			{
+				Title: "Historical Roots",
+				HTML:  "<p>Overview<br>of the chapter.</p>",
+				Sections: []Section{
+					{Title: "Colonial Legacy", HTML: "<h2>Leadership</h2><p>As Achebe argued [1], the trouble is leadership.</p>" + paragraphs},
+					{Title: "Independence", HTML: "<ul><li>1960</li><li>1963 [2]</li></ul><script>alert(1)</script>" + paragraphs},
+				},
+			},
+			{Title: "Bibliography", HTML: "<p>Achebe, Chinua. (1983). <em>The Trouble with Nigeria</em>. Heinemann. [1]</p>", References: true},
+		},
+		Notes: map[int]string{
+			1: "Achebe, Chinua (1983). The Trouble with Nigeria. Heinemann.",
+			2: "Constitution of the Federal Republic of Nigeria (1963).",
+		},
+	}
+}
+
+func TestWriteEPUB(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, WriteEPUB(&buf, testPublication()))
+
+	zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	require.NoError(t, err)
+	require.NotEmpty(t, zr.File)
+	assert.Equal(t, "mimetype", zr.File[0].Name)
+	assert.Equal(t, zip.Store, zr.File[0].Method)
+
+	files := make(map[string]string)
+	for _, f := range zr.File {
+		rc, err := f.Open()
+		require.NoError(t, err)
+		data, err := io.ReadAll(rc)
+		rc.Close()
+		require.NoError(t, err)
+		files[f.Name] = string(data)
+
+		if strings.HasSuffix(f.Name, ".xhtml") || strings.HasSuffix(f.Name, ".opf") || strings.HasSuffix(f.Name, ".xml") {
+			decoder := xml.NewDecoder(strings.NewReader(string(data)))
+			for {
+				_, err := decoder.Token()
+				if err == io.EOF {
+					break
+				}
+				require.NoError(t, err, f.Name)
+			}
+		}
+	}
+
+	assert.Equal(t, "application/epub+zip", files["mimetype"])
+	assert.Contains(t, files["OEBPS/nav.xhtml"], "Colonial Legacy")
+	assert.Contains(t, files["OEBPS/content.opf"], "urn:greatnigeria:book:1")
+
+	chapter := files["OEBPS/part-002.xhtml"]
+	assert.Contains(t, chapter, `href="#note-1"`)
+	assert.Contains(t, chapter, `id="note-1"`)
+	assert.Contains(t, chapter, "The Trouble with Nigeria")
+	assert.NotContains(t, chapter, "alert(1)")
+	assert.NotContains(t, files["OEBPS/part-003.xhtml"], "noteref")
+}
+
+func TestWritePDF(t *testing.T) {
+	var buf bytes.Buffer
+	pages, err := WritePDF(&buf, testPublication())
+	require.NoError(t, err)
+
+	assert.True(t, bytes.HasPrefix(buf.Bytes(), []byte("%PDF-")))
+	assert.Greater(t, pages, 4)
+
+	_, err = Write(io.Discard, &Publication{Title: "Empty"}, FormatPDF)
+	assert.Error(t, err)
+	_, err = Write(io.Discard, testPublication(), "mobi")
+	assert.ErrorIs(t, err, ErrUnsupportedFormat)
+}
+
+func TestPublicationHash(t *testing.T) {
+	p := testPublication()
+	first, err := p.Hash()
+	require.NoError(t, err)
+
+	again, err := testPublication().Hash()
+	require.NoError(t, err)
+	assert.Equal(t, first, again)
+
+	p.Parts[1].Sections[0].HTML += "<p>Revised.</p>"
+	changed, err := p.Hash()
+	require.NoError(t, err)
+	assert.NotEqual(t, first, changed)
+}
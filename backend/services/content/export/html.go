@@ -0,0 +1,136 @@
+package export
+
+import (
+	"encoding/xml"
+	"strings"
+)
+
+// node is an element or text node of parsed content HTML
+type node struct {
+	tag      string // Empty for text nodes
+	attrs    map[string]string
+	text     string
+	children []*node
+}
+
+// droppedTags are removed with their content: scripts, forms and media that
+// cannot work offline
+var droppedTags = map[string]bool{
+	"head": true, "script": true, "style": true, "noscript": true, "template": true,
+	"form": true, "button": true, "input": true, "select": true, "textarea": true, "label": true,
+	"img": true, "picture": true, "svg": true, "video": true, "audio": true, "iframe": true,
+	"object": true, "embed": true, "canvas": true, "nav": true,
+}
+
+// keptTags are written to the EPUB as they are; other elements are replaced
+// by their content
+var keptTags = map[string]bool{
+	"p": true, "h1": true, "h2": true, "h3": true, "h4": true, "h5": true, "h6": true,
+	"ul": true, "ol": true, "li": true, "dl": true, "dt": true, "dd": true,
+	"blockquote": true, "pre": true, "code": true, "hr": true, "br": true,
+	"em": true, "strong": true, "i": true, "b": true, "u": true, "sup": true, "sub": true,
+	"a": true, "div": true, "span": true, "figure": true, "figcaption": true,
+	"table": true, "thead": true, "tbody": true, "tr": true, "th": true, "td": true,
+}
+
+// blockTags start on a new line in the PDF
+var blockTags = map[string]bool{
+	"p": true, "h1": true, "h2": true, "h3": true, "h4": true, "h5": true, "h6": true,
+	"ul": true, "ol": true, "li": true, "dl": true, "dt": true, "dd": true,
+	"blockquote": true, "pre": true, "hr": true, "div": true, "figure": true, "figcaption": true,
+	"table": true, "tr": true,
+}
+
+// parseHTML parses an HTML fragment leniently. Unknown elements are
+// unwrapped, dropped elements removed and malformed markup closed where the
+// parser gives up.
+func parseHTML(src string) *node {
+	root := &node{tag: "body"}
+	decoder := xml.NewDecoder(strings.NewReader("<body>" + src + "</body>"))
+	decoder.Strict = false
+	decoder.AutoClose = xml.HTMLAutoClose
+	decoder.Entity = xml.HTMLEntity
+
+	stack := []*node{}
+	skipDepth := 0
+	for {
+		token, err := decoder.Token()
+		if err != nil {
+			break
+		}
+
+		switch t := token.(type) {
+		case xml.StartElement:
+			name := strings.ToLower(t.Name.Local)
+			if skipDepth > 0 || droppedTags[name] {
+				skipDepth++
+				continue
+			}
+			if len(stack) == 0 {
+				stack = append(stack, root)
+				continue
+			}
+			n := &node{tag: name, attrs: make(map[string]string)}
+			for _, a := range t.Attr {
+				n.attrs[strings.ToLower(a.Name.Local)] = a.Value
+			}
+			parent := stack[len(stack)-1]
+			parent.children = append(parent.children, n)
+			stack = append(stack, n)
+
+		case xml.EndElement:
+			if skipDepth > 0 {
+				skipDepth--
+				continue
+			}
+			if len(stack) > 1 {
+				stack = stack[:len(stack)-1]
+			}
+
+		case xml.CharData:
+			if skipDepth > 0 || len(stack) == 0 {
+				continue
+			}
+			parent := stack[len(stack)-1]
+			parent.children = append(parent.children, &node{text: string(t)})
+		}
+	}
+	return root
+}
+
+// headingLevel returns the level of a heading tag, or 0
+func headingLevel(tag string) int {
+	if len(tag) == 2 && tag[0] == 'h' && tag[1] >= '1' && tag[1] <= '6' {
+		return int(tag[1] - '0')
+	}
+	return 0
+}
+
+// demoteHeading shifts content headings below the part and section titles
+func demoteHeading(tag string) string {
+	level := headingLevel(tag) + 2
+	if level > 6 {
+		level = 6
+	}
+	return "h" + string(rune('0'+level))
+}
+
+// externalLink returns href if it points outside the publication
+func externalLink(href string) string {
+	if strings.HasPrefix(href, "http://") || strings.HasPrefix(href, "https://") || strings.HasPrefix(href, "mailto:") {
+		return href
+	}
+	return ""
+}
+
+// textContent returns the text of a node and its descendants
+func textContent(n *node) string {
+	if n.tag == "" {
+		return n.text
+	}
+	var sb strings.Builder
+	for _, child := range n.children {
+		sb.WriteString(textContent(child))
+	}
+	return sb.String()
+}
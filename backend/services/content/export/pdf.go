@@ -0,0 +1,598 @@
+package export
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	_ "image/gif" // register decoders for cover images
+	"image/jpeg"
+	_ "image/png"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/jung-kurt/gofpdf"
+)
+
+// PDF page layout, in millimetres on A5 paper
+const (
+	pdfPageSize       = "A5"
+	pdfMargin         = 18.0
+	pdfBottomMargin   = 20.0
+	pdfLineHeight     = 5.2
+	pdfBodySize       = 11.0
+	pdfNoteSize       = 8.0
+	pdfNoteLineHeight = 3.6
+	pdfNoteGap        = 4.0 // Space between the text and the first footnote
+	pdfListIndent     = 6.0
+	pdfQuoteIndent    = 8.0
+	pdfMaxNoteRunes   = 300
+)
+
+// whitespaceRegex matches runs of whitespace collapsed in body text
+var whitespaceRegex = regexp.MustCompile(`\s+`)
+
+// inlineStyle is the character formatting of body text
+type inlineStyle struct {
+	bold   bool
+	italic bool
+	mono   bool
+	link   string
+}
+
+// tocEntry is a line of the table of contents
+type tocEntry struct {
+	title string
+	level int
+	link  int
+	page  int
+}
+
+// pdfWriter lays out a publication with gofpdf. Footnotes are placed at the
+// bottom of the page that cites them by raising the page's bottom margin as
+// they are added; a note that no longer fits moves to the next page.
+type pdfWriter struct {
+	pdf         *gofpdf.Fpdf
+	tr          func(string) string
+	notes       map[int]string
+	references  bool // Citation markers in the current part are not footnoted
+	pageNotes   map[int][]int
+	pending     []int
+	style       inlineStyle
+	indent      float64
+	atLineStart bool
+	lastSpace   float64
+	numbered    bool
+	pages       []int // Page of each table of contents entry, in order
+}
+
+// WritePDF writes the publication as a paginated A5 PDF with a cover, a
+// title page, a linked table of contents, bookmarks and footnoted citations.
+// It returns the number of pages.
+func WritePDF(w io.Writer, p *Publication) (int, error) {
+	if err := p.validate(); err != nil {
+		return 0, err
+	}
+	cover := pdfCoverImage(p.Cover)
+
+	// The first pass finds the page of every contents entry; the second
+	// prints them. Both passes lay out the same pages.
+	first, err := renderPDF(p, cover, nil)
+	if err != nil {
+		return 0, err
+	}
+	final, err := renderPDF(p, cover, first.pages)
+	if err != nil {
+		return 0, err
+	}
+
+	pages := final.pdf.PageNo()
+	if err := final.pdf.Output(w); err != nil {
+		return 0, fmt.Errorf("failed to write PDF: %w", err)
+	}
+	return pages, nil
+}
+
+// renderPDF lays out the whole publication. pages holds the contents page
+// numbers found by a previous pass, or nil.
+func renderPDF(p *Publication, cover []byte, pages []int) (*pdfWriter, error) {
+	pdf := gofpdf.New("P", "mm", pdfPageSize, "")
+	pdf.SetMargins(pdfMargin, pdfMargin, pdfMargin)
+	pdf.SetAutoPageBreak(true, pdfBottomMargin)
+	pdf.SetTitle(p.Title, true)
+	pdf.SetAuthor(p.Author, true)
+	pdf.SetSubject(p.Description, true)
+	pdf.SetCreator("Great Nigeria Library", true)
+	pdf.SetCreationDate(p.modified())
+
+	w := &pdfWriter{
+		pdf:         pdf,
+		tr:          pdf.UnicodeTranslatorFromDescriptor(""),
+		notes:       make(map[int]string, len(p.Notes)),
+		pageNotes:   make(map[int][]int),
+		atLineStart: true,
+	}
+	for number, text := range p.Notes {
+		if runes := []rune(text); len(runes) > pdfMaxNoteRunes {
+			text = string(runes[:pdfMaxNoteRunes]) + "..."
+		}
+		w.notes[number] = text
+	}
+	pdf.SetHeaderFunc(w.header)
+	pdf.SetFooterFunc(w.footer)
+
+	if cover != nil {
+		w.coverPage(cover)
+	}
+	w.titlePage(p)
+
+	var entries []tocEntry
+	for _, part := range p.Parts {
+		entries = append(entries, tocEntry{title: part.Title, level: 0, link: pdf.AddLink()})
+		for _, section := range part.Sections {
+			entries = append(entries, tocEntry{title: section.Title, level: 1, link: pdf.AddLink()})
+		}
+	}
+	for i := range entries {
+		if i < len(pages) {
+			entries[i].page = pages[i]
+		}
+	}
+	w.contents(entries)
+
+	entry := 0
+	for _, part := range p.Parts {
+		pdf.AddPage()
+		w.references = part.References
+		w.mark(entries[entry], 0)
+		entry++
+		w.heading(part.Title, 18, 8)
+		w.html(part.HTML)
+
+		for _, section := range part.Sections {
+			// Keep a section title with at least a few lines of its text
+			_, pageHeight := pdf.GetPageSize()
+			_, bottom := pdf.GetAutoPageBreak()
+			if pdf.GetY() > pageHeight-bottom-4*pdfLineHeight {
+				pdf.AddPage()
+			}
+			w.space(4)
+			w.mark(entries[entry], 1)
+			entry++
+			w.heading(section.Title, 13, 6.5)
+			w.html(section.HTML)
+		}
+	}
+
+	if pdf.Err() {
+		return nil, fmt.Errorf("failed to lay out PDF: %w", pdf.Error())
+	}
+	return w, nil
+}
+
+// mark records the page of a contents entry and adds its bookmark and link
+// target at the current position
+func (w *pdfWriter) mark(entry tocEntry, level int) {
+	w.pdf.Bookmark(w.tr(entry.title), level, -1)
+	w.pdf.SetLink(entry.link, -1, -1)
+	w.pages = append(w.pages, w.pdf.PageNo())
+}
+
+// coverPage draws the cover image scaled to fill the page
+func (w *pdfWriter) coverPage(cover []byte) {
+	w.pdf.AddPage()
+	info := w.pdf.RegisterImageOptionsReader("cover", gofpdf.ImageOptions{ImageType: "JPG"}, bytes.NewReader(cover))
+	if info == nil || info.Width() == 0 || info.Height() == 0 {
+		return
+	}
+
+	pageWidth, pageHeight := w.pdf.GetPageSize()
+	scale := pageWidth / info.Width()
+	if pageHeight/info.Height() < scale {
+		scale = pageHeight / info.Height()
+	}
+	width, height := info.Width()*scale, info.Height()*scale
+	w.pdf.ImageOptions("cover", (pageWidth-width)/2, (pageHeight-height)/2, width, height,
+		false, gofpdf.ImageOptions{ImageType: "JPG"}, 0, "")
+}
+
+// titlePage prints the title, subtitle, author and description
+func (w *pdfWriter) titlePage(p *Publication) {
+	pdf := w.pdf
+	pdf.AddPage()
+	_, pageHeight := pdf.GetPageSize()
+	pdf.SetY(pageHeight * 0.28)
+
+	pdf.SetFont("helvetica", "B", 22)
+	pdf.MultiCell(0, 9.5, w.tr(p.Title), "", "C", false)
+	if p.Subtitle != "" {
+		pdf.Ln(3)
+		pdf.SetFont("times", "I", 14)
+		pdf.MultiCell(0, 6.5, w.tr(p.Subtitle), "", "C", false)
+	}
+	if p.Author != "" {
+		pdf.Ln(12)
+		pdf.SetFont("helvetica", "", 12)
+		pdf.MultiCell(0, 6, w.tr(p.Author), "", "C", false)
+	}
+	if p.Description != "" {
+		pdf.Ln(16)
+		pdf.SetFont("times", "", 10)
+		pdf.MultiCell(0, 5, w.tr(p.Description), "", "C", false)
+	}
+}
+
+// contents prints the table of contents with linked entries
+func (w *pdfWriter) contents(entries []tocEntry) {
+	pdf := w.pdf
+	pdf.AddPage()
+	w.numbered = true // Pages are numbered from the contents onwards
+	w.heading("Contents", 18, 8)
+
+	pageWidth, _ := pdf.GetPageSize()
+	for _, entry := range entries {
+		indent := float64(entry.level) * 5
+		if entry.level == 0 {
+			pdf.SetFont("helvetica", "B", 10.5)
+			pdf.Ln(1.5)
+		} else {
+			pdf.SetFont("times", "", 10.5)
+		}
+
+		page := ""
+		if entry.page > 0 {
+			page = strconv.Itoa(entry.page)
+		}
+		titleWidth := pageWidth - 2*pdfMargin - indent - 12
+		pdf.SetX(pdfMargin + indent)
+		pdf.CellFormat(titleWidth, 5.5, w.fit(entry.title, titleWidth), "", 0, "L", false, entry.link, "")
+		pdf.CellFormat(12, 5.5, page, "", 1, "R", false, entry.link, "")
+	}
+}
+
+// fit shortens text with an ellipsis to fit width in the current font
+func (w *pdfWriter) fit(text string, width float64) string {
+	encoded := w.tr(text)
+	if w.pdf.GetStringWidth(encoded) <= width {
+		return encoded
+	}
+	runes := []rune(text)
+	for len(runes) > 0 {
+		runes = runes[:len(runes)-1]
+		encoded = w.tr(strings.TrimSpace(string(runes)) + "...")
+		if w.pdf.GetStringWidth(encoded) <= width {
+			break
+		}
+	}
+	return encoded
+}
+
+// heading prints a part, section or content heading
+func (w *pdfWriter) heading(text string, size, lineHeight float64) {
+	w.endBlock(0)
+	w.pdf.SetFont("helvetica", "B", size)
+	w.pdf.MultiCell(0, lineHeight, w.tr(strings.TrimSpace(text)), "", "L", false)
+	w.atLineStart = true
+	w.lastSpace = 0
+	w.space(lineHeight / 2)
+}
+
+// html lays out a fragment of content HTML
+func (w *pdfWriter) html(src string) {
+	if strings.TrimSpace(src) == "" {
+		return
+	}
+	w.style = inlineStyle{}
+	w.children(parseHTML(src), inlineStyle{})
+	w.endBlock(0)
+}
+
+func (w *pdfWriter) children(n *node, style inlineStyle) {
+	for _, child := range n.children {
+		w.node(child, style)
+	}
+}
+
+// node lays out an element or text node
+func (w *pdfWriter) node(n *node, style inlineStyle) {
+	if n.tag == "" {
+		w.text(n.text, style)
+		return
+	}
+
+	if level := headingLevel(n.tag); level > 0 {
+		w.space(2)
+		size := 12.5 - float64(level)*0.5
+		w.heading(textContent(n), size, size*0.5)
+		return
+	}
+
+	switch n.tag {
+	case "ul", "ol":
+		w.list(n, n.tag == "ol", style)
+	case "blockquote":
+		w.endBlock(2)
+		w.setIndent(w.indent + pdfQuoteIndent)
+		style.italic = true
+		w.children(n, style)
+		w.endBlock(0)
+		w.setIndent(w.indent - pdfQuoteIndent)
+		w.endBlock(2)
+	case "pre":
+		w.endBlock(2)
+		w.pdf.SetFont("courier", "", 8.5)
+		w.pdf.MultiCell(0, 4, w.tr(strings.TrimRight(textContent(n), "\n")), "", "L", false)
+		w.atLineStart = true
+		w.endBlock(2)
+	case "hr":
+		w.endBlock(2)
+		pageWidth, _ := w.pdf.GetPageSize()
+		y := w.pdf.GetY()
+		w.pdf.SetDrawColor(150, 150, 150)
+		w.pdf.Line(pageWidth/2-20, y, pageWidth/2+20, y)
+		w.space(4)
+	case "br":
+		w.pdf.Ln(pdfLineHeight)
+		w.atLineStart = true
+	case "tr":
+		w.endBlock(1)
+		cells := 0
+		for _, cell := range n.children {
+			if cell.tag != "td" && cell.tag != "th" {
+				continue
+			}
+			if cells > 0 {
+				w.text(" | ", style)
+			}
+			cellStyle := style
+			cellStyle.bold = style.bold || cell.tag == "th"
+			w.children(cell, cellStyle)
+			cells++
+		}
+		w.endBlock(1)
+	case "strong", "b":
+		style.bold = true
+		w.children(n, style)
+	case "em", "i":
+		style.italic = true
+		w.children(n, style)
+	case "code":
+		style.mono = true
+		w.children(n, style)
+	case "a":
+		style.link = externalLink(n.attrs["href"])
+		w.children(n, style)
+	default:
+		if blockTags[n.tag] {
+			w.endBlock(2.5)
+			w.children(n, style)
+			w.endBlock(2.5)
+			return
+		}
+		w.children(n, style)
+	}
+}
+
+// list lays out a bulleted or numbered list
+func (w *pdfWriter) list(n *node, ordered bool, style inlineStyle) {
+	w.endBlock(1.5)
+	w.setIndent(w.indent + pdfListIndent)
+
+	number := 0
+	for _, item := range n.children {
+		if item.tag != "li" {
+			w.node(item, style)
+			continue
+		}
+		number++
+		w.endBlock(0.8)
+
+		marker := "•"
+		if ordered {
+			marker = strconv.Itoa(number) + "."
+		}
+		w.setFont(inlineStyle{})
+		w.pdf.SetX(pdfMargin + w.indent - pdfListIndent)
+		w.pdf.CellFormat(pdfListIndent, pdfLineHeight, w.tr(marker), "", 0, "L", false, 0, "")
+		w.atLineStart = true
+		w.children(item, style)
+	}
+
+	w.endBlock(0)
+	w.setIndent(w.indent - pdfListIndent)
+	w.endBlock(1.5)
+}
+
+// text writes body text, turning citation markers into footnote references
+func (w *pdfWriter) text(s string, style inlineStyle) {
+	s = whitespaceRegex.ReplaceAllString(s, " ")
+	if w.atLineStart {
+		s = strings.TrimLeft(s, " ")
+	}
+	if s == "" {
+		return
+	}
+
+	notes := w.notes
+	if w.references {
+		notes = nil
+	}
+	for _, seg := range splitNotes(s, notes) {
+		if seg.note > 0 {
+			w.noteRef(seg.note)
+			continue
+		}
+		w.setFont(style)
+		if style.link != "" {
+			w.pdf.SetTextColor(30, 80, 160)
+			w.pdf.WriteLinkString(pdfLineHeight, w.tr(seg.text), style.link)
+			w.pdf.SetTextColor(0, 0, 0)
+		} else {
+			w.pdf.Write(pdfLineHeight, w.tr(seg.text))
+		}
+	}
+	w.atLineStart = false
+	w.lastSpace = 0
+}
+
+// noteRef writes a superscript reference and adds the note to the page
+func (w *pdfWriter) noteRef(number int) {
+	w.setFont(inlineStyle{})
+	w.pdf.SubWrite(pdfLineHeight, strconv.Itoa(number), 7, 4, 0, "")
+	w.atLineStart = false
+	w.addNote(number)
+}
+
+// addNote reserves space for a footnote at the bottom of the current page,
+// or carries it to the next page when the text has already reached the space
+func (w *pdfWriter) addNote(number int) {
+	page := w.pdf.PageNo()
+	for _, existing := range w.pageNotes[page] {
+		if existing == number {
+			return
+		}
+	}
+
+	height := w.noteHeight(number)
+	if len(w.pageNotes[page]) == 0 {
+		height += pdfNoteGap
+	}
+	_, pageHeight := w.pdf.GetPageSize()
+	_, bottom := w.pdf.GetAutoPageBreak()
+	if w.pdf.GetY()+pdfLineHeight > pageHeight-bottom-height {
+		w.pending = append(w.pending, number)
+		return
+	}
+
+	w.pageNotes[page] = append(w.pageNotes[page], number)
+	w.pdf.SetAutoPageBreak(true, bottom+height)
+}
+
+// noteHeight returns the height of a footnote
+func (w *pdfWriter) noteHeight(number int) float64 {
+	pageWidth, _ := w.pdf.GetPageSize()
+	w.pdf.SetFont("times", "", pdfNoteSize)
+	lines := w.pdf.SplitText(w.tr(w.noteText(number)), pageWidth-2*pdfMargin)
+	w.setFont(w.style)
+	return float64(len(lines)) * pdfNoteLineHeight
+}
+
+// noteText returns the numbered text of a footnote
+func (w *pdfWriter) noteText(number int) string {
+	return strconv.Itoa(number) + ". " + w.notes[number]
+}
+
+// header starts each page with the default bottom margin plus the notes
+// carried over from the previous page
+func (w *pdfWriter) header() {
+	w.pdf.SetAutoPageBreak(true, pdfBottomMargin)
+	pending := w.pending
+	w.pending = nil
+	for _, number := range pending {
+		w.addNote(number)
+	}
+}
+
+// footer prints the page's footnotes and number
+func (w *pdfWriter) footer() {
+	pdf := w.pdf
+	pageWidth, pageHeight := pdf.GetPageSize()
+	page := pdf.PageNo()
+
+	if notes := w.pageNotes[page]; len(notes) > 0 {
+		_, bottom := pdf.GetAutoPageBreak()
+		y := pageHeight - bottom + pdfNoteGap
+		pdf.SetDrawColor(150, 150, 150)
+		pdf.Line(pdfMargin, y-1.5, pdfMargin+35, y-1.5)
+		pdf.SetFont("times", "", pdfNoteSize)
+		pdf.SetXY(pdfMargin, y)
+		for _, number := range notes {
+			pdf.SetX(pdfMargin)
+			pdf.MultiCell(pageWidth-2*pdfMargin, pdfNoteLineHeight, w.tr(w.noteText(number)), "", "L", false)
+		}
+	}
+
+	if w.numbered {
+		pdf.SetFont("helvetica", "", 9)
+		pdf.SetXY(0, pageHeight-12)
+		pdf.CellFormat(pageWidth, 5, strconv.Itoa(page), "", 0, "C", false, 0, "")
+	}
+}
+
+// setFont selects the body font for a style
+func (w *pdfWriter) setFont(style inlineStyle) {
+	w.style = style
+	family := "times"
+	if style.mono {
+		family = "courier"
+	}
+	fontStyle := ""
+	if style.bold {
+		fontStyle += "B"
+	}
+	if style.italic {
+		fontStyle += "I"
+	}
+	size := pdfBodySize
+	if style.mono {
+		size = pdfBodySize - 1.5
+	}
+	w.pdf.SetFont(family, fontStyle, size)
+}
+
+// setIndent sets the left indent of body text
+func (w *pdfWriter) setIndent(indent float64) {
+	if indent < 0 {
+		indent = 0
+	}
+	w.indent = indent
+	w.pdf.SetLeftMargin(pdfMargin + indent)
+	if w.atLineStart {
+		w.pdf.SetX(pdfMargin + indent)
+	}
+}
+
+// endBlock ends the current line and leaves at least space below it
+func (w *pdfWriter) endBlock(space float64) {
+	if !w.atLineStart {
+		w.pdf.Ln(pdfLineHeight)
+		w.atLineStart = true
+	}
+	w.space(space)
+}
+
+// space adds vertical space, collapsing consecutive spacing
+func (w *pdfWriter) space(space float64) {
+	if space > w.lastSpace {
+		w.pdf.Ln(space - w.lastSpace)
+		w.lastSpace = space
+	}
+	w.pdf.SetX(pdfMargin + w.indent)
+}
+
+// pdfCoverImage converts a cover image to JPEG, which every PDF reader
+// supports. Covers that cannot be decoded, such as SVG, are left out.
+func pdfCoverImage(cover *Image) []byte {
+	if cover == nil {
+		return nil
+	}
+	src, _, err := image.Decode(bytes.NewReader(cover.Data))
+	if err != nil {
+		return nil
+	}
+
+	// Flatten transparency onto white
+	bounds := src.Bounds()
+	flat := image.NewRGBA(bounds)
+	draw.Draw(flat, bounds, &image.Uniform{C: color.White}, image.Point{}, draw.Src)
+	draw.Draw(flat, bounds, src, bounds.Min, draw.Over)
+
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, flat, &jpeg.Options{Quality: 90}); err != nil {
+		return nil
+	}
+	return buf.Bytes()
+}
@@ -0,0 +1,182 @@
+// Package export renders books, chapters and sections as EPUB 3 and PDF
+// editions for offline reading. Both formats are built from the same HTML
+// the content renderer produces for the web reader.
+package export
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Export formats
+const (
+	FormatEPUB = "epub"
+	FormatPDF  = "pdf"
+)
+
+// layoutVersion is part of every hash so that changes to the EPUB or PDF
+// layout invalidate cached exports
+const layoutVersion = "1"
+
+// ErrUnsupportedFormat is returned for formats other than EPUB and PDF
+var ErrUnsupportedFormat = errors.New("unsupported export format")
+
+// Publication is the content of an export: a whole book, or a single chapter
+// or section
+type Publication struct {
+	Identifier  string         `json:"identifier"` // Stable unique ID, e.g. urn:greatnigeria:book:3
+	Title       string         `json:"title"`
+	Subtitle    string         `json:"subtitle,omitempty"`
+	Author      string         `json:"author,omitempty"`
+	Description string         `json:"description,omitempty"`
+	Language    string         `json:"language,omitempty"` // BCP 47 tag, defaults to en
+	Modified    time.Time      `json:"modified"`
+	Cover       *Image         `json:"cover,omitempty"`
+	Parts       []Part         `json:"parts"`
+	Notes       map[int]string `json:"notes,omitempty"` // Footnote text by citation reference number
+}
+
+// Part is a front matter item, chapter or back matter item. Each part starts
+// on a new page.
+type Part struct {
+	Title      string    `json:"title"`
+	HTML       string    `json:"html,omitempty"` // Content before the first section
+	Sections   []Section `json:"sections,omitempty"`
+	References bool      `json:"references,omitempty"` // Bibliography; markers such as [1] are not footnoted
+}
+
+// partNotes returns the notes that citation markers in the part refer to
+func (p *Publication) partNotes(part Part) map[int]string {
+	if part.References {
+		return nil
+	}
+	return p.Notes
+}
+
+// Section is a titled section of a part
+type Section struct {
+	Title string `json:"title"`
+	HTML  string `json:"html"`
+}
+
+// Image is an embedded image such as the cover
+type Image struct {
+	MediaType string `json:"mediaType"` // image/jpeg, image/png, image/gif or image/svg+xml
+	Data      []byte `json:"data"`
+}
+
+// Hash returns a digest of everything that affects the output, so exports
+// can be cached by content
+func (p *Publication) Hash() (string, error) {
+	data, err := json.Marshal(p)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode publication: %w", err)
+	}
+	sum := sha256.Sum256(append([]byte(layoutVersion+"\n"), data...))
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// Write renders the publication in the given format. It returns the page
+// count for PDF and zero for EPUB, whose pages depend on the reader.
+func Write(w io.Writer, p *Publication, format string) (int, error) {
+	switch format {
+	case FormatEPUB:
+		return 0, WriteEPUB(w, p)
+	case FormatPDF:
+		return WritePDF(w, p)
+	default:
+		return 0, fmt.Errorf("%w: %s", ErrUnsupportedFormat, format)
+	}
+}
+
+// validate checks that the publication can be rendered
+func (p *Publication) validate() error {
+	if strings.TrimSpace(p.Title) == "" {
+		return errors.New("publication has no title")
+	}
+	if len(p.Parts) == 0 {
+		return errors.New("publication has no content")
+	}
+	return nil
+}
+
+// language returns the publication language or the default
+func (p *Publication) language() string {
+	if p.Language == "" {
+		return "en"
+	}
+	return p.Language
+}
+
+// modified returns the modification time, defaulting to now
+func (p *Publication) modified() time.Time {
+	if p.Modified.IsZero() {
+		return time.Now().UTC()
+	}
+	return p.Modified.UTC()
+}
+
+// noteRefRegex matches citation markers such as [3] and [1, 4]
+var noteRefRegex = regexp.MustCompile(`\[(\d+(?:,\s*\d+)*)\]`)
+
+// segment is a run of text or a footnote reference
+type segment struct {
+	text string
+	note int
+}
+
+// splitNotes splits text at citation markers whose numbers all have a
+// footnote. Other bracketed numbers are left as text.
+func splitNotes(text string, notes map[int]string) []segment {
+	if len(notes) == 0 {
+		return []segment{{text: text}}
+	}
+
+	var segments []segment
+	last := 0
+	for _, loc := range noteRefRegex.FindAllStringSubmatchIndex(text, -1) {
+		var numbers []int
+		for _, part := range strings.Split(text[loc[2]:loc[3]], ",") {
+			number, err := strconv.Atoi(strings.TrimSpace(part))
+			if err != nil || notes[number] == "" {
+				numbers = nil
+				break
+			}
+			numbers = append(numbers, number)
+		}
+		if numbers == nil {
+			continue
+		}
+
+		if loc[0] > last {
+			segments = append(segments, segment{text: text[last:loc[0]]})
+		}
+		for _, number := range numbers {
+			segments = append(segments, segment{note: number})
+		}
+		last = loc[1]
+	}
+	if last < len(text) {
+		segments = append(segments, segment{text: text[last:]})
+	}
+	return segments
+}
+
+// sortedNotes returns note numbers in ascending order
+func sortedNotes(numbers map[int]bool) []int {
+	sorted := make([]int, 0, len(numbers))
+	for number := range numbers {
+		sorted = append(sorted, number)
+	}
+	sort.Ints(sorted)
+	return sorted
+}
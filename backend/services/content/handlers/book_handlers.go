@@ -2,10 +2,12 @@ package handlers
 
 import (
 	"fmt"
+	"io"
 	"net/http"
 	"strconv"
 
 	"github.com/gin-gonic/gin"
+	"github.com/yerenwgventures/GreatNigeriaLibrary-Foundation/backend/services/content/importer"
 	"github.com/yerenwgventures/GreatNigeriaLibrary-Foundation/backend/services/content/service"
 )
 
@@ -65,7 +67,7 @@ func (h *BookHandlers) RegisterRoutes(router *gin.RouterGroup) {
 		// Admin endpoints
 		admin := books.Group("/admin")
 		{
-			admin.POST("/import/book1", h.ImportBook1)
+			admin.POST("/import", h.ImportManuscript)
 		}
 	}
 }
@@ -770,13 +772,61 @@ func (h *BookHandlers) GetFeedbackSummary(c *gin.Context) {
 	c.JSON(http.StatusOK, stats)
 }
 
-// ImportBook1 handles POST /api/books/admin/import/book1
-func (h *BookHandlers) ImportBook1(c *gin.Context) {
-	err := h.bookImportService.ImportBook1()
+// maxManuscriptSize limits manuscript uploads
+const maxManuscriptSize = 50 << 20
+
+// ImportManuscript handles POST /api/books/admin/import. The form field
+// "file" holds an EPUB, a DOCX or a zipped Markdown manuscript. With
+// dry_run=true the response lists the changes without applying them.
+func (h *BookHandlers) ImportManuscript(c *gin.Context) {
+	file, err := c.FormFile("file")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "File required"})
+		return
+	}
+	if file.Size > maxManuscriptSize {
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": "Manuscript is too large"})
+		return
+	}
+
+	options := service.ImportOptions{
+		DryRun:  c.Query("dry_run") == "true",
+		Publish: c.Query("publish") == "true",
+		Notes:   c.DefaultQuery("notes", "Imported from "+file.Filename),
+	}
+	if bookIDStr := c.Query("book_id"); bookIDStr != "" {
+		bookID, err := strconv.ParseUint(bookIDStr, 10, 32)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid book ID"})
+			return
+		}
+		options.BookID = uint(bookID)
+	}
+
+	fileReader, err := file.Open()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to read manuscript"})
+		return
+	}
+	defer fileReader.Close()
+
+	data, err := io.ReadAll(fileReader)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to read manuscript"})
+		return
+	}
+
+	manuscript, err := importer.Parse(file.Filename, data)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	report, err := h.bookImportService.ImportManuscript(manuscript, options)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to import Book 1"})
+		c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("Failed to import manuscript: %v", err)})
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"message": "Book 1 imported successfully"})
+	c.JSON(http.StatusOK, report)
 }
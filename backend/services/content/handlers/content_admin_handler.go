@@ -7,6 +7,7 @@ import (
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/yerenwgventures/GreatNigeriaLibrary-Foundation/backend/pkg/common/audit"
 	"github.com/yerenwgventures/GreatNigeriaLibrary-Foundation/backend/services/content/service"
 )
 
@@ -23,8 +24,8 @@ func NewContentAdminHandler(adminService service.ContentAdminService) *ContentAd
 }
 
 // RegisterRoutes registers the routes for content administration
-func (h *ContentAdminHandler) RegisterRoutes(router *gin.Engine) {
-	contentAdmin := router.Group("/api/v1/content-admin")
+func (h *ContentAdminHandler) RegisterRoutes(router *gin.RouterGroup) {
+	contentAdmin := router.Group("/content-admin")
 	{
 		// Import endpoints
 		contentAdmin.POST("/import/books", h.ImportBooks)
@@ -51,6 +52,7 @@ func (h *ContentAdminHandler) RegisterRoutes(router *gin.Engine) {
 		contentAdmin.POST("/publishing/books/:bookID/schedule", h.ScheduleBookPublishing)
 		contentAdmin.POST("/publishing/chapters/:chapterID/schedule", h.ScheduleChapterPublishing)
 		contentAdmin.POST("/publishing/sections/:sectionID/schedule", h.ScheduleSectionPublishing)
+		contentAdmin.POST("/publishing/:contentType/:contentID/schedule-unpublish", h.ScheduleUnpublishing)
 		contentAdmin.GET("/publishing/scheduled", h.GetScheduledContent)
 		contentAdmin.POST("/publishing/:contentType/:contentID/publish", h.PublishContent)
 		contentAdmin.POST("/publishing/:contentType/:contentID/unpublish", h.UnpublishContent)
@@ -469,7 +471,7 @@ func (h *ContentAdminHandler) RestoreBookRevision(c *gin.Context) {
 	}
 
 	// Restore revision
-	if err := h.adminService.RestoreBookRevision(uint(revisionID)); err != nil {
+	if err := h.adminService.RestoreBookRevision(audit.RequestContext(c), uint(revisionID)); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
@@ -488,7 +490,7 @@ func (h *ContentAdminHandler) RestoreChapterRevision(c *gin.Context) {
 	}
 
 	// Restore revision
-	if err := h.adminService.RestoreChapterRevision(uint(revisionID)); err != nil {
+	if err := h.adminService.RestoreChapterRevision(audit.RequestContext(c), uint(revisionID)); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
@@ -507,7 +509,7 @@ func (h *ContentAdminHandler) RestoreSectionRevision(c *gin.Context) {
 	}
 
 	// Restore revision
-	if err := h.adminService.RestoreSectionRevision(uint(revisionID)); err != nil {
+	if err := h.adminService.RestoreSectionRevision(audit.RequestContext(c), uint(revisionID)); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
@@ -616,7 +618,50 @@ func (h *ContentAdminHandler) ScheduleSectionPublishing(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"message": "Section publishing scheduled successfully", "publishDate": publishDate})
 }
 
-// GetScheduledContent gets all content scheduled to be published
+// ScheduleUnpublishingRequest represents a request to schedule unpublishing
+type ScheduleUnpublishingRequest struct {
+	UnpublishDate string `json:"unpublishDate" binding:"required"` // ISO 8601 date format
+}
+
+// ScheduleUnpublishing schedules content to be unpublished
+func (h *ContentAdminHandler) ScheduleUnpublishing(c *gin.Context) {
+	// Get content type and ID from path
+	contentType := c.Param("contentType")
+	contentID, err := strconv.ParseUint(c.Param("contentID"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid content ID"})
+		return
+	}
+
+	// Validate content type
+	if contentType != "book" && contentType != "chapter" && contentType != "section" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid content type"})
+		return
+	}
+
+	var req ScheduleUnpublishingRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Parse unpublish date
+	unpublishDate, err := time.Parse(time.RFC3339, req.UnpublishDate)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid unpublish date format. Use ISO 8601 format (YYYY-MM-DDTHH:MM:SSZ)"})
+		return
+	}
+
+	// Schedule unpublishing
+	if err := h.adminService.ScheduleUnpublishing(contentType, uint(contentID), unpublishDate); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": fmt.Sprintf("%s unpublishing scheduled successfully", contentType), "unpublishDate": unpublishDate})
+}
+
+// GetScheduledContent gets all content with a pending publish or unpublish schedule
 func (h *ContentAdminHandler) GetScheduledContent(c *gin.Context) {
 	// Get scheduled content
 	scheduled, err := h.adminService.GetScheduledContent()
@@ -646,7 +691,7 @@ func (h *ContentAdminHandler) PublishContent(c *gin.Context) {
 	}
 
 	// Publish content
-	if err := h.adminService.PublishContent(contentType, uint(contentID)); err != nil {
+	if err := h.adminService.PublishContent(audit.RequestContext(c), contentType, uint(contentID)); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
@@ -672,7 +717,7 @@ func (h *ContentAdminHandler) UnpublishContent(c *gin.Context) {
 	}
 
 	// Unpublish content
-	if err := h.adminService.UnpublishContent(contentType, uint(contentID)); err != nil {
+	if err := h.adminService.UnpublishContent(audit.RequestContext(c), contentType, uint(contentID)); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
@@ -1,11 +1,15 @@
 package handlers
 
 import (
+	"errors"
+	"fmt"
 	"net/http"
 	"strconv"
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/yerenwgventures/GreatNigeriaLibrary-Foundation/backend/services/content/export"
+	"github.com/yerenwgventures/GreatNigeriaLibrary-Foundation/backend/services/content/models"
 	"github.com/yerenwgventures/GreatNigeriaLibrary-Foundation/backend/services/content/service"
 )
 
@@ -25,15 +29,24 @@ func NewMediaHandler(mediaGenerator service.MediaGenerator) *MediaHandler {
 func (h *MediaHandler) RegisterRoutes(router *gin.RouterGroup) {
 	media := router.Group("/sections")
 	{
-		media.POST("/:id/audio", h.GenerateAudio)
 		media.POST("/:id/photos", h.GeneratePhotos)
 		media.POST("/:id/video", h.GenerateVideo)
 		media.POST("/:id/pdf", h.GeneratePDF)
 		media.GET("/:id/share", h.GetShareableLink)
 	}
+
+	router.GET("/media/jobs/:id", h.GetMediaJob)
+}
+
+// RegisterAuthenticatedRoutes registers the routes that build editions and
+// queue audio, which is costly. They must be behind AuthRequired.
+func (h *MediaHandler) RegisterAuthenticatedRoutes(router *gin.RouterGroup) {
+	router.POST("/sections/:id/audio", h.GenerateAudio)
+	router.POST("/export/:contentType/:id", h.ExportContent)
 }
 
-// GenerateAudio handles POST /api/books/sections/:id/audio
+// GenerateAudio handles POST /api/sections/:id/audio. Audio is generated in
+// the background: the response holds a job to poll until it is completed.
 func (h *MediaHandler) GenerateAudio(c *gin.Context) {
 	idStr := c.Param("id")
 	id, err := strconv.ParseUint(idStr, 10, 32)
@@ -42,18 +55,65 @@ func (h *MediaHandler) GenerateAudio(c *gin.Context) {
 		return
 	}
 
-	audioURL, duration, err := h.mediaGenerator.GenerateAudioFromText(uint(id))
+	job, err := h.mediaGenerator.RequestAudio(uint(id))
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate audio"})
+		switch {
+		case errors.Is(err, service.ErrNoTTSEngine):
+			c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Audio generation is not available"})
+		case errors.Is(err, service.ErrContentNotPublished):
+			c.JSON(http.StatusNotFound, gin.H{"error": "Section not found"})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate audio"})
+		}
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{
-		"audioUrl":    audioURL,
-		"duration":    duration,
-		"title":       "Audio Book",
-		"generatedAt": time.Now().Format(time.RFC3339),
-	})
+	status := http.StatusAccepted
+	if job.Status == models.MediaJobCompleted {
+		status = http.StatusOK
+	}
+	c.JSON(status, mediaJobResponse(job))
+}
+
+// GetMediaJob handles GET /api/media/jobs/:id
+func (h *MediaHandler) GetMediaJob(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid job ID"})
+		return
+	}
+
+	job, err := h.mediaGenerator.GetMediaJob(uint(id))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Job not found"})
+		return
+	}
+	c.JSON(http.StatusOK, mediaJobResponse(job))
+}
+
+// mediaJobResponse describes a media job and, once it is completed, its
+// result
+func mediaJobResponse(job *models.MediaJob) gin.H {
+	response := gin.H{
+		"jobId":     job.ID,
+		"status":    job.Status,
+		"sectionId": job.SectionID,
+		"statusUrl": fmt.Sprintf("/api/media/jobs/%d", job.ID),
+		"createdAt": job.CreatedAt.Format(time.RFC3339),
+	}
+	switch job.Status {
+	case models.MediaJobCompleted:
+		response["audioUrl"] = job.URL
+		response["timingUrl"] = job.TimingURL
+		response["duration"] = job.Duration
+		response["title"] = "Audio Book"
+		if job.CompletedAt != nil {
+			response["generatedAt"] = job.CompletedAt.Format(time.RFC3339)
+		}
+	case models.MediaJobFailed:
+		response["error"] = job.Error
+	}
+	return response
 }
 
 // GeneratePhotos handles POST /api/books/sections/:id/photos
@@ -145,6 +205,10 @@ func (h *MediaHandler) GetShareableLink(c *gin.Context) {
 	}
 
 	shareableLink, mediaURL, err := h.mediaGenerator.GetShareableLink(uint(id), mediaType)
+	if errors.Is(err, service.ErrMediaPending) {
+		c.JSON(http.StatusAccepted, gin.H{"error": "Media is still being generated"})
+		return
+	}
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate shareable link"})
 		return
@@ -155,3 +219,31 @@ func (h *MediaHandler) GetShareableLink(c *gin.Context) {
 		"mediaUrl":      mediaURL,
 	})
 }
+
+// ExportContent handles POST /api/export/:contentType/:id?format=epub|pdf
+func (h *MediaHandler) ExportContent(c *gin.Context) {
+	contentType := c.Param("contentType")
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid content ID"})
+		return
+	}
+	format := c.DefaultQuery("format", export.FormatEPUB)
+
+	result, err := h.mediaGenerator.ExportContent(contentType, uint(id), format)
+	if err != nil {
+		switch {
+		case errors.Is(err, export.ErrUnsupportedFormat):
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Format must be epub or pdf"})
+		case errors.Is(err, service.ErrInvalidExportContentType):
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Content type must be book, chapter or section"})
+		case errors.Is(err, service.ErrContentNotPublished):
+			c.JSON(http.StatusNotFound, gin.H{"error": "Content not found"})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to export content"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
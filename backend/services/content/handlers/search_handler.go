@@ -0,0 +1,85 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/yerenwgventures/GreatNigeriaLibrary-Foundation/backend/services/content/search"
+	"github.com/yerenwgventures/GreatNigeriaLibrary-Foundation/backend/services/content/service"
+)
+
+// SearchHandler handles full-text search requests
+type SearchHandler struct {
+	searchService service.SearchService
+}
+
+// NewSearchHandler creates a new search handler
+func NewSearchHandler(searchService service.SearchService) *SearchHandler {
+	return &SearchHandler{
+		searchService: searchService,
+	}
+}
+
+// RegisterRoutes registers the search routes
+func (h *SearchHandler) RegisterRoutes(router *gin.RouterGroup) {
+	router.GET("/search", h.Search)
+}
+
+// Search runs a full-text query across published content.
+// Query parameters: q, book_id (repeatable), type (repeatable), tag (repeatable), page, page_size
+func (h *SearchHandler) Search(c *gin.Context) {
+	query := search.Query{
+		Text: c.Query("q"),
+		Tags: c.QueryArray("tag"),
+	}
+
+	for _, value := range c.QueryArray("book_id") {
+		bookID, err := strconv.ParseUint(value, 10, 64)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid book ID"})
+			return
+		}
+		query.BookIDs = append(query.BookIDs, uint(bookID))
+	}
+
+	for _, value := range c.QueryArray("type") {
+		contentType := search.ContentType(value)
+		switch contentType {
+		case search.ContentTypeBook, search.ContentTypeChapter, search.ContentTypeSection,
+			search.ContentTypeFrontMatter, search.ContentTypeBackMatter,
+			search.ContentTypeCitation, search.ContentTypeDiscussion:
+			query.ContentTypes = append(query.ContentTypes, contentType)
+		default:
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Invalid content type: %s", value)})
+			return
+		}
+	}
+
+	if value := c.Query("page"); value != "" {
+		page, err := strconv.Atoi(value)
+		if err != nil || page < 1 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid page"})
+			return
+		}
+		query.Page = page
+	}
+
+	if value := c.Query("page_size"); value != "" {
+		pageSize, err := strconv.Atoi(value)
+		if err != nil || pageSize < 1 || pageSize > search.MaxPageSize {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("page_size must be between 1 and %d", search.MaxPageSize)})
+			return
+		}
+		query.PageSize = pageSize
+	}
+
+	results, err := h.searchService.Search(query)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("Failed to search content: %v", err)})
+		return
+	}
+
+	c.JSON(http.StatusOK, results)
+}
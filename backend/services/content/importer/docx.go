@@ -0,0 +1,220 @@
+package importer
+
+import (
+	"archive/zip"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// docxCoreProperties is docProps/core.xml
+type docxCoreProperties struct {
+	Title       string `xml:"title"`
+	Creator     string `xml:"creator"`
+	Description string `xml:"description"`
+}
+
+// docxStyles is word/styles.xml, used to map style IDs to style names
+type docxStyles struct {
+	Styles []struct {
+		ID   string `xml:"styleId,attr"`
+		Name struct {
+			Val string `xml:"val,attr"`
+		} `xml:"name"`
+	} `xml:"style"`
+}
+
+// docxParagraph is a paragraph of word/document.xml reduced to its style,
+// list membership and Markdown text
+type docxParagraph struct {
+	style string
+	list  bool
+	text  string
+}
+
+// ParseDOCX reads a manuscript from a Word document. The Title and Subtitle
+// styles set the book title and subtitle, Heading 1 starts a chapter (or
+// front or back matter such as "Preface"), and Heading 2 starts a section.
+func ParseDOCX(r io.ReaderAt, size int64) (*Manuscript, error) {
+	zr, err := zip.NewReader(r, size)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open DOCX: %w", err)
+	}
+
+	m := &Manuscript{}
+	var core docxCoreProperties
+	if err := decodeZipXML(zr, "docProps/core.xml", &core); err == nil {
+		m.Title, m.Author, m.Description = core.Title, core.Creator, core.Description
+	}
+
+	styleNames := make(map[string]string)
+	var styles docxStyles
+	if err := decodeZipXML(zr, "word/styles.xml", &styles); err == nil {
+		for _, s := range styles.Styles {
+			styleNames[s.ID] = strings.ToLower(s.Name.Val)
+		}
+	}
+
+	paragraphs, err := readDocxParagraphs(zr)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		docs    []document
+		current *strings.Builder
+		preface strings.Builder // text before the first chapter heading
+	)
+	for _, p := range paragraphs {
+		style := p.style
+		if name, ok := styleNames[style]; ok {
+			style = name
+		}
+		style = strings.ReplaceAll(strings.ToLower(style), " ", "")
+
+		switch style {
+		case "title":
+			if p.text != "" {
+				m.Title = p.text
+			}
+			continue
+		case "subtitle":
+			m.Subtitle = p.text
+			continue
+		case "heading1":
+			docs = append(docs, document{})
+			current = &strings.Builder{}
+			current.WriteString("# " + p.text + "\n\n")
+			continue
+		}
+
+		target := current
+		if target == nil {
+			target = &preface
+		}
+		switch {
+		case style == "heading2":
+			target.WriteString("## " + p.text + "\n\n")
+		case strings.HasPrefix(style, "heading"):
+			target.WriteString("### " + p.text + "\n\n")
+		case p.list:
+			target.WriteString("- " + p.text + "\n")
+		case p.text != "":
+			target.WriteString(p.text + "\n\n")
+		}
+		if current != nil {
+			docs[len(docs)-1].Body = current.String()
+		}
+	}
+
+	if m.Description == "" {
+		m.Description = strings.TrimSpace(preface.String())
+	}
+	if err := buildManuscript(m, docs); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// readDocxParagraphs walks word/document.xml and returns its paragraphs with
+// bold and italic runs converted to Markdown
+func readDocxParagraphs(zr *zip.Reader) ([]docxParagraph, error) {
+	f, err := zr.Open("word/document.xml")
+	if err != nil {
+		return nil, fmt.Errorf("failed to open word/document.xml: %w", err)
+	}
+	defer f.Close()
+
+	var (
+		paragraphs   []docxParagraph
+		paragraph    *docxParagraph
+		text         strings.Builder
+		run          strings.Builder
+		bold, italic bool
+		inText       bool
+	)
+
+	// flushRun appends the current run with its formatting
+	flushRun := func() {
+		s := run.String()
+		run.Reset()
+		if strings.TrimSpace(s) == "" {
+			text.WriteString(s)
+			return
+		}
+		marker := ""
+		if bold {
+			marker += "**"
+		}
+		if italic {
+			marker += "*"
+		}
+		trimmed := strings.TrimSpace(s)
+		lead := s[:strings.Index(s, trimmed)]
+		trail := s[len(lead)+len(trimmed):]
+		text.WriteString(lead + marker + trimmed + marker + trail)
+	}
+
+	decoder := xml.NewDecoder(f)
+	for {
+		token, err := decoder.Token()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse word/document.xml: %w", err)
+		}
+
+		switch t := token.(type) {
+		case xml.StartElement:
+			switch t.Name.Local {
+			case "p":
+				paragraph = &docxParagraph{}
+				text.Reset()
+			case "pStyle":
+				if paragraph != nil {
+					paragraph.style = attr(t, "val")
+				}
+			case "numPr":
+				if paragraph != nil {
+					paragraph.list = true
+				}
+			case "r":
+				bold, italic = false, false
+				run.Reset()
+			case "b":
+				bold = attr(t, "val") != "false" && attr(t, "val") != "0"
+			case "i":
+				italic = attr(t, "val") != "false" && attr(t, "val") != "0"
+			case "t":
+				inText = true
+			case "tab":
+				run.WriteString(" ")
+			case "br":
+				run.WriteString("\n")
+			}
+
+		case xml.EndElement:
+			switch t.Name.Local {
+			case "t":
+				inText = false
+			case "r":
+				flushRun()
+			case "p":
+				if paragraph != nil {
+					paragraph.text = strings.TrimSpace(text.String())
+					paragraphs = append(paragraphs, *paragraph)
+					paragraph = nil
+				}
+			}
+
+		case xml.CharData:
+			if inText {
+				run.Write(t)
+			}
+		}
+	}
+	return paragraphs, nil
+}
@@ -0,0 +1,130 @@
+package importer
+
+import (
+	"archive/zip"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+)
+
+// epubContainer is META-INF/container.xml
+type epubContainer struct {
+	Rootfiles []struct {
+		FullPath string `xml:"full-path,attr"`
+	} `xml:"rootfiles>rootfile"`
+}
+
+// epubPackage is the OPF package document
+type epubPackage struct {
+	Metadata struct {
+		Titles       []string `xml:"title"`
+		Creators     []string `xml:"creator"`
+		Descriptions []string `xml:"description"`
+	} `xml:"metadata"`
+	Manifest []struct {
+		ID         string `xml:"id,attr"`
+		Href       string `xml:"href,attr"`
+		MediaType  string `xml:"media-type,attr"`
+		Properties string `xml:"properties,attr"`
+	} `xml:"manifest>item"`
+	Spine []struct {
+		IDRef  string `xml:"idref,attr"`
+		Linear string `xml:"linear,attr"`
+	} `xml:"spine>itemref"`
+}
+
+// ParseEPUB reads a manuscript from an EPUB. Each document in the reading
+// order becomes a chapter, or front or back matter when its title is one of
+// the usual names such as "Preface" or "About the Author". Level-two
+// headings split chapters into sections.
+func ParseEPUB(r io.ReaderAt, size int64) (*Manuscript, error) {
+	zr, err := zip.NewReader(r, size)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open EPUB: %w", err)
+	}
+
+	var container epubContainer
+	if err := decodeZipXML(zr, "META-INF/container.xml", &container); err != nil {
+		return nil, err
+	}
+	if len(container.Rootfiles) == 0 {
+		return nil, errors.New("EPUB container lists no package document")
+	}
+	opfPath := container.Rootfiles[0].FullPath
+
+	var pkg epubPackage
+	if err := decodeZipXML(zr, opfPath, &pkg); err != nil {
+		return nil, err
+	}
+
+	m := &Manuscript{
+		Title:       first(pkg.Metadata.Titles),
+		Author:      strings.Join(pkg.Metadata.Creators, ", "),
+		Description: first(pkg.Metadata.Descriptions),
+	}
+
+	type manifestItem struct {
+		href, mediaType, properties string
+	}
+	items := make(map[string]manifestItem, len(pkg.Manifest))
+	for _, item := range pkg.Manifest {
+		items[item.ID] = manifestItem{item.Href, item.MediaType, item.Properties}
+	}
+
+	var docs []document
+	for _, ref := range pkg.Spine {
+		item, ok := items[ref.IDRef]
+		if !ok || ref.Linear == "no" || strings.Contains(item.properties, "nav") {
+			continue
+		}
+		if item.mediaType != "application/xhtml+xml" && item.mediaType != "text/html" {
+			continue
+		}
+
+		name := path.Join(path.Dir(opfPath), item.href)
+		f, err := zr.Open(name)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open %s: %w", name, err)
+		}
+		body, err := htmlToMarkdown(f)
+		f.Close()
+		if err != nil {
+			return nil, fmt.Errorf("failed to convert %s: %w", name, err)
+		}
+		docs = append(docs, document{Body: body})
+	}
+
+	if err := buildManuscript(m, docs); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// decodeZipXML decodes an XML file from a zip archive
+func decodeZipXML(zr *zip.Reader, name string, v interface{}) error {
+	f, err := zr.Open(name)
+	if err != nil {
+		return fmt.Errorf("failed to open %s: %w", name, err)
+	}
+	defer f.Close()
+
+	decoder := xml.NewDecoder(f)
+	decoder.Strict = false
+	if err := decoder.Decode(v); err != nil {
+		return fmt.Errorf("failed to parse %s: %w", name, err)
+	}
+	return nil
+}
+
+// first returns the first non-empty value
+func first(values []string) string {
+	for _, v := range values {
+		if v = strings.TrimSpace(v); v != "" {
+			return v
+		}
+	}
+	return ""
+}
@@ -0,0 +1,265 @@
+package importer
+
+import (
+	"encoding/xml"
+	"errors"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var blankLinesRegex = regexp.MustCompile(`\n{3,}`)
+
+// markdownWriter accumulates Markdown while tracking whether the output is
+// at the start of a line, so that collapsed whitespace never indents text
+type markdownWriter struct {
+	sb          strings.Builder
+	atLineStart bool
+}
+
+func newMarkdownWriter() *markdownWriter {
+	return &markdownWriter{atLineStart: true}
+}
+
+// block ends the current paragraph
+func (w *markdownWriter) block() {
+	if w.sb.Len() > 0 {
+		w.sb.WriteString("\n\n")
+	}
+	w.atLineStart = true
+}
+
+// newline ends the current line
+func (w *markdownWriter) newline() {
+	w.sb.WriteByte('\n')
+	w.atLineStart = true
+}
+
+// prefix writes markup such as "## " or "- " at the start of a line
+func (w *markdownWriter) prefix(s string) {
+	w.sb.WriteString(s)
+	w.atLineStart = true
+}
+
+// markup writes inline markup such as "**"
+func (w *markdownWriter) markup(s string) {
+	w.sb.WriteString(s)
+	w.atLineStart = false
+}
+
+// text writes character data with runs of whitespace collapsed
+func (w *markdownWriter) text(s string) {
+	collapsed := strings.Join(strings.Fields(s), " ")
+	if collapsed == "" {
+		if s != "" && !w.atLineStart {
+			w.sb.WriteByte(' ')
+		}
+		return
+	}
+	if !w.atLineStart && s[0] <= ' ' {
+		w.sb.WriteByte(' ')
+	}
+	w.sb.WriteString(collapsed)
+	if s[len(s)-1] <= ' ' {
+		w.sb.WriteByte(' ')
+	}
+	w.atLineStart = false
+}
+
+// raw writes preformatted text unchanged
+func (w *markdownWriter) raw(s string) {
+	w.sb.WriteString(s)
+	w.atLineStart = strings.HasSuffix(s, "\n")
+}
+
+// String returns the Markdown with trailing spaces and extra blank lines
+// removed
+func (w *markdownWriter) String() string {
+	lines := strings.Split(w.sb.String(), "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimRight(line, " \t")
+	}
+	return strings.TrimSpace(blankLinesRegex.ReplaceAllString(strings.Join(lines, "\n"), "\n\n"))
+}
+
+// htmlToMarkdown converts an XHTML document, such as an EPUB chapter, to
+// Markdown. Only the structure the importer needs is kept: headings,
+// paragraphs, emphasis, lists, quotes, code and external links.
+func htmlToMarkdown(r io.Reader) (string, error) {
+	decoder := xml.NewDecoder(r)
+	decoder.Strict = false
+	decoder.AutoClose = xml.HTMLAutoClose
+	decoder.Entity = xml.HTMLEntity
+
+	var (
+		out       = newMarkdownWriter()
+		writers   []*markdownWriter // saved writers while inside blockquotes
+		lists     []string          // "ul" or "ol" for each open list
+		counters  []int
+		links     []string
+		skipDepth int
+		preDepth  int
+	)
+
+	for {
+		token, err := decoder.Token()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return "", err
+		}
+
+		switch t := token.(type) {
+		case xml.StartElement:
+			name := strings.ToLower(t.Name.Local)
+			if skipDepth > 0 {
+				skipDepth++
+				continue
+			}
+			switch name {
+			case "head", "script", "style", "nav":
+				skipDepth = 1
+			case "h1", "h2", "h3", "h4", "h5", "h6":
+				out.block()
+				out.prefix(strings.Repeat("#", int(name[1]-'0')) + " ")
+			case "p", "div", "section", "article", "table", "tr":
+				out.block()
+			case "br":
+				out.newline()
+			case "em", "i":
+				out.markup("*")
+			case "strong", "b":
+				out.markup("**")
+			case "code":
+				if preDepth == 0 {
+					out.markup("`")
+				}
+			case "pre":
+				preDepth++
+				out.block()
+				out.raw("```\n")
+			case "ul", "ol":
+				if len(lists) == 0 {
+					out.block()
+				}
+				lists = append(lists, name)
+				counters = append(counters, 0)
+			case "li":
+				out.newline()
+				depth := len(lists) - 1
+				if depth < 0 {
+					out.prefix("- ")
+					break
+				}
+				indent := strings.Repeat("  ", depth)
+				if lists[depth] == "ol" {
+					counters[depth]++
+					out.prefix(indent + strconv.Itoa(counters[depth]) + ". ")
+				} else {
+					out.prefix(indent + "- ")
+				}
+			case "blockquote":
+				writers = append(writers, out)
+				out = newMarkdownWriter()
+			case "a":
+				href := attr(t, "href")
+				if strings.Contains(href, "://") || strings.HasPrefix(href, "mailto:") {
+					out.markup("[")
+				} else {
+					href = ""
+				}
+				links = append(links, href)
+			}
+
+		case xml.EndElement:
+			name := strings.ToLower(t.Name.Local)
+			if skipDepth > 0 {
+				skipDepth--
+				continue
+			}
+			switch name {
+			case "h1", "h2", "h3", "h4", "h5", "h6", "p", "div", "section", "article", "table", "tr":
+				out.block()
+			case "em", "i":
+				out.markup("*")
+			case "strong", "b":
+				out.markup("**")
+			case "code":
+				if preDepth == 0 {
+					out.markup("`")
+				}
+			case "pre":
+				if preDepth > 0 {
+					preDepth--
+				}
+				if !out.atLineStart {
+					out.newline()
+				}
+				out.raw("```")
+				out.block()
+			case "ul", "ol":
+				if len(lists) > 0 {
+					lists = lists[:len(lists)-1]
+					counters = counters[:len(counters)-1]
+				}
+				if len(lists) == 0 {
+					out.block()
+				}
+			case "blockquote":
+				if len(writers) == 0 {
+					break
+				}
+				quoted := out.String()
+				out = writers[len(writers)-1]
+				writers = writers[:len(writers)-1]
+				out.block()
+				for i, line := range strings.Split(quoted, "\n") {
+					if i > 0 {
+						out.newline()
+					}
+					out.prefix(strings.TrimRight("> "+line, " "))
+				}
+				out.block()
+			case "a":
+				if len(links) == 0 {
+					break
+				}
+				href := links[len(links)-1]
+				links = links[:len(links)-1]
+				if href != "" {
+					out.markup("](" + href + ")")
+				}
+			}
+
+		case xml.CharData:
+			if skipDepth > 0 {
+				continue
+			}
+			if preDepth > 0 {
+				out.raw(string(t))
+			} else {
+				out.text(string(t))
+			}
+		}
+	}
+
+	for len(writers) > 0 {
+		quoted := out.String()
+		out = writers[len(writers)-1]
+		writers = writers[:len(writers)-1]
+		out.raw(quoted)
+	}
+	return out.String(), nil
+}
+
+// attr returns the value of an element attribute
+func attr(element xml.StartElement, name string) string {
+	for _, a := range element.Attr {
+		if strings.EqualFold(a.Name.Local, name) {
+			return a.Value
+		}
+	}
+	return ""
+}
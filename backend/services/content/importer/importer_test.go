@@ -0,0 +1,169 @@
+package importer
+
+import (
+	"archive/zip"
+	"bytes"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseMarkdown(t *testing.T) {
+	fsys := fstest.MapFS{
+		"manuscript/book.yaml": {Data: []byte(`
+title: Awakening the Giant
+author: Great Nigeria Network
+citations:
+  - key: achebe1983
+    author: Achebe, Chinua
+    year: "1983"
+    title: The Trouble with Nigeria
+    source: Heinemann
+    type: book
+`)},
+		"manuscript/00-preface.md": {Data: []byte("# Preface\n\nA nation at the crossroads.\n")},
+		"manuscript/01-history.md": {Data: []byte(`---
+description: How we got here
+---
+# Historical Roots
+
+Chapter overview.
+
+## Colonial Legacy
+
+As Achebe argued [@achebe1983], the trouble is leadership.
+
+` + "```interactive" + `
+type: reflection
+title: Reflect
+points: 10
+content:
+  prompt: What did you learn?
+` + "```" + `
+
+## Military Rule
+
+` + "```go" + `
+## not a section
+` + "```" + `
+`)},
+		"manuscript/99-about.md": {Data: []byte("---\ntype: about_author\n---\nWritten by the network.\n")},
+	}
+
+	m, err := ParseMarkdown(fsys)
+	require.NoError(t, err)
+
+	assert.Equal(t, "Awakening the Giant", m.Title)
+	assert.Equal(t, "A nation at the crossroads.", m.FrontMatter[MatterPreface])
+	assert.Equal(t, "Written by the network.", m.BackMatter[MatterAboutAuthor])
+
+	require.Len(t, m.Chapters, 1)
+	chapter := m.Chapters[0]
+	assert.Equal(t, 1, chapter.Number)
+	assert.Equal(t, "Historical Roots", chapter.Title)
+	assert.Equal(t, "How we got here", chapter.Description)
+	assert.Equal(t, "Chapter overview.", chapter.Content)
+
+	require.Len(t, chapter.Sections, 2)
+	section := chapter.Sections[0]
+	assert.Equal(t, "Colonial Legacy", section.Title)
+	assert.Contains(t, section.Content, "As Achebe argued [1], the trouble")
+	assert.Contains(t, section.Content, "{{interactive:1}}")
+	assert.Equal(t, []string{"achebe1983"}, section.CitationKeys)
+	require.Len(t, section.Elements, 1)
+	assert.Equal(t, "reflection", section.Elements[0].Type)
+	assert.Equal(t, 10, section.Elements[0].Points)
+	assert.Equal(t, "What did you learn?", section.Elements[0].Content["prompt"])
+
+	assert.Contains(t, chapter.Sections[1].Content, "## not a section")
+}
+
+func TestParseMarkdownRejectsUnknownCitations(t *testing.T) {
+	_, err := ParseMarkdown(fstest.MapFS{
+		"book.yaml":     {Data: []byte("title: Test\n")},
+		"01-chapter.md": {Data: []byte("# One\n\n## Start\n\nSee [@missing].\n")},
+	})
+	assert.ErrorContains(t, err, "unknown citation keys: missing")
+}
+
+func TestParseEPUB(t *testing.T) {
+	data := zipFiles(t, map[string]string{
+		"META-INF/container.xml": `<container><rootfiles><rootfile full-path="OEBPS/content.opf"/></rootfiles></container>`,
+		"OEBPS/content.opf": `<package>
+<metadata><dc:title>The Masterplan</dc:title><dc:creator>Great Nigeria Network</dc:creator></metadata>
+<manifest>
+  <item id="nav" href="nav.xhtml" media-type="application/xhtml+xml" properties="nav"/>
+  <item id="intro" href="text/intro.xhtml" media-type="application/xhtml+xml"/>
+  <item id="ch1" href="text/ch1.xhtml" media-type="application/xhtml+xml"/>
+</manifest>
+<spine><itemref idref="nav"/><itemref idref="intro"/><itemref idref="ch1"/></spine>
+</package>`,
+		"OEBPS/nav.xhtml":        `<html><body><h1>Contents</h1></body></html>`,
+		"OEBPS/text/intro.xhtml": `<html><body><h1>Introduction</h1><p>Why this book.</p></body></html>`,
+		"OEBPS/text/ch1.xhtml": `<html><head><title>x</title></head><body>
+<h1>Governance</h1>
+<h2>Institutions</h2>
+<p>Strong <em>institutions</em> &amp; <a href="https://example.com">rules</a>.</p>
+<ul><li>One</li><li>Two</li></ul>
+</body></html>`,
+	})
+
+	m, err := ParseEPUB(bytes.NewReader(data), int64(len(data)))
+	require.NoError(t, err)
+
+	assert.Equal(t, "The Masterplan", m.Title)
+	assert.Equal(t, "Great Nigeria Network", m.Author)
+	assert.Equal(t, "Why this book.", m.FrontMatter[MatterIntroduction])
+	require.Len(t, m.Chapters, 1)
+	assert.Equal(t, "Governance", m.Chapters[0].Title)
+	require.Len(t, m.Chapters[0].Sections, 1)
+	assert.Equal(t, "Strong *institutions* & [rules](https://example.com).\n\n- One\n- Two",
+		m.Chapters[0].Sections[0].Content)
+}
+
+func TestParseDOCX(t *testing.T) {
+	paragraph := func(style, text string) string {
+		return `<w:p><w:pPr><w:pStyle w:val="` + style + `"/></w:pPr><w:r><w:t>` + text + `</w:t></w:r></w:p>`
+	}
+	data := zipFiles(t, map[string]string{
+		"word/document.xml": `<w:document xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main"><w:body>` +
+			paragraph("Title", "Citizen Action") +
+			paragraph("Heading1", "Acknowledgements") +
+			paragraph("", "Thanks to all contributors.") +
+			paragraph("Heading1", "Getting Started") +
+			paragraph("Heading2", "First Steps") +
+			`<w:p><w:r><w:t xml:space="preserve">Join a </w:t></w:r><w:r><w:rPr><w:b/></w:rPr><w:t>local group</w:t></w:r></w:p>` +
+			`</w:body></w:document>`,
+	})
+
+	m, err := ParseDOCX(bytes.NewReader(data), int64(len(data)))
+	require.NoError(t, err)
+
+	assert.Equal(t, "Citizen Action", m.Title)
+	assert.Equal(t, "Thanks to all contributors.", m.FrontMatter[MatterAcknowledgements])
+	require.Len(t, m.Chapters, 1)
+	require.Len(t, m.Chapters[0].Sections, 1)
+	assert.Equal(t, "First Steps", m.Chapters[0].Sections[0].Title)
+	assert.Equal(t, "Join a **local group**", m.Chapters[0].Sections[0].Content)
+}
+
+func TestDiff(t *testing.T) {
+	assert.Empty(t, Diff("a\nb", "a\nb"))
+	assert.Equal(t, " a\n-b\n+c\n d\n", Diff("a\nb\nd", "a\nc\nd"))
+}
+
+func zipFiles(t *testing.T, files map[string]string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for name, content := range files {
+		w, err := zw.Create(name)
+		require.NoError(t, err)
+		_, err = w.Write([]byte(content))
+		require.NoError(t, err)
+	}
+	require.NoError(t, zw.Close())
+	return buf.Bytes()
+}
@@ -0,0 +1,215 @@
+// Package importer parses book manuscripts into a format-independent
+// structure that the content service can diff against and import into the
+// database. Manuscripts can be a directory of Markdown files with YAML front
+// matter, an EPUB or a DOCX document.
+package importer
+
+import (
+	"archive/zip"
+	"bytes"
+	"errors"
+	"fmt"
+	"path"
+	"strconv"
+	"strings"
+)
+
+// ErrUnsupportedFormat is returned for manuscripts that are not Markdown,
+// EPUB or DOCX
+var ErrUnsupportedFormat = errors.New("unsupported manuscript format")
+
+// Matter kinds recognised in front and back matter
+const (
+	MatterIntroduction     = "introduction"
+	MatterPreface          = "preface"
+	MatterAcknowledgements = "acknowledgements"
+	MatterSupportAuthor    = "support_author"
+	MatterConclusion       = "conclusion"
+	MatterAppendices       = "appendices"
+	MatterBibliography     = "bibliography"
+	MatterGlossary         = "glossary"
+	MatterAboutAuthor      = "about_author"
+)
+
+// frontMatterKinds are the matter kinds stored in the front matter; the rest
+// belong to the back matter
+var frontMatterKinds = []string{MatterIntroduction, MatterPreface, MatterAcknowledgements, MatterSupportAuthor}
+
+// Manuscript is a parsed book
+type Manuscript struct {
+	Title       string            `json:"title"`
+	Subtitle    string            `json:"subtitle,omitempty"`
+	Author      string            `json:"author,omitempty"`
+	Description string            `json:"description,omitempty"`
+	CoverImage  string            `json:"cover_image,omitempty"`
+	FrontMatter map[string]string `json:"front_matter,omitempty"`
+	BackMatter  map[string]string `json:"back_matter,omitempty"`
+	Chapters    []Chapter         `json:"chapters"`
+	Citations   []Citation        `json:"citations,omitempty"`
+}
+
+// Chapter is a numbered chapter of a manuscript
+type Chapter struct {
+	Number      int       `json:"number"`
+	Title       string    `json:"title"`
+	Description string    `json:"description,omitempty"`
+	Content     string    `json:"content,omitempty"` // Text before the first section
+	Sections    []Section `json:"sections"`
+}
+
+// Section is a numbered section of a chapter. Interactive elements appear in
+// Content as {{interactive:N}} placeholders, where N is the element position.
+type Section struct {
+	Number       int       `json:"number"`
+	Title        string    `json:"title"`
+	Content      string    `json:"content"`
+	Elements     []Element `json:"elements,omitempty"`
+	CitationKeys []string  `json:"citation_keys,omitempty"`
+}
+
+// Element is an interactive element defined inline in a section
+type Element struct {
+	Position       int                    `json:"position"`
+	Type           string                 `json:"type" yaml:"type"`
+	Title          string                 `json:"title" yaml:"title"`
+	Description    string                 `json:"description,omitempty" yaml:"description"`
+	CompletionType string                 `json:"completion_type,omitempty" yaml:"completion_type"`
+	Points         int                    `json:"points,omitempty" yaml:"points"`
+	Required       bool                   `json:"required,omitempty" yaml:"required"`
+	Content        map[string]interface{} `json:"content,omitempty" yaml:"content"`
+}
+
+// ResolveElements returns the section content with each {{interactive:N}}
+// placeholder pointing at the stored element instead of its position, as
+// the content renderer expects. Positions missing from ids are left as is.
+func (s Section) ResolveElements(ids map[int]uint) string {
+	return placeholderRegex.ReplaceAllStringFunc(s.Content, func(match string) string {
+		position, _ := strconv.Atoi(placeholderRegex.FindStringSubmatch(match)[1])
+		if id, ok := ids[position]; ok {
+			return "{{interactive:" + strconv.FormatUint(uint64(id), 10) + "}}"
+		}
+		return match
+	})
+}
+
+// Citation is a bibliography entry. Sections cite it with [@key], which the
+// parser replaces with the citation's reference number.
+type Citation struct {
+	Key    string `json:"key" yaml:"key"`
+	Author string `json:"author" yaml:"author"`
+	Year   string `json:"year" yaml:"year"`
+	Title  string `json:"title" yaml:"title"`
+	Source string `json:"source" yaml:"source"`
+	URL    string `json:"url,omitempty" yaml:"url"`
+	Type   string `json:"type" yaml:"type"`
+}
+
+// Parse reads a manuscript from an uploaded file. The format is chosen by
+// extension: .epub, .docx, or .zip for a zipped Markdown directory.
+func Parse(filename string, data []byte) (*Manuscript, error) {
+	switch strings.ToLower(path.Ext(filename)) {
+	case ".epub":
+		return ParseEPUB(bytes.NewReader(data), int64(len(data)))
+	case ".docx":
+		return ParseDOCX(bytes.NewReader(data), int64(len(data)))
+	case ".zip":
+		zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+		if err != nil {
+			return nil, fmt.Errorf("failed to open zip archive: %w", err)
+		}
+		return ParseMarkdown(zr)
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedFormat, filename)
+	}
+}
+
+// Validate checks that the manuscript can be imported
+func (m *Manuscript) Validate() error {
+	if strings.TrimSpace(m.Title) == "" {
+		return errors.New("manuscript has no title")
+	}
+	if len(m.Chapters) == 0 {
+		return errors.New("manuscript has no chapters")
+	}
+
+	chapters := make(map[int]bool)
+	for _, chapter := range m.Chapters {
+		if chapters[chapter.Number] {
+			return fmt.Errorf("chapter %d appears more than once", chapter.Number)
+		}
+		chapters[chapter.Number] = true
+		if len(chapter.Sections) == 0 {
+			return fmt.Errorf("chapter %d has no sections", chapter.Number)
+		}
+	}
+
+	keys := make(map[string]bool)
+	for _, citation := range m.Citations {
+		if citation.Key == "" {
+			return errors.New("citation without a key")
+		}
+		if keys[citation.Key] {
+			return fmt.Errorf("citation %q appears more than once", citation.Key)
+		}
+		keys[citation.Key] = true
+	}
+	return nil
+}
+
+// matterKind maps a heading or file name such as "About the Author" to a
+// front or back matter kind, or "" for ordinary chapters
+func matterKind(title string) string {
+	normalized := strings.ToLower(strings.TrimSpace(title))
+	normalized = strings.NewReplacer("-", " ", "_", " ").Replace(normalized)
+
+	switch normalized {
+	case "introduction":
+		return MatterIntroduction
+	case "preface", "foreword":
+		return MatterPreface
+	case "acknowledgements", "acknowledgments":
+		return MatterAcknowledgements
+	case "support the author", "support author":
+		return MatterSupportAuthor
+	case "conclusion", "epilogue", "afterword":
+		return MatterConclusion
+	case "appendix", "appendices":
+		return MatterAppendices
+	case "bibliography", "references":
+		return MatterBibliography
+	case "glossary":
+		return MatterGlossary
+	case "about the author", "about author":
+		return MatterAboutAuthor
+	}
+	return ""
+}
+
+// isFrontMatter reports whether kind belongs in the front matter
+func isFrontMatter(kind string) bool {
+	for _, k := range frontMatterKinds {
+		if k == kind {
+			return true
+		}
+	}
+	return false
+}
+
+// addMatter stores matter content in the front or back matter map
+func (m *Manuscript) addMatter(kind, content string) {
+	content = strings.TrimSpace(content)
+	if content == "" {
+		return
+	}
+	target := &m.BackMatter
+	if isFrontMatter(kind) {
+		target = &m.FrontMatter
+	}
+	if *target == nil {
+		*target = make(map[string]string)
+	}
+	if existing := (*target)[kind]; existing != "" {
+		content = existing + "\n\n" + content
+	}
+	(*target)[kind] = content
+}
@@ -0,0 +1,420 @@
+package importer
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// bookFileNames are the accepted names of the book metadata file in a
+// Markdown manuscript
+var bookFileNames = []string{"book.yaml", "book.yml"}
+
+var (
+	placeholderRegex = regexp.MustCompile(`\{\{interactive:(\d+)\}\}`)
+	citationRegex    = regexp.MustCompile(`\[(@[^\[\]]+)\]`)
+)
+
+// bookFile is the book.yaml of a Markdown manuscript
+type bookFile struct {
+	Title       string     `yaml:"title"`
+	Subtitle    string     `yaml:"subtitle"`
+	Author      string     `yaml:"author"`
+	Description string     `yaml:"description"`
+	CoverImage  string     `yaml:"cover_image"`
+	Citations   []Citation `yaml:"citations"`
+}
+
+// document is one part of a manuscript converted to Markdown: a chapter, or
+// a piece of front or back matter
+type document struct {
+	Kind        string `yaml:"type"` // "chapter", a matter kind, or empty to detect from the title
+	Number      int    `yaml:"number"`
+	Title       string `yaml:"title"`
+	Description string `yaml:"description"`
+	Body        string `yaml:"-"`
+}
+
+// ParseMarkdown reads a manuscript from a directory of Markdown files. The
+// directory holds a book.yaml with the book metadata and citations, and one
+// Markdown file per chapter or piece of front/back matter, imported in file
+// name order. Each file may start with YAML front matter:
+//
+//	---
+//	type: chapter      # or preface, introduction, conclusion, glossary, ...
+//	number: 3
+//	title: The Economy
+//	description: Why growth has stalled
+//	---
+//
+// Level-two headings split a chapter into sections. Interactive elements are
+// written as fenced ```interactive blocks holding YAML, and citations as
+// [@key] or [@key1; @key2].
+func ParseMarkdown(fsys fs.FS) (*Manuscript, error) {
+	root, err := findBookRoot(fsys)
+	if err != nil {
+		return nil, err
+	}
+	if root != "." {
+		if fsys, err = fs.Sub(fsys, root); err != nil {
+			return nil, err
+		}
+	}
+
+	var book bookFile
+	for _, name := range bookFileNames {
+		data, err := fs.ReadFile(fsys, name)
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		if err := yaml.Unmarshal(data, &book); err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %w", name, err)
+		}
+		break
+	}
+
+	var files []string
+	err = fs.WalkDir(fsys, ".", func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if p != "." && strings.HasPrefix(d.Name(), ".") {
+				return fs.SkipDir
+			}
+			return nil
+		}
+		if strings.EqualFold(path.Ext(p), ".md") && !strings.EqualFold(d.Name(), "README.md") {
+			files = append(files, p)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list manuscript files: %w", err)
+	}
+	sort.Strings(files)
+
+	docs := make([]document, 0, len(files))
+	for _, file := range files {
+		data, err := fs.ReadFile(fsys, file)
+		if err != nil {
+			return nil, err
+		}
+		doc, err := parseMarkdownFile(string(data))
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", file, err)
+		}
+		if doc.Kind == "" && doc.Title == "" {
+			// Fall back to the file name, e.g. "00-preface.md"
+			doc.Kind = matterKind(strings.TrimLeft(strings.TrimSuffix(path.Base(file), path.Ext(file)), "0123456789-_ "))
+		}
+		docs = append(docs, doc)
+	}
+
+	m := &Manuscript{
+		Title:       book.Title,
+		Subtitle:    book.Subtitle,
+		Author:      book.Author,
+		Description: book.Description,
+		CoverImage:  book.CoverImage,
+		Citations:   book.Citations,
+	}
+	if err := buildManuscript(m, docs); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// findBookRoot returns the directory holding book.yaml, so that a zip of a
+// manuscript folder imports the same as the folder itself
+func findBookRoot(fsys fs.FS) (string, error) {
+	root := ""
+	err := fs.WalkDir(fsys, ".", func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if root != "" {
+			return fs.SkipAll
+		}
+		for _, name := range bookFileNames {
+			if !d.IsDir() && d.Name() == name {
+				root = path.Dir(p)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to read manuscript: %w", err)
+	}
+	if root == "" {
+		return "", errors.New("manuscript has no book.yaml")
+	}
+	return root, nil
+}
+
+// parseMarkdownFile splits a Markdown file into its front matter and body
+func parseMarkdownFile(text string) (document, error) {
+	var doc document
+	text = strings.TrimPrefix(strings.ReplaceAll(text, "\r\n", "\n"), "\ufeff")
+
+	if strings.HasPrefix(text, "---\n") {
+		end := strings.Index(text[4:], "\n---")
+		if end < 0 {
+			return doc, errors.New("unterminated front matter")
+		}
+		if err := yaml.Unmarshal([]byte(text[4:4+end]), &doc); err != nil {
+			return doc, fmt.Errorf("failed to parse front matter: %w", err)
+		}
+		text = text[4+end+4:]
+		if i := strings.IndexByte(text, '\n'); i >= 0 && strings.TrimSpace(text[:i]) == "" {
+			text = text[i+1:]
+		}
+	}
+
+	doc.Body = text
+	return doc, nil
+}
+
+// buildManuscript turns documents into chapters and front/back matter, then
+// extracts interactive elements and resolves citations in every section
+func buildManuscript(m *Manuscript, docs []document) error {
+	citationNumbers := make(map[string]int, len(m.Citations))
+	for i, citation := range m.Citations {
+		citationNumbers[citation.Key] = i + 1
+	}
+
+	nextNumber := 1
+	for _, doc := range docs {
+		title, body := doc.Title, doc.Body
+		if heading, rest, ok := leadingHeading(body); ok {
+			if title == "" {
+				title = heading
+			}
+			body = rest
+		}
+		if strings.TrimSpace(body) == "" || skipDocument(m, title) {
+			continue
+		}
+
+		kind := strings.ToLower(doc.Kind)
+		if kind == "" {
+			kind = matterKind(title)
+		}
+		if kind != "" && kind != "chapter" {
+			matter := matterKind(kind)
+			if matter == "" {
+				return fmt.Errorf("unknown document type %q", doc.Kind)
+			}
+			m.addMatter(matter, body)
+			continue
+		}
+
+		number := doc.Number
+		if number == 0 {
+			number = nextNumber
+		}
+		nextNumber = number + 1
+
+		intro, sections := splitSections(body)
+		if len(sections) == 0 {
+			sections = []Section{{Number: 1, Title: title, Content: intro}}
+			intro = ""
+		}
+
+		chapter := Chapter{
+			Number:      number,
+			Title:       title,
+			Description: doc.Description,
+			Content:     resolveCitationsOnly(intro, citationNumbers),
+			Sections:    sections,
+		}
+		for i := range chapter.Sections {
+			section := &chapter.Sections[i]
+			if err := extractElements(section); err != nil {
+				return fmt.Errorf("chapter %d section %d: %w", number, section.Number, err)
+			}
+			if err := resolveCitations(section, citationNumbers); err != nil {
+				return fmt.Errorf("chapter %d section %d: %w", number, section.Number, err)
+			}
+		}
+		m.Chapters = append(m.Chapters, chapter)
+	}
+
+	return m.Validate()
+}
+
+// skipDocument reports whether a document is a title page or table of
+// contents, which exports such as EPUB commonly include
+func skipDocument(m *Manuscript, title string) bool {
+	normalized := strings.ToLower(strings.TrimSpace(title))
+	switch normalized {
+	case "contents", "table of contents", "title page", "copyright", "cover":
+		return true
+	}
+	return normalized != "" && strings.EqualFold(normalized, strings.TrimSpace(m.Title))
+}
+
+// leadingHeading returns the text of a level-one heading at the start of
+// body and the body without it
+func leadingHeading(body string) (string, string, bool) {
+	trimmed := strings.TrimLeft(body, "\n")
+	line, rest, _ := strings.Cut(trimmed, "\n")
+	if !strings.HasPrefix(line, "# ") {
+		return "", body, false
+	}
+	return strings.TrimSpace(line[2:]), rest, true
+}
+
+// splitSections splits a chapter body at level-two headings. Text before the
+// first heading is returned as the chapter introduction.
+func splitSections(body string) (string, []Section) {
+	var (
+		intro    strings.Builder
+		sections []Section
+		current  = &intro
+		fence    string
+	)
+
+	scanner := bufio.NewScanner(strings.NewReader(body))
+	scanner.Buffer(make([]byte, 0, 64*1024), 4*1024*1024)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if marker := fenceMarker(line); marker != "" {
+			if fence == "" {
+				fence = marker
+			} else if strings.HasPrefix(strings.TrimSpace(line), fence) {
+				fence = ""
+			}
+		}
+
+		if fence == "" && strings.HasPrefix(line, "## ") {
+			if len(sections) > 0 {
+				sections[len(sections)-1].Content = strings.TrimSpace(current.String())
+			}
+			sections = append(sections, Section{
+				Number: len(sections) + 1,
+				Title:  strings.TrimSpace(line[3:]),
+			})
+			current = &strings.Builder{}
+			continue
+		}
+
+		current.WriteString(line)
+		current.WriteByte('\n')
+	}
+	if len(sections) > 0 {
+		sections[len(sections)-1].Content = strings.TrimSpace(current.String())
+	}
+
+	return strings.TrimSpace(intro.String()), sections
+}
+
+// fenceMarker returns the fence characters opening or closing a code block
+func fenceMarker(line string) string {
+	trimmed := strings.TrimSpace(line)
+	for _, marker := range []string{"```", "~~~"} {
+		if strings.HasPrefix(trimmed, marker) {
+			return marker
+		}
+	}
+	return ""
+}
+
+// extractElements replaces ```interactive blocks with {{interactive:N}}
+// placeholders and records the element definitions
+func extractElements(section *Section) error {
+	var (
+		out     strings.Builder
+		block   strings.Builder
+		inBlock bool
+	)
+
+	for _, line := range strings.Split(section.Content, "\n") {
+		trimmed := strings.TrimSpace(line)
+		switch {
+		case !inBlock && (trimmed == "```interactive" || trimmed == "~~~interactive"):
+			inBlock = true
+			block.Reset()
+		case inBlock && (trimmed == "```" || trimmed == "~~~"):
+			inBlock = false
+			var element Element
+			if err := yaml.Unmarshal([]byte(block.String()), &element); err != nil {
+				return fmt.Errorf("invalid interactive element: %w", err)
+			}
+			if element.Type == "" {
+				return errors.New("interactive element has no type")
+			}
+			element.Position = len(section.Elements) + 1
+			section.Elements = append(section.Elements, element)
+			out.WriteString("{{interactive:" + strconv.Itoa(element.Position) + "}}\n")
+		case inBlock:
+			block.WriteString(line)
+			block.WriteByte('\n')
+		default:
+			out.WriteString(line)
+			out.WriteByte('\n')
+		}
+	}
+	if inBlock {
+		return errors.New("unterminated interactive block")
+	}
+
+	section.Content = strings.TrimSpace(out.String())
+
+	for _, match := range placeholderRegex.FindAllStringSubmatch(section.Content, -1) {
+		position, _ := strconv.Atoi(match[1])
+		if position < 1 || position > len(section.Elements) {
+			return fmt.Errorf("placeholder %s has no interactive block", match[0])
+		}
+	}
+	return nil
+}
+
+// resolveCitations replaces [@key] markers with reference numbers and
+// records the keys the section cites
+func resolveCitations(section *Section, numbers map[string]int) error {
+	var unknown []string
+	seen := make(map[string]bool)
+
+	section.Content = citationRegex.ReplaceAllStringFunc(section.Content, func(match string) string {
+		refs := make([]string, 0, 1)
+		for _, part := range strings.Split(match[1:len(match)-1], ";") {
+			key := strings.TrimPrefix(strings.TrimSpace(part), "@")
+			number, ok := numbers[key]
+			if !ok {
+				unknown = append(unknown, key)
+				return match
+			}
+			if !seen[key] {
+				seen[key] = true
+				section.CitationKeys = append(section.CitationKeys, key)
+			}
+			refs = append(refs, strconv.Itoa(number))
+		}
+		return "[" + strings.Join(refs, ", ") + "]"
+	})
+
+	if len(unknown) > 0 {
+		return fmt.Errorf("unknown citation keys: %s", strings.Join(unknown, ", "))
+	}
+	return nil
+}
+
+// resolveCitationsOnly replaces known [@key] markers in text that does not
+// track citation usage, such as chapter introductions
+func resolveCitationsOnly(text string, numbers map[string]int) string {
+	section := Section{Content: text}
+	_ = resolveCitations(&section, numbers)
+	return section.Content
+}
@@ -14,11 +14,25 @@ type Book struct {
         CoverImage        string        `json:"cover_image"`
         Published         bool          `json:"published" gorm:"default:false"`
         ScheduledPublishAt *time.Time    `json:"scheduled_publish_at"`
+        ScheduledUnpublishAt *time.Time  `json:"scheduled_unpublish_at"`
         CreatedAt         time.Time     `json:"created_at"`
         UpdatedAt         time.Time     `json:"updated_at"`
         Chapters          []BookChapter `json:"chapters" gorm:"-"` // Not persisted to the database
 }
 
+// BookTag associates a free-form tag with a book
+type BookTag struct {
+        ID        uint      `json:"id" gorm:"primaryKey"`
+        BookID    uint      `json:"book_id" gorm:"not null;uniqueIndex:idx_book_tag,priority:1"`
+        Tag       string    `json:"tag" gorm:"size:100;not null;uniqueIndex:idx_book_tag,priority:2;index"`
+        CreatedAt time.Time `json:"created_at"`
+}
+
+// TableName overrides the table name for BookTag
+func (BookTag) TableName() string {
+        return "book_tags"
+}
+
 // BookFrontMatter represents the front matter of a book
 type BookFrontMatter struct {
         ID               uint      `json:"id" gorm:"primaryKey"`
@@ -71,6 +85,7 @@ type BookChapter struct {
         Content           string        `json:"content" gorm:"type:text"`
         Published         bool          `json:"published" gorm:"default:false"`
         ScheduledPublishAt *time.Time    `json:"scheduled_publish_at"`
+        ScheduledUnpublishAt *time.Time  `json:"scheduled_unpublish_at"`
         CreatedAt         time.Time     `json:"created_at"`
         UpdatedAt         time.Time     `json:"updated_at"`
         Sections          []BookSection `json:"sections" gorm:"-"` // Not persisted to the database
@@ -88,6 +103,7 @@ type BookSection struct {
         TimeToRead        int              `json:"time_to_read" gorm:"default:5"`
         Published         bool             `json:"published" gorm:"default:false"`
         ScheduledPublishAt *time.Time       `json:"scheduled_publish_at"`
+        ScheduledUnpublishAt *time.Time     `json:"scheduled_unpublish_at"`
         CreatedAt         time.Time        `json:"created_at"`
         UpdatedAt         time.Time        `json:"updated_at"`
         Subsections       []BookSubsection `json:"subsections" gorm:"-"` // Not persisted to the database
@@ -0,0 +1,37 @@
+package models
+
+import (
+	"time"
+)
+
+// Media job kinds
+const (
+	MediaJobAudio = "audio"
+)
+
+// Media job statuses
+const (
+	MediaJobQueued    = "queued"
+	MediaJobRunning   = "running"
+	MediaJobCompleted = "completed"
+	MediaJobFailed    = "failed"
+)
+
+// MediaJob is a request to generate media for a section in the background.
+// Clients poll the job until it is completed or failed.
+type MediaJob struct {
+	ID          uint       `json:"id" gorm:"primaryKey"`
+	Kind        string     `json:"kind" gorm:"size:20;not null;index:idx_media_jobs_target,priority:1"`
+	SectionID   uint       `json:"section_id" gorm:"not null;index:idx_media_jobs_target,priority:2"`
+	ContentHash string     `json:"content_hash" gorm:"size:64;index:idx_media_jobs_target,priority:3"` // Hash of the text and engine the media is generated from
+	Status      string     `json:"status" gorm:"size:20;not null;index"`
+	Attempts    int        `json:"attempts" gorm:"default:0"`
+	Error       string     `json:"error,omitempty" gorm:"type:text"`
+	URL         string     `json:"url,omitempty"`
+	TimingURL   string     `json:"timing_url,omitempty"` // WebVTT word and sentence timings
+	Duration    float64    `json:"duration,omitempty"`   // Seconds
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+	StartedAt   *time.Time `json:"started_at,omitempty"`
+	CompletedAt *time.Time `json:"completed_at,omitempty"`
+}
@@ -2,31 +2,25 @@ package models
 
 import (
 	"time"
+
+	pointsmodels "github.com/yerenwgventures/GreatNigeriaLibrary-Foundation/backend/services/points/models"
 )
 
-// PointsTransactionType defines the type of points transaction
-type PointsTransactionType string
+// Points transactions are owned by the points service, which records them
+// in its ledger
+type (
+	PointsTransactionType = pointsmodels.PointsTransactionType
+	PointsTransaction     = pointsmodels.PointsTransaction
+)
 
+// Transaction types for points
 const (
-	// Transaction types for points
-	PointsEarned  PointsTransactionType = "earned"
-	PointsSpent   PointsTransactionType = "spent"
-	PointsExpired PointsTransactionType = "expired"
-	PointsAdjusted PointsTransactionType = "adjusted" // For manual adjustments by admins
+	PointsEarned   = pointsmodels.PointsEarned
+	PointsSpent    = pointsmodels.PointsSpent
+	PointsExpired  = pointsmodels.PointsExpired
+	PointsAdjusted = pointsmodels.PointsAdjusted
 )
 
-// PointsTransaction represents a transaction in a user's points account
-type PointsTransaction struct {
-	ID             uint                 `json:"id" gorm:"primaryKey"`
-	UserID         uint                 `json:"user_id" gorm:"index;not null"`
-	Points         int                  `json:"points" gorm:"not null"` // Positive for earned, negative for spent
-	TransactionType PointsTransactionType `json:"transaction_type" gorm:"type:varchar(50);not null;index"`
-	Description    string               `json:"description" gorm:"size:255"`
-	ReferenceID    *uint                `json:"reference_id,omitempty" gorm:"index"` // Reference to related entity (e.g., book, discussion)
-	ReferenceType  string               `json:"reference_type,omitempty" gorm:"size:100"`
-	CreatedAt      time.Time            `json:"created_at"`
-}
-
 // UserProfile extends the core user model with additional profile data
 type UserProfile struct {
 	ID               uint      `json:"id" gorm:"primaryKey"`
@@ -41,11 +35,6 @@ type UserProfile struct {
 	UpdatedAt        time.Time `json:"updated_at"`
 }
 
-// TableName overrides the table name for PointsTransaction
-func (PointsTransaction) TableName() string {
-	return "points_transactions"
-}
-
 // TableName overrides the table name for UserProfile
 func (UserProfile) TableName() string {
 	return "user_profiles"
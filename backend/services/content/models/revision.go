@@ -15,7 +15,7 @@ type ContentRevisionLog struct {
         ContentID      uint      `json:"contentId"`
         RevisionID     uint      `json:"revisionId"`
         UserID         uint      `json:"userId"`
-        Action         string    `json:"action"`         // create, update, delete, restore
+        Action         string    `json:"action"`         // create, update, delete, restore, publish, unpublish
         ChangeSummary  string    `json:"changeSummary"`  // Summary of changes
         Timestamp      time.Time `json:"timestamp"`
 }
\ No newline at end of file
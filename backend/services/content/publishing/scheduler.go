@@ -0,0 +1,278 @@
+// Package publishing publishes and unpublishes books, chapters and sections
+// when their scheduled time comes.
+package publishing
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/yerenwgventures/GreatNigeriaLibrary-Foundation/backend/pkg/common/audit"
+	"github.com/yerenwgventures/GreatNigeriaLibrary-Foundation/backend/pkg/common/jobs"
+	"github.com/yerenwgventures/GreatNigeriaLibrary-Foundation/backend/pkg/common/redis"
+)
+
+// DefaultInterval is the longest the scheduler sleeps between checks. It
+// wakes earlier when the next scheduled time is sooner.
+const DefaultInterval = time.Minute
+
+// LeaseName names the lease that lets one replica run the scheduler
+const LeaseName = "content-publisher"
+
+// batchSize is the number of changes applied per pass. A full batch is
+// followed straight away by another pass.
+const batchSize = 100
+
+// minWait is the shortest sleep between passes
+const minWait = time.Second
+
+// Scheduled publishing actions, also recorded as the revision log action
+const (
+	ActionPublish   = "publish"
+	ActionUnpublish = "unpublish"
+)
+
+// Publish event types
+const (
+	EventPublished   = "content.published"
+	EventUnpublished = "content.unpublished"
+)
+
+// DefaultEventChannel is the Redis channel publish events are sent on
+const DefaultEventChannel = "content:publish-events"
+
+// ScheduledChange is a book, chapter or section whose scheduled publish or
+// unpublish time has come
+type ScheduledChange struct {
+	ContentType string    `json:"contentType"` // book, chapter, or section
+	ContentID   uint      `json:"contentId"`
+	BookID      uint      `json:"bookId"`
+	Title       string    `json:"title"`
+	Action      string    `json:"action"` // publish or unpublish
+	DueAt       time.Time `json:"dueAt"`
+}
+
+// Event is emitted after the scheduler publishes or unpublishes a book,
+// chapter or section
+type Event struct {
+	Type        string    `json:"type"`
+	ContentType string    `json:"contentType"`
+	ContentID   uint      `json:"contentId"`
+	BookID      uint      `json:"bookId"`
+	Title       string    `json:"title"`
+	ScheduledAt time.Time `json:"scheduledAt"`
+	OccurredAt  time.Time `json:"occurredAt"`
+}
+
+// Listener consumes publish events, e.g. to refresh caches or announce new
+// content in digests
+type Listener interface {
+	HandlePublishEvent(ctx context.Context, event Event) error
+}
+
+// Publisher publishes and unpublishes content. The content admin service
+// implements it, so scheduled changes update the search index like manual
+// ones.
+type Publisher interface {
+	PublishContent(ctx context.Context, contentType string, contentID uint) error
+	UnpublishContent(ctx context.Context, contentType string, contentID uint) error
+}
+
+// Store finds content whose scheduled time has come and records what the
+// scheduler did
+type Store interface {
+	GetDueChanges(now time.Time, limit int) ([]ScheduledChange, error)
+	GetNextScheduledTime() (*time.Time, error)
+	LogChange(change ScheduledChange, summary string, at time.Time) error
+}
+
+// Scheduler publishes and unpublishes content when its scheduled time comes.
+// With a lease, only the replica holding it applies changes.
+type Scheduler struct {
+	publisher Publisher
+	store     Store
+	runner    *jobs.Runner
+	now       func() time.Time
+
+	mu        sync.RWMutex
+	listeners []Listener
+}
+
+// NewScheduler creates a new publish scheduler. A nil lease runs the
+// scheduler on every replica, which is only safe with a single replica.
+func NewScheduler(publisher Publisher, store Store, lease jobs.Lease, interval time.Duration) *Scheduler {
+	if interval <= 0 {
+		interval = DefaultInterval
+	}
+	return &Scheduler{
+		publisher: publisher,
+		store:     store,
+		runner:    jobs.NewRunner("publisher", lease, interval),
+		now:       time.Now,
+	}
+}
+
+// AddListener registers a listener for publish events
+func (s *Scheduler) AddListener(listener Listener) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.listeners = append(s.listeners, listener)
+}
+
+// Run applies due changes until ctx is cancelled, sleeping until the next
+// scheduled time or for at most the interval
+func (s *Scheduler) Run(ctx context.Context) {
+	s.runner.Run(ctx, func(ctx context.Context) time.Duration {
+		due, applied, held := s.runOnce(ctx)
+		switch {
+		case !held || applied < due:
+			// Another replica is publishing, or failed changes are retried
+			// after the interval
+			return s.runner.Interval()
+		case due == batchSize:
+			return 0
+		default:
+			return s.nextWait()
+		}
+	})
+}
+
+// RunOnce applies the changes that are due and returns how many were applied.
+// It does nothing when another replica holds the lease.
+func (s *Scheduler) RunOnce(ctx context.Context) int {
+	_, applied, _ := s.runOnce(ctx)
+	return applied
+}
+
+// runOnce returns the number of changes due and applied, and whether this
+// replica holds the lease
+func (s *Scheduler) runOnce(ctx context.Context) (int, int, bool) {
+	if !s.runner.Acquire(ctx) {
+		return 0, 0, false
+	}
+
+	due, err := s.store.GetDueChanges(s.now(), batchSize)
+	if err != nil {
+		log.Printf("Error getting scheduled content: %v", err)
+		return 0, 0, false
+	}
+
+	applied := 0
+	for _, change := range due {
+		if err := s.apply(ctx, change); err != nil {
+			log.Printf("Error applying scheduled %s of %s %d: %v", change.Action, change.ContentType, change.ContentID, err)
+			continue
+		}
+		applied++
+	}
+	if applied > 0 {
+		log.Printf("Applied %d scheduled publishing changes", applied)
+	}
+	return len(due), applied, true
+}
+
+// apply publishes or unpublishes one item, records it in the revision log
+// and notifies the listeners
+func (s *Scheduler) apply(ctx context.Context, change ScheduledChange) error {
+	actx := audit.SystemContext(ctx, "publish-scheduler")
+	eventType := EventPublished
+	summary := "Published as scheduled for "
+	switch change.Action {
+	case ActionPublish:
+		if err := s.publisher.PublishContent(actx, change.ContentType, change.ContentID); err != nil {
+			return err
+		}
+	case ActionUnpublish:
+		if err := s.publisher.UnpublishContent(actx, change.ContentType, change.ContentID); err != nil {
+			return err
+		}
+		eventType = EventUnpublished
+		summary = "Unpublished as scheduled for "
+	default:
+		return fmt.Errorf("invalid publishing action: %s", change.Action)
+	}
+
+	now := s.now()
+	if err := s.store.LogChange(change, summary+change.DueAt.Format(time.RFC3339), now); err != nil {
+		log.Printf("Error logging scheduled %s of %s %d: %v", change.Action, change.ContentType, change.ContentID, err)
+	}
+
+	s.emit(ctx, Event{
+		Type:        eventType,
+		ContentType: change.ContentType,
+		ContentID:   change.ContentID,
+		BookID:      change.BookID,
+		Title:       change.Title,
+		ScheduledAt: change.DueAt,
+		OccurredAt:  now,
+	})
+	return nil
+}
+
+// emit sends an event to every listener. Listener failures are logged; the
+// change itself has already been applied.
+func (s *Scheduler) emit(ctx context.Context, event Event) {
+	s.mu.RLock()
+	listeners := s.listeners
+	s.mu.RUnlock()
+
+	for _, listener := range listeners {
+		if err := listener.HandlePublishEvent(ctx, event); err != nil {
+			log.Printf("Error delivering %s event for %s %d: %v", event.Type, event.ContentType, event.ContentID, err)
+		}
+	}
+}
+
+// nextWait returns how long to sleep before the next pass
+func (s *Scheduler) nextWait() time.Duration {
+	next, err := s.store.GetNextScheduledTime()
+	if err != nil {
+		log.Printf("Error getting next scheduled publishing time: %v", err)
+		return s.runner.Interval()
+	}
+	if next == nil {
+		return s.runner.Interval()
+	}
+
+	wait := next.Sub(s.now())
+	if wait < minWait {
+		return minWait
+	}
+	if wait > s.runner.Interval() {
+		return s.runner.Interval()
+	}
+	return wait
+}
+
+// RedisEmitter sends publish events on a Redis channel so other services can
+// react to them
+type RedisEmitter struct {
+	client  *redis.Client
+	channel string
+}
+
+// NewRedisEmitter creates a new Redis publish event emitter
+func NewRedisEmitter(client *redis.Client, channel string) *RedisEmitter {
+	if channel == "" {
+		channel = DefaultEventChannel
+	}
+	return &RedisEmitter{
+		client:  client,
+		channel: channel,
+	}
+}
+
+// HandlePublishEvent publishes the event on the channel
+func (e *RedisEmitter) HandlePublishEvent(ctx context.Context, event Event) error {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("error encoding publish event: %w", err)
+	}
+	if err := e.client.Publish(ctx, e.channel, payload).Err(); err != nil {
+		return fmt.Errorf("error sending publish event: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,230 @@
+package publishing
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/yerenwgventures/GreatNigeriaLibrary-Foundation/backend/pkg/common/audit"
+	"github.com/yerenwgventures/GreatNigeriaLibrary-Foundation/backend/pkg/common/jobs"
+)
+
+var testNow = time.Date(2026, 11, 1, 8, 0, 1, 0, time.UTC)
+
+type publishCall struct {
+	action      string
+	contentType string
+	contentID   uint
+	actor       string
+}
+
+// fakePublisher records publish calls and fails for the content in fail
+type fakePublisher struct {
+	calls []publishCall
+	fail  map[uint]bool
+}
+
+func (p *fakePublisher) record(ctx context.Context, action, contentType string, contentID uint) error {
+	if p.fail[contentID] {
+		return errors.New("publish failed")
+	}
+	p.calls = append(p.calls, publishCall{action, contentType, contentID, audit.RequestFromContext(ctx).ActorName})
+	return nil
+}
+
+func (p *fakePublisher) PublishContent(ctx context.Context, contentType string, contentID uint) error {
+	return p.record(ctx, ActionPublish, contentType, contentID)
+}
+
+func (p *fakePublisher) UnpublishContent(ctx context.Context, contentType string, contentID uint) error {
+	return p.record(ctx, ActionUnpublish, contentType, contentID)
+}
+
+type loggedChange struct {
+	change  ScheduledChange
+	summary string
+	at      time.Time
+}
+
+// fakeStore returns the due changes it holds and records the revision log
+type fakeStore struct {
+	due     []ScheduledChange
+	next    *time.Time
+	queried int
+	logged  []loggedChange
+}
+
+func (s *fakeStore) GetDueChanges(now time.Time, limit int) ([]ScheduledChange, error) {
+	s.queried++
+	return s.due, nil
+}
+
+func (s *fakeStore) GetNextScheduledTime() (*time.Time, error) { return s.next, nil }
+
+func (s *fakeStore) LogChange(change ScheduledChange, summary string, at time.Time) error {
+	s.logged = append(s.logged, loggedChange{change, summary, at})
+	return nil
+}
+
+type recordingListener struct {
+	events []Event
+}
+
+func (l *recordingListener) HandlePublishEvent(ctx context.Context, event Event) error {
+	l.events = append(l.events, event)
+	return nil
+}
+
+// sharedLease is a job lease row shared by replicas, expiring like
+// database.Lease
+type sharedLease struct {
+	now       *time.Time
+	ttl       time.Duration
+	holder    string
+	expiresAt time.Time
+}
+
+// replicaLease is one replica's handle on a sharedLease
+type replicaLease struct {
+	shared *sharedLease
+	name   string
+}
+
+func (l *replicaLease) Acquire(ctx context.Context) (bool, error) {
+	s := l.shared
+	if s.holder != "" && s.holder != l.name && !s.expiresAt.Before(*s.now) {
+		return false, nil
+	}
+	s.holder = l.name
+	s.expiresAt = s.now.Add(s.ttl)
+	return true, nil
+}
+
+func (l *replicaLease) Release(ctx context.Context) error {
+	if l.shared.holder == l.name {
+		l.shared.holder = ""
+	}
+	return nil
+}
+
+func newTestScheduler(store Store, lease jobs.Lease, now *time.Time) (*Scheduler, *fakePublisher, *recordingListener) {
+	publisher := &fakePublisher{fail: map[uint]bool{}}
+	listener := &recordingListener{}
+	scheduler := NewScheduler(publisher, store, lease, 0)
+	scheduler.now = func() time.Time { return *now }
+	scheduler.AddListener(listener)
+	return scheduler, publisher, listener
+}
+
+func TestRunOncePublishesAndUnpublishesDueContent(t *testing.T) {
+	now := testNow
+	publishAt := time.Date(2026, 11, 1, 8, 0, 0, 0, time.UTC)
+	unpublishAt := time.Date(2026, 11, 1, 7, 0, 0, 0, time.UTC)
+	store := &fakeStore{due: []ScheduledChange{
+		{ContentType: "book", ContentID: 4, BookID: 4, Title: "Expired", Action: ActionUnpublish, DueAt: unpublishAt},
+		{ContentType: "chapter", ContentID: 12, BookID: 3, Title: "Governance", Action: ActionPublish, DueAt: publishAt},
+	}}
+	scheduler, publisher, listener := newTestScheduler(store, nil, &now)
+
+	assert.Equal(t, 2, scheduler.RunOnce(context.Background()))
+
+	assert.Equal(t, []publishCall{
+		{ActionUnpublish, "book", 4, "publish-scheduler"},
+		{ActionPublish, "chapter", 12, "publish-scheduler"},
+	}, publisher.calls)
+
+	assert.Equal(t, []Event{
+		{Type: EventUnpublished, ContentType: "book", ContentID: 4, BookID: 4, Title: "Expired", ScheduledAt: unpublishAt, OccurredAt: now},
+		{Type: EventPublished, ContentType: "chapter", ContentID: 12, BookID: 3, Title: "Governance", ScheduledAt: publishAt, OccurredAt: now},
+	}, listener.events)
+
+	require.Len(t, store.logged, 2)
+	assert.Equal(t, "Unpublished as scheduled for 2026-11-01T07:00:00Z", store.logged[0].summary)
+	assert.Equal(t, "Published as scheduled for 2026-11-01T08:00:00Z", store.logged[1].summary)
+	assert.Equal(t, now, store.logged[1].at)
+}
+
+func TestRunOnceSkipsFailedChanges(t *testing.T) {
+	now := testNow
+	store := &fakeStore{due: []ScheduledChange{
+		{ContentType: "section", ContentID: 7, Action: ActionPublish, DueAt: now},
+		{ContentType: "section", ContentID: 8, Action: ActionPublish, DueAt: now},
+		{ContentType: "section", ContentID: 9, Action: "archive", DueAt: now},
+	}}
+	scheduler, publisher, listener := newTestScheduler(store, nil, &now)
+	publisher.fail[7] = true
+
+	due, applied, held := scheduler.runOnce(context.Background())
+	assert.Equal(t, 3, due)
+	assert.Equal(t, 1, applied)
+	assert.True(t, held)
+
+	require.Len(t, listener.events, 1, "failed changes emit no events")
+	assert.Equal(t, uint(8), listener.events[0].ContentID)
+	require.Len(t, store.logged, 1)
+	assert.Equal(t, uint(8), store.logged[0].change.ContentID)
+}
+
+func TestRunOnceRequiresTheLease(t *testing.T) {
+	now := testNow
+	lease := &sharedLease{now: &now, ttl: 3 * time.Minute}
+	change := ScheduledChange{ContentType: "book", ContentID: 1, Action: ActionPublish, DueAt: now}
+
+	storeA := &fakeStore{due: []ScheduledChange{change}}
+	replicaA, _, eventsA := newTestScheduler(storeA, &replicaLease{lease, "a"}, &now)
+	storeB := &fakeStore{due: []ScheduledChange{change}}
+	replicaB, _, eventsB := newTestScheduler(storeB, &replicaLease{lease, "b"}, &now)
+
+	assert.Equal(t, 1, replicaA.RunOnce(context.Background()))
+	assert.Zero(t, replicaB.RunOnce(context.Background()))
+	assert.Zero(t, storeB.queried, "a replica without the lease does not look for changes")
+
+	// Renewing keeps the lease past its original expiry
+	now = now.Add(2 * time.Minute)
+	assert.Equal(t, 1, replicaA.RunOnce(context.Background()))
+	now = now.Add(2 * time.Minute)
+	assert.Zero(t, replicaB.RunOnce(context.Background()))
+
+	// Replica a stops; b takes over once the lease expires
+	now = now.Add(3*time.Minute + time.Second)
+	assert.Equal(t, 1, replicaB.RunOnce(context.Background()))
+	assert.Zero(t, replicaA.RunOnce(context.Background()))
+
+	assert.Len(t, eventsA.events, 2)
+	assert.Len(t, eventsB.events, 1)
+}
+
+func TestRunReleasesTheLeaseOnShutdown(t *testing.T) {
+	now := testNow
+	lease := &sharedLease{now: &now, ttl: 3 * time.Minute}
+	replicaA, _, _ := newTestScheduler(&fakeStore{}, &replicaLease{lease, "a"}, &now)
+	replicaB, _, _ := newTestScheduler(&fakeStore{}, &replicaLease{lease, "b"}, &now)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	replicaA.Run(ctx)
+
+	_, _, held := replicaB.runOnce(context.Background())
+	assert.True(t, held, "the lease is free without waiting for it to expire")
+}
+
+func TestNextWait(t *testing.T) {
+	now := testNow
+	store := &fakeStore{}
+	scheduler, _, _ := newTestScheduler(store, nil, &now)
+
+	assert.Equal(t, DefaultInterval, scheduler.nextWait(), "nothing scheduled")
+
+	next := now.Add(20 * time.Second)
+	store.next = &next
+	assert.Equal(t, 20*time.Second, scheduler.nextWait())
+
+	next = now.Add(-time.Hour)
+	assert.Equal(t, minWait, scheduler.nextWait())
+
+	next = now.Add(time.Hour)
+	assert.Equal(t, DefaultInterval, scheduler.nextWait())
+}
@@ -0,0 +1,139 @@
+package publishing
+
+import (
+	"database/sql"
+	"fmt"
+	"sort"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// scheduledTable describes a table holding schedulable content
+type scheduledTable struct {
+	contentType string
+	name        string
+	bookColumn  string
+}
+
+// scheduledTables are the books, chapters and sections tables. Their
+// scheduled_unpublish_at columns are added by the 0023_add_publish_schedule
+// migration.
+var scheduledTables = []scheduledTable{
+	{contentType: "book", name: "books", bookColumn: "id"},
+	{contentType: "chapter", name: "book_chapters", bookColumn: "book_id"},
+	{contentType: "section", name: "book_sections", bookColumn: "book_id"},
+}
+
+// revisionLog is a row in content_revision_logs, see models.ContentRevisionLog
+type revisionLog struct {
+	gorm.Model
+	ContentType   string
+	ContentID     uint
+	Action        string
+	ChangeSummary string
+	Timestamp     time.Time
+}
+
+// TableName specifies the table name for revisionLog
+func (revisionLog) TableName() string {
+	return "content_revision_logs"
+}
+
+// GormStore implements Store using GORM
+type GormStore struct {
+	db *gorm.DB
+}
+
+// NewGormStore creates a new GormStore
+func NewGormStore(db *gorm.DB) *GormStore {
+	return &GormStore{db: db}
+}
+
+// GetDueChanges returns up to limit publish and unpublish actions due at
+// now, oldest first. Content whose publishing window ended before it was
+// published is only returned for unpublishing, so it never goes live.
+func (s *GormStore) GetDueChanges(now time.Time, limit int) ([]ScheduledChange, error) {
+	var due []ScheduledChange
+	for _, table := range scheduledTables {
+		var publish []ScheduledChange
+		err := s.db.Table(table.name).
+			Select("id AS content_id, "+table.bookColumn+" AS book_id, title, scheduled_publish_at AS due_at").
+			Where("published = ? AND scheduled_publish_at <= ?", false, now).
+			Where("scheduled_unpublish_at IS NULL OR scheduled_unpublish_at > ?", now).
+			Order("scheduled_publish_at").
+			Limit(limit).
+			Scan(&publish).Error
+		if err != nil {
+			return nil, fmt.Errorf("failed to get %ss due for publishing: %w", table.contentType, err)
+		}
+		for i := range publish {
+			publish[i].ContentType = table.contentType
+			publish[i].Action = ActionPublish
+		}
+
+		var unpublish []ScheduledChange
+		err = s.db.Table(table.name).
+			Select("id AS content_id, "+table.bookColumn+" AS book_id, title, scheduled_unpublish_at AS due_at").
+			Where("scheduled_unpublish_at <= ?", now).
+			Order("scheduled_unpublish_at").
+			Limit(limit).
+			Scan(&unpublish).Error
+		if err != nil {
+			return nil, fmt.Errorf("failed to get %ss due for unpublishing: %w", table.contentType, err)
+		}
+		for i := range unpublish {
+			unpublish[i].ContentType = table.contentType
+			unpublish[i].Action = ActionUnpublish
+		}
+
+		due = append(due, publish...)
+		due = append(due, unpublish...)
+	}
+
+	sort.SliceStable(due, func(i, j int) bool {
+		return due[i].DueAt.Before(due[j].DueAt)
+	})
+	if len(due) > limit {
+		due = due[:limit]
+	}
+	return due, nil
+}
+
+// GetNextScheduledTime returns the earliest pending publish or unpublish
+// time, or nil when nothing is scheduled
+func (s *GormStore) GetNextScheduledTime() (*time.Time, error) {
+	var next *time.Time
+	for _, table := range scheduledTables {
+		queries := []*gorm.DB{
+			s.db.Table(table.name).Select("MIN(scheduled_publish_at)").Where("published = ?", false),
+			s.db.Table(table.name).Select("MIN(scheduled_unpublish_at)"),
+		}
+		for _, query := range queries {
+			var earliest sql.NullTime
+			if err := query.Row().Scan(&earliest); err != nil {
+				return nil, fmt.Errorf("failed to get next scheduled %s: %w", table.contentType, err)
+			}
+			if earliest.Valid && (next == nil || earliest.Time.Before(*next)) {
+				t := earliest.Time
+				next = &t
+			}
+		}
+	}
+	return next, nil
+}
+
+// LogChange records a scheduled change in the content revision log
+func (s *GormStore) LogChange(change ScheduledChange, summary string, at time.Time) error {
+	entry := &revisionLog{
+		ContentType:   change.ContentType,
+		ContentID:     change.ContentID,
+		Action:        change.Action,
+		ChangeSummary: summary,
+		Timestamp:     at,
+	}
+	if err := s.db.Create(entry).Error; err != nil {
+		return fmt.Errorf("failed to log scheduled %s: %w", change.Action, err)
+	}
+	return nil
+}
@@ -0,0 +1,127 @@
+package publishing
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/yerenwgventures/GreatNigeriaLibrary-Foundation/backend/pkg/common/database/dbtest"
+	"gorm.io/gorm"
+)
+
+// openContentDB returns a database with the content tables as the content
+// service creates them, before the migrations add the unpublish schedule
+func openContentDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	db := dbtest.Empty(t)
+	for _, table := range []string{
+		"books (id BIGSERIAL PRIMARY KEY, title TEXT NOT NULL, published BOOLEAN DEFAULT FALSE, scheduled_publish_at TIMESTAMP WITH TIME ZONE)",
+		"book_chapters (id BIGSERIAL PRIMARY KEY, book_id BIGINT NOT NULL, title TEXT NOT NULL, published BOOLEAN DEFAULT FALSE, scheduled_publish_at TIMESTAMP WITH TIME ZONE)",
+		"book_sections (id BIGSERIAL PRIMARY KEY, book_id BIGINT NOT NULL, title TEXT NOT NULL, published BOOLEAN DEFAULT FALSE, scheduled_publish_at TIMESTAMP WITH TIME ZONE)",
+	} {
+		require.NoError(t, db.Exec("CREATE TABLE "+table).Error)
+	}
+	dbtest.Migrate(t, db, 0)
+	return db
+}
+
+func TestGetDueChangesWindow(t *testing.T) {
+	db := openContentDB(t)
+	store := NewGormStore(db)
+	now := time.Date(2026, 11, 1, 8, 0, 0, 0, time.UTC)
+	at := func(d time.Duration) time.Time { return now.Add(d) }
+
+	due, err := store.GetDueChanges(now, 100)
+	require.NoError(t, err)
+	assert.Empty(t, due)
+	next, err := store.GetNextScheduledTime()
+	require.NoError(t, err)
+	assert.Nil(t, next)
+
+	books := []struct {
+		id          uint
+		title       string
+		published   bool
+		publishAt   *time.Time
+		unpublishAt *time.Time
+	}{
+		{1, "Due", false, ptr(at(-2 * time.Hour)), nil},
+		{2, "Future", false, ptr(at(time.Hour)), nil},
+		{3, "Live", true, ptr(at(-3 * time.Hour)), nil},
+		{4, "Window passed", false, ptr(at(-5 * time.Hour)), ptr(at(-time.Hour))},
+		{5, "Ending", true, nil, ptr(at(-30 * time.Minute))},
+		{6, "Window open", false, ptr(at(-10 * time.Minute)), ptr(at(time.Hour))},
+	}
+	for _, b := range books {
+		require.NoError(t, db.Exec(
+			"INSERT INTO books (id, title, published, scheduled_publish_at, scheduled_unpublish_at) VALUES (?, ?, ?, ?, ?)",
+			b.id, b.title, b.published, b.publishAt, b.unpublishAt,
+		).Error)
+	}
+	require.NoError(t, db.Exec(
+		"INSERT INTO book_chapters (id, book_id, title, published, scheduled_publish_at) VALUES (10, 1, 'Chapter', FALSE, ?)",
+		at(-4*time.Hour),
+	).Error)
+	require.NoError(t, db.Exec(
+		"INSERT INTO book_sections (id, book_id, title, published, scheduled_unpublish_at) VALUES (20, 5, 'Section', TRUE, ?)",
+		now,
+	).Error)
+
+	due, err = store.GetDueChanges(now, 100)
+	require.NoError(t, err)
+
+	type key struct {
+		contentType string
+		contentID   uint
+		bookID      uint
+		action      string
+	}
+	var got []key
+	for _, change := range due {
+		got = append(got, key{change.ContentType, change.ContentID, change.BookID, change.Action})
+	}
+	assert.Equal(t, []key{
+		{"chapter", 10, 1, ActionPublish},
+		{"book", 1, 1, ActionPublish},
+		{"book", 4, 4, ActionUnpublish},
+		{"book", 5, 5, ActionUnpublish},
+		{"book", 6, 6, ActionPublish},
+		{"section", 20, 5, ActionUnpublish},
+	}, got, "oldest first; a window that has passed is only unpublished")
+	assert.True(t, due[0].DueAt.Equal(at(-4*time.Hour)))
+	assert.Equal(t, "Window passed", due[2].Title)
+
+	limited, err := store.GetDueChanges(now, 2)
+	require.NoError(t, err)
+	require.Len(t, limited, 2)
+	assert.Equal(t, uint(10), limited[0].ContentID)
+	assert.Equal(t, uint(1), limited[1].ContentID)
+
+	next, err = store.GetNextScheduledTime()
+	require.NoError(t, err)
+	require.NotNil(t, next)
+	assert.True(t, next.Equal(at(-5*time.Hour)), next.String())
+}
+
+func TestLogChange(t *testing.T) {
+	db := openContentDB(t)
+	store := NewGormStore(db)
+	at := time.Date(2026, 11, 1, 8, 0, 1, 0, time.UTC)
+
+	change := ScheduledChange{ContentType: "chapter", ContentID: 12, Action: ActionPublish}
+	require.NoError(t, store.LogChange(change, "Published as scheduled", at))
+
+	var entries []revisionLog
+	require.NoError(t, db.Find(&entries).Error)
+	require.Len(t, entries, 1)
+	assert.Equal(t, "chapter", entries[0].ContentType)
+	assert.Equal(t, uint(12), entries[0].ContentID)
+	assert.Equal(t, ActionPublish, entries[0].Action)
+	assert.Equal(t, "Published as scheduled", entries[0].ChangeSummary)
+	assert.True(t, entries[0].Timestamp.Equal(at))
+}
+
+func ptr(t time.Time) *time.Time {
+	return &t
+}
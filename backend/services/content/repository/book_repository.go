@@ -115,6 +115,7 @@ type BookRepository interface {
         // Advanced book operations
         GetChapterWithSections(chapterID uint) (*models.BookChapter, error)
         SearchBooks(query string, tags []string, limit int) ([]models.Book, error)
+        GetBookTags(bookID uint) ([]string, error)
         GetRecommendations(userID uint, limit int) ([]models.Book, error)
 }
 
@@ -677,10 +678,10 @@ func (r *BookRepositoryImpl) GetBookRevisionByID(id uint) (*models.BookRevision,
         return &revision, nil
 }
 
-// GetScheduledBooks retrieves all books scheduled to be published
+// GetScheduledBooks retrieves all books with a pending publish or unpublish schedule
 func (r *BookRepositoryImpl) GetScheduledBooks() ([]models.Book, error) {
         var books []models.Book
-        err := r.db.Where("scheduled_publish_at IS NOT NULL AND scheduled_publish_at > ?", time.Now()).Find(&books).Error
+        err := r.db.Where("scheduled_publish_at IS NOT NULL OR scheduled_unpublish_at IS NOT NULL").Find(&books).Error
         return books, err
 }
 
@@ -833,6 +834,16 @@ func (r *BookRepositoryImpl) GetChapterWithSections(chapterID uint) (*models.Boo
         return chapter, nil
 }
 
+// GetBookTags retrieves the tags attached to a book
+func (r *BookRepositoryImpl) GetBookTags(bookID uint) ([]string, error) {
+        var tags []string
+        err := r.db.Model(&models.BookTag{}).
+                Where("book_id = ?", bookID).
+                Order("tag").
+                Pluck("tag", &tags).Error
+        return tags, err
+}
+
 // SearchBooks searches for books based on query text and tags
 func (r *BookRepositoryImpl) SearchBooks(query string, tags []string, limit int) ([]models.Book, error) {
         db := r.db
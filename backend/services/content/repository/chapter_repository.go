@@ -4,7 +4,6 @@ import (
         "fmt"
         "github.com/yerenwgventures/GreatNigeriaLibrary-Foundation/backend/services/content/models"
         "gorm.io/gorm"
-        "time"
 )
 
 // ChapterRepository defines the interface for chapter data operations
@@ -118,10 +117,10 @@ func (r *GormChapterRepository) GetChapterRevisionByID(id uint) (*models.Chapter
         return &revision, nil
 }
 
-// GetScheduledChapters retrieves all chapters scheduled to be published
+// GetScheduledChapters retrieves all chapters with a pending publish or unpublish schedule
 func (r *GormChapterRepository) GetScheduledChapters() ([]models.BookChapter, error) {
         var chapters []models.BookChapter
-        if err := r.db.Where("scheduled_publish_at IS NOT NULL AND scheduled_publish_at > ?", time.Now()).Find(&chapters).Error; err != nil {
+        if err := r.db.Where("scheduled_publish_at IS NOT NULL OR scheduled_unpublish_at IS NOT NULL").Find(&chapters).Error; err != nil {
                 return nil, err
         }
         return chapters, nil
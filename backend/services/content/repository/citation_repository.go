@@ -26,6 +26,12 @@ func (r *CitationRepository) CreateCitation(citation *models.Citation) error {
 	return r.DB.Create(citation).Error
 }
 
+// UpdateCitation saves changes to an existing citation
+func (r *CitationRepository) UpdateCitation(citation *models.Citation) error {
+	citation.UpdatedAt = time.Now()
+	return r.DB.Save(citation).Error
+}
+
 // GetCitationByID retrieves a citation by its ID
 func (r *CitationRepository) GetCitationByID(id uint) (*models.Citation, error) {
 	var citation models.Citation
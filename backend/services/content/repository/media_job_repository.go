@@ -0,0 +1,134 @@
+package repository
+
+import (
+        "errors"
+        "time"
+
+        "github.com/yerenwgventures/GreatNigeriaLibrary-Foundation/backend/services/content/models"
+        "gorm.io/gorm"
+)
+
+// MediaJobRepository stores background media generation jobs
+type MediaJobRepository interface {
+        CreateJob(job *models.MediaJob) error
+        GetJobByID(id uint) (*models.MediaJob, error)
+        GetLatestJob(kind string, sectionID uint, contentHash string) (*models.MediaJob, error)
+        UpdateJob(job *models.MediaJob) error
+
+        // Worker operations
+        ClaimNextJob(kind string, now time.Time) (*models.MediaJob, error)
+        RequeueStaleJobs(kind string, startedBefore time.Time, maxAttempts int) (int64, error)
+}
+
+// GormMediaJobRepository implements MediaJobRepository using GORM
+type GormMediaJobRepository struct {
+        db *gorm.DB
+}
+
+// NewGormMediaJobRepository creates a new GormMediaJobRepository
+func NewGormMediaJobRepository(db *gorm.DB) *GormMediaJobRepository {
+        return &GormMediaJobRepository{db: db}
+}
+
+// CreateJob stores a new job
+func (r *GormMediaJobRepository) CreateJob(job *models.MediaJob) error {
+        return r.db.Create(job).Error
+}
+
+// GetJobByID retrieves a job by its ID
+func (r *GormMediaJobRepository) GetJobByID(id uint) (*models.MediaJob, error) {
+        var job models.MediaJob
+        if err := r.db.First(&job, id).Error; err != nil {
+                if errors.Is(err, gorm.ErrRecordNotFound) {
+                        return nil, errors.New("media job not found")
+                }
+                return nil, err
+        }
+        return &job, nil
+}
+
+// GetLatestJob returns the newest job generating media for the section from
+// the given content, or nil if there is none
+func (r *GormMediaJobRepository) GetLatestJob(kind string, sectionID uint, contentHash string) (*models.MediaJob, error) {
+        var jobs []models.MediaJob
+        err := r.db.Where("kind = ? AND section_id = ? AND content_hash = ?", kind, sectionID, contentHash).
+                Order("id DESC").
+                Limit(1).
+                Find(&jobs).Error
+        if err != nil {
+                return nil, err
+        }
+        if len(jobs) == 0 {
+                return nil, nil
+        }
+        return &jobs[0], nil
+}
+
+// UpdateJob saves a job
+func (r *GormMediaJobRepository) UpdateJob(job *models.MediaJob) error {
+        return r.db.Save(job).Error
+}
+
+// ClaimNextJob marks the oldest queued job of a kind as running and returns
+// it, or returns nil when the queue is empty. A job is only claimed by one
+// worker even when several replicas poll the queue.
+func (r *GormMediaJobRepository) ClaimNextJob(kind string, now time.Time) (*models.MediaJob, error) {
+        for {
+                var jobs []models.MediaJob
+                err := r.db.Where("kind = ? AND status = ?", kind, models.MediaJobQueued).
+                        Order("id ASC").
+                        Limit(1).
+                        Find(&jobs).Error
+                if err != nil {
+                        return nil, err
+                }
+                if len(jobs) == 0 {
+                        return nil, nil
+                }
+
+                job := jobs[0]
+                result := r.db.Model(&models.MediaJob{}).
+                        Where("id = ? AND status = ?", job.ID, models.MediaJobQueued).
+                        Updates(map[string]interface{}{
+                                "status":     models.MediaJobRunning,
+                                "attempts":   gorm.Expr("attempts + 1"),
+                                "started_at": now,
+                                "updated_at": now,
+                        })
+                if result.Error != nil {
+                        return nil, result.Error
+                }
+                if result.RowsAffected == 1 {
+                        job.Status = models.MediaJobRunning
+                        job.Attempts++
+                        job.StartedAt = &now
+                        job.UpdatedAt = now
+                        return &job, nil
+                }
+                // Another worker claimed the job first
+        }
+}
+
+// RequeueStaleJobs returns running jobs started before startedBefore to the
+// queue, for example after the replica running them stopped. Jobs that have
+// already been tried maxAttempts times fail instead.
+func (r *GormMediaJobRepository) RequeueStaleJobs(kind string, startedBefore time.Time, maxAttempts int) (int64, error) {
+        stale := r.db.Model(&models.MediaJob{}).
+                Where("kind = ? AND status = ? AND started_at < ?", kind, models.MediaJobRunning, startedBefore)
+
+        failed := stale.Session(&gorm.Session{}).
+                Where("attempts >= ?", maxAttempts).
+                Updates(map[string]interface{}{
+                        "status":       models.MediaJobFailed,
+                        "error":        "job did not finish",
+                        "completed_at": time.Now(),
+                })
+        if failed.Error != nil {
+                return 0, failed.Error
+        }
+
+        requeued := stale.Session(&gorm.Session{}).
+                Where("attempts < ?", maxAttempts).
+                Update("status", models.MediaJobQueued)
+        return requeued.RowsAffected, requeued.Error
+}
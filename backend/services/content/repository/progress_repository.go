@@ -5,6 +5,7 @@ import (
         
         "github.com/yerenwgventures/GreatNigeriaLibrary-Foundation/backend/pkg/models"
         "gorm.io/gorm"
+        "gorm.io/gorm/clause"
 )
 
 // ProgressRepository defines the interface for user progress data operations
@@ -18,6 +19,11 @@ type ProgressRepository interface {
         UpdateProgress(userID, bookID, chapterID, sectionID uint, lastPosition int, percentComplete float64) (*models.UserProgress, error)
         GetByUserAndBook(userID, bookID uint) (*models.UserProgress, error)
         CheckBookCompletion(userID, bookID uint) (bool, error)
+
+        // CreateTopicCompletion records a completed section. It returns false
+        // and the existing record if the user already completed the section.
+        CreateTopicCompletion(completion *models.TopicCompletion) (*models.TopicCompletion, bool, error)
+        UpdateTopicCompletionPoints(completionID uint, points int) error
 }
 
 // GormProgressRepository implements ProgressRepository using GORM
@@ -143,4 +149,27 @@ func (r *GormProgressRepository) CheckBookCompletion(userID, bookID uint) (bool,
         
         // Consider a book completed if progress is over 95%
         return progress.PercentComplete >= 95.0, nil
-}
\ No newline at end of file
+}
+
+// CreateTopicCompletion records a completed section unless it already exists
+func (r *GormProgressRepository) CreateTopicCompletion(completion *models.TopicCompletion) (*models.TopicCompletion, bool, error) {
+        result := r.db.Clauses(clause.OnConflict{DoNothing: true}).Create(completion)
+        if result.Error != nil {
+                return nil, false, result.Error
+        }
+        if result.RowsAffected == 1 {
+                return completion, true, nil
+        }
+
+        var existing models.TopicCompletion
+        err := r.db.Where("user_id = ? AND section_id = ?", completion.UserID, completion.SectionID).First(&existing).Error
+        if err != nil {
+                return nil, false, err
+        }
+        return &existing, false, nil
+}
+
+// UpdateTopicCompletionPoints records the points awarded for a completion
+func (r *GormProgressRepository) UpdateTopicCompletionPoints(completionID uint, points int) error {
+        return r.db.Model(&models.TopicCompletion{}).Where("id = ?", completionID).Update("points", points).Error
+}
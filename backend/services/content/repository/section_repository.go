@@ -3,7 +3,6 @@ package repository
 import (
         "github.com/yerenwgventures/GreatNigeriaLibrary-Foundation/backend/services/content/models"
         "gorm.io/gorm"
-        "time"
 )
 
 // SectionRepository defines the interface for section data operations
@@ -96,10 +95,10 @@ func (r *GormSectionRepository) GetSectionRevisionByID(id uint) (*models.Section
         return &revision, nil
 }
 
-// GetScheduledSections retrieves all sections scheduled to be published
+// GetScheduledSections retrieves all sections with a pending publish or unpublish schedule
 func (r *GormSectionRepository) GetScheduledSections() ([]models.BookSection, error) {
         var sections []models.BookSection
-        if err := r.db.Where("scheduled_publish_at IS NOT NULL AND scheduled_publish_at > ?", time.Now()).Find(&sections).Error; err != nil {
+        if err := r.db.Where("scheduled_publish_at IS NOT NULL OR scheduled_unpublish_at IS NOT NULL").Find(&sections).Error; err != nil {
                 return nil, err
         }
         return sections, nil
@@ -0,0 +1,399 @@
+package search
+
+import (
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// BM25 tuning parameters and the extra weight given to title matches
+const (
+	bm25K1      = 1.2
+	bm25B       = 0.75
+	titleWeight = 2.0
+)
+
+// snippetWords is the number of words shown in a generated snippet
+const snippetWords = 30
+
+// memoryEntry is an indexed document with its pre-computed term statistics
+type memoryEntry struct {
+	doc        Document
+	tags       []string
+	titleTerms map[string]int
+	bodyTerms  map[string]int
+	length     int
+}
+
+// MemoryIndex is an embedded, pure-Go SearchIndex. It keeps everything in
+// memory and is intended for tests and single-instance development setups.
+type MemoryIndex struct {
+	mu      sync.RWMutex
+	entries map[string]*memoryEntry
+}
+
+// NewMemoryIndex creates an empty in-memory search index
+func NewMemoryIndex() *MemoryIndex {
+	return &MemoryIndex{
+		entries: make(map[string]*memoryEntry),
+	}
+}
+
+// Index adds or replaces documents
+func (m *MemoryIndex) Index(docs ...Document) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, doc := range docs {
+		entry := &memoryEntry{
+			doc:        doc,
+			tags:       normalizeTags(doc.Tags),
+			titleTerms: termFrequencies(tokenize(doc.Title)),
+			bodyTerms:  termFrequencies(tokenize(doc.Body)),
+		}
+		for _, count := range entry.titleTerms {
+			entry.length += count
+		}
+		for _, count := range entry.bodyTerms {
+			entry.length += count
+		}
+		entry.doc.Tags = entry.tags
+		m.entries[doc.Key()] = entry
+	}
+
+	return nil
+}
+
+// Remove deletes a single content item from the index
+func (m *MemoryIndex) Remove(contentType ContentType, contentID uint) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	delete(m.entries, DocumentKey(contentType, contentID))
+	return nil
+}
+
+// RemoveBook deletes every document that belongs to a book
+func (m *MemoryIndex) RemoveBook(bookID uint) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for key, entry := range m.entries {
+		if entry.doc.Location.BookID == bookID {
+			delete(m.entries, key)
+		}
+	}
+	return nil
+}
+
+// scoredEntry pairs an entry with its relevance score
+type scoredEntry struct {
+	entry *memoryEntry
+	score float64
+}
+
+// Search runs a ranked full-text query
+func (m *MemoryIndex) Search(query Query) (*Results, error) {
+	query.Normalize()
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	include, exclude := parseQueryTerms(query.Text)
+
+	// Collect every document that matches the query text
+	var matched []scoredEntry
+	averageLength := m.averageLength()
+	for _, entry := range m.entries {
+		if !entry.matches(include, exclude) {
+			continue
+		}
+		matched = append(matched, scoredEntry{
+			entry: entry,
+			score: m.score(entry, include, averageLength),
+		})
+	}
+
+	facets := buildFacets(matched)
+
+	// Apply the book, content type and tag filters
+	filtered := matched[:0:0]
+	for _, candidate := range matched {
+		if candidate.entry.passesFilters(query) {
+			filtered = append(filtered, candidate)
+		}
+	}
+
+	sort.Slice(filtered, func(i, j int) bool {
+		if filtered[i].score != filtered[j].score {
+			return filtered[i].score > filtered[j].score
+		}
+		return documentOrderLess(filtered[i].entry.doc, filtered[j].entry.doc)
+	})
+
+	results := &Results{
+		Query:      query.Text,
+		Hits:       make([]Hit, 0, query.PageSize),
+		Total:      len(filtered),
+		Page:       query.Page,
+		PageSize:   query.PageSize,
+		TotalPages: totalPages(len(filtered), query.PageSize),
+		Facets:     facets,
+	}
+
+	start := query.Offset()
+	if start > len(filtered) {
+		start = len(filtered)
+	}
+	end := start + query.PageSize
+	if end > len(filtered) {
+		end = len(filtered)
+	}
+
+	for _, candidate := range filtered[start:end] {
+		doc := candidate.entry.doc
+		results.Hits = append(results.Hits, Hit{
+			ContentType: doc.ContentType,
+			ContentID:   doc.ContentID,
+			Title:       doc.Title,
+			Snippet:     buildSnippet(doc.Body, include),
+			Score:       math.Round(candidate.score*10000) / 10000,
+			Tags:        doc.Tags,
+			Location:    doc.Location,
+		})
+	}
+
+	return results, nil
+}
+
+// averageLength returns the mean document length in terms
+func (m *MemoryIndex) averageLength() float64 {
+	if len(m.entries) == 0 {
+		return 0
+	}
+	total := 0
+	for _, entry := range m.entries {
+		total += entry.length
+	}
+	return float64(total) / float64(len(m.entries))
+}
+
+// score computes a BM25 relevance score with boosted title matches
+func (m *MemoryIndex) score(entry *memoryEntry, terms []string, averageLength float64) float64 {
+	if len(terms) == 0 || averageLength == 0 {
+		return 0
+	}
+
+	total := float64(len(m.entries))
+	score := 0.0
+	for _, term := range terms {
+		documentFrequency := 0
+		for _, other := range m.entries {
+			if other.titleTerms[term] > 0 || other.bodyTerms[term] > 0 {
+				documentFrequency++
+			}
+		}
+		idf := math.Log(1 + (total-float64(documentFrequency)+0.5)/(float64(documentFrequency)+0.5))
+
+		frequency := titleWeight*float64(entry.titleTerms[term]) + float64(entry.bodyTerms[term])
+		norm := bm25K1 * (1 - bm25B + bm25B*float64(entry.length)/averageLength)
+		score += idf * frequency * (bm25K1 + 1) / (frequency + norm)
+	}
+	return score
+}
+
+// matches reports whether the entry contains every included term and none of the excluded ones
+func (e *memoryEntry) matches(include, exclude []string) bool {
+	for _, term := range include {
+		if e.titleTerms[term] == 0 && e.bodyTerms[term] == 0 {
+			return false
+		}
+	}
+	for _, term := range exclude {
+		if e.titleTerms[term] > 0 || e.bodyTerms[term] > 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// passesFilters applies the query's book, content type and tag filters
+func (e *memoryEntry) passesFilters(query Query) bool {
+	if len(query.BookIDs) > 0 {
+		found := false
+		for _, bookID := range query.BookIDs {
+			if e.doc.Location.BookID == bookID {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+
+	if len(query.ContentTypes) > 0 {
+		found := false
+		for _, contentType := range query.ContentTypes {
+			if e.doc.ContentType == contentType {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+
+	// Every requested tag must be present
+	for _, tag := range normalizeTags(query.Tags) {
+		found := false
+		for _, own := range e.tags {
+			if own == tag {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+
+	return true
+}
+
+// buildFacets counts books, content types and tags across the matched entries
+func buildFacets(matched []scoredEntry) Facets {
+	bookCounts := make(map[uint]int)
+	bookLabels := make(map[uint]string)
+	typeCounts := make(map[string]int)
+	tagCounts := make(map[string]int)
+
+	for _, candidate := range matched {
+		doc := candidate.entry.doc
+		bookCounts[doc.Location.BookID]++
+		bookLabels[doc.Location.BookID] = doc.Location.BookTitle
+		typeCounts[string(doc.ContentType)]++
+		for _, tag := range candidate.entry.tags {
+			tagCounts[tag]++
+		}
+	}
+
+	facets := Facets{
+		Books:        make([]FacetValue, 0, len(bookCounts)),
+		ContentTypes: make([]FacetValue, 0, len(typeCounts)),
+		Tags:         make([]FacetValue, 0, len(tagCounts)),
+	}
+	for bookID, count := range bookCounts {
+		facets.Books = append(facets.Books, FacetValue{
+			Value: strconv.FormatUint(uint64(bookID), 10),
+			Label: bookLabels[bookID],
+			Count: count,
+		})
+	}
+	for contentType, count := range typeCounts {
+		facets.ContentTypes = append(facets.ContentTypes, FacetValue{Value: contentType, Count: count})
+	}
+	for tag, count := range tagCounts {
+		facets.Tags = append(facets.Tags, FacetValue{Value: tag, Count: count})
+	}
+
+	sortFacetValues(facets.Books)
+	sortFacetValues(facets.ContentTypes)
+	sortFacetValues(facets.Tags)
+	return facets
+}
+
+// sortFacetValues orders facet buckets by descending count, then value
+func sortFacetValues(values []FacetValue) {
+	sort.Slice(values, func(i, j int) bool {
+		if values[i].Count != values[j].Count {
+			return values[i].Count > values[j].Count
+		}
+		return values[i].Value < values[j].Value
+	})
+}
+
+// documentOrderLess orders documents by their position in the library
+func documentOrderLess(a, b Document) bool {
+	if a.Location.BookID != b.Location.BookID {
+		return a.Location.BookID < b.Location.BookID
+	}
+	if a.Location.ChapterNumber != b.Location.ChapterNumber {
+		return a.Location.ChapterNumber < b.Location.ChapterNumber
+	}
+	if a.Location.SectionNumber != b.Location.SectionNumber {
+		return a.Location.SectionNumber < b.Location.SectionNumber
+	}
+	return a.Key() < b.Key()
+}
+
+// buildSnippet picks the window of the body with the most matching terms
+// and highlights them
+func buildSnippet(body string, terms []string) string {
+	words := strings.Fields(body)
+	if len(words) == 0 {
+		return ""
+	}
+
+	wanted := make(map[string]bool, len(terms))
+	for _, term := range terms {
+		wanted[term] = true
+	}
+
+	hits := make([]bool, len(words))
+	for i, word := range words {
+		for _, token := range tokenize(word) {
+			if wanted[token] {
+				hits[i] = true
+				break
+			}
+		}
+	}
+
+	// Slide a window over the words to find the densest run of matches
+	start, best, current := 0, -1, 0
+	for i := range words {
+		if hits[i] {
+			current++
+		}
+		if i >= snippetWords && hits[i-snippetWords] {
+			current--
+		}
+		if i >= snippetWords-1 || i == len(words)-1 {
+			if current > best {
+				best = current
+				start = i - snippetWords + 1
+			}
+		}
+	}
+	if start < 0 {
+		start = 0
+	}
+	end := start + snippetWords
+	if end > len(words) {
+		end = len(words)
+	}
+
+	var snippet strings.Builder
+	if start > 0 {
+		snippet.WriteString("… ")
+	}
+	for i := start; i < end; i++ {
+		if i > start {
+			snippet.WriteString(" ")
+		}
+		if hits[i] {
+			snippet.WriteString(highlightStart + words[i] + highlightEnd)
+		} else {
+			snippet.WriteString(words[i])
+		}
+	}
+	if end < len(words) {
+		snippet.WriteString(" …")
+	}
+
+	return finishSnippet(snippet.String())
+}
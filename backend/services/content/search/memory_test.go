@@ -0,0 +1,150 @@
+package search
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestIndex(t *testing.T) *MemoryIndex {
+	index := NewMemoryIndex()
+	require.NoError(t, index.Index(
+		Document{
+			ContentType: ContentTypeBook,
+			ContentID:   1,
+			Title:       "Great Nigeria",
+			Body:        "A citizen's guide to nation building and civic participation.",
+			Tags:        []string{"Civics", "history"},
+			Location:    Location{BookID: 1, BookTitle: "Great Nigeria"},
+		},
+		Document{
+			ContentType: ContentTypeSection,
+			ContentID:   10,
+			Title:       "Building Institutions",
+			Body:        "Strong institutions are the foundation of accountable governance. Citizens build institutions together.",
+			Tags:        []string{"civics"},
+			Location:    Location{BookID: 1, BookTitle: "Great Nigeria", ChapterID: 2, ChapterNumber: 1, SectionID: 10, SectionNumber: 1},
+		},
+		Document{
+			ContentType: ContentTypeSection,
+			ContentID:   11,
+			Title:       "Oil and the Economy",
+			Body:        "The oil economy shaped governance and institutions across the federation.",
+			Tags:        []string{"economy"},
+			Location:    Location{BookID: 2, BookTitle: "Economic Transformation", ChapterID: 5, ChapterNumber: 1, SectionID: 11, SectionNumber: 1},
+		},
+		Document{
+			ContentType: ContentTypeCitation,
+			ContentID:   20,
+			Title:       "Report on <Governance>",
+			Body:        "An analysis of governance reform.",
+			Location:    Location{BookID: 2, BookTitle: "Economic Transformation"},
+		},
+	))
+	return index
+}
+
+func TestMemoryIndexRanksTitleMatchesHigher(t *testing.T) {
+	index := newTestIndex(t)
+
+	results, err := index.Search(Query{Text: "institutions"})
+	require.NoError(t, err)
+
+	require.Equal(t, 2, results.Total)
+	assert.Equal(t, uint(10), results.Hits[0].ContentID)
+	assert.Equal(t, uint(11), results.Hits[1].ContentID)
+	assert.Greater(t, results.Hits[0].Score, results.Hits[1].Score)
+}
+
+func TestMemoryIndexRequiresAllTermsAndHonoursExclusions(t *testing.T) {
+	index := newTestIndex(t)
+
+	results, err := index.Search(Query{Text: "governance institutions"})
+	require.NoError(t, err)
+	assert.Equal(t, 2, results.Total)
+
+	results, err = index.Search(Query{Text: "governance -oil"})
+	require.NoError(t, err)
+	require.Equal(t, 2, results.Total)
+	for _, hit := range results.Hits {
+		assert.NotEqual(t, uint(11), hit.ContentID)
+	}
+}
+
+func TestMemoryIndexFiltersAndFacets(t *testing.T) {
+	index := newTestIndex(t)
+
+	results, err := index.Search(Query{Text: "governance", BookIDs: []uint{2}, ContentTypes: []ContentType{ContentTypeSection}})
+	require.NoError(t, err)
+	require.Equal(t, 1, results.Total)
+	assert.Equal(t, uint(11), results.Hits[0].ContentID)
+
+	// Facets cover every text match, not just the filtered page
+	assert.Equal(t, []FacetValue{
+		{Value: "2", Label: "Economic Transformation", Count: 2},
+		{Value: "1", Label: "Great Nigeria", Count: 1},
+	}, results.Facets.Books)
+	assert.Equal(t, []FacetValue{
+		{Value: "section", Count: 2},
+		{Value: "citation", Count: 1},
+	}, results.Facets.ContentTypes)
+
+	results, err = index.Search(Query{Tags: []string{"CIVICS"}})
+	require.NoError(t, err)
+	assert.Equal(t, 2, results.Total)
+}
+
+func TestMemoryIndexPagination(t *testing.T) {
+	index := newTestIndex(t)
+
+	results, err := index.Search(Query{PageSize: 3, Page: 2})
+	require.NoError(t, err)
+
+	assert.Equal(t, 4, results.Total)
+	assert.Equal(t, 2, results.TotalPages)
+	require.Len(t, results.Hits, 1)
+}
+
+func TestMemoryIndexSnippetsAreEscapedAndHighlighted(t *testing.T) {
+	index := newTestIndex(t)
+	require.NoError(t, index.Index(Document{
+		ContentType: ContentTypeSection,
+		ContentID:   12,
+		Title:       "Long section",
+		Body:        strings.Repeat("filler ", 50) + "<b>federalism</b> matters " + strings.Repeat("filler ", 50),
+		Location:    Location{BookID: 3},
+	}))
+
+	results, err := index.Search(Query{Text: "federalism"})
+	require.NoError(t, err)
+	require.Equal(t, 1, results.Total)
+
+	snippet := results.Hits[0].Snippet
+	assert.True(t, strings.HasPrefix(snippet, "… "))
+	assert.True(t, strings.HasSuffix(snippet, " …"))
+	assert.Contains(t, snippet, "<mark>&lt;b&gt;federalism&lt;/b&gt;</mark>")
+}
+
+func TestMemoryIndexRemove(t *testing.T) {
+	index := newTestIndex(t)
+
+	require.NoError(t, index.Remove(ContentTypeSection, 10))
+	results, err := index.Search(Query{Text: "institutions"})
+	require.NoError(t, err)
+	assert.Equal(t, 1, results.Total)
+
+	require.NoError(t, index.RemoveBook(2))
+	results, err = index.Search(Query{})
+	require.NoError(t, err)
+	require.Equal(t, 1, results.Total)
+	assert.Equal(t, ContentTypeBook, results.Hits[0].ContentType)
+}
+
+func TestStem(t *testing.T) {
+	assert.Equal(t, tokenize("Nations"), tokenize("nation"))
+	assert.Equal(t, tokenize("studies"), tokenize("study"))
+	assert.Equal(t, []string{"build"}, tokenize("the building"))
+	assert.Equal(t, []string{"status"}, tokenize("status"))
+}
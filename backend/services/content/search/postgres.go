@@ -0,0 +1,295 @@
+package search
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// searchDocument is the row stored in the search_documents table, created by
+// the 0018_create_search_documents migration. Tags are stored as ",a,b," so
+// single tags can be matched with LIKE.
+type searchDocument struct {
+	ID            uint   `gorm:"primarykey"`
+	DocKey        string `gorm:"size:64;uniqueIndex"`
+	ContentType   string `gorm:"size:32;index"`
+	ContentID     uint
+	BookID        uint `gorm:"index"`
+	BookTitle     string
+	ChapterID     uint
+	ChapterTitle  string
+	ChapterNumber int
+	SectionID     uint
+	SectionTitle  string
+	SectionNumber int
+	Title         string
+	Body          string `gorm:"type:text"`
+	Tags          string `gorm:"type:text"`
+	UpdatedAt     time.Time
+}
+
+// TableName specifies the table name for searchDocument
+func (searchDocument) TableName() string {
+	return "search_documents"
+}
+
+// searchRow is a searchDocument with its rank and highlighted snippet
+type searchRow struct {
+	searchDocument
+	Rank    float64
+	Snippet string
+}
+
+// facetRow is a single facet bucket returned by a GROUP BY query
+type facetRow struct {
+	Value string
+	Label string
+	Count int
+}
+
+// PostgresIndex is a SearchIndex backed by PostgreSQL full-text search
+type PostgresIndex struct {
+	db *gorm.DB
+}
+
+// NewPostgresIndex creates a new PostgreSQL search index
+func NewPostgresIndex(db *gorm.DB) *PostgresIndex {
+	return &PostgresIndex{db: db}
+}
+
+// Index adds or replaces documents
+func (p *PostgresIndex) Index(docs ...Document) error {
+	if len(docs) == 0 {
+		return nil
+	}
+
+	return p.db.Transaction(func(tx *gorm.DB) error {
+		for _, doc := range docs {
+			row := toSearchDocument(doc)
+			if err := tx.Where("doc_key = ?", row.DocKey).Delete(&searchDocument{}).Error; err != nil {
+				return fmt.Errorf("failed to index %s: %w", row.DocKey, err)
+			}
+			if err := tx.Create(&row).Error; err != nil {
+				return fmt.Errorf("failed to index %s: %w", row.DocKey, err)
+			}
+		}
+		return nil
+	})
+}
+
+// Remove deletes a single content item from the index
+func (p *PostgresIndex) Remove(contentType ContentType, contentID uint) error {
+	return p.db.Where("doc_key = ?", DocumentKey(contentType, contentID)).
+		Delete(&searchDocument{}).Error
+}
+
+// RemoveBook deletes every document that belongs to a book
+func (p *PostgresIndex) RemoveBook(bookID uint) error {
+	return p.db.Where("book_id = ?", bookID).Delete(&searchDocument{}).Error
+}
+
+// Search runs a ranked full-text query using websearch_to_tsquery, so the
+// query text supports quoted phrases, OR and -exclusions
+func (p *PostgresIndex) Search(query Query) (*Results, error) {
+	query.Normalize()
+
+	// matched selects everything matching the query text; facets are computed from it
+	matched := func() *gorm.DB {
+		db := p.db.Model(&searchDocument{})
+		if query.Text != "" {
+			db = db.Where("search_vector @@ websearch_to_tsquery('english', ?)", query.Text)
+		}
+		return db
+	}
+
+	// filtered additionally applies the book, content type and tag filters
+	filtered := func() *gorm.DB {
+		db := matched()
+		if len(query.BookIDs) > 0 {
+			db = db.Where("book_id IN ?", query.BookIDs)
+		}
+		if len(query.ContentTypes) > 0 {
+			types := make([]string, len(query.ContentTypes))
+			for i, contentType := range query.ContentTypes {
+				types[i] = string(contentType)
+			}
+			db = db.Where("content_type IN ?", types)
+		}
+		for _, tag := range normalizeTags(query.Tags) {
+			db = db.Where(`tags LIKE ? ESCAPE '\'`, "%,"+escapeLike(tag)+",%")
+		}
+		return db
+	}
+
+	var total int64
+	if err := filtered().Count(&total).Error; err != nil {
+		return nil, fmt.Errorf("failed to count search results: %w", err)
+	}
+
+	var rows []searchRow
+	db := filtered()
+	if query.Text != "" {
+		headlineOptions := fmt.Sprintf("StartSel=%s, StopSel=%s, MaxWords=35, MinWords=15, MaxFragments=2, FragmentDelimiter=\" … \"", highlightStart, highlightEnd)
+		db = db.Select(
+			"search_documents.*, "+
+				"ts_rank_cd(search_vector, websearch_to_tsquery('english', ?)) AS rank, "+
+				"ts_headline('english', body, websearch_to_tsquery('english', ?), ?) AS snippet",
+			query.Text, query.Text, headlineOptions,
+		).Order("rank DESC")
+	} else {
+		db = db.Select("search_documents.*, 0 AS rank, left(body, 240) AS snippet")
+	}
+	err := db.Order("book_id, chapter_number, section_number, doc_key").
+		Offset(query.Offset()).
+		Limit(query.PageSize).
+		Scan(&rows).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to search content: %w", err)
+	}
+
+	facets, err := p.facets(matched)
+	if err != nil {
+		return nil, err
+	}
+
+	results := &Results{
+		Query:      query.Text,
+		Hits:       make([]Hit, 0, len(rows)),
+		Total:      int(total),
+		Page:       query.Page,
+		PageSize:   query.PageSize,
+		TotalPages: totalPages(int(total), query.PageSize),
+		Facets:     facets,
+	}
+	for _, row := range rows {
+		doc := row.toDocument()
+		results.Hits = append(results.Hits, Hit{
+			ContentType: doc.ContentType,
+			ContentID:   doc.ContentID,
+			Title:       doc.Title,
+			Snippet:     finishSnippet(row.Snippet),
+			Score:       row.Rank,
+			Tags:        doc.Tags,
+			Location:    doc.Location,
+		})
+	}
+
+	return results, nil
+}
+
+// facets computes book, content type and tag counts over the text matches
+func (p *PostgresIndex) facets(matched func() *gorm.DB) (Facets, error) {
+	var books, types, tags []facetRow
+
+	err := matched().
+		Select("book_id AS value, max(book_title) AS label, count(*) AS count").
+		Group("book_id").
+		Order("count DESC, value").
+		Scan(&books).Error
+	if err != nil {
+		return Facets{}, fmt.Errorf("failed to compute book facets: %w", err)
+	}
+
+	err = matched().
+		Select("content_type AS value, count(*) AS count").
+		Group("content_type").
+		Order("count DESC, value").
+		Scan(&types).Error
+	if err != nil {
+		return Facets{}, fmt.Errorf("failed to compute content type facets: %w", err)
+	}
+
+	err = p.db.Table("(?) AS matched", matched().Select("tags")).
+		Select("tag AS value, count(*) AS count").
+		Joins("CROSS JOIN LATERAL unnest(string_to_array(trim(both ',' from matched.tags), ',')) AS tag").
+		Where("tag <> ''").
+		Group("tag").
+		Order("count DESC, value").
+		Scan(&tags).Error
+	if err != nil {
+		return Facets{}, fmt.Errorf("failed to compute tag facets: %w", err)
+	}
+
+	return Facets{
+		Books:        toFacetValues(books),
+		ContentTypes: toFacetValues(types),
+		Tags:         toFacetValues(tags),
+	}, nil
+}
+
+// toFacetValues converts facet rows into facet values
+func toFacetValues(rows []facetRow) []FacetValue {
+	values := make([]FacetValue, len(rows))
+	for i, row := range rows {
+		values[i] = FacetValue{Value: row.Value, Label: row.Label, Count: row.Count}
+	}
+	return values
+}
+
+// toSearchDocument converts a Document into its table row
+func toSearchDocument(doc Document) searchDocument {
+	tags := ""
+	if normalized := normalizeTags(doc.Tags); len(normalized) > 0 {
+		tags = "," + strings.Join(normalized, ",") + ","
+	}
+
+	updatedAt := doc.UpdatedAt
+	if updatedAt.IsZero() {
+		updatedAt = time.Now()
+	}
+
+	return searchDocument{
+		DocKey:        doc.Key(),
+		ContentType:   string(doc.ContentType),
+		ContentID:     doc.ContentID,
+		BookID:        doc.Location.BookID,
+		BookTitle:     doc.Location.BookTitle,
+		ChapterID:     doc.Location.ChapterID,
+		ChapterTitle:  doc.Location.ChapterTitle,
+		ChapterNumber: doc.Location.ChapterNumber,
+		SectionID:     doc.Location.SectionID,
+		SectionTitle:  doc.Location.SectionTitle,
+		SectionNumber: doc.Location.SectionNumber,
+		Title:         doc.Title,
+		Body:          doc.Body,
+		Tags:          tags,
+		UpdatedAt:     updatedAt,
+	}
+}
+
+// toDocument converts a table row back into a Document
+func (d searchDocument) toDocument() Document {
+	var tags []string
+	if trimmed := strings.Trim(d.Tags, ","); trimmed != "" {
+		tags = strings.Split(trimmed, ",")
+	}
+
+	return Document{
+		ContentType: ContentType(d.ContentType),
+		ContentID:   d.ContentID,
+		Title:       d.Title,
+		Body:        d.Body,
+		Tags:        tags,
+		Location: Location{
+			BookID:        d.BookID,
+			BookTitle:     d.BookTitle,
+			ChapterID:     d.ChapterID,
+			ChapterTitle:  d.ChapterTitle,
+			ChapterNumber: d.ChapterNumber,
+			SectionID:     d.SectionID,
+			SectionTitle:  d.SectionTitle,
+			SectionNumber: d.SectionNumber,
+		},
+		UpdatedAt: d.UpdatedAt,
+	}
+}
+
+// likeEscaper escapes the LIKE wildcards in a literal pattern fragment
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// escapeLike makes a tag match itself literally in a LIKE pattern
+func escapeLike(value string) string {
+	return likeEscaper.Replace(value)
+}
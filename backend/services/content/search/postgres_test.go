@@ -0,0 +1,51 @@
+package search
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// likeMatch evaluates a LIKE pattern with ESCAPE '\' the way PostgreSQL does
+func likeMatch(pattern, value string) bool {
+	var expr strings.Builder
+	expr.WriteString("^")
+	for i := 0; i < len(pattern); i++ {
+		switch c := pattern[i]; {
+		case c == '\\' && i+1 < len(pattern):
+			i++
+			expr.WriteString(regexp.QuoteMeta(string(pattern[i])))
+		case c == '%':
+			expr.WriteString(".*")
+		case c == '_':
+			expr.WriteString(".")
+		default:
+			expr.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	expr.WriteString("$")
+	return regexp.MustCompile(expr.String()).MatchString(value)
+}
+
+func TestEscapeLikeMatchesTagsLiterally(t *testing.T) {
+	stored := ",civic_duty,civicxduty,100%,1000,back\\slash,"
+
+	cases := []struct {
+		tag     string
+		matches string
+		misses  string
+	}{
+		{tag: "civic_duty", matches: ",civic_duty,", misses: ",civicxduty,"},
+		{tag: "100%", matches: ",100%,", misses: ",1000,"},
+		{tag: "back\\slash", matches: ",back\\slash,", misses: ",backslash,"},
+	}
+	for _, tc := range cases {
+		pattern := "%," + escapeLike(tc.tag) + ",%"
+
+		assert.True(t, likeMatch(pattern, stored), tc.tag)
+		assert.True(t, likeMatch(pattern, tc.matches), tc.tag)
+		assert.False(t, likeMatch(pattern, tc.misses), tc.tag)
+	}
+}
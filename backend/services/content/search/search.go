@@ -0,0 +1,175 @@
+package search
+
+import (
+	"fmt"
+	"html"
+	"strings"
+	"time"
+)
+
+// ContentType identifies the kind of content a search document was built from
+type ContentType string
+
+const (
+	ContentTypeBook        ContentType = "book"
+	ContentTypeChapter     ContentType = "chapter"
+	ContentTypeSection     ContentType = "section"
+	ContentTypeFrontMatter ContentType = "front_matter"
+	ContentTypeBackMatter  ContentType = "back_matter"
+	ContentTypeCitation    ContentType = "citation"
+	ContentTypeDiscussion  ContentType = "discussion"
+)
+
+// Default and maximum page sizes for search queries
+const (
+	DefaultPageSize = 20
+	MaxPageSize     = 100
+)
+
+// Location points at the place in a book a search document belongs to
+type Location struct {
+	BookID        uint   `json:"book_id"`
+	BookTitle     string `json:"book_title"`
+	ChapterID     uint   `json:"chapter_id,omitempty"`
+	ChapterTitle  string `json:"chapter_title,omitempty"`
+	ChapterNumber int    `json:"chapter_number,omitempty"`
+	SectionID     uint   `json:"section_id,omitempty"`
+	SectionTitle  string `json:"section_title,omitempty"`
+	SectionNumber int    `json:"section_number,omitempty"`
+}
+
+// Document is a unit of searchable content
+type Document struct {
+	ContentType ContentType `json:"content_type"`
+	ContentID   uint        `json:"content_id"`
+	Title       string      `json:"title"`
+	Body        string      `json:"body"` // Plain text, markup already stripped
+	Tags        []string    `json:"tags"`
+	Location    Location    `json:"location"`
+	UpdatedAt   time.Time   `json:"updated_at"`
+}
+
+// Key returns the unique key of the document within an index
+func (d Document) Key() string {
+	return DocumentKey(d.ContentType, d.ContentID)
+}
+
+// DocumentKey builds the unique index key for a content item
+func DocumentKey(contentType ContentType, contentID uint) string {
+	return fmt.Sprintf("%s:%d", contentType, contentID)
+}
+
+// Query describes a search request
+type Query struct {
+	Text         string        `json:"text"`
+	BookIDs      []uint        `json:"book_ids,omitempty"`
+	ContentTypes []ContentType `json:"content_types,omitempty"`
+	Tags         []string      `json:"tags,omitempty"`
+	Page         int           `json:"page"`
+	PageSize     int           `json:"page_size"`
+}
+
+// Normalize applies pagination defaults and limits
+func (q *Query) Normalize() {
+	q.Text = strings.TrimSpace(q.Text)
+	if q.Page < 1 {
+		q.Page = 1
+	}
+	if q.PageSize < 1 {
+		q.PageSize = DefaultPageSize
+	}
+	if q.PageSize > MaxPageSize {
+		q.PageSize = MaxPageSize
+	}
+}
+
+// Offset returns the number of hits to skip for the requested page
+func (q Query) Offset() int {
+	return (q.Page - 1) * q.PageSize
+}
+
+// Hit is a single ranked search result
+type Hit struct {
+	ContentType ContentType `json:"content_type"`
+	ContentID   uint        `json:"content_id"`
+	Title       string      `json:"title"`
+	Snippet     string      `json:"snippet"` // HTML-escaped, matches wrapped in <mark>
+	Score       float64     `json:"score"`
+	Tags        []string    `json:"tags,omitempty"`
+	Location    Location    `json:"location"`
+}
+
+// FacetValue is a single facet bucket
+type FacetValue struct {
+	Value string `json:"value"`
+	Label string `json:"label,omitempty"`
+	Count int    `json:"count"`
+}
+
+// Facets summarises the documents matching the query text. Facet counts
+// ignore the book, type and tag filters so clients can offer alternatives.
+type Facets struct {
+	Books        []FacetValue `json:"books"`
+	ContentTypes []FacetValue `json:"content_types"`
+	Tags         []FacetValue `json:"tags"`
+}
+
+// Results is a page of search hits with facets
+type Results struct {
+	Query      string `json:"query"`
+	Hits       []Hit  `json:"hits"`
+	Total      int    `json:"total"`
+	Page       int    `json:"page"`
+	PageSize   int    `json:"page_size"`
+	TotalPages int    `json:"total_pages"`
+	Facets     Facets `json:"facets"`
+}
+
+// SearchIndex is implemented by full-text search backends
+type SearchIndex interface {
+	// Index adds or replaces documents
+	Index(docs ...Document) error
+	// Remove deletes a single content item from the index
+	Remove(contentType ContentType, contentID uint) error
+	// RemoveBook deletes every document that belongs to a book
+	RemoveBook(bookID uint) error
+	// Search runs a ranked full-text query
+	Search(query Query) (*Results, error)
+}
+
+// totalPages computes the page count for a result set
+func totalPages(total, pageSize int) int {
+	if pageSize <= 0 || total == 0 {
+		return 0
+	}
+	return (total + pageSize - 1) / pageSize
+}
+
+// normalizeTags lowercases, trims and de-duplicates tags
+func normalizeTags(tags []string) []string {
+	seen := make(map[string]bool, len(tags))
+	normalized := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		tag = strings.ToLower(strings.TrimSpace(tag))
+		if tag == "" || strings.Contains(tag, ",") || seen[tag] {
+			continue
+		}
+		seen[tag] = true
+		normalized = append(normalized, tag)
+	}
+	return normalized
+}
+
+// Snippets are built with these markers so the surrounding text can be
+// HTML-escaped before the matches are wrapped in <mark> tags
+const (
+	highlightStart = "\ue002"
+	highlightEnd   = "\ue003"
+)
+
+// finishSnippet escapes a marked-up snippet and converts the markers into <mark> tags
+func finishSnippet(snippet string) string {
+	snippet = html.EscapeString(snippet)
+	snippet = strings.ReplaceAll(snippet, highlightStart, "<mark>")
+	return strings.ReplaceAll(snippet, highlightEnd, "</mark>")
+}
@@ -0,0 +1,84 @@
+package search
+
+import (
+	"strings"
+	"unicode"
+)
+
+// stopWords are common English words that carry no search value
+var stopWords = map[string]bool{
+	"a": true, "an": true, "and": true, "are": true, "as": true, "at": true,
+	"be": true, "by": true, "for": true, "from": true, "has": true, "in": true,
+	"is": true, "it": true, "of": true, "on": true, "or": true, "that": true,
+	"the": true, "this": true, "to": true, "was": true, "were": true, "with": true,
+}
+
+// tokenize splits text into lowercase, stemmed search terms
+func tokenize(text string) []string {
+	fields := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+
+	terms := make([]string, 0, len(fields))
+	for _, field := range fields {
+		if stopWords[field] {
+			continue
+		}
+		terms = append(terms, stem(field))
+	}
+	return terms
+}
+
+// stem strips common English inflections so "nations" matches "nation".
+// It is deliberately conservative; words shorter than five letters are kept.
+func stem(term string) string {
+	if len(term) < 5 {
+		return term
+	}
+	switch {
+	case strings.HasSuffix(term, "ies"):
+		return term[:len(term)-3] + "y"
+	case strings.HasSuffix(term, "ing") && len(term) > 5:
+		return term[:len(term)-3]
+	case strings.HasSuffix(term, "ed") && len(term) > 5:
+		return term[:len(term)-2]
+	case strings.HasSuffix(term, "es") && (strings.HasSuffix(term, "sses") || strings.HasSuffix(term, "ches") || strings.HasSuffix(term, "shes") || strings.HasSuffix(term, "xes")):
+		return term[:len(term)-2]
+	case strings.HasSuffix(term, "s") && !strings.HasSuffix(term, "ss") && !strings.HasSuffix(term, "us") && !strings.HasSuffix(term, "is"):
+		return term[:len(term)-1]
+	}
+	return term
+}
+
+// termFrequencies counts how often each term occurs
+func termFrequencies(terms []string) map[string]int {
+	frequencies := make(map[string]int, len(terms))
+	for _, term := range terms {
+		frequencies[term]++
+	}
+	return frequencies
+}
+
+// parseQueryTerms splits query text into required and excluded terms.
+// Words prefixed with "-" are excluded; quotes are ignored.
+func parseQueryTerms(text string) (include, exclude []string) {
+	seen := make(map[string]bool)
+	for _, word := range strings.Fields(text) {
+		negated := strings.HasPrefix(word, "-") && len(word) > 1
+		if negated {
+			word = word[1:]
+		}
+		for _, term := range tokenize(word) {
+			if seen[term] {
+				continue
+			}
+			seen[term] = true
+			if negated {
+				exclude = append(exclude, term)
+			} else {
+				include = append(include, term)
+			}
+		}
+	}
+	return include, exclude
+}
@@ -1,13 +1,16 @@
 package service
 
 import (
+        "context"
         "encoding/csv"
         "encoding/json"
         "fmt"
         "io"
+        "log"
         "strings"
         "time"
 
+        "github.com/yerenwgventures/GreatNigeriaLibrary-Foundation/backend/pkg/common/audit"
         "github.com/yerenwgventures/GreatNigeriaLibrary-Foundation/backend/services/content/models"
         "github.com/yerenwgventures/GreatNigeriaLibrary-Foundation/backend/services/content/repository"
 )
@@ -37,17 +40,21 @@ type ContentAdminService interface {
         GetBookRevisions(bookID uint) ([]models.BookRevision, error)
         GetChapterRevisions(chapterID uint) ([]models.ChapterRevision, error)
         GetSectionRevisions(sectionID uint) ([]models.SectionRevision, error)
-        RestoreBookRevision(revisionID uint) error
-        RestoreChapterRevision(revisionID uint) error
-        RestoreSectionRevision(revisionID uint) error
+        RestoreBookRevision(ctx context.Context, revisionID uint) error
+        RestoreChapterRevision(ctx context.Context, revisionID uint) error
+        RestoreSectionRevision(ctx context.Context, revisionID uint) error
         
         // Content scheduling and publishing
         ScheduleBookPublishing(bookID uint, publishDate time.Time) error
         ScheduleChapterPublishing(chapterID uint, publishDate time.Time) error
         ScheduleSectionPublishing(sectionID uint, publishDate time.Time) error
+        ScheduleUnpublishing(contentType string, contentID uint, unpublishDate time.Time) error
         GetScheduledContent() ([]interface{}, error)
-        PublishContent(contentType string, contentID uint) error
-        UnpublishContent(contentType string, contentID uint) error
+        PublishContent(ctx context.Context, contentType string, contentID uint) error
+        UnpublishContent(ctx context.Context, contentType string, contentID uint) error
+
+        // SetAuditRecorder sets the recorder of publishing changes and revision restores
+        SetAuditRecorder(recorder *audit.Recorder)
 }
 
 // ContentAdminServiceImpl implements the ContentAdminService interface
@@ -55,6 +62,8 @@ type ContentAdminServiceImpl struct {
         bookRepo    repository.BookRepository
         chapterRepo repository.ChapterRepository
         sectionRepo repository.SectionRepository
+        indexer     SearchIndexer
+        audit       *audit.Recorder
 }
 
 // NewContentAdminService creates a new content admin service
@@ -62,11 +71,37 @@ func NewContentAdminService(
         bookRepo repository.BookRepository,
         chapterRepo repository.ChapterRepository,
         sectionRepo repository.SectionRepository,
+        indexer SearchIndexer,
 ) ContentAdminService {
         return &ContentAdminServiceImpl{
                 bookRepo:    bookRepo,
                 chapterRepo: chapterRepo,
                 sectionRepo: sectionRepo,
+                indexer:     indexer,
+        }
+}
+
+// syncSearchIndex refreshes the search index after content changes. Index
+// failures are logged rather than failing the admin operation.
+func (s *ContentAdminServiceImpl) syncSearchIndex(contentType string, contentID uint) {
+        if s.indexer == nil {
+                return
+        }
+        if err := s.indexer.IndexContent(contentType, contentID); err != nil {
+                log.Printf("Error updating search index for %s %d: %v", contentType, contentID, err)
+        }
+}
+
+// SetAuditRecorder sets the recorder of publishing changes and revision restores
+func (s *ContentAdminServiceImpl) SetAuditRecorder(recorder *audit.Recorder) {
+        s.audit = recorder
+}
+
+// recordAudit records an event in the audit log. Audit failures are logged
+// rather than failing the admin operation, which already happened.
+func (s *ContentAdminServiceImpl) recordAudit(ctx context.Context, event audit.Event) {
+        if err := s.audit.Record(ctx, event); err != nil {
+                log.Printf("Error recording %s of %s %v: %v", event.Action, event.TargetType, event.TargetID, err)
         }
 }
 
@@ -101,6 +136,7 @@ func (s *ContentAdminServiceImpl) ImportBooksFromJSON(reader io.Reader) ([]model
                         return importedBooks, fmt.Errorf("error saving book '%s': %w", book.Title, err)
                 }
                 
+                s.syncSearchIndex("book", book.ID)
                 importedBooks = append(importedBooks, book)
         }
         
@@ -282,6 +318,7 @@ func (s *ContentAdminServiceImpl) ImportBooksFromCSV(reader io.Reader) ([]models
                         return importedBooks, fmt.Errorf("error saving book '%s': %w", book.Title, saveErr)
                 }
                 
+                s.syncSearchIndex("book", book.ID)
                 importedBooks = append(importedBooks, book)
         }
         
@@ -686,6 +723,10 @@ func (s *ContentAdminServiceImpl) CreateBookRevision(bookID uint, changes map[st
                         if title, ok := value.(string); ok {
                                 book.Title = title
                         }
+                case "subtitle":
+                        if subtitle, ok := value.(string); ok {
+                                book.Subtitle = subtitle
+                        }
                 case "description":
                         if description, ok := value.(string); ok {
                                 book.Description = description
@@ -710,6 +751,7 @@ func (s *ContentAdminServiceImpl) CreateBookRevision(bookID uint, changes map[st
         if err := s.bookRepo.UpdateBook(book); err != nil {
                 return nil, fmt.Errorf("error updating book: %w", err)
         }
+        s.syncSearchIndex("book", bookID)
         
         return revision, nil
 }
@@ -751,6 +793,10 @@ func (s *ContentAdminServiceImpl) CreateChapterRevision(chapterID uint, changes
                         if description, ok := value.(string); ok {
                                 chapter.Description = description
                         }
+                case "content":
+                        if content, ok := value.(string); ok {
+                                chapter.Content = content
+                        }
                 case "number":
                         if number, ok := value.(int); ok {
                                 chapter.Number = number
@@ -767,6 +813,7 @@ func (s *ContentAdminServiceImpl) CreateChapterRevision(chapterID uint, changes
         if err := s.chapterRepo.UpdateChapter(chapter); err != nil {
                 return nil, fmt.Errorf("error updating chapter: %w", err)
         }
+        s.syncSearchIndex("chapter", chapterID)
         
         return revision, nil
 }
@@ -832,6 +879,7 @@ func (s *ContentAdminServiceImpl) CreateSectionRevision(sectionID uint, changes
         if err := s.sectionRepo.UpdateSection(section); err != nil {
                 return nil, fmt.Errorf("error updating section: %w", err)
         }
+        s.syncSearchIndex("section", sectionID)
         
         return revision, nil
 }
@@ -852,7 +900,7 @@ func (s *ContentAdminServiceImpl) GetSectionRevisions(sectionID uint) ([]models.
 }
 
 // RestoreBookRevision restores a book to a previous revision
-func (s *ContentAdminServiceImpl) RestoreBookRevision(revisionID uint) error {
+func (s *ContentAdminServiceImpl) RestoreBookRevision(ctx context.Context, revisionID uint) error {
         // Get the revision
         revision, err := s.bookRepo.GetBookRevisionByID(revisionID)
         if err != nil {
@@ -864,6 +912,10 @@ func (s *ContentAdminServiceImpl) RestoreBookRevision(revisionID uint) error {
         if err := json.Unmarshal([]byte(revision.Content), &book); err != nil {
                 return fmt.Errorf("error unmarshaling book revision: %w", err)
         }
+        current, err := s.bookRepo.GetBookByID(book.ID)
+        if err != nil {
+                return fmt.Errorf("error getting book with ID %d: %w", book.ID, err)
+        }
         
         // Update revision date
         book.UpdatedAt = time.Now()
@@ -872,12 +924,20 @@ func (s *ContentAdminServiceImpl) RestoreBookRevision(revisionID uint) error {
         if err := s.bookRepo.UpdateBook(&book); err != nil {
                 return fmt.Errorf("error restoring book from revision: %w", err)
         }
+        s.syncSearchIndex("book", book.ID)
+        s.recordAudit(ctx, audit.Event{
+                Action:     "content.revision_restored",
+                TargetType: "book",
+                TargetID:   book.ID,
+                Before:     current,
+                After:      book,
+        })
         
         return nil
 }
 
 // RestoreChapterRevision restores a chapter to a previous revision
-func (s *ContentAdminServiceImpl) RestoreChapterRevision(revisionID uint) error {
+func (s *ContentAdminServiceImpl) RestoreChapterRevision(ctx context.Context, revisionID uint) error {
         // Get the revision
         revision, err := s.chapterRepo.GetChapterRevisionByID(revisionID)
         if err != nil {
@@ -889,6 +949,10 @@ func (s *ContentAdminServiceImpl) RestoreChapterRevision(revisionID uint) error
         if err := json.Unmarshal([]byte(revision.Content), &chapter); err != nil {
                 return fmt.Errorf("error unmarshaling chapter revision: %w", err)
         }
+        current, err := s.chapterRepo.GetChapterByID(chapter.ID)
+        if err != nil {
+                return fmt.Errorf("error getting chapter with ID %d: %w", chapter.ID, err)
+        }
         
         // Update revision date
         chapter.UpdatedAt = time.Now()
@@ -897,12 +961,20 @@ func (s *ContentAdminServiceImpl) RestoreChapterRevision(revisionID uint) error
         if err := s.chapterRepo.UpdateChapter(&chapter); err != nil {
                 return fmt.Errorf("error restoring chapter from revision: %w", err)
         }
+        s.syncSearchIndex("chapter", chapter.ID)
+        s.recordAudit(ctx, audit.Event{
+                Action:     "content.revision_restored",
+                TargetType: "chapter",
+                TargetID:   chapter.ID,
+                Before:     current,
+                After:      chapter,
+        })
         
         return nil
 }
 
 // RestoreSectionRevision restores a section to a previous revision
-func (s *ContentAdminServiceImpl) RestoreSectionRevision(revisionID uint) error {
+func (s *ContentAdminServiceImpl) RestoreSectionRevision(ctx context.Context, revisionID uint) error {
         // Get the revision
         revision, err := s.sectionRepo.GetSectionRevisionByID(revisionID)
         if err != nil {
@@ -914,6 +986,10 @@ func (s *ContentAdminServiceImpl) RestoreSectionRevision(revisionID uint) error
         if err := json.Unmarshal([]byte(revision.Content), &section); err != nil {
                 return fmt.Errorf("error unmarshaling section revision: %w", err)
         }
+        current, err := s.sectionRepo.GetSectionByID(section.ID)
+        if err != nil {
+                return fmt.Errorf("error getting section with ID %d: %w", section.ID, err)
+        }
         
         // Update revision date
         section.UpdatedAt = time.Now()
@@ -922,6 +998,14 @@ func (s *ContentAdminServiceImpl) RestoreSectionRevision(revisionID uint) error
         if err := s.sectionRepo.UpdateSection(&section); err != nil {
                 return fmt.Errorf("error restoring section from revision: %w", err)
         }
+        s.syncSearchIndex("section", section.ID)
+        s.recordAudit(ctx, audit.Event{
+                Action:     "content.revision_restored",
+                TargetType: "section",
+                TargetID:   section.ID,
+                Before:     current,
+                After:      section,
+        })
         
         return nil
 }
@@ -934,6 +1018,10 @@ func (s *ContentAdminServiceImpl) ScheduleBookPublishing(bookID uint, publishDat
                 return fmt.Errorf("error getting book with ID %d: %w", bookID, err)
         }
         
+        if err := validatePublishingWindow(&publishDate, book.ScheduledUnpublishAt); err != nil {
+                return err
+        }
+        
         // Update the book's scheduled publish date
         book.ScheduledPublishAt = &publishDate
         book.UpdatedAt = time.Now()
@@ -954,6 +1042,10 @@ func (s *ContentAdminServiceImpl) ScheduleChapterPublishing(chapterID uint, publ
                 return fmt.Errorf("error getting chapter with ID %d: %w", chapterID, err)
         }
         
+        if err := validatePublishingWindow(&publishDate, chapter.ScheduledUnpublishAt); err != nil {
+                return err
+        }
+        
         // Update the chapter's scheduled publish date
         chapter.ScheduledPublishAt = &publishDate
         chapter.UpdatedAt = time.Now()
@@ -974,6 +1066,10 @@ func (s *ContentAdminServiceImpl) ScheduleSectionPublishing(sectionID uint, publ
                 return fmt.Errorf("error getting section with ID %d: %w", sectionID, err)
         }
         
+        if err := validatePublishingWindow(&publishDate, section.ScheduledUnpublishAt); err != nil {
+                return err
+        }
+        
         // Update the section's scheduled publish date
         section.ScheduledPublishAt = &publishDate
         section.UpdatedAt = time.Now()
@@ -986,7 +1082,76 @@ func (s *ContentAdminServiceImpl) ScheduleSectionPublishing(sectionID uint, publ
         return nil
 }
 
-// GetScheduledContent gets all content scheduled to be published
+// ScheduleUnpublishing schedules published or scheduled content to be
+// unpublished at a future date, ending its publishing window
+func (s *ContentAdminServiceImpl) ScheduleUnpublishing(contentType string, contentID uint, unpublishDate time.Time) error {
+        switch contentType {
+        case "book":
+                book, err := s.bookRepo.GetBookByID(contentID)
+                if err != nil {
+                        return fmt.Errorf("error getting book with ID %d: %w", contentID, err)
+                }
+                if err := validatePublishingWindow(book.ScheduledPublishAt, &unpublishDate); err != nil {
+                        return err
+                }
+                
+                book.ScheduledUnpublishAt = &unpublishDate
+                book.UpdatedAt = time.Now()
+                
+                if err := s.bookRepo.UpdateBook(book); err != nil {
+                        return fmt.Errorf("error scheduling book unpublishing: %w", err)
+                }
+                
+        case "chapter":
+                chapter, err := s.chapterRepo.GetChapterByID(contentID)
+                if err != nil {
+                        return fmt.Errorf("error getting chapter with ID %d: %w", contentID, err)
+                }
+                if err := validatePublishingWindow(chapter.ScheduledPublishAt, &unpublishDate); err != nil {
+                        return err
+                }
+                
+                chapter.ScheduledUnpublishAt = &unpublishDate
+                chapter.UpdatedAt = time.Now()
+                
+                if err := s.chapterRepo.UpdateChapter(chapter); err != nil {
+                        return fmt.Errorf("error scheduling chapter unpublishing: %w", err)
+                }
+                
+        case "section":
+                section, err := s.sectionRepo.GetSectionByID(contentID)
+                if err != nil {
+                        return fmt.Errorf("error getting section with ID %d: %w", contentID, err)
+                }
+                if err := validatePublishingWindow(section.ScheduledPublishAt, &unpublishDate); err != nil {
+                        return err
+                }
+                
+                section.ScheduledUnpublishAt = &unpublishDate
+                section.UpdatedAt = time.Now()
+                
+                if err := s.sectionRepo.UpdateSection(section); err != nil {
+                        return fmt.Errorf("error scheduling section unpublishing: %w", err)
+                }
+                
+        default:
+                return fmt.Errorf("invalid content type: %s", contentType)
+        }
+        
+        return nil
+}
+
+// validatePublishingWindow checks that scheduled content is published before
+// it is unpublished
+func validatePublishingWindow(publishAt, unpublishAt *time.Time) error {
+        if publishAt != nil && unpublishAt != nil && !publishAt.Before(*unpublishAt) {
+                return fmt.Errorf("unpublish date %s must be after publish date %s",
+                        unpublishAt.Format(time.RFC3339), publishAt.Format(time.RFC3339))
+        }
+        return nil
+}
+
+// GetScheduledContent gets all content with a pending publish or unpublish schedule
 func (s *ContentAdminServiceImpl) GetScheduledContent() ([]interface{}, error) {
         scheduled := make([]interface{}, 0)
         
@@ -1001,6 +1166,7 @@ func (s *ContentAdminServiceImpl) GetScheduledContent() ([]interface{}, error) {
                         "id":          book.ID,
                         "title":       book.Title,
                         "publishDate": book.ScheduledPublishAt,
+                        "unpublishDate": book.ScheduledUnpublishAt,
                 })
         }
         
@@ -1016,6 +1182,7 @@ func (s *ContentAdminServiceImpl) GetScheduledContent() ([]interface{}, error) {
                         "title":       chapter.Title,
                         "bookID":      chapter.BookID,
                         "publishDate": chapter.ScheduledPublishAt,
+                        "unpublishDate": chapter.ScheduledUnpublishAt,
                 })
         }
         
@@ -1032,6 +1199,7 @@ func (s *ContentAdminServiceImpl) GetScheduledContent() ([]interface{}, error) {
                         "bookID":      section.BookID,
                         "chapterID":   section.ChapterID,
                         "publishDate": section.ScheduledPublishAt,
+                        "unpublishDate": section.ScheduledUnpublishAt,
                 })
         }
         
@@ -1039,7 +1207,8 @@ func (s *ContentAdminServiceImpl) GetScheduledContent() ([]interface{}, error) {
 }
 
 // PublishContent publishes content by setting its published flag to true
-func (s *ContentAdminServiceImpl) PublishContent(contentType string, contentID uint) error {
+func (s *ContentAdminServiceImpl) PublishContent(ctx context.Context, contentType string, contentID uint) error {
+        var wasPublished bool
         switch contentType {
         case "book":
                 // Get the book
@@ -1049,6 +1218,7 @@ func (s *ContentAdminServiceImpl) PublishContent(contentType string, contentID u
                 }
                 
                 // Update the book
+                wasPublished = book.Published
                 book.Published = true
                 book.ScheduledPublishAt = nil
                 book.UpdatedAt = time.Now()
@@ -1065,6 +1235,7 @@ func (s *ContentAdminServiceImpl) PublishContent(contentType string, contentID u
                 }
                 
                 // Update the chapter
+                wasPublished = chapter.Published
                 chapter.Published = true
                 chapter.ScheduledPublishAt = nil
                 chapter.UpdatedAt = time.Now()
@@ -1081,6 +1252,7 @@ func (s *ContentAdminServiceImpl) PublishContent(contentType string, contentID u
                 }
                 
                 // Update the section
+                wasPublished = section.Published
                 section.Published = true
                 section.ScheduledPublishAt = nil
                 section.UpdatedAt = time.Now()
@@ -1092,12 +1264,22 @@ func (s *ContentAdminServiceImpl) PublishContent(contentType string, contentID u
         default:
                 return fmt.Errorf("invalid content type: %s", contentType)
         }
+        s.syncSearchIndex(contentType, contentID)
+        s.recordAudit(ctx, audit.Event{
+                Action:     "content.published",
+                TargetType: contentType,
+                TargetID:   contentID,
+                Before:     map[string]interface{}{"published": wasPublished},
+                After:      map[string]interface{}{"published": true},
+        })
         
         return nil
 }
 
-// UnpublishContent unpublishes content by setting its published flag to false
-func (s *ContentAdminServiceImpl) UnpublishContent(contentType string, contentID uint) error {
+// UnpublishContent unpublishes content by setting its published flag to false.
+// Any pending publish or unpublish schedule is cancelled.
+func (s *ContentAdminServiceImpl) UnpublishContent(ctx context.Context, contentType string, contentID uint) error {
+        var wasPublished bool
         switch contentType {
         case "book":
                 // Get the book
@@ -1107,7 +1289,10 @@ func (s *ContentAdminServiceImpl) UnpublishContent(contentType string, contentID
                 }
                 
                 // Update the book
+                wasPublished = book.Published
                 book.Published = false
+                book.ScheduledPublishAt = nil
+                book.ScheduledUnpublishAt = nil
                 book.UpdatedAt = time.Now()
                 
                 if err := s.bookRepo.UpdateBook(book); err != nil {
@@ -1122,7 +1307,10 @@ func (s *ContentAdminServiceImpl) UnpublishContent(contentType string, contentID
                 }
                 
                 // Update the chapter
+                wasPublished = chapter.Published
                 chapter.Published = false
+                chapter.ScheduledPublishAt = nil
+                chapter.ScheduledUnpublishAt = nil
                 chapter.UpdatedAt = time.Now()
                 
                 if err := s.chapterRepo.UpdateChapter(chapter); err != nil {
@@ -1137,7 +1325,10 @@ func (s *ContentAdminServiceImpl) UnpublishContent(contentType string, contentID
                 }
                 
                 // Update the section
+                wasPublished = section.Published
                 section.Published = false
+                section.ScheduledPublishAt = nil
+                section.ScheduledUnpublishAt = nil
                 section.UpdatedAt = time.Now()
                 
                 if err := s.sectionRepo.UpdateSection(section); err != nil {
@@ -1147,6 +1338,14 @@ func (s *ContentAdminServiceImpl) UnpublishContent(contentType string, contentID
         default:
                 return fmt.Errorf("invalid content type: %s", contentType)
         }
+        s.syncSearchIndex(contentType, contentID)
+        s.recordAudit(ctx, audit.Event{
+                Action:     "content.unpublished",
+                TargetType: contentType,
+                TargetID:   contentID,
+                Before:     map[string]interface{}{"published": wasPublished},
+                After:      map[string]interface{}{"published": false},
+        })
         
         return nil
 }
\ No newline at end of file
@@ -7,6 +7,7 @@ import (
         "regexp"
         "strings"
 
+        commonmarkdown "github.com/yerenwgventures/GreatNigeriaLibrary-Foundation/backend/pkg/common/markdown"
         "github.com/yerenwgventures/GreatNigeriaLibrary-Foundation/backend/services/content/models"
         "github.com/yerenwgventures/GreatNigeriaLibrary-Foundation/backend/services/content/repository"
 )
@@ -68,53 +69,11 @@ func (r *ContentRendererImpl) RenderSection(section *models.BookSection, userID
         return content, nil
 }
 
-// RenderMarkdown converts markdown to HTML
+// RenderMarkdown converts CommonMark/GFM markdown to sanitized HTML.
+// Embed placeholders ({{interactive:N}}, {{image:...}}, etc.) are left intact
+// for the later rendering passes.
 func (r *ContentRendererImpl) RenderMarkdown(markdown string) (string, error) {
-        // This is a simplified implementation. For production, use a proper markdown library.
-        // In Go, you might use a library like github.com/gomarkdown/markdown
-        
-        // Convert headers
-        headerRegex := regexp.MustCompile(`(?m)^(#{1,6})\s+(.+)$`)
-        html := headerRegex.ReplaceAllStringFunc(markdown, func(match string) string {
-                parts := headerRegex.FindStringSubmatch(match)
-                level := len(parts[1])
-                text := parts[2]
-                return "<h" + string(rune('0'+level)) + ">" + text + "</h" + string(rune('0'+level)) + ">"
-        })
-        
-        // Convert bold
-        boldRegex := regexp.MustCompile(`\*\*([^*]+)\*\*`)
-        html = boldRegex.ReplaceAllString(html, "<strong>$1</strong>")
-        
-        // Convert italic
-        italicRegex := regexp.MustCompile(`\*([^*]+)\*`)
-        html = italicRegex.ReplaceAllString(html, "<em>$1</em>")
-        
-        // Convert paragraphs (simplified)
-        paragraphRegex := regexp.MustCompile(`(?m)^([^<].+)$`)
-        html = paragraphRegex.ReplaceAllString(html, "<p>$1</p>")
-        
-        // Convert lists (simplified)
-        listItemRegex := regexp.MustCompile(`(?m)^-\s+(.+)$`)
-        html = listItemRegex.ReplaceAllString(html, "<li>$1</li>")
-        
-        // Wrap list items in <ul>
-        listRegex := regexp.MustCompile(`(?s)(<li>.+</li>)`)
-        html = listRegex.ReplaceAllString(html, "<ul>$1</ul>")
-        
-        // Convert links
-        linkRegex := regexp.MustCompile(`\[([^\]]+)\]\(([^)]+)\)`)
-        html = linkRegex.ReplaceAllString(html, "<a href=\"$2\">$1</a>")
-        
-        // Convert images
-        imageRegex := regexp.MustCompile(`!\[([^\]]*)\]\(([^)]+)\)`)
-        html = imageRegex.ReplaceAllString(html, "<img src=\"$2\" alt=\"$1\">")
-        
-        // Fix double paragraph tags
-        html = strings.ReplaceAll(html, "<p><p>", "<p>")
-        html = strings.ReplaceAll(html, "</p></p>", "</p>")
-        
-        return html, nil
+        return commonmarkdown.Render(markdown)
 }
 
 // ProcessInteractiveElements processes interactive element placeholders in content
@@ -166,6 +125,9 @@ func (r *ContentRendererImpl) ProcessInteractiveElements(content string, section
         return content, nil
 }
 
+// youtubeIDRegex matches YouTube video IDs
+var youtubeIDRegex = regexp.MustCompile(`^[A-Za-z0-9_-]{11}$`)
+
 // EnhanceContentWithMedia processes media embed placeholders in content
 func (r *ContentRendererImpl) EnhanceContentWithMedia(content string) (string, error) {
         // Process image embeds
@@ -206,9 +168,10 @@ func (r *ContentRendererImpl) EnhanceContentWithMedia(content string) (string, e
                                 }
                         }
                         
-                        if videoID != "" {
+                        // The embed bypasses the sanitizer, so only well-formed IDs are used
+                        if youtubeIDRegex.MatchString(videoID) {
                                 return "<div class=\"video-embed youtube-embed\">" +
-                                        "<iframe width=\"560\" height=\"315\" src=\"https://www.youtube.com/embed/" + videoID + "\" " +
+                                        "<iframe width=\"560\" height=\"315\" src=\"https://www.youtube.com/embed/" + html.EscapeString(videoID) + "\" " +
                                         "frameborder=\"0\" allow=\"accelerometer; autoplay; clipboard-write; encrypted-media; " +
                                         "gyroscope; picture-in-picture\" allowfullscreen></iframe></div>"
                         }
@@ -2,10 +2,14 @@ package service
 
 import (
         "encoding/json"
+        "errors"
+        "log"
         "time"
 
         "github.com/yerenwgventures/GreatNigeriaLibrary-Foundation/backend/services/content/models"
         "github.com/yerenwgventures/GreatNigeriaLibrary-Foundation/backend/services/content/repository"
+        pointsmodels "github.com/yerenwgventures/GreatNigeriaLibrary-Foundation/backend/services/points/models"
+        pointsservice "github.com/yerenwgventures/GreatNigeriaLibrary-Foundation/backend/services/points/service"
 )
 
 // InteractiveElementService defines the interface for interactive element business logic
@@ -39,19 +43,19 @@ type QuizAnswer struct {
 type InteractiveElementServiceImpl struct {
         elementRepo repository.InteractiveElementRepository
         bookRepo    repository.BookRepository
-        pointsRepo  repository.PointsRepository
+        points      pointsservice.Awarder
 }
 
 // NewInteractiveElementService creates a new interactive element service instance
 func NewInteractiveElementService(
         elementRepo repository.InteractiveElementRepository,
         bookRepo repository.BookRepository,
-        pointsRepo repository.PointsRepository,
+        points pointsservice.Awarder,
 ) InteractiveElementService {
         return &InteractiveElementServiceImpl{
                 elementRepo: elementRepo,
                 bookRepo:    bookRepo,
-                pointsRepo:  pointsRepo,
+                points:      points,
         }
 }
 
@@ -337,12 +341,8 @@ func (s *InteractiveElementServiceImpl) SubmitQuizResponse(userID, elementID uin
         }
         
         // Award points if passed
-        if passed && s.pointsRepo != nil {
-                err = s.pointsRepo.AwardPoints(userID, "interactive_element", elementID, pointsAwarded, "Completed quiz: "+element.Title)
-                if err != nil {
-                        // Log the error but don't fail the response
-                        // TODO: Add logging
-                }
+        if passed {
+                s.awardPoints(userID, pointsmodels.ActionQuizPassed, elementID, pointsAwarded, "Completed quiz: "+element.Title)
         }
         
         return response, nil
@@ -406,13 +406,7 @@ func (s *InteractiveElementServiceImpl) SubmitReflectionResponse(userID, element
         }
         
         // Award points
-        if s.pointsRepo != nil {
-                err = s.pointsRepo.AwardPoints(userID, "interactive_element", elementID, element.PointsValue, "Completed reflection: "+element.Title)
-                if err != nil {
-                        // Log the error but don't fail the response
-                        // TODO: Add logging
-                }
-        }
+        s.awardPoints(userID, pointsmodels.ActionElementCompleted, elementID, element.PointsValue, "Completed reflection: "+element.Title)
         
         return responseObj, nil
 }
@@ -472,13 +466,7 @@ func (s *InteractiveElementServiceImpl) SubmitCallToActionResponse(userID, eleme
         }
         
         // Award points
-        if s.pointsRepo != nil {
-                err = s.pointsRepo.AwardPoints(userID, "interactive_element", elementID, element.PointsValue, "Completed call-to-action: "+element.Title)
-                if err != nil {
-                        // Log the error but don't fail the response
-                        // TODO: Add logging
-                }
-        }
+        s.awardPoints(userID, pointsmodels.ActionElementCompleted, elementID, element.PointsValue, "Completed call-to-action: "+element.Title)
         
         return responseObj, nil
 }
@@ -527,13 +515,7 @@ func (s *InteractiveElementServiceImpl) SubmitDiscussionResponse(userID, element
         }
         
         // Award points
-        if s.pointsRepo != nil {
-                err = s.pointsRepo.AwardPoints(userID, "interactive_element", elementID, element.PointsValue, "Participated in discussion: "+element.Title)
-                if err != nil {
-                        // Log the error but don't fail the response
-                        // TODO: Add logging
-                }
-        }
+        s.awardPoints(userID, pointsmodels.ActionElementCompleted, elementID, element.PointsValue, "Participated in discussion: "+element.Title)
         
         return responseObj, nil
 }
@@ -647,4 +629,24 @@ func compareArrayAnswers(userAnswer, correctAnswer interface{}) bool {
         }
         
         return true
-}
\ No newline at end of file
+}
+
+// awardPoints awards points for a completed element. Each element rewards a
+// user once; failures are logged and never fail the response.
+func (s *InteractiveElementServiceImpl) awardPoints(userID uint, action string, elementID uint, points int, description string) {
+        if s.points == nil || points <= 0 {
+                return
+        }
+
+        _, err := s.points.Award(pointsservice.AwardRequest{
+                UserID:        userID,
+                Action:        action,
+                ReferenceType: "interactive_element",
+                ReferenceID:   elementID,
+                Points:        points,
+                Description:   description,
+        })
+        if err != nil && !errors.Is(err, pointsmodels.ErrDailyCapReached) && !errors.Is(err, pointsmodels.ErrRuleDisabled) {
+                log.Printf("Error awarding points for interactive element %d: %v", elementID, err)
+        }
+}
@@ -0,0 +1,203 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	commonmarkdown "github.com/yerenwgventures/GreatNigeriaLibrary-Foundation/backend/pkg/common/markdown"
+	"github.com/yerenwgventures/GreatNigeriaLibrary-Foundation/backend/services/content/models"
+	"github.com/yerenwgventures/GreatNigeriaLibrary-Foundation/backend/services/content/tts"
+)
+
+// ErrMediaPending is returned when media is still being generated
+var ErrMediaPending = errors.New("media is still being generated")
+
+// ErrNoTTSEngine is returned when audio is requested without a TTS engine
+var ErrNoTTSEngine = errors.New("no text-to-speech engine configured")
+
+// AudioResult describes generated section audio
+type AudioResult struct {
+	URL       string  `json:"url"`
+	TimingURL string  `json:"timingUrl"` // WebVTT sentence and word timings
+	Duration  float64 `json:"duration"`  // Seconds
+	Hash      string  `json:"hash"`
+}
+
+// RequestAudio returns the job generating audio for the current text of a
+// section, queueing a new job when there is none or the last one failed. Only
+// published sections are spoken.
+func (g *MediaGeneratorImpl) RequestAudio(sectionID uint) (*models.MediaJob, error) {
+	if g.ttsEngine == nil {
+		return nil, ErrNoTTSEngine
+	}
+	section, _, err := g.publishedSection(sectionID)
+	if err != nil {
+		return nil, err
+	}
+	_, hash, err := g.sectionSpeech(section)
+	if err != nil {
+		return nil, err
+	}
+
+	job, err := g.jobRepo.GetLatestJob(models.MediaJobAudio, sectionID, hash)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get audio job: %w", err)
+	}
+	if job != nil {
+		switch job.Status {
+		case models.MediaJobQueued, models.MediaJobRunning:
+			return job, nil
+		case models.MediaJobCompleted:
+			if fileExists(g.audioPath(hash, ".wav")) {
+				return job, nil
+			}
+		}
+	}
+
+	job = &models.MediaJob{
+		Kind:        models.MediaJobAudio,
+		SectionID:   sectionID,
+		ContentHash: hash,
+		Status:      models.MediaJobQueued,
+	}
+	if err := g.jobRepo.CreateJob(job); err != nil {
+		return nil, fmt.Errorf("failed to queue audio job: %w", err)
+	}
+	return job, nil
+}
+
+// GetMediaJob retrieves a media job by its ID. Jobs of sections that are no
+// longer published return ErrContentNotPublished.
+func (g *MediaGeneratorImpl) GetMediaJob(jobID uint) (*models.MediaJob, error) {
+	job, err := g.jobRepo.GetJobByID(jobID)
+	if err != nil {
+		return nil, err
+	}
+	if _, _, err := g.publishedSection(job.SectionID); err != nil {
+		return nil, err
+	}
+	return job, nil
+}
+
+// GenerateAudioFromText speaks a section's title and text paragraph by
+// paragraph and stores the WAV audio with a WebVTT timing file. Audio is
+// cached by the hash of the text and the engine.
+func (g *MediaGeneratorImpl) GenerateAudioFromText(ctx context.Context, sectionID uint) (*AudioResult, error) {
+	if g.ttsEngine == nil {
+		return nil, ErrNoTTSEngine
+	}
+	section, err := g.bookRepo.GetSectionByID(sectionID)
+	if err != nil {
+		return nil, err
+	}
+	paragraphs, hash, err := g.sectionSpeech(section)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &AudioResult{
+		URL:       g.audioURL(hash, ".wav"),
+		TimingURL: g.audioURL(hash, ".vtt"),
+		Hash:      hash,
+	}
+
+	// Reuse audio generated from the same text
+	metaPath := g.audioPath(hash, ".json")
+	if data, err := os.ReadFile(metaPath); err == nil && fileExists(g.audioPath(hash, ".wav")) {
+		if err := json.Unmarshal(data, result); err == nil {
+			return result, nil
+		}
+	}
+
+	track, err := tts.Generate(ctx, g.ttsEngine, paragraphs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate audio for section %d: %w", sectionID, err)
+	}
+	result.Duration = track.Audio.Duration().Seconds()
+
+	if err := os.MkdirAll(filepath.Dir(metaPath), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create audio directory: %w", err)
+	}
+	var audio, timing bytes.Buffer
+	if err := tts.EncodeWAV(&audio, track.Audio); err != nil {
+		return nil, err
+	}
+	if err := tts.WriteVTT(&timing, track.Cues); err != nil {
+		return nil, err
+	}
+	meta, err := json.Marshal(result)
+	if err != nil {
+		return nil, err
+	}
+	if err := writeFileAtomic(g.audioPath(hash, ".wav"), audio.Bytes()); err != nil {
+		return nil, err
+	}
+	if err := writeFileAtomic(g.audioPath(hash, ".vtt"), timing.Bytes()); err != nil {
+		return nil, err
+	}
+	if err := writeFileAtomic(metaPath, meta); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// sectionSpeech returns the paragraphs spoken for a section and the hash
+// that identifies its audio
+func (g *MediaGeneratorImpl) sectionSpeech(section *models.BookSection) ([]string, string, error) {
+	paragraphs, err := commonmarkdown.Paragraphs(section.Content)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to read section %d text: %w", section.ID, err)
+	}
+	if title := strings.TrimSpace(section.Title); title != "" {
+		paragraphs = append([]string{title}, paragraphs...)
+	}
+	hash := generateContentHash(g.ttsEngine.Name() + "\n" + strings.Join(paragraphs, "\n"))
+	return paragraphs, hash, nil
+}
+
+// audioPath returns the path of a generated audio file
+func (g *MediaGeneratorImpl) audioPath(hash, ext string) string {
+	return filepath.Join(g.mediaBasePath, "audio", "generated", "audio_"+hash+ext)
+}
+
+// audioURL returns the URL of a generated audio file
+func (g *MediaGeneratorImpl) audioURL(hash, ext string) string {
+	return fmt.Sprintf("%s/static/media/audio/generated/audio_%s%s", g.baseURL, hash, ext)
+}
+
+// ProcessMediaJob generates the media of a claimed job and records the
+// result on the job
+func (g *MediaGeneratorImpl) ProcessMediaJob(ctx context.Context, job *models.MediaJob) error {
+	var err error
+	var result *AudioResult
+	switch job.Kind {
+	case models.MediaJobAudio:
+		result, err = g.GenerateAudioFromText(ctx, job.SectionID)
+	default:
+		err = fmt.Errorf("unknown media job kind: %s", job.Kind)
+	}
+
+	now := time.Now()
+	job.CompletedAt = &now
+	if err != nil {
+		job.Status = models.MediaJobFailed
+		job.Error = err.Error()
+	} else {
+		job.Status = models.MediaJobCompleted
+		job.Error = ""
+		job.URL = result.URL
+		job.TimingURL = result.TimingURL
+		job.Duration = result.Duration
+	}
+	if updateErr := g.jobRepo.UpdateJob(job); updateErr != nil {
+		return fmt.Errorf("failed to update media job %d: %w", job.ID, updateErr)
+	}
+	return err
+}
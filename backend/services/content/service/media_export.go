@@ -0,0 +1,454 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/yerenwgventures/GreatNigeriaLibrary-Foundation/backend/services/content/export"
+	"github.com/yerenwgventures/GreatNigeriaLibrary-Foundation/backend/services/content/models"
+	"github.com/yerenwgventures/GreatNigeriaLibrary-Foundation/backend/services/content/publishing"
+)
+
+// ErrContentNotPublished is returned when exporting unpublished content, or
+// content of an unpublished book or chapter, or speaking such a section
+var ErrContentNotPublished = errors.New("content is not published")
+
+// ErrInvalidExportContentType is returned for content types other than book,
+// chapter and section
+var ErrInvalidExportContentType = errors.New("invalid export content type")
+
+// bibliographyTitleRegex matches the "# Bibliography for ..." heading that
+// GenerateBibliography starts with
+var bibliographyTitleRegex = regexp.MustCompile(`^\s*#\s+Bibliography[^\n]*\n`)
+
+// ExportResult describes a generated EPUB or PDF edition
+type ExportResult struct {
+	URL         string    `json:"url"`
+	Format      string    `json:"format"`
+	ContentType string    `json:"contentType"`
+	ContentID   uint      `json:"contentId"`
+	Pages       int       `json:"pages,omitempty"` // PDF only
+	Size        int64     `json:"size"`
+	Hash        string    `json:"hash"`
+	GeneratedAt time.Time `json:"generatedAt"`
+}
+
+// ExportContent renders a book, chapter or section as an EPUB or PDF edition.
+// Only published content is exported. Editions are cached by the hash of
+// their content, so a published revision always produces a new file.
+func (g *MediaGeneratorImpl) ExportContent(contentType string, contentID uint, format string) (*ExportResult, error) {
+	if format != export.FormatEPUB && format != export.FormatPDF {
+		return nil, fmt.Errorf("%w: %s", export.ErrUnsupportedFormat, format)
+	}
+
+	publication, bookID, err := g.buildPublication(contentType, contentID)
+	if err != nil {
+		return nil, err
+	}
+	hash, err := publication.Hash()
+	if err != nil {
+		return nil, err
+	}
+
+	dir := g.exportDir(bookID)
+	prefix := fmt.Sprintf("%s_%d_", contentType, contentID)
+	fileName := fmt.Sprintf("%s%s.%s", prefix, hash[:16], format)
+	filePath := filepath.Join(dir, fileName)
+	metaPath := filePath + ".json"
+
+	// Reuse a cached edition of the same content
+	if data, err := os.ReadFile(metaPath); err == nil && fileExists(filePath) {
+		var cached ExportResult
+		if err := json.Unmarshal(data, &cached); err == nil && cached.Hash == hash {
+			return &cached, nil
+		}
+	}
+
+	var buf bytes.Buffer
+	pages, err := export.Write(&buf, publication, format)
+	if err != nil {
+		return nil, fmt.Errorf("failed to export %s %d as %s: %w", contentType, contentID, format, err)
+	}
+
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create export directory: %w", err)
+	}
+	// Remove editions of earlier revisions
+	if stale, err := filepath.Glob(filepath.Join(dir, prefix+"*."+format+"*")); err == nil {
+		for _, name := range stale {
+			os.Remove(name)
+		}
+	}
+
+	result := &ExportResult{
+		URL:         fmt.Sprintf("%s/static/media/exports/book_%d/%s", g.baseURL, bookID, fileName),
+		Format:      format,
+		ContentType: contentType,
+		ContentID:   contentID,
+		Pages:       pages,
+		Size:        int64(buf.Len()),
+		Hash:        hash,
+		GeneratedAt: time.Now(),
+	}
+	meta, err := json.Marshal(result)
+	if err != nil {
+		return nil, err
+	}
+	if err := writeFileAtomic(filePath, buf.Bytes()); err != nil {
+		return nil, err
+	}
+	if err := writeFileAtomic(metaPath, meta); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// InvalidateExports removes the cached editions of a book and of its chapters
+// and sections
+func (g *MediaGeneratorImpl) InvalidateExports(bookID uint) error {
+	if err := os.RemoveAll(g.exportDir(bookID)); err != nil {
+		return fmt.Errorf("failed to remove exports of book %d: %w", bookID, err)
+	}
+	return nil
+}
+
+// HandlePublishEvent drops the cached editions of a book when any of its
+// content is published or unpublished by the scheduler
+func (g *MediaGeneratorImpl) HandlePublishEvent(ctx context.Context, event publishing.Event) error {
+	if event.BookID == 0 {
+		return nil
+	}
+	return g.InvalidateExports(event.BookID)
+}
+
+// exportDir returns the directory holding the editions of a book
+func (g *MediaGeneratorImpl) exportDir(bookID uint) string {
+	return filepath.Join(g.mediaBasePath, "exports", fmt.Sprintf("book_%d", bookID))
+}
+
+// buildPublication collects and renders the content of an export. It also
+// returns the ID of the book the content belongs to.
+func (g *MediaGeneratorImpl) buildPublication(contentType string, contentID uint) (*export.Publication, uint, error) {
+	var (
+		book    *models.Book
+		parts   []export.Part
+		title   string
+		updated time.Time
+		err     error
+	)
+
+	switch contentType {
+	case "book":
+		book, err = g.bookRepo.GetBookByID(contentID)
+		if err != nil {
+			return nil, 0, err
+		}
+		if !book.Published {
+			return nil, 0, ErrContentNotPublished
+		}
+		parts, updated, err = g.bookParts(book)
+		if err != nil {
+			return nil, 0, err
+		}
+		title = book.Title
+
+	case "chapter":
+		chapter, err := g.bookRepo.GetChapterByID(contentID)
+		if err != nil {
+			return nil, 0, err
+		}
+		if !chapter.Published {
+			return nil, 0, ErrContentNotPublished
+		}
+		book, err = g.bookRepo.GetBookByID(chapter.BookID)
+		if err != nil {
+			return nil, 0, err
+		}
+		if !book.Published {
+			return nil, 0, ErrContentNotPublished
+		}
+		part, err := g.chapterPart(chapter, &updated)
+		if err != nil {
+			return nil, 0, err
+		}
+		parts = []export.Part{part}
+		title = part.Title
+
+	case "section":
+		var section *models.BookSection
+		section, book, err = g.publishedSection(contentID)
+		if err != nil {
+			return nil, 0, err
+		}
+		html, err := g.contentRenderer.RenderSection(section, 0)
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to render section %d: %w", section.ID, err)
+		}
+		parts = []export.Part{{Title: section.Title, HTML: html}}
+		title = section.Title
+		updated = section.UpdatedAt
+
+	default:
+		return nil, 0, fmt.Errorf("%w: %s", ErrInvalidExportContentType, contentType)
+	}
+
+	publication := &export.Publication{
+		Identifier:  fmt.Sprintf("urn:greatnigeria:%s:%d", contentType, contentID),
+		Title:       title,
+		Author:      book.Author,
+		Description: book.Description,
+		Language:    "en",
+		Modified:    latest(updated, book.UpdatedAt),
+		Cover:       g.coverImage(book.CoverImage),
+		Parts:       parts,
+	}
+	if contentType == "book" {
+		publication.Subtitle = book.Subtitle
+	} else {
+		publication.Subtitle = book.Title
+	}
+
+	if g.citationService != nil {
+		citations, err := g.citationService.GetCitationsByBook(book.ID)
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to get citations of book %d: %w", book.ID, err)
+		}
+		publication.Notes = make(map[int]string, len(citations))
+		for _, citation := range citations {
+			publication.Notes[citation.RefNumber] = citationNote(citation)
+		}
+	}
+
+	return publication, book.ID, nil
+}
+
+// publishedSection retrieves a section and its book, returning
+// ErrContentNotPublished unless the section, its chapter and its book are all
+// published
+func (g *MediaGeneratorImpl) publishedSection(sectionID uint) (*models.BookSection, *models.Book, error) {
+	section, err := g.bookRepo.GetSectionByID(sectionID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !section.Published {
+		return nil, nil, ErrContentNotPublished
+	}
+	chapter, err := g.bookRepo.GetChapterByID(section.ChapterID)
+	if err != nil {
+		return nil, nil, err
+	}
+	book, err := g.bookRepo.GetBookByID(section.BookID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !chapter.Published || !book.Published {
+		return nil, nil, ErrContentNotPublished
+	}
+	return section, book, nil
+}
+
+// bookParts renders the front matter, published chapters and back matter of
+// a book. It also returns the time the newest part was updated.
+func (g *MediaGeneratorImpl) bookParts(book *models.Book) ([]export.Part, time.Time, error) {
+	var parts []export.Part
+	updated := book.UpdatedAt
+
+	addMatter := func(title, markdown string) error {
+		if strings.TrimSpace(markdown) == "" {
+			return nil
+		}
+		html, err := g.contentRenderer.RenderMarkdown(markdown)
+		if err != nil {
+			return fmt.Errorf("failed to render %s: %w", strings.ToLower(title), err)
+		}
+		parts = append(parts, export.Part{Title: title, HTML: html})
+		return nil
+	}
+
+	frontMatter, err := g.bookRepo.GetFrontMatterByBookID(book.ID, false)
+	if err == nil && len(frontMatter) > 0 {
+		front := frontMatter[0]
+		updated = latest(updated, front.UpdatedAt)
+		for _, matter := range []struct{ title, content string }{
+			{"Preface", front.Preface},
+			{"Acknowledgements", front.Acknowledgements},
+			{"Introduction", front.Introduction},
+		} {
+			if err := addMatter(matter.title, matter.content); err != nil {
+				return nil, updated, err
+			}
+		}
+	}
+
+	chapters, err := g.bookRepo.GetChaptersByBookID(book.ID, false)
+	if err != nil {
+		return nil, updated, fmt.Errorf("failed to get chapters of book %d: %w", book.ID, err)
+	}
+	for i := range chapters {
+		part, err := g.chapterPart(&chapters[i], &updated)
+		if err != nil {
+			return nil, updated, err
+		}
+		parts = append(parts, part)
+	}
+
+	backMatter, err := g.bookRepo.GetBackMatterByBookID(book.ID, false)
+	if err == nil && backMatter != nil {
+		updated = latest(updated, backMatter.UpdatedAt)
+		for _, matter := range []struct{ title, content string }{
+			{"Conclusion", backMatter.Conclusion},
+			{"Appendices", backMatter.Appendices},
+			{"Glossary", backMatter.Glossary},
+		} {
+			if err := addMatter(matter.title, matter.content); err != nil {
+				return nil, updated, err
+			}
+		}
+	}
+
+	if g.citationService != nil {
+		if citations, err := g.citationService.GetCitationsByBook(book.ID); err == nil && len(citations) > 0 {
+			bibliography, err := g.citationService.GenerateBibliography(book.ID)
+			if err != nil {
+				return nil, updated, fmt.Errorf("failed to generate bibliography of book %d: %w", book.ID, err)
+			}
+			html, err := g.contentRenderer.RenderMarkdown(bibliographyTitleRegex.ReplaceAllString(bibliography, ""))
+			if err != nil {
+				return nil, updated, fmt.Errorf("failed to render bibliography: %w", err)
+			}
+			parts = append(parts, export.Part{Title: "Bibliography", HTML: html, References: true})
+		}
+	}
+
+	if backMatter != nil {
+		if err := addMatter("About the Author", backMatter.AboutAuthor); err != nil {
+			return nil, updated, err
+		}
+	}
+
+	if len(chapters) == 0 {
+		return nil, updated, fmt.Errorf("book %d has no published chapters", book.ID)
+	}
+	return parts, updated, nil
+}
+
+// chapterPart renders a chapter and its published sections, raising updated
+// to the newest update time
+func (g *MediaGeneratorImpl) chapterPart(chapter *models.BookChapter, updated *time.Time) (export.Part, error) {
+	part := export.Part{Title: fmt.Sprintf("Chapter %d: %s", chapter.Number, chapter.Title)}
+	*updated = latest(*updated, chapter.UpdatedAt)
+
+	if strings.TrimSpace(chapter.Content) != "" {
+		html, err := g.contentRenderer.RenderMarkdown(chapter.Content)
+		if err != nil {
+			return part, fmt.Errorf("failed to render chapter %d: %w", chapter.ID, err)
+		}
+		part.HTML = html
+	}
+
+	sections, err := g.bookRepo.GetSectionsByChapterID(chapter.ID, false)
+	if err != nil {
+		return part, fmt.Errorf("failed to get sections of chapter %d: %w", chapter.ID, err)
+	}
+	for i := range sections {
+		html, err := g.contentRenderer.RenderSection(&sections[i], 0)
+		if err != nil {
+			return part, fmt.Errorf("failed to render section %d: %w", sections[i].ID, err)
+		}
+		part.Sections = append(part.Sections, export.Section{
+			Title: fmt.Sprintf("%d.%d %s", chapter.Number, sections[i].Number, sections[i].Title),
+			HTML:  html,
+		})
+		*updated = latest(*updated, sections[i].UpdatedAt)
+	}
+	return part, nil
+}
+
+// coverImage loads a book cover stored under the static directory. Remote
+// covers are left out.
+func (g *MediaGeneratorImpl) coverImage(coverPath string) *export.Image {
+	if !strings.HasPrefix(coverPath, "/static/") {
+		return nil
+	}
+
+	mediaTypes := map[string]string{
+		".jpg":  "image/jpeg",
+		".jpeg": "image/jpeg",
+		".png":  "image/png",
+		".gif":  "image/gif",
+		".svg":  "image/svg+xml",
+	}
+	mediaType, ok := mediaTypes[strings.ToLower(path.Ext(coverPath))]
+	if !ok {
+		return nil
+	}
+
+	staticDir := filepath.Dir(filepath.Clean(g.mediaBasePath))
+	relPath := path.Clean(strings.TrimPrefix(coverPath, "/static/"))
+	if strings.HasPrefix(relPath, "..") {
+		return nil
+	}
+	data, err := os.ReadFile(filepath.Join(staticDir, filepath.FromSlash(relPath)))
+	if err != nil {
+		return nil
+	}
+	return &export.Image{MediaType: mediaType, Data: data}
+}
+
+// citationNote formats a citation as footnote text
+func citationNote(citation models.Citation) string {
+	var parts []string
+	author := strings.TrimSpace(citation.Author)
+	if citation.Year != "" {
+		author = fmt.Sprintf("%s (%s)", author, citation.Year)
+	}
+	for _, part := range []string{author, citation.Title, citation.Source} {
+		part = strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(part), "."))
+		if part != "" {
+			parts = append(parts, part+".")
+		}
+	}
+	if citation.URL != "" {
+		parts = append(parts, citation.URL)
+	}
+	return strings.Join(parts, " ")
+}
+
+// latest returns the later of two times
+func latest(a, b time.Time) time.Time {
+	if b.After(a) {
+		return b
+	}
+	return a
+}
+
+// writeFileAtomic writes a file through a temporary file so readers never
+// see a partial file
+func writeFileAtomic(filePath string, data []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(filePath), ".export-*")
+	if err != nil {
+		return fmt.Errorf("failed to create export file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write export file: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write export file: %w", err)
+	}
+	if err := os.Chmod(tmp.Name(), 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), filePath)
+}
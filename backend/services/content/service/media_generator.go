@@ -1,6 +1,7 @@
 package service
 
 import (
+	"context"
 	"crypto/md5"
 	"encoding/hex"
 	"fmt"
@@ -11,78 +12,67 @@ import (
 	"strings"
 	"time"
 
+	"github.com/yerenwgventures/GreatNigeriaLibrary-Foundation/backend/services/content/export"
 	"github.com/yerenwgventures/GreatNigeriaLibrary-Foundation/backend/services/content/models"
+	"github.com/yerenwgventures/GreatNigeriaLibrary-Foundation/backend/services/content/publishing"
 	"github.com/yerenwgventures/GreatNigeriaLibrary-Foundation/backend/services/content/repository"
+	"github.com/yerenwgventures/GreatNigeriaLibrary-Foundation/backend/services/content/tts"
 )
 
 // MediaGenerator defines the interface for generating media from content
 type MediaGenerator interface {
-	GenerateAudioFromText(sectionID uint) (string, int, error)
+	GenerateAudioFromText(ctx context.Context, sectionID uint) (*AudioResult, error)
+	RequestAudio(sectionID uint) (*models.MediaJob, error)
+	GetMediaJob(jobID uint) (*models.MediaJob, error)
 	GeneratePhotoCollection(sectionID uint) ([]string, error)
 	GenerateVideoSlideshow(sectionID uint) (string, int, error)
 	GeneratePDF(sectionID uint) (string, int, error)
 	GetShareableLink(sectionID uint, mediaType string) (string, string, error)
+	ExportContent(contentType string, contentID uint, format string) (*ExportResult, error)
+	InvalidateExports(bookID uint) error
+
+	// Cached exports of a book are dropped when its content is published
+	// or unpublished
+	publishing.Listener
+
+	// Queued media jobs are run by a MediaJobWorker
+	MediaJobProcessor
 }
 
 // MediaGeneratorImpl implements the MediaGenerator interface
 type MediaGeneratorImpl struct {
 	bookRepo        repository.BookRepository
+	citationService *CitationService
 	contentRenderer ContentRenderer
+	jobRepo         repository.MediaJobRepository
+	ttsEngine       tts.Engine
 	baseURL         string
 	mediaBasePath   string
 }
 
-// NewMediaGenerator creates a new media generator instance
+// NewMediaGenerator creates a new media generator instance. The citation
+// service supplies the footnotes and bibliography of exports and may be nil.
+// Audio is spoken by the TTS engine; without one, audio is unavailable.
 func NewMediaGenerator(
 	bookRepo repository.BookRepository,
+	citationService *CitationService,
 	contentRenderer ContentRenderer,
+	jobRepo repository.MediaJobRepository,
+	ttsEngine tts.Engine,
 	baseURL string,
 	mediaBasePath string,
 ) MediaGenerator {
 	return &MediaGeneratorImpl{
 		bookRepo:        bookRepo,
+		citationService: citationService,
 		contentRenderer: contentRenderer,
+		jobRepo:         jobRepo,
+		ttsEngine:       ttsEngine,
 		baseURL:         baseURL,
 		mediaBasePath:   mediaBasePath,
 	}
 }
 
-// GenerateAudioFromText generates an audio file from section text
-func (g *MediaGeneratorImpl) GenerateAudioFromText(sectionID uint) (string, int, error) {
-	// Get section content
-	section, err := g.bookRepo.GetSectionByID(sectionID)
-	if err != nil {
-		return "", 0, err
-	}
-
-	// Generate a unique identifier for this content
-	contentHash := generateContentHash(section.Content)
-	audioFileName := fmt.Sprintf("audio_%s.mp3", contentHash)
-	audioDir := filepath.Join(g.mediaBasePath, "audio", "generated")
-	audioFilePath := filepath.Join(audioDir, audioFileName)
-	audioURL := fmt.Sprintf("%s/static/media/audio/generated/%s", g.baseURL, audioFileName)
-
-	// Check if audio file already exists
-	if fileExists(audioFilePath) {
-		// Get audio duration (in seconds)
-		duration := 180 // Placeholder: In a real implementation, get actual duration
-		return audioURL, duration, nil
-	}
-
-	// Ensure directory exists
-	os.MkdirAll(audioDir, 0755)
-
-	// In a real implementation, this would call a text-to-speech service
-	// For this implementation, we'll create a placeholder audio file
-	err = createPlaceholderAudioFile(audioFilePath)
-	if err != nil {
-		return "", 0, err
-	}
-
-	// Return the URL and a placeholder duration
-	return audioURL, 180, nil
-}
-
 // GeneratePhotoCollection generates a collection of images related to the section content
 func (g *MediaGeneratorImpl) GeneratePhotoCollection(sectionID uint) ([]string, error) {
 	// Get section content
@@ -165,40 +155,13 @@ func (g *MediaGeneratorImpl) GenerateVideoSlideshow(sectionID uint) (string, int
 	return videoURL, 240, nil
 }
 
-// GeneratePDF generates a PDF from section content
+// GeneratePDF generates a PDF edition of a section
 func (g *MediaGeneratorImpl) GeneratePDF(sectionID uint) (string, int, error) {
-	// Get section content
-	section, err := g.bookRepo.GetSectionByID(sectionID)
+	result, err := g.ExportContent("section", sectionID, export.FormatPDF)
 	if err != nil {
 		return "", 0, err
 	}
-
-	// Generate a unique identifier for this content
-	contentHash := generateContentHash(section.Content)
-	pdfFileName := fmt.Sprintf("pdf_%s.pdf", contentHash)
-	pdfDir := filepath.Join(g.mediaBasePath, "pdf", "generated")
-	pdfFilePath := filepath.Join(pdfDir, pdfFileName)
-	pdfURL := fmt.Sprintf("%s/static/media/pdf/generated/%s", g.baseURL, pdfFileName)
-
-	// Check if PDF file already exists
-	if fileExists(pdfFilePath) {
-		// Get page count
-		pageCount := 5 // Placeholder: In a real implementation, get actual page count
-		return pdfURL, pageCount, nil
-	}
-
-	// Ensure directory exists
-	os.MkdirAll(pdfDir, 0755)
-
-	// In a real implementation, this would generate a PDF
-	// For this implementation, we'll create a placeholder PDF file
-	err = createPlaceholderPDFFile(pdfFilePath)
-	if err != nil {
-		return "", 0, err
-	}
-
-	// Return the URL and a placeholder page count
-	return pdfURL, 5, nil
+	return result.URL, result.Pages, nil
 }
 
 // GetShareableLink generates a shareable link for media content
@@ -226,11 +189,14 @@ func (g *MediaGeneratorImpl) GetShareableLink(sectionID uint, mediaType string)
 	
 	switch mediaType {
 	case "audio":
-		audioURL, _, err := g.GenerateAudioFromText(sectionID)
+		job, err := g.RequestAudio(sectionID)
 		if err != nil {
 			return "", "", err
 		}
-		mediaURL = audioURL
+		if job.Status != models.MediaJobCompleted {
+			return "", "", ErrMediaPending
+		}
+		mediaURL = job.URL
 		shareableLink = fmt.Sprintf("%s/share/audio/%d", g.baseURL, sectionID)
 	case "photo":
 		photoURLs, err := g.GeneratePhotoCollection(sectionID)
@@ -343,19 +309,6 @@ func getExistingPhotoURLs(photoDir string, baseURL string) ([]string, error) {
 // Placeholder file creation functions
 // In a real implementation, these would be replaced with actual media generation
 
-func createPlaceholderAudioFile(filePath string) error {
-	// Create an empty file
-	file, err := os.Create(filePath)
-	if err != nil {
-		return err
-	}
-	defer file.Close()
-	
-	// Write some placeholder content
-	_, err = file.WriteString("This is a placeholder audio file")
-	return err
-}
-
 func createPlaceholderImage(filePath string) error {
 	// Create an empty file
 	file, err := os.Create(filePath)
@@ -381,16 +334,3 @@ func createPlaceholderVideoFile(filePath string) error {
 	_, err = file.WriteString("This is a placeholder video file")
 	return err
 }
-
-func createPlaceholderPDFFile(filePath string) error {
-	// Create an empty file
-	file, err := os.Create(filePath)
-	if err != nil {
-		return err
-	}
-	defer file.Close()
-	
-	// Write some placeholder content
-	_, err = file.WriteString("%PDF-1.4\nThis is a placeholder PDF file\n%%EOF")
-	return err
-}
@@ -0,0 +1,101 @@
+package service
+
+import (
+        "context"
+        "log"
+        "time"
+
+        "github.com/yerenwgventures/GreatNigeriaLibrary-Foundation/backend/services/content/models"
+        "github.com/yerenwgventures/GreatNigeriaLibrary-Foundation/backend/services/content/repository"
+)
+
+// DefaultMediaJobInterval is how often an idle worker checks for new jobs
+const DefaultMediaJobInterval = 2 * time.Second
+
+// MediaJobTimeout bounds the time one job may take
+const MediaJobTimeout = 10 * time.Minute
+
+// maxMediaJobAttempts is how often a job is retried after its replica stopped
+// while running it
+const maxMediaJobAttempts = 3
+
+// MediaJobProcessor generates the media of a claimed job and records the
+// result on the job
+type MediaJobProcessor interface {
+        ProcessMediaJob(ctx context.Context, job *models.MediaJob) error
+}
+
+// MediaJobWorker runs queued media jobs one at a time. Every replica can run
+// a worker; each job is claimed by one of them.
+type MediaJobWorker struct {
+        processor MediaJobProcessor
+        jobRepo   repository.MediaJobRepository
+        kind      string
+        interval  time.Duration
+}
+
+// NewMediaJobWorker creates a worker for jobs of the given kind
+func NewMediaJobWorker(
+        processor MediaJobProcessor,
+        jobRepo repository.MediaJobRepository,
+        kind string,
+        interval time.Duration,
+) *MediaJobWorker {
+        if interval <= 0 {
+                interval = DefaultMediaJobInterval
+        }
+        return &MediaJobWorker{
+                processor: processor,
+                jobRepo:   jobRepo,
+                kind:      kind,
+                interval:  interval,
+        }
+}
+
+// Run processes jobs until ctx is cancelled, checking for new jobs every
+// interval while the queue is empty
+func (w *MediaJobWorker) Run(ctx context.Context) {
+        ticker := time.NewTicker(w.interval)
+        defer ticker.Stop()
+
+        for {
+                w.RunOnce(ctx)
+
+                select {
+                case <-ctx.Done():
+                        return
+                case <-ticker.C:
+                }
+        }
+}
+
+// RunOnce processes queued jobs until the queue is empty and returns how many
+// it processed. Jobs left running by a stopped replica are queued again first.
+func (w *MediaJobWorker) RunOnce(ctx context.Context) int {
+        requeued, err := w.jobRepo.RequeueStaleJobs(w.kind, time.Now().Add(-MediaJobTimeout-time.Minute), maxMediaJobAttempts)
+        if err != nil {
+                log.Printf("Error requeueing stale %s jobs: %v", w.kind, err)
+        } else if requeued > 0 {
+                log.Printf("Requeued %d stale %s jobs", requeued, w.kind)
+        }
+
+        processed := 0
+        for ctx.Err() == nil {
+                job, err := w.jobRepo.ClaimNextJob(w.kind, time.Now())
+                if err != nil {
+                        log.Printf("Error claiming %s job: %v", w.kind, err)
+                        break
+                }
+                if job == nil {
+                        break
+                }
+
+                jobCtx, cancel := context.WithTimeout(ctx, MediaJobTimeout)
+                if err := w.processor.ProcessMediaJob(jobCtx, job); err != nil {
+                        log.Printf("Error processing %s job %d for section %d: %v", job.Kind, job.ID, job.SectionID, err)
+                }
+                cancel()
+                processed++
+        }
+        return processed
+}
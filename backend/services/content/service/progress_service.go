@@ -1,18 +1,23 @@
 package service
 
 import (
+        stderrors "errors"
         "time"
 
         "github.com/yerenwgventures/GreatNigeriaLibrary-Foundation/backend/services/content/repository"
         "github.com/yerenwgventures/GreatNigeriaLibrary-Foundation/backend/pkg/common/errors"
         "github.com/yerenwgventures/GreatNigeriaLibrary-Foundation/backend/pkg/common/logger"
         "github.com/yerenwgventures/GreatNigeriaLibrary-Foundation/backend/pkg/models"
+        pointsmodels "github.com/yerenwgventures/GreatNigeriaLibrary-Foundation/backend/services/points/models"
+        pointsservice "github.com/yerenwgventures/GreatNigeriaLibrary-Foundation/backend/services/points/service"
 )
 
 // ProgressService handles progress-related business logic
 type ProgressService struct {
         progressRepo repository.ProgressRepository
         logger       *logger.Logger
+
+        pointsAwarder pointsservice.Awarder
 }
 
 // NewProgressService creates a new progress service
@@ -23,6 +28,11 @@ func NewProgressService(progressRepo repository.ProgressRepository, logger *logg
         }
 }
 
+// SetPointsAwarder sets the awarder used to grant points for completed sections
+func (s *ProgressService) SetPointsAwarder(awarder pointsservice.Awarder) {
+        s.pointsAwarder = awarder
+}
+
 // UpdateProgress updates a user's reading progress
 func (s *ProgressService) UpdateProgress(userID, bookID, chapterID, sectionID uint, lastPosition int, percentComplete float64) (*models.UserProgressResponse, error) {
         progress, err := s.progressRepo.UpdateProgress(userID, bookID, chapterID, sectionID, lastPosition, percentComplete)
@@ -73,18 +83,45 @@ func (s *ProgressService) CheckCompletion(userID, bookID uint) (bool, error) {
         return isCompleted, nil
 }
 
-// MarkTopicCompleted marks a topic as completed and records completion time
-func (s *ProgressService) MarkTopicCompleted(userID, bookID, chapterID, sectionID uint, points int) (*models.TopicCompletion, error) {
-        // For a real implementation, this would interact with the database
-        // For now, we'll just create and return a mock completion object
-        completion := &models.TopicCompletion{
+// MarkTopicCompleted records that a user finished a section and awards the
+// section_completed points. Completing a section again returns the original
+// record without awarding more points.
+func (s *ProgressService) MarkTopicCompleted(userID, bookID, chapterID, sectionID uint) (*models.TopicCompletion, error) {
+        completion, created, err := s.progressRepo.CreateTopicCompletion(&models.TopicCompletion{
                 UserID:      userID,
                 BookID:      bookID,
                 ChapterID:   chapterID,
                 SectionID:   sectionID,
-                Points:      points,
                 CompletedAt: time.Now(),
+        })
+        if err != nil {
+                s.logger.WithError(err).Error("Failed to record topic completion")
+                return nil, errors.ErrInternalServer("Failed to record topic completion")
+        }
+
+        if !created || s.pointsAwarder == nil {
+                return completion, nil
         }
-        
+
+        transaction, err := s.pointsAwarder.Award(pointsservice.AwardRequest{
+                UserID:        userID,
+                Action:        pointsmodels.ActionSectionCompleted,
+                ReferenceType: "section",
+                ReferenceID:   sectionID,
+        })
+        if err != nil {
+                // The completion stands even if no points are awarded, e.g.
+                // because the daily cap was reached
+                if !stderrors.Is(err, pointsmodels.ErrDailyCapReached) && !stderrors.Is(err, pointsmodels.ErrRuleDisabled) {
+                        s.logger.WithError(err).Error("Failed to award points for topic completion")
+                }
+                return completion, nil
+        }
+
+        completion.Points = transaction.Points
+        if err := s.progressRepo.UpdateTopicCompletionPoints(completion.ID, completion.Points); err != nil {
+                s.logger.WithError(err).Error("Failed to record points for topic completion")
+        }
+
         return completion, nil
 }
@@ -0,0 +1,432 @@
+package service
+
+import (
+        "fmt"
+        "strings"
+
+        commonmarkdown "github.com/yerenwgventures/GreatNigeriaLibrary-Foundation/backend/pkg/common/markdown"
+        "github.com/yerenwgventures/GreatNigeriaLibrary-Foundation/backend/services/content/models"
+        "github.com/yerenwgventures/GreatNigeriaLibrary-Foundation/backend/services/content/repository"
+        "github.com/yerenwgventures/GreatNigeriaLibrary-Foundation/backend/services/content/search"
+)
+
+// SearchIndexer keeps the full-text search index in sync with published content
+type SearchIndexer interface {
+        // IndexContent re-indexes a book, chapter or section and everything beneath it.
+        // Content that is not published (or whose parent is not) is removed instead.
+        IndexContent(contentType string, contentID uint) error
+        // IndexBook re-indexes a whole book including front/back matter and citations
+        IndexBook(bookID uint) error
+        // RemoveContent removes a book, chapter or section from the index
+        RemoveContent(contentType string, contentID uint) error
+        // ReindexAll rebuilds the index for every book
+        ReindexAll() error
+}
+
+// SearchIndexerImpl implements the SearchIndexer interface
+type SearchIndexerImpl struct {
+        index          search.SearchIndex
+        bookRepo       repository.BookRepository
+        citationRepo   *repository.CitationRepository
+        discussionRepo repository.DiscussionRepository
+}
+
+// NewSearchIndexer creates a new search indexer
+func NewSearchIndexer(
+        index search.SearchIndex,
+        bookRepo repository.BookRepository,
+        citationRepo *repository.CitationRepository,
+        discussionRepo repository.DiscussionRepository,
+) SearchIndexer {
+        return &SearchIndexerImpl{
+                index:          index,
+                bookRepo:       bookRepo,
+                citationRepo:   citationRepo,
+                discussionRepo: discussionRepo,
+        }
+}
+
+// IndexContent re-indexes a single content item
+func (s *SearchIndexerImpl) IndexContent(contentType string, contentID uint) error {
+        switch contentType {
+        case "book":
+                return s.IndexBook(contentID)
+        case "chapter":
+                return s.indexChapter(contentID)
+        case "section":
+                return s.indexSection(contentID)
+        default:
+                return fmt.Errorf("invalid content type: %s", contentType)
+        }
+}
+
+// IndexBook re-indexes a whole book
+func (s *SearchIndexerImpl) IndexBook(bookID uint) error {
+        book, err := s.bookRepo.GetBookByID(bookID)
+        if err != nil {
+                return fmt.Errorf("error getting book with ID %d: %w", bookID, err)
+        }
+
+        // Drop everything first so deleted chapters and sections disappear too
+        if err := s.index.RemoveBook(bookID); err != nil {
+                return fmt.Errorf("error clearing search index for book %d: %w", bookID, err)
+        }
+        if !book.Published {
+                return nil
+        }
+
+        tags, err := s.bookRepo.GetBookTags(bookID)
+        if err != nil {
+                return fmt.Errorf("error getting tags for book %d: %w", bookID, err)
+        }
+
+        bookLocation := search.Location{BookID: book.ID, BookTitle: book.Title}
+        docs := []search.Document{{
+                ContentType: search.ContentTypeBook,
+                ContentID:   book.ID,
+                Title:       book.Title,
+                Body:        joinText(book.Subtitle, book.Author, plainText(book.Description)),
+                Tags:        tags,
+                Location:    bookLocation,
+                UpdatedAt:   book.UpdatedAt,
+        }}
+        docs = append(docs, s.discussionDocuments(models.ContentTypeBook, book.ID, bookLocation, tags)...)
+
+        frontMatters, err := s.bookRepo.GetFrontMatterByBookID(bookID, false)
+        if err != nil {
+                return fmt.Errorf("error getting front matter for book %d: %w", bookID, err)
+        }
+        for _, frontMatter := range frontMatters {
+                docs = append(docs, search.Document{
+                        ContentType: search.ContentTypeFrontMatter,
+                        ContentID:   frontMatter.ID,
+                        Title:       book.Title + ": Front Matter",
+                        Body: joinText(
+                                plainText(frontMatter.Introduction),
+                                plainText(frontMatter.Preface),
+                                plainText(frontMatter.Acknowledgements),
+                                plainText(frontMatter.SupportAuthor),
+                        ),
+                        Tags:      tags,
+                        Location:  bookLocation,
+                        UpdatedAt: frontMatter.UpdatedAt,
+                })
+        }
+
+        // Books without back matter are common, so lookup errors are not fatal
+        if backMatter, err := s.bookRepo.GetBackMatterByBookID(bookID, false); err == nil && backMatter != nil {
+                parts := []string{
+                        plainText(backMatter.Conclusion),
+                        plainText(backMatter.Appendices),
+                        plainText(backMatter.Bibliography),
+                        plainText(backMatter.Glossary),
+                        plainText(backMatter.AboutAuthor),
+                }
+                for _, item := range backMatter.Epilogue {
+                        parts = append(parts, item.Title, plainText(item.Content), item.Quote)
+                }
+                for _, item := range backMatter.Appendix {
+                        parts = append(parts, item.Title, plainText(item.Content))
+                }
+                docs = append(docs, search.Document{
+                        ContentType: search.ContentTypeBackMatter,
+                        ContentID:   backMatter.ID,
+                        Title:       book.Title + ": Back Matter",
+                        Body:        joinText(parts...),
+                        Tags:        tags,
+                        Location:    bookLocation,
+                        UpdatedAt:   backMatter.UpdatedAt,
+                })
+        }
+
+        if s.citationRepo != nil {
+                citations, err := s.citationRepo.GetCitationsByBook(bookID)
+                if err != nil {
+                        return fmt.Errorf("error getting citations for book %d: %w", bookID, err)
+                }
+                for _, citation := range citations {
+                        docs = append(docs, search.Document{
+                                ContentType: search.ContentTypeCitation,
+                                ContentID:   citation.ID,
+                                Title:       citation.Title,
+                                Body:        joinText(citation.Author, citation.Year, citation.Source, citation.Type),
+                                Tags:        tags,
+                                Location:    bookLocation,
+                                UpdatedAt:   citation.UpdatedAt,
+                        })
+                }
+        }
+
+        chapters, err := s.bookRepo.GetChaptersByBookID(bookID, false)
+        if err != nil {
+                return fmt.Errorf("error getting chapters for book %d: %w", bookID, err)
+        }
+        for i := range chapters {
+                chapterDocs, err := s.chapterDocuments(book, &chapters[i], tags)
+                if err != nil {
+                        return err
+                }
+                docs = append(docs, chapterDocs...)
+        }
+
+        if err := s.index.Index(docs...); err != nil {
+                return fmt.Errorf("error indexing book %d: %w", bookID, err)
+        }
+        return nil
+}
+
+// indexChapter re-indexes a chapter and its sections
+func (s *SearchIndexerImpl) indexChapter(chapterID uint) error {
+        chapter, err := s.bookRepo.GetChapterByID(chapterID)
+        if err != nil {
+                return fmt.Errorf("error getting chapter with ID %d: %w", chapterID, err)
+        }
+        if err := s.removeChapter(chapterID); err != nil {
+                return err
+        }
+
+        book, err := s.bookRepo.GetBookByID(chapter.BookID)
+        if err != nil {
+                return fmt.Errorf("error getting book with ID %d: %w", chapter.BookID, err)
+        }
+        if !book.Published || !chapter.Published {
+                return nil
+        }
+
+        tags, err := s.bookRepo.GetBookTags(book.ID)
+        if err != nil {
+                return fmt.Errorf("error getting tags for book %d: %w", book.ID, err)
+        }
+
+        docs, err := s.chapterDocuments(book, chapter, tags)
+        if err != nil {
+                return err
+        }
+        if err := s.index.Index(docs...); err != nil {
+                return fmt.Errorf("error indexing chapter %d: %w", chapterID, err)
+        }
+        return nil
+}
+
+// indexSection re-indexes a single section
+func (s *SearchIndexerImpl) indexSection(sectionID uint) error {
+        section, err := s.bookRepo.GetSectionByID(sectionID)
+        if err != nil {
+                return fmt.Errorf("error getting section with ID %d: %w", sectionID, err)
+        }
+        if err := s.removeSection(sectionID); err != nil {
+                return err
+        }
+
+        chapter, err := s.bookRepo.GetChapterByID(section.ChapterID)
+        if err != nil {
+                return fmt.Errorf("error getting chapter with ID %d: %w", section.ChapterID, err)
+        }
+        book, err := s.bookRepo.GetBookByID(section.BookID)
+        if err != nil {
+                return fmt.Errorf("error getting book with ID %d: %w", section.BookID, err)
+        }
+        if !book.Published || !chapter.Published || !section.Published {
+                return nil
+        }
+
+        tags, err := s.bookRepo.GetBookTags(book.ID)
+        if err != nil {
+                return fmt.Errorf("error getting tags for book %d: %w", book.ID, err)
+        }
+
+        docs := s.sectionDocuments(book, chapter, section, tags)
+        if err := s.index.Index(docs...); err != nil {
+                return fmt.Errorf("error indexing section %d: %w", sectionID, err)
+        }
+        return nil
+}
+
+// RemoveContent removes a content item and everything beneath it from the index
+func (s *SearchIndexerImpl) RemoveContent(contentType string, contentID uint) error {
+        switch contentType {
+        case "book":
+                return s.index.RemoveBook(contentID)
+        case "chapter":
+                return s.removeChapter(contentID)
+        case "section":
+                return s.removeSection(contentID)
+        default:
+                return fmt.Errorf("invalid content type: %s", contentType)
+        }
+}
+
+// ReindexAll rebuilds the index for every book, continuing past failures
+func (s *SearchIndexerImpl) ReindexAll() error {
+        books, err := s.bookRepo.GetAllBooks(true)
+        if err != nil {
+                return fmt.Errorf("error getting books: %w", err)
+        }
+
+        var firstErr error
+        failed := 0
+        for _, book := range books {
+                if err := s.IndexBook(book.ID); err != nil {
+                        failed++
+                        if firstErr == nil {
+                                firstErr = err
+                        }
+                }
+        }
+        if firstErr != nil {
+                return fmt.Errorf("failed to index %d of %d books: %w", failed, len(books), firstErr)
+        }
+        return nil
+}
+
+// chapterDocuments builds the documents for a chapter and its published sections
+func (s *SearchIndexerImpl) chapterDocuments(book *models.Book, chapter *models.BookChapter, tags []string) ([]search.Document, error) {
+        location := search.Location{
+                BookID:        book.ID,
+                BookTitle:     book.Title,
+                ChapterID:     chapter.ID,
+                ChapterTitle:  chapter.Title,
+                ChapterNumber: chapter.Number,
+        }
+        docs := []search.Document{{
+                ContentType: search.ContentTypeChapter,
+                ContentID:   chapter.ID,
+                Title:       chapter.Title,
+                Body:        joinText(plainText(chapter.Description), plainText(chapter.Content)),
+                Tags:        tags,
+                Location:    location,
+                UpdatedAt:   chapter.UpdatedAt,
+        }}
+        docs = append(docs, s.discussionDocuments(models.ContentTypeChapter, chapter.ID, location, tags)...)
+
+        sections, err := s.bookRepo.GetSectionsByChapterID(chapter.ID, false)
+        if err != nil {
+                return nil, fmt.Errorf("error getting sections for chapter %d: %w", chapter.ID, err)
+        }
+        for i := range sections {
+                docs = append(docs, s.sectionDocuments(book, chapter, &sections[i], tags)...)
+        }
+        return docs, nil
+}
+
+// sectionDocuments builds the documents for a section and its discussion topics
+func (s *SearchIndexerImpl) sectionDocuments(book *models.Book, chapter *models.BookChapter, section *models.BookSection, tags []string) []search.Document {
+        location := search.Location{
+                BookID:        book.ID,
+                BookTitle:     book.Title,
+                ChapterID:     chapter.ID,
+                ChapterTitle:  chapter.Title,
+                ChapterNumber: chapter.Number,
+                SectionID:     section.ID,
+                SectionTitle:  section.Title,
+                SectionNumber: section.Number,
+        }
+        docs := []search.Document{{
+                ContentType: search.ContentTypeSection,
+                ContentID:   section.ID,
+                Title:       section.Title,
+                Body:        plainText(section.Content),
+                Tags:        tags,
+                Location:    location,
+                UpdatedAt:   section.UpdatedAt,
+        }}
+        return append(docs, s.discussionDocuments(models.ContentTypeSection, section.ID, location, tags)...)
+}
+
+// discussionDocuments builds documents for the discussion topics attached to a content item
+func (s *SearchIndexerImpl) discussionDocuments(contentType models.ContentType, contentID uint, location search.Location, tags []string) []search.Document {
+        if s.discussionRepo == nil {
+                return nil
+        }
+
+        // Discussions are secondary content, so a lookup failure only skips them
+        topics, err := s.discussionRepo.GetDiscussionTopicsByContentID(contentID, string(contentType))
+        if err != nil {
+                return nil
+        }
+
+        docs := make([]search.Document, 0, len(topics))
+        for _, topic := range topics {
+                docs = append(docs, search.Document{
+                        ContentType: search.ContentTypeDiscussion,
+                        ContentID:   topic.ID,
+                        Title:       topic.Title,
+                        Body:        plainText(topic.Description),
+                        Tags:        tags,
+                        Location:    location,
+                        UpdatedAt:   topic.UpdatedAt,
+                })
+        }
+        return docs
+}
+
+// removeChapter removes a chapter, its sections and their discussions from the index
+func (s *SearchIndexerImpl) removeChapter(chapterID uint) error {
+        sections, err := s.bookRepo.GetSectionsByChapterID(chapterID, true)
+        if err != nil {
+                return fmt.Errorf("error getting sections for chapter %d: %w", chapterID, err)
+        }
+        for _, section := range sections {
+                if err := s.removeSection(section.ID); err != nil {
+                        return err
+                }
+        }
+
+        if err := s.removeDiscussions(models.ContentTypeChapter, chapterID); err != nil {
+                return err
+        }
+        if err := s.index.Remove(search.ContentTypeChapter, chapterID); err != nil {
+                return fmt.Errorf("error removing chapter %d from search index: %w", chapterID, err)
+        }
+        return nil
+}
+
+// removeSection removes a section and its discussions from the index
+func (s *SearchIndexerImpl) removeSection(sectionID uint) error {
+        if err := s.removeDiscussions(models.ContentTypeSection, sectionID); err != nil {
+                return err
+        }
+        if err := s.index.Remove(search.ContentTypeSection, sectionID); err != nil {
+                return fmt.Errorf("error removing section %d from search index: %w", sectionID, err)
+        }
+        return nil
+}
+
+// removeDiscussions removes the discussion topics attached to a content item from the index
+func (s *SearchIndexerImpl) removeDiscussions(contentType models.ContentType, contentID uint) error {
+        if s.discussionRepo == nil {
+                return nil
+        }
+
+        topics, err := s.discussionRepo.GetDiscussionTopicsByContentID(contentID, string(contentType))
+        if err != nil {
+                return nil
+        }
+        for _, topic := range topics {
+                if err := s.index.Remove(search.ContentTypeDiscussion, topic.ID); err != nil {
+                        return fmt.Errorf("error removing discussion %d from search index: %w", topic.ID, err)
+                }
+        }
+        return nil
+}
+
+// plainText converts Markdown content to plain text for indexing. Content that
+// fails to render is indexed as-is rather than dropped.
+func plainText(source string) string {
+        text, err := commonmarkdown.PlainText(source)
+        if err != nil {
+                return source
+        }
+        return text
+}
+
+// joinText joins the non-empty parts of a document body
+func joinText(parts ...string) string {
+        nonEmpty := make([]string, 0, len(parts))
+        for _, part := range parts {
+                if part = strings.TrimSpace(part); part != "" {
+                        nonEmpty = append(nonEmpty, part)
+                }
+        }
+        return strings.Join(nonEmpty, "\n\n")
+}
@@ -1,13 +1,17 @@
 package service
 
 import (
+        "fmt"
+
         "github.com/yerenwgventures/GreatNigeriaLibrary-Foundation/backend/services/content/models"
         "github.com/yerenwgventures/GreatNigeriaLibrary-Foundation/backend/services/content/repository"
+        "github.com/yerenwgventures/GreatNigeriaLibrary-Foundation/backend/services/content/search"
 )
 
 // SearchService defines interface for search-related business logic
 type SearchService interface {
         SearchBooks(query string, userID uint) ([]models.Book, error)
+        Search(query search.Query) (*search.Results, error)
         GetRecommendations(userID, contentID uint) ([]models.Book, error)
         GenerateRecommendations(userID uint) error
 }
@@ -15,12 +19,14 @@ type SearchService interface {
 // SearchServiceImpl implements the SearchService interface
 type SearchServiceImpl struct {
         bookRepo repository.BookRepository
+        index    search.SearchIndex
 }
 
 // NewSearchService creates a new search service instance
-func NewSearchService(bookRepo repository.BookRepository) SearchService {
+func NewSearchService(bookRepo repository.BookRepository, index search.SearchIndex) SearchService {
         return &SearchServiceImpl{
                 bookRepo: bookRepo,
+                index:    index,
         }
 }
 
@@ -30,6 +36,14 @@ func (s *SearchServiceImpl) SearchBooks(query string, userID uint) ([]models.Boo
         return s.bookRepo.SearchBooks(query, []string{}, 10)
 }
 
+// Search runs a ranked full-text query across all published content
+func (s *SearchServiceImpl) Search(query search.Query) (*search.Results, error) {
+        if s.index == nil {
+                return nil, fmt.Errorf("search index not configured")
+        }
+        return s.index.Search(query)
+}
+
 // GetRecommendations retrieves content recommendations for a user
 func (s *SearchServiceImpl) GetRecommendations(userID, contentID uint) ([]models.Book, error) {
         return s.bookRepo.GetRecommendations(userID, 10) // Pass a default limit of 10
@@ -0,0 +1,154 @@
+package tts
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"math"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// OutputPlaceholder in the arguments of a CommandEngine is replaced by the
+// path of a temporary file that the command writes its WAV output to
+const OutputPlaceholder = "{output}"
+
+// CommandEngine runs a local speech synthesiser such as espeak-ng or piper
+// for every chunk of text. The text is written to the command's standard
+// input and the WAV audio is read from its standard output, or from the
+// output file when an argument is OutputPlaceholder.
+type CommandEngine struct {
+	name string
+	path string
+	args []string
+}
+
+// NewCommandEngine creates an engine that runs path with args
+func NewCommandEngine(name, path string, args ...string) *CommandEngine {
+	return &CommandEngine{name: name, path: path, args: args}
+}
+
+// NewEspeakEngine creates an engine using espeak-ng with the given voice,
+// e.g. "en" or "en-us", speaking wordsPerMinute words per minute
+func NewEspeakEngine(path, voice string, wordsPerMinute int) *CommandEngine {
+	if path == "" {
+		path = "espeak-ng"
+	}
+	if voice == "" {
+		voice = "en"
+	}
+	if wordsPerMinute <= 0 {
+		wordsPerMinute = 160
+	}
+	speed := strconv.Itoa(wordsPerMinute)
+	return NewCommandEngine("espeak-ng:"+voice+":"+speed, path, "-v", voice, "-s", speed, "--stdin", "--stdout")
+}
+
+// NewPiperEngine creates an engine using piper with the given voice model
+func NewPiperEngine(path, model string) *CommandEngine {
+	if path == "" {
+		path = "piper"
+	}
+	return NewCommandEngine("piper:"+model, path, "--model", model, "--output_file", OutputPlaceholder)
+}
+
+// Name returns the engine name
+func (e *CommandEngine) Name() string {
+	return e.name
+}
+
+// Check reports whether the command can be found
+func (e *CommandEngine) Check() error {
+	if _, err := exec.LookPath(e.path); err != nil {
+		return fmt.Errorf("speech synthesiser %s not found: %w", e.path, err)
+	}
+	return nil
+}
+
+// Synthesize runs the command for one chunk of text
+func (e *CommandEngine) Synthesize(ctx context.Context, text string) (*Audio, error) {
+	args := make([]string, len(e.args))
+	copy(args, e.args)
+
+	outputFile := ""
+	for i, arg := range args {
+		if arg != OutputPlaceholder {
+			continue
+		}
+		if outputFile == "" {
+			f, err := os.CreateTemp("", "tts-*.wav")
+			if err != nil {
+				return nil, fmt.Errorf("failed to create speech output file: %w", err)
+			}
+			f.Close()
+			outputFile = f.Name()
+			defer os.Remove(outputFile)
+		}
+		args[i] = outputFile
+	}
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, e.path, args...)
+	cmd.Stdin = strings.NewReader(text)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("failed to run %s: %w: %s", e.path, err, strings.TrimSpace(stderr.String()))
+	}
+
+	data := stdout.Bytes()
+	if outputFile != "" {
+		var err error
+		if data, err = os.ReadFile(outputFile); err != nil {
+			return nil, fmt.Errorf("failed to read speech output: %w", err)
+		}
+	}
+	audio, err := DecodeWAV(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode %s output: %w", e.path, err)
+	}
+	return audio, nil
+}
+
+// ToneEngine is a stand-in engine for development and tests. It renders
+// each word as a short tone, so the audio has a realistic length and
+// rhythm without a speech synthesiser installed.
+type ToneEngine struct {
+	SampleRate int
+	WordLength time.Duration // Tone length of a five-letter word
+}
+
+// NewToneEngine creates a tone engine speaking about 160 words per minute
+func NewToneEngine() *ToneEngine {
+	return &ToneEngine{SampleRate: 16000, WordLength: 300 * time.Millisecond}
+}
+
+// Name returns the engine name
+func (e *ToneEngine) Name() string {
+	return fmt.Sprintf("tone:%d:%s", e.SampleRate, e.WordLength)
+}
+
+// Synthesize renders one tone per word with a short gap after each
+func (e *ToneEngine) Synthesize(ctx context.Context, text string) (*Audio, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	audio := &Audio{SampleRate: e.SampleRate, Channels: 1}
+	for i, word := range strings.Fields(text) {
+		length := e.WordLength * time.Duration(len([]rune(word))+2) / 7
+		frames := int(length * time.Duration(e.SampleRate) / time.Second)
+		frequency := 180.0 + float64(i%5)*20
+		for n := 0; n < frames; n++ {
+			// Fade in and out to avoid clicks
+			envelope := math.Min(1, math.Min(float64(n), float64(frames-n))/float64(e.SampleRate/200+1))
+			value := math.Sin(2*math.Pi*frequency*float64(n)/float64(e.SampleRate)) * envelope * 8000
+			audio.Samples = append(audio.Samples, int16(value))
+		}
+		audio.AppendSilence(e.WordLength / 4)
+	}
+	return audio, nil
+}
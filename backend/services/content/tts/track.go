@@ -0,0 +1,164 @@
+package tts
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// ParagraphPause is the silence between paragraphs
+const ParagraphPause = 600 * time.Millisecond
+
+// ErrNoText is returned when there is nothing to speak
+var ErrNoText = errors.New("no text to speak")
+
+// Track is generated speech with the timing of every sentence and word
+type Track struct {
+	Audio *Audio
+	Cues  []Cue
+}
+
+// Cue is a sentence and when it is spoken
+type Cue struct {
+	ID        string // p<paragraph>-s<sentence>, both counted from 1
+	Paragraph int    // Index of the paragraph, from 0
+	Start     time.Duration
+	End       time.Duration
+	Text      string
+	Words     []Word
+}
+
+// Word is a word and when it is spoken
+type Word struct {
+	Text  string
+	Start time.Duration
+	End   time.Duration
+}
+
+// Generate speaks each paragraph with the engine and joins the chunks, with a
+// pause between paragraphs. Paragraph timings are measured from the audio;
+// sentence and word timings within a paragraph are estimated from the
+// length of the words.
+func Generate(ctx context.Context, engine Engine, paragraphs []string) (*Track, error) {
+	track := &Track{Audio: &Audio{}}
+	spoken := 0
+	for i, paragraph := range paragraphs {
+		words := strings.Fields(paragraph)
+		if len(words) == 0 {
+			continue
+		}
+
+		chunk, err := engine.Synthesize(ctx, strings.Join(words, " "))
+		if err != nil {
+			return nil, fmt.Errorf("failed to synthesise paragraph %d: %w", i+1, err)
+		}
+		if spoken > 0 {
+			track.Audio.AppendSilence(ParagraphPause)
+		}
+		start := track.Audio.Duration()
+		if err := track.Audio.Append(chunk); err != nil {
+			return nil, err
+		}
+		spoken++
+
+		track.Cues = append(track.Cues, paragraphCues(i, words, start, track.Audio.Duration())...)
+	}
+
+	if spoken == 0 {
+		return nil, ErrNoText
+	}
+	return track, nil
+}
+
+// paragraphCues spreads the time a paragraph is spoken over its words by
+// length and groups the words into sentences
+func paragraphCues(paragraph int, words []string, start, end time.Duration) []Cue {
+	total := 0
+	for _, word := range words {
+		total += wordWeight(word)
+	}
+
+	var cues []Cue
+	var current *Cue
+	elapsed := 0
+	for _, word := range words {
+		wordStart := start + (end-start)*time.Duration(elapsed)/time.Duration(total)
+		elapsed += wordWeight(word)
+		wordEnd := start + (end-start)*time.Duration(elapsed)/time.Duration(total)
+
+		if current == nil {
+			cues = append(cues, Cue{
+				ID:        fmt.Sprintf("p%d-s%d", paragraph+1, len(cues)+1),
+				Paragraph: paragraph,
+				Start:     wordStart,
+			})
+			current = &cues[len(cues)-1]
+		}
+		current.Words = append(current.Words, Word{Text: word, Start: wordStart, End: wordEnd})
+		current.End = wordEnd
+
+		if endsSentence(word) {
+			current = nil
+		}
+	}
+
+	for i := range cues {
+		texts := make([]string, len(cues[i].Words))
+		for j, word := range cues[i].Words {
+			texts[j] = word.Text
+		}
+		cues[i].Text = strings.Join(texts, " ")
+	}
+	return cues
+}
+
+// wordWeight approximates how long a word takes to say relative to others,
+// counting the pause after it
+func wordWeight(word string) int {
+	weight := len([]rune(word)) + 2
+	if strings.ContainsAny(word[len(word)-1:], ",;:") {
+		weight += 2
+	}
+	if endsSentence(word) {
+		weight += 4
+	}
+	return weight
+}
+
+// endsSentence reports whether a word ends a sentence
+func endsSentence(word string) bool {
+	word = strings.TrimRight(word, `"')]”’`)
+	return strings.HasSuffix(word, ".") || strings.HasSuffix(word, "!") || strings.HasSuffix(word, "?")
+}
+
+// vttEscaper escapes cue text
+var vttEscaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;")
+
+// WriteVTT writes the cues as WebVTT with one cue per sentence. Each word
+// after the first is preceded by a timestamp tag, so players and the book
+// viewer can highlight the word being spoken.
+func WriteVTT(w io.Writer, cues []Cue) error {
+	var sb strings.Builder
+	sb.WriteString("WEBVTT\n")
+	for _, cue := range cues {
+		fmt.Fprintf(&sb, "\n%s\n%s --> %s\n", cue.ID, vttTimestamp(cue.Start), vttTimestamp(cue.End))
+		for i, word := range cue.Words {
+			if i > 0 {
+				fmt.Fprintf(&sb, " <%s>", vttTimestamp(word.Start))
+			}
+			sb.WriteString(vttEscaper.Replace(word.Text))
+		}
+		sb.WriteString("\n")
+	}
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
+
+// vttTimestamp formats a time as hh:mm:ss.ttt
+func vttTimestamp(d time.Duration) string {
+	ms := d.Milliseconds()
+	return fmt.Sprintf("%02d:%02d:%02d.%03d", ms/3600000, ms/60000%60, ms/1000%60, ms%1000)
+}
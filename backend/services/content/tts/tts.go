@@ -0,0 +1,58 @@
+// Package tts turns text into speech audio with a pluggable text-to-speech
+// engine. Text is synthesised one paragraph at a time and the chunks are
+// joined into a single track with WebVTT timings for read-along highlighting.
+package tts
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ErrFormatMismatch is returned when an engine produces chunks with
+// different sample rates or channel counts
+var ErrFormatMismatch = errors.New("audio chunks have different formats")
+
+// Engine synthesises speech from text
+type Engine interface {
+	// Name identifies the engine and voice. It is part of cache keys, so it
+	// must change whenever the output would.
+	Name() string
+	Synthesize(ctx context.Context, text string) (*Audio, error)
+}
+
+// Audio is 16-bit PCM audio. Samples of multi-channel audio are interleaved.
+type Audio struct {
+	SampleRate int
+	Channels   int
+	Samples    []int16
+}
+
+// Duration returns the playing time of the audio
+func (a *Audio) Duration() time.Duration {
+	if a.SampleRate == 0 || a.Channels == 0 {
+		return 0
+	}
+	frames := len(a.Samples) / a.Channels
+	return time.Duration(frames) * time.Second / time.Duration(a.SampleRate)
+}
+
+// Append adds another chunk of audio with the same format to the end
+func (a *Audio) Append(chunk *Audio) error {
+	if a.SampleRate == 0 {
+		a.SampleRate, a.Channels = chunk.SampleRate, chunk.Channels
+	}
+	if chunk.SampleRate != a.SampleRate || chunk.Channels != a.Channels {
+		return fmt.Errorf("%w: %d Hz x %d, then %d Hz x %d", ErrFormatMismatch,
+			a.SampleRate, a.Channels, chunk.SampleRate, chunk.Channels)
+	}
+	a.Samples = append(a.Samples, chunk.Samples...)
+	return nil
+}
+
+// AppendSilence adds silence to the end
+func (a *Audio) AppendSilence(d time.Duration) {
+	frames := int(d * time.Duration(a.SampleRate) / time.Second)
+	a.Samples = append(a.Samples, make([]int16, frames*a.Channels)...)
+}
@@ -0,0 +1,78 @@
+package tts
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenerate(t *testing.T) {
+	engine := NewToneEngine()
+	paragraphs := []string{
+		"Historical Roots",
+		"As Achebe argued, the trouble is leadership. It is not the land!",
+	}
+
+	track, err := Generate(context.Background(), engine, paragraphs)
+	require.NoError(t, err)
+
+	first, err := engine.Synthesize(context.Background(), paragraphs[0])
+	require.NoError(t, err)
+	second, err := engine.Synthesize(context.Background(), paragraphs[1])
+	require.NoError(t, err)
+	want := first.Duration() + ParagraphPause + second.Duration()
+	assert.InDelta(t, float64(want), float64(track.Audio.Duration()), float64(time.Millisecond))
+
+	require.Len(t, track.Cues, 3)
+	assert.Equal(t, "p1-s1", track.Cues[0].ID)
+	assert.Equal(t, "p2-s2", track.Cues[2].ID)
+	assert.Equal(t, "It is not the land!", track.Cues[2].Text)
+	assert.Equal(t, first.Duration()+ParagraphPause, track.Cues[1].Start)
+	assert.Equal(t, track.Audio.Duration(), track.Cues[2].End)
+	for i := 1; i < len(track.Cues); i++ {
+		assert.GreaterOrEqual(t, track.Cues[i].Start, track.Cues[i-1].End)
+	}
+
+	_, err = Generate(context.Background(), engine, []string{" ", ""})
+	assert.ErrorIs(t, err, ErrNoText)
+}
+
+func TestWAVRoundTrip(t *testing.T) {
+	audio := &Audio{SampleRate: 22050, Channels: 1, Samples: []int16{0, 1000, -1000, 32767, -32768}}
+
+	var buf bytes.Buffer
+	require.NoError(t, EncodeWAV(&buf, audio))
+	decoded, err := DecodeWAV(buf.Bytes())
+	require.NoError(t, err)
+	assert.Equal(t, audio, decoded)
+
+	// Streamed output, as written by espeak-ng, leaves the sizes unset
+	streamed := buf.Bytes()
+	copy(streamed[40:44], []byte{0xff, 0xff, 0xff, 0xff})
+	decoded, err = DecodeWAV(streamed)
+	require.NoError(t, err)
+	assert.Equal(t, audio.Samples, decoded.Samples)
+
+	_, err = DecodeWAV([]byte("not audio"))
+	assert.ErrorIs(t, err, ErrInvalidWAV)
+}
+
+func TestWriteVTT(t *testing.T) {
+	cues := paragraphCues(0, strings.Fields("Fish & chips. Done"), 0, 2*time.Second)
+
+	var buf bytes.Buffer
+	require.NoError(t, WriteVTT(&buf, cues))
+	vtt := buf.String()
+
+	assert.True(t, strings.HasPrefix(vtt, "WEBVTT\n"))
+	assert.Contains(t, vtt, "\np1-s1\n00:00:00.000 --> ")
+	assert.Contains(t, vtt, "Fish <00:00:00.")
+	assert.Contains(t, vtt, "&amp;")
+	assert.Contains(t, vtt, "\np1-s2\n")
+	assert.Contains(t, vtt, " --> 00:00:02.000\nDone\n")
+}
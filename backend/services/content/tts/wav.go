@@ -0,0 +1,90 @@
+package tts
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// ErrInvalidWAV is returned for data that is not 16-bit PCM WAV audio
+var ErrInvalidWAV = errors.New("invalid WAV audio")
+
+// DecodeWAV reads 16-bit PCM WAV audio. Streamed WAV files, whose headers
+// leave the sizes unset, are read to the end of the data.
+func DecodeWAV(data []byte) (*Audio, error) {
+	if len(data) < 12 || string(data[0:4]) != "RIFF" || string(data[8:12]) != "WAVE" {
+		return nil, fmt.Errorf("%w: missing RIFF header", ErrInvalidWAV)
+	}
+
+	audio := &Audio{}
+	bitsPerSample := 0
+	for offset := 12; offset+8 <= len(data); {
+		id := string(data[offset : offset+4])
+		size := int(binary.LittleEndian.Uint32(data[offset+4 : offset+8]))
+		body := data[offset+8:]
+		if size < 0 || size > len(body) {
+			size = len(body)
+		}
+		body = body[:size]
+
+		switch id {
+		case "fmt ":
+			if len(body) < 16 {
+				return nil, fmt.Errorf("%w: short format chunk", ErrInvalidWAV)
+			}
+			if format := binary.LittleEndian.Uint16(body[0:2]); format != 1 && format != 0xFFFE {
+				return nil, fmt.Errorf("%w: format %d is not PCM", ErrInvalidWAV, format)
+			}
+			audio.Channels = int(binary.LittleEndian.Uint16(body[2:4]))
+			audio.SampleRate = int(binary.LittleEndian.Uint32(body[4:8]))
+			bitsPerSample = int(binary.LittleEndian.Uint16(body[14:16]))
+		case "data":
+			if bitsPerSample != 16 {
+				return nil, fmt.Errorf("%w: %d-bit samples, want 16-bit", ErrInvalidWAV, bitsPerSample)
+			}
+			audio.Samples = make([]int16, size/2)
+			for i := range audio.Samples {
+				audio.Samples[i] = int16(binary.LittleEndian.Uint16(body[2*i:]))
+			}
+			if audio.Channels == 0 || audio.SampleRate == 0 {
+				return nil, fmt.Errorf("%w: no sample rate", ErrInvalidWAV)
+			}
+			return audio, nil
+		}
+
+		// Chunks are padded to an even size
+		offset += 8 + size + size%2
+	}
+	return nil, fmt.Errorf("%w: no data chunk", ErrInvalidWAV)
+}
+
+// EncodeWAV writes audio as a 16-bit PCM WAV file
+func EncodeWAV(w io.Writer, audio *Audio) error {
+	dataSize := uint32(len(audio.Samples) * 2)
+	blockAlign := uint16(audio.Channels * 2)
+
+	header := make([]byte, 44)
+	copy(header[0:4], "RIFF")
+	binary.LittleEndian.PutUint32(header[4:8], 36+dataSize)
+	copy(header[8:16], "WAVEfmt ")
+	binary.LittleEndian.PutUint32(header[16:20], 16)
+	binary.LittleEndian.PutUint16(header[20:22], 1)
+	binary.LittleEndian.PutUint16(header[22:24], uint16(audio.Channels))
+	binary.LittleEndian.PutUint32(header[24:28], uint32(audio.SampleRate))
+	binary.LittleEndian.PutUint32(header[28:32], uint32(audio.SampleRate)*uint32(blockAlign))
+	binary.LittleEndian.PutUint16(header[32:34], blockAlign)
+	binary.LittleEndian.PutUint16(header[34:36], 16)
+	copy(header[36:40], "data")
+	binary.LittleEndian.PutUint32(header[40:44], dataSize)
+	if _, err := w.Write(header); err != nil {
+		return err
+	}
+
+	samples := make([]byte, dataSize)
+	for i, sample := range audio.Samples {
+		binary.LittleEndian.PutUint16(samples[2*i:], uint16(sample))
+	}
+	_, err := w.Write(samples)
+	return err
+}
@@ -0,0 +1,150 @@
+// Package appeals decides which moderation decisions a user may appeal, which
+// moderator reviews each appeal, and how far a review may reduce a penalty or
+// ban.
+//
+// Users appeal their own penalties, moderation actions and posts a moderator
+// hid or rejected, once per decision and within the appeal window. The
+// moderator who made a decision never reviews its appeal: appeals go to the
+// least busy other moderator whose privileges cover the decision.
+package appeals
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/yerenwgventures/GreatNigeriaLibrary-Foundation/backend/pkg/common/errors"
+	"github.com/yerenwgventures/GreatNigeriaLibrary-Foundation/backend/services/discussion/models"
+)
+
+// DefaultWindow is how long after a decision users may appeal it
+const DefaultWindow = 14 * 24 * time.Hour
+
+// DefaultSLA is how long moderators have to review an appeal
+const DefaultSLA = 72 * time.Hour
+
+// warning is the kind of penalties and moderation actions that only warn
+const warning = "warning"
+
+// Decision is a moderation decision as an appeal sees it, whichever kind it is
+type Decision struct {
+	Type        models.AppealDecisionType
+	ID          uint
+	UserID      uint       // User the decision affects
+	ModeratorID uint       // Moderator who made it, 0 if no moderator has yet
+	Kind        string     // Penalty type, action type or content moderation status
+	DecidedAt   time.Time  // When the decision was made
+	ExpiresAt   *time.Time // When a penalty or ban runs out, nil if it does not
+	Active      bool       // False once the decision was removed or revoked
+	ContentType string     // Topic or comment a content decision is about
+	ContentID   uint
+}
+
+// CanAppeal checks that a user may appeal a decision now. Only the user a
+// decision affects may appeal it, only while it still applies, and only
+// within the window. Posts hidden by flags cannot be appealed here until a
+// moderator confirms the flags.
+func CanAppeal(d Decision, userID uint, window time.Duration, now time.Time) error {
+	if d.UserID != userID {
+		return models.ErrPermissionDenied
+	}
+	if !d.Active || (d.ExpiresAt != nil && !now.Before(*d.ExpiresAt)) {
+		return models.ErrNotAppealable
+	}
+	if d.Type == models.AppealContent && d.ModeratorID == 0 {
+		return models.ErrNotAppealable
+	}
+	if now.After(d.DecidedAt.Add(window)) {
+		return models.ErrAppealWindowClosed
+	}
+	return nil
+}
+
+// MayReview reports whether a moderator may review an appeal of a decision.
+// They must be an active moderator other than the one who decided and the
+// appellant. Global moderators review any appeal; otherwise penalties and
+// moderation actions need the privilege to ban users, and posts the privilege
+// to approve content.
+func MayReview(p models.ModeratorPrivilege, d Decision, appellantID uint, now time.Time) bool {
+	if !p.IsActive || (p.ExpiresAt != nil && !now.Before(*p.ExpiresAt)) {
+		return false
+	}
+	if p.UserID == d.ModeratorID || p.UserID == appellantID {
+		return false
+	}
+	if p.IsGlobalModerator {
+		return true
+	}
+	if d.Type == models.AppealContent {
+		return p.CanApproveContent
+	}
+	return p.CanBanUsers
+}
+
+// PickReviewer chooses who reviews an appeal: of the moderators who may, the
+// one with the fewest pending appeals, then the lowest user ID. It reports
+// false when no moderator may review the appeal.
+func PickReviewer(moderators []models.ModeratorPrivilege, d Decision, appellantID uint, pending map[uint]int64, now time.Time) (uint, bool) {
+	var candidates []uint
+	for _, p := range moderators {
+		if MayReview(p, d, appellantID, now) {
+			candidates = append(candidates, p.UserID)
+		}
+	}
+	if len(candidates) == 0 {
+		return 0, false
+	}
+
+	sort.Slice(candidates, func(i, j int) bool {
+		a, b := candidates[i], candidates[j]
+		if pending[a] != pending[b] {
+			return pending[a] < pending[b]
+		}
+		return a < b
+	})
+	return candidates[0], true
+}
+
+// Reducible reports whether a review may shorten a decision. Warnings have
+// nothing to shorten and hidden posts are either restored or not.
+func Reducible(d Decision) bool {
+	return d.Type != models.AppealContent && d.Kind != warning
+}
+
+// Reduce returns when a penalty or ban lasting days from when it was decided
+// would expire. The reduction must end it before it would have expired.
+func Reduce(d Decision, days int) (time.Time, error) {
+	violations := errors.NewValidationErrors()
+	if !Reducible(d) {
+		violations.Add("outcome", "only penalties and bans other than warnings can be reduced", d.Kind)
+		return time.Time{}, violations
+	}
+	if days < 1 {
+		violations.Add("reducedDays", "must be at least 1", fmt.Sprint(days))
+		return time.Time{}, violations
+	}
+
+	expiresAt := d.DecidedAt.AddDate(0, 0, days)
+	if d.ExpiresAt != nil && !expiresAt.Before(*d.ExpiresAt) {
+		violations.Add("reducedDays", "must end the decision before it would have expired", fmt.Sprint(days))
+		return time.Time{}, violations
+	}
+	return expiresAt, nil
+}
+
+// Summarize fills in the rates of appeal stats from their counts
+func Summarize(stats *models.AppealStats) {
+	resolved := stats.Upheld + stats.Reduced + stats.Overturned
+	if resolved == 0 {
+		stats.OverturnRate, stats.WithinSLARate = 0, 0
+		return
+	}
+	stats.OverturnRate = round(float64(stats.Overturned) / float64(resolved))
+	stats.WithinSLARate = round(float64(stats.ResolvedWithinSLA) / float64(resolved))
+	stats.AverageHoursToReview = round(stats.AverageHoursToReview)
+}
+
+func round(x float64) float64 {
+	return math.Round(x*100) / 100
+}
@@ -0,0 +1,112 @@
+package appeals
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/yerenwgventures/GreatNigeriaLibrary-Foundation/backend/pkg/common/errors"
+	"github.com/yerenwgventures/GreatNigeriaLibrary-Foundation/backend/services/discussion/models"
+)
+
+var now = time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+
+func suspension() Decision {
+	expires := now.AddDate(0, 0, 20)
+	return Decision{
+		Type:        models.AppealPenalty,
+		ID:          7,
+		UserID:      42,
+		ModeratorID: 3,
+		Kind:        string(models.PenaltyTypeSuspension),
+		DecidedAt:   now.AddDate(0, 0, -10),
+		ExpiresAt:   &expires,
+		Active:      true,
+	}
+}
+
+func TestCanAppeal(t *testing.T) {
+	d := suspension()
+	assert.NoError(t, CanAppeal(d, 42, DefaultWindow, now))
+	assert.Equal(t, models.ErrPermissionDenied, CanAppeal(d, 43, DefaultWindow, now))
+	assert.Equal(t, models.ErrAppealWindowClosed, CanAppeal(d, 42, DefaultWindow, now.AddDate(0, 0, 5)))
+
+	d.Active = false
+	assert.Equal(t, models.ErrNotAppealable, CanAppeal(d, 42, DefaultWindow, now), "removed penalties")
+
+	d = suspension()
+	expired := now.Add(-time.Minute)
+	d.ExpiresAt = &expired
+	assert.Equal(t, models.ErrNotAppealable, CanAppeal(d, 42, DefaultWindow, now), "expired penalties")
+
+	hidden := Decision{Type: models.AppealContent, UserID: 42, Kind: "hidden", DecidedAt: now, Active: true}
+	assert.Equal(t, models.ErrNotAppealable, CanAppeal(hidden, 42, DefaultWindow, now), "posts awaiting a moderator")
+	hidden.ModeratorID = 3
+	assert.NoError(t, CanAppeal(hidden, 42, DefaultWindow, now))
+}
+
+func TestPickReviewer(t *testing.T) {
+	d := suspension()
+	expired := now.Add(-time.Hour)
+	moderators := []models.ModeratorPrivilege{
+		{UserID: 3, IsActive: true, IsGlobalModerator: true}, // made the decision
+		{UserID: 4, IsActive: true, CanApproveContent: true}, // may not ban
+		{UserID: 5, IsActive: true, CanBanUsers: true, ExpiresAt: &expired},
+		{UserID: 6, IsActive: false, IsGlobalModerator: true},
+		{UserID: 9, IsActive: true, CanBanUsers: true},
+		{UserID: 8, IsActive: true, IsGlobalModerator: true},
+		{UserID: 42, IsActive: true, IsGlobalModerator: true}, // the appellant
+	}
+
+	reviewer, ok := PickReviewer(moderators, d, 42, nil, now)
+	require.True(t, ok)
+	assert.Equal(t, uint(8), reviewer, "ties go to the lowest user ID")
+
+	reviewer, _ = PickReviewer(moderators, d, 42, map[uint]int64{8: 3, 9: 1}, now)
+	assert.Equal(t, uint(9), reviewer, "the least busy moderator reviews")
+
+	content := Decision{Type: models.AppealContent, ModeratorID: 8}
+	reviewer, _ = PickReviewer(moderators, content, 42, map[uint]int64{4: 5}, now)
+	assert.Equal(t, uint(3), reviewer)
+
+	_, ok = PickReviewer(moderators[:1], d, 42, nil, now)
+	assert.False(t, ok, "the deciding moderator never reviews")
+}
+
+func TestReduce(t *testing.T) {
+	d := suspension()
+
+	expiresAt, err := Reduce(d, 15)
+	require.NoError(t, err)
+	assert.Equal(t, now.AddDate(0, 0, 5), expiresAt)
+
+	for _, days := range []int{0, 30} {
+		_, err = Reduce(d, days)
+		require.IsType(t, &errors.ValidationErrors{}, err)
+		assert.Equal(t, "reducedDays", err.(*errors.ValidationErrors).Errors[0].Field)
+	}
+
+	// Permanent bans become temporary
+	d.Kind, d.ExpiresAt = string(models.PenaltyTypeBan), nil
+	_, err = Reduce(d, 365)
+	assert.NoError(t, err)
+
+	d.Kind = string(models.PenaltyTypeWarning)
+	assert.False(t, Reducible(d))
+	_, err = Reduce(d, 1)
+	assert.Error(t, err)
+	assert.False(t, Reducible(Decision{Type: models.AppealContent, Kind: "hidden"}))
+}
+
+func TestSummarize(t *testing.T) {
+	stats := &models.AppealStats{Upheld: 5, Reduced: 2, Overturned: 1, ResolvedWithinSLA: 6, AverageHoursToReview: 30.456}
+	Summarize(stats)
+	assert.Equal(t, 0.13, stats.OverturnRate)
+	assert.Equal(t, 0.75, stats.WithinSLARate)
+	assert.Equal(t, 30.46, stats.AverageHoursToReview)
+
+	empty := &models.AppealStats{Pending: 2}
+	Summarize(empty)
+	assert.Zero(t, empty.OverturnRate)
+}
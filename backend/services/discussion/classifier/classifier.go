@@ -0,0 +1,268 @@
+// Package classifier scores discussion posts for spam and abuse. A naive
+// Bayes model trained on moderators' decisions is combined with heuristic
+// signals about the post and its author, such as link density, repeated
+// posts and how quickly a new account is posting.
+//
+// The package is pure Go and keeps no state of its own: models are trained
+// from labelled examples and serialised as JSON by the caller.
+package classifier
+
+import (
+	"fmt"
+	"math"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// Sample is a post about to be scored, with what is known about its author
+type Sample struct {
+	Title   string
+	Content string
+	// TrustLevel is the author's discussion trust level, such as "new_user"
+	TrustLevel string
+	// AccountAge is how long the author has been registered; zero if unknown
+	AccountAge time.Duration
+	// RecentPosts is how many posts the author made in the last hour
+	RecentPosts int
+	// RepeatedPosts is how many earlier posts of the author have the same content
+	RepeatedPosts int
+}
+
+// Result is the score of a sample. Scores range from 0 to 1.
+type Result struct {
+	// Spam combines the model and heuristic scores
+	Spam      float64 `json:"spam"`
+	Bayes     float64 `json:"bayes"`
+	Heuristic float64 `json:"heuristic"`
+	// Negativity estimates how hostile the text is
+	Negativity float64 `json:"negativity"`
+	// Signals describe the heuristics that fired
+	Signals []string `json:"signals,omitempty"`
+	// Features are what the model scored; they are kept to retrain it
+	Features []string `json:"features,omitempty"`
+}
+
+var (
+	linkPattern = regexp.MustCompile(`(?i)\b(?:https?://|www\.)[^\s<>"']+`)
+	wordPattern = regexp.MustCompile(`[\pL\pN][\pL\pN'_-]*`)
+)
+
+// maxTokenLength drops tokens that are more likely noise than words
+const maxTokenLength = 32
+
+// text joins the title and content of a sample
+func (s Sample) text() string {
+	if s.Title == "" {
+		return s.Content
+	}
+	return s.Title + "\n" + s.Content
+}
+
+// links returns the links in text
+func links(text string) []string {
+	return linkPattern.FindAllString(text, -1)
+}
+
+// linkHost returns the host of a link without a leading "www."
+func linkHost(link string) string {
+	if !strings.Contains(link, "://") {
+		link = "http://" + link
+	}
+	u, err := url.Parse(strings.TrimRight(link, ".,;:!?)"))
+	if err != nil {
+		return ""
+	}
+	return strings.TrimPrefix(strings.ToLower(u.Hostname()), "www.")
+}
+
+// words returns the lowercased words of text, links excluded
+func words(text string) []string {
+	text = linkPattern.ReplaceAllString(text, " ")
+	var words []string
+	for _, w := range wordPattern.FindAllString(text, -1) {
+		w = strings.ToLower(strings.Trim(w, "'_-"))
+		if n := len([]rune(w)); n >= 2 && n <= maxTokenLength {
+			words = append(words, w)
+		}
+	}
+	return words
+}
+
+// bucket names the range a count falls in, given ascending lower bounds
+func bucket(n int, bounds ...int) string {
+	i := 0
+	for i+1 < len(bounds) && n >= bounds[i+1] {
+		i++
+	}
+	switch {
+	case i == len(bounds)-1:
+		return fmt.Sprintf("%d+", bounds[i])
+	case bounds[i+1]-bounds[i] == 1:
+		return fmt.Sprint(bounds[i])
+	}
+	return fmt.Sprintf("%d-%d", bounds[i], bounds[i+1]-1)
+}
+
+// ageBucket names the range an account age falls in
+func ageBucket(age time.Duration) string {
+	switch {
+	case age <= 0:
+		return "unknown"
+	case age < 24*time.Hour:
+		return "day"
+	case age < 7*24*time.Hour:
+		return "week"
+	case age < 30*24*time.Hour:
+		return "month"
+	}
+	return "older"
+}
+
+// Features returns the features the model scores a sample on: its distinct
+// words, the hosts it links to, and buckets of the author's signals. Each
+// feature appears once.
+func Features(s Sample) []string {
+	text := s.text()
+	seen := map[string]bool{}
+	var features []string
+	add := func(f string) {
+		if !seen[f] {
+			seen[f] = true
+			features = append(features, f)
+		}
+	}
+
+	for _, w := range words(text) {
+		add(w)
+	}
+	found := links(text)
+	for _, link := range found {
+		if host := linkHost(link); host != "" {
+			add("host:" + host)
+		}
+	}
+	add("links:" + bucket(len(found), 0, 1, 2, 4))
+	if s.TrustLevel != "" {
+		add("trust:" + s.TrustLevel)
+	}
+	add("age:" + ageBucket(s.AccountAge))
+	add("recent:" + bucket(s.RecentPosts, 0, 1, 3, 10))
+	add("repeated:" + bucket(s.RepeatedPosts, 0, 1, 3))
+	return features
+}
+
+// signal is a heuristic that fired, with how strongly it suggests spam
+type signal struct {
+	name   string
+	weight float64
+}
+
+// heuristics returns the heuristic signals of a sample
+func heuristics(s Sample) []signal {
+	text := s.text()
+	var signals []signal
+
+	linkCount := len(links(text))
+	wordCount := len(words(text))
+	if linkCount > 0 {
+		density := float64(linkCount) / float64(linkCount+wordCount)
+		switch {
+		case density >= 0.5:
+			signals = append(signals, signal{"mostly links", 0.7})
+		case density >= 0.2 && linkCount >= 2:
+			signals = append(signals, signal{"high link density", 0.4})
+		}
+	}
+
+	switch {
+	case s.RepeatedPosts >= 3:
+		signals = append(signals, signal{"posted the same content repeatedly", 0.8})
+	case s.RepeatedPosts >= 1:
+		signals = append(signals, signal{"posted the same content before", 0.4})
+	}
+
+	newAccount := s.AccountAge > 0 && s.AccountAge < 24*time.Hour
+	switch {
+	case s.RecentPosts >= 10:
+		signals = append(signals, signal{"posting very quickly", 0.6})
+	case s.RecentPosts >= 5 && newAccount:
+		signals = append(signals, signal{"new account posting quickly", 0.5})
+	}
+	if newAccount && linkCount > 0 {
+		signals = append(signals, signal{"new account posting links", 0.3})
+	}
+
+	if shouting(text) {
+		signals = append(signals, signal{"mostly capitals", 0.2})
+	}
+	return signals
+}
+
+// shouting reports whether most letters of a longer text are capitals
+func shouting(text string) bool {
+	var letters, upper int
+	for _, r := range text {
+		if unicode.IsLetter(r) {
+			letters++
+			if unicode.IsUpper(r) {
+				upper++
+			}
+		}
+	}
+	return letters >= 20 && upper*10 >= letters*7
+}
+
+// trustedLevels dampen heuristic scores, which misfire on enthusiastic but
+// established members
+var trustedLevels = map[string]float64{
+	"regular": 0.5,
+	"leader":  0.3,
+}
+
+// combine merges independent scores as a noisy-or: each score alone can
+// raise the result, and agreeing scores raise it further
+func combine(scores ...float64) float64 {
+	rest := 1.0
+	for _, s := range scores {
+		rest *= 1 - math.Max(0, math.Min(1, s))
+	}
+	return 1 - rest
+}
+
+// Score returns the combined spam score of a post from its model and
+// heuristic scores. Untrained models contribute nothing.
+func Score(model *Model, features []string, heuristic float64) (spam, bayes float64) {
+	if model == nil || !model.Trained() {
+		return heuristic, 0
+	}
+	bayes = model.Probability(features)
+	return combine(bayes, heuristic), bayes
+}
+
+// Classify scores a sample with a model, which may be nil or untrained
+func Classify(model *Model, s Sample) Result {
+	var weights []float64
+	var names []string
+	for _, sig := range heuristics(s) {
+		weights = append(weights, sig.weight)
+		names = append(names, sig.name)
+	}
+	heuristic := combine(weights...)
+	if damping, ok := trustedLevels[s.TrustLevel]; ok {
+		heuristic *= damping
+	}
+
+	features := Features(s)
+	spam, bayes := Score(model, features, heuristic)
+	return Result{
+		Spam:       spam,
+		Bayes:      bayes,
+		Heuristic:  heuristic,
+		Negativity: Negativity(s.text()),
+		Signals:    names,
+		Features:   features,
+	}
+}
@@ -0,0 +1,122 @@
+package classifier
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// examples returns labelled posts: offers of easy money linking to the
+// same few sites, and ordinary discussion
+func examples(n int) []Example {
+	var examples []Example
+	for i := 0; i < n; i++ {
+		spam := Sample{
+			Content:    fmt.Sprintf("Earn cash fast from home, guaranteed profit %d! Visit https://quick-cash%d.example now", i, i%3),
+			TrustLevel: "new_user",
+		}
+		ham := Sample{
+			Content:    fmt.Sprintf("Chapter %d of the book explains how the constitution shares power between the states", i),
+			TrustLevel: "member",
+			AccountAge: 90 * 24 * time.Hour,
+		}
+		examples = append(examples,
+			Example{Features: Features(spam), Spam: true},
+			Example{Features: Features(ham)},
+		)
+	}
+	return examples
+}
+
+func TestFeatures(t *testing.T) {
+	features := Features(Sample{
+		Title:         "Hello",
+		Content:       "hello HELLO, see www.Example.com and https://docs.example.org/a.",
+		TrustLevel:    "basic",
+		AccountAge:    time.Hour,
+		RecentPosts:   4,
+		RepeatedPosts: 1,
+	})
+	assert.Equal(t, []string{
+		"hello", "see", "and",
+		"host:example.com", "host:docs.example.org",
+		"links:2-3", "trust:basic", "age:day", "recent:3-9", "repeated:1-2",
+	}, features)
+
+	assert.Contains(t, Features(Sample{Content: "plain"}), "links:0")
+	assert.Contains(t, Features(Sample{RecentPosts: 12}), "recent:10+")
+}
+
+func TestModel(t *testing.T) {
+	model := NewModel()
+	assert.False(t, model.Trained())
+	assert.Equal(t, 0.5, model.Probability([]string{"anything"}))
+
+	model = Train(examples(10))
+	require.True(t, model.Trained())
+
+	spam := Features(Sample{Content: "Guaranteed cash from home, visit https://quick-cash1.example", TrustLevel: "new_user"})
+	ham := Features(Sample{Content: "The constitution explains how states share power", TrustLevel: "member"})
+	assert.Greater(t, model.Probability(spam), 0.9)
+	assert.Less(t, model.Probability(ham), 0.1)
+	assert.Equal(t, model.Probability(nil), model.Probability([]string{"unseen"}), "unseen features are ignored")
+
+	data, err := model.Encode()
+	require.NoError(t, err)
+	decoded, err := Decode(data)
+	require.NoError(t, err)
+	assert.Equal(t, model.Probability(spam), decoded.Probability(spam))
+}
+
+func TestClassify(t *testing.T) {
+	repeated := Sample{
+		Content:       "Buy now https://a.example https://b.example",
+		AccountAge:    time.Hour,
+		RecentPosts:   6,
+		RepeatedPosts: 3,
+	}
+	result := Classify(nil, repeated)
+	assert.Equal(t, 0.0, result.Bayes, "untrained models contribute nothing")
+	assert.Equal(t, result.Heuristic, result.Spam)
+	assert.Greater(t, result.Spam, 0.9)
+	assert.Equal(t, []string{
+		"mostly links",
+		"posted the same content repeatedly",
+		"new account posting quickly",
+		"new account posting links",
+	}, result.Signals)
+
+	repeated.TrustLevel = "leader"
+	assert.Less(t, Classify(nil, repeated).Heuristic, result.Heuristic, "trusted members are given the benefit of the doubt")
+
+	quiet := Classify(Train(examples(10)), Sample{Content: "Chapter 4 explains how the states share power", TrustLevel: "member"})
+	assert.Empty(t, quiet.Signals)
+	assert.Less(t, quiet.Spam, 0.1)
+}
+
+func TestNegativity(t *testing.T) {
+	assert.Equal(t, 0.0, Negativity("A thoughtful reply"))
+	assert.Equal(t, 0.5, Negativity("That is nonsense"))
+	assert.InDelta(t, 0.75, Negativity("You stupid idiot, what rubbish"), 0.001)
+	assert.InDelta(t, 0.33, Negativity("Rubbish argument, but thanks for sharing"), 0.01)
+}
+
+func TestCrossValidate(t *testing.T) {
+	_, err := CrossValidate(examples(1), 5, 0.5)
+	assert.Error(t, err)
+
+	metrics, err := CrossValidate(examples(20), 5, 0.5, 1.1)
+	require.NoError(t, err)
+	assert.Equal(t, Metrics{TruePositives: 20, TrueNegatives: 20}, metrics[0])
+	assert.Equal(t, 1.0, metrics[0].Precision())
+	assert.Equal(t, 1.0, metrics[0].Recall())
+	assert.Equal(t, 1.0, metrics[0].F1())
+
+	// Nothing reaches a threshold above 1
+	assert.Equal(t, Metrics{FalseNegatives: 20, TrueNegatives: 20}, metrics[1])
+	assert.Equal(t, 0.0, metrics[1].Precision())
+	assert.Equal(t, 0.5, metrics[1].Accuracy())
+}
@@ -0,0 +1,119 @@
+package classifier
+
+import "fmt"
+
+// Example is a post a moderator labelled, as it was scored when posted
+type Example struct {
+	Features  []string
+	Heuristic float64
+	Spam      bool
+}
+
+// Train builds a model from labelled examples
+func Train(examples []Example) *Model {
+	m := NewModel()
+	for _, e := range examples {
+		m.Train(e.Features, e.Spam)
+	}
+	return m
+}
+
+// Metrics count a classifier's outcomes on labelled examples
+type Metrics struct {
+	TruePositives  int `json:"truePositives"`
+	FalsePositives int `json:"falsePositives"`
+	TrueNegatives  int `json:"trueNegatives"`
+	FalseNegatives int `json:"falseNegatives"`
+}
+
+// ratio divides, returning 0 for an empty denominator
+func ratio(n, d int) float64 {
+	if d == 0 {
+		return 0
+	}
+	return float64(n) / float64(d)
+}
+
+// Precision is the share of posts scored as spam that were spam
+func (m Metrics) Precision() float64 {
+	return ratio(m.TruePositives, m.TruePositives+m.FalsePositives)
+}
+
+// Recall is the share of spam that was scored as spam
+func (m Metrics) Recall() float64 {
+	return ratio(m.TruePositives, m.TruePositives+m.FalseNegatives)
+}
+
+// Accuracy is the share of posts scored correctly
+func (m Metrics) Accuracy() float64 {
+	return ratio(m.TruePositives+m.TrueNegatives, m.TruePositives+m.FalsePositives+m.TrueNegatives+m.FalseNegatives)
+}
+
+// F1 is the harmonic mean of precision and recall
+func (m Metrics) F1() float64 {
+	p, r := m.Precision(), m.Recall()
+	if p+r == 0 {
+		return 0
+	}
+	return 2 * p * r / (p + r)
+}
+
+// add counts one outcome
+func (m *Metrics) add(predicted, actual bool) {
+	switch {
+	case predicted && actual:
+		m.TruePositives++
+	case predicted:
+		m.FalsePositives++
+	case actual:
+		m.FalseNegatives++
+	default:
+		m.TrueNegatives++
+	}
+}
+
+// Evaluate scores examples with a model and counts the outcomes at a
+// threshold of the combined spam score
+func Evaluate(model *Model, examples []Example, threshold float64) Metrics {
+	var metrics Metrics
+	for _, e := range examples {
+		spam, _ := Score(model, e.Features, e.Heuristic)
+		metrics.add(spam >= threshold, e.Spam)
+	}
+	return metrics
+}
+
+// CrossValidate estimates how a model trained on the examples would score
+// posts it has not seen. The examples are split into folds; each fold is
+// scored at every threshold by a model trained on the others, and the
+// outcomes are summed per threshold.
+func CrossValidate(examples []Example, folds int, thresholds ...float64) ([]Metrics, error) {
+	if folds < 2 {
+		return nil, fmt.Errorf("cross-validation needs at least 2 folds, got %d", folds)
+	}
+	if len(examples) < folds {
+		return nil, fmt.Errorf("%d examples are too few for %d folds", len(examples), folds)
+	}
+
+	metrics := make([]Metrics, len(thresholds))
+	for fold := 0; fold < folds; fold++ {
+		var training, testing []Example
+		for i, e := range examples {
+			if i%folds == fold {
+				testing = append(testing, e)
+			} else {
+				training = append(training, e)
+			}
+		}
+
+		model := Train(training)
+		for i, threshold := range thresholds {
+			m := Evaluate(model, testing, threshold)
+			metrics[i].TruePositives += m.TruePositives
+			metrics[i].FalsePositives += m.FalsePositives
+			metrics[i].TrueNegatives += m.TrueNegatives
+			metrics[i].FalseNegatives += m.FalseNegatives
+		}
+	}
+	return metrics, nil
+}
@@ -0,0 +1,112 @@
+package classifier
+
+import (
+	"encoding/json"
+	"math"
+)
+
+// Minimum examples of each class before a model is trusted
+const MinClassExamples = 5
+
+// Class holds the training counts of one class
+type Class struct {
+	// Examples is the number of posts trained on
+	Examples int `json:"examples"`
+	// Counts is how many of those posts had each feature
+	Counts map[string]int `json:"counts"`
+	// Total is the sum of Counts
+	Total int `json:"total"`
+}
+
+// Model is a naive Bayes model telling spam from legitimate posts. Its zero
+// value is not usable; create models with NewModel or Decode.
+type Model struct {
+	Spam Class `json:"spam"`
+	Ham  Class `json:"ham"`
+}
+
+// NewModel returns an untrained model
+func NewModel() *Model {
+	return &Model{
+		Spam: Class{Counts: map[string]int{}},
+		Ham:  Class{Counts: map[string]int{}},
+	}
+}
+
+// Decode reads a model written by Encode
+func Decode(data []byte) (*Model, error) {
+	m := NewModel()
+	if err := json.Unmarshal(data, m); err != nil {
+		return nil, err
+	}
+	if m.Spam.Counts == nil {
+		m.Spam.Counts = map[string]int{}
+	}
+	if m.Ham.Counts == nil {
+		m.Ham.Counts = map[string]int{}
+	}
+	return m, nil
+}
+
+// Encode serialises the model as JSON
+func (m *Model) Encode() ([]byte, error) {
+	return json.Marshal(m)
+}
+
+// Train adds a labelled post, given by its features, to the model
+func (m *Model) Train(features []string, spam bool) {
+	class := &m.Ham
+	if spam {
+		class = &m.Spam
+	}
+	class.Examples++
+	for _, f := range features {
+		class.Counts[f]++
+		class.Total++
+	}
+}
+
+// Trained reports whether the model has seen enough of both classes to score posts
+func (m *Model) Trained() bool {
+	return m.Spam.Examples >= MinClassExamples && m.Ham.Examples >= MinClassExamples
+}
+
+// vocabulary returns the number of distinct features the model has seen
+func (m *Model) vocabulary() int {
+	n := len(m.Spam.Counts)
+	for f := range m.Ham.Counts {
+		if _, ok := m.Spam.Counts[f]; !ok {
+			n++
+		}
+	}
+	return n
+}
+
+// Probability returns the probability that a post with the given features is
+// spam. Features the model has never seen are ignored, and counts are
+// Laplace smoothed so no single feature decides alone.
+func (m *Model) Probability(features []string) float64 {
+	examples := m.Spam.Examples + m.Ham.Examples
+	if examples == 0 {
+		return 0.5
+	}
+
+	vocabulary := float64(m.vocabulary())
+	spam := math.Log(float64(m.Spam.Examples+1) / float64(examples+2))
+	ham := math.Log(float64(m.Ham.Examples+1) / float64(examples+2))
+	for _, f := range features {
+		s, h := m.Spam.Counts[f], m.Ham.Counts[f]
+		if s == 0 && h == 0 {
+			continue
+		}
+		spam += math.Log(float64(s+1) / (float64(m.Spam.Total) + vocabulary))
+		ham += math.Log(float64(h+1) / (float64(m.Ham.Total) + vocabulary))
+	}
+
+	// P(spam) = 1 / (1 + e^(ham - spam)), guarding against overflow
+	diff := ham - spam
+	if diff > 700 {
+		return 0
+	}
+	return 1 / (1 + math.Exp(diff))
+}
@@ -0,0 +1,40 @@
+package classifier
+
+// negativeWords are hostile or abusive words. The list is deliberately short:
+// it estimates tone for AutoModerationSettings.NegativeSentimentLimit and
+// holds posts for a moderator, it does not remove anything.
+var negativeWords = map[string]bool{
+	"idiot": true, "idiots": true, "stupid": true, "fool": true, "fools": true,
+	"moron": true, "morons": true, "dumb": true, "useless": true, "worthless": true,
+	"pathetic": true, "disgusting": true, "shameless": true, "liar": true, "liars": true,
+	"hate": true, "hateful": true, "trash": true, "garbage": true, "rubbish": true,
+	"nonsense": true, "ugly": true, "scum": true, "coward": true,
+	"cowards": true, "thief": true, "thieves": true, "ignorant": true, "clown": true,
+	"clowns": true, "mumu": true, "oponu": true, "werey": true,
+}
+
+// positiveWords soften the score of heated but constructive posts
+var positiveWords = map[string]bool{
+	"thanks": true, "thank": true, "agree": true, "appreciate": true, "great": true,
+	"good": true, "helpful": true, "respect": true, "please": true, "welcome": true,
+	"love": true, "support": true, "insightful": true, "kind": true,
+}
+
+// Negativity estimates how hostile a text is, from 0 to 1. One hostile word
+// scores 0.5 and each further one moves the score closer to 1; friendly
+// words pull it back down.
+func Negativity(text string) float64 {
+	var negative, positive int
+	for _, w := range words(text) {
+		switch {
+		case negativeWords[w]:
+			negative++
+		case positiveWords[w]:
+			positive++
+		}
+	}
+	if negative == 0 {
+		return 0
+	}
+	return float64(negative) / float64(negative+positive+1)
+}
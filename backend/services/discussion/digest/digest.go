@@ -0,0 +1,75 @@
+// Package digest schedules daily and weekly subscription digests.
+//
+// Each user's digests fall due at a slot in their own time zone: every day at
+// their digest hour for daily digests, and on their digest day at that hour
+// for weekly ones. A subscription is reported in a digest once the slot
+// passes the start of its window, which opens when it was created or last
+// reported on.
+package digest
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/yerenwgventures/GreatNigeriaLibrary-Foundation/backend/services/discussion/models"
+)
+
+// Defaults used when a user has no preferences or they are out of range
+const (
+	DefaultHour = 8
+	DefaultDay  = time.Sunday
+)
+
+// Slot returns the most recent scheduled digest time at or before now, in the
+// user's time zone, and a key identifying that period. Daily digests are due
+// at DigestHour every day; weekly digests at DigestHour on DigestDay.
+func Slot(frequency models.SubscriptionFrequency, preference *models.SubscriptionPreference, now time.Time) (time.Time, string) {
+	location := time.UTC
+	hour, day := DefaultHour, int(DefaultDay)
+	if preference != nil {
+		if preference.TimeZone != "" {
+			if loaded, err := time.LoadLocation(preference.TimeZone); err == nil {
+				location = loaded
+			}
+		}
+		if preference.DigestHour >= 0 && preference.DigestHour <= 23 {
+			hour = preference.DigestHour
+		}
+		if preference.DigestDay >= 0 && preference.DigestDay <= 6 {
+			day = preference.DigestDay
+		}
+	}
+
+	local := now.In(location)
+	slot := time.Date(local.Year(), local.Month(), local.Day(), hour, 0, 0, 0, location)
+
+	if frequency == models.FrequencyWeekly {
+		slot = slot.AddDate(0, 0, -((int(local.Weekday()) - day + 7) % 7))
+		if slot.After(local) {
+			slot = slot.AddDate(0, 0, -7)
+		}
+	} else if slot.After(local) {
+		slot = slot.AddDate(0, 0, -1)
+	}
+
+	return slot, fmt.Sprintf("%s:%s", frequency, slot.Format("2006-01-02"))
+}
+
+// WindowStart returns the time from which a subscription's activity is reported
+func WindowStart(subscription models.AdvancedSubscription) time.Time {
+	if subscription.LastNotifiedAt != nil {
+		return *subscription.LastNotifiedAt
+	}
+	return subscription.CreatedAt
+}
+
+// Due returns the subscriptions whose window started before slot
+func Due(subscriptions []models.AdvancedSubscription, slot time.Time) []models.AdvancedSubscription {
+	var due []models.AdvancedSubscription
+	for _, subscription := range subscriptions {
+		if slot.After(WindowStart(subscription)) {
+			due = append(due, subscription)
+		}
+	}
+	return due
+}
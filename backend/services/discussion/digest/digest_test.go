@@ -0,0 +1,222 @@
+package digest
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/yerenwgventures/GreatNigeriaLibrary-Foundation/backend/services/discussion/models"
+)
+
+func utc(year int, month time.Month, day, hour, minute, second int) time.Time {
+	return time.Date(year, month, day, hour, minute, second, 0, time.UTC)
+}
+
+func TestSlot(t *testing.T) {
+	// 2024-05-01 is a Wednesday
+	cases := []struct {
+		name       string
+		frequency  models.SubscriptionFrequency
+		preference *models.SubscriptionPreference
+		now        time.Time
+		slot       time.Time
+		periodKey  string
+	}{
+		{
+			name:      "daily default at the slot",
+			frequency: models.FrequencyDaily,
+			now:       utc(2024, 5, 1, 8, 0, 0),
+			slot:      utc(2024, 5, 1, 8, 0, 0),
+			periodKey: "daily:2024-05-01",
+		},
+		{
+			name:      "daily default just before the slot",
+			frequency: models.FrequencyDaily,
+			now:       utc(2024, 5, 1, 7, 59, 59),
+			slot:      utc(2024, 4, 30, 8, 0, 0),
+			periodKey: "daily:2024-04-30",
+		},
+		{
+			name:       "daily at midnight",
+			frequency:  models.FrequencyDaily,
+			preference: &models.SubscriptionPreference{DigestHour: 0},
+			now:        utc(2024, 5, 1, 0, 0, 0),
+			slot:       utc(2024, 5, 1, 0, 0, 0),
+			periodKey:  "daily:2024-05-01",
+		},
+		{
+			name:       "daily across the new year",
+			frequency:  models.FrequencyDaily,
+			preference: &models.SubscriptionPreference{DigestHour: 23},
+			now:        utc(2025, 1, 1, 22, 0, 0),
+			slot:       utc(2024, 12, 31, 23, 0, 0),
+			periodKey:  "daily:2024-12-31",
+		},
+		{
+			name:       "daily in Lagos",
+			frequency:  models.FrequencyDaily,
+			preference: &models.SubscriptionPreference{DigestHour: 7, TimeZone: "Africa/Lagos"},
+			now:        utc(2024, 5, 1, 6, 0, 0),
+			slot:       utc(2024, 5, 1, 6, 0, 0),
+			periodKey:  "daily:2024-05-01",
+		},
+		{
+			name:       "daily in Lagos before the slot",
+			frequency:  models.FrequencyDaily,
+			preference: &models.SubscriptionPreference{DigestHour: 7, TimeZone: "Africa/Lagos"},
+			now:        utc(2024, 5, 1, 5, 59, 0),
+			slot:       utc(2024, 4, 30, 6, 0, 0),
+			periodKey:  "daily:2024-04-30",
+		},
+		{
+			name:       "daily west of UTC keys by the local date",
+			frequency:  models.FrequencyDaily,
+			preference: &models.SubscriptionPreference{DigestHour: 20, TimeZone: "America/New_York"},
+			now:        utc(2024, 5, 2, 0, 30, 0),
+			slot:       utc(2024, 5, 2, 0, 0, 0),
+			periodKey:  "daily:2024-05-01",
+		},
+		{
+			name:       "daily east of UTC keys by the local date",
+			frequency:  models.FrequencyDaily,
+			preference: &models.SubscriptionPreference{DigestHour: 6, TimeZone: "Pacific/Auckland"},
+			now:        utc(2024, 4, 30, 18, 0, 0),
+			slot:       utc(2024, 4, 30, 18, 0, 0),
+			periodKey:  "daily:2024-05-01",
+		},
+		{
+			name:       "unknown time zone falls back to UTC",
+			frequency:  models.FrequencyDaily,
+			preference: &models.SubscriptionPreference{DigestHour: 9, TimeZone: "Mars/Olympus_Mons"},
+			now:        utc(2024, 5, 1, 9, 30, 0),
+			slot:       utc(2024, 5, 1, 9, 0, 0),
+			periodKey:  "daily:2024-05-01",
+		},
+		{
+			name:       "out of range hour uses the default",
+			frequency:  models.FrequencyDaily,
+			preference: &models.SubscriptionPreference{DigestHour: 24},
+			now:        utc(2024, 5, 1, 9, 0, 0),
+			slot:       utc(2024, 5, 1, 8, 0, 0),
+			periodKey:  "daily:2024-05-01",
+		},
+		{
+			name:      "weekly default is Sunday",
+			frequency: models.FrequencyWeekly,
+			now:       utc(2024, 5, 1, 12, 0, 0),
+			slot:      utc(2024, 4, 28, 8, 0, 0),
+			periodKey: "weekly:2024-04-28",
+		},
+		{
+			name:       "weekly on the digest day at the slot",
+			frequency:  models.FrequencyWeekly,
+			preference: &models.SubscriptionPreference{DigestDay: 3, DigestHour: 9},
+			now:        utc(2024, 5, 1, 9, 0, 0),
+			slot:       utc(2024, 5, 1, 9, 0, 0),
+			periodKey:  "weekly:2024-05-01",
+		},
+		{
+			name:       "weekly on the digest day before the slot",
+			frequency:  models.FrequencyWeekly,
+			preference: &models.SubscriptionPreference{DigestDay: 3, DigestHour: 9},
+			now:        utc(2024, 5, 1, 8, 59, 59),
+			slot:       utc(2024, 4, 24, 9, 0, 0),
+			periodKey:  "weekly:2024-04-24",
+		},
+		{
+			name:       "weekly digest day earlier in the week",
+			frequency:  models.FrequencyWeekly,
+			preference: &models.SubscriptionPreference{DigestDay: 1, DigestHour: 9},
+			now:        utc(2024, 5, 1, 12, 0, 0),
+			slot:       utc(2024, 4, 29, 9, 0, 0),
+			periodKey:  "weekly:2024-04-29",
+		},
+		{
+			name:       "weekly digest day later in the week",
+			frequency:  models.FrequencyWeekly,
+			preference: &models.SubscriptionPreference{DigestDay: 4, DigestHour: 9},
+			now:        utc(2024, 5, 1, 12, 0, 0),
+			slot:       utc(2024, 4, 25, 9, 0, 0),
+			periodKey:  "weekly:2024-04-25",
+		},
+		{
+			name:       "weekly out of range day uses the default",
+			frequency:  models.FrequencyWeekly,
+			preference: &models.SubscriptionPreference{DigestDay: 7, DigestHour: 9},
+			now:        utc(2024, 5, 1, 12, 0, 0),
+			slot:       utc(2024, 4, 28, 9, 0, 0),
+			periodKey:  "weekly:2024-04-28",
+		},
+		{
+			name:       "weekly digest day follows the local date",
+			frequency:  models.FrequencyWeekly,
+			preference: &models.SubscriptionPreference{DigestDay: 3, DigestHour: 6, TimeZone: "Pacific/Auckland"},
+			now:        utc(2024, 4, 30, 18, 0, 0),
+			slot:       utc(2024, 4, 30, 18, 0, 0),
+			periodKey:  "weekly:2024-05-01",
+		},
+		{
+			name:       "weekly across the start of daylight saving time",
+			frequency:  models.FrequencyWeekly,
+			preference: &models.SubscriptionPreference{DigestDay: 0, DigestHour: 9, TimeZone: "America/New_York"},
+			now:        utc(2024, 3, 10, 13, 0, 0),
+			slot:       utc(2024, 3, 10, 13, 0, 0),
+			periodKey:  "weekly:2024-03-10",
+		},
+		{
+			name:       "weekly before the slot falls back to standard time",
+			frequency:  models.FrequencyWeekly,
+			preference: &models.SubscriptionPreference{DigestDay: 0, DigestHour: 9, TimeZone: "America/New_York"},
+			now:        utc(2024, 3, 10, 12, 59, 0),
+			slot:       utc(2024, 3, 3, 14, 0, 0),
+			periodKey:  "weekly:2024-03-03",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			slot, periodKey := Slot(tc.frequency, tc.preference, tc.now)
+
+			assert.True(t, tc.slot.Equal(slot), "slot %s, want %s", slot.UTC(), tc.slot)
+			assert.Equal(t, tc.periodKey, periodKey)
+			assert.False(t, slot.After(tc.now), "slot must not be in the future")
+		})
+	}
+}
+
+func TestWindowStart(t *testing.T) {
+	created := utc(2024, 4, 1, 0, 0, 0)
+	notified := utc(2024, 4, 20, 8, 0, 0)
+
+	subscription := models.AdvancedSubscription{CreatedAt: created}
+	assert.Equal(t, created, WindowStart(subscription), "never notified")
+
+	subscription.LastNotifiedAt = &notified
+	assert.Equal(t, notified, WindowStart(subscription), "notified before")
+}
+
+func TestDue(t *testing.T) {
+	slot := utc(2024, 5, 1, 8, 0, 0)
+	before := slot.Add(-time.Second)
+	after := slot.Add(time.Second)
+
+	cases := []struct {
+		name         string
+		subscription models.AdvancedSubscription
+		due          bool
+	}{
+		{name: "created before the slot", subscription: models.AdvancedSubscription{CreatedAt: before}, due: true},
+		{name: "created at the slot", subscription: models.AdvancedSubscription{CreatedAt: slot}},
+		{name: "created after the slot", subscription: models.AdvancedSubscription{CreatedAt: after}},
+		{name: "notified before the slot", subscription: models.AdvancedSubscription{CreatedAt: before, LastNotifiedAt: &before}, due: true},
+		{name: "notified at the slot", subscription: models.AdvancedSubscription{CreatedAt: before, LastNotifiedAt: &slot}},
+		{name: "notified after the slot", subscription: models.AdvancedSubscription{CreatedAt: before, LastNotifiedAt: &after}},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			due := Due([]models.AdvancedSubscription{tc.subscription}, slot)
+			assert.Equal(t, tc.due, len(due) == 1)
+		})
+	}
+}
@@ -0,0 +1,232 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/yerenwgventures/GreatNigeriaLibrary-Foundation/backend/pkg/common/errors"
+	"github.com/yerenwgventures/GreatNigeriaLibrary-Foundation/backend/services/discussion/models"
+	"github.com/yerenwgventures/GreatNigeriaLibrary-Foundation/backend/services/discussion/service"
+)
+
+// AppealHandler defines the handler for appeal endpoints
+type AppealHandler struct {
+	appealService service.AppealService
+}
+
+// NewAppealHandler creates a new appeal handler
+func NewAppealHandler(appealService service.AppealService) *AppealHandler {
+	return &AppealHandler{
+		appealService: appealService,
+	}
+}
+
+// RegisterRoutes registers the routes for users to file appeals
+func (h *AppealHandler) RegisterRoutes(router *gin.RouterGroup) {
+	router.POST("/appeals", h.FileAppeal)
+	router.GET("/appeals/me", h.GetMyAppeals)
+}
+
+// RegisterModerationRoutes registers the routes for reviewing appeals. They
+// must be behind the moderator role.
+func (h *AppealHandler) RegisterModerationRoutes(router *gin.RouterGroup) {
+	moderation := router.Group("/moderation")
+	{
+		moderation.GET("/appeals", h.GetAppeals)
+		moderation.GET("/appeals/stats", h.GetAppealStats)
+		moderation.GET("/appeals/:id", h.GetAppeal)
+		moderation.PUT("/appeals/:id/assign", h.AssignAppeal)
+		moderation.PUT("/appeals/:id/review", h.ReviewAppeal)
+	}
+}
+
+// FileAppealRequest represents a request to appeal a moderation decision
+type FileAppealRequest struct {
+	DecisionType string `json:"decisionType" binding:"required,oneof=penalty moderation_action content"`
+	DecisionID   uint   `json:"decisionId" binding:"required"`
+	Reason       string `json:"reason" binding:"required"`
+}
+
+// FileAppeal appeals a penalty, moderation action or hidden post affecting the current user
+func (h *AppealHandler) FileAppeal(c *gin.Context) {
+	var req FileAppealRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Get user ID from context (set by auth middleware)
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	appeal, err := h.appealService.FileAppeal(userID.(uint), models.AppealDecisionType(req.DecisionType), req.DecisionID, req.Reason)
+	if err != nil {
+		respondAppealError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, appeal)
+}
+
+// GetMyAppeals retrieves the appeals the current user filed
+func (h *AppealHandler) GetMyAppeals(c *gin.Context) {
+	// Get user ID from context (set by auth middleware)
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	appeals, err := h.appealService.GetUserAppeals(userID.(uint))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, appeals)
+}
+
+// GetAppeals retrieves appeals, oldest due first
+func (h *AppealHandler) GetAppeals(c *gin.Context) {
+	status := models.AppealStatus(c.DefaultQuery("status", ""))
+	reviewerID, _ := strconv.ParseUint(c.DefaultQuery("reviewer", "0"), 10, 64)
+	overdue := c.DefaultQuery("overdue", "false") == "true"
+
+	// Get pagination parameters
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	pageSize, _ := strconv.Atoi(c.DefaultQuery("pageSize", "20"))
+
+	appeals, count, err := h.appealService.GetAppeals(status, uint(reviewerID), overdue, page, pageSize)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"appeals": appeals,
+		"total":   count,
+		"page":    page,
+		"size":    pageSize,
+	})
+}
+
+// GetAppeal retrieves an appeal by ID
+func (h *AppealHandler) GetAppeal(c *gin.Context) {
+	appealID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid appeal ID"})
+		return
+	}
+
+	appeal, err := h.appealService.GetAppeal(uint(appealID))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Appeal not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, appeal)
+}
+
+// AssignAppealRequest represents a request to hand an appeal to a reviewer
+type AssignAppealRequest struct {
+	ReviewerID uint `json:"reviewerId" binding:"required"`
+}
+
+// AssignAppeal hands a pending appeal to a moderator allowed to review it
+func (h *AppealHandler) AssignAppeal(c *gin.Context) {
+	appealID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid appeal ID"})
+		return
+	}
+
+	var req AssignAppealRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	appeal, err := h.appealService.AssignAppeal(uint(appealID), req.ReviewerID)
+	if err != nil {
+		respondAppealError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, appeal)
+}
+
+// ReviewAppealRequest represents a reviewer's decision on an appeal
+type ReviewAppealRequest struct {
+	Outcome     string `json:"outcome" binding:"required,oneof=upheld reduced overturned"`
+	Response    string `json:"response"`
+	ReducedDays *int   `json:"reducedDays"`
+}
+
+// ReviewAppeal upholds, reduces or overturns the decision an appeal is about
+func (h *AppealHandler) ReviewAppeal(c *gin.Context) {
+	appealID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid appeal ID"})
+		return
+	}
+
+	var req ReviewAppealRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Get user ID from context (set by auth middleware)
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	appeal, err := h.appealService.ReviewAppeal(uint(appealID), userID.(uint), models.AppealStatus(req.Outcome), req.Response, req.ReducedDays)
+	if err != nil {
+		respondAppealError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, appeal)
+}
+
+// GetAppealStats summarises appeals filed in the last days, 30 by default
+func (h *AppealHandler) GetAppealStats(c *gin.Context) {
+	days, err := strconv.Atoi(c.DefaultQuery("days", "30"))
+	if err != nil || days < 1 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid days"})
+		return
+	}
+
+	stats, err := h.appealService.GetAppealStats(time.Now().AddDate(0, 0, -days))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, stats)
+}
+
+// respondAppealError maps errors from filing and reviewing appeals to responses
+func respondAppealError(c *gin.Context, err error) {
+	if v, ok := err.(*errors.ValidationErrors); ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": v.Error(), "validation": v.Errors})
+		return
+	}
+
+	switch err {
+	case models.ErrPermissionDenied, models.ErrNotAppealReviewer:
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error(), "code": err.(models.DiscussionError).Code})
+	case models.ErrNotAppealable, models.ErrAppealWindowClosed, models.ErrAppealExists, models.ErrAppealResolved:
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error(), "code": err.(models.DiscussionError).Code})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
@@ -5,6 +5,7 @@ import (
         "strconv"
 
         "github.com/gin-gonic/gin"
+        "github.com/yerenwgventures/GreatNigeriaLibrary-Foundation/backend/pkg/common/errors"
         "github.com/yerenwgventures/GreatNigeriaLibrary-Foundation/backend/services/discussion/models"
         "github.com/yerenwgventures/GreatNigeriaLibrary-Foundation/backend/services/discussion/service"
 )
@@ -28,6 +29,24 @@ func (h *DiscussionHandler) WithPointsIntegration(pointsIntegration *ForumPoints
         return h
 }
 
+// postingError responds to a topic or comment the posting rules refused.
+// It reports false for other errors.
+func postingError(c *gin.Context, err error) bool {
+        if v, ok := err.(*errors.ValidationErrors); ok {
+                c.JSON(http.StatusBadRequest, gin.H{"error": v.Error(), "validation": v.Errors})
+                return true
+        }
+        if e, ok := err.(models.DiscussionError); ok {
+                if e.Code == models.ErrUserBanned.Code || e.Code == models.ErrPostingRestricted.Code {
+                        c.JSON(http.StatusForbidden, gin.H{"error": e.Error(), "code": e.Code})
+                } else {
+                        c.JSON(http.StatusBadRequest, gin.H{"error": e.Error()})
+                }
+                return true
+        }
+        return false
+}
+
 // RegisterRoutes registers all discussion routes
 func (h *DiscussionHandler) RegisterRoutes(router *gin.Engine, authMiddleware gin.HandlerFunc, adminMiddleware gin.HandlerFunc) {
         // Public routes (no authentication required)
@@ -484,12 +503,15 @@ func (h *DiscussionHandler) CreateTopic(c *gin.Context) {
                 request.TagIDs,
         )
         if err != nil {
-                c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create topic"})
+                if !postingError(c, err) {
+                        c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create topic"})
+                }
                 return
         }
 
-        // Award points for creating a topic if points integration is enabled
-        if h.pointsIntegration != nil {
+        // Award points for creating a topic if points integration is enabled.
+        // Topics held for approval earn nothing until published.
+        if h.pointsIntegration != nil && topic.IsApproved {
                 // Get category slug for more detailed points records
                 categorySlug := ""
                 category, err := h.discussionService.GetCategoryByID(request.CategoryID)
@@ -791,16 +813,15 @@ func (h *DiscussionHandler) CreateComment(c *gin.Context) {
                 request.ParentID,
         )
         if err != nil {
-                if _, ok := err.(models.DiscussionError); ok {
-                        c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
-                } else {
+                if !postingError(c, err) {
                         c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create comment"})
                 }
                 return
         }
 
-        // Award points for creating a comment/reply if points integration is enabled
-        if h.pointsIntegration != nil {
+        // Award points for creating a comment/reply if points integration is enabled.
+        // Comments held for approval earn nothing until published.
+        if h.pointsIntegration != nil && comment.IsApproved {
                 // Determine quality level of the content
                 quality := h.pointsIntegration.DetermineContentQuality(request.Content)
                 
@@ -1045,7 +1066,7 @@ func (h *DiscussionHandler) AddTopicReaction(c *gin.Context) {
                 topic, err := h.discussionService.GetTopicByID(uint(id))
                 if err == nil && topic != nil {
                         // Award points to the topic creator for receiving an upvote
-                        h.pointsIntegration.AwardPointsForUpvotes(topic.UserID, uint(id), 0, 1)
+                        h.pointsIntegration.AwardPointsForUpvote(topic.UserID, userID.(uint), uint(id), 0)
                 }
         }
 
@@ -1133,7 +1154,7 @@ func (h *DiscussionHandler) AddCommentReaction(c *gin.Context) {
                         topicID := comment.TopicID
 
                         // Award points to the comment creator for receiving an upvote
-                        h.pointsIntegration.AwardPointsForUpvotes(comment.UserID, topicID, uint(id), 1)
+                        h.pointsIntegration.AwardPointsForUpvote(comment.UserID, userID.(uint), topicID, uint(id))
                 }
         }
 
@@ -5,6 +5,7 @@ import (
 	"strconv"
 
 	"github.com/gin-gonic/gin"
+	"github.com/yerenwgventures/GreatNigeriaLibrary-Foundation/backend/pkg/common/audit"
 	"github.com/yerenwgventures/GreatNigeriaLibrary-Foundation/backend/services/discussion/models"
 	"github.com/yerenwgventures/GreatNigeriaLibrary-Foundation/backend/services/discussion/service"
 )
@@ -21,13 +22,22 @@ func NewFlagHandler(flagService service.FlagService) *FlagHandler {
 	}
 }
 
-// RegisterRoutes registers the routes for content flags
+// RegisterRoutes registers the route for users to flag content
 func (h *FlagHandler) RegisterRoutes(router *gin.RouterGroup) {
 	moderation := router.Group("/moderation")
 	{
-		// Content flagging
 		moderation.POST("/flags", h.FlagContent)
+	}
+}
+
+// RegisterModerationRoutes registers the routes for reviewing flags and
+// penalising users. They must be behind the moderator role.
+func (h *FlagHandler) RegisterModerationRoutes(router *gin.RouterGroup) {
+	moderation := router.Group("/moderation")
+	{
+		// Content flags
 		moderation.GET("/flags/content/:type/:id", h.GetFlagsByContent)
+		moderation.GET("/flags/content/:type/:id/aggregate", h.GetFlagAggregate)
 		moderation.GET("/flags", h.GetFlagsByStatus)
 		moderation.GET("/flags/:id", h.GetFlagByID)
 		moderation.PUT("/flags/:id/review", h.ReviewFlag)
@@ -66,7 +76,7 @@ func (h *FlagHandler) FlagContent(c *gin.Context) {
 	}
 	
 	// Get user ID from context (set by auth middleware)
-	userID, exists := c.Get("userID")
+	userID, exists := c.Get("user_id")
 	if !exists {
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
 		return
@@ -144,6 +154,30 @@ func (h *FlagHandler) GetFlagsByContent(c *gin.Context) {
 	c.JSON(http.StatusOK, flags)
 }
 
+// GetFlagAggregate retrieves the combined weight of the flags on a content item
+func (h *FlagHandler) GetFlagAggregate(c *gin.Context) {
+	// Get content type and ID from path
+	contentType := c.Param("type")
+	if contentType != "topic" && contentType != "comment" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid content type"})
+		return
+	}
+	
+	contentID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid content ID"})
+		return
+	}
+	
+	aggregate, err := h.flagService.GetFlagAggregate(contentType, uint(contentID))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	
+	c.JSON(http.StatusOK, aggregate)
+}
+
 // GetFlagsByStatus retrieves flags by status
 func (h *FlagHandler) GetFlagsByStatus(c *gin.Context) {
 	// Get status from query
@@ -230,7 +264,7 @@ func (h *FlagHandler) ReviewFlag(c *gin.Context) {
 	}
 	
 	// Get user ID from context (set by auth middleware)
-	userID, exists := c.Get("userID")
+	userID, exists := c.Get("user_id")
 	if !exists {
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
 		return
@@ -281,7 +315,7 @@ func (h *FlagHandler) AssignFlag(c *gin.Context) {
 	}
 	
 	// Get user ID from context (set by auth middleware)
-	userID, exists := c.Get("userID")
+	userID, exists := c.Get("user_id")
 	if !exists {
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
 		return
@@ -314,7 +348,7 @@ func (h *FlagHandler) CreateOrUpdateModerationStatus(c *gin.Context) {
 	}
 	
 	// Get user ID from context (set by auth middleware)
-	userID, exists := c.Get("userID")
+	userID, exists := c.Get("user_id")
 	if !exists {
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
 		return
@@ -443,7 +477,7 @@ func (h *FlagHandler) ApplyUserPenalty(c *gin.Context) {
 	}
 	
 	// Get user ID from context (set by auth middleware)
-	userID, exists := c.Get("userID")
+	userID, exists := c.Get("user_id")
 	if !exists {
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
 		return
@@ -472,6 +506,7 @@ func (h *FlagHandler) ApplyUserPenalty(c *gin.Context) {
 	
 	// Apply penalty
 	penalty, err := h.flagService.ApplyUserPenalty(
+		audit.RequestContext(c),
 		req.UserID,
 		penaltyType,
 		req.Reason,
@@ -553,7 +588,7 @@ func (h *FlagHandler) RemovePenalty(c *gin.Context) {
 	}
 	
 	// Get user ID from context (set by auth middleware)
-	userID, exists := c.Get("userID")
+	userID, exists := c.Get("user_id")
 	if !exists {
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
 		return
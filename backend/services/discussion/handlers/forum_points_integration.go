@@ -1,20 +1,22 @@
 package handlers
 
 import (
-        "bytes"
-        "encoding/json"
+        "errors"
         "fmt"
-        "net/http"
 
         "github.com/yerenwgventures/GreatNigeriaLibrary-Foundation/backend/services/discussion/service"
+        pointsmodels "github.com/yerenwgventures/GreatNigeriaLibrary-Foundation/backend/services/points/models"
+        pointsservice "github.com/yerenwgventures/GreatNigeriaLibrary-Foundation/backend/services/points/service"
         "github.com/sirupsen/logrus"
 )
 
-// ForumPointsIntegration provides an interface to award points for forum activities
+// ForumPointsIntegration awards points for forum activities through the
+// points ledger. Every award carries an idempotency key, so retried requests
+// and repeated reactions never award twice.
 type ForumPointsIntegration struct {
         discussionService service.DiscussionService
         logger            *logrus.Logger
-        pointsAPIURL      string
+        points            pointsservice.Awarder
         enabled           bool
 }
 
@@ -29,14 +31,14 @@ const (
 func NewForumPointsIntegration(
         discussionService service.DiscussionService,
         logger *logrus.Logger,
-        pointsAPIURL string,
+        points pointsservice.Awarder,
         enabled bool,
 ) *ForumPointsIntegration {
         return &ForumPointsIntegration{
                 discussionService: discussionService,
                 logger:            logger,
-                pointsAPIURL:      pointsAPIURL,
-                enabled:           enabled,
+                points:            points,
+                enabled:           enabled && points != nil,
         }
 }
 
@@ -47,15 +49,13 @@ func (p *ForumPointsIntegration) AwardPointsForNewTopic(userID uint, topicID uin
                 return nil
         }
 
-        // Prepare request to points service
-        reqBody := map[string]interface{}{
-                "user_id":  userID,
-                "topic_id": topicID,
-                "category": category,
-                "quality":  quality,
-        }
-
-        return p.sendPointsRequest("/api/points/discussion/topic", reqBody)
+        return p.award(pointsservice.AwardRequest{
+                UserID:        userID,
+                Action:        pointsmodels.ActionTopicCreated,
+                ReferenceType: "topic",
+                ReferenceID:   topicID,
+                Description:   fmt.Sprintf("Started a %s quality topic in %s", quality, category),
+        })
 }
 
 // AwardPointsForReply awards points when a user creates a reply
@@ -65,34 +65,44 @@ func (p *ForumPointsIntegration) AwardPointsForReply(userID uint, topicID uint,
                 return nil
         }
 
-        // Prepare request to points service
-        reqBody := map[string]interface{}{
-                "user_id":    userID,
-                "topic_id":   topicID,
-                "comment_id": commentID,
-                "is_reply":   isReply,
-                "quality":    quality,
+        description := fmt.Sprintf("Commented on topic %d", topicID)
+        if isReply {
+                description = fmt.Sprintf("Replied to a comment on topic %d", topicID)
         }
 
-        return p.sendPointsRequest("/api/points/discussion/reply", reqBody)
+        return p.award(pointsservice.AwardRequest{
+                UserID:        userID,
+                Action:        pointsmodels.ActionCommentCreated,
+                ReferenceType: "comment",
+                ReferenceID:   commentID,
+                Description:   description,
+        })
 }
 
-// AwardPointsForUpvotes awards points when a user receives upvotes
-func (p *ForumPointsIntegration) AwardPointsForUpvotes(userID uint, topicID uint, commentID uint, count int) error {
+// AwardPointsForUpvote awards points to an author when another user upvotes
+// their topic or comment. Each voter rewards a given post once, so removing
+// and re-adding a reaction earns nothing.
+func (p *ForumPointsIntegration) AwardPointsForUpvote(authorID, voterID, topicID, commentID uint) error {
         if !p.enabled {
-                p.logger.Info("Points integration is disabled, not awarding points for upvotes")
+                p.logger.Info("Points integration is disabled, not awarding points for upvote")
+                return nil
+        }
+        if authorID == voterID {
                 return nil
         }
 
-        // Prepare request to points service
-        reqBody := map[string]interface{}{
-                "user_id":    userID,
-                "topic_id":   topicID,
-                "comment_id": commentID,
-                "count":      count,
+        referenceType, referenceID := "topic", topicID
+        if commentID != 0 {
+                referenceType, referenceID = "comment", commentID
         }
 
-        return p.sendPointsRequest("/api/points/discussion/upvote", reqBody)
+        return p.award(pointsservice.AwardRequest{
+                UserID:         authorID,
+                Action:         pointsmodels.ActionUpvoteReceived,
+                ReferenceType:  referenceType,
+                ReferenceID:    referenceID,
+                IdempotencyKey: fmt.Sprintf("%s:%s:%d:%d", pointsmodels.ActionUpvoteReceived, referenceType, referenceID, voterID),
+        })
 }
 
 // AwardPointsForFeaturedTopic awards points when a user's topic is featured
@@ -102,66 +112,38 @@ func (p *ForumPointsIntegration) AwardPointsForFeaturedTopic(userID uint, topicI
                 return nil
         }
 
-        // Prepare request to points service
-        reqBody := map[string]interface{}{
-                "user_id":  userID,
-                "topic_id": topicID,
-                "category": category,
-        }
-
-        return p.sendPointsRequest("/api/points/discussion/featured", reqBody)
+        return p.award(pointsservice.AwardRequest{
+                UserID:        userID,
+                Action:        pointsmodels.ActionTopicFeatured,
+                ReferenceType: "topic",
+                ReferenceID:   topicID,
+        })
 }
 
-// Helper method to send requests to the points service
-func (p *ForumPointsIntegration) sendPointsRequest(endpoint string, data map[string]interface{}) error {
-        jsonData, err := json.Marshal(data)
-        if err != nil {
-                p.logger.WithError(err).Error("Failed to marshal points request data")
-                return err
-        }
-
-        url := p.pointsAPIURL + endpoint
-        p.logger.WithFields(logrus.Fields{
-                "url":  url,
-                "data": string(jsonData),
-        }).Debug("Sending points request")
-
-        // Create and send the HTTP request
-        req, err := http.NewRequest("POST", url, bytes.NewBuffer(jsonData))
-        if err != nil {
-                p.logger.WithError(err).Error("Failed to create points request")
-                return err
-        }
-
-        req.Header.Set("Content-Type", "application/json")
-
-        client := &http.Client{}
-        resp, err := client.Do(req)
-        if err != nil {
-                p.logger.WithError(err).Error("Failed to send points request")
-                return err
-        }
-        defer resp.Body.Close()
-
-        // Check response status
-        if resp.StatusCode != http.StatusOK {
+// award records an award in the points ledger. Reaching a daily cap or a
+// disabled rule is expected and not reported as an error.
+func (p *ForumPointsIntegration) award(req pointsservice.AwardRequest) error {
+        transaction, err := p.points.Award(req)
+        if errors.Is(err, pointsmodels.ErrDailyCapReached) || errors.Is(err, pointsmodels.ErrRuleDisabled) {
                 p.logger.WithFields(logrus.Fields{
-                        "status": resp.StatusCode,
-                        "url":    url,
-                }).Error("Points service returned non-OK status")
-                return fmt.Errorf("points service returned status %d", resp.StatusCode)
+                        "user_id": req.UserID,
+                        "action":  req.Action,
+                }).Debug("No points awarded: " + err.Error())
+                return nil
         }
-
-        var respData map[string]interface{}
-        if err := json.NewDecoder(resp.Body).Decode(&respData); err != nil {
-                p.logger.WithError(err).Error("Failed to decode points response")
+        if err != nil {
+                p.logger.WithError(err).WithFields(logrus.Fields{
+                        "user_id": req.UserID,
+                        "action":  req.Action,
+                }).Error("Failed to award points")
                 return err
         }
 
         p.logger.WithFields(logrus.Fields{
-                "response": respData,
-                "endpoint": endpoint,
-        }).Debug("Points request successful")
+                "user_id": req.UserID,
+                "action":  req.Action,
+                "points":  transaction.Points,
+        }).Debug("Points awarded")
 
         return nil
 }
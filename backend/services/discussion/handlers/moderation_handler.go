@@ -3,8 +3,10 @@ package handlers
 import (
 	"net/http"
 	"strconv"
+	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/yerenwgventures/GreatNigeriaLibrary-Foundation/backend/pkg/common/audit"
 	"github.com/yerenwgventures/GreatNigeriaLibrary-Foundation/backend/services/discussion/models"
 	"github.com/yerenwgventures/GreatNigeriaLibrary-Foundation/backend/services/discussion/service"
 )
@@ -12,6 +14,8 @@ import (
 // ModerationHandler defines the handler for moderation endpoints
 type ModerationHandler struct {
 	moderationService service.ModerationService
+	spamService       service.SpamService
+	appealService     service.AppealService
 }
 
 // NewModerationHandler creates a new moderation handler
@@ -21,6 +25,19 @@ func NewModerationHandler(moderationService service.ModerationService) *Moderati
 	}
 }
 
+// WithSpamService adds the spam classifier routes. Call it before RegisterRoutes.
+func (h *ModerationHandler) WithSpamService(spamService service.SpamService) *ModerationHandler {
+	h.spamService = spamService
+	return h
+}
+
+// WithAppealService adds the moderation dashboard, which reports the queue and
+// appeals together. Call it before RegisterRoutes.
+func (h *ModerationHandler) WithAppealService(appealService service.AppealService) *ModerationHandler {
+	h.appealService = appealService
+	return h
+}
+
 // RegisterRoutes registers the routes for moderation
 func (h *ModerationHandler) RegisterRoutes(router *gin.RouterGroup) {
 	moderation := router.Group("/moderation")
@@ -69,6 +86,17 @@ func (h *ModerationHandler) RegisterRoutes(router *gin.RouterGroup) {
 		moderation.PUT("/prohibited-words/:id", h.UpdateProhibitedWord)
 		moderation.DELETE("/prohibited-words/:id", h.DeleteProhibitedWord)
 		moderation.POST("/filter-text", h.FilterTextWithProhibitedWords)
+		
+		// Spam classifier
+		if h.spamService != nil {
+			moderation.GET("/classification/:type/:id", h.GetClassification)
+			moderation.POST("/classifier/retrain", h.RetrainClassifier)
+		}
+		
+		// Dashboard
+		if h.appealService != nil {
+			moderation.GET("/dashboard", h.GetModerationDashboard)
+		}
 	}
 }
 
@@ -92,7 +120,7 @@ func (h *ModerationHandler) CreateModerationRule(c *gin.Context) {
 	}
 	
 	// Check authentication
-	userID, exists := c.Get("userID")
+	userID, exists := c.Get("user_id")
 	if !exists {
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
 		return
@@ -190,7 +218,7 @@ func (h *ModerationHandler) UpdateModerationRule(c *gin.Context) {
 	}
 	
 	// Check authentication
-	userID, exists := c.Get("userID")
+	userID, exists := c.Get("user_id")
 	if !exists {
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
 		return
@@ -253,7 +281,7 @@ func (h *ModerationHandler) DeleteModerationRule(c *gin.Context) {
 	}
 	
 	// Check authentication
-	userID, exists := c.Get("userID")
+	userID, exists := c.Get("user_id")
 	if !exists {
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
 		return
@@ -283,7 +311,7 @@ func (h *ModerationHandler) FilterContent(c *gin.Context) {
 	}
 	
 	// Check authentication
-	userID, exists := c.Get("userID")
+	userID, exists := c.Get("user_id")
 	if !exists {
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
 		return
@@ -354,7 +382,7 @@ func (h *ModerationHandler) ReviewFilterResult(c *gin.Context) {
 	}
 	
 	// Check authentication
-	userID, exists := c.Get("userID")
+	userID, exists := c.Get("user_id")
 	if !exists {
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
 		return
@@ -435,7 +463,7 @@ func (h *ModerationHandler) AddToModerationQueue(c *gin.Context) {
 	}
 	
 	// Check authentication
-	userID, exists := c.Get("userID")
+	userID, exists := c.Get("user_id")
 	if !exists {
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
 		return
@@ -505,7 +533,7 @@ func (h *ModerationHandler) AssignModerationItem(c *gin.Context) {
 	}
 	
 	// Check authentication
-	userID, exists := c.Get("userID")
+	userID, exists := c.Get("user_id")
 	if !exists {
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
 		return
@@ -543,7 +571,7 @@ func (h *ModerationHandler) ResolveModerationItem(c *gin.Context) {
 	}
 	
 	// Check authentication
-	userID, exists := c.Get("userID")
+	userID, exists := c.Get("user_id")
 	if !exists {
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
 		return
@@ -614,7 +642,7 @@ func (h *ModerationHandler) UpdateUserTrustScore(c *gin.Context) {
 	}
 	
 	// Check authentication
-	moderatorID, exists := c.Get("userID")
+	moderatorID, exists := c.Get("user_id")
 	if !exists {
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
 		return
@@ -692,7 +720,7 @@ func (h *ModerationHandler) GrantModeratorPrivileges(c *gin.Context) {
 	}
 	
 	// Check authentication
-	grantedByID, exists := c.Get("userID")
+	grantedByID, exists := c.Get("user_id")
 	if !exists {
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
 		return
@@ -711,7 +739,7 @@ func (h *ModerationHandler) GrantModeratorPrivileges(c *gin.Context) {
 	}
 	
 	// Grant moderator privileges
-	moderator, err := h.moderationService.GrantModeratorPrivileges(req.UserID, grantedByID.(uint), privileges)
+	moderator, err := h.moderationService.GrantModeratorPrivileges(audit.RequestContext(c), req.UserID, grantedByID.(uint), privileges)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -749,7 +777,7 @@ func (h *ModerationHandler) UpdateModeratorPrivileges(c *gin.Context) {
 	}
 	
 	// Check authentication
-	updatedByID, exists := c.Get("userID")
+	updatedByID, exists := c.Get("user_id")
 	if !exists {
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
 		return
@@ -788,7 +816,7 @@ func (h *ModerationHandler) RevokeModeratorPrivileges(c *gin.Context) {
 	}
 	
 	// Check authentication
-	revokedByID, exists := c.Get("userID")
+	revokedByID, exists := c.Get("user_id")
 	if !exists {
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
 		return
@@ -855,7 +883,7 @@ func (h *ModerationHandler) CreateUserModerationAction(c *gin.Context) {
 	}
 	
 	// Check authentication
-	moderatorID, exists := c.Get("userID")
+	moderatorID, exists := c.Get("user_id")
 	if !exists {
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
 		return
@@ -942,7 +970,7 @@ func (h *ModerationHandler) RevokeUserModerationAction(c *gin.Context) {
 	}
 	
 	// Check authentication
-	moderatorID, exists := c.Get("userID")
+	moderatorID, exists := c.Get("user_id")
 	if !exists {
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
 		return
@@ -1015,7 +1043,7 @@ func (h *ModerationHandler) AddProhibitedWord(c *gin.Context) {
 	}
 	
 	// Check authentication
-	userID, exists := c.Get("userID")
+	userID, exists := c.Get("user_id")
 	if !exists {
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
 		return
@@ -1085,7 +1113,7 @@ func (h *ModerationHandler) UpdateProhibitedWord(c *gin.Context) {
 	}
 	
 	// Check authentication
-	userID, exists := c.Get("userID")
+	userID, exists := c.Get("user_id")
 	if !exists {
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
 		return
@@ -1122,7 +1150,7 @@ func (h *ModerationHandler) DeleteProhibitedWord(c *gin.Context) {
 	}
 	
 	// Check authentication
-	userID, exists := c.Get("userID")
+	userID, exists := c.Get("user_id")
 	if !exists {
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
 		return
@@ -1163,4 +1191,64 @@ func (h *ModerationHandler) FilterTextWithProhibitedWords(c *gin.Context) {
 		"wasFiltered":   wasFiltered,
 		"filteredWords": filteredWords,
 	})
-}
\ No newline at end of file
+}
+// GetClassification returns the spam and abuse scores a post was given when it was posted
+func (h *ModerationHandler) GetClassification(c *gin.Context) {
+	// Get content type and ID from URL
+	contentType := c.Param("type")
+	contentID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid content ID"})
+		return
+	}
+	
+	classification, err := h.spamService.GetClassification(contentType, uint(contentID))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Post has not been classified"})
+		return
+	}
+	
+	c.JSON(http.StatusOK, classification)
+}
+
+// RetrainClassifier retrains the spam classifier now rather than at its next scheduled run
+func (h *ModerationHandler) RetrainClassifier(c *gin.Context) {
+	model, err := h.spamService.Retrain()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if model == nil {
+		c.JSON(http.StatusConflict, gin.H{"error": "Too few labelled posts to train on"})
+		return
+	}
+	
+	c.JSON(http.StatusOK, model)
+}
+
+// GetModerationDashboard reports the moderation queue alongside appeals filed
+// in the last days, 30 by default, and those still awaiting review
+func (h *ModerationHandler) GetModerationDashboard(c *gin.Context) {
+	days, err := strconv.Atoi(c.DefaultQuery("days", "30"))
+	if err != nil || days < 1 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid days"})
+		return
+	}
+	
+	queueStats, err := h.moderationService.GetModerationQueueStats()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	
+	appealStats, err := h.appealService.GetAppealStats(time.Now().AddDate(0, 0, -days))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	
+	c.JSON(http.StatusOK, gin.H{
+		"queue":   queueStats,
+		"appeals": appealStats,
+	})
+}
@@ -39,21 +39,24 @@ func (h *ReportHandler) RegisterRoutes(router *gin.RouterGroup) {
 		reports.POST("/:id/comments", h.AddComment)
 		reports.GET("/:id/comments", h.GetComments)
 		reports.DELETE("/comments/:commentId", h.DeleteComment)
-		
-		// Moderation endpoints (would be protected by moderator role)
-		moderator := reports.Group("/moderation")
-		{
-			moderator.GET("/pending", h.GetPendingReports)
-			moderator.GET("/in-review", h.GetInReviewReports)
-			moderator.GET("/resolved", h.GetResolvedReports)
-			moderator.GET("/rejected", h.GetRejectedReports)
-			moderator.GET("/category/:category", h.GetReportsByCategory)
-			moderator.GET("/stats", h.GetReportStats)
-			moderator.POST("/:id/assign", h.AssignReport)
-			moderator.POST("/:id/status", h.UpdateReportStatus)
-			moderator.POST("/:id/resolve", h.ResolveReport)
-			moderator.GET("/:id/logs", h.GetActionLogs)
-		}
+	}
+}
+
+// RegisterModerationRoutes registers the routes for reviewing reports. They
+// must be behind the moderator role.
+func (h *ReportHandler) RegisterModerationRoutes(router *gin.RouterGroup) {
+	moderator := router.Group("/reports/moderation")
+	{
+		moderator.GET("/pending", h.GetPendingReports)
+		moderator.GET("/in-review", h.GetInReviewReports)
+		moderator.GET("/resolved", h.GetResolvedReports)
+		moderator.GET("/rejected", h.GetRejectedReports)
+		moderator.GET("/category/:category", h.GetReportsByCategory)
+		moderator.GET("/stats", h.GetReportStats)
+		moderator.POST("/:id/assign", h.AssignReport)
+		moderator.POST("/:id/status", h.UpdateReportStatus)
+		moderator.POST("/:id/resolve", h.ResolveReport)
+		moderator.GET("/:id/logs", h.GetActionLogs)
 	}
 }
 
@@ -75,7 +78,7 @@ func (h *ReportHandler) CreateReport(c *gin.Context) {
 	}
 	
 	// Get user ID from context (set by auth middleware)
-	userID, exists := c.Get("userID")
+	userID, exists := c.Get("user_id")
 	if !exists {
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
 		return
@@ -134,7 +137,7 @@ func (h *ReportHandler) CreateReport(c *gin.Context) {
 // GetMyReports retrieves reports created by the authenticated user
 func (h *ReportHandler) GetMyReports(c *gin.Context) {
 	// Get user ID from context (set by auth middleware)
-	userID, exists := c.Get("userID")
+	userID, exists := c.Get("user_id")
 	if !exists {
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
 		return
@@ -172,7 +175,7 @@ func (h *ReportHandler) GetReport(c *gin.Context) {
 	}
 	
 	// Get user ID from context (set by auth middleware)
-	userID, exists := c.Get("userID")
+	userID, exists := c.Get("user_id")
 	if !exists {
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
 		return
@@ -220,7 +223,7 @@ func (h *ReportHandler) AddEvidence(c *gin.Context) {
 	}
 	
 	// Get user ID from context (set by auth middleware)
-	userID, exists := c.Get("userID")
+	userID, exists := c.Get("user_id")
 	if !exists {
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
 		return
@@ -255,7 +258,7 @@ func (h *ReportHandler) GetEvidence(c *gin.Context) {
 	}
 	
 	// Get user ID from context (set by auth middleware)
-	userID, exists := c.Get("userID")
+	userID, exists := c.Get("user_id")
 	if !exists {
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
 		return
@@ -295,7 +298,7 @@ func (h *ReportHandler) DeleteEvidence(c *gin.Context) {
 	}
 	
 	// Get user ID from context (set by auth middleware)
-	userID, exists := c.Get("userID")
+	userID, exists := c.Get("user_id")
 	if !exists {
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
 		return
@@ -333,7 +336,7 @@ func (h *ReportHandler) AddComment(c *gin.Context) {
 	}
 	
 	// Get user ID from context (set by auth middleware)
-	userID, exists := c.Get("userID")
+	userID, exists := c.Get("user_id")
 	if !exists {
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
 		return
@@ -366,7 +369,7 @@ func (h *ReportHandler) GetComments(c *gin.Context) {
 	}
 	
 	// Get user ID from context (set by auth middleware)
-	userID, exists := c.Get("userID")
+	userID, exists := c.Get("user_id")
 	if !exists {
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
 		return
@@ -397,7 +400,7 @@ func (h *ReportHandler) DeleteComment(c *gin.Context) {
 	}
 	
 	// Get user ID from context (set by auth middleware)
-	userID, exists := c.Get("userID")
+	userID, exists := c.Get("user_id")
 	if !exists {
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
 		return
@@ -611,7 +614,7 @@ func (h *ReportHandler) AssignReport(c *gin.Context) {
 	}
 	
 	// Get user ID from context (set by auth middleware)
-	userID, exists := c.Get("userID")
+	userID, exists := c.Get("user_id")
 	if !exists {
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
 		return
@@ -664,7 +667,7 @@ func (h *ReportHandler) UpdateReportStatus(c *gin.Context) {
 	}
 	
 	// Get user ID from context (set by auth middleware)
-	userID, exists := c.Get("userID")
+	userID, exists := c.Get("user_id")
 	if !exists {
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
 		return
@@ -722,7 +725,7 @@ func (h *ReportHandler) ResolveReport(c *gin.Context) {
 	}
 	
 	// Get user ID from context (set by auth middleware)
-	userID, exists := c.Get("userID")
+	userID, exists := c.Get("user_id")
 	if !exists {
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
 		return
@@ -159,7 +159,7 @@ func (h *RichTextHandler) CreateAttachment(c *gin.Context) {
         }
         
         // Get user ID from context (set by auth middleware)
-        userID, exists := c.Get("userID")
+        userID, exists := c.Get("user_id")
         if !exists {
                 c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
                 return
@@ -180,6 +180,10 @@ func (h *RichTextHandler) CreateAttachment(c *gin.Context) {
                 req.Height,
         )
         
+        if err == models.ErrAttachmentsRestricted {
+                c.JSON(http.StatusForbidden, gin.H{"error": err.Error(), "code": models.ErrAttachmentsRestricted.Code})
+                return
+        }
         if err != nil {
                 c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
                 return
@@ -426,7 +430,7 @@ func (h *RichTextHandler) DeleteQuote(c *gin.Context) {
 // GetMentions retrieves mentions for the authenticated user
 func (h *RichTextHandler) GetMentions(c *gin.Context) {
         // Get user ID from context (set by auth middleware)
-        userID, exists := c.Get("userID")
+        userID, exists := c.Get("user_id")
         if !exists {
                 c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
                 return
@@ -3,6 +3,7 @@ package handlers
 import (
 	"net/http"
 	"strconv"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/yerenwgventures/GreatNigeriaLibrary-Foundation/backend/services/discussion/models"
@@ -316,6 +317,7 @@ type UpdatePreferencesRequest struct {
 	DefaultInAppEnabled    bool   `json:"defaultInAppEnabled"`
 	DigestDay              int    `json:"digestDay"`
 	DigestHour             int    `json:"digestHour"`
+	TimeZone               string `json:"timeZone"`
 	AutoSubscribeToReplies bool   `json:"autoSubscribeToReplies"`
 	AutoSubscribeToCreated bool   `json:"autoSubscribeToCreated"`
 }
@@ -361,6 +363,15 @@ func (h *SubscriptionHandler) UpdateUserPreferences(c *gin.Context) {
 		return
 	}
 	
+	// Validate time zone (IANA name, defaults to UTC)
+	if req.TimeZone == "" {
+		req.TimeZone = "UTC"
+	}
+	if _, err := time.LoadLocation(req.TimeZone); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid time zone"})
+		return
+	}
+	
 	// Create preference object
 	preference := models.SubscriptionPreference{
 		UserID:                 userID.(uint),
@@ -370,6 +381,7 @@ func (h *SubscriptionHandler) UpdateUserPreferences(c *gin.Context) {
 		DefaultInAppEnabled:    req.DefaultInAppEnabled,
 		DigestDay:              req.DigestDay,
 		DigestHour:             req.DigestHour,
+		TimeZone:               req.TimeZone,
 		AutoSubscribeToReplies: req.AutoSubscribeToReplies,
 		AutoSubscribeToCreated: req.AutoSubscribeToCreated,
 	}
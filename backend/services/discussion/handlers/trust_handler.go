@@ -0,0 +1,126 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/yerenwgventures/GreatNigeriaLibrary-Foundation/backend/pkg/common/errors"
+	"github.com/yerenwgventures/GreatNigeriaLibrary-Foundation/backend/services/discussion/models"
+	"github.com/yerenwgventures/GreatNigeriaLibrary-Foundation/backend/services/discussion/service"
+)
+
+// TrustHandler defines the handler for trust level endpoints
+type TrustHandler struct {
+	trustService service.TrustService
+}
+
+// NewTrustHandler creates a new trust handler
+func NewTrustHandler(trustService service.TrustService) *TrustHandler {
+	return &TrustHandler{
+		trustService: trustService,
+	}
+}
+
+// RegisterRoutes registers the route for users' own trust level
+func (h *TrustHandler) RegisterRoutes(router *gin.RouterGroup) {
+	router.GET("/trust/me", h.GetMyTrustLevel)
+}
+
+// RegisterModerationRoutes registers the routes for reviewing trust levels
+// and settings. They must be behind the moderator role.
+func (h *TrustHandler) RegisterModerationRoutes(router *gin.RouterGroup) {
+	moderation := router.Group("/moderation")
+	{
+		moderation.GET("/trust/:userId/explanation", h.GetTrustExplanation)
+		moderation.POST("/trust/:userId/recalculate", h.RecalculateTrustLevel)
+		moderation.GET("/trust-settings", h.GetTrustSettings)
+		moderation.PUT("/trust-settings", h.UpdateTrustSettings)
+	}
+}
+
+// GetMyTrustLevel explains the current user's trust level and what it allows
+func (h *TrustHandler) GetMyTrustLevel(c *gin.Context) {
+	// Get user ID from context (set by auth middleware)
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	explanation, err := h.trustService.Explain(userID.(uint))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, explanation)
+}
+
+// GetTrustExplanation explains a user's trust level to moderators
+func (h *TrustHandler) GetTrustExplanation(c *gin.Context) {
+	// Get user ID from URL
+	userID, err := strconv.ParseUint(c.Param("userId"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	explanation, err := h.trustService.Explain(uint(userID))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, explanation)
+}
+
+// RecalculateTrustLevel recalculates a user's trust level now rather than at the next scheduled run
+func (h *TrustHandler) RecalculateTrustLevel(c *gin.Context) {
+	// Get user ID from URL
+	userID, err := strconv.ParseUint(c.Param("userId"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	score, err := h.trustService.Recalculate(uint(userID))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, score)
+}
+
+// GetTrustSettings returns the thresholds and capabilities of the trust levels
+func (h *TrustHandler) GetTrustSettings(c *gin.Context) {
+	settings, err := h.trustService.GetSettings()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, settings)
+}
+
+// UpdateTrustSettings replaces the thresholds and capabilities of the trust levels
+func (h *TrustHandler) UpdateTrustSettings(c *gin.Context) {
+	var req models.TrustSettings
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	settings, err := h.trustService.UpdateSettings(&req)
+	if err != nil {
+		if v, ok := err.(*errors.ValidationErrors); ok {
+			c.JSON(http.StatusBadRequest, gin.H{"error": v.Error(), "validation": v.Errors})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, settings)
+}
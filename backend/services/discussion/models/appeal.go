@@ -0,0 +1,80 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// AppealDecisionType defines the kinds of moderation decisions users can appeal
+type AppealDecisionType string
+
+const (
+	// AppealPenalty for a UserPenalty
+	AppealPenalty AppealDecisionType = "penalty"
+
+	// AppealModerationAction for a UserModerationAction
+	AppealModerationAction AppealDecisionType = "moderation_action"
+
+	// AppealContent for a ContentModerationStatus that hid or rejected a post
+	AppealContent AppealDecisionType = "content"
+)
+
+// AppealStatus defines the possible statuses of an appeal
+type AppealStatus string
+
+const (
+	// AppealStatusPending for appeals awaiting review
+	AppealStatusPending AppealStatus = "pending"
+
+	// AppealStatusUpheld for appeals where the decision stands
+	AppealStatusUpheld AppealStatus = "upheld"
+
+	// AppealStatusReduced for appeals that shortened a penalty or ban
+	AppealStatusReduced AppealStatus = "reduced"
+
+	// AppealStatusOverturned for appeals that reversed the decision
+	AppealStatusOverturned AppealStatus = "overturned"
+)
+
+// Appeal is a user's request that a moderator other than the one who decided
+// reviews a penalty, moderation action or hidden post. Each decision may be
+// appealed once.
+type Appeal struct {
+	gorm.Model
+	DecisionType      AppealDecisionType `json:"decisionType" gorm:"uniqueIndex:idx_appeal_decision"`
+	DecisionID        uint               `json:"decisionId" gorm:"uniqueIndex:idx_appeal_decision"`
+	UserID            uint               `json:"userId" gorm:"index"`
+	ActingModeratorID uint               `json:"actingModeratorId"` // Moderator who made the decision, who may not review it
+	ReviewerID        *uint              `json:"reviewerId" gorm:"index"`
+	Reason            string             `json:"reason" gorm:"type:text"`
+	Status            AppealStatus       `json:"status" gorm:"default:pending"`
+	ReducedDays       *int               `json:"reducedDays"` // Days a reduced penalty or ban lasts from when it was applied
+	Response          string             `json:"response" gorm:"type:text"`
+	DueAt             time.Time          `json:"dueAt"` // When the review is due under the appeal SLA
+	AssignedAt        *time.Time         `json:"assignedAt"`
+	ResolvedAt        *time.Time         `json:"resolvedAt"`
+}
+
+// AppealStats summarise appeals for the moderation dashboard
+type AppealStats struct {
+	Pending              int64   `json:"pending"`
+	Unassigned           int64   `json:"unassigned"`
+	Overdue              int64   `json:"overdue"`
+	Upheld               int64   `json:"upheld"`
+	Reduced              int64   `json:"reduced"`
+	Overturned           int64   `json:"overturned"`
+	ResolvedWithinSLA    int64   `json:"resolvedWithinSla"`
+	AverageHoursToReview float64 `json:"averageHoursToReview"`
+	OverturnRate         float64 `json:"overturnRate"`  // Share of resolved appeals overturned
+	WithinSLARate        float64 `json:"withinSlaRate"` // Share of resolved appeals reviewed on time
+}
+
+// Errors returned when filing and reviewing appeals
+var (
+	ErrNotAppealable      = DiscussionError{Code: "not_appealable", Message: "This decision cannot be appealed"}
+	ErrAppealWindowClosed = DiscussionError{Code: "appeal_window_closed", Message: "The time to appeal this decision has passed"}
+	ErrAppealExists       = DiscussionError{Code: "appeal_exists", Message: "This decision has already been appealed"}
+	ErrAppealResolved     = DiscussionError{Code: "appeal_resolved", Message: "This appeal has already been reviewed"}
+	ErrNotAppealReviewer  = DiscussionError{Code: "not_appeal_reviewer", Message: "You may not review this appeal"}
+)
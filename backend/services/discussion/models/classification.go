@@ -0,0 +1,43 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Labels moderators' decisions give classified posts
+const (
+	LabelSpam = "spam"
+	LabelHam  = "ham"
+)
+
+// PostClassification is the spam and abuse score a topic or comment was given
+// when it was posted. Moderators' decisions about the post label it, and
+// labelled classifications are what the classifier is retrained on.
+type PostClassification struct {
+	gorm.Model
+	ContentType     string     `json:"contentType" gorm:"uniqueIndex:idx_post_classification_content"`
+	ContentID       uint       `json:"contentId" gorm:"uniqueIndex:idx_post_classification_content"`
+	UserID          uint       `json:"userId" gorm:"index"`
+	SpamScore       float64    `json:"spamScore"`
+	BayesScore      float64    `json:"bayesScore"`
+	HeuristicScore  float64    `json:"heuristicScore"`
+	NegativityScore float64    `json:"negativityScore"`
+	Signals         string     `json:"signals" gorm:"type:text"` // JSON array of heuristic signals
+	Features        string     `json:"-" gorm:"type:text"`       // JSON array of model features
+	ModelID         *uint      `json:"modelId"`                  // Nil when scored by heuristics alone
+	Label           string     `json:"label" gorm:"index"`       // LabelSpam, LabelHam or empty
+	LabeledBy       *uint      `json:"labeledBy"`
+	LabeledAt       *time.Time `json:"labeledAt"`
+}
+
+// ClassifierModel is a trained spam model. The latest one scores new posts.
+type ClassifierModel struct {
+	gorm.Model
+	Examples     int     `json:"examples"`
+	SpamExamples int     `json:"spamExamples"`
+	Precision    float64 `json:"precision"` // Cross-validated at the default threshold
+	Recall       float64 `json:"recall"`
+	Data         string  `json:"-" gorm:"type:text"` // JSON-encoded classifier.Model
+}
@@ -29,6 +29,7 @@ type Topic struct {
 	Category    Category  `json:"-" gorm:"foreignKey:CategoryID"`
 	IsPinned    bool      `json:"isPinned" gorm:"default:false"`
 	IsLocked    bool      `json:"isLocked" gorm:"default:false"`
+	IsApproved  bool      `json:"isApproved" gorm:"default:true"` // False while awaiting moderator approval
 	ViewCount   int       `json:"viewCount" gorm:"default:0"`
 	LastPostAt  time.Time `json:"lastPostAt"`
 	Comments    []Comment `json:"-" gorm:"foreignKey:TopicID"`
@@ -173,4 +174,6 @@ var (
 	ErrTopicLocked       = DiscussionError{Code: "topic_locked", Message: "Topic is locked"}
 	ErrInvalidContent    = DiscussionError{Code: "invalid_content", Message: "Invalid content"}
 	ErrDuplicateReaction = DiscussionError{Code: "duplicate_reaction", Message: "Duplicate reaction"}
+	ErrUserBanned        = DiscussionError{Code: "user_banned", Message: "User is banned"}
+	ErrPostingRestricted = DiscussionError{Code: "posting_restricted", Message: "User has posting restrictions"}
 )
\ No newline at end of file
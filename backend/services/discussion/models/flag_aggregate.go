@@ -0,0 +1,42 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// FlagAggregateStatus defines where flagged content stands
+type FlagAggregateStatus string
+
+const (
+	// FlagAggregateOpen for content whose flags have not hidden it
+	FlagAggregateOpen FlagAggregateStatus = "open"
+
+	// FlagAggregateHidden for content hidden by its flags until a moderator
+	// confirms or reverses them
+	FlagAggregateHidden FlagAggregateStatus = "hidden"
+
+	// FlagAggregateUpheld for hidden content a moderator agreed to remove
+	FlagAggregateUpheld FlagAggregateStatus = "upheld"
+
+	// FlagAggregateReversed for hidden content a moderator restored
+	FlagAggregateReversed FlagAggregateStatus = "reversed"
+)
+
+// FlagAggregate combines the flags on a topic or comment. Each flagger adds
+// the weight of their trust level and past accuracy once; content is hidden
+// when the weight reaches the trust settings' HideFlagWeight.
+type FlagAggregate struct {
+	gorm.Model
+	ContentType string              `json:"contentType" gorm:"uniqueIndex:idx_flag_aggregate_content"`
+	ContentID   uint                `json:"contentId" gorm:"uniqueIndex:idx_flag_aggregate_content"`
+	AuthorID    uint                `json:"authorId" gorm:"index"`
+	Weight      float64             `json:"weight"`
+	Flaggers    int                 `json:"flaggers"`
+	Status      FlagAggregateStatus `json:"status" gorm:"default:open"`
+	HiddenAt    *time.Time          `json:"hiddenAt"`
+	QueueItemID *uint               `json:"queueItemId"` // Moderation queue item that confirms or reverses the flags
+	ResolvedBy  *uint               `json:"resolvedBy"`
+	ResolvedAt  *time.Time          `json:"resolvedAt"`
+}
@@ -54,11 +54,14 @@ type AdvancedSubscription struct {
 // SubscriptionDigest represents a digest of updates for a subscription
 type SubscriptionDigest struct {
         gorm.Model
-        UserID          uint      `json:"userId" gorm:"index:idx_digest_user"`
-        FrequencyType   SubscriptionFrequency `json:"frequencyType"`
+        UserID          uint      `json:"userId" gorm:"index:idx_digest_user;uniqueIndex:idx_digest_period,priority:1"`
+        FrequencyType   SubscriptionFrequency `json:"frequencyType" gorm:"uniqueIndex:idx_digest_period,priority:2"`
+        PeriodKey       string    `json:"periodKey" gorm:"size:32;uniqueIndex:idx_digest_period,priority:3"` // e.g. daily:2024-05-01, one digest per user and period
         DigestContent   string    `json:"digestContent" gorm:"type:text"` // JSON content of the digest
-        DeliveryStatus  string    `json:"deliveryStatus"`                 // pending, sent, failed
+        DeliveryStatus  string    `json:"deliveryStatus"`                 // pending, sending, sent, failed
         ScheduledFor    time.Time `json:"scheduledFor"`
+        ClaimedAt       *time.Time `json:"claimedAt"`                     // When sending was last started
+        Attempts        int       `json:"attempts" gorm:"default:0"`
         SentAt          *time.Time `json:"sentAt"`
         ErrorMessage    string    `json:"errorMessage"`
         CreatedAt       time.Time `json:"createdAt"`
@@ -75,8 +78,19 @@ type SubscriptionPreference struct {
         DefaultInAppEnabled    bool                 `json:"defaultInAppEnabled" gorm:"default:true"`
         DigestDay              int                  `json:"digestDay"`              // 0-6 for weekly digests (Sunday-Saturday)
         DigestHour             int                  `json:"digestHour"`             // 0-23 for daily and weekly digests
+        TimeZone               string               `json:"timeZone" gorm:"size:64;default:UTC"` // IANA time zone DigestDay and DigestHour are in
         AutoSubscribeToReplies bool                 `json:"autoSubscribeToReplies" gorm:"default:true"`
         AutoSubscribeToCreated bool                 `json:"autoSubscribeToCreated" gorm:"default:true"`
         CreatedAt              time.Time            `json:"createdAt"`
         UpdatedAt              time.Time            `json:"updatedAt"`
-}
\ No newline at end of file
+}
+
+// TopicActivity summarizes what happened in a topic during a digest window
+type TopicActivity struct {
+        TopicID      uint      `json:"topicId"`
+        Title        string    `json:"title"`
+        IsNew        bool      `json:"isNew"` // Topic was created during the window
+        NewComments  int       `json:"newComments"`
+        NewReactions int       `json:"newReactions"`
+        LastActivity time.Time `json:"lastActivity"`
+}
@@ -0,0 +1,36 @@
+package models
+
+import (
+	"gorm.io/gorm"
+)
+
+// TrustSettings are the thresholds the trust engine promotes and demotes
+// users by, and what each trust level is allowed to do. There is at most one
+// row; without it the engine uses its defaults.
+type TrustSettings struct {
+	gorm.Model
+	BasicScore   float64 `json:"basicScore"` // Trust score that earns each level
+	MemberScore  float64 `json:"memberScore"`
+	RegularScore float64 `json:"regularScore"`
+	LeaderScore  float64 `json:"leaderScore"`
+	// Hysteresis is how far below a level's score a user may fall before
+	// losing it, so users near a threshold do not flap between levels
+	Hysteresis float64 `json:"hysteresis"`
+	// PenaltyWindowDays is how long warnings, penalties, rejected posts and
+	// dismissed flags count against a user
+	PenaltyWindowDays int            `json:"penaltyWindowDays"`
+	LinkLevel         UserTrustLevel `json:"linkLevel"`         // Lowest level that may post links
+	AttachmentLevel   UserTrustLevel `json:"attachmentLevel"`   // Lowest level that may upload attachments
+	NewUserFlagWeight float64        `json:"newUserFlagWeight"` // Weight of a flag raised at each level
+	BasicFlagWeight   float64        `json:"basicFlagWeight"`
+	MemberFlagWeight  float64        `json:"memberFlagWeight"`
+	RegularFlagWeight float64        `json:"regularFlagWeight"`
+	LeaderFlagWeight  float64        `json:"leaderFlagWeight"`
+	// HideFlagWeight is the combined weight of flags that hides a post until
+	// a moderator confirms or reverses the flags
+	HideFlagWeight float64 `json:"hideFlagWeight"`
+}
+
+// ErrAttachmentsRestricted is returned when a user's trust level does not
+// allow attachments yet
+var ErrAttachmentsRestricted = DiscussionError{Code: "attachments_restricted", Message: "Your trust level does not allow attachments yet"}
@@ -0,0 +1,255 @@
+// Package policy decides whether a topic or comment may be posted in a
+// category, following the category's CategoryConfig, PostingRules and
+// AutoModerationSettings.
+//
+// Rule violations are returned as validation errors and the post is refused.
+// Posts that break no rule may still have to be approved by a moderator
+// before they are shown, for instance in categories that require approval or
+// when they contain flagged keywords.
+package policy
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/yerenwgventures/GreatNigeriaLibrary-Foundation/backend/pkg/common/errors"
+	"github.com/yerenwgventures/GreatNigeriaLibrary-Foundation/backend/services/discussion/models"
+)
+
+// Kinds of post
+const (
+	KindTopic   = "topic"
+	KindComment = "comment"
+)
+
+// Post is a topic or comment about to be saved
+type Post struct {
+	Kind    string
+	Title   string // Topics only
+	Content string
+}
+
+// Author describes the user posting
+type Author struct {
+	TrustLevel models.UserTrustLevel
+	// LastPostAt is when the user last created a topic or comment, if ever
+	LastPostAt *time.Time
+	// Moderators are exempt from cooldowns and approval
+	Moderator bool
+	// NoLinks is set when the user's trust level may not post links yet
+	NoLinks bool
+}
+
+// Rules are the posting configuration of a category. Zero values impose no
+// limit, so a category without configuration accepts any post.
+type Rules struct {
+	Config  models.CategoryConfig
+	Posting models.PostingRules
+	AutoMod models.AutoModerationSettings
+}
+
+// TextFilters reports whether posts are checked against the moderation
+// rules. Categories without auto-moderation settings are always checked.
+func (r *Rules) TextFilters() bool {
+	return r.AutoMod.ID == 0 || (r.AutoMod.EnableAutoModeration && r.AutoMod.EnableTextFilters)
+}
+
+// Decision is the outcome of evaluating a post
+type Decision struct {
+	// Violations are the rules the post breaks; it must not be saved
+	Violations *errors.ValidationErrors
+	// Approval lists why the post must be approved before it is shown
+	Approval []string
+}
+
+// Allowed reports whether the post may be saved
+func (d *Decision) Allowed() bool {
+	return !d.Violations.HasErrors()
+}
+
+// NeedsApproval reports whether the post must be approved before it is shown
+func (d *Decision) NeedsApproval() bool {
+	return len(d.Approval) > 0
+}
+
+// Hold records a reason for holding the post for approval
+func (d *Decision) Hold(reason string) {
+	d.Approval = append(d.Approval, reason)
+}
+
+// trustRanks orders trust levels for CategoryConfig.MinimumUserLevel
+var trustRanks = map[models.UserTrustLevel]int{
+	models.TrustLevelNewUser: 0,
+	models.TrustLevelBasic:   1,
+	models.TrustLevelMember:  2,
+	models.TrustLevelRegular: 3,
+	models.TrustLevelLeader:  4,
+}
+
+// TrustRank returns the rank of a trust level, from 0 for new users to 4 for
+// leaders. Unknown levels rank as new users.
+func TrustRank(level models.UserTrustLevel) int {
+	return trustRanks[level]
+}
+
+// linkPattern matches the links counted against PostingRules.MaxLinksPerPost
+var linkPattern = regexp.MustCompile(`(?i)\b(?:https?://|www\.)[^\s<>"']+`)
+
+// CountLinks returns the number of links in text
+func CountLinks(text string) int {
+	return len(linkPattern.FindAllStringIndex(text, -1))
+}
+
+// splitList splits a comma-separated setting, dropping blank entries
+func splitList(list string) []string {
+	var items []string
+	for _, item := range strings.Split(list, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// MatchWords returns the words of a comma-separated list that appear in text
+// as whole words, ignoring case
+func MatchWords(text, list string) []string {
+	var matched []string
+	for _, word := range splitList(list) {
+		re, err := regexp.Compile(`(?i)(?:^|\W)` + regexp.QuoteMeta(word) + `(?:\W|$)`)
+		if err == nil && re.MatchString(text) {
+			matched = append(matched, word)
+		}
+	}
+	return matched
+}
+
+// checkLength adds a violation when text is shorter or longer than allowed
+func checkLength(violations *errors.ValidationErrors, field, text string, min, max int) {
+	length := utf8.RuneCountInString(strings.TrimSpace(text))
+	if min > 0 && length < min {
+		violations.Add(field, fmt.Sprintf("must be at least %d characters", min), "")
+	}
+	if max > 0 && length > max {
+		violations.Add(field, fmt.Sprintf("must be at most %d characters", max), "")
+	}
+}
+
+// autoApproved reports whether AutoModerationSettings.AutoApproveUsers
+// exempts an author from approval. Entries are trust levels, or "moderator".
+func autoApproved(settings models.AutoModerationSettings, author Author) bool {
+	for _, entry := range splitList(settings.AutoApproveUsers) {
+		if strings.EqualFold(entry, string(author.TrustLevel)) || (author.Moderator && strings.EqualFold(entry, "moderator")) {
+			return true
+		}
+	}
+	return false
+}
+
+// Evaluate checks a post against the rules of its category at time now
+func Evaluate(rules *Rules, post Post, author Author, now time.Time) *Decision {
+	decision := &Decision{Violations: errors.NewValidationErrors()}
+	violations := decision.Violations
+	posting := rules.Posting
+	text := post.Content
+	if post.Kind == KindTopic {
+		text = post.Title + "\n" + post.Content
+	}
+
+	// Who may post
+	if min := rules.Config.MinimumUserLevel; min > 0 && !author.Moderator && TrustRank(author.TrustLevel) < min {
+		violations.Add("trustLevel", fmt.Sprintf("posting in this category requires trust level %d", min), string(author.TrustLevel))
+	}
+	if cooldown := time.Duration(posting.PostingCooldown) * time.Second; cooldown > 0 && !author.Moderator && author.LastPostAt != nil {
+		if wait := author.LastPostAt.Add(cooldown).Sub(now); wait > 0 {
+			violations.Add("cooldown", fmt.Sprintf("please wait %d seconds before posting again", int(wait.Seconds()+0.999)), "")
+		}
+	}
+
+	// What may be posted
+	if post.Kind == KindTopic {
+		checkLength(violations, "title", post.Title, posting.MinimumTitleLength, posting.MaximumTitleLength)
+	}
+	checkLength(violations, "content", post.Content, posting.MinimumContentLength, posting.MaximumContentLength)
+	if words := MatchWords(text, posting.DisallowedWords); len(words) > 0 {
+		violations.Add("content", "contains words that are not allowed in this category", strings.Join(words, ", "))
+	}
+	if required := splitList(posting.RequiredWords); len(required) > 0 && len(MatchWords(text, posting.RequiredWords)) == 0 {
+		violations.Add("content", "must mention one of: "+strings.Join(required, ", "), "")
+	}
+	links := CountLinks(text)
+	if author.NoLinks && !author.Moderator && links > 0 {
+		violations.Add("content", "your trust level does not allow links yet", fmt.Sprint(links))
+	}
+	if posting.MaxLinksPerPost > 0 && links > posting.MaxLinksPerPost {
+		violations.Add("content", fmt.Sprintf("may contain at most %d links", posting.MaxLinksPerPost), fmt.Sprint(links))
+	}
+
+	// Whether a moderator must approve it first
+	if author.Moderator || autoApproved(rules.AutoMod, author) {
+		return decision
+	}
+	config := rules.Config
+	switch {
+	case config.RequireApproval:
+		decision.Hold("category requires approval")
+	case post.Kind == KindTopic && config.RequireTopicApproval:
+		decision.Hold("category requires approval of topics")
+	case post.Kind == KindComment && config.RequireCommentApproval:
+		decision.Hold("category requires approval of comments")
+	}
+	if posting.RequireURLApproval && links > 0 {
+		decision.Hold("links require approval")
+	}
+	if words := MatchWords(text, config.AutoModKeywords); len(words) > 0 {
+		decision.Hold("contains flagged keywords: " + strings.Join(words, ", "))
+	}
+	if rules.AutoMod.EnableAutoModeration && rules.AutoMod.KeywordFlagging {
+		if words := MatchWords(text, rules.AutoMod.FlaggedKeywords); len(words) > 0 {
+			decision.Hold("contains flagged keywords: " + strings.Join(words, ", "))
+		}
+	}
+	return decision
+}
+
+// ApplyFilter folds the outcome of the moderation rules into a decision.
+// Content the rules reject or remove is refused; content they flag, hide or
+// queue is held for approval. Warnings and automatic filtering let it
+// through.
+func ApplyFilter(decision *Decision, result *models.ContentFilterResult) {
+	if result == nil {
+		return
+	}
+	switch result.Action {
+	case models.ActionReject, models.ActionRemoved, models.ActionBanned,
+		models.ActionTemporaryBan, models.ActionPermanentBan:
+		decision.Violations.Add("content", "violates the community guidelines", "")
+	case models.ActionFlagged, models.ActionHidden, models.ActionSendToQueue:
+		decision.Hold("matched moderation rules")
+	}
+}
+
+// Scores are a post's spam and abuse classifier scores, from 0 to 1
+type Scores struct {
+	Spam       float64
+	Negativity float64
+}
+
+// ApplyScores holds posts whose classifier scores reach the thresholds of
+// the category's AutoModerationSettings. Moderators and auto-approved authors
+// are exempt.
+func ApplyScores(decision *Decision, rules *Rules, author Author, scores Scores) {
+	settings := rules.AutoMod
+	if !settings.EnableAutoModeration || author.Moderator || autoApproved(settings, author) {
+		return
+	}
+	if settings.SpamDetection && settings.SpamScoreThreshold > 0 && scores.Spam >= settings.SpamScoreThreshold {
+		decision.Hold(fmt.Sprintf("likely spam (score %.2f)", scores.Spam))
+	}
+	if settings.SentimentAnalysis && settings.NegativeSentimentLimit > 0 && scores.Negativity >= settings.NegativeSentimentLimit {
+		decision.Hold(fmt.Sprintf("hostile tone (score %.2f)", scores.Negativity))
+	}
+}
@@ -0,0 +1,178 @@
+package policy
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/yerenwgventures/GreatNigeriaLibrary-Foundation/backend/pkg/common/errors"
+	"github.com/yerenwgventures/GreatNigeriaLibrary-Foundation/backend/services/discussion/models"
+	"gorm.io/gorm"
+)
+
+// fields returns the fields of a decision's violations
+func fields(d *Decision) []string {
+	var fields []string
+	for _, v := range d.Violations.Errors {
+		fields = append(fields, v.Field)
+	}
+	return fields
+}
+
+func TestUnconfiguredCategoriesAcceptAnything(t *testing.T) {
+	d := Evaluate(&Rules{}, Post{Kind: KindTopic, Title: "Hi", Content: "http://a.example http://b.example"}, Author{}, time.Now())
+	assert.True(t, d.Allowed())
+	assert.False(t, d.NeedsApproval())
+	assert.True(t, (&Rules{}).TextFilters())
+}
+
+func TestPostingRules(t *testing.T) {
+	rules := &Rules{Posting: models.PostingRules{
+		MinimumTitleLength:   5,
+		MaximumTitleLength:   20,
+		MinimumContentLength: 10,
+		MaximumContentLength: 200,
+		DisallowedWords:      "scam, get rich",
+		MaxLinksPerPost:      1,
+	}}
+	now := time.Now()
+
+	d := Evaluate(rules, Post{Kind: KindTopic, Title: "Reading the constitution", Content: "short"}, Author{}, now)
+	assert.Equal(t, []string{"title", "content"}, fields(d))
+
+	d = Evaluate(rules, Post{Kind: KindComment, Content: "Join this Scam to GET RICH via www.a.example and https://b.example"}, Author{}, now)
+	assert.Equal(t, []string{"content", "content"}, fields(d))
+	assert.Equal(t, "scam, get rich", d.Violations.Errors[0].Value)
+	assert.Equal(t, "2", d.Violations.Errors[1].Value)
+
+	// Disallowed words only match whole words
+	d = Evaluate(rules, Post{Kind: KindComment, Content: "Scampering goats crossed the road"}, Author{}, now)
+	assert.True(t, d.Allowed())
+
+	// Comments have no title
+	d = Evaluate(rules, Post{Kind: KindComment, Content: "A thoughtful reply"}, Author{}, now)
+	assert.True(t, d.Allowed())
+}
+
+func TestRequiredWords(t *testing.T) {
+	rules := &Rules{Posting: models.PostingRules{RequiredWords: "chapter, section"}}
+	assert.False(t, Evaluate(rules, Post{Kind: KindTopic, Title: "Question", Content: "About the book"}, Author{}, time.Now()).Allowed())
+	assert.True(t, Evaluate(rules, Post{Kind: KindTopic, Title: "Chapter 3", Content: "About the book"}, Author{}, time.Now()).Allowed())
+}
+
+func TestLinksByTrustLevel(t *testing.T) {
+	post := Post{Kind: KindComment, Content: "See https://a.example for the chapter"}
+
+	d := Evaluate(&Rules{}, post, Author{NoLinks: true}, time.Now())
+	assert.Equal(t, []string{"content"}, fields(d))
+	assert.Equal(t, "1", d.Violations.Errors[0].Value)
+
+	assert.True(t, Evaluate(&Rules{}, Post{Kind: KindComment, Content: "No links here"}, Author{NoLinks: true}, time.Now()).Allowed())
+	assert.True(t, Evaluate(&Rules{}, post, Author{NoLinks: true, Moderator: true}, time.Now()).Allowed(), "moderators are exempt")
+}
+
+func TestCooldownAndTrustLevel(t *testing.T) {
+	rules := &Rules{
+		Config:  models.CategoryConfig{MinimumUserLevel: 2},
+		Posting: models.PostingRules{PostingCooldown: 60},
+	}
+	now := time.Now()
+	recent := now.Add(-20 * time.Second)
+	post := Post{Kind: KindComment, Content: "Another reply"}
+
+	d := Evaluate(rules, post, Author{TrustLevel: models.TrustLevelBasic, LastPostAt: &recent}, now)
+	assert.Equal(t, []string{"trustLevel", "cooldown"}, fields(d))
+	assert.Equal(t, "please wait 40 seconds before posting again", d.Violations.Errors[1].Message)
+
+	earlier := now.Add(-time.Minute)
+	assert.True(t, Evaluate(rules, post, Author{TrustLevel: models.TrustLevelMember, LastPostAt: &earlier}, now).Allowed())
+	assert.True(t, Evaluate(rules, post, Author{LastPostAt: &recent, Moderator: true}, now).Allowed(), "moderators are exempt")
+}
+
+func TestApproval(t *testing.T) {
+	rules := &Rules{
+		Config:  models.CategoryConfig{RequireTopicApproval: true, AutoModKeywords: "election"},
+		Posting: models.PostingRules{RequireURLApproval: true},
+		AutoMod: models.AutoModerationSettings{
+			Model:                gorm.Model{ID: 1},
+			EnableAutoModeration: true,
+			KeywordFlagging:      true,
+			FlaggedKeywords:      "rigging",
+			AutoApproveUsers:     "leader",
+		},
+	}
+	now := time.Now()
+
+	d := Evaluate(rules, Post{Kind: KindTopic, Title: "Meetup", Content: "See you there"}, Author{}, now)
+	assert.Equal(t, []string{"category requires approval of topics"}, d.Approval)
+
+	d = Evaluate(rules, Post{Kind: KindComment, Content: "Election rigging? See https://a.example"}, Author{}, now)
+	assert.Equal(t, []string{
+		"links require approval",
+		"contains flagged keywords: election",
+		"contains flagged keywords: rigging",
+	}, d.Approval)
+	assert.True(t, d.Allowed(), "held posts break no rule")
+
+	assert.False(t, Evaluate(rules, Post{Kind: KindTopic, Title: "Meetup", Content: "Election"}, Author{TrustLevel: models.TrustLevelLeader}, now).NeedsApproval())
+	assert.False(t, Evaluate(rules, Post{Kind: KindTopic, Title: "Meetup", Content: "Election"}, Author{Moderator: true}, now).NeedsApproval())
+	assert.False(t, rules.TextFilters(), "configured categories opt in to text filters")
+}
+
+func TestApplyFilter(t *testing.T) {
+	d := &Decision{Violations: errors.NewValidationErrors()}
+	ApplyFilter(d, nil)
+	ApplyFilter(d, &models.ContentFilterResult{Action: models.ActionWarning})
+	assert.True(t, d.Allowed())
+	assert.False(t, d.NeedsApproval())
+
+	ApplyFilter(d, &models.ContentFilterResult{Action: models.ActionSendToQueue})
+	assert.True(t, d.NeedsApproval())
+	ApplyFilter(d, &models.ContentFilterResult{Action: models.ActionReject})
+	assert.False(t, d.Allowed())
+}
+
+func TestApplyScores(t *testing.T) {
+	rules := &Rules{AutoMod: models.AutoModerationSettings{
+		EnableAutoModeration:   true,
+		SpamDetection:          true,
+		SpamScoreThreshold:     0.7,
+		SentimentAnalysis:      true,
+		NegativeSentimentLimit: 0.8,
+		AutoApproveUsers:       "leader",
+	}}
+	scores := Scores{Spam: 0.92, Negativity: 0.8}
+
+	d := &Decision{Violations: errors.NewValidationErrors()}
+	ApplyScores(d, rules, Author{}, Scores{Spam: 0.69, Negativity: 0.5})
+	assert.False(t, d.NeedsApproval())
+	ApplyScores(d, rules, Author{}, scores)
+	assert.Equal(t, []string{"likely spam (score 0.92)", "hostile tone (score 0.80)"}, d.Approval)
+	assert.True(t, d.Allowed(), "scores only hold posts")
+
+	for _, author := range []Author{{Moderator: true}, {TrustLevel: models.TrustLevelLeader}} {
+		d = &Decision{Violations: errors.NewValidationErrors()}
+		ApplyScores(d, rules, author, scores)
+		assert.False(t, d.NeedsApproval())
+	}
+
+	rules.AutoMod.SentimentAnalysis = false
+	d = &Decision{Violations: errors.NewValidationErrors()}
+	ApplyScores(d, rules, Author{}, scores)
+	assert.Equal(t, []string{"likely spam (score 0.92)"}, d.Approval)
+
+	d = &Decision{Violations: errors.NewValidationErrors()}
+	ApplyScores(d, &Rules{}, Author{}, scores)
+	assert.False(t, d.NeedsApproval(), "unconfigured categories do not use scores")
+}
+
+func TestTrustRank(t *testing.T) {
+	assert.Equal(t, 0, TrustRank(models.TrustLevelNewUser))
+	assert.Equal(t, 4, TrustRank(models.TrustLevelLeader))
+	assert.Equal(t, 0, TrustRank("unknown"))
+}
+
+func TestCountLinks(t *testing.T) {
+	assert.Equal(t, 0, CountLinks("no links, just example.com"))
+	assert.Equal(t, 3, CountLinks("http://a.example, HTTPS://b.example/x?y=1 and www.c.example"))
+}
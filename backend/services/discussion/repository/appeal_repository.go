@@ -0,0 +1,216 @@
+package repository
+
+import (
+        "database/sql"
+        "time"
+
+        "github.com/yerenwgventures/GreatNigeriaLibrary-Foundation/backend/services/discussion/appeals"
+        "github.com/yerenwgventures/GreatNigeriaLibrary-Foundation/backend/services/discussion/models"
+        "gorm.io/gorm"
+)
+
+// AppealRepository defines the interface for appeal operations
+type AppealRepository interface {
+        CreateAppeal(appeal *models.Appeal) error
+        GetAppealByID(id uint) (*models.Appeal, error)
+        GetAppealByDecision(decisionType models.AppealDecisionType, decisionID uint) (*models.Appeal, error)
+        UpdateAppeal(appeal *models.Appeal) error
+        GetAppeals(status models.AppealStatus, reviewerID uint, overdue bool, page, pageSize int) ([]models.Appeal, int64, error)
+        GetAppealsByUser(userID uint) ([]models.Appeal, error)
+
+        // GetDecision loads the decision an appeal is about, or an empty
+        // decision if there is none
+        GetDecision(decisionType models.AppealDecisionType, decisionID uint) (*appeals.Decision, error)
+
+        // GetPendingCountsByReviewer counts each reviewer's pending appeals
+        GetPendingCountsByReviewer() (map[uint]int64, error)
+
+        // GetAppealStats summarises appeals filed since a time, and every
+        // appeal still pending
+        GetAppealStats(since, now time.Time) (*models.AppealStats, error)
+}
+
+// GormAppealRepository implements the AppealRepository interface
+type GormAppealRepository struct {
+        db *gorm.DB
+}
+
+// NewGormAppealRepository creates a new appeal repository
+func NewGormAppealRepository(db *gorm.DB) *GormAppealRepository {
+        return &GormAppealRepository{db: db}
+}
+
+// CreateAppeal creates an appeal
+func (r *GormAppealRepository) CreateAppeal(appeal *models.Appeal) error {
+        return r.db.Create(appeal).Error
+}
+
+// GetAppealByID retrieves an appeal by ID
+func (r *GormAppealRepository) GetAppealByID(id uint) (*models.Appeal, error) {
+        var appeal models.Appeal
+        if err := r.db.First(&appeal, id).Error; err != nil {
+                return nil, err
+        }
+        return &appeal, nil
+}
+
+// GetAppealByDecision retrieves the appeal of a decision, or an empty appeal
+// if it was not appealed
+func (r *GormAppealRepository) GetAppealByDecision(decisionType models.AppealDecisionType, decisionID uint) (*models.Appeal, error) {
+        var appeal models.Appeal
+        err := r.db.Where("decision_type = ? AND decision_id = ?", decisionType, decisionID).First(&appeal).Error
+        if err != nil {
+                if err == gorm.ErrRecordNotFound {
+                        return &models.Appeal{}, nil
+                }
+                return nil, err
+        }
+        return &appeal, nil
+}
+
+// UpdateAppeal updates an appeal
+func (r *GormAppealRepository) UpdateAppeal(appeal *models.Appeal) error {
+        return r.db.Save(appeal).Error
+}
+
+// GetAppeals retrieves appeals, oldest due first, optionally by status, by
+// reviewer and only those past their due time
+func (r *GormAppealRepository) GetAppeals(status models.AppealStatus, reviewerID uint, overdue bool, page, pageSize int) ([]models.Appeal, int64, error) {
+        query := r.db.Model(&models.Appeal{})
+        if status != "" {
+                query = query.Where("status = ?", status)
+        }
+        if reviewerID != 0 {
+                query = query.Where("reviewer_id = ?", reviewerID)
+        }
+        if overdue {
+                query = query.Where("status = ? AND due_at < ?", models.AppealStatusPending, time.Now())
+        }
+
+        var count int64
+        if err := query.Count(&count).Error; err != nil {
+                return nil, 0, err
+        }
+
+        var result []models.Appeal
+        err := query.Order("due_at ASC").
+                Limit(pageSize).
+                Offset((page - 1) * pageSize).
+                Find(&result).Error
+        return result, count, err
+}
+
+// GetAppealsByUser retrieves the appeals a user filed, newest first
+func (r *GormAppealRepository) GetAppealsByUser(userID uint) ([]models.Appeal, error) {
+        var result []models.Appeal
+        err := r.db.Where("user_id = ?", userID).Order("created_at DESC").Find(&result).Error
+        return result, err
+}
+
+// GetDecision loads a penalty, moderation action or content moderation status
+// as an appeal sees it. Content decisions affect the post's author.
+func (r *GormAppealRepository) GetDecision(decisionType models.AppealDecisionType, decisionID uint) (*appeals.Decision, error) {
+        var query string
+        switch decisionType {
+        case models.AppealPenalty:
+                query = `
+                        SELECT id, user_id, moderator_id, penalty_type AS kind, created_at AS decided_at,
+                               expires_at, is_active AS active, '' AS content_type, 0 AS content_id
+                        FROM user_penalties
+                        WHERE id = @id AND deleted_at IS NULL`
+        case models.AppealModerationAction:
+                query = `
+                        SELECT id, user_id, COALESCE(NULLIF(moderator_id, 0), applied_by) AS moderator_id,
+                               action_type AS kind, applied_at AS decided_at, expires_at,
+                               is_active AND revoked_at IS NULL AS active, '' AS content_type, 0 AS content_id
+                        FROM user_moderation_actions
+                        WHERE id = @id AND deleted_at IS NULL`
+        case models.AppealContent:
+                query = `
+                        SELECT s.id, COALESCE(t.user_id, c.user_id, 0) AS user_id, COALESCE(s.moderator_id, 0) AS moderator_id,
+                               s.status AS kind, s.updated_at AS decided_at, NULL AS expires_at,
+                               s.status IN ('hidden', 'rejected') AS active, s.content_type, s.content_id
+                        FROM content_moderation_statuses s
+                        LEFT JOIN topics t ON s.content_type = 'topic' AND t.id = s.content_id
+                        LEFT JOIN comments c ON s.content_type = 'comment' AND c.id = s.content_id
+                        WHERE s.id = @id AND s.deleted_at IS NULL`
+        default:
+                return &appeals.Decision{}, nil
+        }
+
+        var row struct {
+                ID          uint
+                UserID      uint
+                ModeratorID uint
+                Kind        string
+                DecidedAt   time.Time
+                ExpiresAt   *time.Time
+                Active      bool
+                ContentType string
+                ContentID   uint
+        }
+        if err := r.db.Raw(query, sql.Named("id", decisionID)).Scan(&row).Error; err != nil {
+                return nil, err
+        }
+        if row.ID == 0 {
+                return &appeals.Decision{}, nil
+        }
+
+        return &appeals.Decision{
+                Type:        decisionType,
+                ID:          row.ID,
+                UserID:      row.UserID,
+                ModeratorID: row.ModeratorID,
+                Kind:        row.Kind,
+                DecidedAt:   row.DecidedAt,
+                ExpiresAt:   row.ExpiresAt,
+                Active:      row.Active,
+                ContentType: row.ContentType,
+                ContentID:   row.ContentID,
+        }, nil
+}
+
+// GetPendingCountsByReviewer counts each reviewer's pending appeals
+func (r *GormAppealRepository) GetPendingCountsByReviewer() (map[uint]int64, error) {
+        var rows []struct {
+                ReviewerID uint
+                Count      int64
+        }
+        err := r.db.Model(&models.Appeal{}).
+                Select("reviewer_id, COUNT(*) AS count").
+                Where("status = ? AND reviewer_id IS NOT NULL", models.AppealStatusPending).
+                Group("reviewer_id").
+                Scan(&rows).Error
+        if err != nil {
+                return nil, err
+        }
+
+        counts := make(map[uint]int64, len(rows))
+        for _, row := range rows {
+                counts[row.ReviewerID] = row.Count
+        }
+        return counts, nil
+}
+
+// GetAppealStats counts appeals by status and against their due times
+func (r *GormAppealRepository) GetAppealStats(since, now time.Time) (*models.AppealStats, error) {
+        stats := &models.AppealStats{}
+        err := r.db.Raw(`
+                SELECT
+                        COUNT(*) FILTER (WHERE status = 'pending') AS pending,
+                        COUNT(*) FILTER (WHERE status = 'pending' AND reviewer_id IS NULL) AS unassigned,
+                        COUNT(*) FILTER (WHERE status = 'pending' AND due_at < @now) AS overdue,
+                        COUNT(*) FILTER (WHERE status = 'upheld' AND created_at >= @since) AS upheld,
+                        COUNT(*) FILTER (WHERE status = 'reduced' AND created_at >= @since) AS reduced,
+                        COUNT(*) FILTER (WHERE status = 'overturned' AND created_at >= @since) AS overturned,
+                        COUNT(*) FILTER (WHERE resolved_at <= due_at AND created_at >= @since) AS resolved_within_sla,
+                        COALESCE(AVG(EXTRACT(EPOCH FROM resolved_at - created_at) / 3600)
+                                FILTER (WHERE resolved_at IS NOT NULL AND created_at >= @since), 0) AS average_hours_to_review
+                FROM appeals
+                WHERE deleted_at IS NULL`,
+                sql.Named("since", since), sql.Named("now", now)).Scan(stats).Error
+        if err != nil {
+                return nil, err
+        }
+        return stats, nil
+}
@@ -0,0 +1,130 @@
+package repository
+
+import (
+        "database/sql"
+        "time"
+
+        "github.com/yerenwgventures/GreatNigeriaLibrary-Foundation/backend/services/discussion/models"
+        "gorm.io/gorm"
+)
+
+// ClassificationRepository defines the interface for spam classification data
+type ClassificationRepository interface {
+        // Post classifications
+        CreateClassification(classification *models.PostClassification) error
+        GetClassification(contentType string, contentID uint) (*models.PostClassification, error)
+        LabelClassification(contentType string, contentID uint, label string, moderatorID uint) error
+        GetLabelledClassifications(limit int) ([]models.PostClassification, error)
+        CountLabelledClassifications() (int64, error)
+
+        // Classifier models
+        CreateClassifierModel(model *models.ClassifierModel) error
+        GetLatestClassifierModel() (*models.ClassifierModel, error)
+
+        // Author signals
+        GetAccountCreatedAt(userID uint) (*time.Time, error)
+        CountPostsSince(userID uint, since time.Time) (int64, error)
+        CountPostsWithContent(userID uint, content string) (int64, error)
+}
+
+// GormClassificationRepository implements the ClassificationRepository interface
+type GormClassificationRepository struct {
+        db *gorm.DB
+}
+
+// NewGormClassificationRepository creates a new classification repository
+func NewGormClassificationRepository(db *gorm.DB) *GormClassificationRepository {
+        return &GormClassificationRepository{db: db}
+}
+
+// CreateClassification saves the classification of a post
+func (r *GormClassificationRepository) CreateClassification(classification *models.PostClassification) error {
+        return r.db.Create(classification).Error
+}
+
+// GetClassification retrieves the classification of a post
+func (r *GormClassificationRepository) GetClassification(contentType string, contentID uint) (*models.PostClassification, error) {
+        var classification models.PostClassification
+        err := r.db.Where("content_type = ? AND content_id = ?", contentType, contentID).First(&classification).Error
+        if err != nil {
+                return nil, err
+        }
+        return &classification, nil
+}
+
+// LabelClassification records a moderator's verdict on a post. Posts that
+// were never classified are left alone.
+func (r *GormClassificationRepository) LabelClassification(contentType string, contentID uint, label string, moderatorID uint) error {
+        return r.db.Model(&models.PostClassification{}).
+                Where("content_type = ? AND content_id = ?", contentType, contentID).
+                Updates(map[string]interface{}{
+                        "label":      label,
+                        "labeled_by": moderatorID,
+                        "labeled_at": time.Now(),
+                }).Error
+}
+
+// GetLabelledClassifications retrieves the most recently labelled classifications
+func (r *GormClassificationRepository) GetLabelledClassifications(limit int) ([]models.PostClassification, error) {
+        var classifications []models.PostClassification
+        err := r.db.Where("label <> ''").
+                Order("labeled_at DESC").
+                Limit(limit).
+                Find(&classifications).Error
+        return classifications, err
+}
+
+// CountLabelledClassifications counts the labelled classifications
+func (r *GormClassificationRepository) CountLabelledClassifications() (int64, error) {
+        var count int64
+        err := r.db.Model(&models.PostClassification{}).Where("label <> ''").Count(&count).Error
+        return count, err
+}
+
+// CreateClassifierModel saves a trained model
+func (r *GormClassificationRepository) CreateClassifierModel(model *models.ClassifierModel) error {
+        return r.db.Create(model).Error
+}
+
+// GetLatestClassifierModel retrieves the most recently trained model
+func (r *GormClassificationRepository) GetLatestClassifierModel() (*models.ClassifierModel, error) {
+        var model models.ClassifierModel
+        err := r.db.Order("id DESC").First(&model).Error
+        if err != nil {
+                return nil, err
+        }
+        return &model, nil
+}
+
+// GetAccountCreatedAt returns when a user registered
+func (r *GormClassificationRepository) GetAccountCreatedAt(userID uint) (*time.Time, error) {
+        var createdAt sql.NullTime
+        if err := r.db.Raw("SELECT created_at FROM users WHERE id = ?", userID).Row().Scan(&createdAt); err != nil {
+                return nil, err
+        }
+        if !createdAt.Valid {
+                return nil, nil
+        }
+        return &createdAt.Time, nil
+}
+
+// CountPostsSince counts the topics and comments a user created since a time
+func (r *GormClassificationRepository) CountPostsSince(userID uint, since time.Time) (int64, error) {
+        var count int64
+        err := r.db.Raw(`
+                SELECT (SELECT COUNT(*) FROM topics WHERE user_id = ? AND created_at >= ?)
+                     + (SELECT COUNT(*) FROM comments WHERE user_id = ? AND created_at >= ?)`,
+                userID, since, userID, since).Row().Scan(&count)
+        return count, err
+}
+
+// CountPostsWithContent counts the topics and comments of a user with exactly
+// the given content, deleted ones included
+func (r *GormClassificationRepository) CountPostsWithContent(userID uint, content string) (int64, error) {
+        var count int64
+        err := r.db.Raw(`
+                SELECT (SELECT COUNT(*) FROM topics WHERE user_id = ? AND content = ?)
+                     + (SELECT COUNT(*) FROM comments WHERE user_id = ? AND content = ?)`,
+                userID, content, userID, content).Row().Scan(&count)
+        return count, err
+}
@@ -1,6 +1,7 @@
 package repository
 
 import (
+        "database/sql"
         "time"
 
         "github.com/yerenwgventures/GreatNigeriaLibrary-Foundation/backend/services/discussion/models"
@@ -107,6 +108,9 @@ type DiscussionRepository interface {
         CreateMention(mention *models.Mention) error
         GetMentionsForUser(userID uint, isRead bool, page, pageSize int) ([]models.Mention, int64, error)
         MarkMentionAsRead(id uint) error
+
+        // Posting
+        GetLastPostTime(userID uint) (*time.Time, error)
 }
 
 // GormDiscussionRepository implements the DiscussionRepository interface using GORM
@@ -275,7 +279,7 @@ func (r *GormDiscussionRepository) GetTopics(page, pageSize int, filters map[str
         var total int64
 
         // Start with the base query
-        query := r.db.Model(&models.Topic{})
+        query := r.db.Model(&models.Topic{}).Where("is_approved = ?", true)
 
         // Apply filters
         for key, value := range filters {
@@ -304,7 +308,7 @@ func (r *GormDiscussionRepository) GetTopics(page, pageSize int, filters map[str
         // Calculate replies count for each topic
         for i := range topics {
                 var count int64
-                err := r.db.Model(&models.Comment{}).Where("topic_id = ?", topics[i].ID).Count(&count).Error
+                err := r.db.Model(&models.Comment{}).Where("topic_id = ? AND is_approved = ?", topics[i].ID, true).Count(&count).Error
                 if err != nil {
                         return nil, 0, err
                 }
@@ -327,7 +331,7 @@ func (r *GormDiscussionRepository) GetTopicByID(id uint) (*models.Topic, error)
 
         // Get replies count
         var count int64
-        err = r.db.Model(&models.Comment{}).Where("topic_id = ?", topic.ID).Count(&count).Error
+        err = r.db.Model(&models.Comment{}).Where("topic_id = ? AND is_approved = ?", topic.ID, true).Count(&count).Error
         if err != nil {
                 return nil, err
         }
@@ -342,7 +346,7 @@ func (r *GormDiscussionRepository) GetTopicsByCategory(categoryID uint, page, pa
         var total int64
 
         // Count total
-        err := r.db.Model(&models.Topic{}).Where("category_id = ?", categoryID).Count(&total).Error
+        err := r.db.Model(&models.Topic{}).Where("category_id = ? AND is_approved = ?", categoryID, true).Count(&total).Error
         if err != nil {
                 return nil, 0, err
         }
@@ -350,7 +354,7 @@ func (r *GormDiscussionRepository) GetTopicsByCategory(categoryID uint, page, pa
         // Calculate offset and fetch paginated results
         offset := (page - 1) * pageSize
         err = r.db.
-                Where("category_id = ?", categoryID).
+                Where("category_id = ? AND is_approved = ?", categoryID, true).
                 Preload("Category").
                 Order("is_pinned DESC, last_post_at DESC").
                 Offset(offset).
@@ -364,7 +368,7 @@ func (r *GormDiscussionRepository) GetTopicsByCategory(categoryID uint, page, pa
         // Calculate replies count for each topic
         for i := range topics {
                 var count int64
-                err := r.db.Model(&models.Comment{}).Where("topic_id = ?", topics[i].ID).Count(&count).Error
+                err := r.db.Model(&models.Comment{}).Where("topic_id = ? AND is_approved = ?", topics[i].ID, true).Count(&count).Error
                 if err != nil {
                         return nil, 0, err
                 }
@@ -402,7 +406,7 @@ func (r *GormDiscussionRepository) GetTopicsByUser(userID uint, page, pageSize i
         // Calculate replies count for each topic
         for i := range topics {
                 var count int64
-                err := r.db.Model(&models.Comment{}).Where("topic_id = ?", topics[i].ID).Count(&count).Error
+                err := r.db.Model(&models.Comment{}).Where("topic_id = ? AND is_approved = ?", topics[i].ID, true).Count(&count).Error
                 if err != nil {
                         return nil, 0, err
                 }
@@ -415,7 +419,7 @@ func (r *GormDiscussionRepository) GetTopicsByUser(userID uint, page, pageSize i
 // GetTopicsByBookSection retrieves topics related to a specific book section
 func (r *GormDiscussionRepository) GetTopicsByBookSection(bookID, chapterID, sectionID uint) ([]models.Topic, error) {
         var topics []models.Topic
-        query := r.db.Preload("Category")
+        query := r.db.Preload("Category").Where("is_approved = ?", true)
 
         // Build the query based on provided parameters
         if bookID > 0 {
@@ -436,7 +440,7 @@ func (r *GormDiscussionRepository) GetTopicsByBookSection(bookID, chapterID, sec
         // Calculate replies count for each topic
         for i := range topics {
                 var count int64
-                err := r.db.Model(&models.Comment{}).Where("topic_id = ?", topics[i].ID).Count(&count).Error
+                err := r.db.Model(&models.Comment{}).Where("topic_id = ? AND is_approved = ?", topics[i].ID, true).Count(&count).Error
                 if err != nil {
                         return nil, err
                 }
@@ -458,6 +462,13 @@ func (r *GormDiscussionRepository) CreateTopic(topic *models.Topic) error {
                         return err
                 }
                 
+                // Create skips false for columns with a default, so hold the topic explicitly
+                if !topic.IsApproved {
+                        if err := tx.Model(topic).Update("is_approved", false).Error; err != nil {
+                                return err
+                        }
+                }
+                
                 // Update user stats
                 var stats models.UserDiscussionStats
                 result := tx.Where("user_id = ?", topic.UserID).First(&stats)
@@ -557,7 +568,7 @@ func (r *GormDiscussionRepository) GetCommentsByTopic(topicID uint, page, pageSi
         var total int64
 
         // Count total comments for this topic
-        err := r.db.Model(&models.Comment{}).Where("topic_id = ? AND parent_id IS NULL", topicID).Count(&total).Error
+        err := r.db.Model(&models.Comment{}).Where("topic_id = ? AND parent_id IS NULL AND is_approved = ?", topicID, true).Count(&total).Error
         if err != nil {
                 return nil, 0, err
         }
@@ -565,7 +576,7 @@ func (r *GormDiscussionRepository) GetCommentsByTopic(topicID uint, page, pageSi
         // Calculate offset and fetch paginated results
         offset := (page - 1) * pageSize
         err = r.db.
-                Where("topic_id = ? AND parent_id IS NULL", topicID).
+                Where("topic_id = ? AND parent_id IS NULL AND is_approved = ?", topicID, true).
                 Order("created_at ASC").
                 Offset(offset).
                 Limit(pageSize).
@@ -578,7 +589,7 @@ func (r *GormDiscussionRepository) GetCommentsByTopic(topicID uint, page, pageSi
         // For each top-level comment, count replies and load the first few replies
         for i := range comments {
                 var replyCount int64
-                err := r.db.Model(&models.Comment{}).Where("parent_id = ?", comments[i].ID).Count(&replyCount).Error
+                err := r.db.Model(&models.Comment{}).Where("parent_id = ? AND is_approved = ?", comments[i].ID, true).Count(&replyCount).Error
                 if err != nil {
                         return nil, 0, err
                 }
@@ -586,7 +597,7 @@ func (r *GormDiscussionRepository) GetCommentsByTopic(topicID uint, page, pageSi
 
                 // Load replies (limited to first 5 for preview)
                 err = r.db.
-                        Where("parent_id = ?", comments[i].ID).
+                        Where("parent_id = ? AND is_approved = ?", comments[i].ID, true).
                         Order("created_at ASC").
                         Limit(5).
                         Find(&comments[i].Replies).Error
@@ -612,7 +623,7 @@ func (r *GormDiscussionRepository) GetCommentByID(id uint) (*models.Comment, err
 func (r *GormDiscussionRepository) GetRepliesByComment(commentID uint) ([]models.Comment, error) {
         var replies []models.Comment
         err := r.db.
-                Where("parent_id = ?", commentID).
+                Where("parent_id = ? AND is_approved = ?", commentID, true).
                 Order("created_at ASC").
                 Find(&replies).Error
         return replies, err
@@ -627,8 +638,13 @@ func (r *GormDiscussionRepository) CreateComment(comment *models.Comment) error
                         return err
                 }
                 
-                // Update the topic's last post time
-                if err := tx.Model(&models.Topic{}).Where("id = ?", comment.TopicID).Update("last_post_at", time.Now()).Error; err != nil {
+                // Create skips false for columns with a default, so hold the comment
+                // explicitly. Held comments do not bump the topic until approved.
+                if !comment.IsApproved {
+                        if err := tx.Model(comment).Update("is_approved", false).Error; err != nil {
+                                return err
+                        }
+                } else if err := tx.Model(&models.Topic{}).Where("id = ?", comment.TopicID).Update("last_post_at", time.Now()).Error; err != nil {
                         return err
                 }
                 
@@ -1294,6 +1310,26 @@ func (r *GormDiscussionRepository) MarkMentionAsRead(id uint) error {
         return r.db.Model(&models.Mention{}).Where("id = ?", id).Update("is_read", true).Error
 }
 
+// GetLastPostTime returns when a user last created a topic or comment, or
+// nil if they never have. Deleted posts count, so deleting a post does not
+// skip a posting cooldown.
+func (r *GormDiscussionRepository) GetLastPostTime(userID uint) (*time.Time, error) {
+        var last sql.NullTime
+        err := r.db.Raw(`
+                SELECT MAX(created_at) FROM (
+                        SELECT created_at FROM topics WHERE user_id = ?
+                        UNION ALL
+                        SELECT created_at FROM comments WHERE user_id = ?
+                ) AS posts`, userID, userID).Row().Scan(&last)
+        if err != nil {
+                return nil, err
+        }
+        if !last.Valid {
+                return nil, nil
+        }
+        return &last.Time, nil
+}
+
 // User Repository methods
 
 // GetUserByID retrieves a user by their ID
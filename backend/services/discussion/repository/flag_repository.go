@@ -5,6 +5,7 @@ import (
 
 	"github.com/yerenwgventures/GreatNigeriaLibrary-Foundation/backend/services/discussion/models"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 // FlagRepository defines the interface for content flag operations
@@ -32,6 +33,15 @@ type FlagRepository interface {
 	UpdateUserPenalty(penalty *models.UserPenalty) error
 	DeactivateUserPenalty(penaltyID uint) error
 	GetPenaltyByID(id uint) (*models.UserPenalty, error)
+
+	// Flag aggregates
+	GetFlagAggregate(contentType string, contentID uint) (*models.FlagAggregate, error)
+	AddFlagWeight(aggregate *models.FlagAggregate) error
+	HideFlagAggregate(id uint, hiddenAt time.Time) (bool, error)
+	SaveFlagAggregate(aggregate *models.FlagAggregate) error
+	GetFlagAccuracy(userID uint) (upheld, dismissed int, err error)
+	ResolvePendingFlags(contentType string, contentID uint, status models.FlagStatus, moderatorID uint, actionTaken string) error
+	DismissFlags(contentType string, contentID uint, moderatorID uint, actionTaken string) error
 }
 
 // GormFlagRepository implements the FlagRepository interface
@@ -194,4 +204,97 @@ func (r *GormFlagRepository) GetPenaltyByID(id uint) (*models.UserPenalty, error
 		return nil, result.Error
 	}
 	return &penalty, nil
-}
\ No newline at end of file
+}
+// GetFlagAggregate retrieves the combined flags on a content item, or an
+// empty aggregate if it was never flagged
+func (r *GormFlagRepository) GetFlagAggregate(contentType string, contentID uint) (*models.FlagAggregate, error) {
+	var aggregate models.FlagAggregate
+	err := r.db.Where("content_type = ? AND content_id = ?", contentType, contentID).First(&aggregate).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return &models.FlagAggregate{}, nil
+		}
+		return nil, err
+	}
+	return &aggregate, nil
+}
+
+// AddFlagWeight adds an aggregate's weight and flaggers to the content's
+// aggregate, creating it on the first flag, and loads the result into it
+func (r *GormFlagRepository) AddFlagWeight(aggregate *models.FlagAggregate) error {
+	err := r.db.Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "content_type"}, {Name: "content_id"}},
+		DoUpdates: clause.Assignments(map[string]interface{}{
+			"weight":     gorm.Expr("flag_aggregates.weight + EXCLUDED.weight"),
+			"flaggers":   gorm.Expr("flag_aggregates.flaggers + EXCLUDED.flaggers"),
+			"updated_at": time.Now(),
+		}),
+	}).Create(aggregate).Error
+	if err != nil {
+		return err
+	}
+	return r.db.Where("content_type = ? AND content_id = ?", aggregate.ContentType, aggregate.ContentID).First(aggregate).Error
+}
+
+// HideFlagAggregate marks an open aggregate hidden. It reports false if the
+// aggregate was no longer open, so only one flag hides the content.
+func (r *GormFlagRepository) HideFlagAggregate(id uint, hiddenAt time.Time) (bool, error) {
+	result := r.db.Model(&models.FlagAggregate{}).
+		Where("id = ? AND status = ?", id, models.FlagAggregateOpen).
+		Updates(map[string]interface{}{
+			"status":     models.FlagAggregateHidden,
+			"hidden_at":  hiddenAt,
+			"updated_at": time.Now(),
+		})
+	return result.RowsAffected == 1, result.Error
+}
+
+// SaveFlagAggregate updates a flag aggregate
+func (r *GormFlagRepository) SaveFlagAggregate(aggregate *models.FlagAggregate) error {
+	return r.db.Save(aggregate).Error
+}
+
+// GetFlagAccuracy counts a user's flags that moderators upheld and dismissed
+func (r *GormFlagRepository) GetFlagAccuracy(userID uint) (int, int, error) {
+	var counts struct {
+		Upheld    int
+		Dismissed int
+	}
+	err := r.db.Model(&models.ContentFlag{}).
+		Select("COUNT(*) FILTER (WHERE status = ?) AS upheld, COUNT(*) FILTER (WHERE status = ?) AS dismissed",
+			models.FlagStatusApproved, models.FlagStatusRejected).
+		Where("user_id = ?", userID).
+		Scan(&counts).Error
+	return counts.Upheld, counts.Dismissed, err
+}
+
+// ResolvePendingFlags closes every pending flag on a content item with the
+// moderator's decision
+func (r *GormFlagRepository) ResolvePendingFlags(contentType string, contentID uint, status models.FlagStatus, moderatorID uint, actionTaken string) error {
+	now := time.Now()
+	return r.db.Model(&models.ContentFlag{}).
+		Where("content_type = ? AND content_id = ? AND status = ?", contentType, contentID, models.FlagStatusPending).
+		Updates(map[string]interface{}{
+			"status":       status,
+			"reviewed_by":  moderatorID,
+			"reviewed_at":  now,
+			"action_taken": actionTaken,
+			"updated_at":   now,
+		}).Error
+}
+
+// DismissFlags dismisses every pending and upheld flag on a content item, as
+// when a moderator restores it
+func (r *GormFlagRepository) DismissFlags(contentType string, contentID uint, moderatorID uint, actionTaken string) error {
+	now := time.Now()
+	return r.db.Model(&models.ContentFlag{}).
+		Where("content_type = ? AND content_id = ? AND status IN ?", contentType, contentID,
+			[]models.FlagStatus{models.FlagStatusPending, models.FlagStatusApproved}).
+		Updates(map[string]interface{}{
+			"status":       models.FlagStatusRejected,
+			"reviewed_by":  moderatorID,
+			"reviewed_at":  now,
+			"action_taken": actionTaken,
+			"updated_at":   now,
+		}).Error
+}
@@ -51,6 +51,7 @@ type ModerationRepository interface {
         GetUserModerationActionByID(id uint) (*models.UserModerationAction, error)
         GetActiveActionsForUser(userID uint) ([]models.UserModerationAction, error)
         DeactivateUserModerationAction(actionID uint) error
+        UpdateUserModerationAction(action *models.UserModerationAction) error
         
         // Prohibited words
         CreateProhibitedWord(word *models.ProhibitedWord) error
@@ -341,6 +342,11 @@ func (r *GormModerationRepository) DeactivateUserModerationAction(actionID uint)
                 }).Error
 }
 
+// UpdateUserModerationAction updates a user moderation action
+func (r *GormModerationRepository) UpdateUserModerationAction(action *models.UserModerationAction) error {
+        return r.db.Save(action).Error
+}
+
 // CreateProhibitedWord creates a prohibited word
 func (r *GormModerationRepository) CreateProhibitedWord(word *models.ProhibitedWord) error {
         return r.db.Create(word).Error
@@ -1,10 +1,12 @@
 package repository
 
 import (
+        "fmt"
         "time"
 
         "github.com/yerenwgventures/GreatNigeriaLibrary-Foundation/backend/services/discussion/models"
         "gorm.io/gorm"
+        "gorm.io/gorm/clause"
 )
 
 // SubscriptionRepository defines the interface for subscription data operations
@@ -25,6 +27,15 @@ type SubscriptionRepository interface {
         GetPendingDigests(frequency models.SubscriptionFrequency, before time.Time) ([]models.SubscriptionDigest, error)
         UpdateDigestStatus(id uint, status string, sentAt *time.Time, errorMessage string) error
         GetUserDigests(userID uint, limit int, offset int) ([]models.SubscriptionDigest, error)
+        
+        GetDigestRecipient(userID uint) (email string, name string, err error)
+        
+        GetDigestSubscriptions(frequency models.SubscriptionFrequency, now time.Time) ([]models.AdvancedSubscription, error)
+        GetTopicActivity(userID uint, refType models.SubscriptionType, refID uint, since, until time.Time, limit int) ([]models.TopicActivity, error)
+        GetReferenceName(refType models.SubscriptionType, refID uint) (string, error)
+        CreateDigestForPeriod(digest *models.SubscriptionDigest, subscriptionIDs []uint, notifiedAt time.Time) (bool, error)
+        ClaimDigest(id uint) (bool, error)
+        RequeueDigests(claimedBefore, failedBefore time.Time, maxAttempts int) (int64, error)
 }
 
 // GormSubscriptionRepository implements the SubscriptionRepository interface
@@ -106,6 +117,7 @@ func (r *GormSubscriptionRepository) GetSubscriptionPreference(userID uint) (*mo
                                 DefaultInAppEnabled:    true,
                                 DigestDay:              0, // Sunday
                                 DigestHour:             8, // 8 AM
+                                TimeZone:               "UTC",
                                 AutoSubscribeToReplies: true,
                                 AutoSubscribeToCreated: true,
                                 CreatedAt:              time.Now(),
@@ -166,4 +178,167 @@ func (r *GormSubscriptionRepository) GetUserDigests(userID uint, limit int, offs
                 Offset(offset).
                 Find(&digests)
         return digests, result.Error
-}
\ No newline at end of file
+}
+
+// GetDigestRecipient retrieves the email address and display name digests are sent to
+func (r *GormSubscriptionRepository) GetDigestRecipient(userID uint) (string, string, error) {
+        var recipient struct {
+                Email    string
+                FullName string
+                Username string
+        }
+        result := r.db.Table("users").
+                Select("email, full_name, username").
+                Where("id = ? AND deleted_at IS NULL", userID).
+                Take(&recipient)
+        if result.Error != nil {
+                return "", "", result.Error
+        }
+        
+        name := recipient.FullName
+        if name == "" {
+                name = recipient.Username
+        }
+        return recipient.Email, name, nil
+}
+
+// GetDigestSubscriptions retrieves the active, unmuted email subscriptions for a digest frequency
+func (r *GormSubscriptionRepository) GetDigestSubscriptions(frequency models.SubscriptionFrequency, now time.Time) ([]models.AdvancedSubscription, error) {
+        var subscriptions []models.AdvancedSubscription
+        result := r.db.Where("frequency = ? AND muted = ? AND email_notification = ?", frequency, false, true).
+                Where("expires_at IS NULL OR expires_at > ?", now).
+                Order("user_id, id").
+                Find(&subscriptions)
+        return subscriptions, result.Error
+}
+
+// topicActivityScopes restricts the topics considered for each subscription type
+var topicActivityScopes = map[models.SubscriptionType]string{
+        models.TopicSubscription:    "t.id = @ref",
+        models.CategorySubscription: "t.category_id = @ref",
+        models.TagSubscription:      "t.id IN (SELECT topic_id FROM topic_tags WHERE tag_id = @ref)",
+}
+
+// GetTopicActivity retrieves topics in a subscription's scope that were created, commented on or
+// reacted to in (since, until]. Activity by the subscriber themselves is not counted.
+func (r *GormSubscriptionRepository) GetTopicActivity(userID uint, refType models.SubscriptionType, refID uint, since, until time.Time, limit int) ([]models.TopicActivity, error) {
+        scope, ok := topicActivityScopes[refType]
+        if !ok {
+                return nil, fmt.Errorf("unsupported subscription type: %s", refType)
+        }
+        
+        query := fmt.Sprintf(`
+                SELECT * FROM (
+                        SELECT
+                                t.id AS topic_id,
+                                t.title,
+                                (t.created_at > @since AND t.created_at <= @until AND t.user_id <> @user) AS is_new,
+                                (SELECT count(*) FROM comments c
+                                        WHERE c.topic_id = t.id AND c.deleted_at IS NULL AND c.is_approved
+                                        AND c.user_id <> @user AND c.created_at > @since AND c.created_at <= @until) AS new_comments,
+                                (SELECT count(*) FROM reactions re
+                                        WHERE re.deleted_at IS NULL AND re.user_id <> @user
+                                        AND re.created_at > @since AND re.created_at <= @until
+                                        AND ((re.target_type = 'topic' AND re.target_id = t.id)
+                                                OR (re.target_type = 'comment' AND re.target_id IN (SELECT id FROM comments WHERE topic_id = t.id)))) AS new_reactions,
+                                GREATEST(t.created_at, t.last_post_at) AS last_activity
+                        FROM topics t
+                        WHERE t.deleted_at IS NULL AND t.is_approved AND %s
+                ) activity
+                WHERE is_new OR new_comments > 0 OR new_reactions > 0
+                ORDER BY is_new DESC, new_comments + new_reactions DESC, last_activity DESC
+                LIMIT @limit`, scope)
+        
+        var activity []models.TopicActivity
+        result := r.db.Raw(query, map[string]interface{}{
+                "ref":   refID,
+                "user":  userID,
+                "since": since,
+                "until": until,
+                "limit": limit,
+        }).Scan(&activity)
+        return activity, result.Error
+}
+
+// GetReferenceName retrieves the title or name of a subscribed topic, category or tag
+func (r *GormSubscriptionRepository) GetReferenceName(refType models.SubscriptionType, refID uint) (string, error) {
+        var name string
+        var result *gorm.DB
+        switch refType {
+        case models.TopicSubscription:
+                result = r.db.Model(&models.Topic{}).Where("id = ?", refID).Select("title").Scan(&name)
+        case models.CategorySubscription:
+                result = r.db.Model(&models.Category{}).Where("id = ?", refID).Select("name").Scan(&name)
+        case models.TagSubscription:
+                result = r.db.Model(&models.Tag{}).Where("id = ?", refID).Select("name").Scan(&name)
+        default:
+                return "", fmt.Errorf("unsupported subscription type: %s", refType)
+        }
+        return name, result.Error
+}
+
+// CreateDigestForPeriod stores a digest and marks the subscriptions it covers as notified.
+// It returns false without changing anything if the user already has a digest for the period,
+// so digest generation can safely be repeated.
+func (r *GormSubscriptionRepository) CreateDigestForPeriod(digest *models.SubscriptionDigest, subscriptionIDs []uint, notifiedAt time.Time) (bool, error) {
+        created := false
+        err := r.db.Transaction(func(tx *gorm.DB) error {
+                result := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(digest)
+                if result.Error != nil {
+                        return result.Error
+                }
+                if result.RowsAffected == 0 {
+                        return nil
+                }
+                created = true
+                
+                if len(subscriptionIDs) == 0 {
+                        return nil
+                }
+                return tx.Model(&models.AdvancedSubscription{}).
+                        Where("id IN ?", subscriptionIDs).
+                        Update("last_notified_at", notifiedAt).Error
+        })
+        return created, err
+}
+
+// ClaimDigest moves a pending digest to "sending" and counts the attempt. It returns false if another worker claimed it first.
+func (r *GormSubscriptionRepository) ClaimDigest(id uint) (bool, error) {
+        now := time.Now()
+        result := r.db.Model(&models.SubscriptionDigest{}).
+                Where("id = ? AND delivery_status = ?", id, "pending").
+                Updates(map[string]interface{}{
+                        "delivery_status": "sending",
+                        "attempts":        gorm.Expr("attempts + 1"),
+                        "claimed_at":      now,
+                        "updated_at":      now,
+                })
+        return result.RowsAffected == 1, result.Error
+}
+
+// RequeueDigests returns digests to "pending" so they are sent again: those claimed before claimedBefore
+// and still "sending", for example after the instance sending them stopped, and those that failed before
+// failedBefore. Digests already tried maxAttempts times stay or become "failed" instead.
+func (r *GormSubscriptionRepository) RequeueDigests(claimedBefore, failedBefore time.Time, maxAttempts int) (int64, error) {
+        now := time.Now()
+        abandoned := r.db.Model(&models.SubscriptionDigest{}).
+                Where("delivery_status = ? AND claimed_at < ? AND attempts >= ?", "sending", claimedBefore, maxAttempts).
+                Updates(map[string]interface{}{
+                        "delivery_status": "failed",
+                        "error_message":   "digest was not sent",
+                        "updated_at":      now,
+                })
+        if abandoned.Error != nil {
+                return 0, abandoned.Error
+        }
+        
+        requeued := r.db.Model(&models.SubscriptionDigest{}).
+                Where("attempts < ?", maxAttempts).
+                Where(r.db.Where("delivery_status = ? AND claimed_at < ?", "sending", claimedBefore).
+                        Or("delivery_status = ? AND updated_at < ?", "failed", failedBefore)).
+                Updates(map[string]interface{}{
+                        "delivery_status": "pending",
+                        "updated_at":      now,
+                })
+        return requeued.RowsAffected, requeued.Error
+}
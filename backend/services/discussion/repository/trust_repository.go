@@ -0,0 +1,157 @@
+package repository
+
+import (
+        "database/sql"
+        "time"
+
+        authmodels "github.com/yerenwgventures/GreatNigeriaLibrary-Foundation/backend/pkg/models"
+        "github.com/yerenwgventures/GreatNigeriaLibrary-Foundation/backend/services/discussion/models"
+        "github.com/yerenwgventures/GreatNigeriaLibrary-Foundation/backend/services/discussion/trust"
+        "gorm.io/gorm"
+)
+
+// TrustRepository defines the interface for the data the trust engine reads
+// and writes
+type TrustRepository interface {
+        // Settings
+        GetTrustSettings() (*models.TrustSettings, error)
+        SaveTrustSettings(settings *models.TrustSettings) error
+
+        // Activity
+        GetActivity(userID uint, since time.Time) (*trust.Activity, error)
+        GetUserIDsAfter(afterID uint, limit int) ([]uint, error)
+
+        // Auth trust levels
+        GetAuthTrustLevel(userID uint) (authmodels.TrustLevel, error)
+        SaveAuthTrustLevel(userID uint, level authmodels.TrustLevel, points int) error
+}
+
+// GormTrustRepository implements the TrustRepository interface
+type GormTrustRepository struct {
+        db *gorm.DB
+}
+
+// NewGormTrustRepository creates a new trust repository
+func NewGormTrustRepository(db *gorm.DB) *GormTrustRepository {
+        return &GormTrustRepository{db: db}
+}
+
+// GetTrustSettings retrieves the trust settings, or empty settings if none
+// were saved
+func (r *GormTrustRepository) GetTrustSettings() (*models.TrustSettings, error) {
+        var settings models.TrustSettings
+        err := r.db.Order("id").First(&settings).Error
+        if err != nil {
+                if err == gorm.ErrRecordNotFound {
+                        return &models.TrustSettings{}, nil
+                }
+                return nil, err
+        }
+        return &settings, nil
+}
+
+// SaveTrustSettings creates or updates the trust settings
+func (r *GormTrustRepository) SaveTrustSettings(settings *models.TrustSettings) error {
+        return r.db.Save(settings).Error
+}
+
+// GetActivity counts what a user has done. Marks against the user are only
+// counted since the given time.
+func (r *GormTrustRepository) GetActivity(userID uint, since time.Time) (*trust.Activity, error) {
+        activity := &trust.Activity{}
+
+        var createdAt sql.NullTime
+        if err := r.db.Raw("SELECT created_at FROM users WHERE id = ?", userID).Row().Scan(&createdAt); err != nil {
+                return nil, err
+        }
+        if createdAt.Valid {
+                activity.AccountAge = time.Since(createdAt.Time)
+        }
+
+        var counts struct {
+                Posts             int
+                ReactionsReceived int
+                FlagsUpheld       int
+                FlagsDismissed    int
+                FlagsAgainst      int
+                Rejections        int
+                Warnings          int
+                Penalties         int
+        }
+        // Penalties a moderator removed before they ran out do not count. The
+        // removal deactivates them early, unlike expiry.
+        err := r.db.Raw(`
+                WITH own_topics AS (
+                        SELECT id FROM topics WHERE user_id = @user
+                ), own_comments AS (
+                        SELECT id FROM comments WHERE user_id = @user
+                ), counted_penalties AS (
+                        SELECT penalty_type FROM user_penalties
+                        WHERE user_id = @user AND created_at >= @since
+                          AND (is_active OR (expires_at IS NOT NULL AND expires_at <= updated_at))
+                )
+                SELECT
+                        (SELECT COUNT(*) FROM topics WHERE user_id = @user AND is_approved AND deleted_at IS NULL)
+                      + (SELECT COUNT(*) FROM comments WHERE user_id = @user AND is_approved AND deleted_at IS NULL) AS posts,
+                        (SELECT COUNT(*) FROM reactions
+                         WHERE deleted_at IS NULL AND user_id <> @user
+                           AND ((target_type = 'topic' AND target_id IN (SELECT id FROM own_topics))
+                             OR (target_type = 'comment' AND target_id IN (SELECT id FROM own_comments)))) AS reactions_received,
+                        (SELECT COUNT(*) FROM content_flags WHERE user_id = @user AND status = 'approved') AS flags_upheld,
+                        (SELECT COUNT(*) FROM content_flags
+                         WHERE user_id = @user AND status = 'rejected' AND reviewed_at >= @since) AS flags_dismissed,
+                        (SELECT COUNT(*) FROM content_flags
+                         WHERE status = 'approved' AND reviewed_at >= @since
+                           AND ((content_type = 'topic' AND content_id IN (SELECT id FROM own_topics))
+                             OR (content_type = 'comment' AND content_id IN (SELECT id FROM own_comments)))) AS flags_against,
+                        (SELECT COUNT(*) FROM mod_advanced_queues
+                         WHERE user_id = @user AND status = 'rejected' AND reviewed_at >= @since) AS rejections,
+                        (SELECT COUNT(*) FROM counted_penalties WHERE penalty_type = 'warning')
+                      + (SELECT COUNT(*) FROM user_moderation_actions
+                         WHERE user_id = @user AND action_type = 'warning' AND applied_at >= @since AND revoked_at IS NULL) AS warnings,
+                        (SELECT COUNT(*) FROM counted_penalties WHERE penalty_type <> 'warning')
+                      + (SELECT COUNT(*) FROM user_moderation_actions
+                         WHERE user_id = @user AND action_type <> 'warning' AND applied_at >= @since AND revoked_at IS NULL) AS penalties`,
+                sql.Named("user", userID), sql.Named("since", since)).Scan(&counts).Error
+        if err != nil {
+                return nil, err
+        }
+
+        activity.Posts = counts.Posts
+        activity.ReactionsReceived = counts.ReactionsReceived
+        activity.FlagsUpheld = counts.FlagsUpheld
+        activity.FlagsDismissed = counts.FlagsDismissed
+        activity.FlagsAgainst = counts.FlagsAgainst
+        activity.Rejections = counts.Rejections
+        activity.Warnings = counts.Warnings
+        activity.Penalties = counts.Penalties
+        return activity, nil
+}
+
+// GetUserIDsAfter returns up to limit IDs of active users above afterID, in order
+func (r *GormTrustRepository) GetUserIDsAfter(afterID uint, limit int) ([]uint, error) {
+        var ids []uint
+        err := r.db.Raw("SELECT id FROM users WHERE id > ? AND deleted_at IS NULL ORDER BY id LIMIT ?", afterID, limit).
+                Scan(&ids).Error
+        return ids, err
+}
+
+// GetAuthTrustLevel returns a user's trust level in the auth service, or an
+// empty level if they have none
+func (r *GormTrustRepository) GetAuthTrustLevel(userID uint) (authmodels.TrustLevel, error) {
+        var level authmodels.TrustLevel
+        err := r.db.Raw("SELECT level FROM user_trust_levels WHERE user_id = ?", userID).Row().Scan(&level)
+        if err == sql.ErrNoRows {
+                return "", nil
+        }
+        return level, err
+}
+
+// SaveAuthTrustLevel sets a user's trust level in the auth service
+func (r *GormTrustRepository) SaveAuthTrustLevel(userID uint, level authmodels.TrustLevel, points int) error {
+        return r.db.Exec(`
+                INSERT INTO user_trust_levels (user_id, level, points, updated_at) VALUES (?, ?, ?, ?)
+                ON CONFLICT (user_id) DO UPDATE
+                SET level = EXCLUDED.level, points = EXCLUDED.points, updated_at = EXCLUDED.updated_at`,
+                userID, level, points, time.Now()).Error
+}
@@ -0,0 +1,370 @@
+package service
+
+import (
+        "fmt"
+        "log"
+        "strings"
+        "time"
+
+        "github.com/yerenwgventures/GreatNigeriaLibrary-Foundation/backend/pkg/common/errors"
+        "github.com/yerenwgventures/GreatNigeriaLibrary-Foundation/backend/services/discussion/appeals"
+        "github.com/yerenwgventures/GreatNigeriaLibrary-Foundation/backend/services/discussion/models"
+        "github.com/yerenwgventures/GreatNigeriaLibrary-Foundation/backend/services/discussion/repository"
+        notificationmodels "github.com/yerenwgventures/GreatNigeriaLibrary-Foundation/backend/services/notification/models"
+        notification "github.com/yerenwgventures/GreatNigeriaLibrary-Foundation/backend/services/notification/service"
+)
+
+// AppealService lets users appeal penalties, moderation actions and hidden
+// posts, and routes each appeal to a moderator other than the one who decided
+type AppealService interface {
+        // Filing
+        FileAppeal(userID uint, decisionType models.AppealDecisionType, decisionID uint, reason string) (*models.Appeal, error)
+        GetUserAppeals(userID uint) ([]models.Appeal, error)
+
+        // Review
+        GetAppeal(id uint) (*models.Appeal, error)
+        GetAppeals(status models.AppealStatus, reviewerID uint, overdue bool, page, pageSize int) ([]models.Appeal, int64, error)
+        AssignAppeal(appealID, reviewerID uint) (*models.Appeal, error)
+        ReviewAppeal(appealID, reviewerID uint, outcome models.AppealStatus, response string, reducedDays *int) (*models.Appeal, error)
+
+        // Dashboard stats
+        GetAppealStats(since time.Time) (*models.AppealStats, error)
+
+        // SetNotifier enables in-app notifications for reviewers and appellants
+        SetNotifier(notifier notification.Notifier)
+}
+
+// AppealServiceImpl implements the AppealService interface
+type AppealServiceImpl struct {
+        appealRepo        repository.AppealRepository
+        flagService       FlagService
+        moderationService ModerationService
+        notifier          notification.Notifier
+        window            time.Duration
+        sla               time.Duration
+}
+
+// NewAppealService creates a new appeal service. Users may appeal a decision
+// for window after it was made, and moderators have sla to review an appeal;
+// zero durations use the defaults.
+func NewAppealService(
+        appealRepo repository.AppealRepository,
+        flagService FlagService,
+        moderationService ModerationService,
+        window, sla time.Duration,
+) AppealService {
+        if window <= 0 {
+                window = appeals.DefaultWindow
+        }
+        if sla <= 0 {
+                sla = appeals.DefaultSLA
+        }
+        return &AppealServiceImpl{
+                appealRepo:        appealRepo,
+                flagService:       flagService,
+                moderationService: moderationService,
+                window:            window,
+                sla:               sla,
+        }
+}
+
+// SetNotifier enables in-app notifications for reviewers and appellants
+func (s *AppealServiceImpl) SetNotifier(notifier notification.Notifier) {
+        s.notifier = notifier
+}
+
+// FileAppeal appeals a decision affecting the user and routes the appeal to a
+// reviewer. Appeals no moderator may review wait for one to be assigned.
+func (s *AppealServiceImpl) FileAppeal(userID uint, decisionType models.AppealDecisionType, decisionID uint, reason string) (*models.Appeal, error) {
+        violations := errors.NewValidationErrors()
+        if decisionType != models.AppealPenalty && decisionType != models.AppealModerationAction && decisionType != models.AppealContent {
+                violations.Add("decisionType", "must be penalty, moderation_action or content", string(decisionType))
+        }
+        reason = strings.TrimSpace(reason)
+        if reason == "" {
+                violations.Add("reason", "is required", "")
+        }
+        if violations.HasErrors() {
+                return nil, violations
+        }
+
+        decision, err := s.appealRepo.GetDecision(decisionType, decisionID)
+        if err != nil {
+                return nil, fmt.Errorf("error getting decision: %w", err)
+        }
+        if decision.ID == 0 {
+                return nil, models.ErrNotAppealable
+        }
+
+        now := time.Now()
+        if err := appeals.CanAppeal(*decision, userID, s.window, now); err != nil {
+                return nil, err
+        }
+
+        existing, err := s.appealRepo.GetAppealByDecision(decisionType, decisionID)
+        if err != nil {
+                return nil, fmt.Errorf("error getting appeal: %w", err)
+        }
+        if existing.ID != 0 {
+                return nil, models.ErrAppealExists
+        }
+
+        appeal := &models.Appeal{
+                DecisionType:      decisionType,
+                DecisionID:        decisionID,
+                UserID:            userID,
+                ActingModeratorID: decision.ModeratorID,
+                Reason:            reason,
+                Status:            models.AppealStatusPending,
+                DueAt:             now.Add(s.sla),
+        }
+        reviewerID, err := s.pickReviewer(decision, userID)
+        if err != nil {
+                log.Printf("Error routing appeal of %s %d: %v", decisionType, decisionID, err)
+        } else if reviewerID != 0 {
+                appeal.ReviewerID = &reviewerID
+                appeal.AssignedAt = &now
+        }
+
+        if err := s.appealRepo.CreateAppeal(appeal); err != nil {
+                return nil, fmt.Errorf("error creating appeal: %w", err)
+        }
+
+        s.notifyReviewer(appeal)
+        return appeal, nil
+}
+
+// pickReviewer returns the least busy moderator who may review an appeal of
+// a decision, or 0 if none may
+func (s *AppealServiceImpl) pickReviewer(decision *appeals.Decision, appellantID uint) (uint, error) {
+        moderators, err := s.moderationService.GetAllModerators()
+        if err != nil {
+                return 0, fmt.Errorf("error getting moderators: %w", err)
+        }
+        pending, err := s.appealRepo.GetPendingCountsByReviewer()
+        if err != nil {
+                return 0, fmt.Errorf("error counting pending appeals: %w", err)
+        }
+
+        reviewerID, _ := appeals.PickReviewer(moderators, *decision, appellantID, pending, time.Now())
+        return reviewerID, nil
+}
+
+// GetUserAppeals retrieves the appeals a user filed
+func (s *AppealServiceImpl) GetUserAppeals(userID uint) ([]models.Appeal, error) {
+        return s.appealRepo.GetAppealsByUser(userID)
+}
+
+// GetAppeal retrieves an appeal by ID
+func (s *AppealServiceImpl) GetAppeal(id uint) (*models.Appeal, error) {
+        return s.appealRepo.GetAppealByID(id)
+}
+
+// GetAppeals retrieves appeals, oldest due first
+func (s *AppealServiceImpl) GetAppeals(status models.AppealStatus, reviewerID uint, overdue bool, page, pageSize int) ([]models.Appeal, int64, error) {
+        // Ensure valid pagination
+        if page < 1 {
+                page = 1
+        }
+
+        if pageSize < 1 || pageSize > 100 {
+                pageSize = 20
+        }
+
+        return s.appealRepo.GetAppeals(status, reviewerID, overdue, page, pageSize)
+}
+
+// AssignAppeal hands a pending appeal to a reviewer, who must be a moderator
+// allowed to review it
+func (s *AppealServiceImpl) AssignAppeal(appealID, reviewerID uint) (*models.Appeal, error) {
+        appeal, decision, err := s.pendingAppeal(appealID)
+        if err != nil {
+                return nil, err
+        }
+        if err := s.checkReviewer(appeal, decision, reviewerID); err != nil {
+                return nil, err
+        }
+
+        now := time.Now()
+        appeal.ReviewerID = &reviewerID
+        appeal.AssignedAt = &now
+        if err := s.appealRepo.UpdateAppeal(appeal); err != nil {
+                return nil, fmt.Errorf("error updating appeal: %w", err)
+        }
+
+        s.notifyReviewer(appeal)
+        return appeal, nil
+}
+
+// ReviewAppeal decides an appeal. Upheld decisions stand, reduced penalties
+// and bans last reducedDays from when they were applied, and overturned
+// decisions are removed, revoked or restored.
+func (s *AppealServiceImpl) ReviewAppeal(appealID, reviewerID uint, outcome models.AppealStatus, response string, reducedDays *int) (*models.Appeal, error) {
+        appeal, decision, err := s.pendingAppeal(appealID)
+        if err != nil {
+                return nil, err
+        }
+        if appeal.ReviewerID != nil && *appeal.ReviewerID != reviewerID {
+                return nil, models.ErrNotAppealReviewer
+        }
+        if err := s.checkReviewer(appeal, decision, reviewerID); err != nil {
+                return nil, err
+        }
+
+        reason := fmt.Sprintf("Appeal %d %s", appeal.ID, outcome)
+        if response != "" {
+                reason += ": " + response
+        }
+
+        switch outcome {
+        case models.AppealStatusUpheld:
+        case models.AppealStatusReduced:
+                if err := s.reduce(decision, reviewerID, reducedDays, reason); err != nil {
+                        return nil, err
+                }
+                appeal.ReducedDays = reducedDays
+        case models.AppealStatusOverturned:
+                if err := s.overturn(decision, reviewerID, reason); err != nil {
+                        return nil, err
+                }
+        default:
+                violations := errors.NewValidationErrors()
+                violations.Add("outcome", "must be upheld, reduced or overturned", string(outcome))
+                return nil, violations
+        }
+
+        now := time.Now()
+        appeal.Status = outcome
+        appeal.Response = response
+        appeal.ReviewerID = &reviewerID
+        appeal.ResolvedAt = &now
+        if appeal.AssignedAt == nil {
+                appeal.AssignedAt = &now
+        }
+        if err := s.appealRepo.UpdateAppeal(appeal); err != nil {
+                return nil, fmt.Errorf("error updating appeal: %w", err)
+        }
+
+        s.notifyAppellant(appeal)
+        return appeal, nil
+}
+
+// reduce shortens the penalty or moderation action an appeal is about
+func (s *AppealServiceImpl) reduce(decision *appeals.Decision, reviewerID uint, days *int, reason string) error {
+        if days == nil {
+                violations := errors.NewValidationErrors()
+                violations.Add("reducedDays", "is required to reduce a decision", "")
+                return violations
+        }
+
+        var err error
+        switch decision.Type {
+        case models.AppealPenalty:
+                _, err = s.flagService.ReducePenalty(decision.ID, reviewerID, *days, reason)
+        case models.AppealModerationAction:
+                _, err = s.moderationService.ReduceUserModerationAction(decision.ID, reviewerID, *days, reason)
+        default:
+                _, err = appeals.Reduce(*decision, *days)
+        }
+        return err
+}
+
+// overturn reverses the decision an appeal is about
+func (s *AppealServiceImpl) overturn(decision *appeals.Decision, reviewerID uint, reason string) error {
+        switch decision.Type {
+        case models.AppealPenalty:
+                return s.flagService.RemovePenalty(decision.ID, reviewerID, reason)
+        case models.AppealModerationAction:
+                return s.moderationService.RevokeUserModerationAction(decision.ID, reviewerID, reason)
+        case models.AppealContent:
+                return s.flagService.RestoreContent(decision.ContentType, decision.ContentID, reviewerID, reason)
+        }
+        return models.ErrNotAppealable
+}
+
+// pendingAppeal loads a pending appeal and the decision it is about
+func (s *AppealServiceImpl) pendingAppeal(appealID uint) (*models.Appeal, *appeals.Decision, error) {
+        appeal, err := s.appealRepo.GetAppealByID(appealID)
+        if err != nil {
+                return nil, nil, fmt.Errorf("error getting appeal: %w", err)
+        }
+        if appeal.Status != models.AppealStatusPending {
+                return nil, nil, models.ErrAppealResolved
+        }
+
+        decision, err := s.appealRepo.GetDecision(appeal.DecisionType, appeal.DecisionID)
+        if err != nil {
+                return nil, nil, fmt.Errorf("error getting decision: %w", err)
+        }
+        if decision.ID == 0 {
+                return nil, nil, models.ErrNotAppealable
+        }
+        // The appeal is against the moderator who made the decision when it
+        // was filed, even if another has since changed it
+        decision.ModeratorID = appeal.ActingModeratorID
+        return appeal, decision, nil
+}
+
+// checkReviewer checks that a moderator's privileges let them review an appeal
+func (s *AppealServiceImpl) checkReviewer(appeal *models.Appeal, decision *appeals.Decision, reviewerID uint) error {
+        privileges, err := s.moderationService.GetModeratorPrivileges(reviewerID)
+        if err != nil || !appeals.MayReview(*privileges, *decision, appeal.UserID, time.Now()) {
+                return models.ErrNotAppealReviewer
+        }
+        return nil
+}
+
+// GetAppealStats summarises appeals filed since a time, and every appeal
+// still pending
+func (s *AppealServiceImpl) GetAppealStats(since time.Time) (*models.AppealStats, error) {
+        stats, err := s.appealRepo.GetAppealStats(since, time.Now())
+        if err != nil {
+                return nil, fmt.Errorf("error getting appeal stats: %w", err)
+        }
+        appeals.Summarize(stats)
+        return stats, nil
+}
+
+// notifyReviewer tells a reviewer an appeal awaits them
+func (s *AppealServiceImpl) notifyReviewer(appeal *models.Appeal) {
+        if appeal.ReviewerID == nil {
+                return
+        }
+
+        n := &notificationmodels.Notification{
+                UserID:        *appeal.ReviewerID,
+                Type:          notificationmodels.TypeModeration,
+                Title:         "An appeal awaits your review",
+                Body:          fmt.Sprintf("Please review it by %s: %s", appeal.DueAt.Format("2 Jan 15:04"), excerpt(appeal.Reason, 140)),
+                Link:          fmt.Sprintf("/moderation/appeals/%d", appeal.ID),
+                ReferenceType: "appeal",
+                ReferenceID:   appeal.ID,
+        }
+        n.WithDedupKey(fmt.Sprintf("appeal:%d:assigned:%d", appeal.ID, *appeal.ReviewerID))
+        notify(s.notifier, n)
+}
+
+// notifyAppellant tells a user how their appeal was decided
+func (s *AppealServiceImpl) notifyAppellant(appeal *models.Appeal) {
+        var title string
+        switch appeal.Status {
+        case models.AppealStatusUpheld:
+                title = "Your appeal was reviewed and the decision stands"
+        case models.AppealStatusReduced:
+                title = fmt.Sprintf("Your appeal was reviewed and the decision was reduced to %d days", *appeal.ReducedDays)
+        default:
+                title = "Your appeal was reviewed and the decision was overturned"
+        }
+
+        n := &notificationmodels.Notification{
+                UserID:        appeal.UserID,
+                Type:          notificationmodels.TypeModeration,
+                Title:         title,
+                Body:          appeal.Response,
+                Link:          "/appeals",
+                ReferenceType: "appeal",
+                ReferenceID:   appeal.ID,
+        }
+        n.WithDedupKey(fmt.Sprintf("appeal:%d:%s", appeal.ID, appeal.Status))
+        notify(s.notifier, n)
+}
@@ -0,0 +1,63 @@
+package service
+
+import (
+        "context"
+        "log"
+        "time"
+
+        "github.com/yerenwgventures/GreatNigeriaLibrary-Foundation/backend/pkg/common/jobs"
+)
+
+// DefaultRetrainInterval is how often the spam classifier is retrained from
+// moderators' decisions
+const DefaultRetrainInterval = 6 * time.Hour
+
+// ClassifierLeaseName names the lease that lets one replica retrain the classifier
+const ClassifierLeaseName = "discussion-classifier"
+
+// ClassifierTrainer periodically retrains the spam classifier. With a lease,
+// only the replica holding it trains; the others load the model it saved.
+type ClassifierTrainer struct {
+        spamService SpamService
+        runner      *jobs.Runner
+        trainedID   uint
+}
+
+// NewClassifierTrainer creates a new classifier trainer. A nil lease trains on
+// every replica, which is only sensible with a single replica.
+func NewClassifierTrainer(spamService SpamService, lease jobs.Lease, interval time.Duration) *ClassifierTrainer {
+        if interval <= 0 {
+                interval = DefaultRetrainInterval
+        }
+        return &ClassifierTrainer{
+                spamService: spamService,
+                runner:      jobs.NewRunner("classifier", lease, interval),
+        }
+}
+
+// Run retrains or reloads the classifier every interval until ctx is cancelled
+func (t *ClassifierTrainer) Run(ctx context.Context) {
+        t.runner.Every(ctx, t.RunOnce)
+}
+
+// RunOnce retrains the classifier if this replica holds the lease, and
+// otherwise loads the latest model
+func (t *ClassifierTrainer) RunOnce(ctx context.Context) {
+        if !t.runner.Acquire(ctx) {
+                if err := t.spamService.ReloadModel(); err != nil {
+                        log.Printf("Error loading spam classifier: %v", err)
+                }
+                return
+        }
+
+        model, err := t.spamService.Retrain()
+        if err != nil {
+                log.Printf("Error retraining spam classifier: %v", err)
+                return
+        }
+        if model != nil && model.ID != t.trainedID {
+                t.trainedID = model.ID
+                log.Printf("Spam classifier %d trained on %d posts (precision %.2f, recall %.2f)",
+                        model.ID, model.Examples, model.Precision, model.Recall)
+        }
+}
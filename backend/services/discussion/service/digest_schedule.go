@@ -0,0 +1,73 @@
+package service
+
+import (
+        "fmt"
+        "sort"
+        "time"
+
+        "github.com/yerenwgventures/GreatNigeriaLibrary-Foundation/backend/services/discussion/models"
+)
+
+const (
+        // maxDigestActivity caps the topics fetched per subscription
+        maxDigestActivity = 200
+        
+        // maxDigestTopTopics is the number of topics listed under a category or tag
+        maxDigestTopTopics = 5
+        
+        // digestSendTimeout is how long a digest may stay "sending" before the
+        // instance sending it is assumed to have stopped
+        digestSendTimeout = 10 * time.Minute
+        
+        // digestRetryDelay is how long a failed digest waits before it is sent again
+        digestRetryDelay = time.Hour
+        
+        // maxDigestAttempts is how often sending a digest is tried
+        maxDigestAttempts = 3
+)
+
+// toDigestTopic converts topic activity into a digest entry
+func toDigestTopic(activity models.TopicActivity) DigestTopic {
+        return DigestTopic{
+                TopicID:      activity.TopicID,
+                Title:        activity.Title,
+                IsNew:        activity.IsNew,
+                NewPosts:     activity.NewComments,
+                NewReactions: activity.NewReactions,
+                LastActivity: activity.LastActivity,
+                URL:          fmt.Sprintf("/discussions/topics/%d", activity.TopicID),
+        }
+}
+
+// summarizeActivity totals new topics, comments and reactions across topics
+func summarizeActivity(activity []models.TopicActivity) (newTopics, newPosts, newReactions int, lastActivity time.Time) {
+        for _, topic := range activity {
+                if topic.IsNew {
+                        newTopics++
+                }
+                newPosts += topic.NewComments
+                newReactions += topic.NewReactions
+                if topic.LastActivity.After(lastActivity) {
+                        lastActivity = topic.LastActivity
+                }
+        }
+        return newTopics, newPosts, newReactions, lastActivity
+}
+
+// topDigestTopics returns the most active topics, most comments and reactions first
+func topDigestTopics(activity []models.TopicActivity) []DigestTopic {
+        sorted := make([]models.TopicActivity, len(activity))
+        copy(sorted, activity)
+        sort.SliceStable(sorted, func(i, j int) bool {
+                return sorted[i].NewComments+sorted[i].NewReactions > sorted[j].NewComments+sorted[j].NewReactions
+        })
+        
+        if len(sorted) > maxDigestTopTopics {
+                sorted = sorted[:maxDigestTopTopics]
+        }
+        topics := make([]DigestTopic, len(sorted))
+        for i, topic := range sorted {
+                topics[i] = toDigestTopic(topic)
+        }
+        return topics
+}
@@ -0,0 +1,70 @@
+package service
+
+import (
+        "context"
+        "log"
+        "time"
+
+        "github.com/yerenwgventures/GreatNigeriaLibrary-Foundation/backend/services/discussion/models"
+)
+
+// DefaultDigestInterval is how often the digest scheduler runs. Digest times are
+// whole hours, so running more often than hourly only shortens the delivery delay.
+const DefaultDigestInterval = 15 * time.Minute
+
+// DigestScheduler periodically generates daily and weekly digests and delivers pending ones.
+// Generation is idempotent per user and period, so several instances can run it.
+type DigestScheduler struct {
+        subscriptionService SubscriptionService
+        interval            time.Duration
+}
+
+// NewDigestScheduler creates a new digest scheduler
+func NewDigestScheduler(subscriptionService SubscriptionService, interval time.Duration) *DigestScheduler {
+        if interval <= 0 {
+                interval = DefaultDigestInterval
+        }
+        return &DigestScheduler{
+                subscriptionService: subscriptionService,
+                interval:            interval,
+        }
+}
+
+// Run generates and sends digests every interval until ctx is cancelled
+func (d *DigestScheduler) Run(ctx context.Context) {
+        ticker := time.NewTicker(d.interval)
+        defer ticker.Stop()
+        
+        for {
+                d.RunOnce()
+                
+                select {
+                case <-ctx.Done():
+                        return
+                case <-ticker.C:
+                }
+        }
+}
+
+// RunOnce performs a single generation and delivery pass
+func (d *DigestScheduler) RunOnce() {
+        for _, frequency := range []models.SubscriptionFrequency{models.FrequencyDaily, models.FrequencyWeekly} {
+                created, err := d.subscriptionService.GenerateDigests(frequency)
+                if err != nil {
+                        log.Printf("Error generating %s digests: %v", frequency, err)
+                        continue
+                }
+                if created > 0 {
+                        log.Printf("Generated %d %s digests", created, frequency)
+                }
+        }
+        
+        sent, err := d.subscriptionService.ProcessPendingDigests()
+        if err != nil {
+                log.Printf("Error processing pending digests: %v", err)
+                return
+        }
+        if sent > 0 {
+                log.Printf("Sent %d digests", sent)
+        }
+}
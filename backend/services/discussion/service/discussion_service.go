@@ -1,12 +1,16 @@
 package service
 
 import (
+        "fmt"
         "strings"
         "time"
 
         "github.com/gosimple/slug"
         "github.com/yerenwgventures/GreatNigeriaLibrary-Foundation/backend/services/discussion/models"
+        "github.com/yerenwgventures/GreatNigeriaLibrary-Foundation/backend/services/discussion/policy"
         "github.com/yerenwgventures/GreatNigeriaLibrary-Foundation/backend/services/discussion/repository"
+        notificationmodels "github.com/yerenwgventures/GreatNigeriaLibrary-Foundation/backend/services/notification/models"
+        notification "github.com/yerenwgventures/GreatNigeriaLibrary-Foundation/backend/services/notification/service"
         "gorm.io/gorm"
 )
 
@@ -73,11 +77,22 @@ type DiscussionService interface {
 
         // User Stats
         GetUserDiscussionStats(userID uint) (*models.UserDiscussionStats, error)
+        
+        // SetNotifier enables in-app notifications for replies and subscriptions
+        SetNotifier(notifier notification.Notifier)
+        
+        // SetPostingPolicy enforces category posting rules on new topics and comments
+        SetPostingPolicy(policy *PostingPolicy)
+        
+        // ApproveContent publishes a topic or comment held for approval
+        ApproveContent(contentType string, contentID uint) error
 }
 
 // DiscussionServiceImpl implements the DiscussionService interface
 type DiscussionServiceImpl struct {
         discussionRepo repository.DiscussionRepository
+        notifier       notification.Notifier
+        policy         *PostingPolicy
 }
 
 // NewDiscussionService creates a new discussion service instance
@@ -87,6 +102,16 @@ func NewDiscussionService(discussionRepo repository.DiscussionRepository) Discus
         }
 }
 
+// SetNotifier enables in-app notifications for replies and subscriptions
+func (s *DiscussionServiceImpl) SetNotifier(notifier notification.Notifier) {
+        s.notifier = notifier
+}
+
+// SetPostingPolicy enforces category posting rules on new topics and comments
+func (s *DiscussionServiceImpl) SetPostingPolicy(policy *PostingPolicy) {
+        s.policy = policy
+}
+
 // GetCategories retrieves all categories
 func (s *DiscussionServiceImpl) GetCategories() ([]models.Category, error) {
         return s.discussionRepo.GetCategories()
@@ -356,16 +381,27 @@ func (s *DiscussionServiceImpl) GetTopicsByBookSection(bookID, chapterID, sectio
 
 // CreateTopic creates a new topic
 func (s *DiscussionServiceImpl) CreateTopic(userID, categoryID uint, title, content string, bookID, chapterID, sectionID *uint, tagIDs []uint) (*models.Topic, error) {
+        // Check the category's posting rules
+        post := policy.Post{Kind: policy.KindTopic, Title: title, Content: content}
+        var check *PostingCheck
+        if s.policy != nil {
+                var err error
+                if check, err = s.policy.Check(userID, categoryID, &post); err != nil {
+                        return nil, err
+                }
+        }
+        
         // Create topic
         topic := &models.Topic{
-                Title:      title,
-                Content:    content,
+                Title:      post.Title,
+                Content:    post.Content,
                 UserID:     userID,
                 CategoryID: categoryID,
                 BookID:     bookID,
                 ChapterID:  chapterID,
                 SectionID:  sectionID,
                 LastPostAt: time.Now(),
+                IsApproved: check == nil || !check.Decision.NeedsApproval(),
         }
         
         err := s.discussionRepo.CreateTopic(topic)
@@ -373,6 +409,10 @@ func (s *DiscussionServiceImpl) CreateTopic(userID, categoryID uint, title, cont
                 return nil, err
         }
         
+        if check != nil {
+                s.policy.Record(check, policy.KindTopic, topic.ID, userID)
+        }
+        
         // Add tags if provided
         if len(tagIDs) > 0 {
                 for _, tagID := range tagIDs {
@@ -384,9 +424,32 @@ func (s *DiscussionServiceImpl) CreateTopic(userID, categoryID uint, title, cont
                 }
         }
         
+        // Subscribers hear of held topics once they are approved
+        if topic.IsApproved {
+                s.notifyTopic(topic, tagIDs)
+        }
+        
         return topic, nil
 }
 
+// notifyTopic notifies instant subscribers of a new topic's category and tags
+func (s *DiscussionServiceImpl) notifyTopic(topic *models.Topic, tagIDs []uint) {
+        update := notificationmodels.Notification{
+                Type:          notificationmodels.TypeSubscriptionUpdate,
+                Title:         fmt.Sprintf("New topic: %s", topic.Title),
+                Body:          excerpt(topic.Content, 140),
+                Link:          contentLink("topic", topic.ID, 0),
+                ActorID:       &topic.UserID,
+                ReferenceType: "topic",
+                ReferenceID:   topic.ID,
+        }
+        update.WithDedupKey(fmt.Sprintf("topic:%d", topic.ID))
+        notifySubscribers(s.notifier, "category", topic.CategoryID, nil, update)
+        for _, tagID := range tagIDs {
+                notifySubscribers(s.notifier, "tag", tagID, nil, update)
+        }
+}
+
 // UpdateTopic updates an existing topic
 func (s *DiscussionServiceImpl) UpdateTopic(id, userID uint, title, content string, categoryID uint) (*models.Topic, error) {
         // Get the topic
@@ -477,19 +540,30 @@ func (s *DiscussionServiceImpl) CreateComment(userID, topicID uint, content stri
         }
         
         // Check if parent comment exists if parentID is provided
+        var parent *models.Comment
         if parentID != nil {
-                _, err := s.discussionRepo.GetCommentByID(*parentID)
+                parent, err = s.discussionRepo.GetCommentByID(*parentID)
                 if err != nil {
                         return nil, models.ErrCommentNotFound
                 }
         }
         
+        // Check the category's posting rules
+        post := policy.Post{Kind: policy.KindComment, Content: content}
+        var check *PostingCheck
+        if s.policy != nil {
+                if check, err = s.policy.Check(userID, topic.CategoryID, &post); err != nil {
+                        return nil, err
+                }
+        }
+        
         // Create comment
         comment := &models.Comment{
-                Content:  content,
-                UserID:   userID,
-                TopicID:  topicID,
-                ParentID: parentID,
+                Content:    post.Content,
+                UserID:     userID,
+                TopicID:    topicID,
+                ParentID:   parentID,
+                IsApproved: check == nil || !check.Decision.NeedsApproval(),
         }
         
         err = s.discussionRepo.CreateComment(comment)
@@ -497,9 +571,13 @@ func (s *DiscussionServiceImpl) CreateComment(userID, topicID uint, content stri
                 return nil, err
         }
         
+        if check != nil {
+                s.policy.Record(check, policy.KindComment, comment.ID, userID)
+        }
+        
         // Parse mentions (@username) and create mention records
         // This is a simplified implementation
-        words := strings.Fields(content)
+        words := strings.Fields(post.Content)
         for _, word := range words {
                 if strings.HasPrefix(word, "@") {
                         // In a real implementation, we would look up the user ID from the username
@@ -509,9 +587,111 @@ func (s *DiscussionServiceImpl) CreateComment(userID, topicID uint, content stri
                 }
         }
         
+        // Replies are announced once held comments are approved
+        if comment.IsApproved {
+                s.notifyReply(topic, parent, comment)
+        }
+        
         return comment, nil
 }
 
+// ApproveContent publishes a topic or comment held for approval and sends
+// the notifications held back with it
+func (s *DiscussionServiceImpl) ApproveContent(contentType string, contentID uint) error {
+        switch contentType {
+        case policy.KindTopic:
+                topic, err := s.discussionRepo.GetTopicByID(contentID)
+                if err != nil {
+                        return err
+                }
+                if topic.IsApproved {
+                        return nil
+                }
+                topic.IsApproved = true
+                if err := s.discussionRepo.UpdateTopic(topic); err != nil {
+                        return err
+                }
+                
+                var tagIDs []uint
+                for _, tag := range topic.Tags {
+                        tagIDs = append(tagIDs, tag.ID)
+                }
+                s.notifyTopic(topic, tagIDs)
+                return nil
+        
+        case policy.KindComment:
+                comment, err := s.discussionRepo.GetCommentByID(contentID)
+                if err != nil {
+                        return err
+                }
+                if comment.IsApproved {
+                        return nil
+                }
+                comment.IsApproved = true
+                if err := s.discussionRepo.UpdateComment(comment); err != nil {
+                        return err
+                }
+                if err := s.discussionRepo.UpdateTopicLastPostTime(comment.TopicID); err != nil {
+                        return err
+                }
+                
+                topic, err := s.discussionRepo.GetTopicByID(comment.TopicID)
+                if err != nil {
+                        return err
+                }
+                var parent *models.Comment
+                if comment.ParentID != nil {
+                        if parent, err = s.discussionRepo.GetCommentByID(*comment.ParentID); err != nil {
+                                parent = nil
+                        }
+                }
+                s.notifyReply(topic, parent, comment)
+                return nil
+        }
+        return fmt.Errorf("cannot approve content of type %q", contentType)
+}
+
+// notifyReply tells the authors of the topic and parent comment about a new
+// comment, then notifies the topic's other instant subscribers
+func (s *DiscussionServiceImpl) notifyReply(topic *models.Topic, parent *models.Comment, comment *models.Comment) {
+        if s.notifier == nil {
+                return
+        }
+        
+        reply := notificationmodels.Notification{
+                Type:          notificationmodels.TypeReply,
+                Body:          excerpt(comment.Content, 140),
+                Link:          contentLink("comment", comment.ID, topic.ID),
+                ActorID:       &comment.UserID,
+                ReferenceType: "comment",
+                ReferenceID:   comment.ID,
+                ScopeType:     "topic",
+                ScopeID:       topic.ID,
+        }
+        reply.WithDedupKey(fmt.Sprintf("comment:%d", comment.ID))
+        
+        notified := []uint{comment.UserID}
+        if parent != nil && parent.UserID != comment.UserID {
+                n := reply
+                n.UserID = parent.UserID
+                n.Title = fmt.Sprintf("New reply to your comment in %s", topic.Title)
+                notify(s.notifier, &n)
+                notified = append(notified, parent.UserID)
+        }
+        if topic.UserID != comment.UserID && (parent == nil || parent.UserID != topic.UserID) {
+                n := reply
+                n.UserID = topic.UserID
+                n.Title = fmt.Sprintf("New reply to %s", topic.Title)
+                notify(s.notifier, &n)
+                notified = append(notified, topic.UserID)
+        }
+        
+        update := reply
+        update.Type = notificationmodels.TypeSubscriptionUpdate
+        update.Title = fmt.Sprintf("New comment in %s", topic.Title)
+        notifySubscribers(s.notifier, "topic", topic.ID, notified, update)
+}
+
 // UpdateComment updates an existing comment
 func (s *DiscussionServiceImpl) UpdateComment(id, userID uint, content string, isAdmin bool) (*models.Comment, error) {
         // Get the comment
@@ -0,0 +1,337 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/yerenwgventures/GreatNigeriaLibrary-Foundation/backend/services/discussion/models"
+	"github.com/yerenwgventures/GreatNigeriaLibrary-Foundation/backend/services/discussion/trust"
+	notificationmodels "github.com/yerenwgventures/GreatNigeriaLibrary-Foundation/backend/services/notification/models"
+)
+
+// hiddenQueuePriority ranks content hidden by flags above ordinary queue items
+const hiddenQueuePriority = 4
+
+// ModerationQueue queues content for a moderator's decision
+type ModerationQueue interface {
+	AddToModerationQueue(contentType string, contentID, userID uint, reason string, filterResultID *uint, priority int) (*models.ModAdvancedQueue, error)
+}
+
+// SetTrustService sets what weighs flags by the flagger's trust level
+func (s *FlagServiceImpl) SetTrustService(trust TrustService) {
+	s.trust = trust
+}
+
+// SetModerationQueue sets where content hidden by flags waits for a moderator
+func (s *FlagServiceImpl) SetModerationQueue(queue ModerationQueue) {
+	s.queue = queue
+}
+
+// GetFlagAggregate retrieves the combined flags on a content item
+func (s *FlagServiceImpl) GetFlagAggregate(contentType string, contentID uint) (*models.FlagAggregate, error) {
+	if contentType != "topic" && contentType != "comment" {
+		return nil, errors.New("invalid content type")
+	}
+
+	return s.flagRepo.GetFlagAggregate(contentType, contentID)
+}
+
+// aggregateFlag adds a new flag's weight to its content's aggregate, and
+// hides the content once the combined weight reaches the threshold. Each
+// user's flags on a content item count once.
+func (s *FlagServiceImpl) aggregateFlag(flag *models.ContentFlag) error {
+	authorID, _, err := s.contentAuthor(flag.ContentType, flag.ContentID)
+	if err != nil {
+		return fmt.Errorf("error getting author: %w", err)
+	}
+
+	flags, err := s.flagRepo.GetFlagsByContent(flag.ContentType, flag.ContentID)
+	if err != nil {
+		return fmt.Errorf("error getting flags: %w", err)
+	}
+	for _, f := range flags {
+		if f.UserID == flag.UserID && f.ID != flag.ID {
+			return nil
+		}
+	}
+
+	// Authors flagging their own posts do not hide them
+	weight := 0.0
+	if flag.UserID != authorID {
+		if weight, err = s.flagWeight(flag.UserID); err != nil {
+			return err
+		}
+	}
+
+	aggregate := &models.FlagAggregate{
+		ContentType: flag.ContentType,
+		ContentID:   flag.ContentID,
+		AuthorID:    authorID,
+		Weight:      weight,
+		Flaggers:    1,
+		Status:      models.FlagAggregateOpen,
+	}
+	if err := s.flagRepo.AddFlagWeight(aggregate); err != nil {
+		return fmt.Errorf("error adding flag weight: %w", err)
+	}
+
+	settings := trust.DefaultSettings()
+	if s.trust != nil {
+		saved, err := s.trust.GetSettings()
+		if err != nil {
+			return fmt.Errorf("error getting trust settings: %w", err)
+		}
+		settings = *saved
+	}
+	if aggregate.Status != models.FlagAggregateOpen || !trust.ShouldHide(settings, aggregate.Weight) {
+		return nil
+	}
+	return s.hideFlaggedContent(aggregate)
+}
+
+// flagWeight returns how much a user's next flag counts: their trust level's
+// flag weight, scaled by how many of their flags moderators upheld
+func (s *FlagServiceImpl) flagWeight(userID uint) (float64, error) {
+	levelWeight := 1.0
+	if s.trust != nil {
+		capabilities, err := s.trust.Capabilities(userID)
+		if err != nil {
+			return 0, fmt.Errorf("error getting capabilities: %w", err)
+		}
+		levelWeight = capabilities.FlagWeight
+	}
+
+	upheld, dismissed, err := s.flagRepo.GetFlagAccuracy(userID)
+	if err != nil {
+		return 0, fmt.Errorf("error getting flag accuracy: %w", err)
+	}
+	return trust.FlagWeight(levelWeight, upheld, dismissed), nil
+}
+
+// hideFlaggedContent hides content whose flags reached the threshold, queues
+// it for a moderator to confirm or reverse the flags, and notifies the author
+func (s *FlagServiceImpl) hideFlaggedContent(aggregate *models.FlagAggregate) error {
+	now := time.Now()
+	hidden, err := s.flagRepo.HideFlagAggregate(aggregate.ID, now)
+	if err != nil {
+		return fmt.Errorf("error hiding flagged content: %w", err)
+	}
+	if !hidden {
+		// Another flag hid it first
+		return nil
+	}
+	aggregate.Status = models.FlagAggregateHidden
+	aggregate.HiddenAt = &now
+
+	if err := s.setContentVisible(aggregate.ContentType, aggregate.ContentID, false); err != nil {
+		return fmt.Errorf("error hiding %s %d: %w", aggregate.ContentType, aggregate.ContentID, err)
+	}
+
+	reason := fmt.Sprintf("Hidden after %d users flagged it (weight %.2f)", aggregate.Flaggers, aggregate.Weight)
+	moderationStatus, err := s.setModerationStatus(aggregate.ContentType, aggregate.ContentID, models.ModerationStatusHidden, nil, reason, "")
+	if err != nil {
+		return err
+	}
+
+	if s.queue != nil {
+		item, err := s.queue.AddToModerationQueue(aggregate.ContentType, aggregate.ContentID, aggregate.AuthorID, reason, nil, hiddenQueuePriority)
+		if err != nil {
+			log.Printf("Error queueing flagged %s %d: %v", aggregate.ContentType, aggregate.ContentID, err)
+		} else {
+			aggregate.QueueItemID = &item.ID
+			if err := s.flagRepo.SaveFlagAggregate(aggregate); err != nil {
+				log.Printf("Error saving flag aggregate %d: %v", aggregate.ID, err)
+			}
+		}
+	}
+
+	s.notifyHidden(aggregate, moderationStatus)
+	return nil
+}
+
+// ResolveFlaggedContent settles the flags on content they hid once a
+// moderator decides on it. Upheld flags keep the content hidden. Otherwise
+// the content is restored and its pending flags are dismissed, which lowers
+// the weight of the flaggers' future flags. Content not hidden by flags is
+// left alone.
+func (s *FlagServiceImpl) ResolveFlaggedContent(contentType string, contentID uint, upheld bool, moderatorID uint) error {
+	aggregate, err := s.GetFlagAggregate(contentType, contentID)
+	if err != nil {
+		return err
+	}
+	if aggregate.ID == 0 || aggregate.Status != models.FlagAggregateHidden {
+		return nil
+	}
+
+	flagStatus, aggregateStatus, status, reason := models.FlagStatusRejected, models.FlagAggregateReversed, models.ModerationStatusApproved, "Restored by a moderator after review of the flags"
+	if upheld {
+		flagStatus, aggregateStatus, status, reason = models.FlagStatusApproved, models.FlagAggregateUpheld, models.ModerationStatusHidden, "A moderator confirmed the flags"
+	}
+
+	if err := s.flagRepo.ResolvePendingFlags(contentType, contentID, flagStatus, moderatorID, reason); err != nil {
+		return fmt.Errorf("error resolving flags: %w", err)
+	}
+
+	now := time.Now()
+	aggregate.Status = aggregateStatus
+	aggregate.ResolvedBy = &moderatorID
+	aggregate.ResolvedAt = &now
+	if err := s.flagRepo.SaveFlagAggregate(aggregate); err != nil {
+		return fmt.Errorf("error saving flag aggregate: %w", err)
+	}
+
+	if !upheld {
+		if err := s.setContentVisible(contentType, contentID, true); err != nil {
+			return fmt.Errorf("error restoring %s %d: %w", contentType, contentID, err)
+		}
+	}
+
+	moderationStatus, err := s.setModerationStatus(contentType, contentID, status, &moderatorID, reason, "")
+	if err != nil {
+		return err
+	}
+	s.notifyAuthor(moderationStatus)
+
+	return nil
+}
+
+// RestoreContent reverses a moderator's decision to hide or reject content,
+// as when an appeal overturns it. The content's flags are dismissed, which
+// lowers the weight of the flaggers' future flags.
+func (s *FlagServiceImpl) RestoreContent(contentType string, contentID uint, moderatorID uint, reason string) error {
+	aggregate, err := s.GetFlagAggregate(contentType, contentID)
+	if err != nil {
+		return err
+	}
+
+	if err := s.flagRepo.DismissFlags(contentType, contentID, moderatorID, reason); err != nil {
+		return fmt.Errorf("error dismissing flags: %w", err)
+	}
+	if aggregate.ID != 0 && aggregate.Status != models.FlagAggregateOpen {
+		now := time.Now()
+		aggregate.Status = models.FlagAggregateReversed
+		aggregate.ResolvedBy = &moderatorID
+		aggregate.ResolvedAt = &now
+		if err := s.flagRepo.SaveFlagAggregate(aggregate); err != nil {
+			return fmt.Errorf("error saving flag aggregate: %w", err)
+		}
+	}
+
+	if err := s.setContentVisible(contentType, contentID, true); err != nil {
+		return fmt.Errorf("error restoring %s %d: %w", contentType, contentID, err)
+	}
+
+	// Teach the spam classifier the post was not abusive after all
+	if s.feedback != nil {
+		if err := s.feedback.LabelContent(contentType, contentID, false, moderatorID); err != nil {
+			log.Printf("Error labelling %s %d: %v", contentType, contentID, err)
+		}
+	}
+
+	moderationStatus, err := s.setModerationStatus(contentType, contentID, models.ModerationStatusApproved, &moderatorID, reason, "")
+	if err != nil {
+		return err
+	}
+	s.notifyAuthor(moderationStatus)
+
+	return nil
+}
+
+// setContentVisible hides a topic or comment from listings, or shows it again
+func (s *FlagServiceImpl) setContentVisible(contentType string, contentID uint, visible bool) error {
+	switch contentType {
+	case "topic":
+		topic, err := s.topicRepo.GetTopicByID(contentID)
+		if err != nil {
+			return err
+		}
+		if topic.IsApproved == visible {
+			return nil
+		}
+		topic.IsApproved = visible
+		return s.topicRepo.UpdateTopic(topic)
+	case "comment":
+		comment, err := s.commentRepo.GetCommentByID(contentID)
+		if err != nil {
+			return err
+		}
+		if comment.IsApproved == visible {
+			return nil
+		}
+		comment.IsApproved = visible
+		return s.commentRepo.UpdateComment(comment)
+	default:
+		return errors.New("invalid content type")
+	}
+}
+
+// setModerationStatus creates or updates a content item's moderation status
+func (s *FlagServiceImpl) setModerationStatus(
+	contentType string,
+	contentID uint,
+	status models.ContentModerationStatusType,
+	moderatorID *uint,
+	reason, notes string,
+) (*models.ContentModerationStatus, error) {
+	now := time.Now()
+	moderationStatus, err := s.flagRepo.GetModerationStatusByContent(contentType, contentID)
+	if err != nil {
+		moderationStatus = &models.ContentModerationStatus{
+			ContentType: contentType,
+			ContentID:   contentID,
+			CreatedAt:   now,
+		}
+	}
+	moderationStatus.Status = status
+	moderationStatus.ModeratorID = moderatorID
+	moderationStatus.Reason = reason
+	if notes != "" {
+		moderationStatus.Notes = notes
+	}
+	moderationStatus.UserNotified = false
+	moderationStatus.UpdatedAt = now
+
+	if moderationStatus.ID == 0 {
+		err = s.flagRepo.CreateModerationStatus(moderationStatus)
+	} else {
+		err = s.flagRepo.UpdateModerationStatus(moderationStatus)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error saving moderation status: %w", err)
+	}
+	return moderationStatus, nil
+}
+
+// notifyHidden tells an author that flags hid their post
+func (s *FlagServiceImpl) notifyHidden(aggregate *models.FlagAggregate, moderationStatus *models.ContentModerationStatus) {
+	if s.notifier == nil {
+		return
+	}
+
+	_, topicID, err := s.contentAuthor(aggregate.ContentType, aggregate.ContentID)
+	if err != nil {
+		log.Printf("Error getting author of %s %d: %v", aggregate.ContentType, aggregate.ContentID, err)
+		return
+	}
+
+	n := &notificationmodels.Notification{
+		UserID:        aggregate.AuthorID,
+		Type:          notificationmodels.TypeModeration,
+		Title:         fmt.Sprintf("Your %s was hidden after members flagged it", aggregate.ContentType),
+		Body:          "A moderator will review it. If they keep it hidden, you can appeal their decision.",
+		Link:          contentLink(aggregate.ContentType, aggregate.ContentID, topicID),
+		ReferenceType: aggregate.ContentType,
+		ReferenceID:   aggregate.ContentID,
+	}
+	n.WithDedupKey(fmt.Sprintf("flags-hidden:%s:%d:%d", aggregate.ContentType, aggregate.ContentID, aggregate.HiddenAt.Unix()))
+	if !notify(s.notifier, n) {
+		return
+	}
+
+	moderationStatus.UserNotified = true
+	if err := s.flagRepo.UpdateModerationStatus(moderationStatus); err != nil {
+		log.Printf("Error marking user notified: %v", err)
+	}
+}
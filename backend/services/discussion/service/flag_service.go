@@ -1,12 +1,18 @@
 package service
 
 import (
+	"context"
 	"errors"
 	"fmt"
+	"log"
 	"time"
 
+	"github.com/yerenwgventures/GreatNigeriaLibrary-Foundation/backend/pkg/common/audit"
+	"github.com/yerenwgventures/GreatNigeriaLibrary-Foundation/backend/services/discussion/appeals"
 	"github.com/yerenwgventures/GreatNigeriaLibrary-Foundation/backend/services/discussion/models"
 	"github.com/yerenwgventures/GreatNigeriaLibrary-Foundation/backend/services/discussion/repository"
+	notificationmodels "github.com/yerenwgventures/GreatNigeriaLibrary-Foundation/backend/services/notification/models"
+	notification "github.com/yerenwgventures/GreatNigeriaLibrary-Foundation/backend/services/notification/service"
 )
 
 // FlagService defines the interface for content flag operations
@@ -26,12 +32,29 @@ type FlagService interface {
 	GetPendingModerationCount() (int64, error)
 	
 	// User penalties
-	ApplyUserPenalty(userID uint, penaltyType models.UserPenaltyType, reason, description string, moderatorID uint, duration *int, relatedContentType *string, relatedContentID *uint, notes string) (*models.UserPenalty, error)
+	ApplyUserPenalty(ctx context.Context, userID uint, penaltyType models.UserPenaltyType, reason, description string, moderatorID uint, duration *int, relatedContentType *string, relatedContentID *uint, notes string) (*models.UserPenalty, error)
 	GetUserPenalties(userID uint) ([]models.UserPenalty, error)
 	GetActivePenalties(userID uint) ([]models.UserPenalty, error)
 	RemovePenalty(penaltyID uint, moderatorID uint, reason string) error
+	ReducePenalty(penaltyID uint, moderatorID uint, days int, reason string) (*models.UserPenalty, error)
 	GetUserDisciplineHistory(userID uint) ([]models.UserPenalty, error)
 	IsUserRestricted(userID uint) (bool, string, error)
+	
+	// Flag aggregation
+	GetFlagAggregate(contentType string, contentID uint) (*models.FlagAggregate, error)
+	ResolveFlaggedContent(contentType string, contentID uint, upheld bool, moderatorID uint) error
+	RestoreContent(contentType string, contentID uint, moderatorID uint, reason string) error
+	
+	// SetNotifier enables in-app notifications for moderation decisions
+	SetNotifier(notifier notification.Notifier)
+	// SetAuditRecorder sets the recorder of penalties applied to users
+	SetAuditRecorder(recorder *audit.Recorder)
+	// SetFeedback sets what learns from reviewed flags
+	SetFeedback(feedback ModerationFeedback)
+	// SetTrustService sets what weighs flags by the flagger's trust level
+	SetTrustService(trust TrustService)
+	// SetModerationQueue sets where content hidden by flags waits for a moderator
+	SetModerationQueue(queue ModerationQueue)
 }
 
 // FlagServiceImpl implements the FlagService interface
@@ -39,6 +62,11 @@ type FlagServiceImpl struct {
 	flagRepo repository.FlagRepository
 	topicRepo repository.TopicRepository
 	commentRepo repository.CommentRepository
+	notifier notification.Notifier
+	audit *audit.Recorder
+	feedback ModerationFeedback
+	trust TrustService
+	queue ModerationQueue
 }
 
 // NewFlagService creates a new flag service
@@ -54,6 +82,21 @@ func NewFlagService(
 	}
 }
 
+// SetNotifier enables in-app notifications for moderation decisions
+func (s *FlagServiceImpl) SetNotifier(notifier notification.Notifier) {
+	s.notifier = notifier
+}
+
+// SetFeedback sets what learns from reviewed flags
+func (s *FlagServiceImpl) SetFeedback(feedback ModerationFeedback) {
+	s.feedback = feedback
+}
+
+// SetAuditRecorder sets the recorder of penalties applied to users
+func (s *FlagServiceImpl) SetAuditRecorder(recorder *audit.Recorder) {
+	s.audit = recorder
+}
+
 // FlagContent flags a content item
 func (s *FlagServiceImpl) FlagContent(
 	contentType string,
@@ -106,6 +149,11 @@ func (s *FlagServiceImpl) FlagContent(
 		}
 	}
 	
+	// Weigh the flag with the others on the content, which may hide it
+	if err := s.aggregateFlag(flag); err != nil {
+		log.Printf("Error aggregating flags on %s %d: %v", contentType, contentID, err)
+	}
+	
 	return flag, nil
 }
 
@@ -198,6 +246,14 @@ func (s *FlagServiceImpl) ReviewFlag(
 		return fmt.Errorf("error updating flag: %w", err)
 	}
 	
+	// Upheld flags teach the spam classifier the post was abusive, dismissed
+	// ones that it was not
+	if s.feedback != nil && status != models.FlagStatusReviewed {
+		if err := s.feedback.LabelContent(flag.ContentType, flag.ContentID, status == models.FlagStatusApproved, moderatorID); err != nil {
+			log.Printf("Error labelling %s %d: %v", flag.ContentType, flag.ContentID, err)
+		}
+	}
+	
 	// If flag is approved, update content moderation status
 	if status == models.FlagStatusApproved {
 		moderationStatus, err := s.flagRepo.GetModerationStatusByContent(flag.ContentType, flag.ContentID)
@@ -229,6 +285,8 @@ func (s *FlagServiceImpl) ReviewFlag(
 				return fmt.Errorf("error updating moderation status: %w", err)
 			}
 		}
+		
+		s.notifyAuthor(moderationStatus)
 	}
 	
 	return nil
@@ -293,6 +351,8 @@ func (s *FlagServiceImpl) CreateOrUpdateModerationStatus(
 		}
 	}
 	
+	s.notifyAuthor(moderationStatus)
+	
 	return moderationStatus, nil
 }
 
@@ -335,6 +395,7 @@ func (s *FlagServiceImpl) GetPendingModerationCount() (int64, error) {
 
 // ApplyUserPenalty applies a penalty to a user
 func (s *FlagServiceImpl) ApplyUserPenalty(
+	ctx context.Context,
 	userID uint, 
 	penaltyType models.UserPenaltyType, 
 	reason, description string, 
@@ -386,6 +447,16 @@ func (s *FlagServiceImpl) ApplyUserPenalty(
 		return nil, fmt.Errorf("error creating user penalty: %w", err)
 	}
 	
+	if err := s.audit.Record(ctx, audit.Event{
+		Action:     "user.penalty_applied",
+		TargetType: "user",
+		TargetID:   userID,
+		ActorID:    moderatorID,
+		After:      penalty,
+	}); err != nil {
+		log.Printf("Error recording penalty %d of user %d: %v", penalty.ID, userID, err)
+	}
+	
 	return penalty, nil
 }
 
@@ -424,6 +495,40 @@ func (s *FlagServiceImpl) RemovePenalty(penaltyID uint, moderatorID uint, reason
 	return nil
 }
 
+// ReducePenalty shortens a penalty to last days from when it was applied.
+// Bans become suspensions.
+func (s *FlagServiceImpl) ReducePenalty(penaltyID uint, moderatorID uint, days int, reason string) (*models.UserPenalty, error) {
+	penalty, err := s.flagRepo.GetPenaltyByID(penaltyID)
+	if err != nil {
+		return nil, fmt.Errorf("error getting penalty: %w", err)
+	}
+	
+	expiresAt, err := appeals.Reduce(appeals.Decision{
+		Type:      models.AppealPenalty,
+		Kind:      string(penalty.PenaltyType),
+		DecidedAt: penalty.CreatedAt,
+		ExpiresAt: penalty.ExpiresAt,
+	}, days)
+	if err != nil {
+		return nil, err
+	}
+	
+	if penalty.PenaltyType == models.PenaltyTypeBan {
+		penalty.PenaltyType = models.PenaltyTypeSuspension
+	}
+	penalty.Duration = &days
+	penalty.ExpiresAt = &expiresAt
+	penalty.Notes += "\n\nReduced to " + fmt.Sprintf("%d days", days) + " by moderator ID " + fmt.Sprintf("%d", moderatorID) + " on " + 
+		time.Now().Format(time.RFC3339) + " with reason: " + reason
+	penalty.UpdatedAt = time.Now()
+	
+	if err := s.flagRepo.UpdateUserPenalty(penalty); err != nil {
+		return nil, fmt.Errorf("error updating penalty: %w", err)
+	}
+	
+	return penalty, nil
+}
+
 // GetUserDisciplineHistory retrieves the discipline history for a user
 func (s *FlagServiceImpl) GetUserDisciplineHistory(userID uint) ([]models.UserPenalty, error) {
 	return s.flagRepo.GetUserPenalties(userID)
@@ -455,6 +560,60 @@ func (s *FlagServiceImpl) IsUserRestricted(userID uint) (bool, string, error) {
 
 // Helper functions
 
+// contentAuthor returns the author of a topic or comment, and the topic ID
+// for comments
+func (s *FlagServiceImpl) contentAuthor(contentType string, contentID uint) (uint, uint, error) {
+	switch contentType {
+	case "topic":
+		topic, err := s.topicRepo.GetTopicByID(contentID)
+		if err != nil {
+			return 0, 0, err
+		}
+		return topic.UserID, 0, nil
+	case "comment":
+		comment, err := s.commentRepo.GetCommentByID(contentID)
+		if err != nil {
+			return 0, 0, err
+		}
+		return comment.UserID, comment.TopicID, nil
+	default:
+		return 0, 0, errors.New("invalid content type")
+	}
+}
+
+// notifyAuthor tells the author of moderated content about the decision and
+// records that they were notified. Pending statuses are not announced.
+func (s *FlagServiceImpl) notifyAuthor(moderationStatus *models.ContentModerationStatus) {
+	if s.notifier == nil || moderationStatus.Status == models.ModerationStatusPending {
+		return
+	}
+	
+	authorID, topicID, err := s.contentAuthor(moderationStatus.ContentType, moderationStatus.ContentID)
+	if err != nil {
+		fmt.Printf("Error getting author of %s %d: %v\n", moderationStatus.ContentType, moderationStatus.ContentID, err)
+		return
+	}
+	
+	n := &notificationmodels.Notification{
+		UserID:        authorID,
+		Type:          notificationmodels.TypeModeration,
+		Title:         fmt.Sprintf("Your %s was %s by a moderator", moderationStatus.ContentType, moderationStatus.Status),
+		Body:          moderationStatus.Reason,
+		Link:          contentLink(moderationStatus.ContentType, moderationStatus.ContentID, topicID),
+		ReferenceType: moderationStatus.ContentType,
+		ReferenceID:   moderationStatus.ContentID,
+	}
+	n.WithDedupKey(fmt.Sprintf("moderation:%s:%d:%s:%d", moderationStatus.ContentType, moderationStatus.ContentID, moderationStatus.Status, moderationStatus.UpdatedAt.Unix()))
+	if !notify(s.notifier, n) {
+		return
+	}
+	
+	moderationStatus.UserNotified = true
+	if err := s.flagRepo.UpdateModerationStatus(moderationStatus); err != nil {
+		fmt.Printf("Error marking user notified: %v\n", err)
+	}
+}
+
 // validateContent validates that content exists
 func (s *FlagServiceImpl) validateContent(contentType string, contentID uint) error {
 	if contentType == "topic" {
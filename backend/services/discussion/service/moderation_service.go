@@ -1,15 +1,20 @@
 package service
 
 import (
+        "context"
         "encoding/json"
         "errors"
         "fmt"
+        "log"
         "regexp"
         "strings"
         "time"
 
+        "github.com/yerenwgventures/GreatNigeriaLibrary-Foundation/backend/pkg/common/audit"
+        "github.com/yerenwgventures/GreatNigeriaLibrary-Foundation/backend/services/discussion/appeals"
         "github.com/yerenwgventures/GreatNigeriaLibrary-Foundation/backend/services/discussion/models"
         "github.com/yerenwgventures/GreatNigeriaLibrary-Foundation/backend/services/discussion/repository"
+        "github.com/yerenwgventures/GreatNigeriaLibrary-Foundation/backend/services/discussion/trust"
 )
 
 // ModerationService defines the interface for moderation operations
@@ -25,6 +30,7 @@ type ModerationService interface {
         GetFilterResults(contentType string, contentID uint) ([]models.ContentFilterResult, error)
         ReviewFilterResult(resultID uint, action models.ModerationAction, userID uint) error
         GetUserFilterResults(userID uint, page, pageSize int) ([]models.ContentFilterResult, error)
+        RecordFilterResult(result *models.ContentFilterResult, contentID uint) error
         
         // Moderation queue
         AddToModerationQueue(contentType string, contentID, userID uint, reason string, filterResultID *uint, priority int) (*models.ModAdvancedQueue, error)
@@ -40,7 +46,7 @@ type ModerationService interface {
         GetUsersByTrustLevel(level models.UserTrustLevel) ([]models.UserTrustScore, error)
         
         // Moderator management
-        GrantModeratorPrivileges(userID, grantedByID uint, privileges map[string]bool) (*models.ModeratorPrivilege, error)
+        GrantModeratorPrivileges(ctx context.Context, userID, grantedByID uint, privileges map[string]bool) (*models.ModeratorPrivilege, error)
         UpdateModeratorPrivileges(userID, updatedByID uint, privileges map[string]bool) (*models.ModeratorPrivilege, error)
         RevokeModeratorPrivileges(userID, revokedByID uint) error
         GetModeratorPrivileges(userID uint) (*models.ModeratorPrivilege, error)
@@ -51,6 +57,7 @@ type ModerationService interface {
         CreateUserModerationAction(userID uint, actionType models.ModerationAction, reason string, duration int, relatedContentID *uint, relatedContentType *string, moderatorID uint, notes string) (*models.UserModerationAction, error)
         GetUserModerationActions(userID uint) ([]models.UserModerationAction, error)
         RevokeUserModerationAction(actionID, moderatorID uint, reason string) error
+        ReduceUserModerationAction(actionID, moderatorID uint, days int, reason string) (*models.UserModerationAction, error)
         GetActiveUserActions(userID uint) ([]models.UserModerationAction, error)
         IsUserBanned(userID uint) (bool, error)
         
@@ -60,12 +67,50 @@ type ModerationService interface {
         UpdateProhibitedWord(wordID uint, word, replacement string, isRegex, isAutoReplace, isActive bool, severity int, userID uint) (*models.ProhibitedWord, error)
         DeleteProhibitedWord(wordID, userID uint) error
         FilterTextWithProhibitedWords(text string) (string, bool, []string, error)
+        
+        // SetAuditRecorder sets the recorder of moderator privilege grants
+        SetAuditRecorder(recorder *audit.Recorder)
+        
+        // SetContentApprover sets what publishes topics and comments approved in the queue
+        SetContentApprover(approver ContentApprover)
+        
+        // SetFeedback sets what learns from decisions on queued topics and comments
+        SetFeedback(feedback ModerationFeedback)
+        
+        // SetTrustService sets where trust level thresholds are configured
+        SetTrustService(trust TrustService)
+        
+        // SetFlagResolver sets what settles the flags on content they hid
+        SetFlagResolver(resolver FlaggedContentResolver)
+}
+
+// ContentApprover publishes content held for approval once a moderator approves it
+type ContentApprover interface {
+        ApproveContent(contentType string, contentID uint) error
+}
+
+// FlaggedContentResolver settles the flags on content they hid once a
+// moderator decides on it in the queue. upheld is true when the moderator
+// removed the content.
+type FlaggedContentResolver interface {
+        ResolveFlaggedContent(contentType string, contentID uint, upheld bool, moderatorID uint) error
+}
+
+// ModerationFeedback learns from moderators' decisions on topics and comments.
+// spam is true when the moderator removed the post.
+type ModerationFeedback interface {
+        LabelContent(contentType string, contentID uint, spam bool, moderatorID uint) error
 }
 
 // ModerationServiceImpl implements the ModerationService interface
 type ModerationServiceImpl struct {
         moderationRepo repository.ModerationRepository
         userRepo       repository.UserRepository
+        audit          *audit.Recorder
+        approver       ContentApprover
+        feedback       ModerationFeedback
+        trust          TrustService
+        flags          FlaggedContentResolver
 }
 
 // NewModerationService creates a new moderation service
@@ -79,6 +124,48 @@ func NewModerationService(
         }
 }
 
+// SetAuditRecorder sets the recorder of moderator privilege grants
+func (s *ModerationServiceImpl) SetAuditRecorder(recorder *audit.Recorder) {
+        s.audit = recorder
+}
+
+// SetContentApprover sets what publishes topics and comments approved in the queue
+func (s *ModerationServiceImpl) SetContentApprover(approver ContentApprover) {
+        s.approver = approver
+}
+
+// SetFeedback sets what learns from decisions on queued topics and comments
+func (s *ModerationServiceImpl) SetFeedback(feedback ModerationFeedback) {
+        s.feedback = feedback
+}
+
+// SetTrustService sets where trust level thresholds are configured
+func (s *ModerationServiceImpl) SetTrustService(trust TrustService) {
+        s.trust = trust
+}
+
+// SetFlagResolver sets what settles the flags on content they hid
+func (s *ModerationServiceImpl) SetFlagResolver(resolver FlaggedContentResolver) {
+        s.flags = resolver
+}
+
+// recordPrivilegeGrant records a grant of moderator privileges in the audit log
+func (s *ModerationServiceImpl) recordPrivilegeGrant(ctx context.Context, grantedByID uint, before, after *models.ModeratorPrivilege) {
+        event := audit.Event{
+                Action:     "moderator.privileges_granted",
+                TargetType: "user",
+                TargetID:   after.UserID,
+                ActorID:    grantedByID,
+                After:      after,
+        }
+        if before != nil {
+                event.Before = before
+        }
+        if err := s.audit.Record(ctx, event); err != nil {
+                log.Printf("Error recording moderator privileges of user %d: %v", after.UserID, err)
+        }
+}
+
 // CreateModerationRule creates a new content moderation rule
 func (s *ModerationServiceImpl) CreateModerationRule(
         name, description, pattern, patternType string, 
@@ -337,6 +424,13 @@ func (s *ModerationServiceImpl) GetUserFilterResults(userID uint, page, pageSize
         return s.moderationRepo.GetFilterResultsByUser(userID, pageSize, offset)
 }
 
+// RecordFilterResult saves the result of FilterContent against the content
+// it was run on, once that content has been saved and has an ID
+func (s *ModerationServiceImpl) RecordFilterResult(result *models.ContentFilterResult, contentID uint) error {
+        result.ContentID = contentID
+        return s.moderationRepo.CreateFilterResult(result)
+}
+
 // AddToModerationQueue adds an item to the moderation queue
 func (s *ModerationServiceImpl) AddToModerationQueue(
         contentType string, 
@@ -496,6 +590,30 @@ func (s *ModerationServiceImpl) ResolveModerationItem(itemID uint, decision, not
                 }
         }
         
+        // Settle the flags on content they hid, restoring it if approved
+        if s.flags != nil && (item.ContentType == "topic" || item.ContentType == "comment") {
+                if err := s.flags.ResolveFlaggedContent(item.ContentType, item.ContentID, decision == "rejected", userID); err != nil {
+                        // Log error but continue
+                        fmt.Printf("Error resolving flags on %s %d: %v\n", item.ContentType, item.ContentID, err)
+                }
+        }
+        
+        // Publish held topics and comments
+        if decision == "approved" && s.approver != nil && (item.ContentType == "topic" || item.ContentType == "comment") {
+                if err := s.approver.ApproveContent(item.ContentType, item.ContentID); err != nil {
+                        // Log error but continue
+                        fmt.Printf("Error publishing approved %s %d: %v\n", item.ContentType, item.ContentID, err)
+                }
+        }
+        
+        // Teach the spam classifier
+        if s.feedback != nil && (item.ContentType == "topic" || item.ContentType == "comment") {
+                if err := s.feedback.LabelContent(item.ContentType, item.ContentID, decision == "rejected", userID); err != nil {
+                        // Log error but continue
+                        fmt.Printf("Error labelling %s %d: %v\n", item.ContentType, item.ContentID, err)
+                }
+        }
+        
         // Update user trust score
         if decision == "rejected" {
                 trustScore, err := s.moderationRepo.GetUserTrustScore(item.UserID)
@@ -572,9 +690,9 @@ func (s *ModerationServiceImpl) UpdateUserTrustScore(
         score.LastScoreUpdate = time.Now()
         score.UpdatedAt = time.Now()
         
-        // Calculate overall trust score
-        // Simple weighted average: 40% content, 40% community, 20% moderator
-        score.TrustScore = (0.4 * contentScore) + (0.4 * communityScore) + (0.2 * moderatorScore)
+        // Calculate overall trust score the way the trust engine does
+        score.TrustScore = trust.Combine(contentScore, communityScore, moderatorScore, score.ManualAdjustment)
+        score.Score = score.TrustScore
         
         // Update the score
         if err := s.moderationRepo.UpdateUserTrustScore(score); err != nil {
@@ -605,39 +723,17 @@ func (s *ModerationServiceImpl) RecalculateUserTrustLevel(userID uint) (models.U
                 return "", fmt.Errorf("error getting user trust score: %w", err)
         }
         
-        // Calculate new level based on score and other factors
-        var newLevel models.UserTrustLevel
-        
-        // Thresholds and penalties
-        const (
-                BasicThreshold   = 20.0
-                MemberThreshold  = 50.0
-                RegularThreshold = 75.0
-                LeaderThreshold  = 90.0
-                
-                ReportPenalty      = -5.0
-                WarningPenalty     = -10.0
-                RejectionPenalty   = -2.0
-        )
-        
-        // Apply penalties
-        adjustedScore := score.TrustScore
-        adjustedScore += float64(score.ReportCount) * ReportPenalty
-        adjustedScore += float64(score.WarningCount) * WarningPenalty
-        adjustedScore += float64(score.ContentRejections) * RejectionPenalty
-        
-        // Determine level
-        if adjustedScore >= LeaderThreshold && score.ContentRejections == 0 && score.WarningCount == 0 {
-                newLevel = models.TrustLevelLeader
-        } else if adjustedScore >= RegularThreshold {
-                newLevel = models.TrustLevelRegular
-        } else if adjustedScore >= MemberThreshold {
-                newLevel = models.TrustLevelMember
-        } else if adjustedScore >= BasicThreshold {
-                newLevel = models.TrustLevelBasic
-        } else {
-                newLevel = models.TrustLevelNewUser
+        // Promote or demote by the trust engine's thresholds. The trust score
+        // already accounts for reports, warnings and rejections.
+        settings := trust.DefaultSettings()
+        if s.trust != nil {
+                current, err := s.trust.GetSettings()
+                if err != nil {
+                        return "", err
+                }
+                settings = *current
         }
+        newLevel := trust.Decide(settings, score.TrustLevel, score.TrustScore, trust.CleanScore(score))
         
         // Update level if it changed
         if newLevel != score.TrustLevel {
@@ -659,6 +755,7 @@ func (s *ModerationServiceImpl) GetUsersByTrustLevel(level models.UserTrustLevel
 
 // GrantModeratorPrivileges grants moderator privileges to a user
 func (s *ModerationServiceImpl) GrantModeratorPrivileges(
+        ctx context.Context,
         userID, grantedByID uint, 
         privileges map[string]bool,
 ) (*models.ModeratorPrivilege, error) {
@@ -671,6 +768,7 @@ func (s *ModerationServiceImpl) GrantModeratorPrivileges(
                 }
                 
                 // Reactivate and update privileges
+                before := *existingPrivileges
                 existingPrivileges.IsActive = true
                 existingPrivileges.UpdatedAt = time.Now()
                 
@@ -704,6 +802,7 @@ func (s *ModerationServiceImpl) GrantModeratorPrivileges(
                         return nil, fmt.Errorf("error updating moderator privileges: %w", err)
                 }
                 
+                s.recordPrivilegeGrant(ctx, grantedByID, &before, existingPrivileges)
                 return existingPrivileges, nil
         }
         
@@ -728,6 +827,7 @@ func (s *ModerationServiceImpl) GrantModeratorPrivileges(
                 return nil, fmt.Errorf("error creating moderator privileges: %w", err)
         }
         
+        s.recordPrivilegeGrant(ctx, grantedByID, nil, newPrivileges)
         return newPrivileges, nil
 }
 
@@ -918,13 +1018,52 @@ func (s *ModerationServiceImpl) RevokeUserModerationAction(actionID, moderatorID
         }
         
         // Append revocation reason to notes
+        now := time.Now()
         if reason != "" {
                 action.Notes += "\n\nRevoked by moderator ID " + fmt.Sprintf("%d", moderatorID) + " on " + 
-                        time.Now().Format(time.RFC3339) + " with reason: " + reason
+                        now.Format(time.RFC3339) + " with reason: " + reason
         }
         
         // Deactivate the action
-        return s.moderationRepo.DeactivateUserModerationAction(actionID)
+        action.IsActive = false
+        action.RevokedBy = &moderatorID
+        action.RevokedAt = &now
+        action.RevocationReason = reason
+        
+        return s.moderationRepo.UpdateUserModerationAction(action)
+}
+
+// ReduceUserModerationAction shortens a moderation action to last days from
+// when it was applied. Permanent bans become temporary.
+func (s *ModerationServiceImpl) ReduceUserModerationAction(actionID, moderatorID uint, days int, reason string) (*models.UserModerationAction, error) {
+        action, err := s.moderationRepo.GetUserModerationActionByID(actionID)
+        if err != nil {
+                return nil, fmt.Errorf("error getting user moderation action: %w", err)
+        }
+        
+        expiresAt, err := appeals.Reduce(appeals.Decision{
+                Type:      models.AppealModerationAction,
+                Kind:      action.ActionType,
+                DecidedAt: action.AppliedAt,
+                ExpiresAt: action.ExpiresAt,
+        }, days)
+        if err != nil {
+                return nil, err
+        }
+        
+        if action.ActionType == string(models.ActionPermanentBan) {
+                action.ActionType = string(models.ActionTemporaryBan)
+        }
+        action.Duration = &days
+        action.ExpiresAt = &expiresAt
+        action.Notes += "\n\nReduced to " + fmt.Sprintf("%d days", days) + " by moderator ID " + fmt.Sprintf("%d", moderatorID) + " on " + 
+                time.Now().Format(time.RFC3339) + " with reason: " + reason
+        
+        if err := s.moderationRepo.UpdateUserModerationAction(action); err != nil {
+                return nil, fmt.Errorf("error updating user moderation action: %w", err)
+        }
+        
+        return action, nil
 }
 
 // GetActiveUserActions retrieves active moderation actions for a user
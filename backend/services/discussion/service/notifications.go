@@ -0,0 +1,58 @@
+package service
+
+import (
+        "fmt"
+        "strings"
+
+        notificationmodels "github.com/yerenwgventures/GreatNigeriaLibrary-Foundation/backend/services/notification/models"
+        notification "github.com/yerenwgventures/GreatNigeriaLibrary-Foundation/backend/services/notification/service"
+)
+
+// notify sends an in-app notification. A missing notifier or a delivery
+// failure never fails the action that triggered the notification.
+func notify(notifier notification.Notifier, n *notificationmodels.Notification) bool {
+        if notifier == nil || n.UserID == 0 {
+                return false
+        }
+        if err := notifier.Notify(n); err != nil {
+                fmt.Printf("Error sending %s notification to user %d: %v\n", n.Type, n.UserID, err)
+                return false
+        }
+        return true
+}
+
+// notifySubscribers sends an in-app notification to the instant subscribers
+// of a topic, category or tag
+func notifySubscribers(notifier notification.Notifier, scopeType string, scopeID uint, exclude []uint, n notificationmodels.Notification) {
+        if notifier == nil {
+                return
+        }
+        if _, err := notifier.NotifySubscribers(scopeType, scopeID, exclude, n); err != nil {
+                fmt.Printf("Error notifying %s %d subscribers: %v\n", scopeType, scopeID, err)
+        }
+}
+
+// contentLink returns the frontend path of a topic or comment
+func contentLink(contentType string, contentID uint, topicID uint) string {
+        switch contentType {
+        case "topic":
+                return fmt.Sprintf("/discussions/%d", contentID)
+        case "comment":
+                if topicID != 0 {
+                        return fmt.Sprintf("/discussions/%d#comment-%d", topicID, contentID)
+                }
+                return fmt.Sprintf("/comments/%d", contentID)
+        default:
+                return ""
+        }
+}
+
+// excerpt flattens and shortens content for a notification body
+func excerpt(content string, max int) string {
+        content = strings.Join(strings.Fields(content), " ")
+        runes := []rune(content)
+        if len(runes) <= max {
+                return content
+        }
+        return strings.TrimSpace(string(runes[:max-3])) + "..."
+}
@@ -0,0 +1,211 @@
+package service
+
+import (
+        "log"
+        "strings"
+        "time"
+
+        "github.com/yerenwgventures/GreatNigeriaLibrary-Foundation/backend/services/discussion/models"
+        "github.com/yerenwgventures/GreatNigeriaLibrary-Foundation/backend/services/discussion/policy"
+        "github.com/yerenwgventures/GreatNigeriaLibrary-Foundation/backend/services/discussion/repository"
+)
+
+// Queue priorities of posts held for approval
+const (
+        approvalPriority = 3
+        // Posts matching moderation rules are reviewed first
+        filterPriority = 4
+)
+
+// PostingPolicy enforces a category's CategoryConfig, PostingRules and
+// AutoModerationSettings on new topics and comments
+type PostingPolicy struct {
+        discussionRepo repository.DiscussionRepository
+        moderation     ModerationService
+        flags          FlagService
+        spam           SpamService
+        trust          TrustService
+}
+
+// NewPostingPolicy creates a posting policy. The flag service is optional.
+func NewPostingPolicy(discussionRepo repository.DiscussionRepository, moderation ModerationService, flags FlagService) *PostingPolicy {
+        return &PostingPolicy{
+                discussionRepo: discussionRepo,
+                moderation:     moderation,
+                flags:          flags,
+        }
+}
+
+// SetSpamService scores new posts for spam and abuse, holding those that
+// reach their category's thresholds
+func (p *PostingPolicy) SetSpamService(spam SpamService) {
+        p.spam = spam
+}
+
+// SetTrustService refuses links from users whose trust level does not allow
+// them yet
+func (p *PostingPolicy) SetTrustService(trust TrustService) {
+        p.trust = trust
+}
+
+// PostingCheck is the outcome of checking a post before it is saved
+type PostingCheck struct {
+        Decision *policy.Decision
+        // Filter is the unsaved result of the moderation rules, if any matched
+        Filter *models.ContentFilterResult
+        // Classification is the unsaved spam score of the post, if it was scored
+        Classification *models.PostClassification
+}
+
+// Check decides whether a user may post in a category. Banned and restricted
+// users get a DiscussionError; posts that break the category's rules get
+// *errors.ValidationErrors. When the moderation rules filter the post
+// automatically, its title and content are replaced with the cleaned text.
+func (p *PostingPolicy) Check(userID, categoryID uint, post *policy.Post) (*PostingCheck, error) {
+        banned, err := p.moderation.IsUserBanned(userID)
+        if err != nil {
+                return nil, err
+        }
+        if banned {
+                return nil, models.ErrUserBanned
+        }
+        if p.flags != nil {
+                restricted, reason, err := p.flags.IsUserRestricted(userID)
+                if err != nil {
+                        return nil, err
+                }
+                if restricted {
+                        if reason == "" {
+                                return nil, models.ErrPostingRestricted
+                        }
+                        return nil, models.DiscussionError{Code: models.ErrPostingRestricted.Code, Message: reason}
+                }
+        }
+
+        rules, err := p.rules(categoryID)
+        if err != nil {
+                return nil, err
+        }
+        author, err := p.author(userID)
+        if err != nil {
+                return nil, err
+        }
+
+        check := &PostingCheck{Decision: policy.Evaluate(rules, *post, author, time.Now())}
+
+        // Moderators are trusted not to need the moderation rules
+        if !author.Moderator && rules.TextFilters() {
+                text := post.Content
+                if post.Kind == policy.KindTopic {
+                        text = post.Title + "\n" + post.Content
+                }
+                result, err := p.moderation.FilterContent(text, post.Kind, userID)
+                if err != nil {
+                        return nil, err
+                }
+                policy.ApplyFilter(check.Decision, result)
+                check.Filter = result
+                if result != nil && result.Action == models.ActionAutomaticFilter {
+                        cleaned := result.CleanedContent
+                        if post.Kind == policy.KindTopic {
+                                if parts := strings.SplitN(cleaned, "\n", 2); len(parts) == 2 {
+                                        post.Title, cleaned = parts[0], parts[1]
+                                }
+                        }
+                        post.Content = cleaned
+                }
+        }
+
+        if !check.Decision.Allowed() {
+                return nil, check.Decision.Violations
+        }
+
+        // Every post is scored so moderators' decisions can train the
+        // classifier, but only categories that enable it act on the scores
+        if p.spam != nil {
+                classification, err := p.spam.ScorePost(post.Kind, userID, author.TrustLevel, post.Title, post.Content)
+                if err != nil {
+                        // Scoring is advisory; post without it
+                        log.Printf("Error scoring %s by user %d: %v", post.Kind, userID, err)
+                } else {
+                        check.Classification = classification
+                        policy.ApplyScores(check.Decision, rules, author, policy.Scores{
+                                Spam:       classification.SpamScore,
+                                Negativity: classification.NegativityScore,
+                        })
+                }
+        }
+        return check, nil
+}
+
+// rules loads the posting configuration of a category
+func (p *PostingPolicy) rules(categoryID uint) (*policy.Rules, error) {
+        config, err := p.discussionRepo.GetCategoryConfig(categoryID)
+        if err != nil {
+                return nil, err
+        }
+        posting, err := p.discussionRepo.GetPostingRules(categoryID)
+        if err != nil {
+                return nil, err
+        }
+        autoMod, err := p.discussionRepo.GetAutoModerationSettings(categoryID)
+        if err != nil {
+                return nil, err
+        }
+        return &policy.Rules{Config: *config, Posting: *posting, AutoMod: *autoMod}, nil
+}
+
+// author describes the user posting
+func (p *PostingPolicy) author(userID uint) (policy.Author, error) {
+        author := policy.Author{TrustLevel: models.TrustLevelNewUser}
+
+        // Users without a trust score are new
+        if score, err := p.moderation.GetUserTrustScore(userID); err == nil {
+                author.TrustLevel = score.TrustLevel
+        }
+
+        moderator, err := p.moderation.IsUserModerator(userID)
+        if err != nil {
+                return author, err
+        }
+        author.Moderator = moderator
+        
+        if p.trust != nil && !moderator {
+                capabilities, err := p.trust.Capabilities(userID)
+                if err != nil {
+                        return author, err
+                }
+                author.NoLinks = !capabilities.PostLinks
+        }
+
+        author.LastPostAt, err = p.discussionRepo.GetLastPostTime(userID)
+        return author, err
+}
+
+// Record saves the filter result of a post against its ID and queues the post
+// for approval if it was held. Failures are logged; the post is already saved.
+func (p *PostingPolicy) Record(check *PostingCheck, kind string, contentID, userID uint) {
+        var filterResultID *uint
+        priority := approvalPriority
+        if check.Filter != nil {
+                if err := p.moderation.RecordFilterResult(check.Filter, contentID); err != nil {
+                        log.Printf("Error recording filter result of %s %d: %v", kind, contentID, err)
+                } else {
+                        filterResultID = &check.Filter.ID
+                }
+                priority = filterPriority
+        }
+        if check.Classification != nil {
+                if err := p.spam.RecordClassification(check.Classification, contentID); err != nil {
+                        log.Printf("Error recording classification of %s %d: %v", kind, contentID, err)
+                }
+        }
+
+        if !check.Decision.NeedsApproval() {
+                return
+        }
+        reason := strings.Join(check.Decision.Approval, "; ")
+        if _, err := p.moderation.AddToModerationQueue(kind, contentID, userID, reason, filterResultID, priority); err != nil {
+                log.Printf("Error queueing %s %d for approval: %v", kind, contentID, err)
+        }
+}
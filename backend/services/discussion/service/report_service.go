@@ -7,6 +7,8 @@ import (
 
         "github.com/yerenwgventures/GreatNigeriaLibrary-Foundation/backend/services/discussion/models"
         "github.com/yerenwgventures/GreatNigeriaLibrary-Foundation/backend/services/discussion/repository"
+        notificationmodels "github.com/yerenwgventures/GreatNigeriaLibrary-Foundation/backend/services/notification/models"
+        notification "github.com/yerenwgventures/GreatNigeriaLibrary-Foundation/backend/services/notification/service"
 )
 
 // ReportService defines the interface for content report operations
@@ -41,6 +43,9 @@ type ReportService interface {
         
         // Action log operations
         GetActionLogs(reportID uint) ([]models.ReportActionLog, error)
+        
+        // SetNotifier enables in-app notifications for resolved reports
+        SetNotifier(notifier notification.Notifier)
 }
 
 // ReportServiceImpl implements the ReportService interface
@@ -49,6 +54,7 @@ type ReportServiceImpl struct {
         userRepo       repository.UserRepository
         discussionRepo repository.DiscussionRepository
         commentRepo    repository.CommentRepository
+        notifier       notification.Notifier
 }
 
 // NewReportService creates a new report service
@@ -66,6 +72,11 @@ func NewReportService(
         }
 }
 
+// SetNotifier enables in-app notifications for resolved reports
+func (s *ReportServiceImpl) SetNotifier(notifier notification.Notifier) {
+        s.notifier = notifier
+}
+
 // CreateReport creates a new content report
 func (s *ReportServiceImpl) CreateReport(
         reporterID uint,
@@ -323,6 +334,22 @@ func (s *ReportServiceImpl) ResolveReport(reportID uint, resolution models.Repor
                 fmt.Printf("Error adding action log: %v\n", err)
         }
         
+        // Let the reporter know the outcome
+        n := &notificationmodels.Notification{
+                UserID:        report.ReporterID,
+                Type:          notificationmodels.TypeReportResolved,
+                Title:         fmt.Sprintf("Your report about a %s has been resolved", report.ContentType),
+                Body:          fmt.Sprintf("Outcome: %s", resolution),
+                ReferenceType: "report",
+                ReferenceID:   reportID,
+        }
+        n.WithDedupKey(fmt.Sprintf("report:%d:resolved", reportID))
+        if notify(s.notifier, n) {
+                if err := s.reportRepo.MarkReporterNotified(reportID); err != nil {
+                        fmt.Printf("Error marking reporter notified: %v\n", err)
+                }
+        }
+        
         return nil
 }
 
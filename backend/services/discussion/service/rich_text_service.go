@@ -9,6 +9,8 @@ import (
 
         "github.com/yerenwgventures/GreatNigeriaLibrary-Foundation/backend/services/discussion/models"
         "github.com/yerenwgventures/GreatNigeriaLibrary-Foundation/backend/services/discussion/repository"
+        notificationmodels "github.com/yerenwgventures/GreatNigeriaLibrary-Foundation/backend/services/notification/models"
+        notification "github.com/yerenwgventures/GreatNigeriaLibrary-Foundation/backend/services/notification/service"
 )
 
 // RichTextService defines the interface for rich text operations
@@ -37,12 +39,20 @@ type RichTextService interface {
         CreateQuote(contentID uint, contentType string, quotedContentID uint, quotedType string, quotedUserID uint, quotedUsername string, quotedContent string, position int) (*models.Quote, error)
         GetQuotesByContent(contentID uint, contentType string) ([]models.Quote, error)
         DeleteQuote(quoteID uint) error
+        
+        // SetNotifier enables in-app notifications for mentions
+        SetNotifier(notifier notification.Notifier)
+        
+        // SetTrustService refuses attachments from users whose trust level does not allow them yet
+        SetTrustService(trust TrustService)
 }
 
 // RichTextServiceImpl implements the RichTextService interface
 type RichTextServiceImpl struct {
         richTextRepo repository.RichTextRepository
         userRepo     repository.UserRepository
+        notifier     notification.Notifier
+        trust        TrustService
 }
 
 // NewRichTextService creates a new rich text service
@@ -56,6 +66,16 @@ func NewRichTextService(
         }
 }
 
+// SetNotifier enables in-app notifications for mentions
+func (s *RichTextServiceImpl) SetNotifier(notifier notification.Notifier) {
+        s.notifier = notifier
+}
+
+// SetTrustService refuses attachments from users whose trust level does not allow them yet
+func (s *RichTextServiceImpl) SetTrustService(trust TrustService) {
+        s.trust = trust
+}
+
 // CreateOrUpdateRichText creates or updates rich text content
 func (s *RichTextServiceImpl) CreateOrUpdateRichText(
         contentID uint,
@@ -214,6 +234,20 @@ func (s *RichTextServiceImpl) ProcessMentions(contentID uint, contentType string
                         continue
                 }
                 
+                // Mentions are recreated whenever the content is edited, so the
+                // dedup key keeps the user from being notified twice
+                n := &notificationmodels.Notification{
+                        UserID:        user.ID,
+                        Type:          notificationmodels.TypeMention,
+                        Title:         "You were mentioned",
+                        Body:          excerpt(rawContent, 140),
+                        Link:          contentLink(contentType, contentID, 0),
+                        ReferenceType: contentType,
+                        ReferenceID:   contentID,
+                }
+                n.WithDedupKey(fmt.Sprintf("mention:%s:%d", contentType, contentID))
+                notify(s.notifier, n)
+                
                 mentions = append(mentions, mention)
         }
         
@@ -246,6 +280,16 @@ func (s *RichTextServiceImpl) CreateAttachment(
         width int,
         height int,
 ) (*models.Attachment, error) {
+        if s.trust != nil {
+                capabilities, err := s.trust.Capabilities(userID)
+                if err != nil {
+                        return nil, fmt.Errorf("failed to check trust level: %w", err)
+                }
+                if !capabilities.Attachments {
+                        return nil, models.ErrAttachmentsRestricted
+                }
+        }
+        
         attachment := &models.Attachment{
                 ContentID:    contentID,
                 ContentType:  contentType,
@@ -0,0 +1,257 @@
+package service
+
+import (
+        "encoding/json"
+        "errors"
+        "fmt"
+        "log"
+        "strings"
+        "sync"
+        "time"
+
+        "github.com/yerenwgventures/GreatNigeriaLibrary-Foundation/backend/services/discussion/classifier"
+        "github.com/yerenwgventures/GreatNigeriaLibrary-Foundation/backend/services/discussion/models"
+        "github.com/yerenwgventures/GreatNigeriaLibrary-Foundation/backend/services/discussion/repository"
+        "gorm.io/gorm"
+)
+
+const (
+        // MaxTrainingExamples caps the labelled posts a model is trained on,
+        // most recently labelled first
+        MaxTrainingExamples = 50000
+
+        // DefaultSpamThreshold is the spam score new models are evaluated at. It
+        // matches the default AutoModerationSettings.SpamScoreThreshold.
+        DefaultSpamThreshold = 0.7
+
+        // trainingFolds is the number of folds new models are cross-validated with
+        trainingFolds = 5
+
+        // velocityWindow is how far back an author's recent posts are counted
+        velocityWindow = time.Hour
+)
+
+// SpamService scores posts for spam and abuse and learns from moderators' decisions
+type SpamService interface {
+        // ScorePost classifies a post before it is saved. The classification is
+        // saved by RecordClassification once the post has an ID.
+        ScorePost(kind string, userID uint, trustLevel models.UserTrustLevel, title, content string) (*models.PostClassification, error)
+        RecordClassification(classification *models.PostClassification, contentID uint) error
+        GetClassification(contentType string, contentID uint) (*models.PostClassification, error)
+
+        // LabelContent records a moderator's verdict on a post for retraining
+        LabelContent(contentType string, contentID uint, spam bool, moderatorID uint) error
+
+        // Retrain trains a new model if posts were labelled since the latest
+        // one, and starts scoring with it. It returns nil while there are too
+        // few labelled posts to train on.
+        Retrain() (*models.ClassifierModel, error)
+
+        // ReloadModel starts scoring with the latest model trained by any instance
+        ReloadModel() error
+}
+
+// SpamServiceImpl implements the SpamService interface
+type SpamServiceImpl struct {
+        classificationRepo repository.ClassificationRepository
+
+        mu      sync.RWMutex
+        model   *classifier.Model
+        modelID *uint
+}
+
+// NewSpamService creates a new spam service. Posts are scored by heuristics
+// alone until a model is loaded or trained.
+func NewSpamService(classificationRepo repository.ClassificationRepository) SpamService {
+        return &SpamServiceImpl{
+                classificationRepo: classificationRepo,
+        }
+}
+
+// current returns the model posts are scored with
+func (s *SpamServiceImpl) current() (*classifier.Model, *uint) {
+        s.mu.RLock()
+        defer s.mu.RUnlock()
+        return s.model, s.modelID
+}
+
+// use starts scoring posts with a model
+func (s *SpamServiceImpl) use(model *classifier.Model, id uint) {
+        s.mu.Lock()
+        defer s.mu.Unlock()
+        s.model = model
+        s.modelID = &id
+}
+
+// ScorePost classifies a post before it is saved
+func (s *SpamServiceImpl) ScorePost(kind string, userID uint, trustLevel models.UserTrustLevel, title, content string) (*models.PostClassification, error) {
+        sample := classifier.Sample{
+                Title:      title,
+                Content:    content,
+                TrustLevel: string(trustLevel),
+        }
+
+        // Gather what is known about the author
+        createdAt, err := s.classificationRepo.GetAccountCreatedAt(userID)
+        if err != nil {
+                return nil, fmt.Errorf("error getting account age: %w", err)
+        }
+        if createdAt != nil {
+                sample.AccountAge = time.Since(*createdAt)
+        }
+        recent, err := s.classificationRepo.CountPostsSince(userID, time.Now().Add(-velocityWindow))
+        if err != nil {
+                return nil, fmt.Errorf("error counting recent posts: %w", err)
+        }
+        sample.RecentPosts = int(recent)
+        if strings.TrimSpace(content) != "" {
+                repeated, err := s.classificationRepo.CountPostsWithContent(userID, content)
+                if err != nil {
+                        return nil, fmt.Errorf("error counting repeated posts: %w", err)
+                }
+                sample.RepeatedPosts = int(repeated)
+        }
+
+        model, modelID := s.current()
+        if model == nil || !model.Trained() {
+                modelID = nil
+        }
+        result := classifier.Classify(model, sample)
+
+        signals, err := json.Marshal(result.Signals)
+        if err != nil {
+                return nil, err
+        }
+        features, err := json.Marshal(result.Features)
+        if err != nil {
+                return nil, err
+        }
+
+        return &models.PostClassification{
+                ContentType:     kind,
+                UserID:          userID,
+                SpamScore:       result.Spam,
+                BayesScore:      result.Bayes,
+                HeuristicScore:  result.Heuristic,
+                NegativityScore: result.Negativity,
+                Signals:         string(signals),
+                Features:        string(features),
+                ModelID:         modelID,
+        }, nil
+}
+
+// RecordClassification saves the classification of a post once it has an ID
+func (s *SpamServiceImpl) RecordClassification(classification *models.PostClassification, contentID uint) error {
+        classification.ContentID = contentID
+        return s.classificationRepo.CreateClassification(classification)
+}
+
+// GetClassification retrieves the classification of a post
+func (s *SpamServiceImpl) GetClassification(contentType string, contentID uint) (*models.PostClassification, error) {
+        return s.classificationRepo.GetClassification(contentType, contentID)
+}
+
+// LabelContent records a moderator's verdict on a post. The latest verdict
+// wins, so an overturned decision relabels the post.
+func (s *SpamServiceImpl) LabelContent(contentType string, contentID uint, spam bool, moderatorID uint) error {
+        label := models.LabelHam
+        if spam {
+                label = models.LabelSpam
+        }
+        return s.classificationRepo.LabelClassification(contentType, contentID, label, moderatorID)
+}
+
+// Retrain trains a new model if posts were labelled since the latest one
+func (s *SpamServiceImpl) Retrain() (*models.ClassifierModel, error) {
+        labelled, err := s.classificationRepo.CountLabelledClassifications()
+        if err != nil {
+                return nil, fmt.Errorf("error counting labelled posts: %w", err)
+        }
+        if labelled > MaxTrainingExamples {
+                labelled = MaxTrainingExamples
+        }
+
+        latest, err := s.classificationRepo.GetLatestClassifierModel()
+        if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+                return nil, fmt.Errorf("error getting latest classifier model: %w", err)
+        }
+        if latest != nil && int64(latest.Examples) == labelled {
+                // Nothing new to learn from
+                return latest, s.load(latest)
+        }
+
+        classifications, err := s.classificationRepo.GetLabelledClassifications(MaxTrainingExamples)
+        if err != nil {
+                return nil, fmt.Errorf("error getting labelled posts: %w", err)
+        }
+        examples := TrainingExamples(classifications)
+
+        model := classifier.Train(examples)
+        if !model.Trained() {
+                return nil, nil
+        }
+
+        record := &models.ClassifierModel{
+                Examples:     len(classifications),
+                SpamExamples: model.Spam.Examples,
+        }
+        if metrics, err := classifier.CrossValidate(examples, trainingFolds, DefaultSpamThreshold); err == nil {
+                record.Precision = metrics[0].Precision()
+                record.Recall = metrics[0].Recall()
+        }
+        data, err := model.Encode()
+        if err != nil {
+                return nil, err
+        }
+        record.Data = string(data)
+
+        if err := s.classificationRepo.CreateClassifierModel(record); err != nil {
+                return nil, fmt.Errorf("error saving classifier model: %w", err)
+        }
+        s.use(model, record.ID)
+        return record, nil
+}
+
+// ReloadModel starts scoring with the latest model trained by any instance
+func (s *SpamServiceImpl) ReloadModel() error {
+        latest, err := s.classificationRepo.GetLatestClassifierModel()
+        if errors.Is(err, gorm.ErrRecordNotFound) {
+                return nil
+        }
+        if err != nil {
+                return fmt.Errorf("error getting latest classifier model: %w", err)
+        }
+        return s.load(latest)
+}
+
+// load starts scoring with a saved model unless it is already in use
+func (s *SpamServiceImpl) load(record *models.ClassifierModel) error {
+        if _, id := s.current(); id != nil && *id == record.ID {
+                return nil
+        }
+        model, err := classifier.Decode([]byte(record.Data))
+        if err != nil {
+                return fmt.Errorf("error decoding classifier model %d: %w", record.ID, err)
+        }
+        s.use(model, record.ID)
+        return nil
+}
+
+// TrainingExamples turns labelled classifications into training examples.
+// Classifications whose features cannot be read are skipped.
+func TrainingExamples(classifications []models.PostClassification) []classifier.Example {
+        examples := make([]classifier.Example, 0, len(classifications))
+        for _, c := range classifications {
+                var features []string
+                if err := json.Unmarshal([]byte(c.Features), &features); err != nil {
+                        log.Printf("Skipping classification %d with unreadable features: %v", c.ID, err)
+                        continue
+                }
+                examples = append(examples, classifier.Example{
+                        Features:  features,
+                        Heuristic: c.HeuristicScore,
+                        Spam:      c.Label == models.LabelSpam,
+                })
+        }
+        return examples
+}
@@ -1,10 +1,15 @@
 package service
 
 import (
+        "context"
+        "encoding/json"
         "errors"
         "fmt"
+        "strings"
         "time"
 
+        "github.com/yerenwgventures/GreatNigeriaLibrary-Foundation/backend/pkg/common/mailer"
+        "github.com/yerenwgventures/GreatNigeriaLibrary-Foundation/backend/services/discussion/digest"
         "github.com/yerenwgventures/GreatNigeriaLibrary-Foundation/backend/services/discussion/models"
         "github.com/yerenwgventures/GreatNigeriaLibrary-Foundation/backend/services/discussion/repository"
 )
@@ -32,20 +37,30 @@ type SubscriptionServiceImpl struct {
         discussionRepo   repository.DiscussionRepository
         topicRepo        repository.TopicRepository
         categoryRepo     repository.CategoryRepository
+        mailer           mailer.Mailer
+        emailTemplates   *mailer.Templates
+        frontendURL      string
 }
 
-// NewSubscriptionService creates a new subscription service
+// NewSubscriptionService creates a new subscription service. Digests are
+// delivered through m and link to frontendURL.
 func NewSubscriptionService(
         subscriptionRepo repository.SubscriptionRepository,
         discussionRepo repository.DiscussionRepository,
         topicRepo repository.TopicRepository,
         categoryRepo repository.CategoryRepository,
+        m mailer.Mailer,
+        emailTemplates *mailer.Templates,
+        frontendURL string,
 ) SubscriptionService {
         return &SubscriptionServiceImpl{
                 subscriptionRepo: subscriptionRepo,
                 discussionRepo:   discussionRepo,
                 topicRepo:        topicRepo,
                 categoryRepo:     categoryRepo,
+                mailer:           m,
+                emailTemplates:   emailTemplates,
+                frontendURL:      strings.TrimRight(frontendURL, "/"),
         }
 }
 
@@ -196,6 +211,7 @@ func (s *SubscriptionServiceImpl) UpdateUserPreferences(
         preference.DefaultInAppEnabled = defaults.DefaultInAppEnabled
         preference.DigestDay = defaults.DigestDay
         preference.DigestHour = defaults.DigestHour
+        preference.TimeZone = defaults.TimeZone
         preference.AutoSubscribeToReplies = defaults.AutoSubscribeToReplies
         preference.AutoSubscribeToCreated = defaults.AutoSubscribeToCreated
         preference.UpdatedAt = time.Now()
@@ -220,7 +236,9 @@ type DigestContent struct {
 type DigestTopic struct {
         TopicID      uint      `json:"topicId"`
         Title        string    `json:"title"`
+        IsNew        bool      `json:"isNew"`
         NewPosts     int       `json:"newPosts"`
+        NewReactions int       `json:"newReactions"`
         LastActivity time.Time `json:"lastActivity"`
         URL          string    `json:"url"`
 }
@@ -231,6 +249,7 @@ type DigestCategory struct {
         Name         string         `json:"name"`
         NewTopics    int            `json:"newTopics"`
         NewPosts     int            `json:"newPosts"`
+        NewReactions int            `json:"newReactions"`
         LastActivity time.Time      `json:"lastActivity"`
         TopTopics    []DigestTopic  `json:"topTopics"`
 }
@@ -241,52 +260,208 @@ type DigestTag struct {
         Name         string         `json:"name"`
         NewTopics    int            `json:"newTopics"`
         NewPosts     int            `json:"newPosts"`
+        NewReactions int            `json:"newReactions"`
         TopTopics    []DigestTopic  `json:"topTopics"`
 }
 
-// GenerateDigests generates digests for a specific frequency
+// GenerateDigests builds and schedules a digest for every user with subscriptions at the given
+// frequency whose digest time has passed. Each user gets at most one digest per period, so
+// running it repeatedly (or after a restart) does not create duplicates.
 func (s *SubscriptionServiceImpl) GenerateDigests(frequency models.SubscriptionFrequency) (int, error) {
-        // Get all subscriptions with the given frequency
-        // For simplicity, we'll use directly SQLs here as this would typically involve complex joins
-        // and aggregations better handled in raw SQL
+        if frequency != models.FrequencyDaily && frequency != models.FrequencyWeekly {
+                return 0, fmt.Errorf("digests are not generated for %s subscriptions", frequency)
+        }
         
-        // For now, just simulate the process with a simple implementation
-        // In a real system, we'd have a more efficient batch processing approach
+        now := time.Now()
+        subscriptions, err := s.subscriptionRepo.GetDigestSubscriptions(frequency, now)
+        if err != nil {
+                return 0, fmt.Errorf("failed to fetch subscriptions: %w", err)
+        }
         
-        // TODO: Implement actual digest generation logic
-        // - Query all users with subscriptions at the given frequency
-        // - For each user, gather all content updates since their last notification
-        // - Generate a digest
-        // - Schedule the digest for delivery
+        // Group subscriptions by user; they are ordered by user ID
+        var userIDs []uint
+        byUser := make(map[uint][]models.AdvancedSubscription)
+        for _, subscription := range subscriptions {
+                if _, ok := byUser[subscription.UserID]; !ok {
+                        userIDs = append(userIDs, subscription.UserID)
+                }
+                byUser[subscription.UserID] = append(byUser[subscription.UserID], subscription)
+        }
         
-        // Simulate some successful digest creations
         createdCount := 0
+        for _, userID := range userIDs {
+                created, err := s.generateUserDigest(userID, frequency, byUser[userID], now)
+                if err != nil {
+                        // Log the error but continue with other users
+                        fmt.Printf("Error generating %s digest for user %d: %v\n", frequency, userID, err)
+                        continue
+                }
+                if created {
+                        createdCount++
+                }
+        }
         
-        // Return number of digests created
         return createdCount, nil
 }
 
-// ProcessPendingDigests processes pending digests for delivery
+// generateUserDigest builds one user's digest for the current period
+func (s *SubscriptionServiceImpl) generateUserDigest(
+        userID uint,
+        frequency models.SubscriptionFrequency,
+        subscriptions []models.AdvancedSubscription,
+        now time.Time,
+) (bool, error) {
+        preference, err := s.subscriptionRepo.GetSubscriptionPreference(userID)
+        if err != nil {
+                return false, fmt.Errorf("failed to get preferences: %w", err)
+        }
+        
+        slot, periodKey := digest.Slot(frequency, preference, now)
+        
+        // Only subscriptions whose window started before this period's slot are due
+        due := digest.Due(subscriptions, slot)
+        if len(due) == 0 {
+                return false, nil
+        }
+        
+        content, err := s.buildDigestContent(userID, due, now)
+        if err != nil {
+                return false, err
+        }
+        if content.TotalUpdates == 0 {
+                // Nothing to report; the window stays open until there is
+                return false, nil
+        }
+        
+        contentJSON, err := json.Marshal(content)
+        if err != nil {
+                return false, fmt.Errorf("failed to encode digest: %w", err)
+        }
+        
+        subscriptionIDs := make([]uint, len(due))
+        for i, subscription := range due {
+                subscriptionIDs[i] = subscription.ID
+        }
+        
+        digest := &models.SubscriptionDigest{
+                UserID:         userID,
+                FrequencyType:  frequency,
+                PeriodKey:      periodKey,
+                DigestContent:  string(contentJSON),
+                DeliveryStatus: "pending",
+                ScheduledFor:   slot.UTC(),
+        }
+        return s.subscriptionRepo.CreateDigestForPeriod(digest, subscriptionIDs, now)
+}
+
+// buildDigestContent gathers the activity for each subscription since it was last notified
+func (s *SubscriptionServiceImpl) buildDigestContent(userID uint, subscriptions []models.AdvancedSubscription, now time.Time) (*DigestContent, error) {
+        content := &DigestContent{
+                Topics:     []DigestTopic{},
+                Categories: []DigestCategory{},
+                Tags:       []DigestTag{},
+        }
+        
+        // A topic can match several subscriptions; count its updates once
+        counted := make(map[uint]bool)
+        countUpdates := func(activity []models.TopicActivity) {
+                for _, topic := range activity {
+                        if counted[topic.TopicID] {
+                                continue
+                        }
+                        counted[topic.TopicID] = true
+                        content.TotalUpdates += topic.NewComments + topic.NewReactions
+                        if topic.IsNew {
+                                content.TotalUpdates++
+                        }
+                }
+        }
+        
+        for _, subscription := range subscriptions {
+                since := digest.WindowStart(subscription)
+                activity, err := s.subscriptionRepo.GetTopicActivity(userID, subscription.Type, subscription.ReferenceID, since, now, maxDigestActivity)
+                if err != nil {
+                        return nil, fmt.Errorf("failed to get activity for %s %d: %w", subscription.Type, subscription.ReferenceID, err)
+                }
+                if len(activity) == 0 {
+                        continue
+                }
+                countUpdates(activity)
+                
+                switch subscription.Type {
+                case models.TopicSubscription:
+                        content.Topics = append(content.Topics, toDigestTopic(activity[0]))
+                        
+                case models.CategorySubscription:
+                        name, err := s.subscriptionRepo.GetReferenceName(subscription.Type, subscription.ReferenceID)
+                        if err != nil {
+                                return nil, fmt.Errorf("failed to get category name: %w", err)
+                        }
+                        category := DigestCategory{CategoryID: subscription.ReferenceID, Name: name}
+                        category.NewTopics, category.NewPosts, category.NewReactions, category.LastActivity = summarizeActivity(activity)
+                        category.TopTopics = topDigestTopics(activity)
+                        content.Categories = append(content.Categories, category)
+                        
+                case models.TagSubscription:
+                        name, err := s.subscriptionRepo.GetReferenceName(subscription.Type, subscription.ReferenceID)
+                        if err != nil {
+                                return nil, fmt.Errorf("failed to get tag name: %w", err)
+                        }
+                        tag := DigestTag{TagID: subscription.ReferenceID, Name: name}
+                        tag.NewTopics, tag.NewPosts, tag.NewReactions, _ = summarizeActivity(activity)
+                        tag.TopTopics = topDigestTopics(activity)
+                        content.Tags = append(content.Tags, tag)
+                }
+        }
+        
+        return content, nil
+}
+
+// ProcessPendingDigests processes pending digests for delivery. Digests left
+// sending by a stopped instance, and failed ones, are sent again first, up to
+// maxDigestAttempts times.
 func (s *SubscriptionServiceImpl) ProcessPendingDigests() (int, error) {
-        // Get pending digests scheduled for now or earlier
         now := time.Now()
-        digests, err := s.subscriptionRepo.GetPendingDigests(models.FrequencyDaily, now)
+        requeued, err := s.subscriptionRepo.RequeueDigests(now.Add(-digestSendTimeout), now.Add(-digestRetryDelay), maxDigestAttempts)
         if err != nil {
-                return 0, fmt.Errorf("failed to fetch pending digests: %w", err)
+                fmt.Printf("Error requeueing digests: %v\n", err)
+        } else if requeued > 0 {
+                fmt.Printf("Requeued %d digests\n", requeued)
+        }
+        
+        // Get pending digests scheduled for now or earlier
+        var digests []models.SubscriptionDigest
+        for _, frequency := range []models.SubscriptionFrequency{models.FrequencyDaily, models.FrequencyWeekly} {
+                pending, err := s.subscriptionRepo.GetPendingDigests(frequency, now)
+                if err != nil {
+                        return 0, fmt.Errorf("failed to fetch pending digests: %w", err)
+                }
+                digests = append(digests, pending...)
         }
         
         processedCount := 0
         
         // Process each digest
         for _, digest := range digests {
-                // In a real implementation, we'd:
-                // 1. Parse the digest content
-                // 2. Format appropriate email/notification
-                // 3. Send it via email/push notification service
-                // 4. Update the status
+                // Another instance may already be sending this digest
+                claimed, err := s.subscriptionRepo.ClaimDigest(digest.ID)
+                if err != nil {
+                        fmt.Printf("Error claiming digest: %v\n", err)
+                        continue
+                }
+                if !claimed {
+                        continue
+                }
                 
-                // Simulate successful delivery
-                err := s.subscriptionRepo.UpdateDigestStatus(digest.ID, "sent", &now, "")
+                if err := s.sendDigest(&digest); err != nil {
+                        if updateErr := s.subscriptionRepo.UpdateDigestStatus(digest.ID, "failed", nil, err.Error()); updateErr != nil {
+                                fmt.Printf("Error updating digest status: %v\n", updateErr)
+                        }
+                        continue
+                }
+                
+                sentAt := time.Now()
+                err = s.subscriptionRepo.UpdateDigestStatus(digest.ID, "sent", &sentAt, "")
                 if err != nil {
                         // Log the error but continue processing other digests
                         fmt.Printf("Error updating digest status: %v\n", err)
@@ -300,6 +475,90 @@ func (s *SubscriptionServiceImpl) ProcessPendingDigests() (int, error) {
         return processedCount, nil
 }
 
+// sendDigest emails a digest to its user
+func (s *SubscriptionServiceImpl) sendDigest(digest *models.SubscriptionDigest) error {
+        if s.mailer == nil || s.emailTemplates == nil {
+                return errors.New("no mailer configured")
+        }
+        
+        var content DigestContent
+        if err := json.Unmarshal([]byte(digest.DigestContent), &content); err != nil {
+                return fmt.Errorf("invalid digest content: %w", err)
+        }
+        
+        email, name, err := s.subscriptionRepo.GetDigestRecipient(digest.UserID)
+        if err != nil {
+                return fmt.Errorf("failed to get digest recipient: %w", err)
+        }
+        if email == "" {
+                return errors.New("user has no email address")
+        }
+        
+        return mailer.SendTemplate(context.Background(), s.mailer, s.emailTemplates, email, mailer.TemplateDigest, s.digestEmailData(name, digest.FrequencyType, content))
+}
+
+// digestEmailData converts digest content into the digest email template data
+func (s *SubscriptionServiceImpl) digestEmailData(name string, frequency models.SubscriptionFrequency, content DigestContent) mailer.DigestData {
+        data := mailer.DigestData{
+                Name:         name,
+                Period:       string(frequency),
+                TotalUpdates: content.TotalUpdates,
+                ManageURL:    s.frontendURL + "/settings/subscriptions",
+        }
+        
+        topicItem := func(topic DigestTopic) mailer.DigestItem {
+                url := topic.URL
+                if url == "" {
+                        url = fmt.Sprintf("%s/discussions/topics/%d", s.frontendURL, topic.TopicID)
+                } else if strings.HasPrefix(url, "/") {
+                        url = s.frontendURL + url
+                }
+                return mailer.DigestItem{
+                        Title:   topic.Title,
+                        URL:     url,
+                        Summary: pluralize(topic.NewPosts, "new post"),
+                }
+        }
+        
+        if len(content.Topics) > 0 {
+                section := mailer.DigestSection{Title: "Topics you follow"}
+                for _, topic := range content.Topics {
+                        section.Items = append(section.Items, topicItem(topic))
+                }
+                data.Sections = append(data.Sections, section)
+        }
+        
+        for _, category := range content.Categories {
+                section := mailer.DigestSection{
+                        Title: fmt.Sprintf("%s: %s, %s", category.Name, pluralize(category.NewTopics, "new topic"), pluralize(category.NewPosts, "new post")),
+                }
+                for _, topic := range category.TopTopics {
+                        section.Items = append(section.Items, topicItem(topic))
+                }
+                data.Sections = append(data.Sections, section)
+        }
+        
+        for _, tag := range content.Tags {
+                section := mailer.DigestSection{
+                        Title: fmt.Sprintf("#%s: %s, %s", tag.Name, pluralize(tag.NewTopics, "new topic"), pluralize(tag.NewPosts, "new post")),
+                }
+                for _, topic := range tag.TopTopics {
+                        section.Items = append(section.Items, topicItem(topic))
+                }
+                data.Sections = append(data.Sections, section)
+        }
+        
+        return data
+}
+
+// pluralize formats a count with a singular or plural noun
+func pluralize(count int, noun string) string {
+        if count == 1 {
+                return "1 " + noun
+        }
+        return fmt.Sprintf("%d %ss", count, noun)
+}
+
 // GetUserDigestHistory retrieves digest history for a user with pagination
 func (s *SubscriptionServiceImpl) GetUserDigestHistory(userID uint, page, pageSize int) ([]models.SubscriptionDigest, error) {
         if page < 1 {
@@ -0,0 +1,53 @@
+package service
+
+import (
+        "context"
+        "log"
+        "time"
+
+        "github.com/yerenwgventures/GreatNigeriaLibrary-Foundation/backend/pkg/common/jobs"
+)
+
+// DefaultTrustInterval is how often every user's trust level is recalculated
+const DefaultTrustInterval = 24 * time.Hour
+
+// TrustLeaseName names the lease that lets one replica recalculate trust levels
+const TrustLeaseName = "discussion-trust"
+
+// TrustScheduler periodically recalculates every user's trust score and
+// level. With a lease, only the replica holding it recalculates.
+type TrustScheduler struct {
+        trustService TrustService
+        runner       *jobs.Runner
+}
+
+// NewTrustScheduler creates a new trust scheduler. A nil lease recalculates on
+// every replica, which is only sensible with a single replica.
+func NewTrustScheduler(trustService TrustService, lease jobs.Lease, interval time.Duration) *TrustScheduler {
+        if interval <= 0 {
+                interval = DefaultTrustInterval
+        }
+        return &TrustScheduler{
+                trustService: trustService,
+                runner:       jobs.NewRunner("trust", lease, interval),
+        }
+}
+
+// Run recalculates trust levels every interval until ctx is cancelled
+func (t *TrustScheduler) Run(ctx context.Context) {
+        t.runner.Every(ctx, t.RunOnce)
+}
+
+// RunOnce recalculates every user's trust level if this replica holds the lease
+func (t *TrustScheduler) RunOnce(ctx context.Context) {
+        if !t.runner.Acquire(ctx) {
+                return
+        }
+
+        started := time.Now()
+        count, err := t.trustService.RecalculateAll(ctx)
+        if err != nil {
+                log.Printf("Error recalculating trust levels: %v", err)
+        }
+        log.Printf("Recalculated trust levels of %d users in %s", count, time.Since(started).Round(time.Second))
+}
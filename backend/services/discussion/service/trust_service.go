@@ -0,0 +1,206 @@
+package service
+
+import (
+        "context"
+        "fmt"
+        "log"
+        "math"
+        "time"
+
+        "github.com/yerenwgventures/GreatNigeriaLibrary-Foundation/backend/services/discussion/models"
+        "github.com/yerenwgventures/GreatNigeriaLibrary-Foundation/backend/services/discussion/repository"
+        "github.com/yerenwgventures/GreatNigeriaLibrary-Foundation/backend/services/discussion/trust"
+)
+
+// trustBatchSize is how many users are recalculated per query for their IDs
+const trustBatchSize = 500
+
+// TrustService computes users' trust scores from their activity, decides
+// their trust levels and what those levels allow
+type TrustService interface {
+        // Recalculate recomputes a user's trust score and level from their
+        // activity, and syncs the level to the auth service
+        Recalculate(userID uint) (*models.UserTrustScore, error)
+
+        // RecalculateAll recalculates every user, returning how many succeeded
+        RecalculateAll(ctx context.Context) (int, error)
+
+        // Explain tells a user why they have their trust level
+        Explain(userID uint) (*trust.Explanation, error)
+
+        // Capabilities returns what a user's trust level allows them to do
+        Capabilities(userID uint) (trust.Capabilities, error)
+
+        // Settings
+        GetSettings() (*models.TrustSettings, error)
+        UpdateSettings(settings *models.TrustSettings) (*models.TrustSettings, error)
+}
+
+// TrustServiceImpl implements the TrustService interface
+type TrustServiceImpl struct {
+        trustRepo      repository.TrustRepository
+        moderationRepo repository.ModerationRepository
+}
+
+// NewTrustService creates a new trust service
+func NewTrustService(trustRepo repository.TrustRepository, moderationRepo repository.ModerationRepository) TrustService {
+        return &TrustServiceImpl{
+                trustRepo:      trustRepo,
+                moderationRepo: moderationRepo,
+        }
+}
+
+// GetSettings returns the trust settings, or the defaults if none were saved
+func (s *TrustServiceImpl) GetSettings() (*models.TrustSettings, error) {
+        settings, err := s.trustRepo.GetTrustSettings()
+        if err != nil {
+                return nil, fmt.Errorf("error getting trust settings: %w", err)
+        }
+        if settings.ID == 0 {
+                defaults := trust.DefaultSettings()
+                return &defaults, nil
+        }
+        return settings, nil
+}
+
+// UpdateSettings validates and saves the trust settings. Invalid settings
+// return *errors.ValidationErrors. Levels change at the next recalculation.
+func (s *TrustServiceImpl) UpdateSettings(settings *models.TrustSettings) (*models.TrustSettings, error) {
+        if violations := trust.Validate(settings); violations.HasErrors() {
+                return nil, violations
+        }
+
+        current, err := s.trustRepo.GetTrustSettings()
+        if err != nil {
+                return nil, fmt.Errorf("error getting trust settings: %w", err)
+        }
+        settings.ID = current.ID
+        settings.CreatedAt = current.CreatedAt
+
+        if err := s.trustRepo.SaveTrustSettings(settings); err != nil {
+                return nil, fmt.Errorf("error saving trust settings: %w", err)
+        }
+        return settings, nil
+}
+
+// Recalculate recomputes a user's trust score and level from their activity
+func (s *TrustServiceImpl) Recalculate(userID uint) (*models.UserTrustScore, error) {
+        settings, err := s.GetSettings()
+        if err != nil {
+                return nil, err
+        }
+
+        now := time.Now()
+        activity, err := s.trustRepo.GetActivity(userID, now.Add(-trust.PenaltyWindow(*settings)))
+        if err != nil {
+                return nil, fmt.Errorf("error getting activity of user %d: %w", userID, err)
+        }
+        score, err := s.moderationRepo.GetUserTrustScore(userID)
+        if err != nil {
+                return nil, fmt.Errorf("error getting user trust score: %w", err)
+        }
+
+        scores := trust.Score(*activity, score.ManualAdjustment)
+        trust.Apply(score, *activity, scores, now)
+        previous := score.TrustLevel
+        score.TrustLevel = trust.Decide(*settings, score.TrustLevel, score.TrustScore, trust.Clean(*activity))
+        score.UpdatedAt = now
+
+        if err := s.moderationRepo.UpdateUserTrustScore(score); err != nil {
+                return nil, fmt.Errorf("error updating user trust score: %w", err)
+        }
+        if score.TrustLevel != previous {
+                log.Printf("User %d trust level changed from %s to %s (score %.1f)", userID, previous, score.TrustLevel, score.TrustScore)
+        }
+
+        if err := s.syncAuthLevel(score); err != nil {
+                return score, fmt.Errorf("error syncing trust level of user %d: %w", userID, err)
+        }
+        return score, nil
+}
+
+// syncAuthLevel copies a user's trust level to the auth service, so the two
+// services agree on how far the user is trusted
+func (s *TrustServiceImpl) syncAuthLevel(score *models.UserTrustScore) error {
+        current, err := s.trustRepo.GetAuthTrustLevel(score.UserID)
+        if err != nil {
+                return err
+        }
+        level := trust.AuthLevel(score.TrustLevel, current)
+        return s.trustRepo.SaveAuthTrustLevel(score.UserID, level, int(math.Round(score.TrustScore)))
+}
+
+// RecalculateAll recalculates every user in order of ID. Failures are logged
+// and skipped; it stops early when ctx is cancelled.
+func (s *TrustServiceImpl) RecalculateAll(ctx context.Context) (int, error) {
+        recalculated := 0
+        var afterID uint
+        for {
+                ids, err := s.trustRepo.GetUserIDsAfter(afterID, trustBatchSize)
+                if err != nil {
+                        return recalculated, fmt.Errorf("error listing users: %w", err)
+                }
+                for _, id := range ids {
+                        if err := ctx.Err(); err != nil {
+                                return recalculated, err
+                        }
+                        if _, err := s.Recalculate(id); err != nil {
+                                log.Printf("Error recalculating trust of user %d: %v", id, err)
+                                continue
+                        }
+                        recalculated++
+                }
+                if len(ids) < trustBatchSize {
+                        return recalculated, nil
+                }
+                afterID = ids[len(ids)-1]
+        }
+}
+
+// Explain tells a user why they have their trust level. Users the engine has
+// not scored yet are scored first.
+func (s *TrustServiceImpl) Explain(userID uint) (*trust.Explanation, error) {
+        settings, err := s.GetSettings()
+        if err != nil {
+                return nil, err
+        }
+        score, err := s.moderationRepo.GetUserTrustScore(userID)
+        if err != nil {
+                return nil, fmt.Errorf("error getting user trust score: %w", err)
+        }
+        if score.PositiveFactors == "" {
+                recalculated, err := s.Recalculate(userID)
+                if recalculated == nil {
+                        return nil, err
+                }
+                // A failure to sync the auth level still leaves a score to explain
+                score = recalculated
+        }
+
+        moderator, err := s.moderationRepo.IsUserModerator(userID)
+        if err != nil {
+                return nil, fmt.Errorf("error checking moderator status: %w", err)
+        }
+        return trust.Explain(*settings, score, moderator), nil
+}
+
+// Capabilities returns what a user's trust level allows them to do.
+// Moderators may do everything.
+func (s *TrustServiceImpl) Capabilities(userID uint) (trust.Capabilities, error) {
+        settings, err := s.GetSettings()
+        if err != nil {
+                return trust.Capabilities{}, err
+        }
+        moderator, err := s.moderationRepo.IsUserModerator(userID)
+        if err != nil {
+                return trust.Capabilities{}, fmt.Errorf("error checking moderator status: %w", err)
+        }
+        if moderator {
+                return trust.ModeratorCapabilities(*settings), nil
+        }
+        score, err := s.moderationRepo.GetUserTrustScore(userID)
+        if err != nil {
+                return trust.Capabilities{}, fmt.Errorf("error getting user trust score: %w", err)
+        }
+        return trust.CapabilitiesOf(*settings, score.TrustLevel), nil
+}
@@ -0,0 +1,34 @@
+package trust
+
+import (
+	"math"
+
+	"github.com/yerenwgventures/GreatNigeriaLibrary-Foundation/backend/services/discussion/models"
+)
+
+// FlagAccuracy is the share of a user's reviewed flags that moderators
+// upheld. Every flagger starts with one upheld and one dismissed flag, so a
+// user without reviewed flags is 50% accurate and a single review moves
+// them only part of the way.
+func FlagAccuracy(upheld, dismissed int) float64 {
+	if upheld < 0 {
+		upheld = 0
+	}
+	if dismissed < 0 {
+		dismissed = 0
+	}
+	return float64(upheld+1) / float64(upheld+dismissed+2)
+}
+
+// FlagWeight is how much a flag counts towards hiding a post: the weight of
+// the flagger's trust level, scaled by their accuracy. Users without a record
+// flag with their level's weight; accurate flaggers count up to twice as much
+// and serial false flaggers close to nothing.
+func FlagWeight(levelWeight float64, upheld, dismissed int) float64 {
+	return math.Round(levelWeight*2*FlagAccuracy(upheld, dismissed)*100) / 100
+}
+
+// ShouldHide reports whether flags with a combined weight hide a post
+func ShouldHide(settings models.TrustSettings, weight float64) bool {
+	return settings.HideFlagWeight > 0 && weight >= settings.HideFlagWeight
+}
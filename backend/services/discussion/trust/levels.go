@@ -0,0 +1,277 @@
+package trust
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/yerenwgventures/GreatNigeriaLibrary-Foundation/backend/pkg/common/errors"
+	authmodels "github.com/yerenwgventures/GreatNigeriaLibrary-Foundation/backend/pkg/models"
+	"github.com/yerenwgventures/GreatNigeriaLibrary-Foundation/backend/services/discussion/models"
+	"github.com/yerenwgventures/GreatNigeriaLibrary-Foundation/backend/services/discussion/policy"
+)
+
+// ladder lists the trust levels from lowest to highest
+var ladder = []models.UserTrustLevel{
+	models.TrustLevelNewUser,
+	models.TrustLevelBasic,
+	models.TrustLevelMember,
+	models.TrustLevelRegular,
+	models.TrustLevelLeader,
+}
+
+// DefaultSettings are used until an administrator saves trust settings
+func DefaultSettings() models.TrustSettings {
+	return models.TrustSettings{
+		BasicScore:        20,
+		MemberScore:       50,
+		RegularScore:      75,
+		LeaderScore:       90,
+		Hysteresis:        5,
+		PenaltyWindowDays: 180,
+		LinkLevel:         models.TrustLevelBasic,
+		AttachmentLevel:   models.TrustLevelMember,
+		NewUserFlagWeight: 0.5,
+		BasicFlagWeight:   1,
+		MemberFlagWeight:  1,
+		RegularFlagWeight: 1.5,
+		LeaderFlagWeight:  2,
+		HideFlagWeight:    3,
+	}
+}
+
+// PenaltyWindow is how long marks against a user count
+func PenaltyWindow(settings models.TrustSettings) time.Duration {
+	return time.Duration(settings.PenaltyWindowDays) * 24 * time.Hour
+}
+
+// Validate checks that settings can be saved
+func Validate(settings *models.TrustSettings) *errors.ValidationErrors {
+	violations := errors.NewValidationErrors()
+	previous := 0.0
+	for _, level := range ladder[1:] {
+		score := threshold(*settings, level)
+		if score <= previous || score > 100 {
+			violations.Add(string(level)+"Score", "must be above the previous level's score and at most 100", fmt.Sprint(score))
+		}
+		previous = score
+	}
+	if settings.Hysteresis < 0 || settings.Hysteresis >= settings.BasicScore {
+		violations.Add("hysteresis", "must be at least 0 and below the basic score", fmt.Sprint(settings.Hysteresis))
+	}
+	if settings.PenaltyWindowDays < 1 {
+		violations.Add("penaltyWindowDays", "must be at least 1", fmt.Sprint(settings.PenaltyWindowDays))
+	}
+	if !known(settings.LinkLevel) {
+		violations.Add("linkLevel", "must be a trust level", string(settings.LinkLevel))
+	}
+	if !known(settings.AttachmentLevel) {
+		violations.Add("attachmentLevel", "must be a trust level", string(settings.AttachmentLevel))
+	}
+	for _, level := range ladder {
+		if weight := flagWeight(*settings, level); weight < 0 {
+			violations.Add(string(level)+"FlagWeight", "must not be negative", fmt.Sprint(weight))
+		}
+	}
+	if settings.HideFlagWeight <= 0 {
+		violations.Add("hideFlagWeight", "must be above 0", fmt.Sprint(settings.HideFlagWeight))
+	}
+	return violations
+}
+
+func known(level models.UserTrustLevel) bool {
+	for _, l := range ladder {
+		if l == level {
+			return true
+		}
+	}
+	return false
+}
+
+// threshold returns the trust score that earns a level
+func threshold(settings models.TrustSettings, level models.UserTrustLevel) float64 {
+	switch level {
+	case models.TrustLevelBasic:
+		return settings.BasicScore
+	case models.TrustLevelMember:
+		return settings.MemberScore
+	case models.TrustLevelRegular:
+		return settings.RegularScore
+	case models.TrustLevelLeader:
+		return settings.LeaderScore
+	}
+	return 0
+}
+
+// KeepScore returns the trust score below which a user loses a level. New
+// users have no level to lose.
+func KeepScore(settings models.TrustSettings, level models.UserTrustLevel) float64 {
+	if policy.TrustRank(level) == 0 {
+		return 0
+	}
+	return threshold(settings, level) - settings.Hysteresis
+}
+
+// Decide returns the trust level a user holds with a trust score, given the
+// level they hold now. A level is earned at its threshold and kept until the
+// score falls the hysteresis below it. Leaders must also be clean.
+func Decide(settings models.TrustSettings, current models.UserTrustLevel, score float64, clean bool) models.UserTrustLevel {
+	rank := policy.TrustRank(current)
+	for r := len(ladder) - 1; r > 0; r-- {
+		level := ladder[r]
+		min := threshold(settings, level)
+		if r <= rank {
+			min = KeepScore(settings, level)
+		}
+		if score >= min && (level != models.TrustLevelLeader || clean) {
+			return level
+		}
+	}
+	return models.TrustLevelNewUser
+}
+
+// Capabilities are what a user's trust level allows them to do
+type Capabilities struct {
+	PostLinks   bool    `json:"postLinks"`
+	Attachments bool    `json:"attachments"`
+	FlagWeight  float64 `json:"flagWeight"` // How much the user's flags count
+}
+
+// CapabilitiesOf returns what a trust level allows
+func CapabilitiesOf(settings models.TrustSettings, level models.UserTrustLevel) Capabilities {
+	rank := policy.TrustRank(level)
+	return Capabilities{
+		PostLinks:   rank >= policy.TrustRank(settings.LinkLevel),
+		Attachments: rank >= policy.TrustRank(settings.AttachmentLevel),
+		FlagWeight:  flagWeight(settings, level),
+	}
+}
+
+// ModeratorCapabilities returns what moderators may do, whatever their level
+func ModeratorCapabilities(settings models.TrustSettings) Capabilities {
+	return Capabilities{PostLinks: true, Attachments: true, FlagWeight: settings.LeaderFlagWeight}
+}
+
+func flagWeight(settings models.TrustSettings, level models.UserTrustLevel) float64 {
+	switch level {
+	case models.TrustLevelBasic:
+		return settings.BasicFlagWeight
+	case models.TrustLevelMember:
+		return settings.MemberFlagWeight
+	case models.TrustLevelRegular:
+		return settings.RegularFlagWeight
+	case models.TrustLevelLeader:
+		return settings.LeaderFlagWeight
+	}
+	return settings.NewUserFlagWeight
+}
+
+// AuthLevel maps a discussion trust level onto the auth service's trust
+// level, given the user's current one. Identity verification and
+// administrators grant the verified and moderator levels, so users keep
+// those unless their activity earns a higher level.
+func AuthLevel(level models.UserTrustLevel, current authmodels.TrustLevel) authmodels.TrustLevel {
+	mapped := authmodels.TrustLevelNewUser
+	switch level {
+	case models.TrustLevelMember, models.TrustLevelRegular:
+		mapped = authmodels.TrustLevelTrusted
+	case models.TrustLevelLeader:
+		mapped = authmodels.TrustLevelExpert
+	}
+	switch {
+	case current == authmodels.TrustLevelModerator:
+		return current
+	case current == authmodels.TrustLevelVerified && mapped != authmodels.TrustLevelExpert:
+		return current
+	}
+	return mapped
+}
+
+// levelNames name the levels in explanations
+var levelNames = map[models.UserTrustLevel]string{
+	models.TrustLevelNewUser: "a new user",
+	models.TrustLevelBasic:   "a basic user",
+	models.TrustLevelMember:  "a member",
+	models.TrustLevelRegular: "a regular",
+	models.TrustLevelLeader:  "a leader",
+}
+
+// NextLevel is what a user needs for the level above theirs
+type NextLevel struct {
+	Level        models.UserTrustLevel `json:"level"`
+	Score        float64               `json:"score"`
+	PointsNeeded float64               `json:"pointsNeeded"`
+	Requirements []string              `json:"requirements,omitempty"`
+}
+
+// Explanation tells a user why they have their trust level
+type Explanation struct {
+	Level            models.UserTrustLevel `json:"level"`
+	TrustScore       float64               `json:"trustScore"`
+	ContentScore     float64               `json:"contentScore"`
+	CommunityScore   float64               `json:"communityScore"`
+	ModeratorScore   float64               `json:"moderatorScore"`
+	ManualAdjustment float64               `json:"manualAdjustment"`
+	KeepScore        float64               `json:"keepScore"` // Below this the user drops a level
+	Next             *NextLevel            `json:"next,omitempty"`
+	PositiveFactors  []Factor              `json:"positiveFactors"`
+	NegativeFactors  []Factor              `json:"negativeFactors"`
+	Capabilities     Capabilities          `json:"capabilities"`
+	Moderator        bool                  `json:"moderator"`
+	Summary          string                `json:"summary"`
+	CalculatedAt     time.Time             `json:"calculatedAt"`
+}
+
+// Explain explains a user's saved trust score and level
+func Explain(settings models.TrustSettings, score *models.UserTrustScore, moderator bool) *Explanation {
+	explanation := &Explanation{
+		Level:            score.TrustLevel,
+		TrustScore:       score.TrustScore,
+		ContentScore:     score.ContentScore,
+		CommunityScore:   score.CommunityScore,
+		ModeratorScore:   score.ModeratorScore,
+		ManualAdjustment: score.ManualAdjustment,
+		KeepScore:        KeepScore(settings, score.TrustLevel),
+		Capabilities:     CapabilitiesOf(settings, score.TrustLevel),
+		Moderator:        moderator,
+		CalculatedAt:     score.LastCalculatedAt,
+	}
+	explanation.PositiveFactors, explanation.NegativeFactors = Factors(score)
+	if moderator {
+		explanation.Capabilities = ModeratorCapabilities(settings)
+	}
+
+	level := score.TrustLevel
+	if !known(level) {
+		level = models.TrustLevelNewUser
+	}
+	summary := []string{fmt.Sprintf("You are %s with a trust score of %.1f.", levelNames[level], score.TrustScore)}
+	if rank := policy.TrustRank(level); rank < len(ladder)-1 {
+		next := &NextLevel{Level: ladder[rank+1], Score: threshold(settings, ladder[rank+1])}
+		next.PointsNeeded = round(next.Score - score.TrustScore)
+		if next.PointsNeeded < 0 {
+			next.PointsNeeded = 0
+		}
+		if next.Level == models.TrustLevelLeader && !CleanScore(score) {
+			next.Requirements = append(next.Requirements, fmt.Sprintf(
+				"no warnings, penalties, rejected posts or upheld flags against your posts in the last %d days", settings.PenaltyWindowDays))
+		}
+		explanation.Next = next
+		if next.PointsNeeded > 0 {
+			summary = append(summary, fmt.Sprintf("%.1f more points would make you %s.", next.PointsNeeded, levelNames[next.Level]))
+		}
+		for _, requirement := range next.Requirements {
+			summary = append(summary, fmt.Sprintf("To become %s you need %s.", levelNames[next.Level], requirement))
+		}
+	} else {
+		summary = append(summary, "That is the highest trust level.")
+	}
+	if explanation.KeepScore > 0 && score.TrustScore < threshold(settings, level) {
+		summary = append(summary, fmt.Sprintf("You keep your level while your score stays at %.1f or above.", explanation.KeepScore))
+	}
+	if moderator {
+		summary = append(summary, "As a moderator you are not limited by your trust level.")
+	}
+	explanation.Summary = strings.Join(summary, " ")
+	return explanation
+}
@@ -0,0 +1,215 @@
+// Package trust computes users' trust scores from their activity in the
+// discussions and decides the trust level each score earns.
+//
+// A trust score runs from 0 to 100 and combines three scores, each also from
+// 0 to 100:
+//
+//   - The content score grows with a user's approved posts, and falls for
+//     posts moderators rejected and upheld flags against their posts.
+//   - The community score grows with the reactions other users give their
+//     posts and with the age of their account.
+//   - The moderator score starts at 50. Flags moderators upheld raise it;
+//     dismissed flags, warnings and penalties lower it.
+//
+// Marks against a user only count for the settings' penalty window, so users
+// can earn back a level they lost. Users are promoted when their trust score
+// reaches a level's threshold and demoted only when it falls the settings'
+// hysteresis below it.
+package trust
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/yerenwgventures/GreatNigeriaLibrary-Foundation/backend/services/discussion/models"
+)
+
+// Activity is what the trust score of a user is computed from
+type Activity struct {
+	AccountAge        time.Duration
+	Posts             int // Approved topics and comments
+	ReactionsReceived int // Reactions of other users to the user's posts
+	FlagsUpheld       int // Flags the user raised that moderators upheld
+
+	// Counted within the penalty window
+	FlagsDismissed int // Flags the user raised that moderators dismissed
+	Rejections     int // Posts of the user that moderators rejected
+	FlagsAgainst   int // Upheld flags against the user's posts
+	Warnings       int
+	Penalties      int // Suspensions, restrictions and bans
+}
+
+// Names of the scores a factor changes
+const (
+	ScoreContent   = "content"
+	ScoreCommunity = "community"
+	ScoreModerator = "moderator"
+)
+
+// Factor is one reason a user's score is what it is
+type Factor struct {
+	Score  string  `json:"score"` // ScoreContent, ScoreCommunity or ScoreModerator
+	Reason string  `json:"reason"`
+	Points float64 `json:"points"` // How much it changed that score
+}
+
+// Scores are a user's computed trust scores
+type Scores struct {
+	Content   float64
+	Community float64
+	Moderator float64
+	Manual    float64 // Moderators' manual adjustment, added to the trust score
+	Trust     float64
+	Positive  []Factor
+	Negative  []Factor
+}
+
+// How activity turns into points. Posts, reactions and account age approach
+// their maximum points, reaching about two thirds of them at their scale.
+const (
+	postPoints     = 100.0
+	postScale      = 30.0
+	reactionPoints = 70.0
+	reactionScale  = 50.0
+	agePoints      = 30.0
+	ageScale       = 90.0 // days
+
+	moderatorBase       = 50.0
+	upheldFlagPoints    = 5.0
+	maxUpheldFlagPoints = 30.0
+	dismissedFlagPoints = -5.0
+	rejectionPoints     = -10.0
+	flagAgainstPoints   = -10.0
+	warningPoints       = -15.0
+	penaltyPoints       = -30.0
+)
+
+// Weights of the three scores in the trust score
+const (
+	contentWeight   = 0.4
+	communityWeight = 0.4
+	moderatorWeight = 0.2
+)
+
+// Combine returns the trust score of three scores and a manual adjustment
+func Combine(content, community, moderator, manual float64) float64 {
+	return clamp(contentWeight*content + communityWeight*community + moderatorWeight*moderator + manual)
+}
+
+// Score computes a user's scores from their activity. The manual adjustment
+// is added to the trust score as it is.
+func Score(activity Activity, manual float64) Scores {
+	s := &Scores{Manual: manual}
+	days := activity.AccountAge.Hours() / 24
+
+	s.add(ScoreContent, plural(activity.Posts, "approved post"), saturate(activity.Posts, postPoints, postScale))
+	s.add(ScoreContent, plural(activity.Rejections, "post")+" rejected by moderators", float64(activity.Rejections)*rejectionPoints)
+	s.add(ScoreContent, plural(activity.FlagsAgainst, "upheld flag")+" against your posts", float64(activity.FlagsAgainst)*flagAgainstPoints)
+
+	s.add(ScoreCommunity, plural(activity.ReactionsReceived, "reaction")+" from other users", saturate(activity.ReactionsReceived, reactionPoints, reactionScale))
+	s.add(ScoreCommunity, "account "+plural(int(days), "day")+" old", round(agePoints*(1-math.Exp(-days/ageScale))))
+
+	s.add(ScoreModerator, "starting moderator score", moderatorBase)
+	s.add(ScoreModerator, plural(activity.FlagsUpheld, "flag")+" upheld by moderators", math.Min(float64(activity.FlagsUpheld)*upheldFlagPoints, maxUpheldFlagPoints))
+	s.add(ScoreModerator, plural(activity.FlagsDismissed, "flag")+" dismissed by moderators", float64(activity.FlagsDismissed)*dismissedFlagPoints)
+	s.add(ScoreModerator, plural(activity.Warnings, "warning"), float64(activity.Warnings)*warningPoints)
+	s.add(ScoreModerator, plural(activity.Penalties, "suspension, restriction or ban", "suspensions, restrictions or bans"), float64(activity.Penalties)*penaltyPoints)
+
+	s.Content = clamp(s.Content)
+	s.Community = clamp(s.Community)
+	s.Moderator = clamp(s.Moderator)
+	s.Trust = round(Combine(s.Content, s.Community, s.Moderator, manual))
+	return *s
+}
+
+// add adds a factor's points to its score, skipping factors worth nothing
+func (s *Scores) add(score, reason string, points float64) {
+	if points == 0 {
+		return
+	}
+	switch score {
+	case ScoreContent:
+		s.Content += points
+	case ScoreCommunity:
+		s.Community += points
+	case ScoreModerator:
+		s.Moderator += points
+	}
+	factor := Factor{Score: score, Reason: reason, Points: round(points)}
+	if points > 0 {
+		s.Positive = append(s.Positive, factor)
+	} else {
+		s.Negative = append(s.Negative, factor)
+	}
+}
+
+// Clean reports whether a user has no marks against them in the penalty
+// window, which leaders need
+func Clean(activity Activity) bool {
+	return activity.Rejections == 0 && activity.FlagsAgainst == 0 && activity.Warnings == 0 && activity.Penalties == 0
+}
+
+// CleanScore reports whether a saved trust score has no marks against it
+func CleanScore(score *models.UserTrustScore) bool {
+	return score.ContentRejections == 0 && score.ReportCount == 0 && score.WarningCount == 0
+}
+
+// Apply saves computed scores and the activity behind them on a user's trust
+// score. It leaves the trust level alone.
+func Apply(score *models.UserTrustScore, activity Activity, scores Scores, now time.Time) {
+	score.ContentScore = round(scores.Content)
+	score.CommunityScore = round(scores.Community)
+	score.ModeratorScore = round(scores.Moderator)
+	score.TrustScore = round(scores.Trust)
+	score.Score = score.TrustScore
+	score.ReportCount = activity.FlagsAgainst
+	score.WarningCount = activity.Warnings + activity.Penalties
+	score.ContentRejections = activity.Rejections
+	score.PositiveFactors = encodeFactors(scores.Positive)
+	score.NegativeFactors = encodeFactors(scores.Negative)
+	score.LastCalculatedAt = now
+	score.LastScoreUpdate = now
+}
+
+// Factors decodes the factors saved on a trust score. Scores the engine has
+// not computed yet have none.
+func Factors(score *models.UserTrustScore) (positive, negative []Factor) {
+	_ = json.Unmarshal([]byte(score.PositiveFactors), &positive)
+	_ = json.Unmarshal([]byte(score.NegativeFactors), &negative)
+	return positive, negative
+}
+
+func encodeFactors(factors []Factor) string {
+	if len(factors) == 0 {
+		return "[]"
+	}
+	data, _ := json.Marshal(factors)
+	return string(data)
+}
+
+// saturate gives points approaching max as n grows, two thirds of max at scale
+func saturate(n int, max, scale float64) float64 {
+	return round(max * (1 - math.Exp(-float64(n)/scale)))
+}
+
+func clamp(score float64) float64 {
+	return math.Max(0, math.Min(100, score))
+}
+
+// round rounds to one decimal place
+func round(x float64) float64 {
+	return math.Round(x*10) / 10
+}
+
+// plural formats a count of things, with an optional irregular plural
+func plural(n int, singular string, pluralForm ...string) string {
+	if n == 1 {
+		return "1 " + singular
+	}
+	if len(pluralForm) > 0 {
+		return fmt.Sprintf("%d %s", n, pluralForm[0])
+	}
+	return fmt.Sprintf("%d %ss", n, singular)
+}
@@ -0,0 +1,164 @@
+package trust
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	authmodels "github.com/yerenwgventures/GreatNigeriaLibrary-Foundation/backend/pkg/models"
+	"github.com/yerenwgventures/GreatNigeriaLibrary-Foundation/backend/services/discussion/models"
+)
+
+const day = 24 * time.Hour
+
+func TestScoreNewUser(t *testing.T) {
+	scores := Score(Activity{AccountAge: day}, 0)
+
+	assert.Equal(t, 0.0, scores.Content)
+	assert.Equal(t, 0.3, scores.Community)
+	assert.Equal(t, 50.0, scores.Moderator)
+	assert.InDelta(t, 10.1, scores.Trust, 0.05)
+	assert.Empty(t, scores.Negative)
+	assert.Equal(t, []Factor{
+		{Score: ScoreCommunity, Reason: "account 1 day old", Points: 0.3},
+		{Score: ScoreModerator, Reason: "starting moderator score", Points: 50},
+	}, scores.Positive)
+}
+
+func TestScoreActivity(t *testing.T) {
+	active := Activity{AccountAge: 365 * day, Posts: 200, ReactionsReceived: 400, FlagsUpheld: 3}
+	scores := Score(active, 0)
+	assert.Equal(t, 99.9, scores.Content)
+	assert.InDelta(t, 99.5, scores.Community, 0.1)
+	assert.Equal(t, 65.0, scores.Moderator)
+	assert.Greater(t, scores.Trust, 90.0)
+
+	// Marks against the user lower the scores they belong to
+	marked := active
+	marked.Rejections, marked.FlagsAgainst, marked.Warnings, marked.Penalties, marked.FlagsDismissed = 2, 1, 1, 1, 2
+	scores = Score(marked, 0)
+	assert.Equal(t, 69.9, scores.Content)
+	assert.Equal(t, 10.0, scores.Moderator)
+	assert.Len(t, scores.Negative, 5)
+	assert.Contains(t, scores.Negative, Factor{Score: ScoreModerator, Reason: "1 suspension, restriction or ban", Points: -30})
+
+	// Scores stay within 0 and 100, and manual adjustments apply to the total
+	scores = Score(Activity{Penalties: 3}, 0)
+	assert.Equal(t, 0.0, scores.Moderator)
+	assert.Equal(t, 0.0, scores.Trust)
+	assert.Equal(t, 25.0, Score(Activity{}, 15).Trust)
+
+	// Upheld flags only raise the moderator score so far
+	assert.Equal(t, 80.0, Score(Activity{FlagsUpheld: 50}, 0).Moderator)
+}
+
+func TestDecideHysteresis(t *testing.T) {
+	settings := DefaultSettings()
+
+	assert.Equal(t, models.TrustLevelNewUser, Decide(settings, models.TrustLevelNewUser, 19.9, true))
+	assert.Equal(t, models.TrustLevelBasic, Decide(settings, models.TrustLevelNewUser, 20, true))
+	assert.Equal(t, models.TrustLevelMember, Decide(settings, models.TrustLevelNewUser, 60, true))
+
+	// Members keep their level within the hysteresis below its threshold
+	assert.Equal(t, models.TrustLevelMember, Decide(settings, models.TrustLevelMember, 46, true))
+	assert.Equal(t, models.TrustLevelMember, Decide(settings, models.TrustLevelMember, 45, true))
+	assert.Equal(t, models.TrustLevelBasic, Decide(settings, models.TrustLevelMember, 44.9, true))
+
+	// but must reach the full threshold to be promoted again
+	assert.Equal(t, models.TrustLevelBasic, Decide(settings, models.TrustLevelBasic, 46, true))
+
+	// Leaders must be clean
+	assert.Equal(t, models.TrustLevelLeader, Decide(settings, models.TrustLevelRegular, 95, true))
+	assert.Equal(t, models.TrustLevelRegular, Decide(settings, models.TrustLevelLeader, 95, false))
+}
+
+func TestValidate(t *testing.T) {
+	settings := DefaultSettings()
+	assert.False(t, Validate(&settings).HasErrors())
+
+	settings.MemberScore = 10
+	settings.Hysteresis = 30
+	settings.LinkLevel = "admin"
+	settings.LeaderFlagWeight = -1
+	settings.HideFlagWeight = 0
+	violations := Validate(&settings)
+	require.True(t, violations.HasErrors())
+	assert.Len(t, violations.Errors, 5)
+}
+
+func TestCapabilities(t *testing.T) {
+	settings := DefaultSettings()
+
+	assert.Equal(t, Capabilities{FlagWeight: 0.5}, CapabilitiesOf(settings, models.TrustLevelNewUser))
+	assert.Equal(t, Capabilities{PostLinks: true, FlagWeight: 1}, CapabilitiesOf(settings, models.TrustLevelBasic))
+	assert.Equal(t, Capabilities{PostLinks: true, Attachments: true, FlagWeight: 2}, CapabilitiesOf(settings, models.TrustLevelLeader))
+	assert.Equal(t, Capabilities{PostLinks: true, Attachments: true, FlagWeight: 2}, ModeratorCapabilities(settings))
+}
+
+func TestAuthLevel(t *testing.T) {
+	assert.Equal(t, authmodels.TrustLevelNewUser, AuthLevel(models.TrustLevelBasic, authmodels.TrustLevelTrusted))
+	assert.Equal(t, authmodels.TrustLevelTrusted, AuthLevel(models.TrustLevelMember, authmodels.TrustLevelNewUser))
+	assert.Equal(t, authmodels.TrustLevelExpert, AuthLevel(models.TrustLevelLeader, authmodels.TrustLevelTrusted))
+
+	// Verified users and moderators keep their level
+	assert.Equal(t, authmodels.TrustLevelVerified, AuthLevel(models.TrustLevelNewUser, authmodels.TrustLevelVerified))
+	assert.Equal(t, authmodels.TrustLevelExpert, AuthLevel(models.TrustLevelLeader, authmodels.TrustLevelVerified))
+	assert.Equal(t, authmodels.TrustLevelModerator, AuthLevel(models.TrustLevelLeader, authmodels.TrustLevelModerator))
+}
+
+func TestExplain(t *testing.T) {
+	settings := DefaultSettings()
+	activity := Activity{AccountAge: 30 * day, Posts: 40, ReactionsReceived: 20, Warnings: 1}
+	scores := Score(activity, 0)
+	score := &models.UserTrustScore{TrustLevel: models.TrustLevelMember}
+	Apply(score, activity, scores, time.Now())
+
+	explanation := Explain(settings, score, false)
+	assert.Equal(t, scores.Trust, explanation.TrustScore)
+	assert.Equal(t, 45.0, explanation.KeepScore)
+	require.NotNil(t, explanation.Next)
+	assert.Equal(t, models.TrustLevelRegular, explanation.Next.Level)
+	assert.Equal(t, scores.Positive, explanation.PositiveFactors)
+	assert.Equal(t, scores.Negative, explanation.NegativeFactors)
+	assert.True(t, explanation.Capabilities.Attachments)
+	assert.Contains(t, explanation.Summary, "You are a member with a trust score of")
+	assert.Contains(t, explanation.Summary, "You keep your level while your score stays at 45.0 or above.")
+
+	// Leaders-to-be are told what keeps them back
+	score.TrustLevel = models.TrustLevelRegular
+	score.TrustScore = 92
+	explanation = Explain(settings, score, true)
+	assert.Equal(t, 0.0, explanation.Next.PointsNeeded)
+	assert.Len(t, explanation.Next.Requirements, 1)
+	assert.Contains(t, explanation.Summary, "To become a leader you need no warnings")
+	assert.Contains(t, explanation.Summary, "As a moderator")
+
+	score.TrustLevel = models.TrustLevelLeader
+	assert.Nil(t, Explain(settings, score, false).Next)
+}
+
+func TestFlagWeight(t *testing.T) {
+	assert.Equal(t, 0.5, FlagAccuracy(0, 0))
+	assert.Equal(t, 1.0, FlagWeight(1, 0, 0), "users without reviewed flags flag with their level's weight")
+	assert.Equal(t, 1.83, FlagWeight(1, 10, 0))
+	assert.Equal(t, 0.17, FlagWeight(1, 0, 10))
+	assert.Equal(t, 0.5, FlagWeight(0.5, 3, 3))
+
+	// Each dismissed flag lowers the weight
+	previous := FlagWeight(2, 4, 0)
+	for dismissed := 1; dismissed <= 5; dismissed++ {
+		weight := FlagWeight(2, 4, dismissed)
+		assert.Less(t, weight, previous)
+		previous = weight
+	}
+}
+
+func TestShouldHide(t *testing.T) {
+	settings := DefaultSettings()
+	assert.False(t, ShouldHide(settings, 2.99))
+	assert.True(t, ShouldHide(settings, 3))
+
+	settings.HideFlagWeight = 0
+	assert.False(t, ShouldHide(settings, 10), "unset thresholds never hide")
+}
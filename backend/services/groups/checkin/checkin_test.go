@@ -0,0 +1,54 @@
+package checkin
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSignAndVerify(t *testing.T) {
+	public, private, err := GenerateKey()
+	require.NoError(t, err)
+
+	now := time.Unix(1700000000, 0)
+	claims := Claims{EventID: 7, AttendeeID: 12, UserID: 3, ExpiresAt: now.Add(time.Hour).Unix()}
+	token, err := Sign(private, claims)
+	require.NoError(t, err)
+	assert.Less(t, len(token), 160, "tokens fit a small QR code")
+
+	verified, err := Verify(public, token, 7, now)
+	require.NoError(t, err)
+	assert.Equal(t, claims, *verified)
+
+	_, err = Verify(public, token, 8, now)
+	assert.ErrorIs(t, err, ErrWrongEvent)
+	_, err = Verify(public, token, 7, now.Add(time.Hour))
+	assert.ErrorIs(t, err, ErrExpiredToken)
+
+	otherPublic, _, err := GenerateKey()
+	require.NoError(t, err)
+	_, err = Verify(otherPublic, token, 7, now)
+	assert.ErrorIs(t, err, ErrInvalidSignature, "tokens of other events do not verify")
+}
+
+func TestVerifyRejectsTampering(t *testing.T) {
+	public, private, err := GenerateKey()
+	require.NoError(t, err)
+	now := time.Unix(1700000000, 0)
+	token, err := Sign(private, Claims{EventID: 7, AttendeeID: 12, UserID: 3, ExpiresAt: now.Add(time.Hour).Unix()})
+	require.NoError(t, err)
+
+	// Claim another attendee with the original signature
+	payload, signature, _ := strings.Cut(token, ".")
+	forged := encoding.EncodeToString([]byte(`{"e":7,"a":13,"u":4,"x":1700003600}`)) + "." + signature
+	_, err = Verify(public, forged, 7, now)
+	assert.ErrorIs(t, err, ErrInvalidSignature)
+
+	for _, malformed := range []string{"", payload, payload + ".", payload + ".!!", "." + signature} {
+		_, err = Verify(public, malformed, 7, now)
+		assert.Error(t, err, malformed)
+	}
+}
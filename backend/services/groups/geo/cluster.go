@@ -0,0 +1,136 @@
+package geo
+
+import (
+	"fmt"
+	"math"
+	"sort"
+)
+
+// Clustering grid. Points are grouped by square cells of CellPixels on a
+// web map at the requested zoom level, so markers never overlap on screen.
+const (
+	MaxZoom    = 20
+	CellPixels = 64
+	tilePixels = 256
+
+	// MercatorRadius is the sphere radius of the Web Mercator projection
+	MercatorRadius = 6378137
+	// MaxMercatorLatitude is the latitude at which Web Mercator maps end
+	MaxMercatorLatitude = 85.05112878
+)
+
+// ErrInvalidZoom is returned for zoom levels outside [0, MaxZoom]
+var ErrInvalidZoom = fmt.Errorf("zoom must be between 0 and %d", MaxZoom)
+
+// ValidateZoom checks that a zoom level is within range
+func ValidateZoom(zoom int) error {
+	if zoom < 0 || zoom > MaxZoom {
+		return ErrInvalidZoom
+	}
+	return nil
+}
+
+// CellSizeMeters returns the side of a clustering cell in Web Mercator
+// meters at a zoom level
+func CellSizeMeters(zoom int) float64 {
+	worldMeters := 2 * math.Pi * MercatorRadius
+	return worldMeters / float64(int64(1)<<zoom) * CellPixels / tilePixels
+}
+
+// Cell returns the clustering cell a point falls in at a zoom level. The
+// groups repository computes the same cells in SQL; keep the two in step.
+func Cell(p Point, zoom int) (int64, int64) {
+	size := CellSizeMeters(zoom)
+	latitude := math.Max(-MaxMercatorLatitude, math.Min(MaxMercatorLatitude, p.Latitude))
+	x := radians(p.Longitude) * MercatorRadius
+	y := math.Log(math.Tan(math.Pi/4+radians(latitude)/2)) * MercatorRadius
+	half := HalfCells(zoom)
+	return clampCell(math.Floor(x/size), half), clampCell(math.Floor(y/size), half)
+}
+
+// HalfCells returns half the number of cells across the map at a zoom
+// level. Cells are numbered from -HalfCells to HalfCells-1 on each axis.
+func HalfCells(zoom int) int64 {
+	return int64(1) << (zoom + 1)
+}
+
+// clampCell keeps the edges of the map, which rounding can push past the
+// last cell, on the grid
+func clampCell(cell float64, half int64) int64 {
+	return max(-half, min(half-1, int64(cell)))
+}
+
+// Feature is a point to cluster, such as a group or an event
+type Feature struct {
+	ID    uint
+	Point Point
+}
+
+// Cluster is a set of features that share a cell. The position is the
+// centroid of its features. ID is set when the cluster holds one feature.
+type Cluster struct {
+	Point
+	Count  int         `json:"count"`
+	Bounds BoundingBox `json:"bounds"`
+	ID     uint        `json:"id,omitempty"`
+}
+
+// ClusterFeatures groups features by cell at a zoom level
+func ClusterFeatures(features []Feature, zoom int) []Cluster {
+	type cell struct{ x, y int64 }
+	clusters := make(map[cell]*Cluster)
+	for _, feature := range features {
+		x, y := Cell(feature.Point, zoom)
+		key := cell{x, y}
+		cluster, ok := clusters[key]
+		if !ok {
+			cluster = &Cluster{
+				ID: feature.ID,
+				Bounds: BoundingBox{
+					South: feature.Point.Latitude,
+					West:  feature.Point.Longitude,
+					North: feature.Point.Latitude,
+					East:  feature.Point.Longitude,
+				},
+			}
+			clusters[key] = cluster
+		}
+
+		cluster.Count++
+		// Sum the coordinates here and divide once all are in
+		cluster.Latitude += feature.Point.Latitude
+		cluster.Longitude += feature.Point.Longitude
+		cluster.Bounds.South = math.Min(cluster.Bounds.South, feature.Point.Latitude)
+		cluster.Bounds.West = math.Min(cluster.Bounds.West, feature.Point.Longitude)
+		cluster.Bounds.North = math.Max(cluster.Bounds.North, feature.Point.Latitude)
+		cluster.Bounds.East = math.Max(cluster.Bounds.East, feature.Point.Longitude)
+		cluster.ID = min(cluster.ID, feature.ID)
+	}
+
+	result := make([]Cluster, 0, len(clusters))
+	for _, cluster := range clusters {
+		cluster.Latitude /= float64(cluster.Count)
+		cluster.Longitude /= float64(cluster.Count)
+		if cluster.Count > 1 {
+			cluster.ID = 0
+		}
+		result = append(result, *cluster)
+	}
+	SortClusters(result)
+	return result
+}
+
+// SortClusters orders clusters largest first, then north to south and west
+// to east
+func SortClusters(clusters []Cluster) {
+	sort.Slice(clusters, func(i, j int) bool {
+		a, b := clusters[i], clusters[j]
+		if a.Count != b.Count {
+			return a.Count > b.Count
+		}
+		if a.Latitude != b.Latitude {
+			return a.Latitude > b.Latitude
+		}
+		return a.Longitude < b.Longitude
+	})
+}
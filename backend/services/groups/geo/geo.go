@@ -0,0 +1,170 @@
+// Package geo holds the geometry behind the map and distance queries of the
+// groups service: points, bounding boxes and grid clustering.
+package geo
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// EarthRadiusKm is the mean radius of the earth used for distances
+const EarthRadiusKm = 6371.0088
+
+// Query limits
+const (
+	// MaxRadiusKm is the largest search radius, enough to cover Nigeria
+	// from any point inside it
+	MaxRadiusKm = 1500
+	// MaxResults caps the rows returned by a single query
+	MaxResults = 500
+	// DefaultNearest is the number of nearest results returned by default
+	DefaultNearest = 20
+)
+
+var (
+	// ErrInvalidPoint is returned for coordinates outside the valid range
+	ErrInvalidPoint = errors.New("latitude must be between -90 and 90 and longitude between -180 and 180")
+	// ErrInvalidBounds is returned for bounding boxes that are malformed
+	ErrInvalidBounds = errors.New("bbox must be west,south,east,north in degrees with south not above north")
+	// ErrInvalidRadius is returned for a radius outside (0, MaxRadiusKm]
+	ErrInvalidRadius = fmt.Errorf("radius must be greater than 0 and at most %d km", MaxRadiusKm)
+)
+
+// Point is a position in WGS 84 degrees
+type Point struct {
+	Latitude  float64 `json:"latitude"`
+	Longitude float64 `json:"longitude"`
+}
+
+// Validate checks that the point lies on the earth
+func (p Point) Validate() error {
+	if math.IsNaN(p.Latitude) || math.IsNaN(p.Longitude) ||
+		p.Latitude < -90 || p.Latitude > 90 || p.Longitude < -180 || p.Longitude > 180 {
+		return ErrInvalidPoint
+	}
+	return nil
+}
+
+// ValidateRadius checks that a search radius is within the allowed range
+func ValidateRadius(radiusKm float64) error {
+	if !(radiusKm > 0 && radiusKm <= MaxRadiusKm) {
+		return ErrInvalidRadius
+	}
+	return nil
+}
+
+func radians(degrees float64) float64 {
+	return degrees * math.Pi / 180
+}
+
+func degrees(radians float64) float64 {
+	return radians * 180 / math.Pi
+}
+
+// DistanceKm returns the great-circle distance between two points using the
+// haversine formula
+func DistanceKm(a, b Point) float64 {
+	dLat := radians(b.Latitude - a.Latitude)
+	dLon := radians(b.Longitude - a.Longitude)
+	h := math.Sin(dLat/2)*math.Sin(dLat/2) +
+		math.Cos(radians(a.Latitude))*math.Cos(radians(b.Latitude))*math.Sin(dLon/2)*math.Sin(dLon/2)
+	return 2 * EarthRadiusKm * math.Asin(math.Min(1, math.Sqrt(h)))
+}
+
+// BoundingBox is an area between two parallels and two meridians, such as a
+// map viewport. A box with West greater than East crosses the antimeridian.
+type BoundingBox struct {
+	South float64 `json:"south"`
+	West  float64 `json:"west"`
+	North float64 `json:"north"`
+	East  float64 `json:"east"`
+}
+
+// ParseBoundingBox parses "west,south,east,north", the order used by GeoJSON
+// and most map libraries
+func ParseBoundingBox(s string) (BoundingBox, error) {
+	parts := strings.Split(s, ",")
+	if len(parts) != 4 {
+		return BoundingBox{}, ErrInvalidBounds
+	}
+
+	var values [4]float64
+	for i, part := range parts {
+		value, err := strconv.ParseFloat(strings.TrimSpace(part), 64)
+		if err != nil {
+			return BoundingBox{}, ErrInvalidBounds
+		}
+		values[i] = value
+	}
+
+	box := BoundingBox{West: values[0], South: values[1], East: values[2], North: values[3]}
+	return box, box.Validate()
+}
+
+// Validate checks that the corners of the box are valid points
+func (b BoundingBox) Validate() error {
+	if (Point{Latitude: b.South, Longitude: b.West}).Validate() != nil ||
+		(Point{Latitude: b.North, Longitude: b.East}).Validate() != nil ||
+		b.South > b.North {
+		return ErrInvalidBounds
+	}
+	return nil
+}
+
+// Contains reports whether p lies inside the box, edges included
+func (b BoundingBox) Contains(p Point) bool {
+	if p.Latitude < b.South || p.Latitude > b.North {
+		return false
+	}
+	if b.West <= b.East {
+		return p.Longitude >= b.West && p.Longitude <= b.East
+	}
+	return p.Longitude >= b.West || p.Longitude <= b.East
+}
+
+// Spans splits a box that crosses the antimeridian in two, so each part can
+// be queried with plain range conditions
+func (b BoundingBox) Spans() []BoundingBox {
+	if b.West <= b.East {
+		return []BoundingBox{b}
+	}
+	return []BoundingBox{
+		{South: b.South, West: b.West, North: b.North, East: 180},
+		{South: b.South, West: -180, North: b.North, East: b.East},
+	}
+}
+
+// Around returns the smallest box that holds every point within radiusKm of
+// the center
+func Around(center Point, radiusKm float64) BoundingBox {
+	angle := radiusKm / EarthRadiusKm
+	box := BoundingBox{
+		South: math.Max(center.Latitude-degrees(angle), -90),
+		North: math.Min(center.Latitude+degrees(angle), 90),
+		West:  -180,
+		East:  180,
+	}
+
+	// A circle around a pole covers every meridian
+	if box.South == -90 || box.North == 90 {
+		return box
+	}
+	spread := math.Sin(angle) / math.Cos(radians(center.Latitude))
+	if spread >= 1 {
+		return box
+	}
+
+	dLon := degrees(math.Asin(spread))
+	box.West = center.Longitude - dLon
+	box.East = center.Longitude + dLon
+	if box.West < -180 {
+		box.West += 360
+	}
+	if box.East > 180 {
+		box.East -= 360
+	}
+	return box
+}
@@ -0,0 +1,115 @@
+package geo
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	lagos  = Point{Latitude: 6.5244, Longitude: 3.3792}
+	ibadan = Point{Latitude: 7.3775, Longitude: 3.9470}
+	abuja  = Point{Latitude: 9.0765, Longitude: 7.3986}
+)
+
+func TestDistanceKm(t *testing.T) {
+	assert.InDelta(t, 114, DistanceKm(lagos, ibadan), 1)
+	assert.InDelta(t, 526, DistanceKm(lagos, abuja), 1)
+	assert.Zero(t, DistanceKm(abuja, abuja))
+	assert.InDelta(t, DistanceKm(lagos, abuja), DistanceKm(abuja, lagos), 1e-9)
+}
+
+func TestPointValidate(t *testing.T) {
+	assert.NoError(t, lagos.Validate())
+	assert.NoError(t, Point{Latitude: -90, Longitude: 180}.Validate())
+	assert.ErrorIs(t, Point{Latitude: 91}.Validate(), ErrInvalidPoint)
+	assert.ErrorIs(t, Point{Longitude: -181}.Validate(), ErrInvalidPoint)
+}
+
+func TestValidateRadius(t *testing.T) {
+	assert.NoError(t, ValidateRadius(10))
+	assert.NoError(t, ValidateRadius(MaxRadiusKm))
+	assert.ErrorIs(t, ValidateRadius(0), ErrInvalidRadius)
+	assert.ErrorIs(t, ValidateRadius(MaxRadiusKm+1), ErrInvalidRadius)
+}
+
+func TestParseBoundingBox(t *testing.T) {
+	box, err := ParseBoundingBox("2.7, 4.2, 14.7, 13.9")
+	require.NoError(t, err)
+	assert.Equal(t, BoundingBox{South: 4.2, West: 2.7, North: 13.9, East: 14.7}, box)
+	assert.True(t, box.Contains(lagos))
+	assert.False(t, box.Contains(Point{Latitude: 5.6, Longitude: -0.2}), "Accra is outside Nigeria")
+
+	for _, invalid := range []string{"", "1,2,3", "a,2,3,4", "0,10,1,5", "0,-95,1,5"} {
+		_, err := ParseBoundingBox(invalid)
+		assert.ErrorIs(t, err, ErrInvalidBounds, invalid)
+	}
+}
+
+func TestBoundingBoxAcrossAntimeridian(t *testing.T) {
+	box := BoundingBox{South: -10, West: 170, North: 10, East: -170}
+	assert.True(t, box.Contains(Point{Longitude: 175}))
+	assert.True(t, box.Contains(Point{Longitude: -175}))
+	assert.False(t, box.Contains(Point{Longitude: 0}))
+	assert.Equal(t, []BoundingBox{
+		{South: -10, West: 170, North: 10, East: 180},
+		{South: -10, West: -180, North: 10, East: -170},
+	}, box.Spans())
+}
+
+func TestAround(t *testing.T) {
+	box := Around(lagos, 100)
+	for _, bearing := range []Point{{1, 0}, {-1, 0}, {0, 1}, {0, -1}} {
+		// Points just inside the radius in each direction
+		p := Point{Latitude: lagos.Latitude + bearing.Latitude*0.89, Longitude: lagos.Longitude + bearing.Longitude*0.89}
+		require.Less(t, DistanceKm(lagos, p), 100.0)
+		assert.True(t, box.Contains(p), p)
+	}
+	assert.False(t, box.Contains(abuja))
+
+	wrapped := Around(Point{Longitude: 179.5}, 200)
+	assert.Greater(t, wrapped.West, wrapped.East, "the box crosses the antimeridian")
+	assert.True(t, wrapped.Contains(Point{Longitude: -179.5}))
+
+	polar := Around(Point{Latitude: 89.5}, 200)
+	assert.Equal(t, 90.0, polar.North)
+	assert.Equal(t, -180.0, polar.West)
+	assert.Equal(t, 180.0, polar.East)
+}
+
+func TestClusterFeatures(t *testing.T) {
+	features := []Feature{{ID: 1, Point: lagos}, {ID: 2, Point: ibadan}, {ID: 3, Point: abuja}}
+
+	// Lagos and Ibadan share a cell at zoom 4, Abuja does not
+	clusters := ClusterFeatures(features, 4)
+	require.Len(t, clusters, 2)
+	assert.Equal(t, 2, clusters[0].Count)
+	assert.Zero(t, clusters[0].ID)
+	assert.InDelta(t, (lagos.Latitude+ibadan.Latitude)/2, clusters[0].Latitude, 1e-9)
+	assert.Equal(t, BoundingBox{South: lagos.Latitude, West: lagos.Longitude, North: ibadan.Latitude, East: ibadan.Longitude}, clusters[0].Bounds)
+	assert.Equal(t, 1, clusters[1].Count)
+	assert.Equal(t, uint(3), clusters[1].ID)
+	assert.Equal(t, abuja, clusters[1].Point)
+
+	assert.Len(t, ClusterFeatures(features, 0), 1)
+
+	clusters = ClusterFeatures(features, 10)
+	require.Len(t, clusters, 3)
+	assert.Equal(t, []uint{3, 2, 1}, []uint{clusters[0].ID, clusters[1].ID, clusters[2].ID}, "north to south")
+}
+
+func TestCell(t *testing.T) {
+	// A world tile has 4 by 4 cells around the origin
+	x, y := Cell(Point{}, 0)
+	assert.Equal(t, [2]int64{0, 0}, [2]int64{x, y})
+	x, y = Cell(Point{Latitude: -1, Longitude: -1}, 0)
+	assert.Equal(t, [2]int64{-1, -1}, [2]int64{x, y})
+
+	// Positions on and beyond the edges of the map fall in the edge cells
+	x, y = Cell(Point{Latitude: -90, Longitude: -180}, 0)
+	assert.Equal(t, [2]int64{-2, -2}, [2]int64{x, y})
+	x, y = Cell(Point{Latitude: 90, Longitude: 180}, 0)
+	assert.Equal(t, [2]int64{1, 1}, [2]int64{x, y})
+	assert.ErrorIs(t, ValidateZoom(MaxZoom+1), ErrInvalidZoom)
+}
@@ -0,0 +1,114 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/yerenwgventures/GreatNigeriaLibrary-Foundation/backend/services/groups/ical"
+	"github.com/yerenwgventures/GreatNigeriaLibrary-Foundation/backend/services/groups/service"
+)
+
+// RegisterPublicRoutes registers the routes that need no authentication:
+// calendar feeds, which calendar apps fetch without a session
+func (h *GroupHandler) RegisterPublicRoutes(router *gin.RouterGroup) {
+	groups := router.Group("/groups")
+	{
+		groups.GET("/calendar/:token", h.GetUserCalendar)
+		groups.GET("/:id/calendar.ics", h.GetGroupCalendar)
+	}
+}
+
+// respondCalendar writes iCalendar data. Downloads are offered as a file,
+// feeds are served inline and must not be cached by proxies.
+func respondCalendar(c *gin.Context, body []byte, filename string) {
+	if filename != "" {
+		c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	} else {
+		c.Header("Cache-Control", "private, no-cache")
+	}
+	c.Data(http.StatusOK, ical.ContentType, body)
+}
+
+// GetEventCalendar handles GET /groups/events/:eventId/calendar.ics
+func (h *GroupHandler) GetEventCalendar(c *gin.Context) {
+	// Get event ID from path
+	id, err := strconv.ParseUint(c.Param("eventId"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid event ID"})
+		return
+	}
+
+	body, err := h.groupService.GetEventCalendar(uint(id))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Event not found"})
+		return
+	}
+
+	respondCalendar(c, body, fmt.Sprintf("event-%d.ics", id))
+}
+
+// CreateCalendarToken handles POST /groups/calendar/token. It issues a new
+// secret for the user's calendar feed URL; the previous one stops working.
+func (h *GroupHandler) CreateCalendarToken(c *gin.Context) {
+	// Get user ID from context
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	token, err := h.groupService.CreateCalendarToken(userID.(uint))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"token": token,
+		"path":  "/groups/calendar/" + token + ".ics",
+	})
+}
+
+// GetUserCalendar handles GET /groups/calendar/:token.ics
+func (h *GroupHandler) GetUserCalendar(c *gin.Context) {
+	token := strings.TrimSuffix(c.Param("token"), ".ics")
+
+	body, err := h.groupService.GetUserCalendar(token)
+	if err != nil {
+		respondCalendarError(c, err)
+		return
+	}
+
+	respondCalendar(c, body, "")
+}
+
+// GetGroupCalendar handles GET /groups/:id/calendar.ics for public groups
+func (h *GroupHandler) GetGroupCalendar(c *gin.Context) {
+	// Get group ID from path
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid group ID"})
+		return
+	}
+
+	body, err := h.groupService.GetGroupCalendar(uint(id))
+	if err != nil {
+		respondCalendarError(c, err)
+		return
+	}
+
+	respondCalendar(c, body, "")
+}
+
+// respondCalendarError reports unknown feeds as not found
+func respondCalendarError(c *gin.Context, err error) {
+	if errors.Is(err, service.ErrCalendarNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Calendar not found"})
+		return
+	}
+	c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+}
@@ -0,0 +1,190 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/yerenwgventures/GreatNigeriaLibrary-Foundation/backend/pkg/common/mailer"
+	"github.com/yerenwgventures/GreatNigeriaLibrary-Foundation/backend/services/groups/models"
+)
+
+// fetch sends a GET request as userID, or without authentication when
+// userID is 0, and returns the raw response
+func (api *groupsAPI) fetch(userID uint, path string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, path, nil)
+	if userID != 0 {
+		req.Header.Set(userIDKey, strconv.FormatUint(uint64(userID), 10))
+	}
+	w := httptest.NewRecorder()
+	api.router.ServeHTTP(w, req)
+	return w
+}
+
+// invite returns the calendar attached to an email
+func invite(t *testing.T, msg *mailer.Message) string {
+	require.NotNil(t, msg)
+	require.Len(t, msg.Attachments, 1)
+	return string(msg.Attachments[0].Content)
+}
+
+func TestEventCalendars(t *testing.T) {
+	api := newGroupsAPI(t)
+	group := api.createGroup(gin.H{"name": "Lagos Readers", "type": models.StudyGroup})
+	api.do(owner, http.MethodPost, fmt.Sprintf("/groups/%d/members", group.ID), gin.H{
+		"userId": member, "role": models.RegularMemberRole, "status": models.ActiveMember,
+	}, nil)
+
+	start := time.Now().Add(48 * time.Hour).Truncate(time.Second)
+	eventsPath := fmt.Sprintf("/groups/%d/events", group.ID)
+	details := gin.H{
+		"title": "Book club", "location": "Yaba", "status": models.PlannedEvent,
+		"startTime": start, "endTime": start.Add(2 * time.Hour),
+	}
+	var event, other models.LocalEvent
+	require.Equal(t, http.StatusCreated, api.do(owner, http.MethodPost, eventsPath, details, &event))
+	require.Equal(t, http.StatusCreated, api.do(owner, http.MethodPost, eventsPath, gin.H{
+		"title": "Reading week", "startTime": start, "endTime": start.Add(time.Hour),
+	}, &other))
+	uid := fmt.Sprintf("UID:group-event-%d@library.example.com", event.ID)
+	otherUID := fmt.Sprintf("UID:group-event-%d@library.example.com", other.ID)
+	api.outbox.Reset()
+
+	t.Run("rsvp emails an invite", func(t *testing.T) {
+		attendeesPath := fmt.Sprintf("/groups/events/%d/attendees", event.ID)
+		require.Equal(t, http.StatusCreated, api.do(member, http.MethodPost, attendeesPath, gin.H{"status": "going"}, nil))
+
+		msg := api.outbox.Last()
+		require.NotNil(t, msg)
+		assert.Equal(t, []string{"user2@example.com"}, msg.To)
+		assert.Equal(t, "event_invitation.v1", msg.Tag)
+		assert.Equal(t, "text/calendar; charset=utf-8; method=REQUEST", msg.Attachments[0].ContentType)
+		ics := invite(t, msg)
+		assert.Contains(t, ics, "METHOD:REQUEST\r\n")
+		assert.Contains(t, ics, uid+"\r\n")
+		assert.Contains(t, ics, "SEQUENCE:0\r\n")
+		assert.Contains(t, ics, "STATUS:TENTATIVE\r\n")
+		assert.Contains(t, ics, `ORGANIZER;CN="Ada":mailto:user1@example.com`)
+		assert.Contains(t, ics, `ATTENDEE;CN="Tunde";PARTSTAT=ACCEPTED:mailto:user2@example.com`)
+	})
+
+	t.Run("event download", func(t *testing.T) {
+		w := api.fetch(member, fmt.Sprintf("/groups/events/%d/calendar.ics", event.ID))
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "text/calendar; charset=utf-8", w.Header().Get("Content-Type"))
+		assert.Equal(t, fmt.Sprintf(`attachment; filename="event-%d.ics"`, event.ID), w.Header().Get("Content-Disposition"))
+		assert.Contains(t, w.Body.String(), "METHOD:PUBLISH\r\n")
+		assert.Contains(t, w.Body.String(), "LOCATION:Yaba\r\n")
+		assert.NotContains(t, w.Body.String(), "ATTENDEE", "downloads do not list attendees")
+
+		assert.Equal(t, http.StatusUnauthorized, api.fetch(0, fmt.Sprintf("/groups/events/%d/calendar.ics", event.ID)).Code)
+		assert.Equal(t, http.StatusNotFound, api.fetch(member, "/groups/events/999/calendar.ics").Code)
+	})
+
+	t.Run("personal feed", func(t *testing.T) {
+		var issued struct {
+			Token string `json:"token"`
+			Path  string `json:"path"`
+		}
+		require.Equal(t, http.StatusCreated, api.do(member, http.MethodPost, "/groups/calendar/token", nil, &issued))
+		assert.Equal(t, "/groups/calendar/"+issued.Token+".ics", issued.Path)
+
+		// The feed needs no session and holds only the events the user attends
+		w := api.fetch(0, issued.Path)
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "private, no-cache", w.Header().Get("Cache-Control"))
+		assert.Contains(t, w.Body.String(), uid+"\r\n")
+		assert.NotContains(t, w.Body.String(), otherUID)
+
+		// Issuing a new token revokes the old one
+		var rotated struct {
+			Path string `json:"path"`
+		}
+		require.Equal(t, http.StatusCreated, api.do(member, http.MethodPost, "/groups/calendar/token", nil, &rotated))
+		assert.Equal(t, http.StatusNotFound, api.fetch(0, issued.Path).Code)
+		assert.Equal(t, http.StatusOK, api.fetch(0, rotated.Path).Code)
+		assert.Equal(t, http.StatusNotFound, api.fetch(0, "/groups/calendar/unknown.ics").Code)
+	})
+
+	t.Run("group feed", func(t *testing.T) {
+		w := api.fetch(0, fmt.Sprintf("/groups/%d/calendar.ics", group.ID))
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), "X-WR-CALNAME:Lagos Readers\r\n")
+		assert.Contains(t, w.Body.String(), uid+"\r\n")
+		assert.Contains(t, w.Body.String(), otherUID+"\r\n")
+
+		private := api.createGroup(gin.H{"name": "Committee", "type": models.StudyGroup, "visibility": models.PrivateGroup})
+		assert.Equal(t, http.StatusNotFound, api.fetch(0, fmt.Sprintf("/groups/%d/calendar.ics", private.ID)).Code)
+		assert.Equal(t, http.StatusNotFound, api.fetch(0, "/groups/999/calendar.ics").Code)
+	})
+
+	t.Run("updates raise the sequence", func(t *testing.T) {
+		eventPath := fmt.Sprintf("/groups/events/%d", event.ID)
+		api.outbox.Reset()
+
+		// Renaming the event does not move it in calendars
+		details["title"] = "Book club: chapter 5"
+		var updated models.LocalEvent
+		require.Equal(t, http.StatusOK, api.do(owner, http.MethodPut, eventPath, details, &updated))
+		assert.Equal(t, 0, updated.Sequence)
+		assert.Empty(t, api.outbox.Messages())
+
+		details["startTime"] = start.Add(time.Hour)
+		details["endTime"] = start.Add(3 * time.Hour)
+		require.Equal(t, http.StatusOK, api.do(owner, http.MethodPut, eventPath, details, &updated))
+		assert.Equal(t, 1, updated.Sequence)
+		require.Len(t, api.outbox.Messages(), 2, "the creator and the member attend")
+		msg := api.outbox.Last()
+		assert.Contains(t, msg.Subject, "Updated: Book club: chapter 5")
+		assert.Contains(t, invite(t, msg), "SEQUENCE:1\r\n")
+
+		api.outbox.Reset()
+		details["status"] = models.CancelledEvent
+		require.Equal(t, http.StatusOK, api.do(owner, http.MethodPut, eventPath, details, &updated))
+		assert.Equal(t, 2, updated.Sequence)
+		require.Len(t, api.outbox.Messages(), 2)
+		msg = api.outbox.Last()
+		assert.Contains(t, msg.Subject, "Cancelled: ")
+		assert.Equal(t, "text/calendar; charset=utf-8; method=CANCEL", msg.Attachments[0].ContentType)
+		ics := invite(t, msg)
+		assert.Contains(t, ics, "METHOD:CANCEL\r\n")
+		assert.Contains(t, ics, "STATUS:CANCELLED\r\n")
+		assert.Contains(t, ics, "SEQUENCE:2\r\n")
+
+		// The public feed shows the cancellation
+		w := api.fetch(0, fmt.Sprintf("/groups/%d/calendar.ics", group.ID))
+		assert.Contains(t, w.Body.String(), "STATUS:CANCELLED\r\n")
+	})
+
+	t.Run("withdrawing removes the invite", func(t *testing.T) {
+		attendeePath := fmt.Sprintf("/groups/events/%d/attendees/%d", other.ID, member)
+		require.Equal(t, http.StatusCreated, api.do(member, http.MethodPost, fmt.Sprintf("/groups/events/%d/attendees", other.ID), gin.H{"status": "maybe"}, nil))
+		assert.Contains(t, invite(t, api.outbox.Last()), "PARTSTAT=TENTATIVE")
+
+		api.outbox.Reset()
+		require.Equal(t, http.StatusOK, api.do(member, http.MethodPut, attendeePath, gin.H{"status": "not_going"}, nil))
+		msg := api.outbox.Last()
+		assert.Contains(t, msg.Subject, "Not attending: Reading week")
+		assert.Contains(t, invite(t, msg), "METHOD:CANCEL\r\n")
+		assert.Contains(t, invite(t, msg), "STATUS:TENTATIVE\r\n", "the event itself goes ahead")
+
+		api.outbox.Reset()
+		require.Equal(t, http.StatusOK, api.do(member, http.MethodPut, attendeePath, gin.H{"status": "not_going"}, nil))
+		assert.Empty(t, api.outbox.Messages(), "nothing changed")
+	})
+
+	t.Run("deleting cancels the invites", func(t *testing.T) {
+		api.outbox.Reset()
+		require.Equal(t, http.StatusOK, api.do(owner, http.MethodDelete, fmt.Sprintf("/groups/events/%d", other.ID), nil, nil))
+		require.Len(t, api.outbox.Messages(), 1, "only the creator still attends")
+		ics := invite(t, api.outbox.Last())
+		assert.Contains(t, ics, "METHOD:CANCEL\r\n")
+		assert.Contains(t, ics, "SEQUENCE:1\r\n")
+	})
+}
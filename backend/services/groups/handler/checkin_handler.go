@@ -0,0 +1,134 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/yerenwgventures/GreatNigeriaLibrary-Foundation/backend/services/groups/service"
+)
+
+// GetCheckInToken handles GET /groups/events/:eventId/checkin/token. It
+// returns the signed token the attendee shows as a QR code at the door.
+func (h *GroupHandler) GetCheckInToken(c *gin.Context) {
+	// Get user ID from context
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	// Get event ID from path
+	eventID, err := strconv.ParseUint(c.Param("eventId"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid event ID"})
+		return
+	}
+
+	token, err := h.groupService.GetCheckInToken(uint(eventID), userID.(uint))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, token)
+}
+
+// GetCheckInKey handles GET /groups/events/:eventId/checkin/key. Organisers'
+// devices fetch the public key before the event to verify tokens offline.
+func (h *GroupHandler) GetCheckInKey(c *gin.Context) {
+	// Get user ID from context
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	// Get event ID from path
+	eventID, err := strconv.ParseUint(c.Param("eventId"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid event ID"})
+		return
+	}
+
+	key, err := h.groupService.GetCheckInKey(uint(eventID), userID.(uint))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, key)
+}
+
+// ScanCheckIn handles POST /groups/events/:eventId/checkin/scan for a single
+// token scanned online
+func (h *GroupHandler) ScanCheckIn(c *gin.Context) {
+	// Get user ID from context
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	// Get event ID from path
+	eventID, err := strconv.ParseUint(c.Param("eventId"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid event ID"})
+		return
+	}
+
+	// Bind request body
+	var checkIn service.CheckIn
+	if err := c.ShouldBindJSON(&checkIn); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	results, err := h.groupService.CheckInBatch(uint(eventID), userID.(uint), []service.CheckIn{checkIn})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, results[0])
+}
+
+// CheckInBatch handles POST /groups/events/:eventId/checkin/batch. Devices
+// that scanned offline upload their check-ins with the time of each scan.
+func (h *GroupHandler) CheckInBatch(c *gin.Context) {
+	// Get user ID from context
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	// Get event ID from path
+	eventID, err := strconv.ParseUint(c.Param("eventId"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid event ID"})
+		return
+	}
+
+	// Bind request body
+	var request struct {
+		CheckIns []service.CheckIn `json:"checkIns" binding:"required,dive"`
+	}
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	results, err := h.groupService.CheckInBatch(uint(eventID), userID.(uint), request.CheckIns)
+	if err != nil {
+		if errors.Is(err, service.ErrCheckInBatchTooLarge) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": results})
+}
@@ -0,0 +1,190 @@
+package handler
+
+import (
+	"crypto/ed25519"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/yerenwgventures/GreatNigeriaLibrary-Foundation/backend/services/groups/checkin"
+	"github.com/yerenwgventures/GreatNigeriaLibrary-Foundation/backend/services/groups/models"
+	"github.com/yerenwgventures/GreatNigeriaLibrary-Foundation/backend/services/groups/service"
+)
+
+func TestEventCheckIn(t *testing.T) {
+	api := newGroupsAPI(t)
+	group := api.createGroup(gin.H{"name": "Ikeja Town Hall", "type": models.LocalActionGroup})
+	for _, userID := range []uint{member, outsider, newcomer} {
+		require.Equal(t, http.StatusCreated, api.do(owner, http.MethodPost, fmt.Sprintf("/groups/%d/members", group.ID), gin.H{
+			"userId": userID, "role": models.RegularMemberRole, "status": models.ActiveMember,
+		}, nil))
+	}
+
+	start := time.Now().Add(time.Hour).Truncate(time.Second)
+	details := gin.H{
+		"title": "Town hall", "status": models.ConfirmedEvent, "maxAttendees": 2,
+		"startTime": start, "endTime": start.Add(2 * time.Hour),
+	}
+	var event models.LocalEvent
+	require.Equal(t, http.StatusCreated, api.do(owner, http.MethodPost, fmt.Sprintf("/groups/%d/events", group.ID), details, &event))
+	eventPath := fmt.Sprintf("/groups/events/%d", event.ID)
+	attendeesPath := eventPath + "/attendees"
+	tokenPath := eventPath + "/checkin/token"
+
+	status := func(userID uint) string {
+		var attendee models.EventAttendee
+		require.Equal(t, http.StatusOK, api.do(owner, http.MethodGet, fmt.Sprintf("%s/%d", attendeesPath, userID), nil, &attendee))
+		return attendee.Status
+	}
+
+	var memberToken, outsiderToken service.CheckInToken
+
+	t.Run("full events waitlist", func(t *testing.T) {
+		// The creator and the member take both places
+		for _, userID := range []uint{member, outsider, newcomer} {
+			require.Equal(t, http.StatusCreated, api.do(userID, http.MethodPost, attendeesPath, gin.H{"status": "going"}, nil))
+		}
+		assert.Equal(t, "going", status(member))
+		assert.Equal(t, "waitlisted", status(outsider))
+		assert.Equal(t, "waitlisted", status(newcomer))
+
+		assert.Equal(t, http.StatusInternalServerError, api.do(outsider, http.MethodGet, tokenPath, nil, nil), "waitlisted attendees cannot check in")
+		require.Equal(t, http.StatusOK, api.do(member, http.MethodGet, tokenPath, nil, &memberToken))
+		assert.Equal(t, start.Add(4*time.Hour).Unix(), memberToken.ExpiresAt.Unix(), "tokens expire two hours after the event")
+	})
+
+	t.Run("cancelling promotes the waitlist", func(t *testing.T) {
+		api.outbox.Reset()
+		require.Equal(t, http.StatusOK, api.do(member, http.MethodPut, fmt.Sprintf("%s/%d", attendeesPath, member), gin.H{"status": "not_going"}, nil))
+		assert.Equal(t, "going", status(outsider), "the first to join the waitlist moves up")
+		assert.Equal(t, "waitlisted", status(newcomer))
+
+		var promoted bool
+		for _, msg := range api.outbox.Messages() {
+			if msg.To[0] == "user3@example.com" {
+				promoted = true
+				assert.Contains(t, invite(t, &msg), "PARTSTAT=ACCEPTED")
+			}
+		}
+		assert.True(t, promoted, "the promoted attendee is sent an invite")
+
+		require.Equal(t, http.StatusOK, api.do(outsider, http.MethodGet, tokenPath, nil, &outsiderToken))
+	})
+
+	t.Run("devices verify tokens offline", func(t *testing.T) {
+		assert.Equal(t, http.StatusInternalServerError, api.do(member, http.MethodGet, eventPath+"/checkin/key", nil, nil), "only organisers get the key")
+
+		var key service.CheckInKey
+		require.Equal(t, http.StatusOK, api.do(owner, http.MethodGet, eventPath+"/checkin/key", nil, &key))
+		assert.Equal(t, checkin.Algorithm, key.Algorithm)
+		public, err := base64.RawURLEncoding.DecodeString(key.PublicKey)
+		require.NoError(t, err)
+
+		claims, err := checkin.Verify(ed25519.PublicKey(public), outsiderToken.Token, event.ID, time.Now())
+		require.NoError(t, err)
+		assert.Equal(t, outsider, claims.UserID)
+	})
+
+	t.Run("batch upload reconciles scans", func(t *testing.T) {
+		now := time.Now().Truncate(time.Second)
+		var results struct {
+			Data []service.CheckInResult `json:"data"`
+		}
+		require.Equal(t, http.StatusOK, api.do(owner, http.MethodPost, eventPath+"/checkin/batch", gin.H{"checkIns": []gin.H{
+			{"token": outsiderToken.Token, "scannedAt": now.Add(-10 * time.Minute)},
+			{"token": outsiderToken.Token, "scannedAt": now.Add(-20 * time.Minute)},
+			{"token": memberToken.Token, "scannedAt": now.Add(-5 * time.Minute)},
+			{"token": "not-a-token"},
+		}}, &results))
+		require.Len(t, results.Data, 4)
+		assert.Equal(t, service.CheckInAccepted, results.Data[0].Status)
+		assert.Equal(t, service.CheckInDuplicate, results.Data[1].Status, "a second device scanned the same attendee")
+		assert.Equal(t, service.CheckInRejected, results.Data[2].Status, "the member withdrew their RSVP")
+		assert.Equal(t, service.CheckInInvalid, results.Data[3].Status)
+
+		var attendee models.EventAttendee
+		require.Equal(t, http.StatusOK, api.do(owner, http.MethodGet, fmt.Sprintf("%s/%d", attendeesPath, outsider), nil, &attendee))
+		assert.True(t, attendee.CheckedIn)
+		require.NotNil(t, attendee.CheckInTime)
+		assert.True(t, attendee.CheckInTime.Equal(now.Add(-20*time.Minute)), "the earliest scan is kept")
+		require.NotNil(t, attendee.CheckedInByID)
+		assert.Equal(t, owner, *attendee.CheckedInByID)
+
+		// Scanning again online is a duplicate too
+		var result service.CheckInResult
+		require.Equal(t, http.StatusOK, api.do(owner, http.MethodPost, eventPath+"/checkin/scan", gin.H{"token": outsiderToken.Token}, &result))
+		assert.Equal(t, service.CheckInDuplicate, result.Status)
+
+		assert.Equal(t, http.StatusInternalServerError, api.do(member, http.MethodPost, eventPath+"/checkin/scan", gin.H{"token": outsiderToken.Token}, nil))
+		assert.Equal(t, http.StatusBadRequest, api.do(owner, http.MethodPost, eventPath+"/checkin/batch", gin.H{"checkIns": []gin.H{{"scannedAt": now}}}, nil))
+	})
+
+	t.Run("tokens of other events are invalid", func(t *testing.T) {
+		var other models.LocalEvent
+		require.Equal(t, http.StatusCreated, api.do(owner, http.MethodPost, fmt.Sprintf("/groups/%d/events", group.ID), gin.H{
+			"title": "Rally", "startTime": start, "endTime": start.Add(time.Hour),
+		}, &other))
+
+		var result service.CheckInResult
+		require.Equal(t, http.StatusOK, api.do(owner, http.MethodPost, fmt.Sprintf("/groups/events/%d/checkin/scan", other.ID), gin.H{"token": outsiderToken.Token}, &result))
+		assert.Equal(t, service.CheckInInvalid, result.Status)
+	})
+
+	t.Run("raising the limit promotes the waitlist", func(t *testing.T) {
+		require.Equal(t, http.StatusOK, api.do(member, http.MethodPut, fmt.Sprintf("%s/%d", attendeesPath, member), gin.H{"status": "going"}, nil))
+		assert.Equal(t, "waitlisted", status(member), "the member rejoins behind the newcomer")
+
+		details["maxAttendees"] = 3
+		require.Equal(t, http.StatusOK, api.do(owner, http.MethodPut, eventPath, details, nil))
+		assert.Equal(t, "going", status(newcomer))
+		assert.Equal(t, "waitlisted", status(member))
+	})
+}
+
+func TestConcurrentRSVPsDoNotOverbook(t *testing.T) {
+	api := newGroupsAPI(t)
+	group := api.createGroup(gin.H{"name": "Surulere Rally", "type": models.LocalActionGroup})
+	rsvps := []uint{member, outsider, newcomer}
+	for _, userID := range rsvps {
+		require.Equal(t, http.StatusCreated, api.do(owner, http.MethodPost, fmt.Sprintf("/groups/%d/members", group.ID), gin.H{
+			"userId": userID, "role": models.RegularMemberRole, "status": models.ActiveMember,
+		}, nil))
+	}
+
+	// The creator takes one of the two places
+	start := time.Now().Add(time.Hour).Truncate(time.Second)
+	var event models.LocalEvent
+	require.Equal(t, http.StatusCreated, api.do(owner, http.MethodPost, fmt.Sprintf("/groups/%d/events", group.ID), gin.H{
+		"title": "Rally", "status": models.ConfirmedEvent, "maxAttendees": 2,
+		"startTime": start, "endTime": start.Add(2 * time.Hour),
+	}, &event))
+	attendeesPath := fmt.Sprintf("/groups/events/%d/attendees", event.ID)
+
+	var wg sync.WaitGroup
+	codes := make([]int, len(rsvps))
+	for i, userID := range rsvps {
+		wg.Add(1)
+		go func(i int, userID uint) {
+			defer wg.Done()
+			codes[i] = api.do(userID, http.MethodPost, attendeesPath, gin.H{"status": "going"}, nil)
+		}(i, userID)
+	}
+	wg.Wait()
+
+	going := 0
+	for i, userID := range rsvps {
+		require.Equal(t, http.StatusCreated, codes[i])
+		var attendee models.EventAttendee
+		require.Equal(t, http.StatusOK, api.do(owner, http.MethodGet, fmt.Sprintf("%s/%d", attendeesPath, userID), nil, &attendee))
+		if attendee.Status == "going" {
+			going++
+		}
+	}
+	assert.Equal(t, 1, going, "only one of the RSVPs gets the last place")
+}
@@ -0,0 +1,230 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/yerenwgventures/GreatNigeriaLibrary-Foundation/backend/services/groups/geo"
+)
+
+// bindPoint reads the latitude and longitude query parameters
+func bindPoint(c *gin.Context) (geo.Point, bool) {
+	latitude, latErr := strconv.ParseFloat(c.Query("latitude"), 64)
+	longitude, lonErr := strconv.ParseFloat(c.Query("longitude"), 64)
+	if latErr != nil || lonErr != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Latitude and longitude are required"})
+		return geo.Point{}, false
+	}
+	return geo.Point{Latitude: latitude, Longitude: longitude}, true
+}
+
+// bindBounds reads the bbox query parameter, given as west,south,east,north
+func bindBounds(c *gin.Context) (geo.BoundingBox, bool) {
+	bounds, err := geo.ParseBoundingBox(c.Query("bbox"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return geo.BoundingBox{}, false
+	}
+	return bounds, true
+}
+
+// bindInt reads an integer query parameter
+func bindInt(c *gin.Context, name, defaultValue string) (int, bool) {
+	value, err := strconv.Atoi(c.DefaultQuery(name, defaultValue))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid " + name})
+		return 0, false
+	}
+	return value, true
+}
+
+// bindRadius reads the radius query parameter in km, 10 by default
+func bindRadius(c *gin.Context) (float64, bool) {
+	radius, err := strconv.ParseFloat(c.DefaultQuery("radius", "10"), 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid radius"})
+		return 0, false
+	}
+	return radius, true
+}
+
+// respondGeoError reports invalid search parameters as bad requests
+func respondGeoError(c *gin.Context, err error) {
+	for _, invalid := range []error{geo.ErrInvalidPoint, geo.ErrInvalidBounds, geo.ErrInvalidRadius, geo.ErrInvalidZoom} {
+		if errors.Is(err, invalid) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+	c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+}
+
+// GetNearbyGroups handles GET /groups/nearby
+func (h *GroupHandler) GetNearbyGroups(c *gin.Context) {
+	origin, ok := bindPoint(c)
+	if !ok {
+		return
+	}
+	radius, ok := bindRadius(c)
+	if !ok {
+		return
+	}
+
+	// Get nearby groups
+	groups, err := h.groupService.GetGroupsByLocation(origin.Latitude, origin.Longitude, radius)
+	if err != nil {
+		respondGeoError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, groups)
+}
+
+// GetNearestGroups handles GET /groups/nearest
+func (h *GroupHandler) GetNearestGroups(c *gin.Context) {
+	origin, ok := bindPoint(c)
+	if !ok {
+		return
+	}
+	limit, ok := bindInt(c, "limit", "0")
+	if !ok {
+		return
+	}
+
+	// Get nearest groups
+	groups, err := h.groupService.GetNearestGroups(origin, limit)
+	if err != nil {
+		respondGeoError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, groups)
+}
+
+// GetGroupsInBounds handles GET /groups/bounds
+func (h *GroupHandler) GetGroupsInBounds(c *gin.Context) {
+	bounds, ok := bindBounds(c)
+	if !ok {
+		return
+	}
+	limit, ok := bindInt(c, "limit", "0")
+	if !ok {
+		return
+	}
+
+	// Get groups in the viewport
+	groups, err := h.groupService.GetGroupsInBounds(bounds, limit)
+	if err != nil {
+		respondGeoError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, groups)
+}
+
+// GetGroupClusters handles GET /groups/clusters
+func (h *GroupHandler) GetGroupClusters(c *gin.Context) {
+	bounds, ok := bindBounds(c)
+	if !ok {
+		return
+	}
+	zoom, ok := bindInt(c, "zoom", "")
+	if !ok {
+		return
+	}
+
+	// Cluster groups in the viewport
+	clusters, err := h.groupService.ClusterGroups(bounds, zoom)
+	if err != nil {
+		respondGeoError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, clusters)
+}
+
+// GetNearbyEvents handles GET /groups/events/nearby
+func (h *GroupHandler) GetNearbyEvents(c *gin.Context) {
+	origin, ok := bindPoint(c)
+	if !ok {
+		return
+	}
+	radius, ok := bindRadius(c)
+	if !ok {
+		return
+	}
+
+	// Get nearby events
+	events, err := h.groupService.GetEventsByLocation(origin.Latitude, origin.Longitude, radius)
+	if err != nil {
+		respondGeoError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, events)
+}
+
+// GetNearestEvents handles GET /groups/events/nearest
+func (h *GroupHandler) GetNearestEvents(c *gin.Context) {
+	origin, ok := bindPoint(c)
+	if !ok {
+		return
+	}
+	limit, ok := bindInt(c, "limit", "0")
+	if !ok {
+		return
+	}
+
+	// Get nearest events
+	events, err := h.groupService.GetNearestEvents(origin, limit)
+	if err != nil {
+		respondGeoError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, events)
+}
+
+// GetEventsInBounds handles GET /groups/events/bounds
+func (h *GroupHandler) GetEventsInBounds(c *gin.Context) {
+	bounds, ok := bindBounds(c)
+	if !ok {
+		return
+	}
+	limit, ok := bindInt(c, "limit", "0")
+	if !ok {
+		return
+	}
+
+	// Get events in the viewport
+	events, err := h.groupService.GetEventsInBounds(bounds, limit)
+	if err != nil {
+		respondGeoError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, events)
+}
+
+// GetEventClusters handles GET /groups/events/clusters
+func (h *GroupHandler) GetEventClusters(c *gin.Context) {
+	bounds, ok := bindBounds(c)
+	if !ok {
+		return
+	}
+	zoom, ok := bindInt(c, "zoom", "")
+	if !ok {
+		return
+	}
+
+	// Cluster events in the viewport
+	clusters, err := h.groupService.ClusterEvents(bounds, zoom)
+	if err != nil {
+		respondGeoError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, clusters)
+}
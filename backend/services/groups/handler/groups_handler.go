@@ -35,6 +35,15 @@ func (h *GroupHandler) RegisterRoutes(router *gin.RouterGroup) {
 		groups.GET("/nearby", h.GetNearbyGroups)
 		groups.GET("/search", h.SearchGroups)
 
+		// Map operations
+		groups.GET("/nearest", h.GetNearestGroups)
+		groups.GET("/bounds", h.GetGroupsInBounds)
+		groups.GET("/clusters", h.GetGroupClusters)
+		groups.GET("/events/nearby", h.GetNearbyEvents)
+		groups.GET("/events/nearest", h.GetNearestEvents)
+		groups.GET("/events/bounds", h.GetEventsInBounds)
+		groups.GET("/events/clusters", h.GetEventClusters)
+
 		// Member operations
 		groups.GET("/:id/members", h.GetGroupMembers)
 		groups.POST("/:id/members", h.AddMember)
@@ -106,6 +115,16 @@ func (h *GroupHandler) RegisterRoutes(router *gin.RouterGroup) {
 		groups.POST("/join-requests/:requestId/reject", h.RejectJoinRequest)
 		groups.DELETE("/join-requests/:requestId", h.CancelJoinRequest)
 
+		// Calendar operations
+		groups.GET("/events/:eventId/calendar.ics", h.GetEventCalendar)
+		groups.POST("/calendar/token", h.CreateCalendarToken)
+
+		// Check-in operations
+		groups.GET("/events/:eventId/checkin/token", h.GetCheckInToken)
+		groups.GET("/events/:eventId/checkin/key", h.GetCheckInKey)
+		groups.POST("/events/:eventId/checkin/scan", h.ScanCheckIn)
+		groups.POST("/events/:eventId/checkin/batch", h.CheckInBatch)
+
 		// User-specific operations
 		groups.GET("/user/:userId", h.GetUserGroups)
 		groups.GET("/user/:userId/events/upcoming", h.GetUserUpcomingEvents)
@@ -161,7 +180,7 @@ func (h *GroupHandler) GetGroupByID(c *gin.Context) {
 // CreateGroup handles POST /groups
 func (h *GroupHandler) CreateGroup(c *gin.Context) {
 	// Get user ID from context
-	userID, exists := c.Get("userID")
+	userID, exists := c.Get("user_id")
 	if !exists {
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
 		return
@@ -190,7 +209,7 @@ func (h *GroupHandler) CreateGroup(c *gin.Context) {
 // UpdateGroup handles PUT /groups/:id
 func (h *GroupHandler) UpdateGroup(c *gin.Context) {
 	// Get user ID from context
-	userID, exists := c.Get("userID")
+	userID, exists := c.Get("user_id")
 	if !exists {
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
 		return
@@ -223,7 +242,7 @@ func (h *GroupHandler) UpdateGroup(c *gin.Context) {
 // DeleteGroup handles DELETE /groups/:id
 func (h *GroupHandler) DeleteGroup(c *gin.Context) {
 	// Get user ID from context
-	userID, exists := c.Get("userID")
+	userID, exists := c.Get("user_id")
 	if !exists {
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
 		return
@@ -246,29 +265,6 @@ func (h *GroupHandler) DeleteGroup(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"message": "Group deleted successfully"})
 }
 
-// GetNearbyGroups handles GET /groups/nearby
-func (h *GroupHandler) GetNearbyGroups(c *gin.Context) {
-	// Get query parameters
-	latitude, _ := strconv.ParseFloat(c.Query("latitude"), 64)
-	longitude, _ := strconv.ParseFloat(c.Query("longitude"), 64)
-	radius, _ := strconv.ParseFloat(c.DefaultQuery("radius", "10"), 64) // Default 10km
-
-	// Validate coordinates
-	if latitude == 0 && longitude == 0 {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Latitude and longitude are required"})
-		return
-	}
-
-	// Get nearby groups
-	groups, err := h.groupService.GetGroupsByLocation(latitude, longitude, radius)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
-		return
-	}
-
-	c.JSON(http.StatusOK, groups)
-}
-
 // SearchGroups handles GET /groups/search
 func (h *GroupHandler) SearchGroups(c *gin.Context) {
 	// Get query parameters
@@ -359,7 +355,7 @@ func (h *GroupHandler) GetGroupMembers(c *gin.Context) {
 // AddMember handles POST /groups/:id/members
 func (h *GroupHandler) AddMember(c *gin.Context) {
 	// Get user ID from context
-	userID, exists := c.Get("userID")
+	userID, exists := c.Get("user_id")
 	if !exists {
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
 		return
@@ -422,7 +418,7 @@ func (h *GroupHandler) GetGroupMember(c *gin.Context) {
 // UpdateMember handles PUT /groups/:id/members/:userId
 func (h *GroupHandler) UpdateMember(c *gin.Context) {
 	// Get user ID from context
-	userID, exists := c.Get("userID")
+	userID, exists := c.Get("user_id")
 	if !exists {
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
 		return
@@ -464,7 +460,7 @@ func (h *GroupHandler) UpdateMember(c *gin.Context) {
 // RemoveMember handles DELETE /groups/:id/members/:userId
 func (h *GroupHandler) RemoveMember(c *gin.Context) {
 	// Get user ID from context
-	userID, exists := c.Get("userID")
+	userID, exists := c.Get("user_id")
 	if !exists {
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
 		return
@@ -528,7 +524,7 @@ func (h *GroupHandler) GetGroupEvents(c *gin.Context) {
 // CreateEvent handles POST /groups/:id/events
 func (h *GroupHandler) CreateEvent(c *gin.Context) {
 	// Get user ID from context
-	userID, exists := c.Get("userID")
+	userID, exists := c.Get("user_id")
 	if !exists {
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
 		return
@@ -584,7 +580,7 @@ func (h *GroupHandler) GetEventByID(c *gin.Context) {
 // UpdateEvent handles PUT /groups/events/:eventId
 func (h *GroupHandler) UpdateEvent(c *gin.Context) {
 	// Get user ID from context
-	userID, exists := c.Get("userID")
+	userID, exists := c.Get("user_id")
 	if !exists {
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
 		return
@@ -617,7 +613,7 @@ func (h *GroupHandler) UpdateEvent(c *gin.Context) {
 // DeleteEvent handles DELETE /groups/events/:eventId
 func (h *GroupHandler) DeleteEvent(c *gin.Context) {
 	// Get user ID from context
-	userID, exists := c.Get("userID")
+	userID, exists := c.Get("user_id")
 	if !exists {
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
 		return
@@ -706,7 +702,7 @@ func (h *GroupHandler) GetEventAttendees(c *gin.Context) {
 // AddAttendee handles POST /groups/events/:eventId/attendees
 func (h *GroupHandler) AddAttendee(c *gin.Context) {
 	// Get user ID from context
-	userID, exists := c.Get("userID")
+	userID, exists := c.Get("user_id")
 	if !exists {
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
 		return
@@ -766,7 +762,7 @@ func (h *GroupHandler) GetEventAttendee(c *gin.Context) {
 // UpdateAttendee handles PUT /groups/events/:eventId/attendees/:userId
 func (h *GroupHandler) UpdateAttendee(c *gin.Context) {
 	// Get user ID from context
-	userID, exists := c.Get("userID")
+	userID, exists := c.Get("user_id")
 	if !exists {
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
 		return
@@ -825,7 +821,7 @@ func (h *GroupHandler) UpdateAttendee(c *gin.Context) {
 // RemoveAttendee handles DELETE /groups/events/:eventId/attendees/:userId
 func (h *GroupHandler) RemoveAttendee(c *gin.Context) {
 	// Get user ID from context
-	userID, exists := c.Get("userID")
+	userID, exists := c.Get("user_id")
 	if !exists {
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
 		return
@@ -875,7 +871,7 @@ func (h *GroupHandler) RemoveAttendee(c *gin.Context) {
 // CheckInAttendee handles POST /groups/events/:eventId/attendees/:userId/checkin
 func (h *GroupHandler) CheckInAttendee(c *gin.Context) {
 	// Get user ID from context
-	userID, exists := c.Get("userID")
+	userID, exists := c.Get("user_id")
 	if !exists {
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
 		return
@@ -903,3 +899,1165 @@ func (h *GroupHandler) CheckInAttendee(c *gin.Context) {
 
 	c.JSON(http.StatusOK, attendee)
 }
+
+// GetGroupResources handles GET /groups/:id/resources
+func (h *GroupHandler) GetGroupResources(c *gin.Context) {
+	// Get group ID from path
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid group ID"})
+		return
+	}
+
+	// Get query parameters
+	resourceType := c.Query("type")
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	pageSize, _ := strconv.Atoi(c.DefaultQuery("pageSize", "10"))
+
+	// Get group resources
+	resources, total, err := h.groupService.GetResourcesByGroupID(uint(id), resourceType, page, pageSize)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": resources,
+		"meta": gin.H{
+			"page":       page,
+			"pageSize":   pageSize,
+			"total":      total,
+			"totalPages": (total + int64(pageSize) - 1) / int64(pageSize),
+		},
+	})
+}
+
+// CreateGroupResource handles POST /groups/:id/resources
+func (h *GroupHandler) CreateGroupResource(c *gin.Context) {
+	// Get user ID from context
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	// Get group ID from path
+	groupID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid group ID"})
+		return
+	}
+
+	// Bind request body
+	var resource models.SharedResource
+	if err := c.ShouldBindJSON(&resource); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Set group ID and creator ID
+	resource.GroupID = uint(groupID)
+	resource.EventID = nil
+	resource.ActionID = nil
+	resource.CreatedByID = userID.(uint)
+
+	// Create resource
+	createdResource, err := h.groupService.CreateResource(&resource)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, createdResource)
+}
+
+// GetEventResources handles GET /groups/events/:eventId/resources
+func (h *GroupHandler) GetEventResources(c *gin.Context) {
+	// Get event ID from path
+	id, err := strconv.ParseUint(c.Param("eventId"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid event ID"})
+		return
+	}
+
+	// Get query parameters
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	pageSize, _ := strconv.Atoi(c.DefaultQuery("pageSize", "10"))
+
+	// Get event resources
+	resources, total, err := h.groupService.GetResourcesByEventID(uint(id), page, pageSize)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": resources,
+		"meta": gin.H{
+			"page":       page,
+			"pageSize":   pageSize,
+			"total":      total,
+			"totalPages": (total + int64(pageSize) - 1) / int64(pageSize),
+		},
+	})
+}
+
+// CreateEventResource handles POST /groups/events/:eventId/resources
+func (h *GroupHandler) CreateEventResource(c *gin.Context) {
+	// Get user ID from context
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	// Get event ID from path
+	eventID, err := strconv.ParseUint(c.Param("eventId"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid event ID"})
+		return
+	}
+
+	// Bind request body
+	var resource models.SharedResource
+	if err := c.ShouldBindJSON(&resource); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Set event ID and creator ID
+	id := uint(eventID)
+	resource.GroupID = 0
+	resource.EventID = &id
+	resource.ActionID = nil
+	resource.CreatedByID = userID.(uint)
+
+	// Create resource
+	createdResource, err := h.groupService.CreateResource(&resource)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, createdResource)
+}
+
+// GetActionResources handles GET /groups/actions/:actionId/resources
+func (h *GroupHandler) GetActionResources(c *gin.Context) {
+	// Get action ID from path
+	id, err := strconv.ParseUint(c.Param("actionId"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid action ID"})
+		return
+	}
+
+	// Get query parameters
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	pageSize, _ := strconv.Atoi(c.DefaultQuery("pageSize", "10"))
+
+	// Get action resources
+	resources, total, err := h.groupService.GetResourcesByActionID(uint(id), page, pageSize)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": resources,
+		"meta": gin.H{
+			"page":       page,
+			"pageSize":   pageSize,
+			"total":      total,
+			"totalPages": (total + int64(pageSize) - 1) / int64(pageSize),
+		},
+	})
+}
+
+// CreateActionResource handles POST /groups/actions/:actionId/resources
+func (h *GroupHandler) CreateActionResource(c *gin.Context) {
+	// Get user ID from context
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	// Get action ID from path
+	actionID, err := strconv.ParseUint(c.Param("actionId"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid action ID"})
+		return
+	}
+
+	// Bind request body
+	var resource models.SharedResource
+	if err := c.ShouldBindJSON(&resource); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Set action ID and creator ID
+	id := uint(actionID)
+	resource.GroupID = 0
+	resource.EventID = nil
+	resource.ActionID = &id
+	resource.CreatedByID = userID.(uint)
+
+	// Create resource
+	createdResource, err := h.groupService.CreateResource(&resource)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, createdResource)
+}
+
+// GetResourceByID handles GET /groups/resources/:resourceId
+func (h *GroupHandler) GetResourceByID(c *gin.Context) {
+	// Get resource ID from path
+	id, err := strconv.ParseUint(c.Param("resourceId"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid resource ID"})
+		return
+	}
+
+	// Get resource
+	resource, err := h.groupService.GetResourceByID(uint(id))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Resource not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, resource)
+}
+
+// UpdateResource handles PUT /groups/resources/:resourceId
+func (h *GroupHandler) UpdateResource(c *gin.Context) {
+	// Get user ID from context
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	// Get resource ID from path
+	id, err := strconv.ParseUint(c.Param("resourceId"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid resource ID"})
+		return
+	}
+
+	// Bind request body
+	var resource models.SharedResource
+	if err := c.ShouldBindJSON(&resource); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Update resource
+	updatedResource, err := h.groupService.UpdateResource(uint(id), &resource, userID.(uint))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, updatedResource)
+}
+
+// DeleteResource handles DELETE /groups/resources/:resourceId
+func (h *GroupHandler) DeleteResource(c *gin.Context) {
+	// Get user ID from context
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	// Get resource ID from path
+	id, err := strconv.ParseUint(c.Param("resourceId"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid resource ID"})
+		return
+	}
+
+	// Delete resource
+	err = h.groupService.DeleteResource(uint(id), userID.(uint))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Resource deleted successfully"})
+}
+
+// GetGroupDiscussions handles GET /groups/:id/discussions
+func (h *GroupHandler) GetGroupDiscussions(c *gin.Context) {
+	// Get group ID from path
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid group ID"})
+		return
+	}
+
+	// Get query parameters
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	pageSize, _ := strconv.Atoi(c.DefaultQuery("pageSize", "10"))
+
+	// Get group discussions
+	discussions, total, err := h.groupService.GetDiscussionsByGroupID(uint(id), page, pageSize)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": discussions,
+		"meta": gin.H{
+			"page":       page,
+			"pageSize":   pageSize,
+			"total":      total,
+			"totalPages": (total + int64(pageSize) - 1) / int64(pageSize),
+		},
+	})
+}
+
+// CreateDiscussion handles POST /groups/:id/discussions
+func (h *GroupHandler) CreateDiscussion(c *gin.Context) {
+	// Get user ID from context
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	// Get group ID from path
+	groupID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid group ID"})
+		return
+	}
+
+	// Bind request body
+	var discussion models.GroupDiscussion
+	if err := c.ShouldBindJSON(&discussion); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Set group ID and creator ID
+	discussion.GroupID = uint(groupID)
+	discussion.CreatedByID = userID.(uint)
+
+	// Create discussion
+	createdDiscussion, err := h.groupService.CreateDiscussion(&discussion)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, createdDiscussion)
+}
+
+// GetDiscussionByID handles GET /groups/discussions/:discussionId
+func (h *GroupHandler) GetDiscussionByID(c *gin.Context) {
+	// Get discussion ID from path
+	id, err := strconv.ParseUint(c.Param("discussionId"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid discussion ID"})
+		return
+	}
+
+	// Get discussion
+	discussion, err := h.groupService.GetDiscussionByID(uint(id))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Discussion not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, discussion)
+}
+
+// UpdateDiscussion handles PUT /groups/discussions/:discussionId
+func (h *GroupHandler) UpdateDiscussion(c *gin.Context) {
+	// Get user ID from context
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	// Get discussion ID from path
+	id, err := strconv.ParseUint(c.Param("discussionId"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid discussion ID"})
+		return
+	}
+
+	// Bind request body
+	var discussion models.GroupDiscussion
+	if err := c.ShouldBindJSON(&discussion); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Update discussion
+	updatedDiscussion, err := h.groupService.UpdateDiscussion(uint(id), &discussion, userID.(uint))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, updatedDiscussion)
+}
+
+// DeleteDiscussion handles DELETE /groups/discussions/:discussionId
+func (h *GroupHandler) DeleteDiscussion(c *gin.Context) {
+	// Get user ID from context
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	// Get discussion ID from path
+	id, err := strconv.ParseUint(c.Param("discussionId"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid discussion ID"})
+		return
+	}
+
+	// Delete discussion
+	err = h.groupService.DeleteDiscussion(uint(id), userID.(uint))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Discussion deleted successfully"})
+}
+
+// GetDiscussionComments handles GET /groups/discussions/:discussionId/comments
+func (h *GroupHandler) GetDiscussionComments(c *gin.Context) {
+	// Get discussion ID from path
+	id, err := strconv.ParseUint(c.Param("discussionId"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid discussion ID"})
+		return
+	}
+
+	// Get query parameters
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	pageSize, _ := strconv.Atoi(c.DefaultQuery("pageSize", "10"))
+
+	// Get discussion comments
+	comments, total, err := h.groupService.GetCommentsByDiscussionID(uint(id), page, pageSize)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": comments,
+		"meta": gin.H{
+			"page":       page,
+			"pageSize":   pageSize,
+			"total":      total,
+			"totalPages": (total + int64(pageSize) - 1) / int64(pageSize),
+		},
+	})
+}
+
+// CreateComment handles POST /groups/discussions/:discussionId/comments
+func (h *GroupHandler) CreateComment(c *gin.Context) {
+	// Get user ID from context
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	// Get discussion ID from path
+	discussionID, err := strconv.ParseUint(c.Param("discussionId"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid discussion ID"})
+		return
+	}
+
+	// Bind request body
+	var comment models.GroupComment
+	if err := c.ShouldBindJSON(&comment); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Set discussion ID and creator ID
+	comment.DiscussionID = uint(discussionID)
+	comment.CreatedByID = userID.(uint)
+
+	// Create comment
+	createdComment, err := h.groupService.CreateComment(&comment)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, createdComment)
+}
+
+// GetCommentByID handles GET /groups/comments/:commentId
+func (h *GroupHandler) GetCommentByID(c *gin.Context) {
+	// Get comment ID from path
+	id, err := strconv.ParseUint(c.Param("commentId"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid comment ID"})
+		return
+	}
+
+	// Get comment
+	comment, err := h.groupService.GetCommentByID(uint(id))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Comment not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, comment)
+}
+
+// UpdateComment handles PUT /groups/comments/:commentId
+func (h *GroupHandler) UpdateComment(c *gin.Context) {
+	// Get user ID from context
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	// Get comment ID from path
+	id, err := strconv.ParseUint(c.Param("commentId"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid comment ID"})
+		return
+	}
+
+	// Bind request body
+	var request struct {
+		Content string `json:"content" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Update comment
+	comment, err := h.groupService.UpdateComment(uint(id), request.Content, userID.(uint))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, comment)
+}
+
+// DeleteComment handles DELETE /groups/comments/:commentId
+func (h *GroupHandler) DeleteComment(c *gin.Context) {
+	// Get user ID from context
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	// Get comment ID from path
+	id, err := strconv.ParseUint(c.Param("commentId"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid comment ID"})
+		return
+	}
+
+	// Delete comment
+	err = h.groupService.DeleteComment(uint(id), userID.(uint))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Comment deleted successfully"})
+}
+
+// GetGroupActions handles GET /groups/:id/actions
+func (h *GroupHandler) GetGroupActions(c *gin.Context) {
+	// Get group ID from path
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid group ID"})
+		return
+	}
+
+	// Get query parameters
+	status := c.Query("status")
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	pageSize, _ := strconv.Atoi(c.DefaultQuery("pageSize", "10"))
+
+	// Get group actions
+	actions, total, err := h.groupService.GetActionsByGroupID(uint(id), status, page, pageSize)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": actions,
+		"meta": gin.H{
+			"page":       page,
+			"pageSize":   pageSize,
+			"total":      total,
+			"totalPages": (total + int64(pageSize) - 1) / int64(pageSize),
+		},
+	})
+}
+
+// CreateAction handles POST /groups/:id/actions
+func (h *GroupHandler) CreateAction(c *gin.Context) {
+	// Get user ID from context
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	// Get group ID from path
+	groupID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid group ID"})
+		return
+	}
+
+	// Bind request body
+	var action models.GroupAction
+	if err := c.ShouldBindJSON(&action); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Set group ID and creator ID
+	action.GroupID = uint(groupID)
+	action.CreatedByID = userID.(uint)
+
+	// Create action
+	createdAction, err := h.groupService.CreateAction(&action)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, createdAction)
+}
+
+// GetActionByID handles GET /groups/actions/:actionId
+func (h *GroupHandler) GetActionByID(c *gin.Context) {
+	// Get action ID from path
+	id, err := strconv.ParseUint(c.Param("actionId"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid action ID"})
+		return
+	}
+
+	// Get action
+	action, err := h.groupService.GetActionByID(uint(id))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Action not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, action)
+}
+
+// UpdateAction handles PUT /groups/actions/:actionId
+func (h *GroupHandler) UpdateAction(c *gin.Context) {
+	// Get user ID from context
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	// Get action ID from path
+	id, err := strconv.ParseUint(c.Param("actionId"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid action ID"})
+		return
+	}
+
+	// Bind request body
+	var action models.GroupAction
+	if err := c.ShouldBindJSON(&action); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Update action
+	updatedAction, err := h.groupService.UpdateAction(uint(id), &action, userID.(uint))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, updatedAction)
+}
+
+// DeleteAction handles DELETE /groups/actions/:actionId
+func (h *GroupHandler) DeleteAction(c *gin.Context) {
+	// Get user ID from context
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	// Get action ID from path
+	id, err := strconv.ParseUint(c.Param("actionId"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid action ID"})
+		return
+	}
+
+	// Delete action
+	err = h.groupService.DeleteAction(uint(id), userID.(uint))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Action deleted successfully"})
+}
+
+// GetUserActions handles GET /groups/user/:userId/actions
+func (h *GroupHandler) GetUserActions(c *gin.Context) {
+	// Get user ID from path
+	id, err := strconv.ParseUint(c.Param("userId"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	// Get query parameters
+	status := c.Query("status")
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	pageSize, _ := strconv.Atoi(c.DefaultQuery("pageSize", "10"))
+
+	// Get actions assigned to the user
+	actions, total, err := h.groupService.GetActionsByAssigneeID(uint(id), status, page, pageSize)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": actions,
+		"meta": gin.H{
+			"page":       page,
+			"pageSize":   pageSize,
+			"total":      total,
+			"totalPages": (total + int64(pageSize) - 1) / int64(pageSize),
+		},
+	})
+}
+
+// GetGroupInvitations handles GET /groups/:id/invitations
+func (h *GroupHandler) GetGroupInvitations(c *gin.Context) {
+	// Get group ID from path
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid group ID"})
+		return
+	}
+
+	// Get query parameters
+	status := c.Query("status")
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	pageSize, _ := strconv.Atoi(c.DefaultQuery("pageSize", "10"))
+
+	// Get group invitations
+	invitations, total, err := h.groupService.GetInvitationsByGroupID(uint(id), status, page, pageSize)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": invitations,
+		"meta": gin.H{
+			"page":       page,
+			"pageSize":   pageSize,
+			"total":      total,
+			"totalPages": (total + int64(pageSize) - 1) / int64(pageSize),
+		},
+	})
+}
+
+// CreateInvitation handles POST /groups/:id/invitations
+func (h *GroupHandler) CreateInvitation(c *gin.Context) {
+	// Get user ID from context
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	// Get group ID from path
+	groupID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid group ID"})
+		return
+	}
+
+	// Bind request body
+	var request struct {
+		Email   string `json:"email"`
+		UserID  *uint  `json:"userId"`
+		Message string `json:"message"`
+	}
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if request.Email == "" && request.UserID == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Email or user ID is required"})
+		return
+	}
+
+	// Create invitation
+	invitation, err := h.groupService.CreateInvitation(uint(groupID), request.Email, request.UserID, userID.(uint), request.Message)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, invitation)
+}
+
+// GetInvitationByID handles GET /groups/invitations/:invitationId
+func (h *GroupHandler) GetInvitationByID(c *gin.Context) {
+	// Get invitation ID from path
+	id, err := strconv.ParseUint(c.Param("invitationId"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid invitation ID"})
+		return
+	}
+
+	// Get invitation
+	invitation, err := h.groupService.GetInvitationByID(uint(id))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Invitation not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, invitation)
+}
+
+// GetInvitationByCode handles GET /groups/invitations/code/:code
+func (h *GroupHandler) GetInvitationByCode(c *gin.Context) {
+	// Get invitation
+	invitation, err := h.groupService.GetInvitationByCode(c.Param("code"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Invitation not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, invitation)
+}
+
+// AcceptInvitation handles POST /groups/invitations/code/:code/accept
+func (h *GroupHandler) AcceptInvitation(c *gin.Context) {
+	// Get user ID from context
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	// Accept invitation
+	err := h.groupService.AcceptInvitation(c.Param("code"), userID.(uint))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Invitation accepted successfully"})
+}
+
+// DeclineInvitation handles POST /groups/invitations/code/:code/decline
+func (h *GroupHandler) DeclineInvitation(c *gin.Context) {
+	// Get user ID from context
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	// Decline invitation
+	err := h.groupService.DeclineInvitation(c.Param("code"), userID.(uint))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Invitation declined successfully"})
+}
+
+// CancelInvitation handles DELETE /groups/invitations/:invitationId
+func (h *GroupHandler) CancelInvitation(c *gin.Context) {
+	// Get user ID from context
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	// Get invitation ID from path
+	id, err := strconv.ParseUint(c.Param("invitationId"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid invitation ID"})
+		return
+	}
+
+	// Cancel invitation
+	err = h.groupService.CancelInvitation(uint(id), userID.(uint))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Invitation cancelled successfully"})
+}
+
+// GetUserInvitations handles GET /groups/user/:userId/invitations
+func (h *GroupHandler) GetUserInvitations(c *gin.Context) {
+	// Get user ID from path
+	id, err := strconv.ParseUint(c.Param("userId"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	// Get query parameters
+	status := c.Query("status")
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	pageSize, _ := strconv.Atoi(c.DefaultQuery("pageSize", "10"))
+
+	// Get user invitations
+	invitations, total, err := h.groupService.GetInvitationsByUserID(uint(id), status, page, pageSize)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": invitations,
+		"meta": gin.H{
+			"page":       page,
+			"pageSize":   pageSize,
+			"total":      total,
+			"totalPages": (total + int64(pageSize) - 1) / int64(pageSize),
+		},
+	})
+}
+
+// GetGroupJoinRequests handles GET /groups/:id/join-requests
+func (h *GroupHandler) GetGroupJoinRequests(c *gin.Context) {
+	// Get group ID from path
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid group ID"})
+		return
+	}
+
+	// Get query parameters
+	status := c.Query("status")
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	pageSize, _ := strconv.Atoi(c.DefaultQuery("pageSize", "10"))
+
+	// Get group join requests
+	requests, total, err := h.groupService.GetJoinRequestsByGroupID(uint(id), status, page, pageSize)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": requests,
+		"meta": gin.H{
+			"page":       page,
+			"pageSize":   pageSize,
+			"total":      total,
+			"totalPages": (total + int64(pageSize) - 1) / int64(pageSize),
+		},
+	})
+}
+
+// CreateJoinRequest handles POST /groups/:id/join-requests
+func (h *GroupHandler) CreateJoinRequest(c *gin.Context) {
+	// Get user ID from context
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	// Get group ID from path
+	groupID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid group ID"})
+		return
+	}
+
+	// Bind request body
+	var request struct {
+		Message string `json:"message"`
+		Code    string `json:"code"`
+	}
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Create join request
+	joinRequest, err := h.groupService.CreateJoinRequest(uint(groupID), userID.(uint), request.Message, request.Code)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, joinRequest)
+}
+
+// GetJoinRequestByID handles GET /groups/join-requests/:requestId
+func (h *GroupHandler) GetJoinRequestByID(c *gin.Context) {
+	// Get join request ID from path
+	id, err := strconv.ParseUint(c.Param("requestId"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid join request ID"})
+		return
+	}
+
+	// Get join request
+	request, err := h.groupService.GetJoinRequestByID(uint(id))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Join request not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, request)
+}
+
+// ApproveJoinRequest handles POST /groups/join-requests/:requestId/approve
+func (h *GroupHandler) ApproveJoinRequest(c *gin.Context) {
+	// Get user ID from context
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	// Get join request ID from path
+	id, err := strconv.ParseUint(c.Param("requestId"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid join request ID"})
+		return
+	}
+
+	// Approve join request
+	err = h.groupService.ApproveJoinRequest(uint(id), userID.(uint))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Join request approved successfully"})
+}
+
+// RejectJoinRequest handles POST /groups/join-requests/:requestId/reject
+func (h *GroupHandler) RejectJoinRequest(c *gin.Context) {
+	// Get user ID from context
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	// Get join request ID from path
+	id, err := strconv.ParseUint(c.Param("requestId"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid join request ID"})
+		return
+	}
+
+	// Reject join request
+	err = h.groupService.RejectJoinRequest(uint(id), userID.(uint))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Join request rejected successfully"})
+}
+
+// CancelJoinRequest handles DELETE /groups/join-requests/:requestId
+func (h *GroupHandler) CancelJoinRequest(c *gin.Context) {
+	// Get user ID from context
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	// Get join request ID from path
+	id, err := strconv.ParseUint(c.Param("requestId"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid join request ID"})
+		return
+	}
+
+	// Cancel join request
+	err = h.groupService.CancelJoinRequest(uint(id), userID.(uint))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Join request cancelled successfully"})
+}
+
+// GetUserJoinRequests handles GET /groups/user/:userId/join-requests
+func (h *GroupHandler) GetUserJoinRequests(c *gin.Context) {
+	// Get user ID from path
+	id, err := strconv.ParseUint(c.Param("userId"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	// Get query parameters
+	status := c.Query("status")
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	pageSize, _ := strconv.Atoi(c.DefaultQuery("pageSize", "10"))
+
+	// Get user join requests
+	requests, total, err := h.groupService.GetJoinRequestsByUserID(uint(id), status, page, pageSize)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": requests,
+		"meta": gin.H{
+			"page":       page,
+			"pageSize":   pageSize,
+			"total":      total,
+			"totalPages": (total + int64(pageSize) - 1) / int64(pageSize),
+		},
+	})
+}
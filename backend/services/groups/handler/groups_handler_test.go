@@ -0,0 +1,407 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/yerenwgventures/GreatNigeriaLibrary-Foundation/backend/pkg/common/database/dbtest"
+	"github.com/yerenwgventures/GreatNigeriaLibrary-Foundation/backend/pkg/common/mailer"
+	"github.com/yerenwgventures/GreatNigeriaLibrary-Foundation/backend/services/groups/geo"
+	"github.com/yerenwgventures/GreatNigeriaLibrary-Foundation/backend/services/groups/models"
+	"github.com/yerenwgventures/GreatNigeriaLibrary-Foundation/backend/services/groups/repository"
+	"github.com/yerenwgventures/GreatNigeriaLibrary-Foundation/backend/services/groups/service"
+)
+
+// Users acting in the tests
+const (
+	owner     uint = 1
+	member    uint = 2
+	outsider  uint = 3
+	newcomer  uint = 4
+	userIDKey      = "X-Test-User"
+)
+
+// groupsAPI drives the groups routes end to end over the database
+// repository. It needs a test database, see dbtest.
+type groupsAPI struct {
+	t      *testing.T
+	router *gin.Engine
+	outbox *mailer.OutboxMailer
+}
+
+func newGroupsAPI(t *testing.T) *groupsAPI {
+	gin.SetMode(gin.TestMode)
+	db := dbtest.Open(t)
+	for userID, name := range map[uint]string{owner: "Ada", member: "Tunde", outsider: "Chidi", newcomer: "Bisi"} {
+		require.NoError(t, db.Exec(
+			"INSERT INTO users (id, username, email, password, full_name) VALUES (?, ?, ?, '', ?)",
+			userID, strings.ToLower(name), fmt.Sprintf("user%d@example.com", userID), name,
+		).Error)
+	}
+	repo := repository.NewGroupRepository(db)
+	templates, err := mailer.NewTemplates("Great Nigeria Library")
+	require.NoError(t, err)
+	outbox := mailer.NewOutboxMailer("")
+	groupService := service.NewGroupServiceWithMailer(repo, outbox, templates, "https://library.example.com")
+	handler := NewGroupHandler(groupService)
+
+	router := gin.New()
+	handler.RegisterPublicRoutes(router.Group("/"))
+	// Stands in for AuthRequired, which sets the authenticated user ID
+	authenticated := router.Group("/", func(c *gin.Context) {
+		userID, err := strconv.ParseUint(c.GetHeader(userIDKey), 10, 64)
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+			return
+		}
+		c.Set("user_id", uint(userID))
+	})
+	handler.RegisterRoutes(authenticated)
+
+	return &groupsAPI{t: t, router: router, outbox: outbox}
+}
+
+// do sends a request as userID and decodes the JSON response into out
+func (api *groupsAPI) do(userID uint, method, path string, body interface{}, out interface{}) int {
+	var reader *bytes.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		require.NoError(api.t, err)
+		reader = bytes.NewReader(data)
+	} else {
+		reader = bytes.NewReader(nil)
+	}
+
+	req := httptest.NewRequest(method, path, reader)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(userIDKey, strconv.FormatUint(uint64(userID), 10))
+	w := httptest.NewRecorder()
+	api.router.ServeHTTP(w, req)
+
+	if out != nil && w.Code < http.StatusBadRequest {
+		require.NoError(api.t, json.Unmarshal(w.Body.Bytes(), out), w.Body.String())
+	}
+	return w.Code
+}
+
+// createGroup creates a group owned by owner
+func (api *groupsAPI) createGroup(group gin.H) models.Group {
+	var created models.Group
+	require.Equal(api.t, http.StatusCreated, api.do(owner, http.MethodPost, "/groups", group, &created))
+	return created
+}
+
+// memberStatus returns the membership status of a user, or "" if there is none
+func (api *groupsAPI) memberStatus(groupID, userID uint) models.MemberStatus {
+	var m models.GroupMember
+	path := fmt.Sprintf("/groups/%d/members/%d", groupID, userID)
+	if api.do(owner, http.MethodGet, path, nil, &m) != http.StatusOK {
+		return ""
+	}
+	return m.Status
+}
+
+type page[T any] struct {
+	Data []T `json:"data"`
+	Meta struct {
+		Total int64 `json:"total"`
+	} `json:"meta"`
+}
+
+func TestUnauthenticatedRequestsAreRejected(t *testing.T) {
+	api := newGroupsAPI(t)
+
+	req := httptest.NewRequest(http.MethodGet, "/groups", nil)
+	w := httptest.NewRecorder()
+	api.router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+}
+
+func TestGroupMembership(t *testing.T) {
+	api := newGroupsAPI(t)
+	group := api.createGroup(gin.H{"name": "Lagos Readers", "type": models.StudyGroup})
+	assert.Equal(t, models.PublicGroup, group.Visibility)
+
+	// The creator becomes the owner
+	var creator models.GroupMember
+	require.Equal(t, http.StatusOK, api.do(owner, http.MethodGet, fmt.Sprintf("/groups/%d/members/%d", group.ID, owner), nil, &creator))
+	assert.Equal(t, models.OwnerRole, creator.Role)
+	assert.Equal(t, models.ActiveMember, creator.Status)
+	assert.NotNil(t, creator.JoinedAt)
+
+	membersPath := fmt.Sprintf("/groups/%d/members", group.ID)
+	assert.Equal(t, http.StatusCreated, api.do(owner, http.MethodPost, membersPath, gin.H{
+		"userId": member, "role": models.RegularMemberRole, "status": models.ActiveMember,
+	}, nil))
+	assert.Equal(t, http.StatusInternalServerError, api.do(owner, http.MethodPost, membersPath, gin.H{
+		"userId": member, "role": models.RegularMemberRole, "status": models.ActiveMember,
+	}, nil), "a user has one membership per group")
+
+	var members page[models.GroupMember]
+	require.Equal(t, http.StatusOK, api.do(member, http.MethodGet, membersPath+"?status=active", nil, &members))
+	assert.Equal(t, int64(2), members.Meta.Total)
+
+	var loaded models.Group
+	require.Equal(t, http.StatusOK, api.do(member, http.MethodGet, fmt.Sprintf("/groups/%d", group.ID), nil, &loaded))
+	assert.Len(t, loaded.Members, 2)
+
+	var groups []models.Group
+	require.Equal(t, http.StatusOK, api.do(member, http.MethodGet, fmt.Sprintf("/groups/user/%d", member), nil, &groups))
+	require.Len(t, groups, 1)
+	assert.Equal(t, group.ID, groups[0].ID)
+
+	// Only owners and admins change roles
+	memberPath := fmt.Sprintf("%s/%d", membersPath, member)
+	promote := gin.H{"role": models.ModeratorRole, "status": models.ActiveMember}
+	assert.Equal(t, http.StatusInternalServerError, api.do(member, http.MethodPut, memberPath, promote, nil))
+	var promoted models.GroupMember
+	require.Equal(t, http.StatusOK, api.do(owner, http.MethodPut, memberPath, promote, &promoted))
+	assert.Equal(t, models.ModeratorRole, promoted.Role)
+
+	assert.Equal(t, http.StatusOK, api.do(owner, http.MethodDelete, memberPath, nil, nil))
+	assert.Equal(t, models.MemberStatus(""), api.memberStatus(group.ID, member))
+}
+
+func TestGroupEvents(t *testing.T) {
+	api := newGroupsAPI(t)
+	group := api.createGroup(gin.H{"name": "Abuja Cleanup", "type": models.LocalActionGroup})
+	api.do(owner, http.MethodPost, fmt.Sprintf("/groups/%d/members", group.ID), gin.H{
+		"userId": member, "role": models.RegularMemberRole, "status": models.ActiveMember,
+	}, nil)
+
+	start := time.Now().Add(48 * time.Hour)
+	event := gin.H{"title": "Market cleanup", "startTime": start, "endTime": start.Add(3 * time.Hour)}
+	eventsPath := fmt.Sprintf("/groups/%d/events", group.ID)
+	assert.Equal(t, http.StatusInternalServerError, api.do(outsider, http.MethodPost, eventsPath, event, nil),
+		"only members create events")
+
+	var created models.LocalEvent
+	require.Equal(t, http.StatusCreated, api.do(owner, http.MethodPost, eventsPath, event, &created))
+	assert.Equal(t, models.PlannedEvent, created.Status)
+
+	attendeesPath := fmt.Sprintf("/groups/events/%d/attendees", created.ID)
+	assert.Equal(t, http.StatusCreated, api.do(member, http.MethodPost, attendeesPath, gin.H{"status": "going"}, nil))
+	assert.Equal(t, http.StatusInternalServerError, api.do(outsider, http.MethodPost, attendeesPath, gin.H{"status": "going"}, nil))
+
+	var loaded models.LocalEvent
+	require.Equal(t, http.StatusOK, api.do(member, http.MethodGet, fmt.Sprintf("/groups/events/%d", created.ID), nil, &loaded))
+	assert.Len(t, loaded.Attendees, 2, "the creator attends too")
+
+	// Members check themselves in; checking in others takes an owner or admin
+	checkinPath := fmt.Sprintf("%s/%d/checkin", attendeesPath, owner)
+	assert.Equal(t, http.StatusInternalServerError, api.do(member, http.MethodPost, checkinPath, nil, nil))
+	var checkedIn models.EventAttendee
+	require.Equal(t, http.StatusOK, api.do(owner, http.MethodPost, fmt.Sprintf("%s/%d/checkin", attendeesPath, member), nil, &checkedIn))
+	assert.True(t, checkedIn.CheckedIn)
+
+	var upcoming page[models.LocalEvent]
+	require.Equal(t, http.StatusOK, api.do(member, http.MethodGet, fmt.Sprintf("/groups/user/%d/events/upcoming", member), nil, &upcoming))
+	require.Len(t, upcoming.Data, 1)
+	assert.Equal(t, created.ID, upcoming.Data[0].ID)
+
+	require.Equal(t, http.StatusOK, api.do(outsider, http.MethodGet, fmt.Sprintf("/groups/user/%d/events/upcoming", outsider), nil, &upcoming))
+	assert.Empty(t, upcoming.Data)
+}
+
+func TestGroupInvitations(t *testing.T) {
+	api := newGroupsAPI(t)
+	group := api.createGroup(gin.H{"name": "Book Club", "type": models.InterestGroup, "visibility": models.SecretGroup})
+	invitationsPath := fmt.Sprintf("/groups/%d/invitations", group.ID)
+
+	assert.Equal(t, http.StatusBadRequest, api.do(owner, http.MethodPost, invitationsPath, gin.H{}, nil))
+	assert.Equal(t, http.StatusInternalServerError, api.do(outsider, http.MethodPost, invitationsPath, gin.H{"userId": newcomer}, nil),
+		"only owners, admins and moderators invite")
+
+	var invitation models.GroupInvitation
+	require.Equal(t, http.StatusCreated, api.do(owner, http.MethodPost, invitationsPath, gin.H{"userId": member}, &invitation))
+	assert.Equal(t, "pending", invitation.Status)
+	assert.Len(t, invitation.Code, 32)
+	assert.True(t, invitation.ExpiresAt.After(time.Now()))
+
+	// The invitation is only for the invited user
+	acceptPath := fmt.Sprintf("/groups/invitations/code/%s/accept", invitation.Code)
+	assert.Equal(t, http.StatusInternalServerError, api.do(outsider, http.MethodPost, acceptPath, nil, nil))
+	assert.Equal(t, http.StatusOK, api.do(member, http.MethodPost, acceptPath, nil, nil))
+	assert.Equal(t, models.ActiveMember, api.memberStatus(group.ID, member))
+	assert.Equal(t, http.StatusInternalServerError, api.do(member, http.MethodPost, acceptPath, nil, nil),
+		"an invitation is accepted once")
+
+	var declined models.GroupInvitation
+	require.Equal(t, http.StatusCreated, api.do(owner, http.MethodPost, invitationsPath, gin.H{"userId": outsider}, &declined))
+	assert.Equal(t, http.StatusOK, api.do(outsider, http.MethodPost, fmt.Sprintf("/groups/invitations/code/%s/decline", declined.Code), nil, nil))
+	require.Equal(t, http.StatusOK, api.do(outsider, http.MethodGet, fmt.Sprintf("/groups/invitations/%d", declined.ID), nil, &declined))
+	assert.Equal(t, "declined", declined.Status)
+	assert.Equal(t, models.MemberStatus(""), api.memberStatus(group.ID, outsider))
+
+	var cancelled models.GroupInvitation
+	require.Equal(t, http.StatusCreated, api.do(owner, http.MethodPost, invitationsPath, gin.H{"email": "new@example.com"}, &cancelled))
+	invitationPath := fmt.Sprintf("/groups/invitations/%d", cancelled.ID)
+	assert.Equal(t, http.StatusInternalServerError, api.do(newcomer, http.MethodDelete, invitationPath, nil, nil))
+	assert.Equal(t, http.StatusOK, api.do(owner, http.MethodDelete, invitationPath, nil, nil))
+	assert.Equal(t, http.StatusInternalServerError,
+		api.do(newcomer, http.MethodPost, fmt.Sprintf("/groups/invitations/code/%s/accept", cancelled.Code), nil, nil))
+
+	var invitations page[models.GroupInvitation]
+	require.Equal(t, http.StatusOK, api.do(owner, http.MethodGet, invitationsPath, nil, &invitations))
+	require.Len(t, invitations.Data, 3)
+	assert.Equal(t, cancelled.ID, invitations.Data[0].ID, "newest first")
+}
+
+func TestGroupJoinRequests(t *testing.T) {
+	api := newGroupsAPI(t)
+
+	t.Run("open group approves at once", func(t *testing.T) {
+		group := api.createGroup(gin.H{"name": "Open", "type": models.InterestGroup, "joinApprovalRequired": false})
+
+		var request models.GroupJoinRequest
+		require.Equal(t, http.StatusCreated, api.do(member, http.MethodPost, fmt.Sprintf("/groups/%d/join-requests", group.ID), gin.H{}, &request))
+		assert.Equal(t, "approved", request.Status)
+		assert.Equal(t, models.ActiveMember, api.memberStatus(group.ID, member))
+	})
+
+	t.Run("approval required", func(t *testing.T) {
+		group := api.createGroup(gin.H{"name": "Reviewed", "type": models.ProjectGroup, "joinApprovalRequired": true})
+		requestsPath := fmt.Sprintf("/groups/%d/join-requests", group.ID)
+
+		var approved, rejected models.GroupJoinRequest
+		require.Equal(t, http.StatusCreated, api.do(member, http.MethodPost, requestsPath, gin.H{"message": "hi"}, &approved))
+		assert.Equal(t, "pending", approved.Status)
+		assert.Equal(t, models.MemberStatus(""), api.memberStatus(group.ID, member))
+		assert.Equal(t, http.StatusInternalServerError, api.do(member, http.MethodPost, requestsPath, gin.H{}, nil),
+			"one pending request per group")
+		require.Equal(t, http.StatusCreated, api.do(outsider, http.MethodPost, requestsPath, gin.H{}, &rejected))
+
+		// Only owners and admins decide
+		approvePath := fmt.Sprintf("/groups/join-requests/%d/approve", approved.ID)
+		assert.Equal(t, http.StatusInternalServerError, api.do(outsider, http.MethodPost, approvePath, nil, nil))
+		assert.Equal(t, http.StatusOK, api.do(owner, http.MethodPost, approvePath, nil, nil))
+		assert.Equal(t, models.ActiveMember, api.memberStatus(group.ID, member))
+
+		assert.Equal(t, http.StatusOK, api.do(owner, http.MethodPost, fmt.Sprintf("/groups/join-requests/%d/reject", rejected.ID), nil, nil))
+		assert.Equal(t, models.MemberStatus(""), api.memberStatus(group.ID, outsider))
+
+		var requests page[models.GroupJoinRequest]
+		require.Equal(t, http.StatusOK, api.do(owner, http.MethodGet, requestsPath+"?status=rejected", nil, &requests))
+		require.Len(t, requests.Data, 1)
+		assert.Equal(t, outsider, requests.Data[0].UserID)
+	})
+
+	t.Run("membership code", func(t *testing.T) {
+		group := api.createGroup(gin.H{
+			"name": "Coded", "type": models.StudyGroup, "joinApprovalRequired": true, "membershipCode": "NAIJA",
+		})
+		requestsPath := fmt.Sprintf("/groups/%d/join-requests", group.ID)
+
+		assert.Equal(t, http.StatusInternalServerError, api.do(member, http.MethodPost, requestsPath, gin.H{"code": "WRONG"}, nil))
+		var request models.GroupJoinRequest
+		require.Equal(t, http.StatusCreated, api.do(member, http.MethodPost, requestsPath, gin.H{"code": "NAIJA"}, &request))
+		assert.Equal(t, "approved", request.Status)
+		assert.Equal(t, models.ActiveMember, api.memberStatus(group.ID, member))
+	})
+
+	t.Run("cancel", func(t *testing.T) {
+		group := api.createGroup(gin.H{"name": "Cancelled", "type": models.StudyGroup, "joinApprovalRequired": true})
+
+		var request models.GroupJoinRequest
+		require.Equal(t, http.StatusCreated, api.do(newcomer, http.MethodPost, fmt.Sprintf("/groups/%d/join-requests", group.ID), gin.H{}, &request))
+		requestPath := fmt.Sprintf("/groups/join-requests/%d", request.ID)
+		assert.Equal(t, http.StatusInternalServerError, api.do(owner, http.MethodDelete, requestPath, nil, nil),
+			"only the requester cancels")
+		assert.Equal(t, http.StatusOK, api.do(newcomer, http.MethodDelete, requestPath, nil, nil))
+		assert.Equal(t, http.StatusNotFound, api.do(newcomer, http.MethodGet, requestPath, nil, nil))
+	})
+
+	t.Run("secret group", func(t *testing.T) {
+		group := api.createGroup(gin.H{"name": "Hidden", "type": models.StudyGroup, "visibility": models.SecretGroup})
+		assert.Equal(t, http.StatusInternalServerError,
+			api.do(member, http.MethodPost, fmt.Sprintf("/groups/%d/join-requests", group.ID), gin.H{}, nil))
+	})
+}
+
+func TestGroupsAndEventsOnTheMap(t *testing.T) {
+	api := newGroupsAPI(t)
+	place := func(name string, latitude, longitude float64, extra gin.H) models.Group {
+		group := gin.H{"name": name, "type": models.LocalActionGroup, "latitude": latitude, "longitude": longitude}
+		for key, value := range extra {
+			group[key] = value
+		}
+		return api.createGroup(group)
+	}
+	lagos := place("Lagos", 6.5244, 3.3792, nil)
+	ibadan := place("Ibadan", 7.3775, 3.9470, nil)
+	abuja := place("Abuja", 9.0765, 7.3986, nil)
+	place("Online", 6.5, 3.4, gin.H{"isVirtual": true})
+	place("Hidden", 6.5, 3.4, gin.H{"visibility": models.SecretGroup})
+
+	var groups []models.Group
+	require.Equal(t, http.StatusOK, api.do(owner, http.MethodGet, "/groups/nearby?latitude=6.45&longitude=3.39&radius=150", nil, &groups))
+	require.Len(t, groups, 2, "virtual, secret and distant groups are left out")
+	assert.Equal(t, []uint{lagos.ID, ibadan.ID}, []uint{groups[0].ID, groups[1].ID})
+	require.NotNil(t, groups[0].DistanceKm)
+	assert.InDelta(t, 8.3, *groups[0].DistanceKm, 0.5)
+
+	require.Equal(t, http.StatusOK, api.do(owner, http.MethodGet, "/groups/nearest?latitude=9&longitude=7&limit=2", nil, &groups))
+	require.Len(t, groups, 2)
+	assert.Equal(t, []uint{abuja.ID, ibadan.ID}, []uint{groups[0].ID, groups[1].ID})
+
+	require.Equal(t, http.StatusOK, api.do(owner, http.MethodGet, "/groups/bounds?bbox=2.7,4.2,14.7,13.9", nil, &groups))
+	assert.Len(t, groups, 3)
+	require.Equal(t, http.StatusOK, api.do(owner, http.MethodGet, "/groups/bounds?bbox=3,6,4,7", nil, &groups))
+	require.Len(t, groups, 1)
+	assert.Equal(t, lagos.ID, groups[0].ID)
+
+	var clusters []geo.Cluster
+	require.Equal(t, http.StatusOK, api.do(owner, http.MethodGet, "/groups/clusters?bbox=2.7,4.2,14.7,13.9&zoom=4", nil, &clusters))
+	require.Len(t, clusters, 2)
+	assert.Equal(t, 2, clusters[0].Count, "Lagos and Ibadan")
+	assert.Equal(t, abuja.ID, clusters[1].ID)
+
+	for _, path := range []string{
+		"/groups/nearby?latitude=6.45",
+		"/groups/nearby?latitude=6.45&longitude=3.39&radius=5000",
+		"/groups/nearest?latitude=95&longitude=3.39",
+		"/groups/bounds?bbox=1,2,3",
+		"/groups/clusters?bbox=2.7,4.2,14.7,13.9",
+		"/groups/clusters?bbox=2.7,4.2,14.7,13.9&zoom=30",
+	} {
+		assert.Equal(t, http.StatusBadRequest, api.do(owner, http.MethodGet, path, nil, nil), path)
+	}
+
+	// Events are mapped until they end
+	createEvent := func(group models.Group, title string, start time.Time) {
+		event := gin.H{
+			"title": title, "startTime": start, "endTime": start.Add(2 * time.Hour),
+			"latitude": group.Latitude, "longitude": group.Longitude,
+		}
+		require.Equal(t, http.StatusCreated, api.do(owner, http.MethodPost, fmt.Sprintf("/groups/%d/events", group.ID), event, nil))
+	}
+	createEvent(lagos, "Beach cleanup", time.Now().Add(24*time.Hour))
+	createEvent(abuja, "Town hall", time.Now().Add(time.Hour))
+	createEvent(abuja, "Last week", time.Now().Add(-7*24*time.Hour))
+
+	var events []models.LocalEvent
+	require.Equal(t, http.StatusOK, api.do(owner, http.MethodGet, "/groups/events/nearby?latitude=9&longitude=7.4&radius=50", nil, &events))
+	require.Len(t, events, 1)
+	assert.Equal(t, "Town hall", events[0].Title)
+
+	require.Equal(t, http.StatusOK, api.do(owner, http.MethodGet, "/groups/events/nearest?latitude=6.5&longitude=3.4", nil, &events))
+	require.Len(t, events, 2)
+	assert.Equal(t, "Beach cleanup", events[0].Title)
+
+	require.Equal(t, http.StatusOK, api.do(owner, http.MethodGet, "/groups/events/bounds?bbox=2.7,4.2,14.7,13.9&limit=1", nil, &events))
+	assert.Len(t, events, 1)
+
+	require.Equal(t, http.StatusOK, api.do(owner, http.MethodGet, "/groups/events/clusters?bbox=2.7,4.2,14.7,13.9&zoom=0", nil, &clusters))
+	require.Len(t, clusters, 1)
+	assert.Equal(t, 2, clusters[0].Count)
+}
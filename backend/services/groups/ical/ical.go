@@ -0,0 +1,196 @@
+// Package ical renders group events as iCalendar (RFC 5545) data for
+// calendar downloads, subscription feeds and emailed invites (RFC 5546).
+package ical
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// ProductID identifies the application that produced a calendar
+const ProductID = "-//Great Nigeria Library//Groups//EN"
+
+// ContentType is the media type of iCalendar data
+const ContentType = "text/calendar; charset=utf-8"
+
+// Method is the iTIP method of a calendar. Downloads and feeds publish
+// events; emails request attendance or cancel an event.
+type Method string
+
+// Calendar methods
+const (
+	MethodPublish Method = "PUBLISH"
+	MethodRequest Method = "REQUEST"
+	MethodCancel  Method = "CANCEL"
+)
+
+// Event statuses
+const (
+	StatusTentative = "TENTATIVE"
+	StatusConfirmed = "CONFIRMED"
+	StatusCancelled = "CANCELLED"
+)
+
+// Attendee participation statuses
+const (
+	PartStatAccepted    = "ACCEPTED"
+	PartStatTentative   = "TENTATIVE"
+	PartStatDeclined    = "DECLINED"
+	PartStatNeedsAction = "NEEDS-ACTION"
+)
+
+// maxLineOctets is the longest content line before it must be folded
+const maxLineOctets = 75
+
+// Calendar is a set of events sent or published together
+type Calendar struct {
+	// Name is shown by calendar apps for subscribed feeds
+	Name   string
+	Method Method
+	Events []Event
+}
+
+// Person is the organizer or an attendee of an event
+type Person struct {
+	Name     string
+	Email    string
+	PartStat string
+}
+
+// Event is a single VEVENT. Sequence must grow whenever the time, place or
+// status of the event changes so calendar apps replace their copy.
+type Event struct {
+	UID          string
+	Sequence     int
+	Status       string
+	Summary      string
+	Description  string
+	Location     string
+	URL          string
+	Start        time.Time
+	End          time.Time
+	Latitude     float64
+	Longitude    float64
+	HasGeo       bool
+	Organizer    *Person
+	Attendees    []Person
+	Created      time.Time
+	LastModified time.Time
+}
+
+// Encode renders the calendar. now is used as the DTSTAMP of every event.
+func (c *Calendar) Encode(now time.Time) []byte {
+	w := &writer{}
+	w.line("BEGIN:VCALENDAR")
+	w.line("VERSION:2.0")
+	w.line("PRODID:" + ProductID)
+	w.line("CALSCALE:GREGORIAN")
+	method := c.Method
+	if method == "" {
+		method = MethodPublish
+	}
+	w.line("METHOD:" + string(method))
+	if c.Name != "" {
+		w.line("X-WR-CALNAME:" + Escape(c.Name))
+	}
+
+	for i := range c.Events {
+		c.Events[i].encode(w, now)
+	}
+
+	w.line("END:VCALENDAR")
+	return w.buf.Bytes()
+}
+
+func (e *Event) encode(w *writer, now time.Time) {
+	w.line("BEGIN:VEVENT")
+	w.line("UID:" + e.UID)
+	w.line("DTSTAMP:" + formatTime(now))
+	w.line("DTSTART:" + formatTime(e.Start))
+	if !e.End.IsZero() {
+		w.line("DTEND:" + formatTime(e.End))
+	}
+	w.line(fmt.Sprintf("SEQUENCE:%d", e.Sequence))
+	if e.Status != "" {
+		w.line("STATUS:" + e.Status)
+	}
+	w.line("SUMMARY:" + Escape(e.Summary))
+	if e.Description != "" {
+		w.line("DESCRIPTION:" + Escape(e.Description))
+	}
+	if e.Location != "" {
+		w.line("LOCATION:" + Escape(e.Location))
+	}
+	if e.HasGeo {
+		w.line(fmt.Sprintf("GEO:%.6f;%.6f", e.Latitude, e.Longitude))
+	}
+	if e.URL != "" {
+		w.line("URL:" + e.URL)
+	}
+	if e.Organizer != nil {
+		w.line("ORGANIZER" + e.Organizer.params() + ":mailto:" + e.Organizer.Email)
+	}
+	for _, attendee := range e.Attendees {
+		w.line("ATTENDEE" + attendee.params() + ":mailto:" + attendee.Email)
+	}
+	if !e.Created.IsZero() {
+		w.line("CREATED:" + formatTime(e.Created))
+	}
+	if !e.LastModified.IsZero() {
+		w.line("LAST-MODIFIED:" + formatTime(e.LastModified))
+	}
+	w.line("END:VEVENT")
+}
+
+// params renders the CN and PARTSTAT parameters of a person
+func (p Person) params() string {
+	var params string
+	if p.Name != "" {
+		params += `;CN="` + strings.NewReplacer(`"`, "'", "\r", "", "\n", " ").Replace(p.Name) + `"`
+	}
+	if p.PartStat != "" {
+		params += ";PARTSTAT=" + p.PartStat
+	}
+	return params
+}
+
+// formatTime formats t as a UTC date-time
+func formatTime(t time.Time) string {
+	return t.UTC().Format("20060102T150405Z")
+}
+
+// Escape escapes a TEXT value
+func Escape(s string) string {
+	return strings.NewReplacer(
+		`\`, `\\`,
+		";", `\;`,
+		",", `\,`,
+		"\r\n", `\n`,
+		"\n", `\n`,
+		"\r", `\n`,
+	).Replace(s)
+}
+
+// writer writes content lines, folding them at 75 octets without splitting
+// UTF-8 characters
+type writer struct {
+	buf bytes.Buffer
+}
+
+func (w *writer) line(s string) {
+	limit := maxLineOctets
+	for len(s) > limit {
+		cut := limit
+		for cut > 0 && !utf8.RuneStart(s[cut]) {
+			cut--
+		}
+		w.buf.WriteString(s[:cut] + "\r\n ")
+		s = s[cut:]
+		// Continuation lines start with a space, which counts
+		limit = maxLineOctets - 1
+	}
+	w.buf.WriteString(s + "\r\n")
+}
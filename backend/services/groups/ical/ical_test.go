@@ -0,0 +1,87 @@
+package ical
+
+import (
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var stamp = time.Date(2025, 3, 1, 9, 30, 0, 0, time.UTC)
+
+func TestEncode(t *testing.T) {
+	lagos := time.FixedZone("WAT", 3600)
+	calendar := &Calendar{
+		Name:   "Lagos Readers, Events",
+		Method: MethodRequest,
+		Events: []Event{{
+			UID:         "event-7@example.com",
+			Sequence:    2,
+			Status:      StatusConfirmed,
+			Summary:     "Book club; chapter 5",
+			Description: "Bring notes,\nand a friend",
+			Location:    "Yaba, Lagos",
+			URL:         "https://example.com/groups/events/7",
+			Start:       time.Date(2025, 3, 8, 16, 0, 0, 0, lagos),
+			End:         time.Date(2025, 3, 8, 18, 0, 0, 0, lagos),
+			Latitude:    6.5,
+			Longitude:   3.37,
+			HasGeo:      true,
+			Organizer:   &Person{Name: "Ada", Email: "ada@example.com"},
+			Attendees:   []Person{{Name: `Tunde "T"`, Email: "tunde@example.com", PartStat: PartStatAccepted}},
+		}},
+	}
+
+	body := string(calendar.Encode(stamp))
+	assert.True(t, strings.HasPrefix(body, "BEGIN:VCALENDAR\r\nVERSION:2.0\r\n"))
+	assert.True(t, strings.HasSuffix(body, "END:VEVENT\r\nEND:VCALENDAR\r\n"))
+	for _, line := range []string{
+		"METHOD:REQUEST",
+		`X-WR-CALNAME:Lagos Readers\, Events`,
+		"UID:event-7@example.com",
+		"DTSTAMP:20250301T093000Z",
+		"DTSTART:20250308T150000Z",
+		"DTEND:20250308T170000Z",
+		"SEQUENCE:2",
+		"STATUS:CONFIRMED",
+		`SUMMARY:Book club\; chapter 5`,
+		`DESCRIPTION:Bring notes\,\nand a friend`,
+		`LOCATION:Yaba\, Lagos`,
+		"GEO:6.500000;3.370000",
+		`ORGANIZER;CN="Ada":mailto:ada@example.com`,
+		`ATTENDEE;CN="Tunde 'T'";PARTSTAT=ACCEPTED:mailto:tunde@example.com`,
+	} {
+		assert.Contains(t, body, "\r\n"+line+"\r\n")
+	}
+	assert.NotContains(t, body, "CREATED:")
+}
+
+func TestEncodeDefaultsToPublish(t *testing.T) {
+	body := string((&Calendar{}).Encode(stamp))
+	assert.Contains(t, body, "METHOD:PUBLISH\r\n")
+	assert.NotContains(t, body, "BEGIN:VEVENT")
+}
+
+func TestLinesAreFolded(t *testing.T) {
+	calendar := &Calendar{Events: []Event{{
+		UID:         "event-1@example.com",
+		Summary:     "Ìpàdé",
+		Description: strings.Repeat("Ẹ káàbọ̀ sí ìpàdé wa. ", 20),
+	}}}
+	body := string(calendar.Encode(stamp))
+
+	var unfolded strings.Builder
+	for i, line := range strings.Split(strings.TrimSuffix(body, "\r\n"), "\r\n") {
+		require.LessOrEqual(t, len(line), 75, "line %d", i)
+		require.True(t, utf8.ValidString(line), "line %d splits a character", i)
+		if strings.HasPrefix(line, " ") {
+			unfolded.WriteString(line[1:])
+			continue
+		}
+		unfolded.WriteString("\n" + line)
+	}
+	assert.Contains(t, unfolded.String(), "\nDESCRIPTION:"+Escape(calendar.Events[0].Description)+"\n")
+}
@@ -3,6 +3,7 @@ package models
 import (
 	"time"
 
+	"github.com/yerenwgventures/GreatNigeriaLibrary-Foundation/backend/services/groups/geo"
 	"gorm.io/gorm"
 )
 
@@ -101,50 +102,75 @@ type Group struct {
 	Description string          `json:"description" gorm:"type:text"`
 	Type        GroupType       `json:"type" gorm:"size:50;not null"`
 	Visibility  GroupVisibility `json:"visibility" gorm:"size:50;not null;default:'public'"`
-	
+
 	// Location information
-	Location     string  `json:"location" gorm:"size:255"`
-	Latitude     float64 `json:"latitude" gorm:"default:0"`
-	Longitude    float64 `json:"longitude" gorm:"default:0"`
-	IsVirtual    bool    `json:"isVirtual" gorm:"default:false"`
-	
+	Location  string  `json:"location" gorm:"size:255"`
+	Latitude  float64 `json:"latitude" gorm:"default:0"`
+	Longitude float64 `json:"longitude" gorm:"default:0"`
+	IsVirtual bool    `json:"isVirtual" gorm:"default:false"`
+
+	// DistanceKm is set by location queries to the distance from the searched point
+	DistanceKm *float64 `json:"distanceKm,omitempty" gorm:"-"`
+
 	// Group settings
 	JoinApprovalRequired bool   `json:"joinApprovalRequired" gorm:"default:true"`
 	MembershipCode       string `json:"membershipCode" gorm:"size:50"` // Optional code to join
-	MaxMembers           int    `json:"maxMembers" gorm:"default:0"` // 0 means unlimited
-	
+	MaxMembers           int    `json:"maxMembers" gorm:"default:0"`   // 0 means unlimited
+
 	// Group metadata
-	CreatedByID uint      `json:"createdById" gorm:"not null"`
-	BannerImage string    `json:"bannerImage" gorm:"size:255"`
-	AvatarImage string    `json:"avatarImage" gorm:"size:255"`
-	Tags        string    `json:"tags" gorm:"size:255"` // Comma-separated tags
-	
+	CreatedByID uint   `json:"createdById" gorm:"not null"`
+	BannerImage string `json:"bannerImage" gorm:"size:255"`
+	AvatarImage string `json:"avatarImage" gorm:"size:255"`
+	Tags        string `json:"tags" gorm:"size:255"` // Comma-separated tags
+
 	// Relationships
-	Members     []GroupMember     `json:"members,omitempty" gorm:"foreignKey:GroupID"`
-	Events      []LocalEvent      `json:"events,omitempty" gorm:"foreignKey:GroupID"`
-	Resources   []SharedResource  `json:"resources,omitempty" gorm:"foreignKey:GroupID"`
-	Discussions []GroupDiscussion `json:"discussions,omitempty" gorm:"foreignKey:GroupID"`
-	Actions     []GroupAction     `json:"actions,omitempty" gorm:"foreignKey:GroupID"`
+	Members      []GroupMember      `json:"members,omitempty" gorm:"foreignKey:GroupID"`
+	Events       []LocalEvent       `json:"events,omitempty" gorm:"foreignKey:GroupID"`
+	Resources    []SharedResource   `json:"resources,omitempty" gorm:"foreignKey:GroupID"`
+	Discussions  []GroupDiscussion  `json:"discussions,omitempty" gorm:"foreignKey:GroupID"`
+	Actions      []GroupAction      `json:"actions,omitempty" gorm:"foreignKey:GroupID"`
+	Invitations  []GroupInvitation  `json:"invitations,omitempty" gorm:"foreignKey:GroupID"`
+	JoinRequests []GroupJoinRequest `json:"joinRequests,omitempty" gorm:"foreignKey:GroupID"`
+}
+
+// Position returns where the group meets. Virtual groups and groups without
+// coordinates have no position.
+func (g *Group) Position() (geo.Point, bool) {
+	return position(g.IsVirtual, g.Latitude, g.Longitude)
+}
+
+// SetDistance records the distance of the group from a searched point
+func (g *Group) SetDistance(km float64) {
+	g.DistanceKm = &km
 }
 
-// GroupMember represents a member of a group
+// position is the rule the geography columns of migration 0010 follow too
+func position(isVirtual bool, latitude, longitude float64) (geo.Point, bool) {
+	if isVirtual || (latitude == 0 && longitude == 0) {
+		return geo.Point{}, false
+	}
+	return geo.Point{Latitude: latitude, Longitude: longitude}, true
+}
+
+// GroupMember represents a member of a group. A user has at most one
+// membership per group.
 type GroupMember struct {
 	gorm.Model
-	GroupID     uint         `json:"groupId" gorm:"not null"`
-	UserID      uint         `json:"userId" gorm:"not null"`
-	Role        MemberRole   `json:"role" gorm:"size:50;not null;default:'member'"`
-	Status      MemberStatus `json:"status" gorm:"size:50;not null;default:'active'"`
-	JoinedAt    *time.Time   `json:"joinedAt"`
-	InvitedBy   *uint        `json:"invitedBy"`
-	ApprovedBy  *uint        `json:"approvedBy"`
-	LastActive  *time.Time   `json:"lastActive"`
-	Notes       string       `json:"notes" gorm:"type:text"`
+	GroupID    uint         `json:"groupId" gorm:"not null;uniqueIndex:idx_group_members_group_user,where:deleted_at IS NULL"`
+	UserID     uint         `json:"userId" gorm:"not null;uniqueIndex:idx_group_members_group_user,where:deleted_at IS NULL;index"`
+	Role       MemberRole   `json:"role" gorm:"size:50;not null;default:'member'"`
+	Status     MemberStatus `json:"status" gorm:"size:50;not null;default:'active'"`
+	JoinedAt   *time.Time   `json:"joinedAt"`
+	InvitedBy  *uint        `json:"invitedBy"`
+	ApprovedBy *uint        `json:"approvedBy"`
+	LastActive *time.Time   `json:"lastActive"`
+	Notes      string       `json:"notes" gorm:"type:text"`
 }
 
 // LocalEvent represents an event organized by a local group
 type LocalEvent struct {
 	gorm.Model
-	GroupID      uint        `json:"groupId" gorm:"not null"`
+	GroupID      uint        `json:"groupId" gorm:"not null;index"`
 	Title        string      `json:"title" gorm:"size:255;not null"`
 	Description  string      `json:"description" gorm:"type:text"`
 	Location     string      `json:"location" gorm:"size:255"`
@@ -157,30 +183,50 @@ type LocalEvent struct {
 	Status       EventStatus `json:"status" gorm:"size:50;not null;default:'planned'"`
 	CreatedByID  uint        `json:"createdById" gorm:"not null"`
 	MaxAttendees int         `json:"maxAttendees" gorm:"default:0"` // 0 means unlimited
-	
+	// Sequence counts the changes to the time, place or status of the event,
+	// so calendar apps know which copy of it is newer
+	Sequence int `json:"sequence" gorm:"not null;default:0"`
+
+	// DistanceKm is set by location queries to the distance from the searched point
+	DistanceKm *float64 `json:"distanceKm,omitempty" gorm:"-"`
+
 	// Relationships
-	Attendees []EventAttendee `json:"attendees,omitempty" gorm:"foreignKey:EventID"`
+	Attendees []EventAttendee  `json:"attendees,omitempty" gorm:"foreignKey:EventID"`
 	Resources []SharedResource `json:"resources,omitempty" gorm:"foreignKey:EventID"`
 }
 
-// EventAttendee represents a user attending a local event
+// Position returns where the event takes place. Virtual events and events
+// without coordinates have no position.
+func (e *LocalEvent) Position() (geo.Point, bool) {
+	return position(e.IsVirtual, e.Latitude, e.Longitude)
+}
+
+// SetDistance records the distance of the event from a searched point
+func (e *LocalEvent) SetDistance(km float64) {
+	e.DistanceKm = &km
+}
+
+// EventAttendee represents a user attending a local event. A user RSVPs at
+// most once per event. Users who RSVP going to a full event are waitlisted
+// and promoted in RSVP order when places free up.
 type EventAttendee struct {
 	gorm.Model
-	EventID    uint      `json:"eventId" gorm:"not null"`
-	UserID     uint      `json:"userId" gorm:"not null"`
-	Status     string    `json:"status" gorm:"size:50;not null;default:'going'"` // going, maybe, not_going
-	RSVPTime   time.Time `json:"rsvpTime" gorm:"not null"`
-	CheckedIn  bool      `json:"checkedIn" gorm:"default:false"`
-	CheckInTime *time.Time `json:"checkInTime"`
-	Notes      string    `json:"notes" gorm:"type:text"`
+	EventID       uint       `json:"eventId" gorm:"not null;uniqueIndex:idx_event_attendees_event_user,where:deleted_at IS NULL"`
+	UserID        uint       `json:"userId" gorm:"not null;uniqueIndex:idx_event_attendees_event_user,where:deleted_at IS NULL;index"`
+	Status        string     `json:"status" gorm:"size:50;not null;default:'going'"` // going, maybe, not_going, waitlisted
+	RSVPTime      time.Time  `json:"rsvpTime" gorm:"not null"`
+	CheckedIn     bool       `json:"checkedIn" gorm:"default:false"`
+	CheckInTime   *time.Time `json:"checkInTime"`
+	CheckedInByID *uint      `json:"checkedInById"`
+	Notes         string     `json:"notes" gorm:"type:text"`
 }
 
 // SharedResource represents a resource shared within a group
 type SharedResource struct {
 	gorm.Model
-	GroupID     uint         `json:"groupId"`
-	EventID     *uint        `json:"eventId"`
-	ActionID    *uint        `json:"actionId"`
+	GroupID     uint         `json:"groupId" gorm:"index"`
+	EventID     *uint        `json:"eventId" gorm:"index"`
+	ActionID    *uint        `json:"actionId" gorm:"index"`
 	Title       string       `json:"title" gorm:"size:255;not null"`
 	Description string       `json:"description" gorm:"type:text"`
 	Type        ResourceType `json:"type" gorm:"size:50;not null"`
@@ -196,13 +242,13 @@ type SharedResource struct {
 // GroupDiscussion represents a discussion thread within a group
 type GroupDiscussion struct {
 	gorm.Model
-	GroupID     uint   `json:"groupId" gorm:"not null"`
+	GroupID     uint   `json:"groupId" gorm:"not null;index"`
 	Title       string `json:"title" gorm:"size:255;not null"`
 	Content     string `json:"content" gorm:"type:text"`
 	CreatedByID uint   `json:"createdById" gorm:"not null"`
 	IsPinned    bool   `json:"isPinned" gorm:"default:false"`
 	IsLocked    bool   `json:"isLocked" gorm:"default:false"`
-	
+
 	// Relationships
 	Comments []GroupComment `json:"comments,omitempty" gorm:"foreignKey:DiscussionID"`
 }
@@ -210,25 +256,28 @@ type GroupDiscussion struct {
 // GroupComment represents a comment in a group discussion
 type GroupComment struct {
 	gorm.Model
-	DiscussionID uint   `json:"discussionId" gorm:"not null"`
+	DiscussionID uint   `json:"discussionId" gorm:"not null;index"`
 	Content      string `json:"content" gorm:"type:text"`
 	CreatedByID  uint   `json:"createdById" gorm:"not null"`
-	ParentID     *uint  `json:"parentId"` // For replies to comments
+	ParentID     *uint  `json:"parentId" gorm:"index"` // For replies to comments
+
+	// Relationships
+	Replies []GroupComment `json:"replies,omitempty" gorm:"foreignKey:ParentID"`
 }
 
 // GroupAction represents an action or task within a group
 type GroupAction struct {
 	gorm.Model
-	GroupID      uint      `json:"groupId" gorm:"not null"`
-	Title        string    `json:"title" gorm:"size:255;not null"`
-	Description  string    `json:"description" gorm:"type:text"`
-	Status       string    `json:"status" gorm:"size:50;not null;default:'pending'"` // pending, in_progress, completed, cancelled
-	Priority     string    `json:"priority" gorm:"size:50;not null;default:'medium'"` // low, medium, high, urgent
+	GroupID      uint       `json:"groupId" gorm:"not null;index"`
+	Title        string     `json:"title" gorm:"size:255;not null"`
+	Description  string     `json:"description" gorm:"type:text"`
+	Status       string     `json:"status" gorm:"size:50;not null;default:'pending'"`  // pending, in_progress, completed, cancelled
+	Priority     string     `json:"priority" gorm:"size:50;not null;default:'medium'"` // low, medium, high, urgent
 	DueDate      *time.Time `json:"dueDate"`
 	CompletedAt  *time.Time `json:"completedAt"`
-	CreatedByID  uint      `json:"createdById" gorm:"not null"`
-	AssignedToID *uint     `json:"assignedToId"`
-	
+	CreatedByID  uint       `json:"createdById" gorm:"not null"`
+	AssignedToID *uint      `json:"assignedToId" gorm:"index"`
+
 	// Relationships
 	Resources []SharedResource `json:"resources,omitempty" gorm:"foreignKey:ActionID"`
 }
@@ -236,26 +285,44 @@ type GroupAction struct {
 // GroupInvitation represents an invitation to join a group
 type GroupInvitation struct {
 	gorm.Model
-	GroupID     uint      `json:"groupId" gorm:"not null"`
+	GroupID     uint      `json:"groupId" gorm:"not null;index"`
 	Email       string    `json:"email" gorm:"size:255"`
-	UserID      *uint     `json:"userId"` // Optional, if inviting an existing user
+	UserID      *uint     `json:"userId" gorm:"index"` // Optional, if inviting an existing user
 	InvitedByID uint      `json:"invitedById" gorm:"not null"`
 	Status      string    `json:"status" gorm:"size:50;not null;default:'pending'"` // pending, accepted, declined, expired
 	ExpiresAt   time.Time `json:"expiresAt" gorm:"not null"`
-	Code        string    `json:"code" gorm:"size:100;not null"` // Unique invitation code
+	Code        string    `json:"code" gorm:"size:100;not null;uniqueIndex"` // Unique invitation code
 	Message     string    `json:"message" gorm:"type:text"`
 }
 
 // GroupJoinRequest represents a request to join a group
 type GroupJoinRequest struct {
 	gorm.Model
-	GroupID    uint      `json:"groupId" gorm:"not null"`
-	UserID     uint      `json:"userId" gorm:"not null"`
-	Status     string    `json:"status" gorm:"size:50;not null;default:'pending'"` // pending, approved, rejected
-	Message    string    `json:"message" gorm:"type:text"`
-	ApprovedBy *uint     `json:"approvedBy"`
+	GroupID    uint       `json:"groupId" gorm:"not null;index"`
+	UserID     uint       `json:"userId" gorm:"not null;index"`
+	Status     string     `json:"status" gorm:"size:50;not null;default:'pending'"` // pending, approved, rejected
+	Message    string     `json:"message" gorm:"type:text"`
+	ApprovedBy *uint      `json:"approvedBy"`
 	ApprovedAt *time.Time `json:"approvedAt"`
-	RejectedBy *uint     `json:"rejectedBy"`
+	RejectedBy *uint      `json:"rejectedBy"`
 	RejectedAt *time.Time `json:"rejectedAt"`
-	Code       string    `json:"code" gorm:"size:100"` // Optional membership code
+	Code       string     `json:"code" gorm:"size:100"` // Optional membership code
+}
+
+// CalendarToken is the secret in the URL of a user's calendar feed. Only a
+// SHA-256 hash of the token is stored; each user has at most one.
+type CalendarToken struct {
+	gorm.Model
+	UserID    uint   `json:"userId" gorm:"not null;uniqueIndex:idx_calendar_tokens_user_id,where:deleted_at IS NULL"`
+	TokenHash string `json:"-" gorm:"size:64;not null;uniqueIndex"`
+}
+
+// EventCheckInKey is the key pair that signs the check-in tokens of an
+// event. The public key is handed to organisers' devices to verify tokens
+// offline.
+type EventCheckInKey struct {
+	gorm.Model
+	EventID    uint   `json:"eventId" gorm:"not null;uniqueIndex"`
+	PublicKey  []byte `json:"publicKey" gorm:"not null"`
+	PrivateKey []byte `json:"-" gorm:"not null"`
 }
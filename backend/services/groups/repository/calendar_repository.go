@@ -0,0 +1,48 @@
+package repository
+
+import (
+	"github.com/yerenwgventures/GreatNigeriaLibrary-Foundation/backend/services/groups/models"
+	"gorm.io/gorm/clause"
+)
+
+// SaveCalendarToken stores the calendar token of a user, replacing the one
+// they had before
+func (r *GroupRepositoryImpl) SaveCalendarToken(token *models.CalendarToken) error {
+	return r.db.Clauses(clause.OnConflict{
+		Columns:     []clause.Column{{Name: "user_id"}},
+		TargetWhere: clause.Where{Exprs: []clause.Expression{clause.Expr{SQL: "deleted_at IS NULL"}}},
+		DoUpdates:   clause.AssignmentColumns([]string{"token_hash", "updated_at"}),
+	}).Create(token).Error
+}
+
+// GetCalendarTokenByHash retrieves a calendar token by the hash of its secret
+func (r *GroupRepositoryImpl) GetCalendarTokenByHash(tokenHash string) (*models.CalendarToken, error) {
+	var token models.CalendarToken
+	if err := r.db.Where("token_hash = ?", tokenHash).First(&token).Error; err != nil {
+		return nil, err
+	}
+	return &token, nil
+}
+
+// GetEventRecipient returns the email address and display name calendar
+// invites are sent to for a user
+func (r *GroupRepositoryImpl) GetEventRecipient(userID uint) (string, string, error) {
+	var recipient struct {
+		Email    string
+		FullName string
+		Username string
+	}
+	result := r.db.Table("users").
+		Select("email, full_name, username").
+		Where("id = ? AND deleted_at IS NULL", userID).
+		Take(&recipient)
+	if result.Error != nil {
+		return "", "", result.Error
+	}
+
+	name := recipient.FullName
+	if name == "" {
+		name = recipient.Username
+	}
+	return recipient.Email, name, nil
+}
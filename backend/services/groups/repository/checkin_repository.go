@@ -0,0 +1,52 @@
+package repository
+
+import (
+	"github.com/yerenwgventures/GreatNigeriaLibrary-Foundation/backend/services/groups/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// GetCheckInKey retrieves the check-in key pair of an event
+func (r *GroupRepositoryImpl) GetCheckInKey(eventID uint) (*models.EventCheckInKey, error) {
+	var key models.EventCheckInKey
+	if err := r.db.Where("event_id = ?", eventID).First(&key).Error; err != nil {
+		return nil, err
+	}
+	return &key, nil
+}
+
+// CreateCheckInKey stores the check-in key pair of an event unless it
+// already has one. Callers read the key back to get the one that won.
+func (r *GroupRepositoryImpl) CreateCheckInKey(key *models.EventCheckInKey) error {
+	return r.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "event_id"}},
+		DoNothing: true,
+	}).Create(key).Error
+}
+
+// GetNextWaitlisted retrieves the waitlisted attendee of an event who
+// RSVP'd first
+func (r *GroupRepositoryImpl) GetNextWaitlisted(eventID uint) (*models.EventAttendee, error) {
+	var attendee models.EventAttendee
+	err := r.db.Where("event_id = ? AND status = ?", eventID, "waitlisted").
+		Order("rsvp_time ASC, id ASC").
+		First(&attendee).Error
+	if err != nil {
+		return nil, err
+	}
+	return &attendee, nil
+}
+
+// LockEvent runs fn in a transaction holding a lock on the event's row, with
+// a repository that works in that transaction and the event as locked.
+// Changes that take a place at the event count the places left under it, so
+// concurrent RSVPs and waitlist promotions cannot take the same place.
+func (r *GroupRepositoryImpl) LockEvent(eventID uint, fn func(repo GroupRepository, event *models.LocalEvent) error) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var event models.LocalEvent
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&event, eventID).Error; err != nil {
+			return err
+		}
+		return fn(&GroupRepositoryImpl{db: tx}, &event)
+	})
+}
@@ -0,0 +1,349 @@
+package repository
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/yerenwgventures/GreatNigeriaLibrary-Foundation/backend/services/groups/geo"
+	"github.com/yerenwgventures/GreatNigeriaLibrary-Foundation/backend/services/groups/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Geospatial queries use the PostGIS geography columns of migration 0010
+// when they exist. Without PostGIS, on plain Postgres or SQLite, rows are
+// prefiltered on latitude and longitude with the bounding box of the search
+// and distances and clusters are computed in Go.
+
+// listedVisibilities are the group visibilities shown on maps
+var listedVisibilities = []models.GroupVisibility{models.PublicGroup, models.PrivateGroup}
+
+// mappedEventStatuses are the event statuses shown on maps
+var mappedEventStatuses = []models.EventStatus{models.PlannedEvent, models.ConfirmedEvent, models.InProgressEvent}
+
+// nearestSearchRadiusKm is the first radius tried by nearest queries without
+// PostGIS. It grows until enough rows are found or it covers the earth.
+const nearestSearchRadiusKm = 50
+
+// originSQL is the searched point as a geography, bound to longitude, latitude
+const originSQL = "ST_SetSRID(ST_MakePoint(?, ?), 4326)::geography"
+
+// mappable is a model with a position, such as a group or an event
+type mappable[T any] interface {
+	*T
+	Position() (geo.Point, bool)
+	SetDistance(km float64)
+}
+
+// nearestFirst keeps the rows within radiusKm of origin, records their
+// distance and orders them nearest first, keeping at most limit rows
+func nearestFirst[T any, P mappable[T]](rows []T, origin geo.Point, radiusKm float64, limit int) []T {
+	type entry struct {
+		row      T
+		distance float64
+	}
+
+	var entries []entry
+	for i := range rows {
+		position, ok := P(&rows[i]).Position()
+		if !ok {
+			continue
+		}
+		distance := geo.DistanceKm(origin, position)
+		if distance > radiusKm {
+			continue
+		}
+		P(&rows[i]).SetDistance(distance)
+		entries = append(entries, entry{row: rows[i], distance: distance})
+	}
+	sort.SliceStable(entries, func(i, j int) bool { return entries[i].distance < entries[j].distance })
+
+	if len(entries) > limit {
+		entries = entries[:limit]
+	}
+	nearest := make([]T, len(entries))
+	for i, e := range entries {
+		nearest[i] = e.row
+	}
+	return nearest
+}
+
+// withinBounds keeps at most limit rows positioned inside bounds
+func withinBounds[T any, P mappable[T]](rows []T, bounds geo.BoundingBox, limit int) []T {
+	var kept []T
+	for i := range rows {
+		if position, ok := P(&rows[i]).Position(); ok && bounds.Contains(position) {
+			kept = append(kept, rows[i])
+			if len(kept) == limit {
+				break
+			}
+		}
+	}
+	return kept
+}
+
+// geoTable is a table with a position, scoped to the rows shown on maps
+type geoTable struct {
+	name    string
+	scope   func(db *gorm.DB) *gorm.DB
+	preload func(db *gorm.DB) *gorm.DB // Applied when loading whole rows
+}
+
+func (t geoTable) col(name string) string {
+	return t.name + "." + name
+}
+
+// positioned is the condition for rows with a position without PostGIS. It
+// matches models.Group.Position and the generated geography columns.
+func (t geoTable) positioned() string {
+	return fmt.Sprintf("COALESCE(%s, false) = false AND (%s <> 0 OR %s <> 0)", t.col("is_virtual"), t.col("latitude"), t.col("longitude"))
+}
+
+// groupsTable holds the listed groups with their active members
+var groupsTable = geoTable{
+	name: "groups",
+	scope: func(db *gorm.DB) *gorm.DB {
+		return db.Model(&models.Group{}).Where("groups.visibility IN ?", listedVisibilities)
+	},
+	preload: func(db *gorm.DB) *gorm.DB {
+		return db.Preload("Members", func(db *gorm.DB) *gorm.DB {
+			return db.Where("status = ?", models.ActiveMember)
+		})
+	},
+}
+
+// eventsTable holds the events of listed groups that have not ended or been
+// cancelled
+var eventsTable = geoTable{
+	name: "local_events",
+	scope: func(db *gorm.DB) *gorm.DB {
+		return db.Model(&models.LocalEvent{}).
+			Joins("JOIN groups ON groups.id = local_events.group_id AND groups.deleted_at IS NULL").
+			Where("groups.visibility IN ?", listedVisibilities).
+			Where("local_events.status IN ?", mappedEventStatuses).
+			Where("local_events.end_time > ?", time.Now())
+	},
+	preload: func(db *gorm.DB) *gorm.DB { return db },
+}
+
+// hasGeography reports whether the PostGIS columns of migration 0010 exist
+func (r *GroupRepositoryImpl) hasGeography() bool {
+	r.geographyOnce.Do(func() {
+		if r.db.Dialector.Name() != "postgres" {
+			return
+		}
+		err := r.db.Raw(`SELECT EXISTS (
+			SELECT 1 FROM information_schema.columns
+			WHERE table_schema = current_schema()
+				AND table_name = 'groups' AND column_name = 'geog'
+		)`).Scan(&r.geography).Error
+		if err != nil {
+			r.geography = false
+		}
+	})
+	return r.geography
+}
+
+// rows selects whole rows of a table
+func (r *GroupRepositoryImpl) rows(t geoTable) *gorm.DB {
+	return t.preload(t.scope(r.db)).Select(t.name + ".*")
+}
+
+// inBounds restricts a query to rows positioned inside bounds
+func (r *GroupRepositoryImpl) inBounds(query *gorm.DB, t geoTable, bounds geo.BoundingBox) *gorm.DB {
+	var conditions []string
+	var args []interface{}
+	for _, span := range bounds.Spans() {
+		if r.hasGeography() {
+			conditions = append(conditions, t.col("geog")+"::geometry && ST_MakeEnvelope(?, ?, ?, ?, 4326)")
+			args = append(args, span.West, span.South, span.East, span.North)
+		} else {
+			conditions = append(conditions, fmt.Sprintf("(%s BETWEEN ? AND ? AND %s BETWEEN ? AND ?)", t.col("latitude"), t.col("longitude")))
+			args = append(args, span.South, span.North, span.West, span.East)
+		}
+	}
+
+	query = query.Where("("+strings.Join(conditions, " OR ")+")", args...)
+	if !r.hasGeography() {
+		query = query.Where(t.positioned())
+	}
+	return query
+}
+
+// byDistance orders a query by distance from origin using the GiST index
+func byDistance(t geoTable, origin geo.Point) clause.OrderBy {
+	return clause.OrderBy{Expression: clause.Expr{
+		SQL:                t.col("geog") + " <-> " + originSQL,
+		Vars:               []interface{}{origin.Longitude, origin.Latitude},
+		WithoutParentheses: true,
+	}}
+}
+
+// findNear finds rows within radiusKm of origin, nearest first
+func findNear[T any, P mappable[T]](r *GroupRepositoryImpl, t geoTable, origin geo.Point, radiusKm float64, limit int) ([]T, error) {
+	var rows []T
+	if r.hasGeography() {
+		err := r.rows(t).
+			Where("ST_DWithin("+t.col("geog")+", "+originSQL+", ?)", origin.Longitude, origin.Latitude, radiusKm*1000).
+			Order(byDistance(t, origin)).
+			Limit(limit).
+			Find(&rows).Error
+		if err != nil {
+			return nil, err
+		}
+		return nearestFirst[T, P](rows, origin, math.Inf(1), limit), nil
+	}
+
+	if err := r.inBounds(r.rows(t), t, geo.Around(origin, radiusKm)).Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	return nearestFirst[T, P](rows, origin, radiusKm, limit), nil
+}
+
+// findNearest finds the limit rows nearest to origin
+func findNearest[T any, P mappable[T]](r *GroupRepositoryImpl, t geoTable, origin geo.Point, limit int) ([]T, error) {
+	if r.hasGeography() {
+		var rows []T
+		err := r.rows(t).
+			Where(t.col("geog") + " IS NOT NULL").
+			Order(byDistance(t, origin)).
+			Limit(limit).
+			Find(&rows).Error
+		if err != nil {
+			return nil, err
+		}
+		return nearestFirst[T, P](rows, origin, math.Inf(1), limit), nil
+	}
+
+	halfCircumference := math.Pi * geo.EarthRadiusKm
+	for radius := float64(nearestSearchRadiusKm); ; radius *= 4 {
+		rows, err := findNear[T, P](r, t, origin, radius, limit)
+		if err != nil || len(rows) == limit || radius >= halfCircumference {
+			return rows, err
+		}
+	}
+}
+
+// findInBounds finds at most limit rows inside bounds
+func findInBounds[T any](r *GroupRepositoryImpl, t geoTable, bounds geo.BoundingBox, limit int) ([]T, error) {
+	var rows []T
+	err := r.inBounds(r.rows(t), t, bounds).Order(t.col("id")).Limit(limit).Find(&rows).Error
+	return rows, err
+}
+
+// clusterRow is a cluster computed by the database
+type clusterRow struct {
+	Count     int
+	Latitude  float64
+	Longitude float64
+	South     float64
+	West      float64
+	North     float64
+	East      float64
+	ID        uint
+}
+
+// featureRow is the position of a row to cluster in Go
+type featureRow struct {
+	ID        uint
+	Latitude  float64
+	Longitude float64
+}
+
+// cluster groups the rows inside bounds by the cells of geo.Cell
+func (r *GroupRepositoryImpl) cluster(t geoTable, bounds geo.BoundingBox, zoom int) ([]geo.Cluster, error) {
+	if !r.hasGeography() {
+		var rows []featureRow
+		query := t.scope(r.db).Select(fmt.Sprintf("%s, %s, %s", t.col("id"), t.col("latitude"), t.col("longitude")))
+		if err := r.inBounds(query, t, bounds).Scan(&rows).Error; err != nil {
+			return nil, err
+		}
+
+		features := make([]geo.Feature, len(rows))
+		for i, row := range rows {
+			features[i] = geo.Feature{ID: row.ID, Point: geo.Point{Latitude: row.Latitude, Longitude: row.Longitude}}
+		}
+		return geo.ClusterFeatures(features, zoom), nil
+	}
+
+	// The same projection and cells as geo.Cell
+	size := geo.CellSizeMeters(zoom)
+	half := geo.HalfCells(zoom)
+	cells := t.scope(r.db).Select(fmt.Sprintf(`%[1]s.id, %[1]s.latitude, %[1]s.longitude,
+		LEAST(GREATEST(floor(radians(%[1]s.longitude) * ? / ?), ?), ?) AS cell_x,
+		LEAST(GREATEST(floor(ln(tan(pi() / 4 + radians(LEAST(GREATEST(%[1]s.latitude, ?), ?)) / 2)) * ? / ?), ?), ?) AS cell_y`, t.name),
+		geo.MercatorRadius, size, -half, half-1,
+		-geo.MaxMercatorLatitude, geo.MaxMercatorLatitude, geo.MercatorRadius, size, -half, half-1)
+
+	var rows []clusterRow
+	err := r.db.Table("(?) AS cells", r.inBounds(cells, t, bounds)).
+		Select(`COUNT(*) AS count, AVG(latitude) AS latitude, AVG(longitude) AS longitude,
+			MIN(latitude) AS south, MIN(longitude) AS west, MAX(latitude) AS north, MAX(longitude) AS east,
+			MIN(id) AS id`).
+		Group("cell_x, cell_y").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	clusters := make([]geo.Cluster, len(rows))
+	for i, row := range rows {
+		clusters[i] = geo.Cluster{
+			Point:  geo.Point{Latitude: row.Latitude, Longitude: row.Longitude},
+			Count:  row.Count,
+			Bounds: geo.BoundingBox{South: row.South, West: row.West, North: row.North, East: row.East},
+		}
+		if row.Count == 1 {
+			clusters[i].ID = row.ID
+		}
+	}
+	geo.SortClusters(clusters)
+	return clusters, nil
+}
+
+// GetGroupsByLocation retrieves the listed groups within radiusKm of a
+// point, nearest first, up to geo.MaxResults
+func (r *GroupRepositoryImpl) GetGroupsByLocation(latitude, longitude float64, radiusKm float64) ([]models.Group, error) {
+	origin := geo.Point{Latitude: latitude, Longitude: longitude}
+	return findNear[models.Group](r, groupsTable, origin, radiusKm, geo.MaxResults)
+}
+
+// GetGroupsInBounds retrieves up to limit listed groups inside a bounding box
+func (r *GroupRepositoryImpl) GetGroupsInBounds(bounds geo.BoundingBox, limit int) ([]models.Group, error) {
+	return findInBounds[models.Group](r, groupsTable, bounds, limit)
+}
+
+// GetNearestGroups retrieves the limit listed groups nearest to a point
+func (r *GroupRepositoryImpl) GetNearestGroups(origin geo.Point, limit int) ([]models.Group, error) {
+	return findNearest[models.Group](r, groupsTable, origin, limit)
+}
+
+// ClusterGroups clusters the listed groups inside a bounding box for a zoom level
+func (r *GroupRepositoryImpl) ClusterGroups(bounds geo.BoundingBox, zoom int) ([]geo.Cluster, error) {
+	return r.cluster(groupsTable, bounds, zoom)
+}
+
+// GetEventsByLocation retrieves the upcoming events within radiusKm of a
+// point, nearest first, up to geo.MaxResults
+func (r *GroupRepositoryImpl) GetEventsByLocation(latitude, longitude float64, radiusKm float64) ([]models.LocalEvent, error) {
+	origin := geo.Point{Latitude: latitude, Longitude: longitude}
+	return findNear[models.LocalEvent](r, eventsTable, origin, radiusKm, geo.MaxResults)
+}
+
+// GetEventsInBounds retrieves up to limit upcoming events inside a bounding box
+func (r *GroupRepositoryImpl) GetEventsInBounds(bounds geo.BoundingBox, limit int) ([]models.LocalEvent, error) {
+	return findInBounds[models.LocalEvent](r, eventsTable, bounds, limit)
+}
+
+// GetNearestEvents retrieves the limit upcoming events nearest to a point
+func (r *GroupRepositoryImpl) GetNearestEvents(origin geo.Point, limit int) ([]models.LocalEvent, error) {
+	return findNearest[models.LocalEvent](r, eventsTable, origin, limit)
+}
+
+// ClusterEvents clusters the upcoming events inside a bounding box for a zoom level
+func (r *GroupRepositoryImpl) ClusterEvents(bounds geo.BoundingBox, zoom int) ([]geo.Cluster, error) {
+	return r.cluster(eventsTable, bounds, zoom)
+}
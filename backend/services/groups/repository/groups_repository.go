@@ -1,9 +1,10 @@
 package repository
 
 import (
-	"fmt"
+	"sync"
 	"time"
 
+	"github.com/yerenwgventures/GreatNigeriaLibrary-Foundation/backend/services/groups/geo"
 	"github.com/yerenwgventures/GreatNigeriaLibrary-Foundation/backend/services/groups/models"
 	"gorm.io/gorm"
 )
@@ -88,11 +89,36 @@ type GroupRepository interface {
 	GetJoinRequestsByUserID(userID uint, status string, page, pageSize int) ([]models.GroupJoinRequest, int64, error)
 	UpdateJoinRequest(request *models.GroupJoinRequest) error
 	DeleteJoinRequest(id uint) error
+
+	// Geospatial operations. Only groups and events with a position are
+	// found, see models.Group.Position.
+	GetGroupsInBounds(bounds geo.BoundingBox, limit int) ([]models.Group, error)
+	GetNearestGroups(origin geo.Point, limit int) ([]models.Group, error)
+	ClusterGroups(bounds geo.BoundingBox, zoom int) ([]geo.Cluster, error)
+	GetEventsByLocation(latitude, longitude float64, radiusKm float64) ([]models.LocalEvent, error)
+	GetEventsInBounds(bounds geo.BoundingBox, limit int) ([]models.LocalEvent, error)
+	GetNearestEvents(origin geo.Point, limit int) ([]models.LocalEvent, error)
+	ClusterEvents(bounds geo.BoundingBox, zoom int) ([]geo.Cluster, error)
+
+	// Calendar operations
+	SaveCalendarToken(token *models.CalendarToken) error
+	GetCalendarTokenByHash(tokenHash string) (*models.CalendarToken, error)
+	GetEventRecipient(userID uint) (email, name string, err error)
+
+	// Check-in operations
+	GetCheckInKey(eventID uint) (*models.EventCheckInKey, error)
+	CreateCheckInKey(key *models.EventCheckInKey) error
+	GetNextWaitlisted(eventID uint) (*models.EventAttendee, error)
+	LockEvent(eventID uint, fn func(repo GroupRepository, event *models.LocalEvent) error) error
 }
 
 // GroupRepositoryImpl implements the GroupRepository interface
 type GroupRepositoryImpl struct {
 	db *gorm.DB
+
+	// Whether the PostGIS columns of migration 0010 exist, checked once
+	geographyOnce sync.Once
+	geography     bool
 }
 
 // NewGroupRepository creates a new group repository
@@ -141,31 +167,6 @@ func (r *GroupRepositoryImpl) GetGroupsByUserID(userID uint, includeInvited bool
 	return groups, nil
 }
 
-// GetGroupsByLocation retrieves groups near a specific location
-func (r *GroupRepositoryImpl) GetGroupsByLocation(latitude, longitude float64, radiusKm float64) ([]models.Group, error) {
-	var groups []models.Group
-
-	// Haversine formula to calculate distance
-	// This is a simplified version and might not be accurate for very large distances
-	// For production, consider using PostGIS or a similar spatial database extension
-	distanceQuery := fmt.Sprintf(
-		"(6371 * acos(cos(radians(%f)) * cos(radians(latitude)) * cos(radians(longitude) - radians(%f)) + sin(radians(%f)) * sin(radians(latitude))))",
-		latitude, longitude, latitude,
-	)
-
-	err := r.db.Where(fmt.Sprintf("%s <= ?", distanceQuery), radiusKm).
-		Where("visibility = ? OR visibility = ?", models.PublicGroup, models.PrivateGroup).
-		Preload("Members", func(db *gorm.DB) *gorm.DB {
-			return db.Where("status = ?", models.ActiveMember)
-		}).Find(&groups).Error
-
-	if err != nil {
-		return nil, err
-	}
-
-	return groups, nil
-}
-
 // UpdateGroup updates a group
 func (r *GroupRepositoryImpl) UpdateGroup(group *models.Group) error {
 	return r.db.Save(group).Error
@@ -0,0 +1,326 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"net/url"
+	"time"
+
+	"github.com/yerenwgventures/GreatNigeriaLibrary-Foundation/backend/pkg/common/mailer"
+	"github.com/yerenwgventures/GreatNigeriaLibrary-Foundation/backend/services/groups/ical"
+	"github.com/yerenwgventures/GreatNigeriaLibrary-Foundation/backend/services/groups/models"
+)
+
+const (
+	// calendarTokenLength is the length of the secret in calendar feed URLs
+	calendarTokenLength = 40
+	// calendarPageSize is the page size used to read every event of a feed
+	calendarPageSize = 100
+	// calendarHistory is how long finished events stay in group feeds
+	calendarHistory = 90 * 24 * time.Hour
+	// maxInviteRecipients caps the attendees emailed about one change
+	maxInviteRecipients = 1000
+)
+
+// ErrCalendarNotFound is returned for unknown calendar tokens and for the
+// feeds of groups that are not public
+var ErrCalendarNotFound = errors.New("calendar not found")
+
+// eventTimeZone is the time zone of event times in emails. Nigeria keeps
+// West Africa Time all year.
+var eventTimeZone = time.FixedZone("WAT", 3600)
+
+// inviteKind is why a calendar invite is emailed to an attendee
+type inviteKind int
+
+const (
+	// inviteRSVP follows an RSVP of going or maybe
+	inviteRSVP inviteKind = iota
+	// inviteUpdate follows a change to the time, place or status of the event
+	inviteUpdate
+	// inviteCancel follows the cancellation or deletion of the event
+	inviteCancel
+	// inviteWithdraw follows an attendee changing their RSVP to not going
+	inviteWithdraw
+)
+
+// attending reports whether an RSVP status puts the event in the
+// attendee's calendar
+func attending(status string) bool {
+	return status == "going" || status == "maybe"
+}
+
+// partStat maps an RSVP status to an iCalendar participation status
+func partStat(status string) string {
+	switch status {
+	case "going":
+		return ical.PartStatAccepted
+	case "maybe":
+		return ical.PartStatTentative
+	case "not_going":
+		return ical.PartStatDeclined
+	default:
+		return ical.PartStatNeedsAction
+	}
+}
+
+// calendarStatus maps an event status to an iCalendar event status
+func calendarStatus(status models.EventStatus) string {
+	switch status {
+	case models.PlannedEvent:
+		return ical.StatusTentative
+	case models.CancelledEvent:
+		return ical.StatusCancelled
+	default:
+		return ical.StatusConfirmed
+	}
+}
+
+// rescheduled reports whether an update changes the time, place or status
+// of an event, which calendar apps need a new sequence number to pick up
+func rescheduled(existing, updated *models.LocalEvent) bool {
+	return !existing.StartTime.Equal(updated.StartTime) ||
+		!existing.EndTime.Equal(updated.EndTime) ||
+		existing.Location != updated.Location ||
+		existing.Latitude != updated.Latitude ||
+		existing.Longitude != updated.Longitude ||
+		existing.IsVirtual != updated.IsVirtual ||
+		existing.VirtualLink != updated.VirtualLink ||
+		existing.Status != updated.Status
+}
+
+// hashCalendarToken returns the stored form of a calendar token
+func hashCalendarToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// calendarDomain is the domain part of event UIDs, taken from the frontend
+// URL so UIDs stay stable across deployments of the same site
+func (s *GroupServiceImpl) calendarDomain() string {
+	if u, err := url.Parse(s.frontendURL); err == nil && u.Hostname() != "" {
+		return u.Hostname()
+	}
+	return "localhost"
+}
+
+// eventURL returns the frontend page of an event, or "" without a frontend URL
+func (s *GroupServiceImpl) eventURL(eventID uint) string {
+	if s.frontendURL == "" {
+		return ""
+	}
+	return fmt.Sprintf("%s/groups/events/%d", s.frontendURL, eventID)
+}
+
+// calendarEvent converts an event into a VEVENT without attendees
+func (s *GroupServiceImpl) calendarEvent(event *models.LocalEvent) ical.Event {
+	e := ical.Event{
+		UID:          fmt.Sprintf("group-event-%d@%s", event.ID, s.calendarDomain()),
+		Sequence:     event.Sequence,
+		Status:       calendarStatus(event.Status),
+		Summary:      event.Title,
+		Description:  event.Description,
+		Location:     event.Location,
+		URL:          s.eventURL(event.ID),
+		Start:        event.StartTime,
+		End:          event.EndTime,
+		Created:      event.CreatedAt,
+		LastModified: event.UpdatedAt,
+	}
+	if event.VirtualLink != "" {
+		if e.Location == "" {
+			e.Location = event.VirtualLink
+		}
+		if e.Description != "" {
+			e.Description += "\n\n"
+		}
+		e.Description += "Join online: " + event.VirtualLink
+	}
+	if point, ok := event.Position(); ok {
+		e.Latitude, e.Longitude, e.HasGeo = point.Latitude, point.Longitude, true
+	}
+	return e
+}
+
+// encodeCalendar renders events as a published calendar
+func (s *GroupServiceImpl) encodeCalendar(name string, events []models.LocalEvent) []byte {
+	calendar := &ical.Calendar{Name: name, Method: ical.MethodPublish}
+	for i := range events {
+		calendar.Events = append(calendar.Events, s.calendarEvent(&events[i]))
+	}
+	return calendar.Encode(time.Now())
+}
+
+// GetEventCalendar renders a single event as an .ics file
+func (s *GroupServiceImpl) GetEventCalendar(eventID uint) ([]byte, error) {
+	event, err := s.groupRepo.GetEventByID(eventID)
+	if err != nil {
+		return nil, err
+	}
+	return s.encodeCalendar(event.Title, []models.LocalEvent{*event}), nil
+}
+
+// CreateCalendarToken issues the secret of a user's calendar feed URL. Any
+// token issued before stops working.
+func (s *GroupServiceImpl) CreateCalendarToken(userID uint) (string, error) {
+	token, err := generateRandomCode(calendarTokenLength)
+	if err != nil {
+		return "", err
+	}
+
+	err = s.groupRepo.SaveCalendarToken(&models.CalendarToken{
+		UserID:    userID,
+		TokenHash: hashCalendarToken(token),
+	})
+	if err != nil {
+		return "", err
+	}
+	return token, nil
+}
+
+// GetUserCalendar renders the feed of a calendar token: the upcoming events
+// its user has RSVP'd going or maybe to
+func (s *GroupServiceImpl) GetUserCalendar(token string) ([]byte, error) {
+	calendarToken, err := s.groupRepo.GetCalendarTokenByHash(hashCalendarToken(token))
+	if err != nil {
+		return nil, ErrCalendarNotFound
+	}
+	userID := calendarToken.UserID
+
+	var events []models.LocalEvent
+	for page := 1; ; page++ {
+		batch, total, err := s.GetUpcomingEvents(userID, page, calendarPageSize)
+		if err != nil {
+			return nil, err
+		}
+		for _, event := range batch {
+			for _, attendee := range event.Attendees {
+				if attendee.UserID == userID && attending(attendee.Status) {
+					events = append(events, event)
+					break
+				}
+			}
+		}
+		if len(batch) == 0 || int64(page*calendarPageSize) >= total {
+			break
+		}
+	}
+
+	return s.encodeCalendar("My group events", events), nil
+}
+
+// GetGroupCalendar renders the feed of a public group: its upcoming events
+// and those that finished recently. Cancelled events stay in the feed so
+// subscribed calendars show them as cancelled.
+func (s *GroupServiceImpl) GetGroupCalendar(groupID uint) ([]byte, error) {
+	group, err := s.groupRepo.GetGroupByID(groupID)
+	if err != nil || group.Visibility != models.PublicGroup {
+		return nil, ErrCalendarNotFound
+	}
+
+	since := time.Now().Add(-calendarHistory)
+	var events []models.LocalEvent
+	for page := 1; ; page++ {
+		batch, total, err := s.groupRepo.GetEventsByGroupID(groupID, "", page, calendarPageSize)
+		if err != nil {
+			return nil, err
+		}
+		for _, event := range batch {
+			if event.EndTime.After(since) {
+				events = append(events, event)
+			}
+		}
+		if len(batch) == 0 || int64(page*calendarPageSize) >= total {
+			break
+		}
+	}
+
+	return s.encodeCalendar(group.Name, events), nil
+}
+
+// sendEventInvites emails a calendar invite about an event to each of its
+// attendees whose RSVP puts it in their calendar. Failures are logged.
+func (s *GroupServiceImpl) sendEventInvites(event *models.LocalEvent, kind inviteKind) {
+	if s.mailer == nil || s.emailTemplates == nil {
+		return
+	}
+
+	attendees, _, err := s.groupRepo.GetAttendeesByEventID(event.ID, "", 1, maxInviteRecipients)
+	if err != nil {
+		log.Printf("Error loading attendees of event %d for calendar invites: %v", event.ID, err)
+		return
+	}
+	for i := range attendees {
+		if attending(attendees[i].Status) {
+			s.sendEventInvite(event, &attendees[i], kind)
+		}
+	}
+}
+
+// sendEventInvite emails a calendar invite about an event to one attendee.
+// Failures are logged; the RSVP or change stands without the email.
+func (s *GroupServiceImpl) sendEventInvite(event *models.LocalEvent, attendee *models.EventAttendee, kind inviteKind) {
+	if s.mailer == nil || s.emailTemplates == nil {
+		return
+	}
+	if err := s.emailEventInvite(event, attendee, kind); err != nil {
+		log.Printf("Error sending calendar invite for event %d to user %d: %v", event.ID, attendee.UserID, err)
+	}
+}
+
+func (s *GroupServiceImpl) emailEventInvite(event *models.LocalEvent, attendee *models.EventAttendee, kind inviteKind) error {
+	email, name, err := s.groupRepo.GetEventRecipient(attendee.UserID)
+	if err != nil {
+		return err
+	}
+	if email == "" {
+		return errors.New("user has no email address")
+	}
+	group, err := s.groupRepo.GetGroupByID(event.GroupID)
+	if err != nil {
+		return err
+	}
+
+	method := ical.MethodRequest
+	if kind == inviteCancel || kind == inviteWithdraw {
+		method = ical.MethodCancel
+	}
+
+	invite := s.calendarEvent(event)
+	if kind == inviteCancel {
+		invite.Status = ical.StatusCancelled
+	}
+	if organizerEmail, organizerName, err := s.groupRepo.GetEventRecipient(event.CreatedByID); err == nil && organizerEmail != "" {
+		invite.Organizer = &ical.Person{Name: organizerName, Email: organizerEmail}
+	}
+	invite.Attendees = []ical.Person{{Name: name, Email: email, PartStat: partStat(attendee.Status)}}
+	calendar := &ical.Calendar{Method: method, Events: []ical.Event{invite}}
+
+	msg, err := s.emailTemplates.Render(mailer.TemplateEventInvitation, mailer.EventInvitationData{
+		Name:        name,
+		EventTitle:  event.Title,
+		GroupName:   group.Name,
+		StartTime:   event.StartTime.In(eventTimeZone),
+		EndTime:     event.EndTime.In(eventTimeZone),
+		Location:    event.Location,
+		VirtualLink: event.VirtualLink,
+		EventURL:    s.eventURL(event.ID),
+		Updated:     kind == inviteUpdate,
+		Cancelled:   kind == inviteCancel,
+		Withdrawn:   kind == inviteWithdraw,
+	})
+	if err != nil {
+		return err
+	}
+	msg.To = []string{email}
+	msg.Attachments = []mailer.Attachment{{
+		Filename:    "invite.ics",
+		ContentType: ical.ContentType + "; method=" + string(method),
+		Content:     calendar.Encode(time.Now()),
+	}}
+	return s.mailer.Send(context.Background(), msg)
+}
@@ -0,0 +1,310 @@
+package service
+
+import (
+	"crypto/ed25519"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/yerenwgventures/GreatNigeriaLibrary-Foundation/backend/services/groups/checkin"
+	"github.com/yerenwgventures/GreatNigeriaLibrary-Foundation/backend/services/groups/models"
+	"github.com/yerenwgventures/GreatNigeriaLibrary-Foundation/backend/services/groups/repository"
+	notificationmodels "github.com/yerenwgventures/GreatNigeriaLibrary-Foundation/backend/services/notification/models"
+)
+
+const (
+	// checkInGrace is how long after an event ends its check-in tokens stay valid
+	checkInGrace = 2 * time.Hour
+	// MaxCheckInBatch caps the check-ins uploaded at once
+	MaxCheckInBatch = 500
+)
+
+// Outcomes of a check-in
+const (
+	// CheckInAccepted means the attendee is now checked in
+	CheckInAccepted = "checked_in"
+	// CheckInDuplicate means the attendee was already checked in. The
+	// earliest scan is kept.
+	CheckInDuplicate = "duplicate"
+	// CheckInInvalid means the token is malformed, forged, expired or for
+	// another event
+	CheckInInvalid = "invalid"
+	// CheckInRejected means the token is genuine but its RSVP was withdrawn
+	CheckInRejected = "rejected"
+)
+
+// ErrCheckInBatchTooLarge is returned for uploads of more than MaxCheckInBatch check-ins
+var ErrCheckInBatchTooLarge = fmt.Errorf("at most %d check-ins can be uploaded at once", MaxCheckInBatch)
+
+// CheckInToken is the signed token an attendee shows as a QR code
+type CheckInToken struct {
+	Token     string    `json:"token"`
+	ExpiresAt time.Time `json:"expiresAt"`
+}
+
+// CheckInKey is the public key organisers' devices verify tokens with
+type CheckInKey struct {
+	EventID   uint   `json:"eventId"`
+	Algorithm string `json:"algorithm"`
+	PublicKey string `json:"publicKey"` // base64url, no padding
+}
+
+// CheckIn is a scanned token. ScannedAt is when an offline device scanned
+// it; it defaults to the time of upload.
+type CheckIn struct {
+	Token     string    `json:"token" binding:"required"`
+	ScannedAt time.Time `json:"scannedAt"`
+}
+
+// CheckInResult is the outcome of one check-in
+type CheckInResult struct {
+	Status   string                `json:"status"`
+	Error    string                `json:"error,omitempty"`
+	Attendee *models.EventAttendee `json:"attendee,omitempty"`
+}
+
+// authorizeCheckIn checks that a user may check in the attendees of an event
+func (s *GroupServiceImpl) authorizeCheckIn(event *models.LocalEvent, userID uint) error {
+	if userID == event.CreatedByID {
+		return nil
+	}
+	member, err := s.groupRepo.GetMemberByID(event.GroupID, userID)
+	if err != nil || (member.Role != models.OwnerRole && member.Role != models.AdminRole && member.Role != models.ModeratorRole) {
+		return errors.New("unauthorized: only the event creator or group admins/moderators can check in attendees")
+	}
+	return nil
+}
+
+// checkInKey returns the key pair of an event, creating it on first use
+func (s *GroupServiceImpl) checkInKey(eventID uint) (*models.EventCheckInKey, error) {
+	if key, err := s.groupRepo.GetCheckInKey(eventID); err == nil {
+		return key, nil
+	}
+
+	public, private, err := checkin.GenerateKey()
+	if err != nil {
+		return nil, err
+	}
+	err = s.groupRepo.CreateCheckInKey(&models.EventCheckInKey{
+		EventID:    eventID,
+		PublicKey:  public,
+		PrivateKey: private,
+	})
+	if err != nil {
+		return nil, err
+	}
+	// Another request may have created the key first
+	return s.groupRepo.GetCheckInKey(eventID)
+}
+
+// GetCheckInToken issues the check-in token of a user going to an event. It
+// expires a little after the event ends.
+func (s *GroupServiceImpl) GetCheckInToken(eventID, userID uint) (*CheckInToken, error) {
+	event, err := s.groupRepo.GetEventByID(eventID)
+	if err != nil {
+		return nil, err
+	}
+	if event.Status == models.CancelledEvent {
+		return nil, errors.New("event has been cancelled")
+	}
+
+	attendee, err := s.groupRepo.GetAttendeeByID(eventID, userID)
+	if err != nil || attendee.Status != "going" {
+		return nil, errors.New("only attendees going to the event can check in")
+	}
+
+	expiresAt := event.EndTime.Add(checkInGrace)
+	if !time.Now().Before(expiresAt) {
+		return nil, errors.New("check-in for this event has closed")
+	}
+
+	key, err := s.checkInKey(eventID)
+	if err != nil {
+		return nil, err
+	}
+	token, err := checkin.Sign(ed25519.PrivateKey(key.PrivateKey), checkin.Claims{
+		EventID:    eventID,
+		AttendeeID: attendee.ID,
+		UserID:     userID,
+		ExpiresAt:  expiresAt.Unix(),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &CheckInToken{Token: token, ExpiresAt: time.Unix(expiresAt.Unix(), 0)}, nil
+}
+
+// GetCheckInKey returns the public key of an event to an organiser
+func (s *GroupServiceImpl) GetCheckInKey(eventID, organizerID uint) (*CheckInKey, error) {
+	event, err := s.groupRepo.GetEventByID(eventID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.authorizeCheckIn(event, organizerID); err != nil {
+		return nil, err
+	}
+
+	key, err := s.checkInKey(eventID)
+	if err != nil {
+		return nil, err
+	}
+	return &CheckInKey{
+		EventID:   eventID,
+		Algorithm: checkin.Algorithm,
+		PublicKey: checkin.EncodeKey(ed25519.PublicKey(key.PublicKey)),
+	}, nil
+}
+
+// CheckInBatch records check-ins scanned by an organiser, online or
+// uploaded later from an offline device. Each check-in has its own result,
+// in the order given.
+func (s *GroupServiceImpl) CheckInBatch(eventID, organizerID uint, checkIns []CheckIn) ([]CheckInResult, error) {
+	if len(checkIns) > MaxCheckInBatch {
+		return nil, ErrCheckInBatchTooLarge
+	}
+
+	event, err := s.groupRepo.GetEventByID(eventID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.authorizeCheckIn(event, organizerID); err != nil {
+		return nil, err
+	}
+	key, err := s.checkInKey(eventID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	results := make([]CheckInResult, len(checkIns))
+	for i, c := range checkIns {
+		result, err := s.checkInToken(event, ed25519.PublicKey(key.PublicKey), organizerID, c, now)
+		if err != nil {
+			return nil, err
+		}
+		results[i] = result
+	}
+	return results, nil
+}
+
+// checkInToken records one check-in. Errors are only returned when the
+// repository fails.
+func (s *GroupServiceImpl) checkInToken(event *models.LocalEvent, key ed25519.PublicKey, organizerID uint, c CheckIn, now time.Time) (CheckInResult, error) {
+	// Device clocks may run ahead; a scan cannot be later than its upload
+	scannedAt := c.ScannedAt
+	if scannedAt.IsZero() || scannedAt.After(now) {
+		scannedAt = now
+	}
+
+	claims, err := checkin.Verify(key, c.Token, event.ID, scannedAt)
+	if err != nil {
+		return CheckInResult{Status: CheckInInvalid, Error: err.Error()}, nil
+	}
+
+	// A withdrawn and repeated RSVP is a new attendee record
+	attendee, err := s.groupRepo.GetAttendeeByID(event.ID, claims.UserID)
+	if err != nil || attendee.ID != claims.AttendeeID {
+		return CheckInResult{Status: CheckInRejected, Error: "the RSVP of this token has been withdrawn"}, nil
+	}
+
+	if attendee.CheckedIn {
+		// Several devices may scan the same person; the earliest scan wins
+		if attendee.CheckInTime == nil || scannedAt.Before(*attendee.CheckInTime) {
+			attendee.CheckInTime = &scannedAt
+			attendee.CheckedInByID = &organizerID
+			if err := s.groupRepo.UpdateAttendee(attendee); err != nil {
+				return CheckInResult{}, err
+			}
+		}
+		return CheckInResult{Status: CheckInDuplicate, Attendee: attendee}, nil
+	}
+
+	if attendee.Status != "going" {
+		return CheckInResult{Status: CheckInRejected, Error: "the attendee is no longer going", Attendee: attendee}, nil
+	}
+
+	attendee.CheckedIn = true
+	attendee.CheckInTime = &scannedAt
+	attendee.CheckedInByID = &organizerID
+	if err := s.groupRepo.UpdateAttendee(attendee); err != nil {
+		return CheckInResult{}, err
+	}
+	return CheckInResult{Status: CheckInAccepted, Attendee: attendee}, nil
+}
+
+// eventFull reports whether an event has no places left for attendees going.
+// Callers hold the event's lock, see GroupRepository.LockEvent.
+func eventFull(repo repository.GroupRepository, event *models.LocalEvent) (bool, error) {
+	if event.MaxAttendees <= 0 {
+		return false, nil
+	}
+	_, going, err := repo.GetAttendeesByEventID(event.ID, "going", 1, 1)
+	if err != nil {
+		return false, err
+	}
+	return going >= int64(event.MaxAttendees), nil
+}
+
+// promoteWaitlist moves waitlisted attendees to going, first come first
+// served, while the event has places. Failures are logged; the change that
+// freed the place stands.
+func (s *GroupServiceImpl) promoteWaitlist(event *models.LocalEvent) {
+	if event.Status == models.CancelledEvent {
+		return
+	}
+
+	for {
+		// Take the place under the event's lock, so an RSVP cannot take it too
+		var next *models.EventAttendee
+		err := s.groupRepo.LockEvent(event.ID, func(repo repository.GroupRepository, locked *models.LocalEvent) error {
+			full, err := eventFull(repo, locked)
+			if err != nil || full {
+				return err
+			}
+
+			waiting, err := repo.GetNextWaitlisted(event.ID)
+			if err != nil {
+				// Nobody is waiting
+				return nil
+			}
+			waiting.Status = "going"
+			if err := repo.UpdateAttendee(waiting); err != nil {
+				return err
+			}
+			next = waiting
+			return nil
+		})
+		if err != nil {
+			log.Printf("Error promoting the waitlist of event %d: %v", event.ID, err)
+			return
+		}
+		if next == nil {
+			return
+		}
+
+		s.sendEventInvite(event, next, inviteRSVP)
+		if err := s.notifyPromotion(event, next); err != nil {
+			log.Printf("Error notifying user %d of a place at event %d: %v", next.UserID, event.ID, err)
+		}
+	}
+}
+
+// notifyPromotion tells a user in the app that they moved off a waitlist
+func (s *GroupServiceImpl) notifyPromotion(event *models.LocalEvent, attendee *models.EventAttendee) error {
+	if s.notifier == nil {
+		return nil
+	}
+
+	n := &notificationmodels.Notification{
+		UserID:        attendee.UserID,
+		Type:          notificationmodels.TypeEventWaitlist,
+		Title:         fmt.Sprintf("A place opened up at %s", event.Title),
+		Body:          "You have moved off the waitlist and are now going.",
+		Link:          fmt.Sprintf("/groups/events/%d", event.ID),
+		ReferenceType: "local_event",
+		ReferenceID:   event.ID,
+	}
+	return s.notifier.Notify(n)
+}
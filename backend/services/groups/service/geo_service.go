@@ -0,0 +1,87 @@
+package service
+
+import (
+	"github.com/yerenwgventures/GreatNigeriaLibrary-Foundation/backend/services/groups/geo"
+	"github.com/yerenwgventures/GreatNigeriaLibrary-Foundation/backend/services/groups/models"
+)
+
+// validateRadiusSearch checks the center and radius of a radius search
+func validateRadiusSearch(latitude, longitude float64, radiusKm float64) error {
+	if err := (geo.Point{Latitude: latitude, Longitude: longitude}).Validate(); err != nil {
+		return err
+	}
+	return geo.ValidateRadius(radiusKm)
+}
+
+// clampLimit applies the default to a missing limit and caps it at geo.MaxResults
+func clampLimit(limit, defaultLimit int) int {
+	if limit <= 0 {
+		return defaultLimit
+	}
+	if limit > geo.MaxResults {
+		return geo.MaxResults
+	}
+	return limit
+}
+
+// GetGroupsInBounds retrieves the groups inside a map viewport
+func (s *GroupServiceImpl) GetGroupsInBounds(bounds geo.BoundingBox, limit int) ([]models.Group, error) {
+	if err := bounds.Validate(); err != nil {
+		return nil, err
+	}
+	return s.groupRepo.GetGroupsInBounds(bounds, clampLimit(limit, geo.MaxResults))
+}
+
+// GetNearestGroups retrieves the groups nearest to a point, nearest first
+func (s *GroupServiceImpl) GetNearestGroups(origin geo.Point, limit int) ([]models.Group, error) {
+	if err := origin.Validate(); err != nil {
+		return nil, err
+	}
+	return s.groupRepo.GetNearestGroups(origin, clampLimit(limit, geo.DefaultNearest))
+}
+
+// ClusterGroups clusters the groups inside a map viewport for a zoom level
+func (s *GroupServiceImpl) ClusterGroups(bounds geo.BoundingBox, zoom int) ([]geo.Cluster, error) {
+	if err := bounds.Validate(); err != nil {
+		return nil, err
+	}
+	if err := geo.ValidateZoom(zoom); err != nil {
+		return nil, err
+	}
+	return s.groupRepo.ClusterGroups(bounds, zoom)
+}
+
+// GetEventsByLocation retrieves upcoming events near a specific location, nearest first
+func (s *GroupServiceImpl) GetEventsByLocation(latitude, longitude float64, radiusKm float64) ([]models.LocalEvent, error) {
+	if err := validateRadiusSearch(latitude, longitude, radiusKm); err != nil {
+		return nil, err
+	}
+	return s.groupRepo.GetEventsByLocation(latitude, longitude, radiusKm)
+}
+
+// GetEventsInBounds retrieves the upcoming events inside a map viewport
+func (s *GroupServiceImpl) GetEventsInBounds(bounds geo.BoundingBox, limit int) ([]models.LocalEvent, error) {
+	if err := bounds.Validate(); err != nil {
+		return nil, err
+	}
+	return s.groupRepo.GetEventsInBounds(bounds, clampLimit(limit, geo.MaxResults))
+}
+
+// GetNearestEvents retrieves the upcoming events nearest to a point, nearest first
+func (s *GroupServiceImpl) GetNearestEvents(origin geo.Point, limit int) ([]models.LocalEvent, error) {
+	if err := origin.Validate(); err != nil {
+		return nil, err
+	}
+	return s.groupRepo.GetNearestEvents(origin, clampLimit(limit, geo.DefaultNearest))
+}
+
+// ClusterEvents clusters the upcoming events inside a map viewport for a zoom level
+func (s *GroupServiceImpl) ClusterEvents(bounds geo.BoundingBox, zoom int) ([]geo.Cluster, error) {
+	if err := bounds.Validate(); err != nil {
+		return nil, err
+	}
+	if err := geo.ValidateZoom(zoom); err != nil {
+		return nil, err
+	}
+	return s.groupRepo.ClusterEvents(bounds, zoom)
+}
@@ -1,13 +1,21 @@
 package service
 
 import (
+	"context"
 	"crypto/rand"
 	"encoding/base64"
 	"errors"
+	"fmt"
+	"log"
+	"strings"
 	"time"
 
+	"github.com/yerenwgventures/GreatNigeriaLibrary-Foundation/backend/pkg/common/mailer"
+	"github.com/yerenwgventures/GreatNigeriaLibrary-Foundation/backend/services/groups/geo"
 	"github.com/yerenwgventures/GreatNigeriaLibrary-Foundation/backend/services/groups/models"
 	"github.com/yerenwgventures/GreatNigeriaLibrary-Foundation/backend/services/groups/repository"
+	notificationmodels "github.com/yerenwgventures/GreatNigeriaLibrary-Foundation/backend/services/notification/models"
+	notification "github.com/yerenwgventures/GreatNigeriaLibrary-Foundation/backend/services/notification/service"
 )
 
 // GroupService defines the interface for group-related business logic
@@ -93,11 +101,38 @@ type GroupService interface {
 	ApproveJoinRequest(id uint, approvedByID uint) error
 	RejectJoinRequest(id uint, rejectedByID uint) error
 	CancelJoinRequest(id uint, userID uint) error
+
+	// Geospatial operations
+	GetGroupsInBounds(bounds geo.BoundingBox, limit int) ([]models.Group, error)
+	GetNearestGroups(origin geo.Point, limit int) ([]models.Group, error)
+	ClusterGroups(bounds geo.BoundingBox, zoom int) ([]geo.Cluster, error)
+	GetEventsByLocation(latitude, longitude float64, radiusKm float64) ([]models.LocalEvent, error)
+	GetEventsInBounds(bounds geo.BoundingBox, limit int) ([]models.LocalEvent, error)
+	GetNearestEvents(origin geo.Point, limit int) ([]models.LocalEvent, error)
+	ClusterEvents(bounds geo.BoundingBox, zoom int) ([]geo.Cluster, error)
+
+	// Calendar operations
+	GetEventCalendar(eventID uint) ([]byte, error)
+	CreateCalendarToken(userID uint) (string, error)
+	GetUserCalendar(token string) ([]byte, error)
+	GetGroupCalendar(groupID uint) ([]byte, error)
+
+	// Check-in operations
+	GetCheckInToken(eventID, userID uint) (*CheckInToken, error)
+	GetCheckInKey(eventID, organizerID uint) (*CheckInKey, error)
+	CheckInBatch(eventID, organizerID uint, checkIns []CheckIn) ([]CheckInResult, error)
+
+	// SetNotifier enables in-app notifications for invitations and waitlist promotions
+	SetNotifier(notifier notification.Notifier)
 }
 
 // GroupServiceImpl implements the GroupService interface
 type GroupServiceImpl struct {
-	groupRepo repository.GroupRepository
+	groupRepo      repository.GroupRepository
+	mailer         mailer.Mailer
+	emailTemplates *mailer.Templates
+	frontendURL    string
+	notifier       notification.Notifier
 }
 
 // NewGroupService creates a new group service
@@ -107,6 +142,22 @@ func NewGroupService(groupRepo repository.GroupRepository) GroupService {
 	}
 }
 
+// NewGroupServiceWithMailer creates a new group service that emails
+// invitations. Invitation links point at frontendURL.
+func NewGroupServiceWithMailer(groupRepo repository.GroupRepository, m mailer.Mailer, templates *mailer.Templates, frontendURL string) GroupService {
+	return &GroupServiceImpl{
+		groupRepo:      groupRepo,
+		mailer:         m,
+		emailTemplates: templates,
+		frontendURL:    strings.TrimRight(frontendURL, "/"),
+	}
+}
+
+// SetNotifier enables in-app notifications for invitations
+func (s *GroupServiceImpl) SetNotifier(notifier notification.Notifier) {
+	s.notifier = notifier
+}
+
 // CreateGroup creates a new group
 func (s *GroupServiceImpl) CreateGroup(group *models.Group) (*models.Group, error) {
 	// Validate group data
@@ -152,8 +203,11 @@ func (s *GroupServiceImpl) GetGroupsByUserID(userID uint, includeInvited bool) (
 	return s.groupRepo.GetGroupsByUserID(userID, includeInvited)
 }
 
-// GetGroupsByLocation retrieves groups near a specific location
+// GetGroupsByLocation retrieves groups near a specific location, nearest first
 func (s *GroupServiceImpl) GetGroupsByLocation(latitude, longitude float64, radiusKm float64) ([]models.Group, error) {
+	if err := validateRadiusSearch(latitude, longitude, radiusKm); err != nil {
+		return nil, err
+	}
 	return s.groupRepo.GetGroupsByLocation(latitude, longitude, radiusKm)
 }
 
@@ -430,6 +484,10 @@ func (s *GroupServiceImpl) UpdateEvent(id uint, event *models.LocalEvent, userID
 		}
 	}
 
+	// Calendar apps only replace their copy of an event when its sequence grows
+	wasCancelled := existingEvent.Status == models.CancelledEvent
+	changed := rescheduled(existingEvent, event)
+
 	// Update only allowed fields
 	existingEvent.Title = event.Title
 	existingEvent.Description = event.Description
@@ -442,12 +500,27 @@ func (s *GroupServiceImpl) UpdateEvent(id uint, event *models.LocalEvent, userID
 	existingEvent.EndTime = event.EndTime
 	existingEvent.Status = event.Status
 	existingEvent.MaxAttendees = event.MaxAttendees
+	if changed {
+		existingEvent.Sequence++
+	}
 
 	err = s.groupRepo.UpdateEvent(existingEvent)
 	if err != nil {
 		return nil, err
 	}
 
+	// Update the calendars of attendees, unless the event stays cancelled
+	if changed && !(wasCancelled && existingEvent.Status == models.CancelledEvent) {
+		kind := inviteUpdate
+		if existingEvent.Status == models.CancelledEvent {
+			kind = inviteCancel
+		}
+		s.sendEventInvites(existingEvent, kind)
+	}
+
+	// Raising the attendee limit may make room for the waitlist
+	s.promoteWaitlist(existingEvent)
+
 	return existingEvent, nil
 }
 
@@ -467,6 +540,13 @@ func (s *GroupServiceImpl) DeleteEvent(id uint, userID uint) error {
 		}
 	}
 
+	// Remove the event from the calendars of attendees
+	if event.Status != models.CancelledEvent {
+		event.Sequence++
+		event.Status = models.CancelledEvent
+		s.sendEventInvites(event, inviteCancel)
+	}
+
 	return s.groupRepo.DeleteEvent(id)
 }
 
@@ -490,30 +570,35 @@ func (s *GroupServiceImpl) AddAttendee(eventID, userID uint, status string) (*mo
 		return nil, errors.New("user is already an attendee of this event")
 	}
 
-	// Check if the event has reached its maximum attendees
-	if event.MaxAttendees > 0 && status == "going" {
-		attendees, _, err := s.groupRepo.GetAttendeesByEventID(eventID, "going", 1, 1000)
-		if err != nil {
-			return nil, err
-		}
-
-		if len(attendees) >= event.MaxAttendees {
-			return nil, errors.New("event has reached its maximum number of attendees")
-		}
-	}
-
-	// Create the attendee
+	// Create the attendee. Attendees going to a full event join its
+	// waitlist; the places are counted under the event's lock, so concurrent
+	// RSVPs cannot take the same place.
 	attendee := &models.EventAttendee{
 		EventID:  eventID,
 		UserID:   userID,
 		Status:   status,
 		RSVPTime: time.Now(),
 	}
-
-	err = s.groupRepo.AddAttendee(attendee)
+	err = s.groupRepo.LockEvent(eventID, func(repo repository.GroupRepository, locked *models.LocalEvent) error {
+		if attendee.Status == "going" {
+			full, err := eventFull(repo, locked)
+			if err != nil {
+				return err
+			}
+			if full {
+				attendee.Status = "waitlisted"
+			}
+		}
+		return repo.AddAttendee(attendee)
+	})
 	if err != nil {
 		return nil, err
 	}
+	status = attendee.Status
+
+	if attending(status) && event.Status != models.CancelledEvent {
+		s.sendEventInvite(event, attendee, inviteRSVP)
+	}
 
 	return attendee, nil
 }
@@ -536,51 +621,82 @@ func (s *GroupServiceImpl) UpdateAttendee(eventID, userID uint, status string) (
 		return nil, err
 	}
 
-	// Check if the attendee exists
-	attendee, err := s.groupRepo.GetAttendeeByID(eventID, userID)
-	if err != nil {
-		return nil, err
-	}
-
-	// Check if the event has reached its maximum attendees
-	if event.MaxAttendees > 0 && status == "going" && attendee.Status != "going" {
-		attendees, _, err := s.groupRepo.GetAttendeesByEventID(eventID, "going", 1, 1000)
+	// Update the attendee under the event's lock, so concurrent RSVPs and
+	// waitlist promotions cannot take the same place
+	var attendee *models.EventAttendee
+	var previousStatus string
+	err = s.groupRepo.LockEvent(eventID, func(repo repository.GroupRepository, locked *models.LocalEvent) error {
+		// Check if the attendee exists
+		var err error
+		attendee, err = repo.GetAttendeeByID(eventID, userID)
 		if err != nil {
-			return nil, err
+			return err
 		}
+		previousStatus = attendee.Status
 
-		if len(attendees) >= event.MaxAttendees {
-			return nil, errors.New("event has reached its maximum number of attendees")
+		// Attendees going to a full event join its waitlist
+		if status == "going" && previousStatus != "going" {
+			full, err := eventFull(repo, locked)
+			if err != nil {
+				return err
+			}
+			if full {
+				status = "waitlisted"
+			}
 		}
-	}
 
-	// Update the attendee
-	attendee.Status = status
-	attendee.RSVPTime = time.Now()
-
-	err = s.groupRepo.UpdateAttendee(attendee)
+		// Staying on the waitlist keeps its place
+		attendee.Status = status
+		if status != previousStatus {
+			attendee.RSVPTime = time.Now()
+		}
+		return repo.UpdateAttendee(attendee)
+	})
 	if err != nil {
 		return nil, err
 	}
 
+	if status != previousStatus && event.Status != models.CancelledEvent {
+		switch {
+		case attending(status):
+			s.sendEventInvite(event, attendee, inviteRSVP)
+		case attending(previousStatus):
+			s.sendEventInvite(event, attendee, inviteWithdraw)
+		}
+	}
+
+	if previousStatus == "going" && status != "going" {
+		s.promoteWaitlist(event)
+	}
+
 	return attendee, nil
 }
 
 // RemoveAttendee removes an attendee from an event
 func (s *GroupServiceImpl) RemoveAttendee(eventID, userID uint) error {
 	// Check if the event exists
-	_, err := s.groupRepo.GetEventByID(eventID)
+	event, err := s.groupRepo.GetEventByID(eventID)
 	if err != nil {
 		return err
 	}
 
 	// Check if the attendee exists
-	_, err = s.groupRepo.GetAttendeeByID(eventID, userID)
+	attendee, err := s.groupRepo.GetAttendeeByID(eventID, userID)
 	if err != nil {
 		return err
 	}
 
-	return s.groupRepo.RemoveAttendee(eventID, userID)
+	if err := s.groupRepo.RemoveAttendee(eventID, userID); err != nil {
+		return err
+	}
+
+	if attending(attendee.Status) && event.Status != models.CancelledEvent {
+		s.sendEventInvite(event, attendee, inviteWithdraw)
+	}
+	if attendee.Status == "going" {
+		s.promoteWaitlist(event)
+	}
+	return nil
 }
 
 // CheckInAttendee checks in an attendee to an event
@@ -598,17 +714,15 @@ func (s *GroupServiceImpl) CheckInAttendee(eventID, userID, checkedInByID uint)
 	}
 
 	// Check if the user checking in attendees is the event creator or a group admin/owner
-	if checkedInByID != event.CreatedByID {
-		member, err := s.groupRepo.GetMemberByID(event.GroupID, checkedInByID)
-		if err != nil || (member.Role != models.OwnerRole && member.Role != models.AdminRole && member.Role != models.ModeratorRole) {
-			return nil, errors.New("unauthorized: only the event creator or group admins/moderators can check in attendees")
-		}
+	if err := s.authorizeCheckIn(event, checkedInByID); err != nil {
+		return nil, err
 	}
 
 	// Update the attendee
 	now := time.Now()
 	attendee.CheckedIn = true
 	attendee.CheckInTime = &now
+	attendee.CheckedInByID = &checkedInByID
 
 	err = s.groupRepo.UpdateAttendee(attendee)
 	if err != nil {
@@ -1170,9 +1284,50 @@ func (s *GroupServiceImpl) CreateInvitation(groupID uint, email string, userID *
 		return nil, err
 	}
 
+	// The invitation stays valid in the app if the email cannot be sent
+	if err := s.sendInvitationEmail(group, invitation); err != nil {
+		log.Printf("Error sending invitation %d for group %d: %v", invitation.ID, groupID, err)
+	}
+	if err := s.notifyInvitation(group, invitation); err != nil {
+		log.Printf("Error notifying invitation %d for group %d: %v", invitation.ID, groupID, err)
+	}
+
 	return invitation, nil
 }
 
+// sendInvitationEmail emails an invitation to its recipient
+func (s *GroupServiceImpl) sendInvitationEmail(group *models.Group, invitation *models.GroupInvitation) error {
+	if s.mailer == nil || s.emailTemplates == nil || invitation.Email == "" {
+		return nil
+	}
+
+	return mailer.SendTemplate(context.Background(), s.mailer, s.emailTemplates, invitation.Email, mailer.TemplateGroupInvitation, mailer.GroupInvitationData{
+		GroupName: group.Name,
+		Message:   invitation.Message,
+		AcceptURL: fmt.Sprintf("%s/groups/invitations/%s", s.frontendURL, invitation.Code),
+		ExpiresAt: invitation.ExpiresAt,
+	})
+}
+
+// notifyInvitation sends an in-app notification to an invited existing user
+func (s *GroupServiceImpl) notifyInvitation(group *models.Group, invitation *models.GroupInvitation) error {
+	if s.notifier == nil || invitation.UserID == nil {
+		return nil
+	}
+
+	n := &notificationmodels.Notification{
+		UserID:        *invitation.UserID,
+		Type:          notificationmodels.TypeGroupInvitation,
+		Title:         fmt.Sprintf("You have been invited to join %s", group.Name),
+		Body:          invitation.Message,
+		Link:          fmt.Sprintf("/groups/invitations/%s", invitation.Code),
+		ActorID:       &invitation.InvitedByID,
+		ReferenceType: "group_invitation",
+		ReferenceID:   invitation.ID,
+	}
+	return s.notifier.Notify(n)
+}
+
 // GetInvitationByID retrieves an invitation by its ID
 func (s *GroupServiceImpl) GetInvitationByID(id uint) (*models.GroupInvitation, error) {
 	return s.groupRepo.GetInvitationByID(id)
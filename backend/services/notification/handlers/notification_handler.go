@@ -0,0 +1,256 @@
+package handlers
+
+import (
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/yerenwgventures/GreatNigeriaLibrary-Foundation/backend/pkg/common/middleware"
+	"github.com/yerenwgventures/GreatNigeriaLibrary-Foundation/backend/services/notification/service"
+)
+
+// heartbeatInterval keeps idle streams open through proxies
+const heartbeatInterval = 25 * time.Second
+
+// streamPath is the path of the notification stream
+const streamPath = "/notifications/stream"
+
+// NotificationHandler defines the handler for notification endpoints
+type NotificationHandler struct {
+	notificationService service.NotificationService
+	streamTokens        *service.StreamTokens
+}
+
+// NewNotificationHandler creates a new notification handler
+func NewNotificationHandler(notificationService service.NotificationService, streamTokens *service.StreamTokens) *NotificationHandler {
+	return &NotificationHandler{
+		notificationService: notificationService,
+		streamTokens:        streamTokens,
+	}
+}
+
+// RegisterRoutes registers the routes for the notification center. They
+// must be behind authentication.
+func (h *NotificationHandler) RegisterRoutes(router *gin.RouterGroup) {
+	notifications := router.Group("/notifications")
+	{
+		notifications.GET("", h.GetNotifications)
+		notifications.GET("/unread-count", h.GetUnreadCount)
+		notifications.POST("/stream-token", h.IssueStreamToken)
+		notifications.POST("/read", h.MarkRead)
+		notifications.POST("/read-all", h.MarkAllRead)
+		notifications.POST("/:id/read", h.MarkOneRead)
+	}
+}
+
+// GetNotifications handles listing the current user's notifications
+func (h *NotificationHandler) GetNotifications(c *gin.Context) {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	pageSize, _ := strconv.Atoi(c.DefaultQuery("page_size", "20"))
+	unreadOnly := c.Query("unread") == "true"
+
+	notifications, total, err := h.notificationService.GetNotifications(userID, unreadOnly, page, pageSize)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get notifications"})
+		return
+	}
+
+	unread, err := h.notificationService.GetUnreadCount(userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get unread count"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"notifications": notifications,
+		"total":         total,
+		"unreadCount":   unread,
+		"page":          page,
+		"pageSize":      pageSize,
+	})
+}
+
+// GetUnreadCount handles getting the current user's unread count
+func (h *NotificationHandler) GetUnreadCount(c *gin.Context) {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	unread, err := h.notificationService.GetUnreadCount(userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get unread count"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"unreadCount": unread})
+}
+
+// MarkReadRequest represents a request to mark notifications as read
+type MarkReadRequest struct {
+	IDs []uint `json:"ids" binding:"required"`
+}
+
+// MarkRead handles marking several notifications as read
+func (h *NotificationHandler) MarkRead(c *gin.Context) {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	var req MarkReadRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	h.markRead(c, userID, req.IDs)
+}
+
+// MarkOneRead handles marking a single notification as read
+func (h *NotificationHandler) MarkOneRead(c *gin.Context) {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid notification ID"})
+		return
+	}
+
+	h.markRead(c, userID, []uint{uint(id)})
+}
+
+func (h *NotificationHandler) markRead(c *gin.Context, userID uint, ids []uint) {
+	updated, err := h.notificationService.MarkRead(userID, ids)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to mark notifications as read"})
+		return
+	}
+
+	unread, err := h.notificationService.GetUnreadCount(userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get unread count"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"updated": updated, "unreadCount": unread})
+}
+
+// MarkAllRead handles marking all of the current user's notifications as read
+func (h *NotificationHandler) MarkAllRead(c *gin.Context) {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	updated, err := h.notificationService.MarkAllRead(userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to mark notifications as read"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"updated": updated, "unreadCount": 0})
+}
+
+// RegisterStreamRoutes registers the notification stream. EventSource
+// cannot send an Authorization header, so the stream authenticates with a
+// stream token instead and must not be behind authentication.
+func (h *NotificationHandler) RegisterStreamRoutes(router *gin.RouterGroup) {
+	router.GET(streamPath, h.Stream)
+}
+
+// IssueStreamToken handles exchanging the current user's access token for a
+// short-lived stream token. The token is returned for use as the stream's
+// token query parameter and also set in an HTTP-only cookie, which is only
+// sent with requests for the stream.
+func (h *NotificationHandler) IssueStreamToken(c *gin.Context) {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	token, expiresAt := h.streamTokens.Issue(userID)
+
+	secure := c.Request.TLS != nil || c.GetHeader("X-Forwarded-Proto") == "https"
+	c.SetSameSite(http.SameSiteStrictMode)
+	c.SetCookie(service.StreamTokenCookie, token, int(h.streamTokens.TTL().Seconds()), clientPath(c, streamPath), "", secure, true)
+
+	c.JSON(http.StatusOK, gin.H{"token": token, "expiresAt": expiresAt})
+}
+
+// clientPath returns path as the client sees it. The API gateway forwards the
+// part of the path it stripped in X-Forwarded-Prefix.
+func clientPath(c *gin.Context, path string) string {
+	prefix := strings.TrimRight(c.GetHeader("X-Forwarded-Prefix"), "/")
+	if !strings.HasPrefix(prefix, "/") || strings.ContainsAny(prefix, ";,") {
+		return path
+	}
+	return prefix + path
+}
+
+// Stream handles the Server-Sent Events stream of a user's notifications,
+// authenticated by a stream token in the token query parameter or cookie.
+// The first event carries the unread count so clients can render the badge
+// without a separate request.
+func (h *NotificationHandler) Stream(c *gin.Context) {
+	token := c.Query("token")
+	if token == "" {
+		token, _ = c.Cookie(service.StreamTokenCookie)
+	}
+	userID, err := h.streamTokens.Verify(token)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	events, unsubscribe := h.notificationService.Subscribe(userID)
+	defer unsubscribe()
+
+	unread, err := h.notificationService.GetUnreadCount(userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get unread count"})
+		return
+	}
+
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no")
+	c.SSEvent(service.EventUnreadCount, service.Event{Type: service.EventUnreadCount, UnreadCount: unread})
+	c.Writer.Flush()
+
+	heartbeat := time.NewTicker(heartbeatInterval)
+	defer heartbeat.Stop()
+
+	c.Stream(func(w io.Writer) bool {
+		select {
+		case <-c.Request.Context().Done():
+			return false
+		case event, ok := <-events:
+			if !ok {
+				return false
+			}
+			c.SSEvent(event.Type, event)
+			return true
+		case <-heartbeat.C:
+			c.SSEvent("heartbeat", gin.H{"time": time.Now().Unix()})
+			return true
+		}
+	})
+}
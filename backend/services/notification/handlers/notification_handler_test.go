@@ -0,0 +1,151 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/yerenwgventures/GreatNigeriaLibrary-Foundation/backend/services/notification/models"
+	"github.com/yerenwgventures/GreatNigeriaLibrary-Foundation/backend/services/notification/service"
+)
+
+type fakeNotificationService struct {
+	broker *service.LocalBroker
+}
+
+func (s *fakeNotificationService) Notify(notification *models.Notification) error { return nil }
+
+func (s *fakeNotificationService) NotifySubscribers(scopeType string, scopeID uint, exclude []uint, notification models.Notification) (int, error) {
+	return 0, nil
+}
+
+func (s *fakeNotificationService) GetNotifications(userID uint, unreadOnly bool, page, pageSize int) ([]models.Notification, int64, error) {
+	return nil, 0, nil
+}
+
+func (s *fakeNotificationService) GetUnreadCount(userID uint) (int64, error) { return 3, nil }
+
+func (s *fakeNotificationService) MarkRead(userID uint, ids []uint) (int64, error) { return 0, nil }
+
+func (s *fakeNotificationService) MarkAllRead(userID uint) (int64, error) { return 0, nil }
+
+func (s *fakeNotificationService) Subscribe(userID uint) (<-chan service.Event, func()) {
+	return s.broker.Subscribe(userID)
+}
+
+// newTestRouter registers the notification routes, authenticating the
+// regular routes as user 42 the way AuthRequired would
+func newTestRouter() *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	handler := NewNotificationHandler(&fakeNotificationService{broker: service.NewLocalBroker()}, service.NewStreamTokens("secret", time.Minute))
+
+	router := gin.New()
+	handler.RegisterStreamRoutes(router.Group("/"))
+	handler.RegisterRoutes(router.Group("/", func(c *gin.Context) {
+		if c.GetHeader("Authorization") == "" {
+			c.AbortWithStatus(http.StatusUnauthorized)
+			return
+		}
+		c.Set("user_id", uint(42))
+	}))
+	return router
+}
+
+// streamRecorder adds the CloseNotifier gin's Stream requires
+type streamRecorder struct {
+	*httptest.ResponseRecorder
+}
+
+func (r streamRecorder) CloseNotify() <-chan bool {
+	return make(chan bool)
+}
+
+// openStream requests the stream and closes it shortly after it opens
+func openStream(t *testing.T, router *gin.Engine, target string, cookie *http.Cookie) *httptest.ResponseRecorder {
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	req := httptest.NewRequest(http.MethodGet, target, nil).WithContext(ctx)
+	if cookie != nil {
+		req.AddCookie(cookie)
+	}
+	w := httptest.NewRecorder()
+	router.ServeHTTP(streamRecorder{w}, req)
+	return w
+}
+
+func issueStreamToken(t *testing.T, router *gin.Engine) (string, *http.Cookie) {
+	req := httptest.NewRequest(http.MethodPost, "/notifications/stream-token", nil)
+	req.Header.Set("Authorization", "Bearer access-token")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var body struct {
+		Token string `json:"token"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+	require.NotEmpty(t, body.Token)
+
+	cookies := w.Result().Cookies()
+	require.Len(t, cookies, 1)
+	assert.Equal(t, service.StreamTokenCookie, cookies[0].Name)
+	assert.Equal(t, body.Token, cookies[0].Value)
+	assert.True(t, cookies[0].HttpOnly)
+	assert.Equal(t, "/notifications/stream", cookies[0].Path, "the cookie is only sent with requests for the stream")
+	return body.Token, cookies[0]
+}
+
+func TestStreamTokenCookieFollowsTheGatewayPrefix(t *testing.T) {
+	router := newTestRouter()
+
+	req := httptest.NewRequest(http.MethodPost, "/notifications/stream-token", nil)
+	req.Header.Set("Authorization", "Bearer access-token")
+	req.Header.Set("X-Forwarded-Prefix", "/api/v1")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	cookies := w.Result().Cookies()
+	require.Len(t, cookies, 1)
+	assert.Equal(t, "/api/v1/notifications/stream", cookies[0].Path)
+}
+
+func TestStreamTokenRequiresAuthentication(t *testing.T) {
+	router := newTestRouter()
+
+	req := httptest.NewRequest(http.MethodPost, "/notifications/stream-token", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+}
+
+func TestStreamRejectsMissingAndInvalidTokens(t *testing.T) {
+	router := newTestRouter()
+
+	assert.Equal(t, http.StatusUnauthorized, openStream(t, router, "/notifications/stream", nil).Code)
+	assert.Equal(t, http.StatusUnauthorized, openStream(t, router, "/notifications/stream?token=42.9999999999.forged", nil).Code)
+
+	forged := &http.Cookie{Name: service.StreamTokenCookie, Value: "42.9999999999.forged"}
+	assert.Equal(t, http.StatusUnauthorized, openStream(t, router, "/notifications/stream", forged).Code)
+}
+
+func TestStreamAcceptsTokenFromQueryOrCookie(t *testing.T) {
+	router := newTestRouter()
+	token, cookie := issueStreamToken(t, router)
+
+	for name, w := range map[string]*httptest.ResponseRecorder{
+		"query":  openStream(t, router, "/notifications/stream?token="+token, nil),
+		"cookie": openStream(t, router, "/notifications/stream", cookie),
+	} {
+		assert.Equal(t, http.StatusOK, w.Code, name)
+		assert.Contains(t, w.Body.String(), "event:"+service.EventUnreadCount, name)
+		assert.Contains(t, w.Body.String(), `"unreadCount":3`, name)
+	}
+}
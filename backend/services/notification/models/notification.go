@@ -0,0 +1,73 @@
+package models
+
+import (
+	"time"
+)
+
+// NotificationType identifies what a notification is about
+type NotificationType string
+
+const (
+	// TypeMention is sent when a user is @mentioned in a topic or comment
+	TypeMention NotificationType = "mention"
+
+	// TypeReply is sent when someone replies to a user's topic or comment
+	TypeReply NotificationType = "reply"
+
+	// TypeSubscriptionUpdate is sent for new activity on an instant subscription
+	TypeSubscriptionUpdate NotificationType = "subscription_update"
+
+	// TypeModeration is sent to an author when moderators act on their content
+	TypeModeration NotificationType = "moderation"
+
+	// TypeReportResolved is sent to a reporter when their report is resolved
+	TypeReportResolved NotificationType = "report_resolved"
+
+	// TypeGroupInvitation is sent when a user is invited to a group
+	TypeGroupInvitation NotificationType = "group_invitation"
+
+	// TypeEventWaitlist is sent when a user is promoted from an event waitlist
+	TypeEventWaitlist NotificationType = "event_waitlist"
+)
+
+// Notification is an in-app notification for a single user. The table is
+// created by the 0021_create_notifications migration.
+type Notification struct {
+	ID            uint             `json:"id" gorm:"primarykey"`
+	UserID        uint             `json:"userId" gorm:"not null;index:idx_notifications_user_unread,priority:1;uniqueIndex:idx_notifications_dedup,priority:1"`
+	Type          NotificationType `json:"type" gorm:"size:32;not null"`
+	Title         string           `json:"title" gorm:"size:255"`
+	Body          string           `json:"body" gorm:"type:text"`
+	Link          string           `json:"link" gorm:"size:512"`
+	ActorID       *uint            `json:"actorId,omitempty"`
+	ReferenceType string           `json:"referenceType,omitempty" gorm:"size:32"` // topic, comment, report, group_invitation, ...
+	ReferenceID   uint             `json:"referenceId,omitempty"`
+	ReadAt        *time.Time       `json:"readAt" gorm:"index:idx_notifications_user_unread,priority:2"`
+	CreatedAt     time.Time        `json:"createdAt" gorm:"index"`
+
+	// DedupKey makes delivery idempotent: a user never gets two notifications
+	// with the same key, e.g. when a comment with a mention is edited
+	DedupKey *string `json:"-" gorm:"size:128;uniqueIndex:idx_notifications_dedup,priority:2"`
+
+	// ScopeType and ScopeID name the subscription (topic, category or tag) whose
+	// InAppNotification setting decides whether the notification is delivered.
+	// Without a scope the user's default in-app preference applies.
+	ScopeType string `json:"-" gorm:"-"`
+	ScopeID   uint   `json:"-" gorm:"-"`
+}
+
+// TableName specifies the table name for Notification
+func (Notification) TableName() string {
+	return "notifications"
+}
+
+// IsRead reports whether the notification has been read
+func (n *Notification) IsRead() bool {
+	return n.ReadAt != nil
+}
+
+// WithDedupKey sets the deduplication key and returns the notification
+func (n *Notification) WithDedupKey(key string) *Notification {
+	n.DedupKey = &key
+	return n
+}
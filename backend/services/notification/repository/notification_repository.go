@@ -0,0 +1,140 @@
+package repository
+
+import (
+	"errors"
+	"time"
+
+	"github.com/yerenwgventures/GreatNigeriaLibrary-Foundation/backend/services/notification/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// NotificationRepository defines the interface for notification data operations
+type NotificationRepository interface {
+	Create(notification *models.Notification) (bool, error)
+	GetByUser(userID uint, unreadOnly bool, limit, offset int) ([]models.Notification, int64, error)
+	CountUnread(userID uint) (int64, error)
+	MarkRead(userID uint, ids []uint, readAt time.Time) (int64, error)
+	MarkAllRead(userID uint, readAt time.Time) (int64, error)
+
+	// Preferences are read from the discussion subscription tables
+	InAppEnabled(userID uint, scopeType string, scopeID uint) (bool, error)
+	GetInstantSubscribers(scopeType string, scopeID uint, now time.Time) ([]uint, error)
+}
+
+// GormNotificationRepository implements the NotificationRepository interface
+type GormNotificationRepository struct {
+	db *gorm.DB
+}
+
+// NewGormNotificationRepository creates a new notification repository
+func NewGormNotificationRepository(db *gorm.DB) *GormNotificationRepository {
+	return &GormNotificationRepository{db: db}
+}
+
+// Create stores a notification. It returns false if the user already has a
+// notification with the same dedup key.
+func (r *GormNotificationRepository) Create(notification *models.Notification) (bool, error) {
+	result := r.db.Clauses(clause.OnConflict{DoNothing: true}).Create(notification)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// GetByUser retrieves a page of a user's notifications, newest first
+func (r *GormNotificationRepository) GetByUser(userID uint, unreadOnly bool, limit, offset int) ([]models.Notification, int64, error) {
+	query := r.db.Model(&models.Notification{}).Where("user_id = ?", userID)
+	if unreadOnly {
+		query = query.Where("read_at IS NULL")
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var notifications []models.Notification
+	err := query.Order("created_at DESC, id DESC").Limit(limit).Offset(offset).Find(&notifications).Error
+	return notifications, total, err
+}
+
+// CountUnread counts a user's unread notifications
+func (r *GormNotificationRepository) CountUnread(userID uint) (int64, error) {
+	var count int64
+	err := r.db.Model(&models.Notification{}).
+		Where("user_id = ? AND read_at IS NULL", userID).
+		Count(&count).Error
+	return count, err
+}
+
+// MarkRead marks some of a user's notifications as read
+func (r *GormNotificationRepository) MarkRead(userID uint, ids []uint, readAt time.Time) (int64, error) {
+	if len(ids) == 0 {
+		return 0, nil
+	}
+	result := r.db.Model(&models.Notification{}).
+		Where("user_id = ? AND id IN ? AND read_at IS NULL", userID, ids).
+		Update("read_at", readAt)
+	return result.RowsAffected, result.Error
+}
+
+// MarkAllRead marks all of a user's notifications as read
+func (r *GormNotificationRepository) MarkAllRead(userID uint, readAt time.Time) (int64, error) {
+	result := r.db.Model(&models.Notification{}).
+		Where("user_id = ? AND read_at IS NULL", userID).
+		Update("read_at", readAt)
+	return result.RowsAffected, result.Error
+}
+
+// InAppEnabled reports whether a user wants in-app notifications for a scope.
+// A matching subscription decides through its InAppNotification and Muted
+// settings; otherwise the user's default in-app preference applies, which is
+// on when the user has never saved preferences.
+func (r *GormNotificationRepository) InAppEnabled(userID uint, scopeType string, scopeID uint) (bool, error) {
+	if scopeType != "" {
+		var subscription struct {
+			InAppNotification bool
+			Muted             bool
+		}
+		err := r.db.Table("advanced_subscriptions").
+			Select("in_app_notification, muted").
+			Where("user_id = ? AND type = ? AND reference_id = ? AND deleted_at IS NULL", userID, scopeType, scopeID).
+			Order("id DESC").
+			Take(&subscription).Error
+		if err == nil {
+			return subscription.InAppNotification && !subscription.Muted, nil
+		}
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return false, err
+		}
+	}
+
+	var preference struct {
+		DefaultInAppEnabled bool
+	}
+	err := r.db.Table("subscription_preferences").
+		Select("default_in_app_enabled").
+		Where("user_id = ? AND deleted_at IS NULL", userID).
+		Take(&preference).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return true, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return preference.DefaultInAppEnabled, nil
+}
+
+// GetInstantSubscribers retrieves the users with an active, unmuted, instant
+// in-app subscription to a topic, category or tag
+func (r *GormNotificationRepository) GetInstantSubscribers(scopeType string, scopeID uint, now time.Time) ([]uint, error) {
+	var userIDs []uint
+	err := r.db.Table("advanced_subscriptions").
+		Distinct("user_id").
+		Where("type = ? AND reference_id = ? AND frequency = ?", scopeType, scopeID, "instant").
+		Where("in_app_notification = ? AND muted = ? AND deleted_at IS NULL", true, false).
+		Where("expires_at IS NULL OR expires_at > ?", now).
+		Pluck("user_id", &userIDs).Error
+	return userIDs, err
+}
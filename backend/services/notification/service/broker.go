@@ -0,0 +1,168 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"sync"
+
+	"github.com/yerenwgventures/GreatNigeriaLibrary-Foundation/backend/pkg/common/redis"
+	"github.com/yerenwgventures/GreatNigeriaLibrary-Foundation/backend/services/notification/models"
+)
+
+// Event types sent to connected clients
+const (
+	EventNotification = "notification"
+	EventUnreadCount  = "unread_count"
+)
+
+// DefaultRedisChannel is the pub/sub channel notification events are fanned out on
+const DefaultRedisChannel = "notifications:events"
+
+// subscriberBuffer is the number of events buffered per connection. Events for
+// a connection that falls further behind are dropped; the unread count in the
+// next event lets the client catch up.
+const subscriberBuffer = 32
+
+// Event is a real-time update for one user
+type Event struct {
+	Type         string               `json:"type"`
+	Notification *models.Notification `json:"notification,omitempty"`
+	UnreadCount  int64                `json:"unreadCount"`
+}
+
+// Broker delivers events to the connections of a user
+type Broker interface {
+	Publish(ctx context.Context, userID uint, event Event) error
+	Subscribe(userID uint) (<-chan Event, func())
+}
+
+// LocalBroker delivers events to connections held by this process
+type LocalBroker struct {
+	mu          sync.RWMutex
+	subscribers map[uint]map[chan Event]struct{}
+}
+
+// NewLocalBroker creates a new in-process broker
+func NewLocalBroker() *LocalBroker {
+	return &LocalBroker{
+		subscribers: make(map[uint]map[chan Event]struct{}),
+	}
+}
+
+// Publish sends an event to every connection of the user without blocking
+func (b *LocalBroker) Publish(ctx context.Context, userID uint, event Event) error {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	for ch := range b.subscribers[userID] {
+		select {
+		case ch <- event:
+		default:
+		}
+	}
+	return nil
+}
+
+// Subscribe registers a connection for a user. The returned function must be
+// called when the connection closes.
+func (b *LocalBroker) Subscribe(userID uint) (<-chan Event, func()) {
+	ch := make(chan Event, subscriberBuffer)
+
+	b.mu.Lock()
+	if b.subscribers[userID] == nil {
+		b.subscribers[userID] = make(map[chan Event]struct{})
+	}
+	b.subscribers[userID][ch] = struct{}{}
+	b.mu.Unlock()
+
+	var once sync.Once
+	return ch, func() {
+		once.Do(func() {
+			b.mu.Lock()
+			delete(b.subscribers[userID], ch)
+			if len(b.subscribers[userID]) == 0 {
+				delete(b.subscribers, userID)
+			}
+			b.mu.Unlock()
+			close(ch)
+		})
+	}
+}
+
+// Connections returns the number of open connections for a user
+func (b *LocalBroker) Connections(userID uint) int {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	return len(b.subscribers[userID])
+}
+
+// redisEnvelope is the pub/sub message carrying an event
+type redisEnvelope struct {
+	UserID uint  `json:"userId"`
+	Event  Event `json:"event"`
+}
+
+// RedisBroker fans events out through Redis pub/sub so a user connected to any
+// instance receives them. Each instance delivers to its own connections
+// through a LocalBroker.
+type RedisBroker struct {
+	client  *redis.Client
+	channel string
+	local   *LocalBroker
+}
+
+// NewRedisBroker creates a new Redis-backed broker. Run must be started for
+// events to reach local connections.
+func NewRedisBroker(client *redis.Client, channel string) *RedisBroker {
+	if channel == "" {
+		channel = DefaultRedisChannel
+	}
+	return &RedisBroker{
+		client:  client,
+		channel: channel,
+		local:   NewLocalBroker(),
+	}
+}
+
+// Publish sends an event to every instance
+func (b *RedisBroker) Publish(ctx context.Context, userID uint, event Event) error {
+	payload, err := json.Marshal(redisEnvelope{UserID: userID, Event: event})
+	if err != nil {
+		return fmt.Errorf("error encoding notification event: %w", err)
+	}
+	if err := b.client.Publish(ctx, b.channel, payload).Err(); err != nil {
+		return fmt.Errorf("error publishing notification event: %w", err)
+	}
+	return nil
+}
+
+// Subscribe registers a local connection for a user
+func (b *RedisBroker) Subscribe(userID uint) (<-chan Event, func()) {
+	return b.local.Subscribe(userID)
+}
+
+// Run relays events from Redis to local connections until ctx is cancelled
+func (b *RedisBroker) Run(ctx context.Context) {
+	pubsub := b.client.Subscribe(ctx, b.channel)
+	defer pubsub.Close()
+
+	messages := pubsub.Channel()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case msg, ok := <-messages:
+			if !ok {
+				return
+			}
+			var envelope redisEnvelope
+			if err := json.Unmarshal([]byte(msg.Payload), &envelope); err != nil {
+				log.Printf("Error decoding notification event: %v", err)
+				continue
+			}
+			b.local.Publish(ctx, envelope.UserID, envelope.Event)
+		}
+	}
+}
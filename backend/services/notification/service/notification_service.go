@@ -0,0 +1,196 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/yerenwgventures/GreatNigeriaLibrary-Foundation/backend/services/notification/models"
+	"github.com/yerenwgventures/GreatNigeriaLibrary-Foundation/backend/services/notification/repository"
+)
+
+// Notifier is used by other services to send in-app notifications
+type Notifier interface {
+	// Notify delivers a notification if the user's preferences allow it
+	Notify(notification *models.Notification) error
+
+	// NotifySubscribers sends a copy of the notification to every user with an
+	// instant in-app subscription to the scope, except the excluded users, and
+	// returns the number delivered
+	NotifySubscribers(scopeType string, scopeID uint, exclude []uint, notification models.Notification) (int, error)
+}
+
+// NotificationService defines the interface for notification operations
+type NotificationService interface {
+	Notifier
+
+	GetNotifications(userID uint, unreadOnly bool, page, pageSize int) ([]models.Notification, int64, error)
+	GetUnreadCount(userID uint) (int64, error)
+	MarkRead(userID uint, ids []uint) (int64, error)
+	MarkAllRead(userID uint) (int64, error)
+
+	// Subscribe streams real-time events for a user until the returned function is called
+	Subscribe(userID uint) (<-chan Event, func())
+}
+
+// alwaysDelivered lists notification types that ignore in-app preferences
+var alwaysDelivered = map[models.NotificationType]bool{
+	models.TypeModeration:      true,
+	models.TypeReportResolved:  true,
+	models.TypeGroupInvitation: true,
+	models.TypeEventWaitlist:   true,
+}
+
+// NotificationServiceImpl implements the NotificationService interface
+type NotificationServiceImpl struct {
+	notificationRepo repository.NotificationRepository
+	broker           Broker
+}
+
+// NewNotificationService creates a new notification service
+func NewNotificationService(notificationRepo repository.NotificationRepository, broker Broker) NotificationService {
+	if broker == nil {
+		broker = NewLocalBroker()
+	}
+	return &NotificationServiceImpl{
+		notificationRepo: notificationRepo,
+		broker:           broker,
+	}
+}
+
+// Notify delivers a notification if the user's preferences allow it
+func (s *NotificationServiceImpl) Notify(notification *models.Notification) error {
+	if notification.UserID == 0 {
+		return errors.New("notification has no recipient")
+	}
+	if notification.ActorID != nil && *notification.ActorID == notification.UserID {
+		// Nobody is notified about their own actions
+		return nil
+	}
+
+	if !alwaysDelivered[notification.Type] {
+		enabled, err := s.notificationRepo.InAppEnabled(notification.UserID, notification.ScopeType, notification.ScopeID)
+		if err != nil {
+			return fmt.Errorf("failed to check notification preferences: %w", err)
+		}
+		if !enabled {
+			return nil
+		}
+	}
+
+	_, err := s.deliver(notification)
+	return err
+}
+
+// NotifySubscribers notifies every instant in-app subscriber of a scope
+func (s *NotificationServiceImpl) NotifySubscribers(scopeType string, scopeID uint, exclude []uint, notification models.Notification) (int, error) {
+	userIDs, err := s.notificationRepo.GetInstantSubscribers(scopeType, scopeID, time.Now())
+	if err != nil {
+		return 0, fmt.Errorf("failed to get subscribers: %w", err)
+	}
+
+	skip := make(map[uint]bool, len(exclude)+1)
+	for _, userID := range exclude {
+		skip[userID] = true
+	}
+	if notification.ActorID != nil {
+		skip[*notification.ActorID] = true
+	}
+
+	delivered := 0
+	for _, userID := range userIDs {
+		if skip[userID] {
+			continue
+		}
+		recipient := notification
+		recipient.ID = 0
+		recipient.UserID = userID
+		recipient.ScopeType = scopeType
+		recipient.ScopeID = scopeID
+		created, err := s.deliver(&recipient)
+		if err != nil {
+			return delivered, err
+		}
+		if created {
+			delivered++
+		}
+	}
+
+	return delivered, nil
+}
+
+// deliver stores a notification and pushes it to the user's connections
+func (s *NotificationServiceImpl) deliver(notification *models.Notification) (bool, error) {
+	created, err := s.notificationRepo.Create(notification)
+	if err != nil {
+		return false, fmt.Errorf("failed to create notification: %w", err)
+	}
+	if !created {
+		return false, nil
+	}
+
+	s.publish(notification.UserID, Event{Type: EventNotification, Notification: notification})
+	return true, nil
+}
+
+// publish sends an event with the user's current unread count. Delivery is
+// best effort; clients reload the list when they reconnect.
+func (s *NotificationServiceImpl) publish(userID uint, event Event) {
+	count, err := s.notificationRepo.CountUnread(userID)
+	if err != nil {
+		log.Printf("Error counting unread notifications for user %d: %v", userID, err)
+	}
+	event.UnreadCount = count
+
+	if err := s.broker.Publish(context.Background(), userID, event); err != nil {
+		log.Printf("Error publishing notification event for user %d: %v", userID, err)
+	}
+}
+
+// GetNotifications retrieves a page of a user's notifications
+func (s *NotificationServiceImpl) GetNotifications(userID uint, unreadOnly bool, page, pageSize int) ([]models.Notification, int64, error) {
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 || pageSize > 100 {
+		pageSize = 20
+	}
+
+	return s.notificationRepo.GetByUser(userID, unreadOnly, pageSize, (page-1)*pageSize)
+}
+
+// GetUnreadCount retrieves a user's unread notification count
+func (s *NotificationServiceImpl) GetUnreadCount(userID uint) (int64, error) {
+	return s.notificationRepo.CountUnread(userID)
+}
+
+// MarkRead marks notifications as read and returns the number changed
+func (s *NotificationServiceImpl) MarkRead(userID uint, ids []uint) (int64, error) {
+	updated, err := s.notificationRepo.MarkRead(userID, ids, time.Now())
+	if err != nil {
+		return 0, fmt.Errorf("failed to mark notifications as read: %w", err)
+	}
+	if updated > 0 {
+		s.publish(userID, Event{Type: EventUnreadCount})
+	}
+	return updated, nil
+}
+
+// MarkAllRead marks all of a user's notifications as read
+func (s *NotificationServiceImpl) MarkAllRead(userID uint) (int64, error) {
+	updated, err := s.notificationRepo.MarkAllRead(userID, time.Now())
+	if err != nil {
+		return 0, fmt.Errorf("failed to mark notifications as read: %w", err)
+	}
+	if updated > 0 {
+		s.publish(userID, Event{Type: EventUnreadCount})
+	}
+	return updated, nil
+}
+
+// Subscribe streams real-time events for a user
+func (s *NotificationServiceImpl) Subscribe(userID uint) (<-chan Event, func()) {
+	return s.broker.Subscribe(userID)
+}
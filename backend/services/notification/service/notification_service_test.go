@@ -0,0 +1,152 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/yerenwgventures/GreatNigeriaLibrary-Foundation/backend/services/notification/models"
+)
+
+type fakeRepository struct {
+	notifications []models.Notification
+	disabled      map[uint]bool
+	subscribers   []uint
+}
+
+func (r *fakeRepository) Create(n *models.Notification) (bool, error) {
+	for _, existing := range r.notifications {
+		if n.DedupKey != nil && existing.DedupKey != nil && existing.UserID == n.UserID && *existing.DedupKey == *n.DedupKey {
+			return false, nil
+		}
+	}
+	n.ID = uint(len(r.notifications) + 1)
+	r.notifications = append(r.notifications, *n)
+	return true, nil
+}
+
+func (r *fakeRepository) GetByUser(userID uint, unreadOnly bool, limit, offset int) ([]models.Notification, int64, error) {
+	var result []models.Notification
+	for _, n := range r.notifications {
+		if n.UserID == userID && (!unreadOnly || !n.IsRead()) {
+			result = append(result, n)
+		}
+	}
+	return result, int64(len(result)), nil
+}
+
+func (r *fakeRepository) CountUnread(userID uint) (int64, error) {
+	_, count, err := r.GetByUser(userID, true, 0, 0)
+	return count, err
+}
+
+func (r *fakeRepository) MarkRead(userID uint, ids []uint, readAt time.Time) (int64, error) {
+	var updated int64
+	for i := range r.notifications {
+		for _, id := range ids {
+			n := &r.notifications[i]
+			if n.ID == id && n.UserID == userID && !n.IsRead() {
+				n.ReadAt = &readAt
+				updated++
+			}
+		}
+	}
+	return updated, nil
+}
+
+func (r *fakeRepository) MarkAllRead(userID uint, readAt time.Time) (int64, error) {
+	var updated int64
+	for i := range r.notifications {
+		n := &r.notifications[i]
+		if n.UserID == userID && !n.IsRead() {
+			n.ReadAt = &readAt
+			updated++
+		}
+	}
+	return updated, nil
+}
+
+func (r *fakeRepository) InAppEnabled(userID uint, scopeType string, scopeID uint) (bool, error) {
+	return !r.disabled[userID], nil
+}
+
+func (r *fakeRepository) GetInstantSubscribers(scopeType string, scopeID uint, now time.Time) ([]uint, error) {
+	return r.subscribers, nil
+}
+
+func TestNotifyPublishesToSubscribers(t *testing.T) {
+	repo := &fakeRepository{}
+	svc := NewNotificationService(repo, NewLocalBroker())
+
+	events, unsubscribe := svc.Subscribe(7)
+	defer unsubscribe()
+
+	require.NoError(t, svc.Notify(&models.Notification{UserID: 7, Type: models.TypeReply, Title: "New reply"}))
+
+	select {
+	case event := <-events:
+		assert.Equal(t, EventNotification, event.Type)
+		assert.Equal(t, "New reply", event.Notification.Title)
+		assert.Equal(t, int64(1), event.UnreadCount)
+	case <-time.After(time.Second):
+		t.Fatal("expected a notification event")
+	}
+
+	_, err := svc.MarkAllRead(7)
+	require.NoError(t, err)
+
+	event := <-events
+	assert.Equal(t, EventUnreadCount, event.Type)
+	assert.Equal(t, int64(0), event.UnreadCount)
+}
+
+func TestNotifyRespectsPreferences(t *testing.T) {
+	repo := &fakeRepository{disabled: map[uint]bool{3: true}}
+	svc := NewNotificationService(repo, nil)
+
+	actor := uint(5)
+	require.NoError(t, svc.Notify(&models.Notification{UserID: 3, Type: models.TypeMention}))
+	require.NoError(t, svc.Notify(&models.Notification{UserID: 5, Type: models.TypeReply, ActorID: &actor}))
+	require.NoError(t, svc.Notify(&models.Notification{UserID: 3, Type: models.TypeModeration}))
+
+	require.Len(t, repo.notifications, 1)
+	assert.Equal(t, models.TypeModeration, repo.notifications[0].Type)
+}
+
+func TestNotifySubscribersDeduplicates(t *testing.T) {
+	repo := &fakeRepository{subscribers: []uint{1, 2, 3}}
+	svc := NewNotificationService(repo, nil)
+
+	actor := uint(2)
+	template := models.Notification{Type: models.TypeSubscriptionUpdate, ActorID: &actor}
+	template.WithDedupKey("comment:10")
+
+	delivered, err := svc.NotifySubscribers("topic", 4, []uint{3}, template)
+	require.NoError(t, err)
+	assert.Equal(t, 1, delivered)
+
+	// User 1 already has this notification; only user 3 is new
+	delivered, err = svc.NotifySubscribers("topic", 4, nil, template)
+	require.NoError(t, err)
+	assert.Equal(t, 1, delivered)
+	assert.Len(t, repo.notifications, 2)
+}
+
+func TestLocalBrokerUnsubscribe(t *testing.T) {
+	broker := NewLocalBroker()
+	_, first := broker.Subscribe(1)
+	second, unsubscribe := broker.Subscribe(1)
+	assert.Equal(t, 2, broker.Connections(1))
+
+	first()
+	first()
+	assert.Equal(t, 1, broker.Connections(1))
+
+	require.NoError(t, broker.Publish(context.Background(), 1, Event{Type: EventUnreadCount, UnreadCount: 4}))
+	assert.Equal(t, int64(4), (<-second).UnreadCount)
+
+	unsubscribe()
+	assert.Equal(t, 0, broker.Connections(1))
+}
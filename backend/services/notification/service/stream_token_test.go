@@ -0,0 +1,54 @@
+package service
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStreamTokensRoundTrip(t *testing.T) {
+	tokens := NewStreamTokens("secret", time.Minute)
+	now := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+	tokens.now = func() time.Time { return now }
+
+	token, expiresAt := tokens.Issue(42)
+	assert.Equal(t, now.Add(time.Minute), expiresAt)
+
+	userID, err := tokens.Verify(token)
+	require.NoError(t, err)
+	assert.Equal(t, uint(42), userID)
+
+	now = now.Add(59 * time.Second)
+	_, err = tokens.Verify(token)
+	assert.NoError(t, err, "still valid before expiry")
+
+	now = now.Add(time.Second)
+	_, err = tokens.Verify(token)
+	assert.Equal(t, ErrInvalidStreamToken, err, "expired")
+}
+
+func TestStreamTokensRejectForgeries(t *testing.T) {
+	tokens := NewStreamTokens("secret", time.Minute)
+	token, _ := tokens.Issue(42)
+	parts := strings.Split(token, ".")
+
+	forged := []string{
+		"",
+		"42",
+		parts[0] + "." + parts[1],
+		"43." + parts[1] + "." + parts[2],
+		parts[0] + ".9999999999." + parts[2],
+		token + "x",
+	}
+	for _, candidate := range forged {
+		_, err := tokens.Verify(candidate)
+		assert.Equal(t, ErrInvalidStreamToken, err, candidate)
+	}
+
+	other, _ := NewStreamTokens("other secret", time.Minute).Issue(42)
+	_, err := tokens.Verify(other)
+	assert.Equal(t, ErrInvalidStreamToken, err, "signed with another secret")
+}
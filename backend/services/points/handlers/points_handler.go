@@ -0,0 +1,207 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/yerenwgventures/GreatNigeriaLibrary-Foundation/backend/pkg/common/middleware"
+	"github.com/yerenwgventures/GreatNigeriaLibrary-Foundation/backend/services/points/models"
+	"github.com/yerenwgventures/GreatNigeriaLibrary-Foundation/backend/services/points/service"
+)
+
+// PointsHandler defines the handler for points endpoints
+type PointsHandler struct {
+	pointsService service.PointsService
+}
+
+// NewPointsHandler creates a new points handler
+func NewPointsHandler(pointsService service.PointsService) *PointsHandler {
+	return &PointsHandler{
+		pointsService: pointsService,
+	}
+}
+
+// RegisterRoutes registers the routes for the current user's points
+func (h *PointsHandler) RegisterRoutes(router *gin.RouterGroup) {
+	points := router.Group("/points")
+	{
+		points.GET("/balance", h.GetBalance)
+		points.GET("/history", h.GetHistory)
+		points.GET("/rules", h.GetRules)
+	}
+}
+
+// RegisterAdminRoutes registers the admin routes for points. The caller is
+// responsible for restricting router to admins.
+func (h *PointsHandler) RegisterAdminRoutes(router *gin.RouterGroup) {
+	points := router.Group("/points")
+	{
+		points.POST("/adjust", h.Adjust)
+		points.GET("/rules", h.GetRules)
+		points.PUT("/rules/:action", h.UpdateRule)
+		points.GET("/discrepancies", h.GetDiscrepancies)
+		points.POST("/reconcile/:userId", h.Reconcile)
+		points.GET("/users/:userId/history", h.GetUserHistory)
+	}
+}
+
+// GetBalance handles getting the current user's balance
+func (h *PointsHandler) GetBalance(c *gin.Context) {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	balance, err := h.pointsService.GetBalance(userID)
+	if err != nil {
+		respondError(c, err, "Failed to get points balance")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"balance": balance})
+}
+
+// GetHistory handles listing the current user's transactions
+func (h *PointsHandler) GetHistory(c *gin.Context) {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	h.respondHistory(c, userID)
+}
+
+// GetUserHistory handles listing any user's transactions
+func (h *PointsHandler) GetUserHistory(c *gin.Context) {
+	userID, err := strconv.ParseUint(c.Param("userId"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	h.respondHistory(c, uint(userID))
+}
+
+// GetRules handles listing the earning rules
+func (h *PointsHandler) GetRules(c *gin.Context) {
+	rules, err := h.pointsService.GetRules()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get earning rules"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"rules": rules})
+}
+
+// UpdateRule handles changing an earning rule
+func (h *PointsHandler) UpdateRule(c *gin.Context) {
+	var request struct {
+		Points      int    `json:"points" binding:"min=0"`
+		DailyCap    int    `json:"dailyCap" binding:"min=0"`
+		Enabled     *bool  `json:"enabled" binding:"required"`
+		Description string `json:"description"`
+	}
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	rule, err := h.pointsService.UpdateRule(c.Param("action"), request.Points, request.DailyCap, *request.Enabled, request.Description)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, rule)
+}
+
+// Adjust handles a manual credit or debit by an admin. Clients should send
+// an Idempotency-Key header so a retried request is applied only once.
+func (h *PointsHandler) Adjust(c *gin.Context) {
+	adminID, ok := middleware.GetUserID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	var request struct {
+		UserID uint   `json:"userId" binding:"required"`
+		Points int    `json:"points" binding:"required"`
+		Reason string `json:"reason" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	transaction, err := h.pointsService.Adjust(adminID, request.UserID, request.Points, request.Reason, c.GetHeader("Idempotency-Key"))
+	if err != nil {
+		respondError(c, err, "Failed to adjust points")
+		return
+	}
+
+	c.JSON(http.StatusOK, transaction)
+}
+
+// GetDiscrepancies handles listing users whose balance does not match their ledger
+func (h *PointsHandler) GetDiscrepancies(c *gin.Context) {
+	discrepancies, err := h.pointsService.FindDiscrepancies()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check balances"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"discrepancies": discrepancies})
+}
+
+// Reconcile handles resetting a user's balance to their ledger
+func (h *PointsHandler) Reconcile(c *gin.Context) {
+	userID, err := strconv.ParseUint(c.Param("userId"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	discrepancy, err := h.pointsService.Reconcile(uint(userID))
+	if err != nil {
+		respondError(c, err, "Failed to reconcile balance")
+		return
+	}
+
+	c.JSON(http.StatusOK, discrepancy)
+}
+
+// respondHistory writes a page of a user's transactions
+func (h *PointsHandler) respondHistory(c *gin.Context, userID uint) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	pageSize, _ := strconv.Atoi(c.DefaultQuery("page_size", "20"))
+
+	transactions, total, err := h.pointsService.GetHistory(userID, page, pageSize)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get points history"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"transactions": transactions,
+		"total":        total,
+		"page":         page,
+		"pageSize":     pageSize,
+	})
+}
+
+// respondError maps ledger errors to status codes
+func respondError(c *gin.Context, err error, message string) {
+	switch {
+	case errors.Is(err, models.ErrUserNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, models.ErrInsufficientPoints):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": message})
+	}
+}
@@ -0,0 +1,60 @@
+package models
+
+import (
+	"errors"
+	"time"
+)
+
+// Earning actions
+const (
+	ActionTopicCreated     = "topic_created"
+	ActionCommentCreated   = "comment_created"
+	ActionUpvoteReceived   = "upvote_received"
+	ActionTopicFeatured    = "topic_featured"
+	ActionSectionCompleted = "section_completed"
+	ActionQuizPassed       = "quiz_passed"
+	ActionElementCompleted = "element_completed"
+	ActionBookCompleted    = "book_completed"
+
+	// ActionAdjustment marks manual adjustments by admins
+	ActionAdjustment = "admin_adjustment"
+	// ActionOpeningBalance marks balances that existed before the ledger
+	ActionOpeningBalance = "opening_balance"
+)
+
+var (
+	// ErrUserNotFound is returned when the user does not exist
+	ErrUserNotFound = errors.New("user not found")
+	// ErrUnknownAction is returned when no earning rule exists for an action
+	ErrUnknownAction = errors.New("unknown points action")
+	// ErrRuleDisabled is returned when the earning rule for an action is disabled
+	ErrRuleDisabled = errors.New("points rule is disabled")
+	// ErrDailyCapReached is returned when the user has earned the daily maximum for an action
+	ErrDailyCapReached = errors.New("daily points cap reached")
+	// ErrInsufficientPoints is returned when a debit would make the balance negative
+	ErrInsufficientPoints = errors.New("insufficient points")
+)
+
+// EarningRule configures how many points an action earns
+type EarningRule struct {
+	ID          uint      `json:"id" gorm:"primarykey"`
+	Action      string    `json:"action" gorm:"size:64;not null;uniqueIndex"`
+	Points      int       `json:"points" gorm:"not null"`
+	DailyCap    int       `json:"dailyCap" gorm:"not null;default:0"` // Most points a user can earn from the action per UTC day, 0 for no cap
+	Enabled     bool      `json:"enabled" gorm:"not null;default:true"`
+	Description string    `json:"description" gorm:"size:255"`
+	CreatedAt   time.Time `json:"createdAt"`
+	UpdatedAt   time.Time `json:"updatedAt"`
+}
+
+// TableName specifies the table name for EarningRule
+func (EarningRule) TableName() string {
+	return "points_earning_rules"
+}
+
+// Discrepancy is a user whose stored balance differs from their ledger
+type Discrepancy struct {
+	UserID        uint `json:"userId"`
+	StoredBalance int  `json:"storedBalance"`
+	LedgerBalance int  `json:"ledgerBalance"`
+}
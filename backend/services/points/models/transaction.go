@@ -0,0 +1,39 @@
+package models
+
+import (
+	"time"
+)
+
+// PointsTransactionType defines the type of points transaction
+type PointsTransactionType string
+
+const (
+	// Transaction types for points
+	PointsEarned   PointsTransactionType = "earned"
+	PointsSpent    PointsTransactionType = "spent"
+	PointsExpired  PointsTransactionType = "expired"
+	PointsAdjusted PointsTransactionType = "adjusted" // For manual adjustments by admins
+)
+
+// PointsTransaction represents a transaction in a user's points account.
+// Transactions are written by the points service, which keeps
+// users.points_balance equal to the sum of a user's transactions.
+type PointsTransaction struct {
+	ID              uint                  `json:"id" gorm:"primaryKey"`
+	UserID          uint                  `json:"user_id" gorm:"index;not null;index:idx_points_transactions_daily,priority:1;uniqueIndex:idx_points_transactions_idempotency,priority:1"`
+	Points          int                   `json:"points" gorm:"not null"` // Positive for earned, negative for spent
+	TransactionType PointsTransactionType `json:"transaction_type" gorm:"type:varchar(50);not null;index"`
+	Description     string                `json:"description" gorm:"size:255"`
+	ReferenceID     *uint                 `json:"reference_id,omitempty" gorm:"index"` // Reference to related entity (e.g., book, discussion)
+	ReferenceType   string                `json:"reference_type,omitempty" gorm:"size:100"`
+	Action          string                `json:"action,omitempty" gorm:"size:64;index:idx_points_transactions_daily,priority:2"` // Earning rule that produced the transaction
+	BalanceAfter    int                   `json:"balance_after"`
+	ActorID         *uint                 `json:"actor_id,omitempty"` // Admin who made a manual adjustment
+	IdempotencyKey  *string               `json:"-" gorm:"size:191;uniqueIndex:idx_points_transactions_idempotency,priority:2"`
+	CreatedAt       time.Time             `json:"created_at" gorm:"index:idx_points_transactions_daily,priority:3"`
+}
+
+// TableName overrides the table name for PointsTransaction
+func (PointsTransaction) TableName() string {
+	return "points_transactions"
+}
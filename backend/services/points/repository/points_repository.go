@@ -0,0 +1,229 @@
+package repository
+
+import (
+	"errors"
+	"time"
+
+	"github.com/yerenwgventures/GreatNigeriaLibrary-Foundation/backend/services/points/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// PointsRepository defines the interface for points ledger data operations
+type PointsRepository interface {
+	// Record applies a transaction to the user's balance. If the user already
+	// has a transaction with the same idempotency key, that transaction is
+	// returned and nothing changes. Positive transactions with an action are
+	// reduced to fit dailyCap, counted from dayStart.
+	Record(entry *models.PointsTransaction, dailyCap int, dayStart time.Time) (*models.PointsTransaction, bool, error)
+	GetBalance(userID uint) (int, error)
+	GetHistory(userID uint, limit, offset int) ([]models.PointsTransaction, int64, error)
+
+	// Earning rules
+	GetRules() ([]models.EarningRule, error)
+	GetRule(action string) (*models.EarningRule, error)
+	SaveRule(rule *models.EarningRule) error
+
+	// Reconciliation
+	FindDiscrepancies() ([]models.Discrepancy, error)
+	Reconcile(userID uint) (*models.Discrepancy, error)
+}
+
+// GormPointsRepository implements the PointsRepository interface
+type GormPointsRepository struct {
+	db *gorm.DB
+}
+
+// NewGormPointsRepository creates a new points repository
+func NewGormPointsRepository(db *gorm.DB) *GormPointsRepository {
+	return &GormPointsRepository{db: db}
+}
+
+// Record applies a transaction to the user's balance
+func (r *GormPointsRepository) Record(entry *models.PointsTransaction, dailyCap int, dayStart time.Time) (*models.PointsTransaction, bool, error) {
+	var result *models.PointsTransaction
+	created := false
+
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		// Lock the user's row so transactions for the same user apply one at a
+		// time; this also serializes retries that share an idempotency key
+		var user struct {
+			PointsBalance int
+		}
+		err := tx.Table("users").
+			Clauses(clause.Locking{Strength: "UPDATE"}).
+			Select("points_balance").
+			Where("id = ? AND deleted_at IS NULL", entry.UserID).
+			Take(&user).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return models.ErrUserNotFound
+		}
+		if err != nil {
+			return err
+		}
+
+		if entry.IdempotencyKey != nil {
+			var existing models.PointsTransaction
+			err := tx.Where("user_id = ? AND idempotency_key = ?", entry.UserID, *entry.IdempotencyKey).Take(&existing).Error
+			if err == nil {
+				result = &existing
+				return nil
+			}
+			if !errors.Is(err, gorm.ErrRecordNotFound) {
+				return err
+			}
+		}
+
+		points := entry.Points
+		if dailyCap > 0 && points > 0 && entry.Action != "" {
+			var earned int
+			err := tx.Model(&models.PointsTransaction{}).
+				Select("COALESCE(SUM(points), 0)").
+				Where("user_id = ? AND action = ? AND points > 0 AND created_at >= ?", entry.UserID, entry.Action, dayStart).
+				Scan(&earned).Error
+			if err != nil {
+				return err
+			}
+			if earned >= dailyCap {
+				return models.ErrDailyCapReached
+			}
+			if points > dailyCap-earned {
+				points = dailyCap - earned
+			}
+		}
+
+		balance := user.PointsBalance + points
+		if points < 0 && balance < 0 {
+			return models.ErrInsufficientPoints
+		}
+
+		if err := tx.Table("users").Where("id = ?", entry.UserID).Update("points_balance", balance).Error; err != nil {
+			return err
+		}
+
+		entry.Points = points
+		entry.BalanceAfter = balance
+		if err := tx.Create(entry).Error; err != nil {
+			return err
+		}
+
+		result = entry
+		created = true
+		return nil
+	})
+	if err != nil {
+		return nil, false, err
+	}
+
+	return result, created, nil
+}
+
+// GetBalance retrieves a user's balance
+func (r *GormPointsRepository) GetBalance(userID uint) (int, error) {
+	var user struct {
+		PointsBalance int
+	}
+	err := r.db.Table("users").Select("points_balance").Where("id = ? AND deleted_at IS NULL", userID).Take(&user).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return 0, models.ErrUserNotFound
+	}
+	return user.PointsBalance, err
+}
+
+// GetHistory retrieves a page of a user's transactions, newest first
+func (r *GormPointsRepository) GetHistory(userID uint, limit, offset int) ([]models.PointsTransaction, int64, error) {
+	query := r.db.Model(&models.PointsTransaction{}).Where("user_id = ?", userID)
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var transactions []models.PointsTransaction
+	err := query.Order("created_at DESC, id DESC").Limit(limit).Offset(offset).Find(&transactions).Error
+	return transactions, total, err
+}
+
+// GetRules retrieves all earning rules
+func (r *GormPointsRepository) GetRules() ([]models.EarningRule, error) {
+	var rules []models.EarningRule
+	err := r.db.Order("action").Find(&rules).Error
+	return rules, err
+}
+
+// GetRule retrieves the earning rule for an action
+func (r *GormPointsRepository) GetRule(action string) (*models.EarningRule, error) {
+	var rule models.EarningRule
+	err := r.db.Where("action = ?", action).Take(&rule).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, models.ErrUnknownAction
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &rule, nil
+}
+
+// SaveRule creates or updates an earning rule
+func (r *GormPointsRepository) SaveRule(rule *models.EarningRule) error {
+	return r.db.Save(rule).Error
+}
+
+// FindDiscrepancies retrieves users whose stored balance differs from the
+// sum of their transactions
+func (r *GormPointsRepository) FindDiscrepancies() ([]models.Discrepancy, error) {
+	var discrepancies []models.Discrepancy
+	err := r.db.Raw(`
+		SELECT u.id AS user_id,
+		       u.points_balance AS stored_balance,
+		       COALESCE(SUM(t.points), 0) AS ledger_balance
+		FROM users u
+		LEFT JOIN points_transactions t ON t.user_id = u.id
+		WHERE u.deleted_at IS NULL
+		GROUP BY u.id, u.points_balance
+		HAVING u.points_balance <> COALESCE(SUM(t.points), 0)
+		ORDER BY u.id`).Scan(&discrepancies).Error
+	return discrepancies, err
+}
+
+// Reconcile resets a user's stored balance to the sum of their transactions
+// and returns the balances before the fix
+func (r *GormPointsRepository) Reconcile(userID uint) (*models.Discrepancy, error) {
+	discrepancy := &models.Discrepancy{UserID: userID}
+
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		var user struct {
+			PointsBalance int
+		}
+		err := tx.Table("users").
+			Clauses(clause.Locking{Strength: "UPDATE"}).
+			Select("points_balance").
+			Where("id = ? AND deleted_at IS NULL", userID).
+			Take(&user).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return models.ErrUserNotFound
+		}
+		if err != nil {
+			return err
+		}
+		discrepancy.StoredBalance = user.PointsBalance
+
+		err = tx.Model(&models.PointsTransaction{}).
+			Select("COALESCE(SUM(points), 0)").
+			Where("user_id = ?", userID).
+			Scan(&discrepancy.LedgerBalance).Error
+		if err != nil {
+			return err
+		}
+
+		if discrepancy.StoredBalance == discrepancy.LedgerBalance {
+			return nil
+		}
+		return tx.Table("users").Where("id = ?", userID).Update("points_balance", discrepancy.LedgerBalance).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return discrepancy, nil
+}
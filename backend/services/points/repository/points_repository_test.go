@@ -0,0 +1,225 @@
+package repository
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/yerenwgventures/GreatNigeriaLibrary-Foundation/backend/pkg/common/database/dbtest"
+	"github.com/yerenwgventures/GreatNigeriaLibrary-Foundation/backend/services/points/models"
+	"gorm.io/gorm"
+)
+
+// ledgerMigration is the migration that creates the points ledger
+const ledgerMigration = 22
+
+func createUser(t *testing.T, db *gorm.DB, username string, balance int) uint {
+	t.Helper()
+	var id uint
+	err := db.Raw(`
+		INSERT INTO users (username, email, password, full_name, points_balance, created_at, updated_at)
+		VALUES (?, ?, 'x', ?, ?, NOW(), NOW())
+		RETURNING id`,
+		username, username+"@example.com", username, balance,
+	).Scan(&id).Error
+	require.NoError(t, err)
+	return id
+}
+
+func balanceOf(t *testing.T, db *gorm.DB, userID uint) int {
+	t.Helper()
+	var balance int
+	require.NoError(t, db.Raw("SELECT points_balance FROM users WHERE id = ?", userID).Scan(&balance).Error)
+	return balance
+}
+
+func earned(userID uint, points int, action, key string) *models.PointsTransaction {
+	return &models.PointsTransaction{
+		UserID:          userID,
+		Points:          points,
+		TransactionType: models.PointsEarned,
+		Action:          action,
+		IdempotencyKey:  &key,
+	}
+}
+
+func TestRecordIsIdempotent(t *testing.T) {
+	db := dbtest.Open(t)
+	repo := NewGormPointsRepository(db)
+	ada := createUser(t, db, "ada", 0)
+	bola := createUser(t, db, "bola", 0)
+	dayStart := time.Now().UTC().Truncate(24 * time.Hour)
+
+	first, created, err := repo.Record(earned(ada, 10, models.ActionQuizPassed, "quiz_passed:quiz:1"), 0, dayStart)
+	require.NoError(t, err)
+	assert.True(t, created)
+	assert.Equal(t, 10, first.BalanceAfter)
+
+	retry, created, err := repo.Record(earned(ada, 10, models.ActionQuizPassed, "quiz_passed:quiz:1"), 0, dayStart)
+	require.NoError(t, err)
+	assert.False(t, created, "a retry returns the original transaction")
+	assert.Equal(t, first.ID, retry.ID)
+	assert.Equal(t, 10, balanceOf(t, db, ada))
+
+	_, created, err = repo.Record(earned(bola, 10, models.ActionQuizPassed, "quiz_passed:quiz:1"), 0, dayStart)
+	require.NoError(t, err)
+	assert.True(t, created, "keys are scoped to the user")
+
+	history, total, err := repo.GetHistory(ada, 10, 0)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), total)
+	assert.Len(t, history, 1)
+}
+
+func TestRecordAppliesDailyCap(t *testing.T) {
+	db := dbtest.Open(t)
+	repo := NewGormPointsRepository(db)
+	ada := createUser(t, db, "ada", 0)
+	dayStart := time.Now().UTC().Truncate(24 * time.Hour)
+
+	transaction, _, err := repo.Record(earned(ada, 10, models.ActionCommentCreated, "a"), 15, dayStart)
+	require.NoError(t, err)
+	assert.Equal(t, 10, transaction.Points)
+
+	transaction, _, err = repo.Record(earned(ada, 10, models.ActionCommentCreated, "b"), 15, dayStart)
+	require.NoError(t, err)
+	assert.Equal(t, 5, transaction.Points, "reduced to fit the cap")
+
+	_, _, err = repo.Record(earned(ada, 10, models.ActionCommentCreated, "c"), 15, dayStart)
+	assert.Equal(t, models.ErrDailyCapReached, err)
+
+	_, _, err = repo.Record(earned(ada, 10, models.ActionTopicCreated, "d"), 15, dayStart)
+	assert.NoError(t, err, "caps are per action")
+	assert.Equal(t, 25, balanceOf(t, db, ada))
+
+	// Move today's earnings to yesterday; the cap starts again
+	require.NoError(t, db.Exec("UPDATE points_transactions SET created_at = created_at - INTERVAL '1 day' WHERE user_id = ?", ada).Error)
+	transaction, _, err = repo.Record(earned(ada, 10, models.ActionCommentCreated, "e"), 15, dayStart)
+	require.NoError(t, err)
+	assert.Equal(t, 10, transaction.Points)
+}
+
+func TestRecordRejectsOverdraftsAndUnknownUsers(t *testing.T) {
+	db := dbtest.Open(t)
+	repo := NewGormPointsRepository(db)
+	ada := createUser(t, db, "ada", 0)
+	dayStart := time.Now().UTC().Truncate(24 * time.Hour)
+
+	_, _, err := repo.Record(earned(ada, 10, models.ActionQuizPassed, "earn"), 0, dayStart)
+	require.NoError(t, err)
+
+	spend := &models.PointsTransaction{UserID: ada, Points: -11, TransactionType: models.PointsSpent}
+	_, _, err = repo.Record(spend, 0, dayStart)
+	assert.Equal(t, models.ErrInsufficientPoints, err)
+	assert.Equal(t, 10, balanceOf(t, db, ada))
+
+	spend = &models.PointsTransaction{UserID: ada, Points: -10, TransactionType: models.PointsSpent}
+	transaction, _, err := repo.Record(spend, 1, dayStart)
+	require.NoError(t, err)
+	assert.Equal(t, -10, transaction.Points, "debits are never capped")
+	assert.Equal(t, 0, transaction.BalanceAfter)
+
+	_, _, err = repo.Record(earned(ada+1000, 10, models.ActionQuizPassed, "missing"), 0, dayStart)
+	assert.Equal(t, models.ErrUserNotFound, err)
+}
+
+func TestFindDiscrepanciesAndReconcile(t *testing.T) {
+	db := dbtest.Open(t)
+	repo := NewGormPointsRepository(db)
+	ada := createUser(t, db, "ada", 0)
+	bola := createUser(t, db, "bola", 0)
+	dayStart := time.Now().UTC().Truncate(24 * time.Hour)
+
+	for _, user := range []uint{ada, bola} {
+		_, _, err := repo.Record(earned(user, 10, models.ActionQuizPassed, "earn"), 0, dayStart)
+		require.NoError(t, err)
+	}
+
+	discrepancies, err := repo.FindDiscrepancies()
+	require.NoError(t, err)
+	assert.Empty(t, discrepancies)
+
+	require.NoError(t, db.Exec("UPDATE users SET points_balance = 25 WHERE id = ?", bola).Error)
+	discrepancies, err = repo.FindDiscrepancies()
+	require.NoError(t, err)
+	assert.Equal(t, []models.Discrepancy{{UserID: bola, StoredBalance: 25, LedgerBalance: 10}}, discrepancies)
+
+	fixed, err := repo.Reconcile(bola)
+	require.NoError(t, err)
+	assert.Equal(t, &models.Discrepancy{UserID: bola, StoredBalance: 25, LedgerBalance: 10}, fixed)
+	assert.Equal(t, 10, balanceOf(t, db, bola))
+
+	discrepancies, err = repo.FindDiscrepancies()
+	require.NoError(t, err)
+	assert.Empty(t, discrepancies)
+
+	_, err = repo.Reconcile(bola + 1000)
+	assert.Equal(t, models.ErrUserNotFound, err)
+}
+
+func TestMigrationRecordsOpeningBalances(t *testing.T) {
+	db := dbtest.Empty(t)
+	dbtest.Migrate(t, db, ledgerMigration-1)
+
+	// The transactions table as earlier releases created it, before the ledger
+	require.NoError(t, db.Exec(`
+		CREATE TABLE points_transactions (
+			id BIGSERIAL PRIMARY KEY,
+			user_id BIGINT NOT NULL,
+			points BIGINT NOT NULL,
+			transaction_type VARCHAR(50) NOT NULL,
+			description VARCHAR(255),
+			reference_id BIGINT,
+			reference_type VARCHAR(100),
+			created_at TIMESTAMP WITH TIME ZONE
+		)`).Error)
+
+	withBalance := createUser(t, db, "ada", 40)
+	negative := createUser(t, db, "bola", -5)
+	zero := createUser(t, db, "chidi", 0)
+	deleted := createUser(t, db, "dayo", 30)
+	require.NoError(t, db.Exec("UPDATE users SET deleted_at = NOW() WHERE id = ?", deleted).Error)
+	recorded := createUser(t, db, "emeka", 15)
+	require.NoError(t, db.Exec(`
+		INSERT INTO points_transactions (user_id, points, transaction_type, description, created_at)
+		VALUES (?, 15, 'earned', 'Read a book', NOW())`, recorded).Error)
+
+	dbtest.Migrate(t, db, 0)
+	repo := NewGormPointsRepository(db)
+
+	for user, want := range map[uint]int{withBalance: 40, negative: -5} {
+		history, total, err := repo.GetHistory(user, 10, 0)
+		require.NoError(t, err)
+		require.Equal(t, int64(1), total, fmt.Sprint(user))
+		assert.Equal(t, want, history[0].Points)
+		assert.Equal(t, want, history[0].BalanceAfter)
+		assert.Equal(t, models.PointsAdjusted, history[0].TransactionType)
+		assert.Equal(t, models.ActionOpeningBalance, history[0].Action)
+	}
+
+	for _, user := range []uint{zero, deleted} {
+		_, total, err := repo.GetHistory(user, 10, 0)
+		require.NoError(t, err)
+		assert.Zero(t, total, fmt.Sprint(user))
+	}
+
+	history, _, err := repo.GetHistory(recorded, 10, 0)
+	require.NoError(t, err)
+	require.Len(t, history, 1)
+	assert.Equal(t, "Read a book", history[0].Description, "users with transactions keep their ledger")
+
+	discrepancies, err := repo.FindDiscrepancies()
+	require.NoError(t, err)
+	assert.Empty(t, discrepancies)
+
+	rules, err := repo.GetRules()
+	require.NoError(t, err)
+	assert.Len(t, rules, 8)
+	rule, err := repo.GetRule(models.ActionTopicCreated)
+	require.NoError(t, err)
+	assert.Equal(t, 10, rule.Points)
+	assert.Equal(t, 50, rule.DailyCap)
+	assert.True(t, rule.Enabled)
+}
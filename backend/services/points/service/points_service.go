@@ -0,0 +1,225 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/yerenwgventures/GreatNigeriaLibrary-Foundation/backend/services/points/models"
+	"github.com/yerenwgventures/GreatNigeriaLibrary-Foundation/backend/services/points/repository"
+)
+
+// AwardRequest describes points earned for an action
+type AwardRequest struct {
+	UserID        uint
+	Action        string
+	ReferenceType string
+	ReferenceID   uint
+	// Points overrides the rule's amount when positive, e.g. for quizzes that
+	// carry their own point value
+	Points int
+	// IdempotencyKey defaults to "action:referenceType:referenceID", so each
+	// action is rewarded once per referenced entity
+	IdempotencyKey string
+	Description    string
+}
+
+// Awarder awards points for earning actions. Other services depend on this
+// interface rather than the full PointsService.
+type Awarder interface {
+	// Award applies the earning rule for req.Action. Retrying with the same
+	// idempotency key returns the original transaction.
+	Award(req AwardRequest) (*models.PointsTransaction, error)
+}
+
+// PointsService defines the interface for points ledger operations
+type PointsService interface {
+	Awarder
+
+	// Spend debits points, failing with ErrInsufficientPoints if the balance is too low
+	Spend(userID uint, points int, description, referenceType string, referenceID uint, idempotencyKey string) (*models.PointsTransaction, error)
+	// Adjust credits or debits points on behalf of an admin
+	Adjust(adminID, userID uint, points int, reason, idempotencyKey string) (*models.PointsTransaction, error)
+
+	GetBalance(userID uint) (int, error)
+	GetHistory(userID uint, page, pageSize int) ([]models.PointsTransaction, int64, error)
+
+	GetRules() ([]models.EarningRule, error)
+	UpdateRule(action string, points, dailyCap int, enabled bool, description string) (*models.EarningRule, error)
+
+	FindDiscrepancies() ([]models.Discrepancy, error)
+	Reconcile(userID uint) (*models.Discrepancy, error)
+}
+
+// PointsServiceImpl implements the PointsService interface
+type PointsServiceImpl struct {
+	repo repository.PointsRepository
+	now  func() time.Time
+}
+
+// NewPointsService creates a new points service
+func NewPointsService(repo repository.PointsRepository) *PointsServiceImpl {
+	return &PointsServiceImpl{
+		repo: repo,
+		now:  time.Now,
+	}
+}
+
+// Award applies the earning rule for an action
+func (s *PointsServiceImpl) Award(req AwardRequest) (*models.PointsTransaction, error) {
+	rule, err := s.repo.GetRule(req.Action)
+	if err != nil {
+		return nil, err
+	}
+	if !rule.Enabled {
+		return nil, models.ErrRuleDisabled
+	}
+
+	points := rule.Points
+	if req.Points > 0 {
+		points = req.Points
+	}
+	if points <= 0 {
+		return nil, fmt.Errorf("rule %s awards no points", rule.Action)
+	}
+
+	key := req.IdempotencyKey
+	if key == "" {
+		key = fmt.Sprintf("%s:%s:%d", req.Action, req.ReferenceType, req.ReferenceID)
+	}
+	description := req.Description
+	if description == "" {
+		description = rule.Description
+	}
+
+	entry := &models.PointsTransaction{
+		UserID:          req.UserID,
+		Points:          points,
+		TransactionType: models.PointsEarned,
+		Description:     description,
+		ReferenceType:   req.ReferenceType,
+		Action:          req.Action,
+		IdempotencyKey:  &key,
+	}
+	if req.ReferenceID != 0 {
+		referenceID := req.ReferenceID
+		entry.ReferenceID = &referenceID
+	}
+
+	transaction, _, err := s.repo.Record(entry, rule.DailyCap, s.dayStart())
+	return transaction, err
+}
+
+// Spend debits points from a user
+func (s *PointsServiceImpl) Spend(userID uint, points int, description, referenceType string, referenceID uint, idempotencyKey string) (*models.PointsTransaction, error) {
+	if points <= 0 {
+		return nil, errors.New("points to spend must be positive")
+	}
+
+	entry := &models.PointsTransaction{
+		UserID:          userID,
+		Points:          -points,
+		TransactionType: models.PointsSpent,
+		Description:     description,
+		ReferenceType:   referenceType,
+	}
+	if referenceID != 0 {
+		entry.ReferenceID = &referenceID
+	}
+	if idempotencyKey != "" {
+		entry.IdempotencyKey = &idempotencyKey
+	}
+
+	transaction, _, err := s.repo.Record(entry, 0, s.dayStart())
+	return transaction, err
+}
+
+// Adjust credits or debits points on behalf of an admin
+func (s *PointsServiceImpl) Adjust(adminID, userID uint, points int, reason, idempotencyKey string) (*models.PointsTransaction, error) {
+	if points == 0 {
+		return nil, errors.New("adjustment must not be zero")
+	}
+	if reason == "" {
+		return nil, errors.New("adjustment reason is required")
+	}
+
+	entry := &models.PointsTransaction{
+		UserID:          userID,
+		Points:          points,
+		TransactionType: models.PointsAdjusted,
+		Description:     reason,
+		Action:          models.ActionAdjustment,
+		ActorID:         &adminID,
+	}
+	if idempotencyKey != "" {
+		entry.IdempotencyKey = &idempotencyKey
+	}
+
+	transaction, _, err := s.repo.Record(entry, 0, s.dayStart())
+	return transaction, err
+}
+
+// GetBalance retrieves a user's balance
+func (s *PointsServiceImpl) GetBalance(userID uint) (int, error) {
+	return s.repo.GetBalance(userID)
+}
+
+// GetHistory retrieves a page of a user's transactions
+func (s *PointsServiceImpl) GetHistory(userID uint, page, pageSize int) ([]models.PointsTransaction, int64, error) {
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 || pageSize > 100 {
+		pageSize = 20
+	}
+	return s.repo.GetHistory(userID, pageSize, (page-1)*pageSize)
+}
+
+// GetRules retrieves all earning rules
+func (s *PointsServiceImpl) GetRules() ([]models.EarningRule, error) {
+	return s.repo.GetRules()
+}
+
+// UpdateRule changes an earning rule, creating it if the action is new
+func (s *PointsServiceImpl) UpdateRule(action string, points, dailyCap int, enabled bool, description string) (*models.EarningRule, error) {
+	if action == "" {
+		return nil, errors.New("action is required")
+	}
+	if points < 0 || dailyCap < 0 {
+		return nil, errors.New("points and daily cap must not be negative")
+	}
+
+	rule, err := s.repo.GetRule(action)
+	if errors.Is(err, models.ErrUnknownAction) {
+		rule = &models.EarningRule{Action: action}
+	} else if err != nil {
+		return nil, err
+	}
+
+	rule.Points = points
+	rule.DailyCap = dailyCap
+	rule.Enabled = enabled
+	if description != "" {
+		rule.Description = description
+	}
+
+	if err := s.repo.SaveRule(rule); err != nil {
+		return nil, fmt.Errorf("failed to save earning rule: %w", err)
+	}
+	return rule, nil
+}
+
+// FindDiscrepancies retrieves users whose balance does not match their ledger
+func (s *PointsServiceImpl) FindDiscrepancies() ([]models.Discrepancy, error) {
+	return s.repo.FindDiscrepancies()
+}
+
+// Reconcile resets a user's balance to the sum of their ledger
+func (s *PointsServiceImpl) Reconcile(userID uint) (*models.Discrepancy, error) {
+	return s.repo.Reconcile(userID)
+}
+
+// dayStart returns the start of the current UTC day, used for daily caps
+func (s *PointsServiceImpl) dayStart() time.Time {
+	return s.now().UTC().Truncate(24 * time.Hour)
+}
@@ -0,0 +1,180 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/yerenwgventures/GreatNigeriaLibrary-Foundation/backend/services/points/models"
+)
+
+// fakeRepository records what the service asks the ledger to do. Like the
+// real repository, it returns the original transaction for a repeated
+// idempotency key.
+type fakeRepository struct {
+	rules        map[string]*models.EarningRule
+	transactions []*models.PointsTransaction
+	dailyCap     int
+	dayStart     time.Time
+}
+
+func newFakeRepository() *fakeRepository {
+	return &fakeRepository{rules: map[string]*models.EarningRule{
+		models.ActionQuizPassed:     {Action: models.ActionQuizPassed, Points: 10, DailyCap: 100, Enabled: true, Description: "Passed a quiz"},
+		models.ActionCommentCreated: {Action: models.ActionCommentCreated, Points: 2, DailyCap: 20, Enabled: false},
+	}}
+}
+
+func (r *fakeRepository) Record(entry *models.PointsTransaction, dailyCap int, dayStart time.Time) (*models.PointsTransaction, bool, error) {
+	r.dailyCap, r.dayStart = dailyCap, dayStart
+	if entry.IdempotencyKey != nil {
+		for _, existing := range r.transactions {
+			if existing.UserID == entry.UserID && existing.IdempotencyKey != nil && *existing.IdempotencyKey == *entry.IdempotencyKey {
+				return existing, false, nil
+			}
+		}
+	}
+	entry.ID = uint(len(r.transactions) + 1)
+	r.transactions = append(r.transactions, entry)
+	return entry, true, nil
+}
+
+func (r *fakeRepository) GetBalance(userID uint) (int, error) { return 0, nil }
+
+func (r *fakeRepository) GetHistory(userID uint, limit, offset int) ([]models.PointsTransaction, int64, error) {
+	return nil, 0, nil
+}
+
+func (r *fakeRepository) GetRules() ([]models.EarningRule, error) { return nil, nil }
+
+func (r *fakeRepository) GetRule(action string) (*models.EarningRule, error) {
+	rule, ok := r.rules[action]
+	if !ok {
+		return nil, models.ErrUnknownAction
+	}
+	copied := *rule
+	return &copied, nil
+}
+
+func (r *fakeRepository) SaveRule(rule *models.EarningRule) error {
+	r.rules[rule.Action] = rule
+	return nil
+}
+
+func (r *fakeRepository) FindDiscrepancies() ([]models.Discrepancy, error) { return nil, nil }
+
+func (r *fakeRepository) Reconcile(userID uint) (*models.Discrepancy, error) { return nil, nil }
+
+func newTestService() (*PointsServiceImpl, *fakeRepository) {
+	repo := newFakeRepository()
+	service := NewPointsService(repo)
+	lagos := time.FixedZone("WAT", 60*60)
+	service.now = func() time.Time { return time.Date(2024, 5, 1, 0, 30, 0, 0, lagos) }
+	return service, repo
+}
+
+func TestAwardAppliesTheEarningRule(t *testing.T) {
+	service, repo := newTestService()
+
+	transaction, err := service.Award(AwardRequest{UserID: 7, Action: models.ActionQuizPassed, ReferenceType: "quiz", ReferenceID: 3})
+	require.NoError(t, err)
+
+	assert.Equal(t, 10, transaction.Points)
+	assert.Equal(t, models.PointsEarned, transaction.TransactionType)
+	assert.Equal(t, "Passed a quiz", transaction.Description)
+	assert.Equal(t, "quiz_passed:quiz:3", *transaction.IdempotencyKey)
+	assert.Equal(t, uint(3), *transaction.ReferenceID)
+	assert.Equal(t, 100, repo.dailyCap)
+	assert.Equal(t, time.Date(2024, 4, 30, 0, 0, 0, 0, time.UTC), repo.dayStart, "caps reset at midnight UTC")
+}
+
+func TestAwardRetriesReturnTheOriginalTransaction(t *testing.T) {
+	service, repo := newTestService()
+	req := AwardRequest{UserID: 7, Action: models.ActionQuizPassed, ReferenceType: "quiz", ReferenceID: 3}
+
+	first, err := service.Award(req)
+	require.NoError(t, err)
+	retry, err := service.Award(req)
+	require.NoError(t, err)
+
+	assert.Same(t, first, retry)
+	assert.Len(t, repo.transactions, 1)
+
+	req.ReferenceID = 4
+	_, err = service.Award(req)
+	require.NoError(t, err)
+	assert.Len(t, repo.transactions, 2, "each referenced entity is rewarded once")
+}
+
+func TestAwardOverrides(t *testing.T) {
+	service, _ := newTestService()
+
+	transaction, err := service.Award(AwardRequest{
+		UserID:         7,
+		Action:         models.ActionQuizPassed,
+		Points:         25,
+		IdempotencyKey: "custom",
+		Description:    "Perfect score",
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, 25, transaction.Points)
+	assert.Equal(t, "custom", *transaction.IdempotencyKey)
+	assert.Equal(t, "Perfect score", transaction.Description)
+	assert.Nil(t, transaction.ReferenceID)
+}
+
+func TestAwardRejectsUnknownAndDisabledRules(t *testing.T) {
+	service, repo := newTestService()
+
+	_, err := service.Award(AwardRequest{UserID: 7, Action: "unknown"})
+	assert.Equal(t, models.ErrUnknownAction, err)
+
+	_, err = service.Award(AwardRequest{UserID: 7, Action: models.ActionCommentCreated})
+	assert.Equal(t, models.ErrRuleDisabled, err)
+
+	assert.Empty(t, repo.transactions)
+}
+
+func TestSpendAndAdjust(t *testing.T) {
+	service, repo := newTestService()
+
+	_, err := service.Spend(7, 0, "Nothing", "", 0, "")
+	assert.Error(t, err)
+
+	transaction, err := service.Spend(7, 30, "Unlocked a book", "book", 2, "unlock:2")
+	require.NoError(t, err)
+	assert.Equal(t, -30, transaction.Points)
+	assert.Equal(t, models.PointsSpent, transaction.TransactionType)
+	assert.Zero(t, repo.dailyCap, "debits are never capped")
+
+	_, err = service.Adjust(1, 7, 0, "No change", "")
+	assert.Error(t, err)
+	_, err = service.Adjust(1, 7, 5, "", "")
+	assert.Error(t, err)
+
+	transaction, err = service.Adjust(1, 7, -5, "Duplicate award", "")
+	require.NoError(t, err)
+	assert.Equal(t, -5, transaction.Points)
+	assert.Equal(t, models.ActionAdjustment, transaction.Action)
+	assert.Equal(t, uint(1), *transaction.ActorID)
+	assert.Nil(t, transaction.IdempotencyKey)
+}
+
+func TestUpdateRule(t *testing.T) {
+	service, repo := newTestService()
+
+	_, err := service.UpdateRule(models.ActionQuizPassed, -1, 0, true, "")
+	assert.Error(t, err)
+
+	rule, err := service.UpdateRule(models.ActionQuizPassed, 15, 60, false, "")
+	require.NoError(t, err)
+	assert.Equal(t, "Passed a quiz", rule.Description, "description kept when not given")
+	assert.False(t, repo.rules[models.ActionQuizPassed].Enabled)
+
+	rule, err = service.UpdateRule("event_attended", 5, 0, true, "Attended an event")
+	require.NoError(t, err)
+	assert.Equal(t, "event_attended", rule.Action)
+	assert.Contains(t, repo.rules, "event_attended")
+}
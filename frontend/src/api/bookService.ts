@@ -7,12 +7,16 @@ import {
   Section,
   Subsection,
   AudioBookResponse,
+  MediaJobResponse,
   PhotoBookResponse,
   VideoBookResponse,
   PDFBookResponse,
   ShareableLinkResponse
 } from '../types';
 
+// AUDIO_JOB_POLL_INTERVAL is how often an audio job is checked, in milliseconds
+const AUDIO_JOB_POLL_INTERVAL = 2000;
+
 const BookService = {
   /**
    * Get all books
@@ -112,11 +116,20 @@ const BookService = {
   },
 
   /**
-   * Generate audio book from section content
+   * Generate audio book from section content. Audio is generated in the
+   * background, so the job is polled until it finishes.
    */
   generateAudio: async (sectionId: string): Promise<AudioBookResponse> => {
-    const response = await apiClient.post<AudioBookResponse>(`/books/sections/${sectionId}/audio`);
-    return response.data;
+    const response = await apiClient.post<MediaJobResponse>(`/books/sections/${sectionId}/audio`);
+    let job = response.data;
+    while (job.status === 'queued' || job.status === 'running') {
+      await new Promise((resolve) => setTimeout(resolve, AUDIO_JOB_POLL_INTERVAL));
+      job = (await apiClient.get<MediaJobResponse>(`/media/jobs/${job.jobId}`)).data;
+    }
+    if (job.status === 'failed') {
+      throw new Error(job.error || 'Failed to generate audio');
+    }
+    return job as AudioBookResponse;
   },
 
   /**
@@ -117,11 +117,12 @@ const AudioBook: React.FC<AudioBookProps> = ({ sectionId }) => {
       {audioBook ? (
         <>
           <AudioPlayer controls src={audioBook.audioUrl}>
+            {audioBook.timingUrl && <track kind="metadata" src={audioBook.timingUrl} default />}
             Your browser does not support the audio element.
           </AudioPlayer>
           
           <div>
-            <p>Duration: {Math.floor(audioBook.duration / 60)}:{(audioBook.duration % 60).toString().padStart(2, '0')}</p>
+            <p>Duration: {Math.floor(audioBook.duration / 60)}:{Math.floor(audioBook.duration % 60).toString().padStart(2, '0')}</p>
             <p>Generated: {new Date(audioBook.generatedAt).toLocaleString()}</p>
           </div>
           
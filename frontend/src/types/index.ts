@@ -108,11 +108,21 @@ export interface Bookmark {
 // Interactive Book Elements Types
 export interface AudioBookResponse {
   audioUrl: string;
+  timingUrl?: string; // WebVTT sentence and word timings
   duration: number;
   title: string;
   generatedAt: string;
 }
 
+export interface MediaJobResponse extends Partial<AudioBookResponse> {
+  jobId: number;
+  status: 'queued' | 'running' | 'completed' | 'failed';
+  sectionId: number;
+  statusUrl: string;
+  createdAt: string;
+  error?: string;
+}
+
 export interface PhotoBookResponse {
   photoUrls: string[];
   count: number;
@@ -56,10 +56,15 @@ func main() {
 	}
 	defer dbConn.Close()
 
-	// Run comprehensive GORM auto-migration for all foundation services
+	// Apply versioned schema migrations and, in development, the demo data
 	migrationService := database.NewMigrationService(dbConn.DB, appLogger)
-	if err := migrationService.MigrateFoundation(); err != nil {
-		appLogger.Fatal("Failed to run foundation migrations: " + err.Error())
+	if err := migrationService.Migrate(); err != nil {
+		appLogger.Fatal("Failed to run database migrations: " + err.Error())
+	}
+	if cfg.Database.SeedDemoData {
+		if err := migrationService.SeedDemoData(); err != nil {
+			appLogger.Fatal("Failed to load demo data: " + err.Error())
+		}
 	}
 
 	// Initialize repositories